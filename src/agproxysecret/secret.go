@@ -8,6 +8,7 @@ import (
 	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
 	agcapability "github.com/Dynatrace/dynatrace-operator/src/controllers/activegate/capability"
 	"github.com/Dynatrace/dynatrace-operator/src/kubeobjects"
+	"github.com/Dynatrace/dynatrace-operator/src/secretprovider"
 	"github.com/go-logr/logr"
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
@@ -104,8 +105,9 @@ func proxyUrlFromSpec(dynakube *dynatracev1beta1.DynaKube) string {
 }
 
 func (agProxySecretGenerator *ActiveGateProxySecretGenerator) proxyUrlFromUserSecret(ctx context.Context, dynakube *dynatracev1beta1.DynaKube) (string, error) {
-	var proxySecret corev1.Secret
-	if err := agProxySecretGenerator.client.Get(ctx, client.ObjectKey{Name: dynakube.Spec.Proxy.ValueFrom, Namespace: agProxySecretGenerator.namespace}, &proxySecret); err != nil {
+	proxySecret, err := secretprovider.NewReader(agProxySecretGenerator.client, agProxySecretGenerator.namespace, dynakube.Spec.SecretProvider).
+		Get(ctx, dynakube.Spec.Proxy.ValueFrom)
+	if err != nil {
 		return "", errors.WithMessage(err, fmt.Sprintf("failed to query %s secret", dynakube.Spec.Proxy.ValueFrom))
 	}
 
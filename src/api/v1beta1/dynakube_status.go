@@ -40,6 +40,9 @@ type DynaKubeStatus struct {
 	// ConnectionInfo caches information about the tenant and its communication hosts
 	ConnectionInfo ConnectionInfoStatus `json:"connectionInfo,omitempty"`
 
+	// AdditionalTenants caches the connection info and token conditions of the additional tenant targets
+	AdditionalTenants []TenantTargetStatus `json:"additionalTenants,omitempty"`
+
 	// CommunicationHostForClient caches a communication host specific to the api url.
 	CommunicationHostForClient CommunicationHostStatus `json:"communicationHostForClient,omitempty"`
 
@@ -49,15 +52,104 @@ type DynaKubeStatus struct {
 	// LatestAgentVersionUnixDefault caches the current agent version for unix and the PaaS installer which is configured for the environment
 	LatestAgentVersionUnixPaas string `json:"latestAgentVersionUnixPaas,omitempty"`
 
+	// PendingAgentVersionUnixPaas contains the latest PaaS agent version found outside of the maintenance windows,
+	// the code modules are updated to it in the next window
+	PendingAgentVersionUnixPaas string `json:"pendingAgentVersionUnixPaas,omitempty"`
+
 	// Conditions includes status about the current state of the instance
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
 
+	// UninjectedWorkloads lists the workloads in monitored namespaces with running pods, which were started without injection
+	UninjectedWorkloads []UninjectedWorkloadStatus `json:"uninjectedWorkloads,omitempty"`
+
+	// LastUninjectedWorkloadsScanTimestamp indicates when the monitored namespaces were last scanned for uninjected workloads
+	LastUninjectedWorkloadsScanTimestamp *metav1.Time `json:"lastUninjectedWorkloadsScanTimestamp,omitempty"`
+
+	// TokenRotation tracks which of the secrets derived from the tokens already use the current tokens
+	TokenRotation TokenRotationStatus `json:"tokenRotation,omitempty"`
+
+	// Settings tracks the Settings 2.0 objects created for spec.settings
+	Settings SettingsStatus `json:"settings,omitempty"`
+
 	ActiveGate          ActiveGateStatus `json:"activeGate,omitempty"`
 	ExtensionController EecStatus        `json:"eec,omitempty"`
 	Statsd              StatsdStatus     `json:"statsd,omitempty"`
 	OneAgent            OneAgentStatus   `json:"oneAgent,omitempty"`
 }
 
+type TenantTargetStatus struct {
+	// Name of the tenant target
+	Name string `json:"name"`
+
+	// LastAPITokenProbeTimestamp tracks when the last request for the API token validity was sent
+	LastAPITokenProbeTimestamp *metav1.Time `json:"lastAPITokenProbeTimestamp,omitempty"`
+
+	// LastPaaSTokenProbeTimestamp tracks when the last request for the PaaS token validity was sent
+	LastPaaSTokenProbeTimestamp *metav1.Time `json:"lastPaaSTokenProbeTimestamp,omitempty"`
+
+	// LastDataIngestTokenProbeTimestamp tracks when the last request for the DataIngest token validity was sent
+	LastDataIngestTokenProbeTimestamp *metav1.Time `json:"lastDataIngestTokenProbeTimestamp,omitempty"`
+
+	// ConnectionInfo caches information about the tenant and its communication hosts
+	ConnectionInfo ConnectionInfoStatus `json:"connectionInfo,omitempty"`
+
+	// Conditions includes status about the tokens of the tenant target
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+type TokenRotationStatus struct {
+	// TokensHash is the hash of the tokens last found in the tokens secret
+	TokensHash string `json:"tokensHash,omitempty"`
+
+	// LastRotationTimestamp indicates when the operator found changed tokens in the tokens secret
+	LastRotationTimestamp *metav1.Time `json:"lastRotationTimestamp,omitempty"`
+
+	// UpdatedSecrets lists the secrets derived from the tokens which use the current tokens, as namespace/name
+	UpdatedSecrets []string `json:"updatedSecrets,omitempty"`
+
+	// OutdatedSecrets lists the secrets derived from the tokens which still use previous tokens, as namespace/name
+	OutdatedSecrets []string `json:"outdatedSecrets,omitempty"`
+}
+
+type SettingsStatus struct {
+	// LastCheckTimestamp indicates when the objects were last compared to the objects on the tenant
+	LastCheckTimestamp *metav1.Time `json:"lastCheckTimestamp,omitempty"`
+
+	// Objects lists the objects created on the tenant
+	Objects []SettingsObjectStatus `json:"objects,omitempty"`
+}
+
+type SettingsObjectStatus struct {
+	// Name of the object in spec.settings
+	Name string `json:"name"`
+
+	// ObjectId of the object on the tenant
+	ObjectId string `json:"objectId"`
+
+	// SchemaId of the object
+	SchemaId string `json:"schemaId"`
+
+	// Scope of the object, after the default was applied
+	Scope string `json:"scope"`
+
+	// Hash of the schema version and value last written to the tenant
+	Hash string `json:"hash"`
+}
+
+type UninjectedWorkloadStatus struct {
+	// Namespace of the workload
+	Namespace string `json:"namespace"`
+
+	// Name of the root owner of the pods, or of the pod if it has no owner
+	Name string `json:"name"`
+
+	// Kind of the root owner, empty for pods without owner
+	Kind string `json:"kind,omitempty"`
+
+	// Pods is the number of running pods without injection
+	Pods int `json:"pods"`
+}
+
 type ConnectionInfoStatus struct {
 	CommunicationHosts []CommunicationHostStatus `json:"communicationHosts,omitempty"`
 	TenantUUID         string                    `json:"tenantUUID,omitempty"`
@@ -84,6 +176,33 @@ type VersionStatus struct {
 
 	// LastUpdateProbeTimestamp defines the last timestamp when the querying for updates have been done
 	LastUpdateProbeTimestamp *metav1.Time `json:"lastUpdateProbeTimestamp,omitempty"`
+
+	// PreviousVersion contains the version deployed before the last update, until the pods of the update are ready
+	PreviousVersion string `json:"previousVersion,omitempty"`
+
+	// PreviousImageHash contains the image hash deployed before the last update, until the pods of the update are ready
+	PreviousImageHash string `json:"previousImageHash,omitempty"`
+
+	// PendingVersion contains a version found outside of the maintenance windows, which is deployed in the next window
+	PendingVersion string `json:"pendingVersion,omitempty"`
+
+	// PendingImageHash contains the image hash of the PendingVersion
+	PendingImageHash string `json:"pendingImageHash,omitempty"`
+
+	// RolledBack is set if the pods of the last update failed to become ready and the previous version was restored.
+	// Automatic updates are paused until the rollback is acknowledged
+	RolledBack *RollbackStatus `json:"rolledBack,omitempty"`
+}
+
+type RollbackStatus struct {
+	// Version that failed to roll out
+	Version string `json:"version,omitempty"`
+
+	// ImageHash of the version that failed to roll out
+	ImageHash string `json:"imageHash,omitempty"`
+
+	// Timestamp of the rollback
+	Timestamp metav1.Time `json:"timestamp,omitempty"`
 }
 
 func (verStatus *VersionStatus) Status() VersionStatus {
@@ -129,6 +248,41 @@ type OneAgentStatus struct {
 
 	// LastHostsRequestTimestamp indicates the last timestamp the Operator queried for hosts
 	LastHostsRequestTimestamp *metav1.Time `json:"lastHostsRequestTimestamp,omitempty"`
+
+	// Rollout tracks the progress of the staged rollout of the OneAgent pods
+	Rollout *OneAgentRolloutStatus `json:"rollout,omitempty"`
+}
+
+type RolloutPhase string
+
+const (
+	RolloutProgressing RolloutPhase = "Progressing"
+	RolloutSoaking     RolloutPhase = "Soaking"
+	RolloutCompleted   RolloutPhase = "Completed"
+	RolloutHalted      RolloutPhase = "Halted"
+)
+
+type OneAgentRolloutStatus struct {
+	// Revision is the hash of the OneAgent pod template which is rolled out
+	Revision string `json:"revision,omitempty"`
+
+	// Phase of the rollout (Progressing, Soaking, Completed, Halted)
+	Phase RolloutPhase `json:"phase,omitempty"`
+
+	// Stage is the index of the current stage, the stage after the configured ones contains the remaining nodes
+	Stage int `json:"stage"`
+
+	// StageName is the name of the current stage
+	StageName string `json:"stageName,omitempty"`
+
+	// StageStartedTimestamp indicates when the pods of the current stage started to be updated
+	StageStartedTimestamp *metav1.Time `json:"stageStartedTimestamp,omitempty"`
+
+	// StageReadyTimestamp indicates when all updated pods of the current stage were ready, the soak time starts then
+	StageReadyTimestamp *metav1.Time `json:"stageReadyTimestamp,omitempty"`
+
+	// Message explains the current phase, e.g. why the rollout was halted
+	Message string `json:"message,omitempty"`
 }
 
 func (oneAgentStatus *OneAgentStatus) Name() string {
@@ -2,6 +2,7 @@ package v1beta1
 
 import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
 const (
@@ -38,6 +39,76 @@ const (
 	ReasonTokenError string = "TokenError"
 )
 
+// Condition types of the components reconciled for a DynaKube
+const (
+	// IstioConditionType identifies the condition of the Istio ServiceEntries and VirtualServices
+	IstioConditionType string = "IstioReady"
+
+	// NetworkPolicyConditionType identifies the condition of the NetworkPolicies for the communication endpoints
+	NetworkPolicyConditionType string = "NetworkPoliciesReady"
+
+	// PullSecretConditionType identifies the condition of the Dynatrace pull secret
+	PullSecretConditionType string = "PullSecretReady"
+
+	// OneAgentConditionType identifies the rollout condition of the OneAgent daemonset
+	OneAgentConditionType string = "OneAgentReady"
+
+	// OneAgentRolloutConditionType identifies the condition of the staged rollout of the OneAgent pods
+	OneAgentRolloutConditionType string = "OneAgentRolloutReady"
+
+	// CSIDriverConditionType identifies the rollout condition of the CSI driver daemonset, which provisions the code modules
+	CSIDriverConditionType string = "CSIDriverReady"
+
+	// ActiveGateConditionType identifies the rollout condition of the ActiveGate statefulset with multiple capabilities
+	ActiveGateConditionType string = "ActiveGateReady"
+
+	// KubeMonActiveGateConditionType identifies the rollout condition of the kubernetes-monitoring ActiveGate statefulset
+	KubeMonActiveGateConditionType string = "KubeMonActiveGateReady"
+
+	// RoutingActiveGateConditionType identifies the rollout condition of the routing ActiveGate statefulset
+	RoutingActiveGateConditionType string = "RoutingActiveGateReady"
+
+	// AutomaticApiMonitoringConditionType identifies the condition of the automatic Kubernetes API monitoring setting
+	AutomaticApiMonitoringConditionType string = "AutomaticApiMonitoringReady"
+
+	// RolledBackConditionType identifies the condition which is set while a failed update of a component is rolled back
+	RolledBackConditionType string = "RolledBack"
+
+	// TokensPropagatedConditionType identifies the condition of the secrets derived from the tokens, which is false
+	// while some of them still use previous tokens
+	TokensPropagatedConditionType string = "TokensPropagated"
+
+	// SettingsConditionType identifies the condition of the Settings 2.0 objects of spec.settings
+	SettingsConditionType string = "SettingsReady"
+)
+
+// Possible reasons for component conditions
+const (
+	// ReasonReady is set when the objects of a component have been reconciled and all of its pods are ready
+	ReasonReady string = "Ready"
+
+	// ReasonReconcileFailed is set when the objects of a component could not be reconciled
+	ReasonReconcileFailed string = "ReconcileFailed"
+
+	// ReasonRolloutInProgress is set when pods of a component are not updated or not ready yet
+	ReasonRolloutInProgress string = "RolloutInProgress"
+
+	// ReasonRolloutHalted is set when a staged rollout was stopped, because too many updated pods are unhealthy
+	ReasonRolloutHalted string = "RolloutHalted"
+
+	// ReasonUpdateFailed is set when the pods of an update did not become ready in time and the update was rolled back
+	ReasonUpdateFailed string = "UpdateFailed"
+
+	// ReasonNotFound is set when a workload the component relies on does not exist
+	ReasonNotFound string = "NotFound"
+
+	// ReasonNotReconciled is set when the reconcile stopped before the component was reached, so its last reported state may be outdated
+	ReasonNotReconciled string = "NotReconciled"
+
+	// ReasonTokensNotValid is set when changed tokens can't be propagated yet, because they didn't pass the verifications
+	ReasonTokensNotValid string = "TokensNotValid"
+)
+
 type DynaKubeProxy struct {
 	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Proxy value",order=32,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:com.tectonic.ui:text"}
 	Value string `json:"value,omitempty"`
@@ -46,6 +117,194 @@ type DynaKubeProxy struct {
 	ValueFrom string `json:"valueFrom,omitempty"`
 }
 
+type TenantTargetSpec struct {
+	// Name of the tenant target, used to name the objects generated for it and referenced by the
+	// oneagent.dynatrace.com/tenant-target annotation
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MaxLength=20
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Name",order=34,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:com.tectonic.ui:text"}
+	Name string `json:"name"`
+
+	// Location of the Dynatrace API of the additional environment, including your specific environment UUID
+	// +kubebuilder:validation:Required
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="API URL",order=35,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:com.tectonic.ui:text"}
+	APIURL string `json:"apiUrl"`
+
+	// Credentials for the additional environment, defaults to <dynakube name>-<target name>
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Tenant specific secrets",order=36,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:io.kubernetes:Secret"}
+	Tokens string `json:"tokens,omitempty"`
+}
+
+type EnrichmentRuleType string
+
+const (
+	EnrichmentRuleTypePodLabel       EnrichmentRuleType = "POD_LABEL"
+	EnrichmentRuleTypePodAnnotation  EnrichmentRuleType = "POD_ANNOTATION"
+	EnrichmentRuleTypeNamespaceLabel EnrichmentRuleType = "NAMESPACE_LABEL"
+	EnrichmentRuleTypeNodeLabel      EnrichmentRuleType = "NODE_LABEL"
+)
+
+type MetadataEnrichmentSpec struct {
+	// Rules which copy labels or annotations into the metadata of the injected pods
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Metadata enrichment rules",order=37,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:com.tectonic.ui:text"}
+	Rules []EnrichmentRule `json:"rules,omitempty"`
+}
+
+type EnrichmentRule struct {
+	// Where the value is taken from: pod labels, pod annotations, namespace labels or node labels
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Enum=POD_LABEL;POD_ANNOTATION;NAMESPACE_LABEL;NODE_LABEL
+	Type EnrichmentRuleType `json:"type"`
+
+	// Key of the label or annotation
+	// +kubebuilder:validation:Required
+	Source string `json:"source"`
+
+	// Optional: name of the attribute, defaults to k8s.pod.label.<source>, k8s.pod.annotation.<source>,
+	// k8s.namespace.label.<source> or k8s.node.label.<source>
+	Target string `json:"target,omitempty"`
+}
+
+// +kubebuilder:validation:Enum=NodeAdded;NodeNotReady;NodePressure;NodeVersionChanged;NodeInterruption
+type NodeEventType string
+
+const (
+	NodeEventAdded          NodeEventType = "NodeAdded"
+	NodeEventNotReady       NodeEventType = "NodeNotReady"
+	NodeEventPressure       NodeEventType = "NodePressure"
+	NodeEventVersionChanged NodeEventType = "NodeVersionChanged"
+	NodeEventInterruption   NodeEventType = "NodeInterruption"
+)
+
+type NodeEventsSpec struct {
+	// Events which are sent: NodeAdded, NodeNotReady (and ready again), NodePressure (memory, disk or PID pressure started or ended),
+	// NodeVersionChanged (kernel or kubelet version) and NodeInterruption (spot or preemptible interruption taints)
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Node event types",order=38,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:com.tectonic.ui:text"}
+	Events []NodeEventType `json:"events,omitempty"`
+
+	// Optional: Custom properties added to every node lifecycle event
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Node event custom properties",order=39,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:com.tectonic.ui:text"}
+	CustomProperties map[string]string `json:"customProperties,omitempty"`
+
+	// Optional: Keys of taints which signal a spot or preemptible interruption,
+	// in addition to the ones set by the AWS node termination handler and GKE
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Interruption taints",order=40,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:com.tectonic.ui:text"}
+	InterruptionTaints []string `json:"interruptionTaints,omitempty"`
+}
+
+type MaintenanceWindowSpec struct {
+	// Start of the window as cron expression with minute, hour, day of month, month and day of week, e.g. "0 22 * * 1-5"
+	// +kubebuilder:validation:Required
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Schedule",order=47,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:com.tectonic.ui:text"}
+	Schedule string `json:"schedule"`
+
+	// Length of the window, e.g. 4h
+	// +kubebuilder:validation:Required
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Duration",order=48,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:com.tectonic.ui:text"}
+	Duration metav1.Duration `json:"duration"`
+
+	// Optional: IANA time zone of the schedule, e.g. Europe/Vienna, defaults to UTC
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Time zone",order=49,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:com.tectonic.ui:text"}
+	TimeZone string `json:"timeZone,omitempty"`
+}
+
+// SettingsScopeEnvironment is the scope of settings objects which apply to the whole tenant
+const SettingsScopeEnvironment = "environment"
+
+type SettingsObjectSpec struct {
+	// Name identifies the object in the status of the DynaKube, it has to be unique within the list
+	// +kubebuilder:validation:Required
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Name",order=61,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:com.tectonic.ui:text"}
+	Name string `json:"name"`
+
+	// Schema of the object, e.g. builtin:anomaly-detection.kubernetes.cluster
+	// +kubebuilder:validation:Required
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Schema ID",order=62,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:com.tectonic.ui:text"}
+	SchemaId string `json:"schemaId"`
+
+	// Optional: Version of the schema the value is written for, defaults to the latest version
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Schema version",order=63,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:com.tectonic.ui:text"}
+	SchemaVersion string `json:"schemaVersion,omitempty"`
+
+	// Optional: Scope of the object, e.g. environment or an entity ID, defaults to the Kubernetes cluster entity of the cluster,
+	// which exists once the ActiveGate monitors the Kubernetes API
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Scope",order=64,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:com.tectonic.ui:text"}
+	Scope string `json:"scope,omitempty"`
+
+	// Value of the object as defined by the schema. Fields which are left out keep the value the tenant sets for them
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Type=object
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Value",order=65,xDescriptors="urn:alm:descriptor:com.tectonic.ui:advanced"
+	Value runtime.RawExtension `json:"value"`
+}
+
+type SecretProviderSpec struct {
+	// Reads the secrets from a KV version 2 secrets engine of HashiCorp Vault, logging in with the Kubernetes auth method
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Vault",order=50,xDescriptors="urn:alm:descriptor:com.tectonic.ui:advanced"
+	Vault *VaultSecretProviderSpec `json:"vault,omitempty"`
+
+	// Reads the secrets from files mounted into the operator, webhook and CSI driver pods, e.g. by the secrets store CSI driver
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="File",order=51,xDescriptors="urn:alm:descriptor:com.tectonic.ui:advanced"
+	File *FileSecretProviderSpec `json:"file,omitempty"`
+
+	// Optional: How long the values of a secret are cached before they are read again, defaults to 5m
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Refresh interval",order=52,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:com.tectonic.ui:text"}
+	RefreshInterval *metav1.Duration `json:"refreshInterval,omitempty"`
+}
+
+type VaultSecretProviderSpec struct {
+	// Address of the Vault server, which has to use https, e.g. https://vault.vault.svc:8200
+	// +kubebuilder:validation:Required
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Vault address",order=53,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:com.tectonic.ui:text"}
+	Address string `json:"address"`
+
+	// Role of the Kubernetes auth method, which has to be bound to the service accounts of the operator, the webhook and the CSI driver
+	// and to the audience vault
+	// +kubebuilder:validation:Required
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Vault role",order=54,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:com.tectonic.ui:text"}
+	Role string `json:"role"`
+
+	// Optional: Path the Kubernetes auth method is enabled at, defaults to kubernetes
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Vault auth path",order=55,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:com.tectonic.ui:text"}
+	AuthPath string `json:"authPath,omitempty"`
+
+	// Optional: Path the KV version 2 secrets engine is enabled at, defaults to secret
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Vault mount",order=56,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:com.tectonic.ui:text"}
+	Mount string `json:"mount,omitempty"`
+
+	// Optional: Path in the secrets engine below which the secrets are stored, a secret is read from <path>/<secret name>
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Vault path",order=57,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:com.tectonic.ui:text"}
+	Path string `json:"path,omitempty"`
+
+	// Optional: Name of a configmap with the CA certificates of the Vault server in the field 'certs'
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Vault CA certificates",order=58,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:io.kubernetes:ConfigMap"}
+	TrustedCAs string `json:"trustedCAs,omitempty"`
+}
+
+// +kubebuilder:validation:Enum=csi;env
+type FileSecretFormat string
+
+const (
+	// FileSecretFormatCSI is one file per field at <path>/<secret name>/<field>, as mounted by the secrets store CSI driver
+	FileSecretFormatCSI FileSecretFormat = "csi"
+
+	// FileSecretFormatEnv is one file per secret at <path>/<secret name>.env, with a <field>=<value> line per field
+	FileSecretFormatEnv FileSecretFormat = "env"
+)
+
+type FileSecretProviderSpec struct {
+	// Directory the secrets are mounted to, which has to be the same in the operator, webhook and CSI driver pods
+	// +kubebuilder:validation:Required
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Path",order=59,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:com.tectonic.ui:text"}
+	Path string `json:"path"`
+
+	// Optional: Layout of the files, csi for a file per field, env for an environment file per secret, defaults to csi
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Format",order=60,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:com.tectonic.ui:text"}
+	Format FileSecretFormat `json:"format,omitempty"`
+}
+
 type DynaKubeValueSource struct {
 	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Custom properties value",order=32,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:com.tectonic.ui:text"}
 	Value string `json:"value,omitempty"`
@@ -88,6 +347,14 @@ type DynaKubeSpec struct {
 	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Tenant specific secrets",order=2,xDescriptors="urn:alm:descriptor:io.kubernetes:Secret"
 	Tokens string `json:"tokens,omitempty"`
 
+	// Optional: Additional Dynatrace environments, e.g. to report to a second environment during a migration.
+	// Each tenant target has its own tokens, the code modules of a namespace or pod are assigned to it with the
+	// oneagent.dynatrace.com/tenant-target annotation.
+	// Additionally, a OneAgent DaemonSet and an ActiveGate StatefulSet are deployed per tenant target, if the DynaKube
+	// has a OneAgent or an ActiveGate. Multiple OneAgents per node require the multiple-osagents-on-node feature flag.
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Additional tenants",order=2,xDescriptors="urn:alm:descriptor:com.tectonic.ui:advanced"
+	AdditionalTenants []TenantTargetSpec `json:"additionalTenants,omitempty"`
+
 	// Optional: Pull secret for your private registry
 	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Custom PullSecret",order=8,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:io.kubernetes:Secret"}
 	CustomPullSecret string `json:"customPullSecret,omitempty"`
@@ -114,12 +381,57 @@ type DynaKubeSpec struct {
 	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Enable Istio automatic management",order=9,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:com.tectonic.ui:booleanSwitch"}
 	EnableIstio bool `json:"enableIstio,omitempty"`
 
+	// If enabled, NetworkPolicies will be created to allow pods to access the Dynatrace environment
+	// Injected namespaces labeled with networkpolicy.dynatrace.com/default-deny=true get policies for their pods with OneAgent injected
+	// The same label on the namespace of the DynaKube adds policies for the OneAgent and ActiveGate pods, which also allow egress to the cluster and the kube-apiserver
+	// Hostnames are allowed by FQDN policies, if the CRDs of Cilium or Calico are installed on the cluster
+	// Intended as companion of default-deny policies, as the selected pods become isolated for egress
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Enable NetworkPolicy automatic management",order=10,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:com.tectonic.ui:booleanSwitch"}
+	EnableNetworkPolicies bool `json:"enableNetworkPolicies,omitempty"`
+
 	// Optional: set a namespace selector to limit which namespaces are monitored
 	// By default, all namespaces will be monitored
 	// Has no effect during classicFullStack and hostMonitoring mode
 	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Namespace Selector",order=17,xDescriptors="urn:alm:descriptor:com.tectonic.ui:selector:core:v1:Namespace"
 	NamespaceSelector metav1.LabelSelector `json:"namespaceSelector,omitempty"`
 
+	// Optional: If enabled, workloads in monitored namespaces with running pods that were started without injection are restarted
+	// Only Deployments, StatefulSets and DaemonSets are restarted, at most one per reconcile
+	// Each workload is restarted only once, remove the dynakube.dynatrace.com/restarted-at annotation from its pod template to allow another restart
+	// The workloads are listed in the status in any case
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Restart uninjected workloads",order=18,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:com.tectonic.ui:booleanSwitch"}
+	RestartUninjectedWorkloads bool `json:"restartUninjectedWorkloads,omitempty"`
+
+	// Optional: Adds the values of chosen pod labels, pod annotations, namespace labels and node labels
+	// to the metadata enrichment files and the OneAgent configuration of injected pods
+	// Node labels are resolved by the operator, pods on nodes which joined after the last update of the init secret are not enriched with them
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Metadata enrichment",order=19,xDescriptors="urn:alm:descriptor:com.tectonic.ui:text"
+	MetadataEnrichment MetadataEnrichmentSpec `json:"metadataEnrichment,omitempty"`
+
+	// Optional: Node lifecycle events sent to the OneAgent host entity of the node
+	// MARKED_FOR_TERMINATION events are sent in any case
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Node events",order=20,xDescriptors="urn:alm:descriptor:com.tectonic.ui:text"
+	NodeEvents NodeEventsSpec `json:"nodeEvents,omitempty"`
+
+	// Optional: Time windows in which automatic updates change the OneAgent daemonset, the ActiveGate statefulsets
+	// and the code modules provisioned by the CSI driver. New versions found outside of them are kept pending.
+	// By default, updates are rolled out as soon as they are found
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Maintenance windows",order=21,xDescriptors="urn:alm:descriptor:com.tectonic.ui:advanced"
+	MaintenanceWindows []MaintenanceWindowSpec `json:"maintenanceWindows,omitempty"`
+
+	// Optional: Reads the secrets referenced by tokens, additionalTenants, proxy.valueFrom and customPullSecret from an external
+	// secret store instead of Kubernetes secrets. The values are cached by the operator, the webhook and the CSI driver and refreshed periodically.
+	// The proxy and the custom pull secret are copied to secrets managed by the operator, as pods can only reference Kubernetes secrets
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Secret provider",order=22,xDescriptors="urn:alm:descriptor:com.tectonic.ui:advanced"
+	SecretProvider *SecretProviderSpec `json:"secretProvider,omitempty"`
+
+	// Optional: Settings 2.0 objects managed by the operator on the tenant of the apiUrl. Changes made to the objects on the tenant are reverted,
+	// objects removed from the list are deleted.
+	// The objects are also deleted with the DynaKube, unless the tokens are no longer valid or the deletion keeps failing for an hour,
+	// the ids of objects left on the tenant are recorded as event. Needs the scopes settings.read and settings.write on the API token
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Settings",order=23,xDescriptors="urn:alm:descriptor:com.tectonic.ui:advanced"
+	Settings []SettingsObjectSpec `json:"settings,omitempty"`
+
 	// General configuration about OneAgent instances
 	// +kubebuilder:validation:MaxProperties=1
 	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="OneAgent",xDescriptors="urn:alm:descriptor:com.tectonic.ui:text"
@@ -0,0 +1,85 @@
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NodeTerminationReason is why a node was marked for termination
+// +kubebuilder:validation:Enum=Unschedulable;Deleted
+type NodeTerminationReason string
+
+const (
+	NodeUnschedulable NodeTerminationReason = "Unschedulable"
+	NodeDeleted       NodeTerminationReason = "Deleted"
+)
+
+// DynaKubeNodeSpec identifies the node and the DynaKube, which runs a OneAgent on it
+// +k8s:openapi-gen=true
+type DynaKubeNodeSpec struct {
+	// Name of the DynaKube, which runs a OneAgent on the node
+	DynaKube string `json:"dynakube"`
+
+	// Name of the node
+	NodeName string `json:"nodeName"`
+}
+
+// DynaKubeNodeStatus defines the observed state of the OneAgent on the node
+// +k8s:openapi-gen=true
+type DynaKubeNodeStatus struct {
+	// IPAddress of the OneAgent running on the node
+	IPAddress string `json:"ipAddress,omitempty"`
+
+	// HostEntityID is the entity id of the OneAgent host
+	HostEntityID string `json:"hostEntityID,omitempty"`
+
+	// RemovedTimestamp indicates when the node was found to be removed from the cluster
+	RemovedTimestamp *metav1.Time `json:"removedTimestamp,omitempty"`
+
+	// TerminationEvents lists the latest MARKED_FOR_TERMINATION events sent for the node
+	TerminationEvents []NodeTerminationEvent `json:"terminationEvents,omitempty"`
+}
+
+type NodeTerminationEvent struct {
+	// Timestamp when the event was sent
+	Timestamp metav1.Time `json:"timestamp"`
+
+	// Reason the node was marked for termination (Unschedulable, Deleted)
+	Reason NodeTerminationReason `json:"reason"`
+
+	// Error contains the cause if the event could not be sent
+	Error string `json:"error,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// DynaKubeNode lists a node which runs a OneAgent of a DynaKube, with the host entity of the OneAgent
+// and the MARKED_FOR_TERMINATION events sent for the node. It is managed by the operator, one per DynaKube and node.
+// +k8s:openapi-gen=true
+// +kubebuilder:resource:path=dynakubenodes,scope=Namespaced,categories=dynatrace
+// +kubebuilder:printcolumn:name="Node",type=string,JSONPath=`.spec.nodeName`
+// +kubebuilder:printcolumn:name="DynaKube",type=string,JSONPath=`.spec.dynakube`
+// +kubebuilder:printcolumn:name="IP",type=string,JSONPath=`.status.ipAddress`
+// +kubebuilder:printcolumn:name="Host",type=string,JSONPath=`.status.hostEntityID`
+// +kubebuilder:printcolumn:name="Last Marked",type=date,JSONPath=`.status.terminationEvents[-1:].timestamp`
+// +kubebuilder:printcolumn:name="Removed",type=date,JSONPath=`.status.removedTimestamp`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+type DynaKubeNode struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   DynaKubeNodeSpec   `json:"spec,omitempty"`
+	Status DynaKubeNodeStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// DynaKubeNodeList contains a list of DynaKubeNode
+type DynaKubeNodeList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []DynaKubeNode `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&DynaKubeNode{}, &DynaKubeNodeList{})
+}
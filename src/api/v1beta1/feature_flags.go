@@ -29,6 +29,7 @@ const (
 	annotationFeaturePrefix                           = PublicAnnotationPrefix + "feature-"
 	annotationFeatureDisableActiveGateUpdates         = annotationFeaturePrefix + "disable-activegate-updates"
 	annotationFeatureDisableHostsRequests             = annotationFeaturePrefix + "disable-hosts-requests"
+	annotationFeatureDisableAutomaticRollback         = annotationFeaturePrefix + "disable-automatic-rollback"
 	annotationFeatureOneAgentMaxUnavailable           = annotationFeaturePrefix + "oneagent-max-unavailable"
 	annotationFeatureEnableWebhookReinvocationPolicy  = annotationFeaturePrefix + "enable-webhook-reinvocation-policy"
 	annotationFeatureIgnoreUnknownState               = annotationFeaturePrefix + "ignore-unknown-state"
@@ -41,6 +42,7 @@ const (
 	AnnotationFeatureDisableReadOnlyOneAgent          = annotationFeaturePrefix + "disable-oneagent-readonly-host-fs"
 	AnnotationFeatureEnableActivegateRawImage         = annotationFeaturePrefix + "enable-activegate-raw-image"
 	AnnotationFeatureEnableMultipleOsAgentsOnNode     = annotationFeaturePrefix + "multiple-osagents-on-node"
+	AnnotationFeatureAllowUnverifiedOneAgentDownloads = annotationFeaturePrefix + "allow-unverified-oneagent-downloads"
 )
 
 var (
@@ -57,6 +59,11 @@ func (dk *DynaKube) FeatureDisableHostsRequests() bool {
 	return dk.Annotations[annotationFeatureDisableHostsRequests] == "true"
 }
 
+// FeatureDisableAutomaticRollback is a feature flag to keep failed OneAgent and ActiveGate updates, instead of rolling them back.
+func (dk *DynaKube) FeatureDisableAutomaticRollback() bool {
+	return dk.Annotations[annotationFeatureDisableAutomaticRollback] == "true"
+}
+
 // FeatureOneAgentMaxUnavailable is a feature flag to configure maxUnavailable on the OneAgent DaemonSets rolling upgrades.
 func (dk *DynaKube) FeatureOneAgentMaxUnavailable() int {
 	raw := dk.Annotations[annotationFeatureOneAgentMaxUnavailable]
@@ -160,3 +167,10 @@ func (dk *DynaKube) FeatureEnableActivegateRawImage() bool {
 func (dk *DynaKube) FeatureEnableMultipleOsAgentsOnNode() bool {
 	return dk.Annotations[AnnotationFeatureEnableMultipleOsAgentsOnNode] == "true"
 }
+
+// FeatureAllowUnverifiedOneAgentDownloads is a feature flag to install OneAgent packages downloaded from the Dynatrace API
+// without a pinned checksum. The Dynatrace API doesn't provide checksums, so such packages are only checked for archive integrity.
+// Defaults to false
+func (dk *DynaKube) FeatureAllowUnverifiedOneAgentDownloads() bool {
+	return dk.Annotations[AnnotationFeatureAllowUnverifiedOneAgentDownloads] == "true"
+}
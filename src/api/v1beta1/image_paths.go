@@ -120,6 +120,28 @@ func resolveImagePath(resolver imagePathResolver) string {
 	return fmt.Sprintf("%s/%s", registry, resolver.DefaultImagePath())
 }
 
+// pinDeployedImage pins the image to the digest of the deployed version once it is known. The image reference only
+// changes with the deployed version, so a pending or rolled back update neither changes the pod template nor is
+// pulled by tag when pods restart.
+func pinDeployedImage(image string, versionStatus VersionStatus) string {
+	if image == "" || versionStatus.ImageHash == "" {
+		return image
+	}
+
+	digest := versionStatus.ImageHash
+	if !strings.Contains(digest, ":") {
+		digest = "sha256:" + digest
+	}
+
+	if i := strings.Index(image, "@"); i >= 0 {
+		image = image[:i]
+	}
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		image = image[:i]
+	}
+	return image + "@" + digest
+}
+
 func buildImageRegistry(apiURL string) string {
 	registry := strings.TrimPrefix(apiURL, "https://")
 	registry = strings.TrimPrefix(registry, "http://")
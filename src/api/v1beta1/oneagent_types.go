@@ -2,6 +2,7 @@ package v1beta1
 
 import (
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 type OneAgentMode string
@@ -110,6 +111,47 @@ type HostInjectSpec struct {
 	// Optional: Adds additional labels for the OneAgent pods
 	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Labels",order=26,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:com.tectonic.ui:text"}
 	Labels map[string]string `json:"labels,omitempty"`
+
+	// Optional: Rolls out changes of the OneAgent pods, like new versions, to the nodes in stages instead of all at once
+	// Nodes not selected by any stage are updated last
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Staged rollout",order=27,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:com.tectonic.ui:text"}
+	Rollout *RolloutSpec `json:"rollout,omitempty"`
+}
+
+type RolloutSpec struct {
+	// Stages updated one after the other, the first one being the canary
+	// +kubebuilder:validation:MinItems=1
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Rollout stages",order=41,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:com.tectonic.ui:text"}
+	Stages []RolloutStage `json:"stages"`
+
+	// Optional: Time to wait after the OneAgent pods of a stage are ready, before continuing with the next stage
+	// Defaults to 10m
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Soak time",order=42,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:com.tectonic.ui:text"}
+	SoakTime *metav1.Duration `json:"soakTime,omitempty"`
+
+	// Optional: Time the updated OneAgent pods of a stage have to become ready, before the rollout is halted
+	// Defaults to 15m
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Progress deadline",order=43,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:com.tectonic.ui:text"}
+	ProgressDeadline *metav1.Duration `json:"progressDeadline,omitempty"`
+
+	// Optional: Number of updated OneAgent pods of a stage, which may be unhealthy without halting the rollout
+	// Pods are unhealthy if they restarted, are not ready during the soak time, or their host is not available in Dynatrace
+	// Defaults to 0
+	// +kubebuilder:validation:Minimum=0
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Max unhealthy pods",order=44,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:com.tectonic.ui:number"}
+	MaxUnhealthyPods int `json:"maxUnhealthyPods,omitempty"`
+}
+
+type RolloutStage struct {
+	// Name of the stage, shown in the status
+	// +kubebuilder:validation:Required
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Stage name",order=45,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:com.tectonic.ui:text"}
+	Name string `json:"name"`
+
+	// Labels of the nodes in the stage
+	// +kubebuilder:validation:Required
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Stage node selector",order=46,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:com.tectonic.ui:selector:Node"}
+	NodeSelector map[string]string `json:"nodeSelector"`
 }
 
 type ApplicationMonitoringSpec struct {
@@ -129,4 +171,59 @@ type AppInjectionSpec struct {
 	// Optional: define resources requests and limits for the initContainer
 	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Resource Requirements",order=15,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:com.tectonic.ui:resourceRequirements"}
 	InitResources corev1.ResourceRequirements `json:"initResources,omitempty"`
+
+	// Optional: Location to fetch the code modules from instead of the Dynatrace API,
+	// e.g. for clusters that can not reach the tenant, but have access to an internal registry or mirror
+	// +nullable
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Code modules source",order=16,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:com.tectonic.ui:hidden"}
+	CodeModulesSource *CodeModulesSourceSpec `json:"codeModulesSource,omitempty"`
+
+	// Optional: Code modules the CSI driver keeps on the nodes in addition to the OneAgent version in use,
+	// so pods requesting another flavor can use the CSI volume and pinning a pre-pulled version needs no download
+	// +nullable
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Code modules pre-pull",order=50,xDescriptors={"urn:alm:descriptor:com.tectonic.ui:advanced","urn:alm:descriptor:com.tectonic.ui:hidden"}
+	CodeModulesPrePull *CodeModulesPrePullSpec `json:"codeModulesPrePull,omitempty"`
+}
+
+type CodeModulesPrePullSpec struct {
+	// Optional: OneAgent versions to keep, the version in use is always kept
+	// Example: 1.239.0.20220315-161254
+	Versions []string `json:"versions,omitempty"`
+
+	// Optional: Flavors to keep, either default, multidistro or musl
+	// The default flavor of the node is always kept, multidistro on x86 and default on arm
+	// Pods requesting musl download it with the installer, unless musl is listed
+	Flavors []string `json:"flavors,omitempty"`
+
+	// Optional: Architectures to keep, either x86 or arm
+	// Nodes only keep the code modules of their own architecture, nodes of architectures not listed only keep the version in use
+	// Defaults to all architectures
+	Architectures []string `json:"architectures,omitempty"`
+}
+
+type CodeModulesSourceSpec struct {
+	// Optional: OCI image containing the code modules, e.g. registry.example.com/dynatrace/codemodules
+	// The OneAgent version is used as tag, if the image has neither tag nor digest
+	// The CSI driver pulls it with the credentials of the custom pull secret,
+	// without the CSI driver the init container pulls it with the imagePullSecrets of the pod
+	// Cannot be used in conjunction with mirrorIndexUrl
+	Image string `json:"image,omitempty"`
+
+	// Optional: Directory inside the image that contains the code modules
+	// Defaults to opt/dynatrace/oneagent
+	ImagePath string `json:"imagePath,omitempty"`
+
+	// Optional: URL of the signed index of a mirror, either http(s):// or file://
+	// The index lists the code module archives per version, flavor and architecture, its signature is expected at <mirrorIndexUrl>.sig
+	// Cannot be used in conjunction with image
+	MirrorIndexURL string `json:"mirrorIndexUrl,omitempty"`
+
+	// Optional: Base64 encoded ed25519 public key to verify the signature of the mirror index
+	// Required if mirrorIndexUrl is set
+	MirrorPublicKey string `json:"mirrorPublicKey,omitempty"`
+
+	// Optional: Hex encoded SHA-256 checksum of the code modules package of the version, which the CSI driver downloads from the Dynatrace API
+	// The Dynatrace API doesn't provide checksums, without it the package is only installed with the allow-unverified-oneagent-downloads feature flag
+	// Requires version, cannot be used in conjunction with image or mirrorIndexUrl
+	Sha256 string `json:"sha256,omitempty"`
 }
@@ -31,8 +31,19 @@ const (
 	// PullSecretSuffix is the suffix appended to the DynaKube name to n.
 	PullSecretSuffix   = "-pull-secret"
 	TenantSecretSuffix = "-activegate-tenant-secret"
+	ProxySecretSuffix  = "-proxy"
 
 	PodNameOsAgent = "oneagent"
+
+	// OneAgentTenantSecretSuffix is the suffix of the secret with the tenant config of the OneAgents of a tenant target
+	OneAgentTenantSecretSuffix = "-oneagent-tenant-secret"
+
+	// LabelTenantTarget is set on the objects generated for an additional tenant, its value is the name of the tenant target
+	LabelTenantTarget = "operator.dynatrace.com/tenant-target"
+
+	// AnnotationAcknowledgeRollback resumes the automatic updates of a component whose update was rolled back,
+	// if it is set to the version that failed to roll out. Multiple versions can be separated by commas.
+	AnnotationAcknowledgeRollback = PublicAnnotationPrefix + "acknowledge-rollback"
 )
 
 // NeedsActiveGate returns true when a feature requires ActiveGate instances.
@@ -114,22 +125,55 @@ func (dk *DynaKube) ShouldAutoUpdateOneAgent() bool {
 	return false
 }
 
+// RollbackAcknowledged returns true if the rollback of the given version was acknowledged with the AnnotationAcknowledgeRollback annotation.
+func (dk *DynaKube) RollbackAcknowledged(version string) bool {
+	if version == "" {
+		return false
+	}
+	for _, acknowledged := range strings.Split(dk.Annotations[AnnotationAcknowledgeRollback], ",") {
+		if strings.TrimSpace(acknowledged) == version {
+			return true
+		}
+	}
+	return false
+}
+
 // AGTenantSecret returns the name of the secret containing tenant UUID, token and communication endpoints for ActiveGate
 func (dk *DynaKube) AGTenantSecret() string {
 	return dk.Name + TenantSecretSuffix
 }
 
 // PullSecret returns the name of the pull secret to be used for immutable images.
+// With a secret provider, the custom pull secret is copied to the pull secret managed by the operator.
 func (dk *DynaKube) PullSecret() string {
-	if dk.Spec.CustomPullSecret != "" {
+	if dk.Spec.CustomPullSecret != "" && dk.Spec.SecretProvider == nil {
 		return dk.Spec.CustomPullSecret
 	}
 	return dk.Name + PullSecretSuffix
 }
 
+// ProxySecret returns the name of the secret referenced by pods for the proxy, if it's set with proxy.valueFrom.
+// With a secret provider, the proxy is copied to a secret managed by the operator.
+func (dk *DynaKube) ProxySecret() string {
+	if dk.Spec.SecretProvider != nil {
+		return dk.Name + ProxySecretSuffix
+	}
+	return dk.Spec.Proxy.ValueFrom
+}
+
+// NeedsProxySecretCopy returns true if the proxy has to be copied from the secret provider to the secret returned by ProxySecret.
+func (dk *DynaKube) NeedsProxySecretCopy() bool {
+	return dk.Spec.SecretProvider != nil && dk.Spec.Proxy != nil && dk.Spec.Proxy.ValueFrom != ""
+}
+
+// NeedsPullSecretCopy returns true if the custom pull secret has to be copied from the secret provider to the secret returned by PullSecret.
+func (dk *DynaKube) NeedsPullSecretCopy() bool {
+	return dk.Spec.SecretProvider != nil && dk.Spec.CustomPullSecret != ""
+}
+
 // ActiveGateImage returns the ActiveGate image to be used with the dk DynaKube instance.
 func (dk *DynaKube) ActiveGateImage() string {
-	return resolveImagePath(newActiveGateImagePath(dk))
+	return pinDeployedImage(resolveImagePath(newActiveGateImagePath(dk)), dk.Status.ActiveGate.VersionStatus)
 }
 
 // EecImage returns the Extension Controller image to be used with the dk DynaKube instance.
@@ -179,6 +223,34 @@ func (dk *DynaKube) InitResources() *corev1.ResourceRequirements {
 	return nil
 }
 
+// CodeModulesSource returns where the code modules are taken from, nil means they are downloaded from the Dynatrace API.
+func (dk *DynaKube) CodeModulesSource() *CodeModulesSourceSpec {
+	if dk.ApplicationMonitoringMode() {
+		return dk.Spec.OneAgent.ApplicationMonitoring.CodeModulesSource
+	} else if dk.CloudNativeFullstackMode() {
+		return dk.Spec.OneAgent.CloudNativeFullStack.CodeModulesSource
+	}
+	return nil
+}
+
+// CodeModulesSha256 returns the pinned checksum of the code modules package of the version, empty if there is none.
+func (dk *DynaKube) CodeModulesSha256() string {
+	if source := dk.CodeModulesSource(); source != nil {
+		return source.Sha256
+	}
+	return ""
+}
+
+// CodeModulesPrePull returns the code modules the CSI driver keeps on the nodes besides the version in use, nil if there are none.
+func (dk *DynaKube) CodeModulesPrePull() *CodeModulesPrePullSpec {
+	if dk.ApplicationMonitoringMode() {
+		return dk.Spec.OneAgent.ApplicationMonitoring.CodeModulesPrePull
+	} else if dk.CloudNativeFullstackMode() {
+		return dk.Spec.OneAgent.CloudNativeFullStack.CodeModulesPrePull
+	}
+	return nil
+}
+
 func (dk *DynaKube) OneAgentResources() *corev1.ResourceRequirements {
 	if dk.ClassicFullStackMode() {
 		return &dk.Spec.OneAgent.ClassicFullStack.OneAgentResources
@@ -201,6 +273,18 @@ func (dk *DynaKube) NodeSelector() map[string]string {
 	return nil
 }
 
+// OneAgentRollout returns the staged rollout of the OneAgent pods, or nil if pods are updated all at once.
+func (dk *DynaKube) OneAgentRollout() *RolloutSpec {
+	if dk.ClassicFullStackMode() {
+		return dk.Spec.OneAgent.ClassicFullStack.Rollout
+	} else if dk.HostMonitoringMode() {
+		return dk.Spec.OneAgent.HostMonitoring.Rollout
+	} else if dk.CloudNativeFullstackMode() {
+		return dk.Spec.OneAgent.CloudNativeFullStack.Rollout
+	}
+	return nil
+}
+
 func (dk *DynaKube) Version() string {
 	if dk.ClassicFullStackMode() {
 		return dk.Spec.OneAgent.ClassicFullStack.Version
@@ -222,7 +306,7 @@ func (dk *DynaKube) NamespaceSelector() *metav1.LabelSelector {
 func (dk *DynaKube) ImmutableOneAgentImage() string {
 	oneAgentImage := dk.Image()
 	if oneAgentImage != "" {
-		return oneAgentImage // TODO: What to do with the Version field in this case ?
+		return pinDeployedImage(oneAgentImage, dk.Status.OneAgent.VersionStatus) // TODO: What to do with the Version field in this case ?
 	}
 
 	if dk.Spec.APIURL == "" {
@@ -235,7 +319,7 @@ func (dk *DynaKube) ImmutableOneAgentImage() string {
 	}
 
 	registry := buildImageRegistry(dk.Spec.APIURL)
-	return fmt.Sprintf("%s/linux/oneagent:%s", registry, tag)
+	return pinDeployedImage(fmt.Sprintf("%s/linux/oneagent:%s", registry, tag), dk.Status.OneAgent.VersionStatus)
 }
 
 // Tokens returns the name of the Secret to be used for tokens.
@@ -246,6 +330,60 @@ func (dk *DynaKube) Tokens() string {
 	return dk.Name
 }
 
+// TenantTarget returns the additional tenant with the given name, or nil if there is none.
+func (dk *DynaKube) TenantTarget(name string) *TenantTargetSpec {
+	for i := range dk.Spec.AdditionalTenants {
+		if dk.Spec.AdditionalTenants[i].Name == name {
+			return &dk.Spec.AdditionalTenants[i]
+		}
+	}
+	return nil
+}
+
+// TenantTargetTokens returns the name of the secret with the tokens of the additional tenant.
+func (dk *DynaKube) TenantTargetTokens(target TenantTargetSpec) string {
+	if target.Tokens != "" {
+		return target.Tokens
+	}
+	return dk.Name + "-" + target.Name
+}
+
+// TenantTargetStatus returns the status of the additional tenant with the given name, an empty status is added if it is missing.
+func (dk *DynaKube) TenantTargetStatus(name string) *TenantTargetStatus {
+	for i := range dk.Status.AdditionalTenants {
+		if dk.Status.AdditionalTenants[i].Name == name {
+			return &dk.Status.AdditionalTenants[i]
+		}
+	}
+	dk.Status.AdditionalTenants = append(dk.Status.AdditionalTenants, TenantTargetStatus{Name: name})
+	return &dk.Status.AdditionalTenants[len(dk.Status.AdditionalTenants)-1]
+}
+
+// AGTenantSecretForTarget returns the name of the ActiveGate tenant secret of the additional tenant.
+func (dk *DynaKube) AGTenantSecretForTarget(target string) string {
+	return dk.Name + "-" + target + TenantSecretSuffix
+}
+
+// OneAgentTenantSecretForTarget returns the name of the secret with the tenant config of the OneAgents of the additional tenant.
+func (dk *DynaKube) OneAgentTenantSecretForTarget(target string) string {
+	return dk.Name + "-" + target + OneAgentTenantSecretSuffix
+}
+
+// OneAgentDaemonsetNameForTarget returns the name of the DaemonSet of the OneAgents reporting to the additional tenant.
+func (dk *DynaKube) OneAgentDaemonsetNameForTarget(target string) string {
+	return fmt.Sprintf("%s-%s-%s", dk.Name, target, PodNameOsAgent)
+}
+
+// TenantTargetUUID returns the UUID of the additional tenant once its connection info is known.
+func (dk *DynaKube) TenantTargetUUID(name string) string {
+	for _, targetStatus := range dk.Status.AdditionalTenants {
+		if targetStatus.Name == name {
+			return targetStatus.ConnectionInfo.TenantUUID
+		}
+	}
+	return ""
+}
+
 func (dk *DynaKube) CommunicationHostForClient() dtclient.CommunicationHost {
 	return dtclient.CommunicationHost(dk.Status.CommunicationHostForClient)
 }
@@ -319,3 +457,49 @@ func splitArg(arg string) (key, value string) {
 	value = split[1]
 	return
 }
+
+// EnrichmentRules returns the rules of the metadata enrichment with the given type.
+func (dk *DynaKube) EnrichmentRules(ruleType EnrichmentRuleType) []EnrichmentRule {
+	var rules []EnrichmentRule
+	for _, rule := range dk.Spec.MetadataEnrichment.Rules {
+		if rule.Type == ruleType {
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+// NeedsPodInfo checks if the init container needs the labels and annotations of the pod.
+func (dk *DynaKube) NeedsPodInfo() bool {
+	return len(dk.EnrichmentRules(EnrichmentRuleTypePodLabel)) > 0 || len(dk.EnrichmentRules(EnrichmentRuleTypePodAnnotation)) > 0
+}
+
+// ToAttribute returns the name of the attribute the rule writes.
+func (rule EnrichmentRule) ToAttribute() string {
+	if rule.Target != "" {
+		return rule.Target
+	}
+
+	var prefix string
+	switch rule.Type {
+	case EnrichmentRuleTypePodLabel:
+		prefix = "k8s.pod.label."
+	case EnrichmentRuleTypePodAnnotation:
+		prefix = "k8s.pod.annotation."
+	case EnrichmentRuleTypeNamespaceLabel:
+		prefix = "k8s.namespace.label."
+	case EnrichmentRuleTypeNodeLabel:
+		prefix = "k8s.node.label."
+	}
+	return prefix + rule.Source
+}
+
+// NodeEventEnabled checks if the given node lifecycle event should be sent.
+func (dk *DynaKube) NodeEventEnabled(eventType NodeEventType) bool {
+	for _, enabled := range dk.Spec.NodeEvents.Events {
+		if enabled == eventType {
+			return true
+		}
+	}
+	return false
+}
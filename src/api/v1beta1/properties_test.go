@@ -96,6 +96,55 @@ func TestOneAgentImage(t *testing.T) {
 		dk := DynaKube{Spec: DynaKubeSpec{OneAgent: OneAgentSpec{ClassicFullStack: &ClassicFullStackSpec{Image: customImg}}}}
 		assert.Equal(t, customImg, dk.ImmutableOneAgentImage())
 	})
+
+	t.Run(`OneAgentImage is pinned to the restored version after a rollback`, func(t *testing.T) {
+		dk := DynaKube{Spec: DynaKubeSpec{APIURL: testAPIURL}}
+		dk.Status.OneAgent.VersionStatus = VersionStatus{
+			Version:    "1.234.5",
+			ImageHash:  "abcdef",
+			RolledBack: &RollbackStatus{Version: "1.235.0"},
+		}
+		assert.Equal(t, "test-endpoint/linux/oneagent@sha256:abcdef", dk.ImmutableOneAgentImage())
+	})
+
+	t.Run(`custom OneAgentImage with a registry port is pinned after a rollback`, func(t *testing.T) {
+		customImg := "registry:5000/my/oneagent:latest"
+		dk := DynaKube{Spec: DynaKubeSpec{OneAgent: OneAgentSpec{ClassicFullStack: &ClassicFullStackSpec{Image: customImg}}}}
+		dk.Status.OneAgent.VersionStatus = VersionStatus{
+			ImageHash:  "sha256:abcdef",
+			RolledBack: &RollbackStatus{Version: "1.235.0"},
+		}
+		assert.Equal(t, "registry:5000/my/oneagent@sha256:abcdef", dk.ImmutableOneAgentImage())
+	})
+
+	t.Run(`OneAgentImage is pinned to the deployed version`, func(t *testing.T) {
+		dk := DynaKube{Spec: DynaKubeSpec{APIURL: testAPIURL}}
+		dk.Status.OneAgent.VersionStatus = VersionStatus{Version: "1.234.5", ImageHash: "abcdef"}
+		assert.Equal(t, "test-endpoint/linux/oneagent@sha256:abcdef", dk.ImmutableOneAgentImage())
+	})
+
+	t.Run(`OneAgentImage is pinned to the deployed version while an update is pending`, func(t *testing.T) {
+		dk := DynaKube{Spec: DynaKubeSpec{APIURL: testAPIURL}}
+		dk.Status.OneAgent.VersionStatus = VersionStatus{
+			Version:          "1.234.5",
+			ImageHash:        "abcdef",
+			PendingVersion:   "1.235.0",
+			PendingImageHash: "123456",
+		}
+		assert.Equal(t, "test-endpoint/linux/oneagent@sha256:abcdef", dk.ImmutableOneAgentImage())
+	})
+}
+
+func TestRollbackAcknowledged(t *testing.T) {
+	dk := DynaKube{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+		AnnotationAcknowledgeRollback: "1.235.0, 1.236.0",
+	}}}
+
+	assert.True(t, dk.RollbackAcknowledged("1.235.0"))
+	assert.True(t, dk.RollbackAcknowledged("1.236.0"))
+	assert.False(t, dk.RollbackAcknowledged("1.237.0"))
+	assert.False(t, dk.RollbackAcknowledged(""))
+	assert.False(t, (&DynaKube{}).RollbackAcknowledged(""))
 }
 
 func TestOneAgentDaemonsetName(t *testing.T) {
@@ -124,6 +173,61 @@ func TestTokens(t *testing.T) {
 	})
 }
 
+func TestSecretsCopiedFromSecretProvider(t *testing.T) {
+	newDynakube := func(provider *SecretProviderSpec) DynaKube {
+		return DynaKube{
+			ObjectMeta: metav1.ObjectMeta{Name: "dynakube"},
+			Spec: DynaKubeSpec{
+				CustomPullSecret: "custom-pull-secret",
+				Proxy:            &DynaKubeProxy{ValueFrom: "proxy-secret"},
+				SecretProvider:   provider,
+			},
+		}
+	}
+
+	t.Run(`without secret provider the referenced secrets are used`, func(t *testing.T) {
+		dk := newDynakube(nil)
+		assert.Equal(t, "custom-pull-secret", dk.PullSecret())
+		assert.Equal(t, "proxy-secret", dk.ProxySecret())
+		assert.False(t, dk.NeedsPullSecretCopy())
+		assert.False(t, dk.NeedsProxySecretCopy())
+	})
+	t.Run(`with secret provider the copies are used`, func(t *testing.T) {
+		dk := newDynakube(&SecretProviderSpec{File: &FileSecretProviderSpec{Path: "/mnt/secrets"}})
+		assert.Equal(t, "dynakube"+PullSecretSuffix, dk.PullSecret())
+		assert.Equal(t, "dynakube"+ProxySecretSuffix, dk.ProxySecret())
+		assert.True(t, dk.NeedsPullSecretCopy())
+		assert.True(t, dk.NeedsProxySecretCopy())
+	})
+}
+
+func TestTenantTargets(t *testing.T) {
+	dk := DynaKube{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-name"},
+		Spec: DynaKubeSpec{
+			AdditionalTenants: []TenantTargetSpec{
+				{Name: "migration"},
+				{Name: "custom", Tokens: "custom-tokens"},
+			},
+		},
+	}
+
+	t.Run(`TenantTargetTokens uses custom or default token name`, func(t *testing.T) {
+		assert.Equal(t, "test-name-migration", dk.TenantTargetTokens(*dk.TenantTarget("migration")))
+		assert.Equal(t, "custom-tokens", dk.TenantTargetTokens(*dk.TenantTarget("custom")))
+		assert.Nil(t, dk.TenantTarget("unknown"))
+	})
+	t.Run(`TenantTargetStatus adds missing status`, func(t *testing.T) {
+		dk := dk.DeepCopy()
+		assert.Empty(t, dk.TenantTargetUUID("migration"))
+
+		dk.TenantTargetStatus("migration").ConnectionInfo.TenantUUID = "uuid"
+		assert.Equal(t, "uuid", dk.TenantTargetUUID("migration"))
+		assert.Len(t, dk.Status.AdditionalTenants, 1)
+		assert.Equal(t, "uuid", dk.TenantTargetStatus("migration").ConnectionInfo.TenantUUID)
+	})
+}
+
 func TestTenantUUID(t *testing.T) {
 	t.Run("happy path", func(t *testing.T) {
 		apiUrl := "https://demo.dev.dynatracelabs.com/api"
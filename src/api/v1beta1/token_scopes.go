@@ -0,0 +1,104 @@
+package v1beta1
+
+import (
+	"github.com/Dynatrace/dynatrace-operator/src/dtclient"
+)
+
+const (
+	TokenFeatureTenantConnection        = "tenant connection"
+	TokenFeatureOneAgentDownload        = "OneAgent download"
+	TokenFeatureKubernetesApiMonitoring = "automatic Kubernetes API monitoring"
+	TokenFeatureDataIngest              = "data-ingest"
+	TokenFeatureSettings                = "settings objects"
+)
+
+// TokenScopeRequirement lists the scopes a feature of the DynaKube needs on one of the tokens.
+// +kubebuilder:object:generate=false
+type TokenScopeRequirement struct {
+	Feature string
+	Token   string
+	Scopes  []string
+}
+
+// MissingScopes returns the scopes of the requirement which are not in scopes.
+func (requirement TokenScopeRequirement) MissingScopes(scopes dtclient.TokenScopes) []string {
+	var missing []string
+	for _, scope := range requirement.Scopes {
+		if !scopes.Contains(scope) {
+			missing = append(missing, scope)
+		}
+	}
+	return missing
+}
+
+// RequiredTokenScopes returns the scopes which the features enabled on the DynaKube need on the tokens of the apiUrl tenant.
+// The installer download scope is needed on the API token if there is no PaaS token in the secret,
+// the data-ingest token is only checked if it's in the secret.
+func (dk *DynaKube) RequiredTokenScopes(hasPaasToken, hasDataIngestToken bool) []TokenScopeRequirement {
+	requirements := requiredTokenScopes(hasPaasToken, hasDataIngestToken)
+	if dk.KubernetesMonitoringMode() && dk.FeatureAutomaticKubernetesApiMonitoring() {
+		requirements = append(requirements, TokenScopeRequirement{
+			Feature: TokenFeatureKubernetesApiMonitoring,
+			Token:   dtclient.DynatraceApiToken,
+			Scopes: []string{
+				dtclient.TokenScopeEntitiesRead,
+				dtclient.TokenScopeSettingsRead,
+				dtclient.TokenScopeSettingsWrite,
+			},
+		})
+	}
+	if len(dk.Spec.Settings) > 0 {
+		requirements = append(requirements, TokenScopeRequirement{
+			Feature: TokenFeatureSettings,
+			Token:   dtclient.DynatraceApiToken,
+			Scopes:  dk.settingsTokenScopes(),
+		})
+	}
+	return requirements
+}
+
+// settingsTokenScopes returns the scopes needed to manage the settings objects, the entities are read to find
+// the Kubernetes cluster entity, which is the default scope.
+func (dk *DynaKube) settingsTokenScopes() []string {
+	for _, setting := range dk.Spec.Settings {
+		if setting.Scope == "" {
+			return []string{dtclient.TokenScopeEntitiesRead, dtclient.TokenScopeSettingsRead, dtclient.TokenScopeSettingsWrite}
+		}
+	}
+	return []string{dtclient.TokenScopeSettingsRead, dtclient.TokenScopeSettingsWrite}
+}
+
+// TenantTargetRequiredTokenScopes returns the scopes needed on the tokens of an additional tenant.
+// The ActiveGate only connects to the apiUrl tenant, so features of the ActiveGate don't need any scopes.
+func (dk *DynaKube) TenantTargetRequiredTokenScopes(hasPaasToken, hasDataIngestToken bool) []TokenScopeRequirement {
+	return requiredTokenScopes(hasPaasToken, hasDataIngestToken)
+}
+
+func requiredTokenScopes(hasPaasToken, hasDataIngestToken bool) []TokenScopeRequirement {
+	downloadToken := dtclient.DynatraceApiToken
+	if hasPaasToken {
+		downloadToken = dtclient.DynatracePaasToken
+	}
+
+	requirements := []TokenScopeRequirement{
+		{
+			Feature: TokenFeatureTenantConnection,
+			Token:   dtclient.DynatraceApiToken,
+			Scopes:  []string{dtclient.TokenScopeDataExport},
+		},
+		{
+			Feature: TokenFeatureOneAgentDownload,
+			Token:   downloadToken,
+			Scopes:  []string{dtclient.TokenScopeInstallerDownload},
+		},
+	}
+
+	if hasDataIngestToken {
+		requirements = append(requirements, TokenScopeRequirement{
+			Feature: TokenFeatureDataIngest,
+			Token:   dtclient.DynatraceDataIngestToken,
+			Scopes:  []string{dtclient.TokenScopeMetricsIngest},
+		})
+	}
+	return requirements
+}
@@ -0,0 +1,93 @@
+package v1beta1
+
+import (
+	"testing"
+
+	"github.com/Dynatrace/dynatrace-operator/src/dtclient"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestRequiredTokenScopes(t *testing.T) {
+	kubeMonDynakube := func() DynaKube {
+		return DynaKube{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{annotationFeatureAutomaticKubernetesApiMonitoring: "true"},
+			},
+			Spec: DynaKubeSpec{
+				ActiveGate: ActiveGateSpec{
+					Capabilities: []CapabilityDisplayName{KubeMonCapability.DisplayName},
+				},
+			},
+		}
+	}
+
+	t.Run(`installer download on paas token`, func(t *testing.T) {
+		dk := DynaKube{}
+		requirements := dk.RequiredTokenScopes(true, false)
+
+		assert.Equal(t, []TokenScopeRequirement{
+			{Feature: TokenFeatureTenantConnection, Token: dtclient.DynatraceApiToken, Scopes: []string{dtclient.TokenScopeDataExport}},
+			{Feature: TokenFeatureOneAgentDownload, Token: dtclient.DynatracePaasToken, Scopes: []string{dtclient.TokenScopeInstallerDownload}},
+		}, requirements)
+	})
+	t.Run(`installer download on api token without paas token`, func(t *testing.T) {
+		dk := DynaKube{}
+		requirements := dk.RequiredTokenScopes(false, false)
+
+		assert.Len(t, requirements, 2)
+		assert.Equal(t, dtclient.DynatraceApiToken, requirements[1].Token)
+	})
+	t.Run(`data-ingest scopes if token is present`, func(t *testing.T) {
+		dk := DynaKube{}
+		requirements := dk.RequiredTokenScopes(true, true)
+
+		assert.Contains(t, requirements, TokenScopeRequirement{
+			Feature: TokenFeatureDataIngest, Token: dtclient.DynatraceDataIngestToken, Scopes: []string{dtclient.TokenScopeMetricsIngest},
+		})
+	})
+	t.Run(`settings scopes for automatic kubernetes api monitoring`, func(t *testing.T) {
+		dk := kubeMonDynakube()
+		requirements := dk.RequiredTokenScopes(true, false)
+
+		assert.Contains(t, requirements, TokenScopeRequirement{
+			Feature: TokenFeatureKubernetesApiMonitoring,
+			Token:   dtclient.DynatraceApiToken,
+			Scopes:  []string{dtclient.TokenScopeEntitiesRead, dtclient.TokenScopeSettingsRead, dtclient.TokenScopeSettingsWrite},
+		})
+	})
+	t.Run(`no settings scopes without feature flag`, func(t *testing.T) {
+		dk := kubeMonDynakube()
+		dk.Annotations = nil
+
+		assert.Len(t, dk.RequiredTokenScopes(true, false), 2)
+	})
+	t.Run(`no settings scopes for tenant targets`, func(t *testing.T) {
+		dk := kubeMonDynakube()
+
+		assert.Len(t, dk.TenantTargetRequiredTokenScopes(true, false), 2)
+	})
+	t.Run(`settings scopes for settings objects`, func(t *testing.T) {
+		dk := DynaKube{Spec: DynaKubeSpec{Settings: []SettingsObjectSpec{{Name: "anomaly-detection", Scope: SettingsScopeEnvironment}}}}
+
+		assert.Contains(t, dk.RequiredTokenScopes(true, false), TokenScopeRequirement{
+			Feature: TokenFeatureSettings,
+			Token:   dtclient.DynatraceApiToken,
+			Scopes:  []string{dtclient.TokenScopeSettingsRead, dtclient.TokenScopeSettingsWrite},
+		})
+
+		dk.Spec.Settings = append(dk.Spec.Settings, SettingsObjectSpec{Name: "cluster-anomaly-detection"})
+		assert.Contains(t, dk.RequiredTokenScopes(true, false), TokenScopeRequirement{
+			Feature: TokenFeatureSettings,
+			Token:   dtclient.DynatraceApiToken,
+			Scopes:  []string{dtclient.TokenScopeEntitiesRead, dtclient.TokenScopeSettingsRead, dtclient.TokenScopeSettingsWrite},
+		})
+	})
+}
+
+func TestTokenScopeRequirement_MissingScopes(t *testing.T) {
+	requirement := TokenScopeRequirement{Scopes: []string{dtclient.TokenScopeSettingsRead, dtclient.TokenScopeSettingsWrite}}
+
+	assert.Empty(t, requirement.MissingScopes(dtclient.TokenScopes{dtclient.TokenScopeSettingsRead, dtclient.TokenScopeSettingsWrite}))
+	assert.Equal(t, []string{dtclient.TokenScopeSettingsWrite}, requirement.MissingScopes(dtclient.TokenScopes{dtclient.TokenScopeSettingsRead}))
+}
@@ -81,6 +81,16 @@ func (in *ActiveGateStatus) DeepCopy() *ActiveGateStatus {
 func (in *AppInjectionSpec) DeepCopyInto(out *AppInjectionSpec) {
 	*out = *in
 	in.InitResources.DeepCopyInto(&out.InitResources)
+	if in.CodeModulesSource != nil {
+		in, out := &in.CodeModulesSource, &out.CodeModulesSource
+		*out = new(CodeModulesSourceSpec)
+		**out = **in
+	}
+	if in.CodeModulesPrePull != nil {
+		in, out := &in.CodeModulesPrePull, &out.CodeModulesPrePull
+		*out = new(CodeModulesPrePullSpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AppInjectionSpec.
@@ -201,6 +211,51 @@ func (in *CloudNativeFullStackSpec) DeepCopy() *CloudNativeFullStackSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CodeModulesPrePullSpec) DeepCopyInto(out *CodeModulesPrePullSpec) {
+	*out = *in
+	if in.Versions != nil {
+		in, out := &in.Versions, &out.Versions
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Flavors != nil {
+		in, out := &in.Flavors, &out.Flavors
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Architectures != nil {
+		in, out := &in.Architectures, &out.Architectures
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CodeModulesPrePullSpec.
+func (in *CodeModulesPrePullSpec) DeepCopy() *CodeModulesPrePullSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CodeModulesPrePullSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CodeModulesSourceSpec) DeepCopyInto(out *CodeModulesSourceSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CodeModulesSourceSpec.
+func (in *CodeModulesSourceSpec) DeepCopy() *CodeModulesSourceSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CodeModulesSourceSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CommunicationHostStatus) DeepCopyInto(out *CommunicationHostStatus) {
 	*out = *in
@@ -295,6 +350,106 @@ func (in *DynaKubeList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DynaKubeNode) DeepCopyInto(out *DynaKubeNode) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DynaKubeNode.
+func (in *DynaKubeNode) DeepCopy() *DynaKubeNode {
+	if in == nil {
+		return nil
+	}
+	out := new(DynaKubeNode)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DynaKubeNode) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DynaKubeNodeList) DeepCopyInto(out *DynaKubeNodeList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]DynaKubeNode, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DynaKubeNodeList.
+func (in *DynaKubeNodeList) DeepCopy() *DynaKubeNodeList {
+	if in == nil {
+		return nil
+	}
+	out := new(DynaKubeNodeList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DynaKubeNodeList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DynaKubeNodeSpec) DeepCopyInto(out *DynaKubeNodeSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DynaKubeNodeSpec.
+func (in *DynaKubeNodeSpec) DeepCopy() *DynaKubeNodeSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DynaKubeNodeSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DynaKubeNodeStatus) DeepCopyInto(out *DynaKubeNodeStatus) {
+	*out = *in
+	if in.RemovedTimestamp != nil {
+		in, out := &in.RemovedTimestamp, &out.RemovedTimestamp
+		*out = (*in).DeepCopy()
+	}
+	if in.TerminationEvents != nil {
+		in, out := &in.TerminationEvents, &out.TerminationEvents
+		*out = make([]NodeTerminationEvent, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DynaKubeNodeStatus.
+func (in *DynaKubeNodeStatus) DeepCopy() *DynaKubeNodeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DynaKubeNodeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DynaKubeProxy) DeepCopyInto(out *DynaKubeProxy) {
 	*out = *in
@@ -318,7 +473,31 @@ func (in *DynaKubeSpec) DeepCopyInto(out *DynaKubeSpec) {
 		*out = new(DynaKubeProxy)
 		**out = **in
 	}
+	if in.AdditionalTenants != nil {
+		in, out := &in.AdditionalTenants, &out.AdditionalTenants
+		*out = make([]TenantTargetSpec, len(*in))
+		copy(*out, *in)
+	}
 	in.NamespaceSelector.DeepCopyInto(&out.NamespaceSelector)
+	in.MetadataEnrichment.DeepCopyInto(&out.MetadataEnrichment)
+	in.NodeEvents.DeepCopyInto(&out.NodeEvents)
+	if in.MaintenanceWindows != nil {
+		in, out := &in.MaintenanceWindows, &out.MaintenanceWindows
+		*out = make([]MaintenanceWindowSpec, len(*in))
+		copy(*out, *in)
+	}
+	if in.SecretProvider != nil {
+		in, out := &in.SecretProvider, &out.SecretProvider
+		*out = new(SecretProviderSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Settings != nil {
+		in, out := &in.Settings, &out.Settings
+		*out = make([]SettingsObjectSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	in.OneAgent.DeepCopyInto(&out.OneAgent)
 	in.ActiveGate.DeepCopyInto(&out.ActiveGate)
 	in.Routing.DeepCopyInto(&out.Routing)
@@ -356,6 +535,13 @@ func (in *DynaKubeStatus) DeepCopyInto(out *DynaKubeStatus) {
 		*out = (*in).DeepCopy()
 	}
 	in.ConnectionInfo.DeepCopyInto(&out.ConnectionInfo)
+	if in.AdditionalTenants != nil {
+		in, out := &in.AdditionalTenants, &out.AdditionalTenants
+		*out = make([]TenantTargetStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	out.CommunicationHostForClient = in.CommunicationHostForClient
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
@@ -364,6 +550,17 @@ func (in *DynaKubeStatus) DeepCopyInto(out *DynaKubeStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.UninjectedWorkloads != nil {
+		in, out := &in.UninjectedWorkloads, &out.UninjectedWorkloads
+		*out = make([]UninjectedWorkloadStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.LastUninjectedWorkloadsScanTimestamp != nil {
+		in, out := &in.LastUninjectedWorkloadsScanTimestamp, &out.LastUninjectedWorkloadsScanTimestamp
+		*out = (*in).DeepCopy()
+	}
+	in.TokenRotation.DeepCopyInto(&out.TokenRotation)
+	in.Settings.DeepCopyInto(&out.Settings)
 	in.ActiveGate.DeepCopyInto(&out.ActiveGate)
 	in.ExtensionController.DeepCopyInto(&out.ExtensionController)
 	in.Statsd.DeepCopyInto(&out.Statsd)
@@ -411,6 +608,36 @@ func (in *EecStatus) DeepCopy() *EecStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EnrichmentRule) DeepCopyInto(out *EnrichmentRule) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EnrichmentRule.
+func (in *EnrichmentRule) DeepCopy() *EnrichmentRule {
+	if in == nil {
+		return nil
+	}
+	out := new(EnrichmentRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FileSecretProviderSpec) DeepCopyInto(out *FileSecretProviderSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FileSecretProviderSpec.
+func (in *FileSecretProviderSpec) DeepCopy() *FileSecretProviderSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(FileSecretProviderSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *HostInjectSpec) DeepCopyInto(out *HostInjectSpec) {
 	*out = *in
@@ -453,6 +680,11 @@ func (in *HostInjectSpec) DeepCopyInto(out *HostInjectSpec) {
 			(*out)[key] = val
 		}
 	}
+	if in.Rollout != nil {
+		in, out := &in.Rollout, &out.Rollout
+		*out = new(RolloutSpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HostInjectSpec.
@@ -497,6 +729,90 @@ func (in *KubernetesMonitoringSpec) DeepCopy() *KubernetesMonitoringSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MaintenanceWindowSpec) DeepCopyInto(out *MaintenanceWindowSpec) {
+	*out = *in
+	out.Duration = in.Duration
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MaintenanceWindowSpec.
+func (in *MaintenanceWindowSpec) DeepCopy() *MaintenanceWindowSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MaintenanceWindowSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetadataEnrichmentSpec) DeepCopyInto(out *MetadataEnrichmentSpec) {
+	*out = *in
+	if in.Rules != nil {
+		in, out := &in.Rules, &out.Rules
+		*out = make([]EnrichmentRule, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetadataEnrichmentSpec.
+func (in *MetadataEnrichmentSpec) DeepCopy() *MetadataEnrichmentSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MetadataEnrichmentSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeEventsSpec) DeepCopyInto(out *NodeEventsSpec) {
+	*out = *in
+	if in.Events != nil {
+		in, out := &in.Events, &out.Events
+		*out = make([]NodeEventType, len(*in))
+		copy(*out, *in)
+	}
+	if in.CustomProperties != nil {
+		in, out := &in.CustomProperties, &out.CustomProperties
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.InterruptionTaints != nil {
+		in, out := &in.InterruptionTaints, &out.InterruptionTaints
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeEventsSpec.
+func (in *NodeEventsSpec) DeepCopy() *NodeEventsSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeEventsSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeTerminationEvent) DeepCopyInto(out *NodeTerminationEvent) {
+	*out = *in
+	in.Timestamp.DeepCopyInto(&out.Timestamp)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeTerminationEvent.
+func (in *NodeTerminationEvent) DeepCopy() *NodeTerminationEvent {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeTerminationEvent)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *OneAgentInstance) DeepCopyInto(out *OneAgentInstance) {
 	*out = *in
@@ -512,6 +828,29 @@ func (in *OneAgentInstance) DeepCopy() *OneAgentInstance {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OneAgentRolloutStatus) DeepCopyInto(out *OneAgentRolloutStatus) {
+	*out = *in
+	if in.StageStartedTimestamp != nil {
+		in, out := &in.StageStartedTimestamp, &out.StageStartedTimestamp
+		*out = (*in).DeepCopy()
+	}
+	if in.StageReadyTimestamp != nil {
+		in, out := &in.StageReadyTimestamp, &out.StageReadyTimestamp
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OneAgentRolloutStatus.
+func (in *OneAgentRolloutStatus) DeepCopy() *OneAgentRolloutStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(OneAgentRolloutStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *OneAgentSpec) DeepCopyInto(out *OneAgentSpec) {
 	*out = *in
@@ -562,6 +901,11 @@ func (in *OneAgentStatus) DeepCopyInto(out *OneAgentStatus) {
 		in, out := &in.LastHostsRequestTimestamp, &out.LastHostsRequestTimestamp
 		*out = (*in).DeepCopy()
 	}
+	if in.Rollout != nil {
+		in, out := &in.Rollout, &out.Rollout
+		*out = new(OneAgentRolloutStatus)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OneAgentStatus.
@@ -574,6 +918,76 @@ func (in *OneAgentStatus) DeepCopy() *OneAgentStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RollbackStatus) DeepCopyInto(out *RollbackStatus) {
+	*out = *in
+	in.Timestamp.DeepCopyInto(&out.Timestamp)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RollbackStatus.
+func (in *RollbackStatus) DeepCopy() *RollbackStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RollbackStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RolloutSpec) DeepCopyInto(out *RolloutSpec) {
+	*out = *in
+	if in.Stages != nil {
+		in, out := &in.Stages, &out.Stages
+		*out = make([]RolloutStage, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.SoakTime != nil {
+		in, out := &in.SoakTime, &out.SoakTime
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.ProgressDeadline != nil {
+		in, out := &in.ProgressDeadline, &out.ProgressDeadline
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutSpec.
+func (in *RolloutSpec) DeepCopy() *RolloutSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RolloutSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RolloutStage) DeepCopyInto(out *RolloutStage) {
+	*out = *in
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutStage.
+func (in *RolloutStage) DeepCopy() *RolloutStage {
+	if in == nil {
+		return nil
+	}
+	out := new(RolloutStage)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RoutingSpec) DeepCopyInto(out *RoutingSpec) {
 	*out = *in
@@ -590,6 +1004,91 @@ func (in *RoutingSpec) DeepCopy() *RoutingSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecretProviderSpec) DeepCopyInto(out *SecretProviderSpec) {
+	*out = *in
+	if in.Vault != nil {
+		in, out := &in.Vault, &out.Vault
+		*out = new(VaultSecretProviderSpec)
+		**out = **in
+	}
+	if in.File != nil {
+		in, out := &in.File, &out.File
+		*out = new(FileSecretProviderSpec)
+		**out = **in
+	}
+	if in.RefreshInterval != nil {
+		in, out := &in.RefreshInterval, &out.RefreshInterval
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretProviderSpec.
+func (in *SecretProviderSpec) DeepCopy() *SecretProviderSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SecretProviderSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SettingsObjectSpec) DeepCopyInto(out *SettingsObjectSpec) {
+	*out = *in
+	in.Value.DeepCopyInto(&out.Value)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SettingsObjectSpec.
+func (in *SettingsObjectSpec) DeepCopy() *SettingsObjectSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SettingsObjectSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SettingsObjectStatus) DeepCopyInto(out *SettingsObjectStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SettingsObjectStatus.
+func (in *SettingsObjectStatus) DeepCopy() *SettingsObjectStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SettingsObjectStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SettingsStatus) DeepCopyInto(out *SettingsStatus) {
+	*out = *in
+	if in.LastCheckTimestamp != nil {
+		in, out := &in.LastCheckTimestamp, &out.LastCheckTimestamp
+		*out = (*in).DeepCopy()
+	}
+	if in.Objects != nil {
+		in, out := &in.Objects, &out.Objects
+		*out = make([]SettingsObjectStatus, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SettingsStatus.
+func (in *SettingsStatus) DeepCopy() *SettingsStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SettingsStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *StatsdStatus) DeepCopyInto(out *StatsdStatus) {
 	*out = *in
@@ -606,6 +1105,115 @@ func (in *StatsdStatus) DeepCopy() *StatsdStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TenantTargetSpec) DeepCopyInto(out *TenantTargetSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TenantTargetSpec.
+func (in *TenantTargetSpec) DeepCopy() *TenantTargetSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(TenantTargetSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TenantTargetStatus) DeepCopyInto(out *TenantTargetStatus) {
+	*out = *in
+	if in.LastAPITokenProbeTimestamp != nil {
+		in, out := &in.LastAPITokenProbeTimestamp, &out.LastAPITokenProbeTimestamp
+		*out = (*in).DeepCopy()
+	}
+	if in.LastPaaSTokenProbeTimestamp != nil {
+		in, out := &in.LastPaaSTokenProbeTimestamp, &out.LastPaaSTokenProbeTimestamp
+		*out = (*in).DeepCopy()
+	}
+	if in.LastDataIngestTokenProbeTimestamp != nil {
+		in, out := &in.LastDataIngestTokenProbeTimestamp, &out.LastDataIngestTokenProbeTimestamp
+		*out = (*in).DeepCopy()
+	}
+	in.ConnectionInfo.DeepCopyInto(&out.ConnectionInfo)
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TenantTargetStatus.
+func (in *TenantTargetStatus) DeepCopy() *TenantTargetStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(TenantTargetStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TokenRotationStatus) DeepCopyInto(out *TokenRotationStatus) {
+	*out = *in
+	if in.LastRotationTimestamp != nil {
+		in, out := &in.LastRotationTimestamp, &out.LastRotationTimestamp
+		*out = (*in).DeepCopy()
+	}
+	if in.UpdatedSecrets != nil {
+		in, out := &in.UpdatedSecrets, &out.UpdatedSecrets
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.OutdatedSecrets != nil {
+		in, out := &in.OutdatedSecrets, &out.OutdatedSecrets
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TokenRotationStatus.
+func (in *TokenRotationStatus) DeepCopy() *TokenRotationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(TokenRotationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UninjectedWorkloadStatus) DeepCopyInto(out *UninjectedWorkloadStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UninjectedWorkloadStatus.
+func (in *UninjectedWorkloadStatus) DeepCopy() *UninjectedWorkloadStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(UninjectedWorkloadStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VaultSecretProviderSpec) DeepCopyInto(out *VaultSecretProviderSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultSecretProviderSpec.
+func (in *VaultSecretProviderSpec) DeepCopy() *VaultSecretProviderSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(VaultSecretProviderSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VersionStatus) DeepCopyInto(out *VersionStatus) {
 	*out = *in
@@ -613,6 +1221,11 @@ func (in *VersionStatus) DeepCopyInto(out *VersionStatus) {
 		in, out := &in.LastUpdateProbeTimestamp, &out.LastUpdateProbeTimestamp
 		*out = (*in).DeepCopy()
 	}
+	if in.RolledBack != nil {
+		in, out := &in.RolledBack, &out.RolledBack
+		*out = new(RollbackStatus)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VersionStatus.
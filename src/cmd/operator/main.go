@@ -21,6 +21,7 @@ import (
 
 	"github.com/Dynatrace/dynatrace-operator/src/kubesystem"
 	"github.com/Dynatrace/dynatrace-operator/src/logger"
+	"github.com/Dynatrace/dynatrace-operator/src/secretprovider"
 	"github.com/Dynatrace/dynatrace-operator/src/version"
 	"github.com/spf13/pflag"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
@@ -38,17 +39,33 @@ const (
 	csiDriverCmd     = "csi-driver"
 	standaloneCmd    = "init"
 	webhookServerCmd = "webhook-server"
+	mutateCmd        = "mutate"
+	troubleshootCmd  = "troubleshoot"
 )
 
-var errBadSubcmd = fmt.Errorf("subcommand must be %s, %s, %s or %s", operatorCmd, csiDriverCmd, webhookServerCmd, standaloneCmd)
+var errBadSubcmd = fmt.Errorf("subcommand must be %s, %s, %s, %s, %s or %s", operatorCmd, csiDriverCmd, webhookServerCmd, standaloneCmd, mutateCmd, troubleshootCmd)
 
 func main() {
 	pflag.CommandLine.AddFlagSet(webhookServerFlags())
 	pflag.CommandLine.AddFlagSet(csiDriverFlags())
+	pflag.CommandLine.AddFlagSet(mutateFlags())
+	pflag.CommandLine.AddFlagSet(troubleshootFlags())
 	pflag.Parse()
 
 	ctrl.SetLogger(log)
 
+	// runs offline, so it doesn't need the cluster config
+	if getSubCommand() == mutateCmd {
+		exitOnError(startMutate(), "mutate command failed")
+		os.Exit(0)
+	}
+
+	// prints its results to stdout, so the version isn't logged
+	if getSubCommand() == troubleshootCmd {
+		exitOnError(startTroubleshoot(), "troubleshoot command failed")
+		os.Exit(0)
+	}
+
 	version.LogVersion()
 
 	namespace := os.Getenv("POD_NAMESPACE")
@@ -57,6 +74,7 @@ func main() {
 		log.Error(err, "")
 		os.Exit(1)
 	}
+	exitOnError(secretprovider.SetupServiceAccountTokens(cfg), "failed to set up service account tokens")
 
 	var mgr manager.Manager
 	var cleanUp func()
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	"github.com/Dynatrace/dynatrace-operator/src/version"
+	"github.com/Dynatrace/dynatrace-operator/src/webhook/mutation"
+	"github.com/pkg/errors"
+	"github.com/spf13/pflag"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/yaml"
+)
+
+var (
+	mutateDryRun    bool
+	mutatePodFile   string
+	mutateDynakube  string
+	mutateNamespace string
+	mutateImage     string
+	mutateOutput    string
+)
+
+func mutateFlags() *pflag.FlagSet {
+	mutateFlagSet := pflag.NewFlagSet("mutate", pflag.ExitOnError)
+	mutateFlagSet.BoolVar(&mutateDryRun, "dry-run", false, "Print the JSON patch of the injection instead of applying it, required.")
+	mutateFlagSet.StringVarP(&mutatePodFile, "filename", "f", "", "File with the Pod to inject, in YAML or JSON.")
+	mutateFlagSet.StringVar(&mutateDynakube, "dynakube", "", "File with the DynaKube the namespace of the Pod is mapped to, in YAML or JSON.")
+	mutateFlagSet.StringVar(&mutateNamespace, "pod-namespace", "default", "Namespace the Pod is created in.")
+	mutateFlagSet.StringVar(&mutateImage, "image", "docker.io/dynatrace/dynatrace-operator:"+version.Version, "Image used for the install container.")
+	mutateFlagSet.StringVarP(&mutateOutput, "output", "o", "-", "File to write the JSON patch to, defaults to stdout.")
+	return mutateFlagSet
+}
+
+// startMutate runs the pod mutation webhook offline, without connecting to the cluster.
+func startMutate() error {
+	if !mutateDryRun {
+		return errors.New("only --dry-run is supported")
+	} else if mutatePodFile == "" || mutateDynakube == "" {
+		return errors.New("--filename and --dynakube are required")
+	}
+
+	var pod corev1.Pod
+	if err := decodeFile(mutatePodFile, &pod); err != nil {
+		return err
+	}
+	var dk dynatracev1beta1.DynaKube
+	if err := decodeFile(mutateDynakube, &dk); err != nil {
+		return err
+	}
+
+	patch, err := mutation.DryRun(context.TODO(), &dk, &pod, mutateNamespace, mutateImage)
+	if err != nil {
+		return err
+	}
+
+	var output io.Writer = os.Stdout
+	if mutateOutput != "-" {
+		file, err := os.Create(mutateOutput)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = file.Close() }()
+		output = file
+	}
+	_, err = fmt.Fprintln(output, string(patch))
+	return err
+}
+
+func decodeFile(path string, into interface{}) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = file.Close() }()
+
+	if err := yaml.NewYAMLOrJSONDecoder(file, 4096).Decode(into); err != nil {
+		return errors.WithMessagef(err, "failed to decode %s", path)
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"os"
+
+	"github.com/Dynatrace/dynatrace-operator/src/scheme"
+	"github.com/Dynatrace/dynatrace-operator/src/troubleshoot"
+	"github.com/pkg/errors"
+	"github.com/spf13/pflag"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/config"
+)
+
+const (
+	troubleshootFormatText = "text"
+	troubleshootFormatJSON = "json"
+)
+
+var (
+	troubleshootDynakube  string
+	troubleshootNamespace string
+	troubleshootFormat    string
+)
+
+func troubleshootFlags() *pflag.FlagSet {
+	troubleshootFlagSet := pflag.NewFlagSet("troubleshoot", pflag.ExitOnError)
+	troubleshootFlagSet.StringVar(&troubleshootDynakube, "dynakube-name", "dynakube", "Name of the DynaKube to check.")
+	troubleshootFlagSet.StringVarP(&troubleshootNamespace, "namespace", "n", "dynatrace", "Namespace the operator and the DynaKube are deployed in.")
+	troubleshootFlagSet.StringVar(&troubleshootFormat, "format", troubleshootFormatText, "Format of the results, text or json.")
+	return troubleshootFlagSet
+}
+
+// startTroubleshoot checks the installation of the operator in the cluster of the current kubeconfig for known issues.
+func startTroubleshoot() error {
+	if troubleshootFormat != troubleshootFormatText && troubleshootFormat != troubleshootFormatJSON {
+		return errors.Errorf("--format must be %s or %s", troubleshootFormatText, troubleshootFormatJSON)
+	}
+
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return err
+	}
+	clt, err := client.New(cfg, client.Options{Scheme: scheme.Scheme})
+	if err != nil {
+		return err
+	}
+
+	results := troubleshoot.NewTroubleshooter(context.TODO(), clt, troubleshootNamespace, troubleshootDynakube).Run()
+
+	if troubleshootFormat == troubleshootFormatJSON {
+		err = troubleshoot.WriteJSON(os.Stdout, results)
+	} else {
+		err = troubleshoot.WriteText(os.Stdout, results)
+	}
+	if err != nil {
+		return err
+	}
+
+	if troubleshoot.HasFailures(results) {
+		return errors.New("known issues found")
+	}
+	return nil
+}
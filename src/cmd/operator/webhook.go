@@ -19,8 +19,8 @@ package main
 import (
 	"github.com/Dynatrace/dynatrace-operator/src/api/v1alpha1"
 	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/certificates"
 	"github.com/Dynatrace/dynatrace-operator/src/kubesystem"
-	"github.com/Dynatrace/dynatrace-operator/src/webhook"
 	"github.com/Dynatrace/dynatrace-operator/src/webhook/mutation"
 	"github.com/spf13/pflag"
 	"k8s.io/client-go/rest"
@@ -48,7 +48,11 @@ func setupWebhookServer(ns string, cfg *rest.Config) (manager.Manager, func(), e
 	}
 
 	if !kubesystem.DeployedViaOLM() {
-		waitForCertificates(newCertificateWatcher(mgr, ns, webhook.SecretCertsName))
+		secretName, err := certificates.CertificateSecretName()
+		if err != nil {
+			return nil, cleanUp, err
+		}
+		waitForCertificates(newCertificateWatcher(mgr, ns, secretName))
 	}
 
 	if err := mutation.AddNamespaceMutationWebhookToManager(mgr, ns); err != nil {
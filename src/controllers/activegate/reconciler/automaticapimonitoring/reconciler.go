@@ -59,7 +59,7 @@ func (r *AutomaticApiMonitoringReconciler) ensureSettingExists() (string, error)
 	}
 
 	// determine newest ME (can be empty string), and create or update a settings object accordingly
-	meID := determineNewestMonitoredEntity(monitoredEntities)
+	meID := dtclient.NewestMonitoredEntity(monitoredEntities)
 	objectID, err := r.dtc.CreateOrUpdateKubernetesSetting(r.name, r.kubeSystemUUID, meID)
 
 	if err != nil {
@@ -68,19 +68,3 @@ func (r *AutomaticApiMonitoringReconciler) ensureSettingExists() (string, error)
 
 	return objectID, nil
 }
-
-// determineNewestMonitoredEntity returns the UUID of the newest entities; or empty string if the slice of entities is empty
-func determineNewestMonitoredEntity(entities []dtclient.MonitoredEntity) string {
-	if len(entities) == 0 {
-		return ""
-	}
-
-	var newestMe dtclient.MonitoredEntity
-	for _, entity := range entities {
-		if entity.LastSeenTms > newestMe.LastSeenTms {
-			newestMe = entity
-		}
-	}
-
-	return newestMe.EntityId
-}
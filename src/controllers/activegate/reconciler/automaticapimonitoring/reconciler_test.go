@@ -163,24 +163,3 @@ func TestReconcileErrors(t *testing.T) {
 		assert.Equal(t, "", actual)
 	})
 }
-
-func TestDetermineNewestMonitoredEntity(t *testing.T) {
-	t.Run(`newest monitored entity is correctly calculated`, func(t *testing.T) {
-		// arrange
-		// explicit create of entities here to visualize that one has the newest LastSeenTimestamp
-		// here it is the first one
-		entities := []dtclient.MonitoredEntity{
-			{EntityId: "KUBERNETES_CLUSTER-0E30FE4BF2007587", DisplayName: "operator test entity newest", LastSeenTms: 1639483869085},
-			{EntityId: "KUBERNETES_CLUSTER-119C75CCDA94799F", DisplayName: "operator test entity 1", LastSeenTms: 1639034988126},
-			{EntityId: "KUBERNETES_CLUSTER-119C75CCDA947993", DisplayName: "operator test entity 2", LastSeenTms: 1639134988126},
-			{EntityId: "KUBERNETES_CLUSTER-119C75CCDA94799D", DisplayName: "operator test entity 3", LastSeenTms: 1639234988126},
-		}
-
-		// act
-		newestEntity := determineNewestMonitoredEntity(entities)
-
-		// assert
-		assert.NotNil(t, newestEntity)
-		assert.Equal(t, entities[0].EntityId, newestEntity)
-	})
-}
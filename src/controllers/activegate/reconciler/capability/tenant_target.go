@@ -0,0 +1,37 @@
+package capability
+
+import (
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/activegate/capability"
+	sts "github.com/Dynatrace/dynatrace-operator/src/controllers/activegate/reconciler/statefulset"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// tenantTargetCapability names the objects of the capability after the tenant target, so they don't collide with the ones of the primary tenant.
+// The service account of the capability is shared.
+type tenantTargetCapability struct {
+	capability.Capability
+	target string
+}
+
+func (c tenantTargetCapability) ShortName() string {
+	return c.target + "-" + c.Capability.ShortName()
+}
+
+func (c tenantTargetCapability) Config() capability.Configuration {
+	config := c.Capability.Config()
+	if config.ServiceAccountOwner == "" {
+		config.ServiceAccountOwner = c.Capability.ShortName()
+	}
+	return config
+}
+
+// NewTenantTargetReconciler reconciles the ActiveGate of the capability reporting to the additional tenant,
+// whose ActiveGate tenant secret has to exist.
+func NewTenantTargetReconciler(capability capability.Capability, target string, clt client.Client, apiReader client.Reader, scheme *runtime.Scheme,
+	instance *dynatracev1beta1.DynaKube) *Reconciler {
+	r := NewReconciler(tenantTargetCapability{Capability: capability, target: target}, clt, apiReader, scheme, instance)
+	r.AddOnAfterStatefulSetCreateListener(sts.UseTenantTargetSecret(instance, target))
+	return r
+}
@@ -0,0 +1,78 @@
+package capability
+
+import (
+	"context"
+	"testing"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/activegate/capability"
+	"github.com/Dynatrace/dynatrace-operator/src/kubesystem"
+	"github.com/Dynatrace/dynatrace-operator/src/scheme"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestNewTenantTargetReconciler(t *testing.T) {
+	const target = "migration"
+
+	clt := fake.NewClientBuilder().
+		WithScheme(scheme.Scheme).
+		WithObjects(&corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: kubesystem.Namespace,
+				UID:  testUID,
+			},
+		}).
+		Build()
+	instance := &dynatracev1beta1.DynaKube{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: testNamespace,
+			Name:      testName,
+		},
+		Spec: dynatracev1beta1.DynaKubeSpec{
+			APIURL: "https://testing.dev.dynatracelabs.com/api",
+			ActiveGate: dynatracev1beta1.ActiveGateSpec{
+				Capabilities: []dynatracev1beta1.CapabilityDisplayName{
+					dynatracev1beta1.KubeMonCapability.DisplayName,
+					dynatracev1beta1.RoutingCapability.DisplayName,
+				},
+			},
+		},
+	}
+	multiCapability := capability.NewMultiCapability(instance)
+
+	reconcileUntilUpToDate := func(r *Reconciler) {
+		for update := true; update; {
+			var err error
+			update, err = r.Reconcile()
+			require.NoError(t, err)
+		}
+	}
+	reconcileUntilUpToDate(NewReconciler(multiCapability, clt, clt, scheme.Scheme, instance))
+	reconcileUntilUpToDate(NewTenantTargetReconciler(multiCapability, target, clt, clt, scheme.Scheme, instance))
+
+	var primarySts, targetSts appsv1.StatefulSet
+	require.NoError(t, clt.Get(context.TODO(), client.ObjectKey{Name: testName + "-activegate", Namespace: testNamespace}, &primarySts))
+	require.NoError(t, clt.Get(context.TODO(), client.ObjectKey{Name: testName + "-" + target + "-activegate", Namespace: testNamespace}, &targetSts))
+
+	assert.Equal(t, target, targetSts.Spec.Template.Labels[dynatracev1beta1.LabelTenantTarget])
+	assert.NotContains(t, primarySts.Spec.Template.Labels, dynatracev1beta1.LabelTenantTarget)
+	assert.NotEqual(t, primarySts.Spec.Selector.MatchLabels, targetSts.Spec.Selector.MatchLabels)
+	assert.Equal(t, primarySts.Spec.Template.Spec.ServiceAccountName, targetSts.Spec.Template.Spec.ServiceAccountName)
+
+	var targetSvc corev1.Service
+	require.NoError(t, clt.Get(context.TODO(), client.ObjectKey{Name: targetSts.Name, Namespace: testNamespace}, &targetSvc))
+	assert.Equal(t, targetSts.Spec.Selector.MatchLabels, targetSvc.Spec.Selector)
+
+	activeGateContainer, err := getActiveGateContainer(&targetSts)
+	require.NoError(t, err)
+	assert.Contains(t, activeGateContainer.Env, corev1.EnvVar{
+		Name:  dtDNSEntryPoint,
+		Value: buildDNSEntryPoint(instance, target+"-"+multiCapability.ShortName()),
+	})
+}
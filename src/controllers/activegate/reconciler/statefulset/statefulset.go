@@ -205,15 +205,7 @@ func buildVolumes(stsProperties *statefulSetProperties, extraContainerBuilders [
 	var volumes []corev1.Volume
 
 	if stsProperties.DynaKube.FeatureEnableActivegateRawImage() {
-		volumes = append(volumes, corev1.Volume{
-			Name: tenantSecretVolumeName,
-			VolumeSource: corev1.VolumeSource{
-				Secret: &corev1.SecretVolumeSource{
-					SecretName: stsProperties.AGTenantSecret(),
-				},
-			},
-		},
-		)
+		volumes = append(volumes, tenantSecretVolume(stsProperties.AGTenantSecret()))
 	}
 
 	if !isCustomPropertiesNilOrEmpty(stsProperties.CustomProperties) {
@@ -246,6 +238,17 @@ func buildVolumes(stsProperties *statefulSetProperties, extraContainerBuilders [
 	return volumes
 }
 
+func tenantSecretVolume(secretName string) corev1.Volume {
+	return corev1.Volume{
+		Name: tenantSecretVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{
+				SecretName: secretName,
+			},
+		},
+	}
+}
+
 func buildProxyVolumes() []corev1.Volume {
 	return []corev1.Volume{
 		{
@@ -293,18 +296,21 @@ func buildVolumeMounts(stsProperties *statefulSetProperties) []corev1.VolumeMoun
 	}
 
 	if stsProperties.DynaKube.FeatureEnableActivegateRawImage() {
-		volumeMounts = append(volumeMounts, corev1.VolumeMount{
-			Name:      tenantSecretVolumeName,
-			ReadOnly:  true,
-			MountPath: tenantTokenMountPoint,
-			SubPath:   activegate.TenantTokenName,
-		},
-		)
+		volumeMounts = append(volumeMounts, tenantTokenVolumeMount())
 	}
 
 	return volumeMounts
 }
 
+func tenantTokenVolumeMount() corev1.VolumeMount {
+	return corev1.VolumeMount{
+		Name:      tenantSecretVolumeName,
+		ReadOnly:  true,
+		MountPath: tenantTokenMountPoint,
+		SubPath:   activegate.TenantTokenName,
+	}
+}
+
 func buildProxyMounts() []corev1.VolumeMount {
 	return []corev1.VolumeMount{
 		{
@@ -346,8 +352,8 @@ func buildEnvs(stsProperties *statefulSetProperties) []corev1.EnvVar {
 
 	if stsProperties.DynaKube.FeatureEnableActivegateRawImage() {
 		envs = append(envs,
-			communicationEndpointEnvVar(stsProperties),
-			tenantUuidNameEnvVar(stsProperties))
+			communicationEndpointEnvVar(stsProperties.AGTenantSecret()),
+			tenantUuidNameEnvVar(stsProperties.AGTenantSecret()))
 	}
 
 	envs = append(envs, stsProperties.Env...)
@@ -362,13 +368,13 @@ func buildEnvs(stsProperties *statefulSetProperties) []corev1.EnvVar {
 	return envs
 }
 
-func tenantUuidNameEnvVar(stsProperties *statefulSetProperties) corev1.EnvVar {
+func tenantUuidNameEnvVar(secretName string) corev1.EnvVar {
 	return corev1.EnvVar{
 		Name: dtTenant,
 		ValueFrom: &corev1.EnvVarSource{
 			SecretKeyRef: &corev1.SecretKeySelector{
 				LocalObjectReference: corev1.LocalObjectReference{
-					Name: stsProperties.AGTenantSecret(),
+					Name: secretName,
 				},
 				Key: activegate.TenantUuidName,
 			},
@@ -376,13 +382,13 @@ func tenantUuidNameEnvVar(stsProperties *statefulSetProperties) corev1.EnvVar {
 	}
 }
 
-func communicationEndpointEnvVar(stsProperties *statefulSetProperties) corev1.EnvVar {
+func communicationEndpointEnvVar(secretName string) corev1.EnvVar {
 	return corev1.EnvVar{
 		Name: dtServer,
 		ValueFrom: &corev1.EnvVarSource{
 			SecretKeyRef: &corev1.SecretKeySelector{
 				LocalObjectReference: corev1.LocalObjectReference{
-					Name: stsProperties.AGTenantSecret(),
+					Name: secretName,
 				},
 				Key: activegate.CommunicationEndpointsName,
 			},
@@ -0,0 +1,68 @@
+package statefulset
+
+import (
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/activegate/capability"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/activegate/internal/events"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// UseTenantTargetSecret lets the ActiveGate report to the additional tenant, with the tenant config of its ActiveGate tenant secret.
+// It's set regardless of the raw image feature flag, as the image is pulled from the environment of the apiUrl.
+// The StatefulSet and its pods are labeled with the tenant target.
+func UseTenantTargetSecret(instance *dynatracev1beta1.DynaKube, target string) events.StatefulSetEvent {
+	return func(sts *appsv1.StatefulSet) {
+		secretName := instance.AGTenantSecretForTarget(target)
+		sts.Labels[dynatracev1beta1.LabelTenantTarget] = target
+		sts.Spec.Template.Labels[dynatracev1beta1.LabelTenantTarget] = target
+
+		podSpec := &sts.Spec.Template.Spec
+		podSpec.Volumes = append(removeVolume(podSpec.Volumes, tenantSecretVolumeName), tenantSecretVolume(secretName))
+
+		for i := range podSpec.Containers {
+			container := &podSpec.Containers[i]
+			if container.Name != capability.ActiveGateContainerName {
+				continue
+			}
+			container.Env = append(removeEnvVars(container.Env, dtServer, dtTenant),
+				communicationEndpointEnvVar(secretName),
+				tenantUuidNameEnvVar(secretName))
+			container.VolumeMounts = append(removeVolumeMount(container.VolumeMounts, tenantSecretVolumeName), tenantTokenVolumeMount())
+		}
+	}
+}
+
+func removeVolume(volumes []corev1.Volume, name string) []corev1.Volume {
+	var result []corev1.Volume
+	for _, volume := range volumes {
+		if volume.Name != name {
+			result = append(result, volume)
+		}
+	}
+	return result
+}
+
+func removeVolumeMount(volumeMounts []corev1.VolumeMount, name string) []corev1.VolumeMount {
+	var result []corev1.VolumeMount
+	for _, volumeMount := range volumeMounts {
+		if volumeMount.Name != name {
+			result = append(result, volumeMount)
+		}
+	}
+	return result
+}
+
+func removeEnvVars(envs []corev1.EnvVar, names ...string) []corev1.EnvVar {
+	var result []corev1.EnvVar
+	for _, env := range envs {
+		removed := false
+		for _, name := range names {
+			removed = removed || env.Name == name
+		}
+		if !removed {
+			result = append(result, env)
+		}
+	}
+	return result
+}
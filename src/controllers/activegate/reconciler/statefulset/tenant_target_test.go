@@ -0,0 +1,78 @@
+package statefulset
+
+import (
+	"testing"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/activegate/capability"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/activegate/internal/events"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/activegate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestUseTenantTargetSecret(t *testing.T) {
+	const target = "migration"
+
+	buildStatefulSet := func(t *testing.T, instance *dynatracev1beta1.DynaKube) *appsv1.StatefulSet {
+		stsProperties := NewStatefulSetProperties(instance, &instance.Spec.ActiveGate.CapabilityProperties,
+			testUID, "", testFeature, "", "", nil, nil, nil)
+		stsProperties.OnAfterCreateListener = []events.StatefulSetEvent{UseTenantTargetSecret(instance, target)}
+		sts, err := CreateStatefulSet(stsProperties)
+		require.NoError(t, err)
+		return sts
+	}
+	assertTenantTargetSecret := func(t *testing.T, instance *dynatracev1beta1.DynaKube, sts *appsv1.StatefulSet) {
+		secretName := instance.AGTenantSecretForTarget(target)
+		assert.Equal(t, target, sts.Labels[dynatracev1beta1.LabelTenantTarget])
+		assert.Equal(t, target, sts.Spec.Template.Labels[dynatracev1beta1.LabelTenantTarget])
+		assert.Equal(t, []corev1.Volume{tenantSecretVolume(secretName)}, filterVolumes(sts.Spec.Template.Spec.Volumes, tenantSecretVolumeName))
+
+		container := sts.Spec.Template.Spec.Containers[0]
+		require.Equal(t, capability.ActiveGateContainerName, container.Name)
+		assert.Contains(t, container.VolumeMounts, tenantTokenVolumeMount())
+		var tenantEnvs []corev1.EnvVar
+		for _, env := range container.Env {
+			if env.Name == dtServer || env.Name == dtTenant {
+				tenantEnvs = append(tenantEnvs, env)
+			}
+		}
+		require.Len(t, tenantEnvs, 2)
+		for _, env := range tenantEnvs {
+			assert.Equal(t, secretName, env.ValueFrom.SecretKeyRef.Name)
+		}
+	}
+
+	t.Run(`tenant config is added`, func(t *testing.T) {
+		instance := buildTestInstance()
+		sts := buildStatefulSet(t, instance)
+		assertTenantTargetSecret(t, instance, sts)
+	})
+	t.Run(`tenant config of raw image is replaced`, func(t *testing.T) {
+		instance := buildTestInstance()
+		instance.Annotations[dynatracev1beta1.AnnotationFeatureEnableActivegateRawImage] = "true"
+		sts := buildStatefulSet(t, instance)
+		assertTenantTargetSecret(t, instance, sts)
+
+		container := sts.Spec.Template.Spec.Containers[0]
+		var tokenMounts int
+		for _, volumeMount := range container.VolumeMounts {
+			if volumeMount.SubPath == activegate.TenantTokenName {
+				tokenMounts++
+			}
+		}
+		assert.Equal(t, 1, tokenMounts)
+	})
+}
+
+func filterVolumes(volumes []corev1.Volume, name string) []corev1.Volume {
+	var result []corev1.Volume
+	for _, volume := range volumes {
+		if volume.Name == name {
+			result = append(result, volume)
+		}
+	}
+	return result
+}
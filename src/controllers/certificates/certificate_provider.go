@@ -0,0 +1,92 @@
+package certificates
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// ProviderEnv selects the provider of the webhook certificates, the built-in one is used if it isn't set.
+	ProviderEnv = "WEBHOOK_CERTIFICATE_PROVIDER"
+	// IssuerEnv and IssuerKindEnv reference the cert-manager issuer of the webhook certificates.
+	IssuerEnv     = "WEBHOOK_CERTIFICATE_ISSUER"
+	IssuerKindEnv = "WEBHOOK_CERTIFICATE_ISSUER_KIND"
+	// SecretEnv is the name of the user-provided secret with the webhook certificates.
+	SecretEnv = "WEBHOOK_CERTIFICATE_SECRET"
+
+	ProviderBuiltin     = "builtin"
+	ProviderCertManager = "cert-manager"
+	ProviderSecret      = "secret"
+
+	defaultIssuerKind = "Issuer"
+)
+
+// certificateProvider supplies the secret with the certificates of the webhook server.
+// The WebhookCertificateController patches the CA bundle of the secret into the webhook configurations and the CRD.
+type certificateProvider interface {
+	// getCertificateSecret returns the certificates, or nil if they are not issued yet.
+	getCertificateSecret(ctx context.Context, namespace string) (*certificateSecret, error)
+
+	// secretName is the name of the secret the webhook server reads the certificates from.
+	secretName() string
+}
+
+func newCertificateProvider(clt client.Client, apiReader client.Reader) (certificateProvider, error) {
+	switch provider := os.Getenv(ProviderEnv); provider {
+	case "", ProviderBuiltin:
+		return &builtinProvider{apiReader: apiReader}, nil
+	case ProviderCertManager:
+		issuer := os.Getenv(IssuerEnv)
+		if issuer == "" {
+			return nil, errors.Errorf("%s must be set for the %s certificate provider", IssuerEnv, ProviderCertManager)
+		}
+		issuerKind := os.Getenv(IssuerKindEnv)
+		if issuerKind == "" {
+			issuerKind = defaultIssuerKind
+		}
+		return &certManagerProvider{client: clt, apiReader: apiReader, issuerName: issuer, issuerKind: issuerKind}, nil
+	case ProviderSecret:
+		name := os.Getenv(SecretEnv)
+		if name == "" {
+			return nil, errors.Errorf("%s must be set for the %s certificate provider", SecretEnv, ProviderSecret)
+		}
+		return &secretProvider{apiReader: apiReader, name: name}, nil
+	default:
+		return nil, fmt.Errorf("unknown certificate provider %s", provider)
+	}
+}
+
+// CertificateSecretName returns the name of the secret with the certificates of the webhook server.
+func CertificateSecretName() (string, error) {
+	provider, err := newCertificateProvider(nil, nil)
+	if err != nil {
+		return "", err
+	}
+	return provider.secretName(), nil
+}
+
+// builtinProvider creates a self-signed root certificate and the server certificate, and renews them before they expire.
+type builtinProvider struct {
+	apiReader client.Reader
+}
+
+func (provider *builtinProvider) getCertificateSecret(ctx context.Context, namespace string) (*certificateSecret, error) {
+	certSecret := newCertificateSecret()
+
+	if err := certSecret.setSecretFromReader(ctx, provider.apiReader, namespace); err != nil {
+		return nil, err
+	}
+
+	if err := certSecret.validateCertificates(namespace); err != nil {
+		return nil, err
+	}
+	return certSecret, nil
+}
+
+func (provider *builtinProvider) secretName() string {
+	return buildSecretName()
+}
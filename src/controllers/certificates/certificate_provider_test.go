@@ -0,0 +1,138 @@
+package certificates
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Dynatrace/dynatrace-operator/src/webhook"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	testIssuer     = "test-issuer"
+	testUserSecret = "user-certs"
+)
+
+func TestNewCertificateProvider(t *testing.T) {
+	t.Run(`builtin by default`, func(t *testing.T) {
+		provider, err := newCertificateProvider(nil, nil)
+		require.NoError(t, err)
+		assert.IsType(t, &builtinProvider{}, provider)
+		assert.Equal(t, webhook.SecretCertsName, provider.secretName())
+	})
+	t.Run(`cert-manager with default issuer kind`, func(t *testing.T) {
+		t.Setenv(ProviderEnv, ProviderCertManager)
+		t.Setenv(IssuerEnv, testIssuer)
+
+		provider, err := newCertificateProvider(nil, nil)
+		require.NoError(t, err)
+		require.IsType(t, &certManagerProvider{}, provider)
+		assert.Equal(t, defaultIssuerKind, provider.(*certManagerProvider).issuerKind)
+		assert.Equal(t, webhook.SecretCertsName, provider.secretName())
+	})
+	t.Run(`user secret`, func(t *testing.T) {
+		t.Setenv(ProviderEnv, ProviderSecret)
+		t.Setenv(SecretEnv, testUserSecret)
+
+		secretName, err := CertificateSecretName()
+		require.NoError(t, err)
+		assert.Equal(t, testUserSecret, secretName)
+	})
+	t.Run(`missing configuration`, func(t *testing.T) {
+		t.Setenv(ProviderEnv, ProviderCertManager)
+		_, err := newCertificateProvider(nil, nil)
+		assert.Error(t, err)
+
+		t.Setenv(ProviderEnv, ProviderSecret)
+		_, err = newCertificateProvider(nil, nil)
+		assert.Error(t, err)
+
+		t.Setenv(ProviderEnv, "unknown")
+		_, err = newCertificateProvider(nil, nil)
+		assert.Error(t, err)
+	})
+}
+
+func TestReconcileCertificate_CertManager(t *testing.T) {
+	clt := prepareFakeClient(false, false)
+	controller, request := prepareController(clt)
+	controller.provider = &certManagerProvider{client: clt, apiReader: clt, issuerName: testIssuer, issuerKind: "ClusterIssuer"}
+
+	res, err := controller.Reconcile(context.TODO(), request)
+	require.NoError(t, err)
+	assert.Equal(t, PendingDuration, res.RequeueAfter)
+
+	certificate := &unstructured.Unstructured{}
+	certificate.SetGroupVersionKind(CertificateGVK)
+	require.NoError(t, clt.Get(context.TODO(), client.ObjectKey{Name: webhook.DeploymentName, Namespace: testNamespace}, certificate))
+	issuerKind, _, _ := unstructured.NestedString(certificate.Object, "spec", "issuerRef", "kind")
+	assert.Equal(t, "ClusterIssuer", issuerKind)
+	dnsNames, _, _ := unstructured.NestedStringSlice(certificate.Object, "spec", "dnsNames")
+	assert.Equal(t, []string{testDomain}, dnsNames)
+
+	// cert-manager issues the certificate
+	issued := createTestSecret(t, createValidTestCertData(t))
+	delete(issued.Data, RootKey)
+	delete(issued.Data, RootCertOld)
+	require.NoError(t, clt.Create(context.TODO(), issued))
+
+	res, err = controller.Reconcile(context.TODO(), request)
+	require.NoError(t, err)
+	assert.Equal(t, SuccessDuration, res.RequeueAfter)
+	assertCABundles(t, clt, issued.Data[RootCert])
+
+	secret := &corev1.Secret{}
+	require.NoError(t, clt.Get(context.TODO(), client.ObjectKey{Name: expectedSecretName, Namespace: testNamespace}, secret))
+	assert.Equal(t, issued.Data, secret.Data)
+}
+
+func TestReconcileCertificate_UserSecret(t *testing.T) {
+	clt := prepareFakeClient(false, false)
+	controller, request := prepareController(clt)
+	controller.provider = &secretProvider{apiReader: clt, name: testUserSecret}
+
+	_, err := controller.Reconcile(context.TODO(), request)
+	require.Error(t, err)
+
+	userSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: testUserSecret, Namespace: testNamespace},
+		Data:       map[string][]byte{RootCert: {1}, ServerCert: {2}, ServerKey: {3}},
+	}
+	require.NoError(t, clt.Create(context.TODO(), userSecret))
+
+	res, err := controller.Reconcile(context.TODO(), request)
+	require.NoError(t, err)
+	assert.Equal(t, SuccessDuration, res.RequeueAfter)
+	assertCABundles(t, clt, []byte{1})
+
+	secret := &corev1.Secret{}
+	require.NoError(t, clt.Get(context.TODO(), client.ObjectKey{Name: testUserSecret, Namespace: testNamespace}, secret))
+	assert.Equal(t, userSecret.Data, secret.Data)
+	err = clt.Get(context.TODO(), client.ObjectKey{Name: expectedSecretName, Namespace: testNamespace}, &corev1.Secret{})
+	assert.Error(t, err)
+}
+
+func assertCABundles(t *testing.T, clt client.Client, caBundle []byte) {
+	mutatingWebhookConfig := &admissionregistrationv1.MutatingWebhookConfiguration{}
+	require.NoError(t, clt.Get(context.TODO(), client.ObjectKey{Name: webhook.DeploymentName}, mutatingWebhookConfig))
+	for _, mutatingWebhook := range mutatingWebhookConfig.Webhooks {
+		assert.Equal(t, caBundle, mutatingWebhook.ClientConfig.CABundle)
+	}
+
+	validatingWebhookConfig := &admissionregistrationv1.ValidatingWebhookConfiguration{}
+	require.NoError(t, clt.Get(context.TODO(), client.ObjectKey{Name: webhook.DeploymentName}, validatingWebhookConfig))
+	for _, validatingWebhook := range validatingWebhookConfig.Webhooks {
+		assert.Equal(t, caBundle, validatingWebhook.ClientConfig.CABundle)
+	}
+
+	crd := &apiv1.CustomResourceDefinition{}
+	require.NoError(t, clt.Get(context.TODO(), client.ObjectKey{Name: crdName}, crd))
+	assert.Equal(t, caBundle, crd.Spec.Conversion.Webhook.ClientConfig.CABundle)
+}
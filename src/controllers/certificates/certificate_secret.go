@@ -25,6 +25,15 @@ func newCertificateSecret() *certificateSecret {
 	return &certificateSecret{}
 }
 
+// newExternalCertificateSecret wraps a secret with certificates which are issued outside the operator, so they are never updated.
+func newExternalCertificateSecret(secret *corev1.Secret) *certificateSecret {
+	return &certificateSecret{
+		secret:          secret,
+		certificates:    &Certs{Data: secret.Data},
+		existsInCluster: true,
+	}
+}
+
 func (certSecret *certificateSecret) setSecretFromReader(ctx context.Context, apiReader client.Reader, namespace string) error {
 	secret, err := kubeobjects.GetSecret(ctx, apiReader, buildSecretName(), namespace)
 	if err != nil {
@@ -0,0 +1,86 @@
+package certificates
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/Dynatrace/dynatrace-operator/src/kubeobjects"
+	"github.com/Dynatrace/dynatrace-operator/src/webhook"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var CertificateGVK = schema.GroupVersionKind{Group: "cert-manager.io", Version: "v1", Kind: "Certificate"}
+
+// certManagerProvider requests the certificates from a cert-manager issuer by a Certificate, cert-manager takes care of the renewal.
+type certManagerProvider struct {
+	client     client.Client
+	apiReader  client.Reader
+	issuerName string
+	issuerKind string
+}
+
+func (provider *certManagerProvider) getCertificateSecret(ctx context.Context, namespace string) (*certificateSecret, error) {
+	if err := provider.createOrUpdateCertificate(ctx, namespace); err != nil {
+		return nil, err
+	}
+
+	secret, err := kubeobjects.GetSecret(ctx, provider.apiReader, provider.secretName(), namespace)
+	if err != nil {
+		return nil, err
+	} else if secret == nil || len(secret.Data[ServerCert]) == 0 {
+		log.Info("certificate not issued by cert-manager yet", "issuer", provider.issuerName)
+		return nil, nil
+	} else if len(secret.Data[RootCert]) == 0 {
+		log.Info("certificate issued by cert-manager lacks the CA certificate, the issuer has to provide it", "issuer", provider.issuerName)
+		return nil, nil
+	}
+	return newExternalCertificateSecret(secret), nil
+}
+
+func (provider *certManagerProvider) createOrUpdateCertificate(ctx context.Context, namespace string) error {
+	desired := provider.buildCertificate(namespace)
+
+	existing := &unstructured.Unstructured{}
+	existing.SetGroupVersionKind(CertificateGVK)
+	err := provider.apiReader.Get(ctx, client.ObjectKey{Name: desired.GetName(), Namespace: namespace}, existing)
+	if k8serrors.IsNotFound(err) {
+		log.Info("creating cert-manager certificate", "name", desired.GetName())
+		return provider.client.Create(ctx, desired)
+	} else if err != nil {
+		return err
+	}
+
+	if reflect.DeepEqual(existing.Object["spec"], desired.Object["spec"]) {
+		return nil
+	}
+	log.Info("updating cert-manager certificate", "name", desired.GetName())
+	existing.Object["spec"] = desired.Object["spec"]
+	return provider.client.Update(ctx, existing)
+}
+
+func (provider *certManagerProvider) buildCertificate(namespace string) *unstructured.Unstructured {
+	certificate := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"spec": map[string]interface{}{
+				"secretName": provider.secretName(),
+				"dnsNames":   []interface{}{getDomain(namespace)},
+				"issuerRef": map[string]interface{}{
+					"name":  provider.issuerName,
+					"kind":  provider.issuerKind,
+					"group": CertificateGVK.Group,
+				},
+			},
+		},
+	}
+	certificate.SetGroupVersionKind(CertificateGVK)
+	certificate.SetName(webhook.DeploymentName)
+	certificate.SetNamespace(namespace)
+	return certificate
+}
+
+func (provider *certManagerProvider) secretName() string {
+	return buildSecretName()
+}
@@ -0,0 +1,35 @@
+package certificates
+
+import (
+	"context"
+
+	"github.com/Dynatrace/dynatrace-operator/src/kubeobjects"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// secretProvider uses the certificates of a secret provided by the user, which are never rotated by the operator.
+type secretProvider struct {
+	apiReader client.Reader
+	name      string
+}
+
+func (provider *secretProvider) getCertificateSecret(ctx context.Context, namespace string) (*certificateSecret, error) {
+	secret, err := kubeobjects.GetSecret(ctx, provider.apiReader, provider.name, namespace)
+	if err != nil {
+		return nil, err
+	} else if secret == nil {
+		return nil, errors.Errorf("certificates secret %s not found", provider.name)
+	}
+
+	for _, key := range []string{RootCert, ServerCert, ServerKey} {
+		if len(secret.Data[key]) == 0 {
+			return nil, errors.Errorf("certificates secret %s is missing %s", provider.name, key)
+		}
+	}
+	return newExternalCertificateSecret(secret), nil
+}
+
+func (provider *secretProvider) secretName() string {
+	return provider.name
+}
@@ -13,13 +13,17 @@ import (
 	apiv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
+	ctrlbuilder "sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 )
 
 const (
 	SuccessDuration = 3 * time.Hour
+	PendingDuration = 30 * time.Second
 
 	crdName                      = "dynakubes.dynatrace.com"
 	secretPostfix                = "-certs"
@@ -27,25 +31,44 @@ const (
 )
 
 func Add(mgr manager.Manager, ns string) error {
-	return ctrl.NewControllerManagedBy(mgr).
-		For(&appsv1.Deployment{}).
-		WithEventFilter(eventfilter.ForObjectNameAndNamespace(webhook.DeploymentName, ns)).
-		Complete(newWebhookCertificateController(mgr, nil))
+	return add(mgr, ns, nil)
 }
 
 func AddBootstrap(mgr manager.Manager, ns string, cancelMgr context.CancelFunc) error {
-	return ctrl.NewControllerManagedBy(mgr).
-		For(&appsv1.Deployment{}).
-		WithEventFilter(eventfilter.ForObjectNameAndNamespace(webhook.DeploymentName, ns)).
-		Complete(newWebhookCertificateController(mgr, cancelMgr))
+	return add(mgr, ns, cancelMgr)
 }
 
-func newWebhookCertificateController(mgr manager.Manager, cancelMgr context.CancelFunc) *WebhookCertificateController {
+func add(mgr manager.Manager, ns string, cancelMgr context.CancelFunc) error {
+	controller, err := newWebhookCertificateController(mgr, cancelMgr)
+	if err != nil {
+		return err
+	}
+
+	builder := ctrl.NewControllerManagedBy(mgr).
+		For(&appsv1.Deployment{}, ctrlbuilder.WithPredicates(eventfilter.ForObjectNameAndNamespace(webhook.DeploymentName, ns)))
+
+	// certificates issued outside the operator can change at any time
+	if _, isBuiltin := controller.provider.(*builtinProvider); !isBuiltin {
+		builder = builder.Watches(&source.Kind{Type: &corev1.Secret{}},
+			handler.EnqueueRequestsFromMapFunc(func(object client.Object) []reconcile.Request {
+				return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: webhook.DeploymentName, Namespace: ns}}}
+			}),
+			ctrlbuilder.WithPredicates(eventfilter.ForObjectNameAndNamespace(controller.provider.secretName(), ns)))
+	}
+	return builder.Complete(controller)
+}
+
+func newWebhookCertificateController(mgr manager.Manager, cancelMgr context.CancelFunc) (*WebhookCertificateController, error) {
+	provider, err := newCertificateProvider(mgr.GetClient(), mgr.GetAPIReader())
+	if err != nil {
+		return nil, err
+	}
 	return &WebhookCertificateController{
 		cancelMgrFunc: cancelMgr,
 		client:        mgr.GetClient(),
 		apiReader:     mgr.GetAPIReader(),
-	}
+		provider:      provider,
+	}, nil
 }
 
 type WebhookCertificateController struct {
@@ -54,6 +77,7 @@ type WebhookCertificateController struct {
 	apiReader     client.Reader
 	namespace     string
 	cancelMgrFunc context.CancelFunc
+	provider      certificateProvider
 }
 
 func (controller *WebhookCertificateController) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
@@ -76,16 +100,11 @@ func (controller *WebhookCertificateController) Reconcile(ctx context.Context, r
 		log.Info("could not find validating webhook configuration, this is normal when deployed using OLM")
 	}
 
-	certSecret := newCertificateSecret()
-
-	err = certSecret.setSecretFromReader(controller.ctx, controller.apiReader, controller.namespace)
-	if err != nil {
-		return reconcile.Result{}, errors.WithStack(err)
-	}
-
-	err = certSecret.validateCertificates(controller.namespace)
+	certSecret, err := controller.getProvider().getCertificateSecret(controller.ctx, controller.namespace)
 	if err != nil {
 		return reconcile.Result{}, errors.WithStack(err)
+	} else if certSecret == nil {
+		return reconcile.Result{RequeueAfter: PendingDuration}, nil
 	}
 
 	mutatingWebhookConfigs := getClientConfigsFromMutatingWebhook(mutatingWebhookConfiguration)
@@ -124,6 +143,13 @@ func (controller *WebhookCertificateController) Reconcile(ctx context.Context, r
 	return reconcile.Result{RequeueAfter: SuccessDuration}, nil
 }
 
+func (controller *WebhookCertificateController) getProvider() certificateProvider {
+	if controller.provider == nil {
+		controller.provider = &builtinProvider{apiReader: controller.apiReader}
+	}
+	return controller.provider
+}
+
 func (controller *WebhookCertificateController) cancelMgr() {
 	if controller.cancelMgrFunc != nil {
 		log.Info("stopping manager after certificates creation")
@@ -24,7 +24,9 @@ const (
 	DataPath             = "/data"
 	DriverName           = "csi.oneagent.dynatrace.com"
 	AgentBinaryDir       = "bin"
+	AgentConfigDir       = "config"
 	AgentRunDir          = "run"
+	SharedAgentBinaryDir = "_shared"
 	OverlayMappedDirPath = "mapped"
 	OverlayVarDirPath    = "var"
 	OverlayWorkDirPath   = "work"
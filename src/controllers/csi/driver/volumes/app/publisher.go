@@ -21,10 +21,13 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
 	dtcsi "github.com/Dynatrace/dynatrace-operator/src/controllers/csi"
 	csivolumes "github.com/Dynatrace/dynatrace-operator/src/controllers/csi/driver/volumes"
 	"github.com/Dynatrace/dynatrace-operator/src/controllers/csi/metadata"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/csi/provisioner/arch"
+	"github.com/Dynatrace/dynatrace-operator/src/dtclient"
 	"github.com/container-storage-interface/spec/lib/go/csi"
 	dto "github.com/prometheus/client_model/go"
 	"github.com/spf13/afero"
@@ -58,6 +61,10 @@ func (publisher *AppVolumePublisher) PublishVolume(ctx context.Context, volumeCf
 		return nil, err
 	}
 
+	if err := publisher.selectFlavor(bindCfg, volumeCfg); err != nil {
+		return nil, err
+	}
+
 	if err := publisher.mountOneAgent(bindCfg, volumeCfg); err != nil {
 		return nil, status.Error(codes.Internal, fmt.Sprintf("failed to mount oneagent volume: %s", err))
 	}
@@ -122,6 +129,27 @@ func (publisher *AppVolumePublisher) fireVolumeUnpublishedMetric(volume metadata
 	}
 }
 
+// selectFlavor uses the pre-pulled code modules of the flavor requested by the pod, if they are stored on the node.
+// Otherwise the default flavor of the node is mounted, except for musl, which the glibc based flavors can't replace.
+// The publish of a musl volume fails until the code modules are pre-pulled, the kubelet retries it.
+func (publisher *AppVolumePublisher) selectFlavor(bindCfg *csivolumes.BindConfig, volumeCfg *csivolumes.VolumeConfig) error {
+	binaryName := arch.BinaryName(bindCfg.Version, volumeCfg.Flavor)
+	if binaryName == bindCfg.Version {
+		return nil
+	}
+
+	if _, err := publisher.fs.Stat(publisher.path.AgentBinaryDirForVersion(bindCfg.TenantUUID, binaryName)); err != nil {
+		if volumeCfg.Flavor == dtclient.FlavorMusl {
+			return status.Error(codes.Unavailable, fmt.Sprintf("code modules of flavor %s are not pre-pulled on the node for version %s", volumeCfg.Flavor, bindCfg.Version))
+		}
+		log.Info("requested flavor not pre-pulled, using default flavor", "flavor", volumeCfg.Flavor, "version", bindCfg.Version)
+		volumeCfg.Flavor = ""
+		return nil
+	}
+	bindCfg.Version = binaryName
+	return nil
+}
+
 func (publisher *AppVolumePublisher) mountOneAgent(bindCfg *csivolumes.BindConfig, volumeCfg *csivolumes.VolumeConfig) error {
 	mappedDir := publisher.path.OverlayMappedDir(bindCfg.TenantUUID, volumeCfg.VolumeID)
 	_ = publisher.fs.MkdirAll(mappedDir, os.ModePerm)
@@ -133,7 +161,7 @@ func (publisher *AppVolumePublisher) mountOneAgent(bindCfg *csivolumes.BindConfi
 	_ = publisher.fs.MkdirAll(workDir, os.ModePerm)
 
 	overlayOptions := []string{
-		"lowerdir=" + publisher.path.AgentBinaryDirForVersion(bindCfg.TenantUUID, bindCfg.Version),
+		"lowerdir=" + publisher.lowerDir(bindCfg),
 		"upperdir=" + upperDir,
 		"workdir=" + workDir,
 	}
@@ -153,6 +181,16 @@ func (publisher *AppVolumePublisher) mountOneAgent(bindCfg *csivolumes.BindConfi
 	return nil
 }
 
+// lowerDir returns the binary of the tenant, shared binaries with the config directory of the tenant on top of them.
+func (publisher *AppVolumePublisher) lowerDir(bindCfg *csivolumes.BindConfig) string {
+	binaryDir := publisher.path.AgentBinaryDirForVersion(bindCfg.TenantUUID, bindCfg.Version)
+	configDir := publisher.path.AgentConfigDirForVersion(bindCfg.TenantUUID, bindCfg.Version)
+	if exists, _ := publisher.fs.DirExists(configDir); exists {
+		return configDir + ":" + binaryDir
+	}
+	return binaryDir
+}
+
 func (publisher *AppVolumePublisher) umountOneAgent(targetPath string, overlayFSPath string) error {
 	if err := publisher.mounter.Unmount(targetPath); err != nil {
 		log.Error(err, "Unmount failed", "path", targetPath)
@@ -170,7 +208,11 @@ func (publisher *AppVolumePublisher) umountOneAgent(targetPath string, overlayFS
 
 func (publisher *AppVolumePublisher) storeVolume(bindCfg *csivolumes.BindConfig, volumeCfg *csivolumes.VolumeConfig) error {
 	volume := metadata.NewVolume(volumeCfg.VolumeID, volumeCfg.PodName, bindCfg.Version, bindCfg.TenantUUID)
-	log.Info("inserting volume info", "ID", volume.VolumeID, "PodUID", volume.PodName, "Version", volume.Version, "TenantUUID", volume.TenantUUID)
+	mountTimestamp := time.Now()
+	volume.PodNamespace = volumeCfg.PodNamespace
+	volume.Flavor = volumeCfg.Flavor
+	volume.MountTimestamp = &mountTimestamp
+	log.Info("inserting volume info", "ID", volume.VolumeID, "PodUID", volume.PodName, "PodNamespace", volume.PodNamespace, "Version", volume.Version, "TenantUUID", volume.TenantUUID)
 	return publisher.db.InsertVolume(volume)
 }
 
@@ -9,11 +9,15 @@ import (
 	dtcsi "github.com/Dynatrace/dynatrace-operator/src/controllers/csi"
 	csivolumes "github.com/Dynatrace/dynatrace-operator/src/controllers/csi/driver/volumes"
 	"github.com/Dynatrace/dynatrace-operator/src/controllers/csi/metadata"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/csi/provisioner/arch"
+	"github.com/Dynatrace/dynatrace-operator/src/dtclient"
 	"github.com/Dynatrace/dynatrace-operator/src/scheme/fake"
 	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/spf13/afero"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/utils/mount"
@@ -43,6 +47,78 @@ func TestPublishVolume(t *testing.T) {
 	assertReferencesForPublishedVolume(t, &publisher, mounter)
 }
 
+func TestPublishVolume_flavor(t *testing.T) {
+	t.Run(`pre-pulled flavor is mounted`, func(t *testing.T) {
+		mounter := mount.NewFakeMounter([]mount.MountPoint{})
+		publisher := newPublisherForTesting(t, mounter)
+		mockOneAgent(t, &publisher)
+		muslVersion := arch.BinaryName(testAgentVersion, dtclient.FlavorMusl)
+		require.NoError(t, publisher.fs.MkdirAll(publisher.path.AgentBinaryDirForVersion(testTenantUUID, muslVersion), 0755))
+		volumeCfg := createTestVolumeConfig()
+		volumeCfg.Flavor = dtclient.FlavorMusl
+
+		_, err := publisher.PublishVolume(context.TODO(), volumeCfg)
+		require.NoError(t, err)
+
+		volume, err := publisher.loadVolume(testVolumeId)
+		require.NoError(t, err)
+		assert.Equal(t, muslVersion, volume.Version)
+		assert.Equal(t, dtclient.FlavorMusl, volume.Flavor)
+		assert.NotNil(t, volume.MountTimestamp)
+		require.NotEmpty(t, mounter.MountPoints)
+		assert.Contains(t, mounter.MountPoints[0].Opts, "lowerdir="+publisher.path.AgentBinaryDirForVersion(testTenantUUID, muslVersion))
+		agentsVersionsMetric.DeleteLabelValues(muslVersion)
+	})
+	t.Run(`default flavor is mounted if requested flavor is not pre-pulled`, func(t *testing.T) {
+		mounter := mount.NewFakeMounter([]mount.MountPoint{})
+		publisher := newPublisherForTesting(t, mounter)
+		mockOneAgent(t, &publisher)
+		volumeCfg := createTestVolumeConfig()
+		volumeCfg.Flavor = dtclient.FlavorDefault
+		if arch.Flavor == dtclient.FlavorDefault {
+			volumeCfg.Flavor = dtclient.FlavorMultidistro
+		}
+
+		_, err := publisher.PublishVolume(context.TODO(), volumeCfg)
+		require.NoError(t, err)
+
+		assertReferencesForPublishedVolume(t, &publisher, mounter)
+		volume, err := publisher.loadVolume(testVolumeId)
+		require.NoError(t, err)
+		assert.Empty(t, volume.Flavor)
+	})
+	t.Run(`publish fails if musl is not pre-pulled`, func(t *testing.T) {
+		mounter := mount.NewFakeMounter([]mount.MountPoint{})
+		publisher := newPublisherForTesting(t, mounter)
+		mockOneAgent(t, &publisher)
+		volumeCfg := createTestVolumeConfig()
+		volumeCfg.Flavor = dtclient.FlavorMusl
+
+		_, err := publisher.PublishVolume(context.TODO(), volumeCfg)
+		require.Error(t, err)
+		assert.Equal(t, codes.Unavailable, status.Code(err))
+
+		assert.Empty(t, mounter.MountPoints)
+		volume, err := publisher.loadVolume(testVolumeId)
+		require.NoError(t, err)
+		assert.Nil(t, volume)
+	})
+}
+
+func TestPublishVolume_sharedBinary(t *testing.T) {
+	mounter := mount.NewFakeMounter([]mount.MountPoint{})
+	publisher := newPublisherForTesting(t, mounter)
+	mockOneAgent(t, &publisher)
+	configDir := publisher.path.AgentConfigDirForVersion(testTenantUUID, testAgentVersion)
+	require.NoError(t, publisher.fs.MkdirAll(configDir, 0755))
+
+	_, err := publisher.PublishVolume(context.TODO(), createTestVolumeConfig())
+	require.NoError(t, err)
+
+	require.NotEmpty(t, mounter.MountPoints)
+	assert.Contains(t, mounter.MountPoints[0].Opts, "lowerdir="+configDir+":"+publisher.path.AgentBinaryDirForVersion(testTenantUUID, testAgentVersion))
+}
+
 func TestUnpublishVolume(t *testing.T) {
 	t.Run(`valid metadata`, func(t *testing.T) {
 		resetMetrics()
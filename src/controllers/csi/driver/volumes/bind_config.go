@@ -11,7 +11,8 @@ import (
 
 type BindConfig struct {
 	TenantUUID string
-	Version    string
+	// Version is the name of the directory of the code modules, which includes the flavor for pre-pulled flavors
+	Version string
 }
 
 func NewBindConfig(
@@ -63,10 +63,11 @@ func (publisher *HostVolumePublisher) PublishVolume(ctx context.Context, volumeC
 		)
 	}
 
-	if err := publisher.mountOneAgent(bindCfg.TenantUUID, volumeCfg); err != nil {
+	storageKey := osAgentStorageKey(bindCfg.TenantUUID, volumeCfg)
+	if err := publisher.mountOneAgent(storageKey, volumeCfg); err != nil {
 		return nil, status.Error(codes.Internal, fmt.Sprintf("failed to mount osagent volume: %s", err.Error()))
 	}
-	volume, err := publisher.db.GetOsAgentVolumeViaTenantUUID(bindCfg.TenantUUID)
+	volume, err := publisher.db.GetOsAgentVolumeViaTenantUUID(storageKey)
 	if err != nil {
 		return nil, status.Error(codes.Internal, fmt.Sprintf("failed to get osagent volume info from database: %s", err.Error()))
 	}
@@ -75,7 +76,7 @@ func (publisher *HostVolumePublisher) PublishVolume(ctx context.Context, volumeC
 	if volume == nil {
 		storage := metadata.OsAgentVolume{
 			VolumeID:     volumeCfg.VolumeID,
-			TenantUUID:   bindCfg.TenantUUID,
+			TenantUUID:   storageKey,
 			Mounted:      true,
 			LastModified: &timestamp,
 		}
@@ -128,6 +129,15 @@ func (publisher *HostVolumePublisher) CanUnpublishVolume(volumeInfo *csivolumes.
 	return volume != nil, nil
 }
 
+// osAgentStorageKey separates the storage of the OneAgents of an additional tenant from the ones of the primary tenant,
+// which run on the same node.
+func osAgentStorageKey(tenantUUID string, volumeCfg *csivolumes.VolumeConfig) string {
+	if volumeCfg.TenantTarget == "" {
+		return tenantUUID
+	}
+	return tenantUUID + "-" + volumeCfg.TenantTarget
+}
+
 func (publisher *HostVolumePublisher) mountOneAgent(tenantUUID string, volumeCfg *csivolumes.VolumeConfig) error {
 	hostDir := publisher.path.OsAgentDir(tenantUUID)
 	_ = publisher.fs.MkdirAll(hostDir, os.ModePerm)
@@ -40,6 +40,24 @@ func TestPublishVolume(t *testing.T) {
 		assert.NotEmpty(t, mounter.MountPoints)
 		assertReferencesForPublishedVolume(t, &publisher, mounter)
 	})
+	t.Run(`tenant target has its own storage`, func(t *testing.T) {
+		mounter := mount.NewFakeMounter([]mount.MountPoint{})
+		publisher := newPublisherForTesting(t, mounter)
+		mockDynakube(t, &publisher)
+		volumeCfg := createTestVolumeConfig()
+		volumeCfg.TenantTarget = "secondary"
+
+		response, err := publisher.PublishVolume(context.TODO(), volumeCfg)
+
+		require.NoError(t, err)
+		assert.NotNil(t, response)
+		require.Len(t, mounter.MountPoints, 1)
+		assert.Equal(t, publisher.path.OsAgentDir(testTenantUUID+"-secondary"), mounter.MountPoints[0].Device)
+
+		volume, err := publisher.db.GetOsAgentVolumeViaVolumeID(testVolumeId)
+		require.NoError(t, err)
+		assert.Equal(t, testTenantUUID+"-secondary", volume.TenantUUID)
+	})
 	t.Run(`not ready dynakube`, func(t *testing.T) {
 		mounter := mount.NewFakeMounter([]mount.MountPoint{})
 		publisher := newPublisherForTesting(t, mounter)
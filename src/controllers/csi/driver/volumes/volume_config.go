@@ -7,11 +7,18 @@ import (
 )
 
 const (
-	PodNameContextKey = "csi.storage.k8s.io/pod.name"
+	PodNameContextKey      = "csi.storage.k8s.io/pod.name"
+	PodNamespaceContextKey = "csi.storage.k8s.io/pod.namespace"
 
 	// CSIVolumeAttributeModeField used for identifying the origin of the NodePublishVolume request
 	CSIVolumeAttributeModeField     = "mode"
 	CSIVolumeAttributeDynakubeField = "dynakube"
+
+	// CSIVolumeAttributeFlavorField is the code modules flavor requested by the pod, optional
+	CSIVolumeAttributeFlavorField = "flavor"
+
+	// CSIVolumeAttributeTenantTargetField is the additional tenant the OneAgent of the pod reports to, optional
+	CSIVolumeAttributeTenantTargetField = "tenantTarget"
 )
 
 // Represents the basic information about a volume
@@ -24,8 +31,11 @@ type VolumeInfo struct {
 type VolumeConfig struct {
 	VolumeInfo
 	PodName      string
+	PodNamespace string
 	Mode         string
 	DynakubeName string
+	Flavor       string
+	TenantTarget string
 }
 
 // Transforms the NodePublishVolumeRequest into a VolumeConfig
@@ -78,8 +88,11 @@ func ParseNodePublishVolumeRequest(req *csi.NodePublishVolumeRequest) (*VolumeCo
 			TargetPath: targetPath,
 		},
 		PodName:      podName,
+		PodNamespace: volCtx[PodNamespaceContextKey],
 		Mode:         mode,
 		DynakubeName: dynakubeName,
+		Flavor:       volCtx[CSIVolumeAttributeFlavorField],
+		TenantTarget: volCtx[CSIVolumeAttributeTenantTargetField],
 	}, nil
 }
 
@@ -5,12 +5,14 @@ import (
 
 	"github.com/container-storage-interface/spec/lib/go/csi"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 const (
 	testVolumeId   = "a-volume-id"
 	testTargetPath = "a-target-path"
 	testPodUID     = "a-pod-uid"
+	testNamespace  = "a-namespace"
 )
 
 func TestCSIDriverServer_ParsePublishVolumeRequest(t *testing.T) {
@@ -144,6 +146,7 @@ func TestCSIDriverServer_ParsePublishVolumeRequest(t *testing.T) {
 			TargetPath: testTargetPath,
 			VolumeContext: map[string]string{
 				PodNameContextKey:               testPodUID,
+				PodNamespaceContextKey:          testNamespace,
 				CSIVolumeAttributeDynakubeField: testDynakubeName,
 				CSIVolumeAttributeModeField:     "test",
 			},
@@ -155,7 +158,51 @@ func TestCSIDriverServer_ParsePublishVolumeRequest(t *testing.T) {
 		assert.Equal(t, testVolumeId, volumeCfg.VolumeID)
 		assert.Equal(t, testTargetPath, volumeCfg.TargetPath)
 		assert.Equal(t, testPodUID, volumeCfg.PodName)
+		assert.Equal(t, testNamespace, volumeCfg.PodNamespace)
 		assert.Equal(t, "test", volumeCfg.Mode)
 		assert.Equal(t, testDynakubeName, volumeCfg.DynakubeName)
+		assert.Empty(t, volumeCfg.Flavor)
+	})
+	t.Run(`requested flavor is parsed`, func(t *testing.T) {
+		request := &csi.NodePublishVolumeRequest{
+			VolumeCapability: &csi.VolumeCapability{
+				AccessType: &csi.VolumeCapability_Mount{
+					Mount: &csi.VolumeCapability_MountVolume{},
+				},
+			},
+			VolumeId:   testVolumeId,
+			TargetPath: testTargetPath,
+			VolumeContext: map[string]string{
+				PodNameContextKey:               testPodUID,
+				CSIVolumeAttributeDynakubeField: testDynakubeName,
+				CSIVolumeAttributeModeField:     "test",
+				CSIVolumeAttributeFlavorField:   "musl",
+			},
+		}
+		volumeCfg, err := ParseNodePublishVolumeRequest(request)
+
+		require.NoError(t, err)
+		assert.Equal(t, "musl", volumeCfg.Flavor)
+	})
+	t.Run(`tenant target is parsed`, func(t *testing.T) {
+		request := &csi.NodePublishVolumeRequest{
+			VolumeCapability: &csi.VolumeCapability{
+				AccessType: &csi.VolumeCapability_Mount{
+					Mount: &csi.VolumeCapability_MountVolume{},
+				},
+			},
+			VolumeId:   testVolumeId,
+			TargetPath: testTargetPath,
+			VolumeContext: map[string]string{
+				PodNameContextKey:                   testPodUID,
+				CSIVolumeAttributeDynakubeField:     testDynakubeName,
+				CSIVolumeAttributeModeField:         "test",
+				CSIVolumeAttributeTenantTargetField: "secondary",
+			},
+		}
+		volumeCfg, err := ParseNodePublishVolumeRequest(request)
+
+		require.NoError(t, err)
+		assert.Equal(t, "secondary", volumeCfg.TenantTarget)
 	})
 }
@@ -2,12 +2,21 @@ package csigc
 
 import (
 	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	dtcsi "github.com/Dynatrace/dynatrace-operator/src/controllers/csi"
 
 	"github.com/pkg/errors"
 	"github.com/spf13/afero"
 )
 
-func (gc *CSIGarbageCollector) runBinaryGarbageCollection(tenantUUID string, latestVersion string) {
+// maxSharedBinaryInstallAge is the time after which a shared binary that is still being installed is considered abandoned
+const maxSharedBinaryInstallAge = time.Hour
+
+// runBinaryGarbageCollection removes the stored code modules which are neither the latest version, nor pre-pulled, nor used by a volume.
+func (gc *CSIGarbageCollector) runBinaryGarbageCollection(tenantUUID string, latestVersion string, prePulledBinaries ...string) {
 	fs := &afero.Afero{Fs: gc.fs}
 	gcRunsMetric.Inc()
 
@@ -27,6 +36,7 @@ func (gc *CSIGarbageCollector) runBinaryGarbageCollection(tenantUUID string, lat
 
 	for _, version := range storedVersions {
 		shouldDelete := isNotLatestVersion(version, latestVersion) &&
+			isNotPrePulled(version, prePulledBinaries) &&
 			shouldDeleteVersion(version, usedVersions)
 
 		if shouldDelete {
@@ -34,8 +44,96 @@ func (gc *CSIGarbageCollector) runBinaryGarbageCollection(tenantUUID string, lat
 			log.Info("deleting unused version", "version", version, "path", binaryPath)
 
 			removeUnusedVersion(fs, binaryPath)
+			_ = fs.RemoveAll(gc.path.AgentConfigDirForVersion(tenantUUID, version))
+		}
+	}
+}
+
+// runSharedBinaryGarbageCollection removes the shared binaries which are not linked by any tenant anymore.
+// The links of the tenants are kept as long as volumes in the database use them, so both are counted as references.
+func (gc *CSIGarbageCollector) runSharedBinaryGarbageCollection() {
+	fs := &afero.Afero{Fs: gc.fs}
+
+	sharedBinaries, err := fs.ReadDir(gc.path.SharedAgentBinaryDir())
+	if os.IsNotExist(err) {
+		return
+	} else if err != nil {
+		log.Info("failed to get shared binaries", "error", err)
+		return
+	}
+
+	references, err := gc.countSharedBinaryReferences(fs)
+	if err != nil {
+		log.Info("failed to count references of shared binaries", "error", err)
+		return
+	}
+
+	for _, sharedBinary := range sharedBinaries {
+		sharedPath := gc.path.SharedAgentBinaryDirForKey(sharedBinary.Name())
+		// binaries being installed are not linked yet, they are only removed if the install was interrupted
+		if strings.HasPrefix(sharedBinary.Name(), ".") {
+			if time.Since(sharedBinary.ModTime()) > maxSharedBinaryInstallAge {
+				log.Info("deleting abandoned shared binary install", "path", sharedPath)
+				removeUnusedVersion(fs, sharedPath)
+			}
+			continue
+		}
+		if references[sharedPath] > 0 {
+			continue
+		}
+		log.Info("deleting unreferenced shared binary", "path", sharedPath)
+		removeUnusedVersion(fs, sharedPath)
+	}
+}
+
+// countSharedBinaryReferences counts the links from the binary directories of all tenants and the volumes using them per shared binary.
+func (gc *CSIGarbageCollector) countSharedBinaryReferences(fs *afero.Afero) (map[string]int, error) {
+	linkReader, ok := gc.fs.(afero.LinkReader)
+	if !ok {
+		return nil, errors.Errorf("filesystem %s does not support symlinks", gc.fs.Name())
+	}
+
+	envDirs, err := fs.ReadDir(gc.path.RootDir)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	references := map[string]int{}
+	for _, envDir := range envDirs {
+		if !envDir.IsDir() || envDir.Name() == dtcsi.SharedAgentBinaryDir {
+			continue
+		}
+		tenantUUID := envDir.Name()
+
+		binaries, err := fs.ReadDir(gc.path.AgentBinaryDir(tenantUUID))
+		if err != nil {
+			continue
+		}
+		usedVersions, err := gc.db.GetUsedVersions(tenantUUID)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, binary := range binaries {
+			if binary.Mode()&os.ModeSymlink == 0 {
+				continue
+			}
+			binaryPath := gc.path.AgentBinaryDirForVersion(tenantUUID, binary.Name())
+			target, err := linkReader.ReadlinkIfPossible(binaryPath)
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+			if !filepath.IsAbs(target) {
+				target = filepath.Join(filepath.Dir(binaryPath), target)
+			}
+
+			references[target]++
+			if usedVersions[binary.Name()] {
+				references[target]++
+			}
 		}
 	}
+	return references, nil
 }
 
 func (gc *CSIGarbageCollector) getStoredVersions(fs *afero.Afero, tenantUUID string) ([]string, error) {
@@ -62,6 +160,16 @@ func isNotLatestVersion(version string, latestVersion string) bool {
 	return true
 }
 
+func isNotPrePulled(version string, prePulledBinaries []string) bool {
+	for _, binary := range prePulledBinaries {
+		if version == binary {
+			log.Info("skipped, is pre-pulled")
+			return false
+		}
+	}
+	return true
+}
+
 func removeUnusedVersion(fs *afero.Afero, binaryPath string) {
 	size, _ := dirSize(fs, binaryPath)
 	err := fs.RemoveAll(binaryPath)
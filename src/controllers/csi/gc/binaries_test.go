@@ -2,8 +2,10 @@ package csigc
 
 import (
 	"fmt"
+	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	dtcsi "github.com/Dynatrace/dynatrace-operator/src/controllers/csi"
 	"github.com/Dynatrace/dynatrace-operator/src/controllers/csi/metadata"
@@ -11,6 +13,7 @@ import (
 	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/spf13/afero"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 const (
@@ -101,6 +104,97 @@ func TestBinaryGarbageCollector_ignoresUsed(t *testing.T) {
 	gc.assertVersionExists(t, version_1, version_2, version_3)
 }
 
+func TestBinaryGarbageCollector_ignoresPrePulled(t *testing.T) {
+	resetMetrics()
+	gc := NewMockGarbageCollector()
+	gc.mockUnusedVersions(version_1, version_2, version_2+"-musl", version_3)
+
+	gc.runBinaryGarbageCollection(tenantUUID, version_3, version_2, version_2+"-musl")
+
+	assert.Equal(t, float64(1), testutil.ToFloat64(gcRunsMetric))
+	assert.Equal(t, float64(1), testutil.ToFloat64(foldersRemovedMetric))
+
+	gc.assertVersionExists(t, version_2, version_2+"-musl", version_3)
+	gc.assertVersionNotExists(t, version_1)
+}
+
+func TestSharedBinaryGarbageCollector(t *testing.T) {
+	t.Run(`removes unreferenced shared binaries`, func(t *testing.T) {
+		resetMetrics()
+		gc := newMockSharedGarbageCollector(t.TempDir())
+		linkedDir := gc.mockSharedBinary(t, "1-musl-x86-abc")
+		unreferencedDir := gc.mockSharedBinary(t, "1-musl-x86-def")
+		installingDir := gc.mockSharedBinary(t, ".tenant-1")
+		abandonedDir := gc.mockSharedBinary(t, ".tenant-2")
+		abandonedTime := time.Now().Add(-2 * maxSharedBinaryInstallAge)
+		require.NoError(t, gc.fs.Chtimes(abandonedDir, abandonedTime, abandonedTime))
+		gc.mockSharedBinaryLink(t, tenantUUID, "1-musl", linkedDir)
+
+		gc.runSharedBinaryGarbageCollection()
+
+		assert.Equal(t, float64(2), testutil.ToFloat64(foldersRemovedMetric))
+		assertExists(t, gc.fs, linkedDir, true)
+		assertExists(t, gc.fs, unreferencedDir, false)
+		assertExists(t, gc.fs, installingDir, true)
+		assertExists(t, gc.fs, abandonedDir, false)
+	})
+	t.Run(`shared binary is kept while any tenant links it`, func(t *testing.T) {
+		resetMetrics()
+		gc := newMockSharedGarbageCollector(t.TempDir())
+		sharedDir := gc.mockSharedBinary(t, "1-musl-x86-abc")
+		gc.mockSharedBinaryLink(t, tenantUUID, "1-musl", sharedDir)
+		gc.mockSharedBinaryLink(t, "other", "1-musl", sharedDir)
+		_ = gc.db.InsertVolume(metadata.NewVolume("volume", "pod", "1-musl", "other"))
+
+		gc.runBinaryGarbageCollection(tenantUUID, version_2)
+		gc.runSharedBinaryGarbageCollection()
+
+		assertExists(t, gc.fs, gc.path.AgentBinaryDirForVersion(tenantUUID, "1-musl"), false)
+		assertExists(t, gc.fs, sharedDir, true)
+
+		gc.runBinaryGarbageCollection("other", version_2)
+		gc.runSharedBinaryGarbageCollection()
+
+		assertExists(t, gc.fs, sharedDir, true)
+
+		_ = gc.db.DeleteVolume("volume")
+		gc.runBinaryGarbageCollection("other", version_2)
+		gc.runSharedBinaryGarbageCollection()
+
+		assertExists(t, gc.fs, sharedDir, false)
+	})
+}
+
+func newMockSharedGarbageCollector(rootDir string) *CSIGarbageCollector {
+	return &CSIGarbageCollector{
+		opts: dtcsi.CSIOptions{RootDir: rootDir},
+		fs:   afero.NewOsFs(),
+		db:   metadata.FakeMemoryDB(),
+		path: metadata.PathResolver{RootDir: rootDir},
+	}
+}
+
+func (gc *CSIGarbageCollector) mockSharedBinary(t *testing.T, key string) string {
+	sharedDir := gc.path.SharedAgentBinaryDirForKey(key)
+	require.NoError(t, gc.fs.MkdirAll(filepath.Join(sharedDir, "agent"), 0755))
+	require.NoError(t, afero.WriteFile(gc.fs, filepath.Join(sharedDir, "agent", "file"), []byte("agent"), 0644))
+	return sharedDir
+}
+
+func (gc *CSIGarbageCollector) mockSharedBinaryLink(t *testing.T, tenantUUID string, version string, sharedDir string) {
+	binaryDir := gc.path.AgentBinaryDirForVersion(tenantUUID, version)
+	require.NoError(t, gc.fs.MkdirAll(filepath.Dir(binaryDir), 0755))
+	target, err := filepath.Rel(filepath.Dir(binaryDir), sharedDir)
+	require.NoError(t, err)
+	require.NoError(t, os.Symlink(target, binaryDir))
+}
+
+func assertExists(t *testing.T, fs afero.Fs, path string, expected bool) {
+	exists, err := afero.Exists(fs, path)
+	require.NoError(t, err)
+	assert.Equal(t, expected, exists, path)
+}
+
 func NewMockGarbageCollector() *CSIGarbageCollector {
 	return &CSIGarbageCollector{
 		opts: dtcsi.CSIOptions{RootDir: rootDir},
@@ -7,6 +7,7 @@ import (
 	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
 	dtcsi "github.com/Dynatrace/dynatrace-operator/src/controllers/csi"
 	"github.com/Dynatrace/dynatrace-operator/src/controllers/csi/metadata"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/csi/provisioner/arch"
 	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube"
 	"github.com/Dynatrace/dynatrace-operator/src/dtclient"
 	"github.com/spf13/afero"
@@ -83,8 +84,20 @@ func (gc *CSIGarbageCollector) Reconcile(ctx context.Context, request reconcile.
 		return reconcileResult, nil
 	}
 
+	currentVersion := dk.Version()
+	if currentVersion == "" {
+		currentVersion = dk.Status.LatestAgentVersionUnixPaas
+	}
+	var prePulledBinaries []string
+	for _, binary := range arch.PrePulledBinaries(&dk, currentVersion) {
+		prePulledBinaries = append(prePulledBinaries, binary.Name())
+	}
+
 	log.Info("running binary garbage collection")
-	gc.runBinaryGarbageCollection(ci.TenantUUID, latestAgentVersion)
+	gc.runBinaryGarbageCollection(ci.TenantUUID, latestAgentVersion, prePulledBinaries...)
+
+	log.Info("running shared binary garbage collection")
+	gc.runSharedBinaryGarbageCollection()
 
 	log.Info("running log garbage collection")
 	gc.runLogGarbageCollection(ci.TenantUUID)
@@ -49,8 +49,7 @@ func emptyMemoryDB() *SqliteAccess {
 
 func FakeMemoryDB() *SqliteAccess {
 	db := SqliteAccess{}
-	db.Setup(":memory:")
-	_ = db.createTables()
+	_ = db.Setup(":memory:")
 	return &db
 }
 
@@ -17,19 +17,34 @@ func NewDynakube(dynakubeName, tenantUUID, latestVersion string) *Dynakube {
 	return &Dynakube{dynakubeName, tenantUUID, latestVersion}
 }
 
+// AppVolumeType is the type of volumes that provide the code modules to application pods
+const AppVolumeType = "app"
+
 type Volume struct {
 	VolumeID   string
 	PodName    string
 	Version    string
 	TenantUUID string
+
+	// Optional fields, not set for volumes that were stored before they were introduced
+	PodNamespace   string
+	Flavor         string
+	MountTimestamp *time.Time
+	VolumeType     string
 }
 
-// NewVolume returns a new Volume if all fields are set.
+// NewVolume returns a new Volume if all required fields are set.
 func NewVolume(id, podUID, version, tenantUUID string) *Volume {
 	if id == "" || podUID == "" || version == "" || tenantUUID == "" {
 		return nil
 	}
-	return &Volume{id, podUID, version, tenantUUID}
+	return &Volume{
+		VolumeID:   id,
+		PodName:    podUID,
+		Version:    version,
+		TenantUUID: tenantUUID,
+		VolumeType: AppVolumeType,
+	}
 }
 
 type OsAgentVolume struct {
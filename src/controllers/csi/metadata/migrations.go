@@ -0,0 +1,192 @@
+package metadata
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+)
+
+const (
+	schemaVersionTableName       = "schema_version"
+	schemaVersionCreateStatement = `
+	CREATE TABLE IF NOT EXISTS schema_version (
+		Version INTEGER NOT NULL
+	);`
+
+	getSchemaVersionStatement = `
+	SELECT Version
+	FROM schema_version;
+	`
+
+	deleteSchemaVersionStatement = "DELETE FROM schema_version;"
+
+	insertSchemaVersionStatement = `
+	INSERT INTO schema_version (Version)
+	VALUES (?);
+	`
+
+	countTablesStatement = `
+	SELECT COUNT(*)
+	FROM sqlite_master
+	WHERE type = 'table' AND name != ?;
+	`
+
+	backupStatement = "VACUUM INTO ?;"
+
+	inMemoryPath = ":memory:"
+)
+
+// migration changes the schema of the database from the previous version to its version.
+// Migrations only ever move forward and must keep the schema usable by the previous version of the driver,
+// so only add tables or columns (with defaults) and never drop or rename them.
+// That way a CSI driver which was rolled back can still work with the database.
+type migration struct {
+	version     int
+	description string
+	statements  []string
+}
+
+// schemaMigrations are all the migrations of the database, ordered by version.
+// Append new migrations to the end, never change released ones.
+var schemaMigrations = []migration{
+	{
+		version:     1,
+		description: "create dynakubes, volumes and osagent_volumes tables",
+		statements: []string{
+			dynakubesCreateStatement,
+			volumesCreateStatement,
+			osAgentVolumesCreateStatement,
+		},
+	},
+	{
+		version:     2,
+		description: "add pod namespace, flavor, mount timestamp and volume type to volumes",
+		statements: []string{
+			"ALTER TABLE volumes ADD COLUMN PodNamespace VARCHAR NOT NULL DEFAULT '';",
+			"ALTER TABLE volumes ADD COLUMN Flavor VARCHAR NOT NULL DEFAULT '';",
+			"ALTER TABLE volumes ADD COLUMN MountTimestamp DATETIME;",
+			fmt.Sprintf("ALTER TABLE volumes ADD COLUMN VolumeType VARCHAR NOT NULL DEFAULT '%s';", AppVolumeType),
+		},
+	},
+}
+
+func latestSchemaVersion(migrations []migration) int {
+	if len(migrations) == 0 {
+		return 0
+	}
+	return migrations[len(migrations)-1].version
+}
+
+// migrate brings the schema of the database to the latest version.
+// Before the first pending migration is applied a backup of the database is taken next to it.
+func (a *SqliteAccess) migrate(path string) error {
+	return a.applyMigrations(path, schemaMigrations)
+}
+
+func (a *SqliteAccess) applyMigrations(path string, migrations []migration) error {
+	if _, err := a.conn.Exec(schemaVersionCreateStatement); err != nil {
+		return fmt.Errorf("couldn't create the table %s, err: %s", schemaVersionTableName, err)
+	}
+
+	currentVersion, err := a.getSchemaVersion()
+	if err != nil {
+		return err
+	}
+
+	latestVersion := latestSchemaVersion(migrations)
+	if currentVersion > latestVersion {
+		log.Info("database schema is newer than this version of the driver knows, continuing with the newer schema",
+			"schemaVersion", currentVersion, "latestKnownVersion", latestVersion)
+		return nil
+	}
+	if currentVersion == latestVersion {
+		return nil
+	}
+
+	backupPath, err := a.backup(path, currentVersion)
+	if err != nil {
+		return err
+	}
+
+	for _, m := range migrations {
+		if m.version <= currentVersion {
+			continue
+		}
+		log.Info("migrating database schema", "from", currentVersion, "to", m.version, "description", m.description)
+		if err := a.applyMigration(m); err != nil {
+			return fmt.Errorf("couldn't migrate the database from schema version %d to %d, the database was left at version %d (backup: '%s'), err: %s",
+				currentVersion,
+				m.version,
+				currentVersion,
+				backupPath,
+				err)
+		}
+		currentVersion = m.version
+	}
+	return nil
+}
+
+// applyMigration runs all statements of the migration and stores the new schema version in a single transaction,
+// so a failing migration leaves the database as it was.
+func (a *SqliteAccess) applyMigration(m migration) error {
+	tx, err := a.conn.Begin()
+	if err != nil {
+		return err
+	}
+	for _, statement := range m.statements {
+		if _, err := tx.Exec(statement); err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+	}
+	if _, err := tx.Exec(deleteSchemaVersionStatement); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	if _, err := tx.Exec(insertSchemaVersionStatement, m.version); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// getSchemaVersion returns the schema version of the database.
+// Databases created before schema versioning was introduced have no version and are treated as version 0,
+// the first migration only creates tables that don't exist yet, so it's safe to run on them.
+func (a *SqliteAccess) getSchemaVersion() (int, error) {
+	var version int
+	err := a.conn.QueryRow(getSchemaVersionStatement).Scan(&version)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("couldn't get the schema version of the database, err: %s", err)
+	}
+	return version, nil
+}
+
+// backup copies the database to `<path>.v<version>.bak` and returns the path of the copy.
+// Nothing is copied for in-memory databases or databases that have no tables yet.
+func (a *SqliteAccess) backup(path string, version int) (string, error) {
+	if path == "" || path == inMemoryPath {
+		return "", nil
+	}
+
+	var tableCount int
+	if err := a.conn.QueryRow(countTablesStatement, schemaVersionTableName).Scan(&tableCount); err != nil {
+		return "", fmt.Errorf("couldn't check the database for existing tables, err: %s", err)
+	}
+	if tableCount == 0 {
+		return "", nil
+	}
+
+	backupPath := fmt.Sprintf("%s.v%d.bak", path, version)
+	if err := os.Remove(backupPath); err != nil && !os.IsNotExist(err) {
+		return "", fmt.Errorf("couldn't remove old database backup '%s', err: %s", backupPath, err)
+	}
+	if _, err := a.conn.Exec(backupStatement, backupPath); err != nil {
+		return "", fmt.Errorf("couldn't back up the database to '%s', err: %s", backupPath, err)
+	}
+	log.Info("backed up database before migrating", "path", backupPath, "schemaVersion", version)
+	return backupPath, nil
+}
@@ -0,0 +1,132 @@
+package metadata
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	legacyVolumesCreateStatement = `
+	CREATE TABLE volumes (
+		ID VARCHAR NOT NULL,
+		PodName VARCHAR NOT NULL,
+		Version VARCHAR NOT NULL,
+		TenantUUID VARCHAR NOT NULL,
+		PRIMARY KEY (ID)
+	);`
+	legacyInsertVolumeStatement = "INSERT INTO volumes (ID, PodName, Version, TenantUUID) VALUES (?,?,?,?);"
+)
+
+func TestMigrate_freshDB(t *testing.T) {
+	db := emptyMemoryDB()
+
+	err := db.migrate(inMemoryPath)
+	require.NoError(t, err)
+
+	version, err := db.getSchemaVersion()
+	require.NoError(t, err)
+	assert.Equal(t, latestSchemaVersion(schemaMigrations), version)
+	assert.True(t, checkIfTablesExist(db))
+
+	t.Run(`migrating again does nothing`, func(t *testing.T) {
+		err := db.migrate(inMemoryPath)
+		require.NoError(t, err)
+
+		version, err := db.getSchemaVersion()
+		require.NoError(t, err)
+		assert.Equal(t, latestSchemaVersion(schemaMigrations), version)
+	})
+}
+
+func TestMigrate_unversionedDB(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "csi.db")
+	db := SqliteAccess{}
+	require.NoError(t, db.connect(sqliteDriverName, path))
+	_, err := db.conn.Exec(legacyVolumesCreateStatement)
+	require.NoError(t, err)
+	_, err = db.conn.Exec(legacyInsertVolumeStatement, testVolume1.VolumeID, testVolume1.PodName, testVolume1.Version, testVolume1.TenantUUID)
+	require.NoError(t, err)
+
+	err = db.migrate(path)
+	require.NoError(t, err)
+
+	t.Run(`keeps existing rows`, func(t *testing.T) {
+		volume, err := db.GetVolume(testVolume1.VolumeID)
+		require.NoError(t, err)
+		require.NotNil(t, volume)
+		assert.Equal(t, testVolume1.PodName, volume.PodName)
+		assert.Equal(t, testVolume1.Version, volume.Version)
+		assert.Equal(t, AppVolumeType, volume.VolumeType)
+		assert.Empty(t, volume.PodNamespace)
+		assert.Nil(t, volume.MountTimestamp)
+	})
+	t.Run(`creates missing tables`, func(t *testing.T) {
+		assert.True(t, checkIfTablesExist(&db))
+	})
+	t.Run(`takes a backup`, func(t *testing.T) {
+		backup := SqliteAccess{}
+		require.NoError(t, backup.connect(sqliteDriverName, path+".v0.bak"))
+
+		var podName string
+		err := backup.conn.QueryRow("SELECT PodName FROM volumes WHERE ID = ?;", testVolume1.VolumeID).Scan(&podName)
+		require.NoError(t, err)
+		assert.Equal(t, testVolume1.PodName, podName)
+	})
+}
+
+func TestMigrate_emptyFileDB(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "csi.db")
+	db := SqliteAccess{}
+	require.NoError(t, db.connect(sqliteDriverName, path))
+
+	err := db.migrate(path)
+	require.NoError(t, err)
+
+	_, err = os.Stat(path + ".v0.bak")
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestApplyMigrations(t *testing.T) {
+	t.Run(`failing migration is rolled back`, func(t *testing.T) {
+		db := emptyMemoryDB()
+		migrations := []migration{
+			schemaMigrations[0],
+			{
+				version: 2,
+				statements: []string{
+					"ALTER TABLE volumes ADD COLUMN Something VARCHAR NOT NULL DEFAULT '';",
+					"ALTER TABLE missing ADD COLUMN Something VARCHAR;",
+				},
+			},
+		}
+
+		err := db.applyMigrations(inMemoryPath, migrations)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "left at version 1")
+
+		version, err := db.getSchemaVersion()
+		require.NoError(t, err)
+		assert.Equal(t, 1, version)
+
+		_, err = db.conn.Exec("SELECT Something FROM volumes;")
+		assert.Error(t, err)
+	})
+	t.Run(`newer schema is kept`, func(t *testing.T) {
+		db := FakeMemoryDB()
+		_, err := db.conn.Exec(deleteSchemaVersionStatement)
+		require.NoError(t, err)
+		_, err = db.conn.Exec(insertSchemaVersionStatement, latestSchemaVersion(schemaMigrations)+1)
+		require.NoError(t, err)
+
+		err = db.migrate(inMemoryPath)
+		require.NoError(t, err)
+
+		version, err := db.getSchemaVersion()
+		require.NoError(t, err)
+		assert.Equal(t, latestSchemaVersion(schemaMigrations)+1, version)
+	})
+}
@@ -38,6 +38,20 @@ func (pr PathResolver) AgentBinaryDirForVersion(tenantUUID string, version strin
 	return filepath.Join(pr.AgentBinaryDir(tenantUUID), version)
 }
 
+// AgentConfigDirForVersion is the directory of the tenant specific files of a shared binary, which are mounted on top of it.
+func (pr PathResolver) AgentConfigDirForVersion(tenantUUID string, version string) string {
+	return filepath.Join(pr.EnvDir(tenantUUID), dtcsi.AgentConfigDir, version)
+}
+
+// SharedAgentBinaryDir is the directory of the code modules shared by all tenants on the node.
+func (pr PathResolver) SharedAgentBinaryDir() string {
+	return filepath.Join(pr.RootDir, dtcsi.SharedAgentBinaryDir)
+}
+
+func (pr PathResolver) SharedAgentBinaryDirForKey(key string) string {
+	return filepath.Join(pr.SharedAgentBinaryDir(), key)
+}
+
 func (pr PathResolver) InnerAgentBinaryDirForSymlinkForVersion(tenantUUID string, version string) string {
 	return filepath.Join(pr.AgentBinaryDirForVersion(tenantUUID, version), "agent", "bin", "current")
 }
@@ -19,6 +19,9 @@ func TestPathResolver(t *testing.T) {
 	assert.Equal(t, fakeEnv, pathResolver.EnvDir(tenantUUID))
 	assert.Equal(t, filepath.Join(fakeEnv, "bin"), pathResolver.AgentBinaryDir(tenantUUID))
 	assert.Equal(t, filepath.Join(fakeEnv, "bin", "v1"), pathResolver.AgentBinaryDirForVersion(tenantUUID, "v1"))
+	assert.Equal(t, filepath.Join(fakeEnv, "config", "v1"), pathResolver.AgentConfigDirForVersion(tenantUUID, "v1"))
+	assert.Equal(t, filepath.Join(rootDir, "_shared"), pathResolver.SharedAgentBinaryDir())
+	assert.Equal(t, filepath.Join(rootDir, "_shared", "v1-musl-x86-abc"), pathResolver.SharedAgentBinaryDirForKey("v1-musl-x86-abc"))
 	assert.Equal(t, filepath.Join(fakeEnv, "run"), pathResolver.AgentRunDir(tenantUUID))
 	assert.Equal(t, agentRunDirForVolume, pathResolver.AgentRunDirForVolume(tenantUUID, fakeVolume))
 	assert.Equal(t, filepath.Join(agentRunDirForVolume, "mapped"), pathResolver.OverlayMappedDir(tenantUUID, fakeVolume))
@@ -48,12 +48,16 @@ const (
 	`
 
 	insertVolumeStatement = `
-	INSERT INTO volumes (ID, PodName, Version, TenantUUID)
-	VALUES (?,?,?,?)
+	INSERT INTO volumes (ID, PodName, Version, TenantUUID, PodNamespace, Flavor, MountTimestamp, VolumeType)
+	VALUES (?,?,?,?,?,?,?,?)
 	ON CONFLICT(ID) DO UPDATE SET
 	  PodName=excluded.PodName,
 	  Version=excluded.Version,
-	  TenantUUID=excluded.TenantUUID;
+	  TenantUUID=excluded.TenantUUID,
+	  PodNamespace=excluded.PodNamespace,
+	  Flavor=excluded.Flavor,
+	  MountTimestamp=excluded.MountTimestamp,
+	  VolumeType=excluded.VolumeType;
 	`
 
 	insertOsAgentVolumeStatement = `
@@ -82,7 +86,7 @@ const (
 	`
 
 	getVolumeStatement = `
-	SELECT PodName, Version, TenantUUID
+	SELECT PodName, Version, TenantUUID, PodNamespace, Flavor, MountTimestamp, VolumeType
 	FROM volumes
 	WHERE ID = ?;
 	`
@@ -148,25 +152,12 @@ func (a *SqliteAccess) connect(driver, path string) error {
 	return nil
 }
 
-func (a *SqliteAccess) createTables() error {
-	if _, err := a.conn.Exec(dynakubesCreateStatement); err != nil {
-		return fmt.Errorf("couldn't create the table %s, err: %s", dynakubesTableName, err)
-	}
-	if _, err := a.conn.Exec(volumesCreateStatement); err != nil {
-		return fmt.Errorf("couldn't create the table %s, err: %s", volumesTableName, err)
-	}
-	if _, err := a.conn.Exec(osAgentVolumesCreateStatement); err != nil {
-		return fmt.Errorf("couldn't create the table %s, err: %s", osAgentVolumesTableName, err)
-	}
-	return nil
-}
-
-// Setup connects to the database and creates the necessary tables if they don't exist
+// Setup connects to the database and migrates its schema to the latest version
 func (a *SqliteAccess) Setup(path string) error {
 	if err := a.connect(sqliteDriverName, path); err != nil {
 		return err
 	}
-	if err := a.createTables(); err != nil {
+	if err := a.migrate(path); err != nil {
 		return err
 	}
 	return nil
@@ -220,7 +211,15 @@ func (a *SqliteAccess) GetDynakube(dynakubeName string) (*Dynakube, error) {
 
 // InsertVolume inserts a new Volume
 func (a *SqliteAccess) InsertVolume(volume *Volume) error {
-	err := a.executeStatement(insertVolumeStatement, volume.VolumeID, volume.PodName, volume.Version, volume.TenantUUID)
+	err := a.executeStatement(insertVolumeStatement,
+		volume.VolumeID,
+		volume.PodName,
+		volume.Version,
+		volume.TenantUUID,
+		volume.PodNamespace,
+		volume.Flavor,
+		volume.MountTimestamp,
+		volume.VolumeType)
 	if err != nil {
 		err = fmt.Errorf("couldn't insert volume info, volume id '%s', pod '%s', version '%s', dynakube '%s', err: %s",
 			volume.VolumeID,
@@ -237,11 +236,24 @@ func (a *SqliteAccess) GetVolume(volumeID string) (*Volume, error) {
 	var PodName string
 	var version string
 	var tenantUUID string
-	err := a.querySimpleStatement(getVolumeStatement, volumeID, &PodName, &version, &tenantUUID)
+	var podNamespace string
+	var flavor string
+	var mountTimestamp sql.NullTime
+	var volumeType string
+	err := a.querySimpleStatement(getVolumeStatement, volumeID, &PodName, &version, &tenantUUID, &podNamespace, &flavor, &mountTimestamp, &volumeType)
 	if err != nil {
 		err = fmt.Errorf("couldn't get volume field for volume id '%s', err: %s", volumeID, err)
 	}
-	return NewVolume(volumeID, PodName, version, tenantUUID), err
+	volume := NewVolume(volumeID, PodName, version, tenantUUID)
+	if volume != nil {
+		volume.PodNamespace = podNamespace
+		volume.Flavor = flavor
+		volume.VolumeType = volumeType
+		if mountTimestamp.Valid {
+			volume.MountTimestamp = &mountTimestamp.Time
+		}
+	}
+	return volume, err
 }
 
 // DeleteVolume deletes a Volume by its ID
@@ -47,10 +47,10 @@ func TestConnect_badDriver(t *testing.T) {
 	assert.Nil(t, db.conn)
 }
 
-func TestCreateTables(t *testing.T) {
+func TestMigrate(t *testing.T) {
 	db := emptyMemoryDB()
 
-	err := db.createTables()
+	err := db.migrate(":memory:")
 
 	assert.Nil(t, err)
 
@@ -160,7 +160,7 @@ func TestInsertVolume(t *testing.T) {
 
 	err := db.InsertVolume(&testVolume1)
 	require.NoError(t, err)
-	row := db.conn.QueryRow(fmt.Sprintf("SELECT * FROM %s WHERE ID = ?;", volumesTableName), testVolume1.VolumeID)
+	row := db.conn.QueryRow(fmt.Sprintf("SELECT ID, PodName, Version, TenantUUID FROM %s WHERE ID = ?;", volumesTableName), testVolume1.VolumeID)
 	var id string
 	var puid string
 	var ver string
@@ -175,7 +175,7 @@ func TestInsertVolume(t *testing.T) {
 	testVolume1.PodName = newPodName
 	err = db.InsertVolume(&testVolume1)
 	require.NoError(t, err)
-	row = db.conn.QueryRow(fmt.Sprintf("SELECT * FROM %s WHERE ID = ?;", volumesTableName), testVolume1.VolumeID)
+	row = db.conn.QueryRow(fmt.Sprintf("SELECT ID, PodName, Version, TenantUUID FROM %s WHERE ID = ?;", volumesTableName), testVolume1.VolumeID)
 	err = row.Scan(&id, &puid, &ver, &tuid)
 	require.NoError(t, err)
 	assert.Equal(t, id, testVolume1.VolumeID)
@@ -291,6 +291,25 @@ func TestGetVolume(t *testing.T) {
 	assert.Equal(t, testVolume1, *volume)
 }
 
+func TestGetVolume_optionalFields(t *testing.T) {
+	db := FakeMemoryDB()
+	mountTimestamp := time.Now().UTC().Truncate(time.Second)
+	volume := *NewVolume(testVolume2.VolumeID, testVolume2.PodName, testVolume2.Version, testVolume2.TenantUUID)
+	volume.PodNamespace = "namespace"
+	volume.Flavor = "musl"
+	volume.MountTimestamp = &mountTimestamp
+	err := db.InsertVolume(&volume)
+	require.NoError(t, err)
+
+	stored, err := db.GetVolume(volume.VolumeID)
+	require.NoError(t, err)
+	require.NotNil(t, stored.MountTimestamp)
+	assert.True(t, mountTimestamp.Equal(*stored.MountTimestamp))
+	stored.MountTimestamp = &mountTimestamp
+	assert.Equal(t, volume, *stored)
+	assert.Equal(t, AppVolumeType, stored.VolumeType)
+}
+
 func TestGetUsedVersions(t *testing.T) {
 	db := FakeMemoryDB()
 	err := db.InsertVolume(&testVolume1)
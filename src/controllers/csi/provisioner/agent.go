@@ -23,6 +23,7 @@ type agentUpdater struct {
 
 func newAgentUpdater(
 	dtc dtclient.Client,
+	source installer.ArtifactSource,
 	path metadata.PathResolver,
 	fs afero.Fs,
 	recorder record.EventRecorder,
@@ -37,7 +38,10 @@ func newAgentUpdater(
 			Arch:         arch.Arch,
 			Flavor:       arch.Flavor,
 			Technologies: []string{"all"},
+
+			AllowUnverified: dk.FeatureAllowUnverifiedOneAgentDownloads(),
 		},
+		source,
 	)
 	return &agentUpdater{
 		fs:        fs,
@@ -59,8 +63,7 @@ func (updater *agentUpdater) updateAgent(installedVersion, tenantUUID string, pr
 			"installed version", installedVersion,
 			"target directory", targetDir)
 
-		updater.installer.SetVersion(targetVersion)
-		if err := updater.installer.InstallAgent(targetDir); err != nil {
+		if err := updater.installBinary(tenantUUID, arch.Binary{Version: targetVersion, Flavor: arch.Flavor}); err != nil {
 			updater.recorder.Eventf(dk,
 				corev1.EventTypeWarning,
 				failedInstallAgentVersionEvent,
@@ -68,7 +71,7 @@ func (updater *agentUpdater) updateAgent(installedVersion, tenantUUID string, pr
 			return "", err
 		}
 		log.Info("updating ruxitagentproc.conf on new version")
-		if err := updater.installer.UpdateProcessModuleConfig(targetDir, latestProcessModuleConfigCache.ProcessModuleConfig); err != nil {
+		if err := updater.updateProcessModuleConfig(tenantUUID, targetVersion, latestProcessModuleConfigCache); err != nil {
 			return "", err
 		}
 		updater.recorder.Eventf(dk,
@@ -87,14 +90,14 @@ func (updater *agentUpdater) updateAgent(installedVersion, tenantUUID string, pr
 			installAgentVersionEvent,
 			"Set new agent version: %s to tenant: %s", targetVersion, tenantUUID)
 		log.Info("updating ruxitagentproc.conf on new set version")
-		if err := updater.installer.UpdateProcessModuleConfig(targetDir, latestProcessModuleConfigCache.ProcessModuleConfig); err != nil {
+		if err := updater.updateProcessModuleConfig(tenantUUID, targetVersion, latestProcessModuleConfigCache); err != nil {
 			return "", err
 		}
 		return targetVersion, nil
 	}
 	if latestProcessModuleConfigCache != nil && previousHash != latestProcessModuleConfigCache.Hash {
 		log.Info("updating ruxitagentproc.conf on latest installed version")
-		if err := updater.installer.UpdateProcessModuleConfig(targetDir, latestProcessModuleConfigCache.ProcessModuleConfig); err != nil {
+		if err := updater.updateProcessModuleConfig(tenantUUID, targetVersion, latestProcessModuleConfigCache); err != nil {
 			return "", err
 		}
 	}
@@ -102,6 +105,45 @@ func (updater *agentUpdater) updateAgent(installedVersion, tenantUUID string, pr
 	return "", nil
 }
 
+// prePullAgents installs the pre-pulled code modules of the DynaKube which are missing on the node
+// and keeps their ruxitagentproc.conf up to date, the version in use is left to updateAgent.
+func (updater *agentUpdater) prePullAgents(tenantUUID string, previousHash string, latestProcessModuleConfigCache *processModuleConfigCache) error {
+	dk := updater.dk
+	currentVersion := updater.getOneAgentVersionFromInstance()
+
+	for _, binary := range arch.PrePulledBinaries(dk, currentVersion) {
+		if binary.Name() == currentVersion {
+			continue
+		}
+		targetDir := updater.path.AgentBinaryDirForVersion(tenantUUID, binary.Name())
+
+		if _, err := updater.fs.Stat(targetDir); os.IsNotExist(err) {
+			log.Info("pre-pulling agent", "version", binary.Version, "flavor", binary.Flavor, "target directory", targetDir)
+
+			if err := updater.installBinary(tenantUUID, binary); err != nil {
+				updater.recorder.Eventf(dk,
+					corev1.EventTypeWarning,
+					failedInstallAgentVersionEvent,
+					"Failed to pre-pull agent version: %s flavor: %s to tenant: %s, err: %s", binary.Version, binary.Flavor, tenantUUID, err)
+				return err
+			}
+			if err := updater.updateProcessModuleConfig(tenantUUID, binary.Name(), latestProcessModuleConfigCache); err != nil {
+				return err
+			}
+			updater.recorder.Eventf(dk,
+				corev1.EventTypeNormal,
+				installAgentVersionEvent,
+				"Pre-pulled agent version: %s flavor: %s to tenant: %s", binary.Version, binary.Flavor, tenantUUID)
+		} else if latestProcessModuleConfigCache != nil && previousHash != latestProcessModuleConfigCache.Hash {
+			log.Info("updating ruxitagentproc.conf on pre-pulled agent", "version", binary.Version, "flavor", binary.Flavor)
+			if err := updater.updateProcessModuleConfig(tenantUUID, binary.Name(), latestProcessModuleConfigCache); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 func (updater *agentUpdater) getOneAgentVersionFromInstance() string {
 	dk := updater.dk
 	currentVersion := dk.Status.LatestAgentVersionUnixPaas
@@ -6,6 +6,7 @@ import (
 
 	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
 	"github.com/Dynatrace/dynatrace-operator/src/controllers/csi/metadata"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/csi/provisioner/arch"
 	"github.com/Dynatrace/dynatrace-operator/src/dtclient"
 	"github.com/Dynatrace/dynatrace-operator/src/installer"
 	t_utils "github.com/Dynatrace/dynatrace-operator/src/testing"
@@ -23,7 +24,7 @@ const (
 
 func TestNewAgentUpdater(t *testing.T) {
 	t.Run(`create`, func(t *testing.T) {
-		createTestAgentUpdater(t, nil)
+		createTestAgentUpdater(t, &dynatracev1beta1.DynaKube{})
 	})
 }
 
@@ -82,6 +83,12 @@ func TestUpdateAgent(t *testing.T) {
 		updater.installer.(*installer.InstallerMock).
 			On("SetVersion", testVersion).
 			Return()
+		updater.installer.(*installer.InstallerMock).
+			On("SetFlavor", arch.Flavor).
+			Return()
+		updater.installer.(*installer.InstallerMock).
+			On("SetSha256", "").
+			Return()
 		updater.installer.(*installer.InstallerMock).
 			On("InstallAgent", targetDir).
 			Return(nil)
@@ -185,6 +192,12 @@ func TestUpdateAgent(t *testing.T) {
 		updater.installer.(*installer.InstallerMock).
 			On("SetVersion", testVersion).
 			Return()
+		updater.installer.(*installer.InstallerMock).
+			On("SetFlavor", arch.Flavor).
+			Return()
+		updater.installer.(*installer.InstallerMock).
+			On("SetSha256", "").
+			Return()
 		updater.installer.(*installer.InstallerMock).
 			On("InstallAgent", targetDir).
 			Return(fmt.Errorf("BOOM"))
@@ -209,6 +222,89 @@ func TestUpdateAgent(t *testing.T) {
 	})
 }
 
+func TestPrePullAgents(t *testing.T) {
+	dk := dynatracev1beta1.DynaKube{
+		Spec: dynatracev1beta1.DynaKubeSpec{
+			OneAgent: dynatracev1beta1.OneAgentSpec{
+				CloudNativeFullStack: &dynatracev1beta1.CloudNativeFullStackSpec{
+					AppInjectionSpec: dynatracev1beta1.AppInjectionSpec{
+						CodeModulesPrePull: &dynatracev1beta1.CodeModulesPrePullSpec{
+							Versions: []string{"other"},
+							Flavors:  []string{dtclient.FlavorMusl},
+						},
+					},
+					Version: testVersion,
+				},
+			},
+		},
+	}
+	prePulledDirs := func(updater *agentUpdater) []string {
+		return []string{
+			updater.path.AgentBinaryDirForVersion(testTenantUUID, arch.BinaryName(testVersion, dtclient.FlavorMusl)),
+			updater.path.AgentBinaryDirForVersion(testTenantUUID, "other"),
+			updater.path.AgentBinaryDirForVersion(testTenantUUID, arch.BinaryName("other", dtclient.FlavorMusl)),
+		}
+	}
+
+	t.Run(`install missing`, func(t *testing.T) {
+		updater := createTestAgentUpdater(t, &dk)
+		processModuleCache := createTestProcessModuleConfigCache("1")
+		installerMock := updater.installer.(*installer.InstallerMock)
+		installerMock.On("SetVersion", mock.Anything).Return()
+		installerMock.On("SetFlavor", mock.Anything).Return()
+		installerMock.On("SetSha256", mock.Anything).Return()
+		for _, targetDir := range prePulledDirs(updater) {
+			installerMock.On("InstallAgent", targetDir).Return(nil)
+			installerMock.On("UpdateProcessModuleConfig", targetDir, &testProcessModuleConfig).Return(nil)
+		}
+
+		err := updater.prePullAgents(testTenantUUID, "", &processModuleCache)
+
+		require.NoError(t, err)
+		installerMock.AssertNumberOfCalls(t, "InstallAgent", 3)
+		installerMock.AssertCalled(t, "SetFlavor", dtclient.FlavorMusl)
+		installerMock.AssertCalled(t, "SetVersion", "other")
+		installerMock.AssertNotCalled(t, "InstallAgent", updater.path.AgentBinaryDirForVersion(testTenantUUID, testVersion))
+	})
+	t.Run(`only process module config update`, func(t *testing.T) {
+		updater := createTestAgentUpdater(t, &dk)
+		processModuleCache := createTestProcessModuleConfigCache("other")
+		installerMock := updater.installer.(*installer.InstallerMock)
+		for _, targetDir := range prePulledDirs(updater) {
+			_ = updater.fs.MkdirAll(targetDir, 0755)
+			installerMock.On("UpdateProcessModuleConfig", targetDir, &testProcessModuleConfig).Return(nil)
+		}
+
+		err := updater.prePullAgents(testTenantUUID, "1", &processModuleCache)
+
+		require.NoError(t, err)
+		installerMock.AssertNotCalled(t, "InstallAgent", mock.Anything)
+		installerMock.AssertNumberOfCalls(t, "UpdateProcessModuleConfig", 3)
+	})
+	t.Run(`failed install`, func(t *testing.T) {
+		updater := createTestAgentUpdater(t, &dk)
+		processModuleCache := createTestProcessModuleConfigCache("1")
+		installerMock := updater.installer.(*installer.InstallerMock)
+		installerMock.On("SetVersion", mock.Anything).Return()
+		installerMock.On("SetFlavor", mock.Anything).Return()
+		installerMock.On("SetSha256", mock.Anything).Return()
+		installerMock.On("InstallAgent", mock.Anything).Return(fmt.Errorf("BOOM"))
+
+		err := updater.prePullAgents(testTenantUUID, "", &processModuleCache)
+
+		require.Error(t, err)
+		t_utils.AssertEvents(t,
+			updater.recorder.(*record.FakeRecorder).Events,
+			t_utils.Events{
+				t_utils.Event{
+					EventType: corev1.EventTypeWarning,
+					Reason:    failedInstallAgentVersionEvent,
+				},
+			},
+		)
+	})
+}
+
 func updateOneagent(t *testing.T, alreadyInstalled bool) {
 	dk := dynatracev1beta1.DynaKube{
 		Spec: dynatracev1beta1.DynaKubeSpec{
@@ -228,6 +324,12 @@ func updateOneagent(t *testing.T, alreadyInstalled bool) {
 	updater.installer.(*installer.InstallerMock).
 		On("SetVersion", testVersion).
 		Return()
+	updater.installer.(*installer.InstallerMock).
+		On("SetFlavor", arch.Flavor).
+		Return()
+	updater.installer.(*installer.InstallerMock).
+		On("SetSha256", "").
+		Return()
 	updater.installer.(*installer.InstallerMock).
 		On("InstallAgent", targetDir).
 		Run(func(args mock.Arguments) {
@@ -268,7 +370,7 @@ func createTestAgentUpdater(t *testing.T, dk *dynatracev1beta1.DynaKube) *agentU
 	fs := afero.NewMemMapFs()
 	rec := record.NewFakeRecorder(10)
 
-	updater := newAgentUpdater(&client, path, fs, rec, dk)
+	updater := newAgentUpdater(&client, nil, path, fs, rec, dk)
 	require.NotNil(t, updater)
 	assert.NotNil(t, updater.installer)
 
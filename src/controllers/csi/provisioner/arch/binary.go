@@ -0,0 +1,73 @@
+package arch
+
+import (
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+)
+
+// Binary is a version and flavor of the code modules stored on the node, always of the architecture of the node.
+type Binary struct {
+	Version string
+	Flavor  string
+}
+
+// Name returns the name of the directory the binary is stored in. The default flavor of the node uses the plain version,
+// which is where the code modules were stored before other flavors could be pre-pulled.
+func (binary Binary) Name() string {
+	return BinaryName(binary.Version, binary.Flavor)
+}
+
+// BinaryName returns the name of the directory the code modules of the given version and flavor are stored in.
+func BinaryName(version, flavor string) string {
+	if flavor == "" || flavor == Flavor {
+		return version
+	}
+	return version + "-" + flavor
+}
+
+// PrePulledBinaries returns the code modules the node keeps for the DynaKube: the version in use in the default flavor
+// of the node, and every combination of the pre-pulled versions and flavors, if the architecture of the node is pre-pulled.
+func PrePulledBinaries(dk *dynatracev1beta1.DynaKube, currentVersion string) []Binary {
+	var binaries []Binary
+	if currentVersion != "" {
+		binaries = append(binaries, Binary{Version: currentVersion, Flavor: Flavor})
+	}
+
+	prePull := dk.CodeModulesPrePull()
+	if prePull == nil || (len(prePull.Architectures) > 0 && !contains(prePull.Architectures, Arch)) {
+		return binaries
+	}
+
+	versions := prePull.Versions
+	if currentVersion != "" {
+		versions = append([]string{currentVersion}, versions...)
+	}
+	flavors := append([]string{Flavor}, prePull.Flavors...)
+
+	for _, version := range versions {
+		for _, flavor := range flavors {
+			binary := Binary{Version: version, Flavor: flavor}
+			if !containsBinary(binaries, binary) {
+				binaries = append(binaries, binary)
+			}
+		}
+	}
+	return binaries
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func containsBinary(binaries []Binary, binary Binary) bool {
+	for _, b := range binaries {
+		if b.Name() == binary.Name() {
+			return true
+		}
+	}
+	return false
+}
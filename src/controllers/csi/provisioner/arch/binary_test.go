@@ -0,0 +1,66 @@
+package arch
+
+import (
+	"testing"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	"github.com/Dynatrace/dynatrace-operator/src/dtclient"
+	"github.com/stretchr/testify/assert"
+)
+
+const otherArch = "s390"
+
+func TestBinaryName(t *testing.T) {
+	assert.Equal(t, "1.2.3", BinaryName("1.2.3", ""))
+	assert.Equal(t, "1.2.3", BinaryName("1.2.3", Flavor))
+	assert.Equal(t, "1.2.3-musl", BinaryName("1.2.3", dtclient.FlavorMusl))
+}
+
+func TestPrePulledBinaries(t *testing.T) {
+	newDynakube := func(prePull *dynatracev1beta1.CodeModulesPrePullSpec) *dynatracev1beta1.DynaKube {
+		return &dynatracev1beta1.DynaKube{
+			Spec: dynatracev1beta1.DynaKubeSpec{
+				OneAgent: dynatracev1beta1.OneAgentSpec{
+					ApplicationMonitoring: &dynatracev1beta1.ApplicationMonitoringSpec{
+						AppInjectionSpec: dynatracev1beta1.AppInjectionSpec{CodeModulesPrePull: prePull},
+					},
+				},
+			},
+		}
+	}
+
+	t.Run(`only current version without pre-pull`, func(t *testing.T) {
+		assert.Equal(t, []Binary{{Version: "2", Flavor: Flavor}}, PrePulledBinaries(newDynakube(nil), "2"))
+		assert.Empty(t, PrePulledBinaries(newDynakube(nil), ""))
+	})
+	t.Run(`combinations of versions and flavors`, func(t *testing.T) {
+		binaries := PrePulledBinaries(newDynakube(&dynatracev1beta1.CodeModulesPrePullSpec{
+			Versions: []string{"1", "2"},
+			Flavors:  []string{dtclient.FlavorMusl, Flavor},
+		}), "2")
+
+		assert.Equal(t, []Binary{
+			{Version: "2", Flavor: Flavor},
+			{Version: "2", Flavor: dtclient.FlavorMusl},
+			{Version: "1", Flavor: Flavor},
+			{Version: "1", Flavor: dtclient.FlavorMusl},
+		}, binaries)
+	})
+	t.Run(`other architectures only keep current version`, func(t *testing.T) {
+		binaries := PrePulledBinaries(newDynakube(&dynatracev1beta1.CodeModulesPrePullSpec{
+			Versions:      []string{"1"},
+			Flavors:       []string{dtclient.FlavorMusl},
+			Architectures: []string{otherArch},
+		}), "2")
+
+		assert.Equal(t, []Binary{{Version: "2", Flavor: Flavor}}, binaries)
+	})
+	t.Run(`architecture of node`, func(t *testing.T) {
+		binaries := PrePulledBinaries(newDynakube(&dynatracev1beta1.CodeModulesPrePullSpec{
+			Versions:      []string{"1"},
+			Architectures: []string{otherArch, Arch},
+		}), "2")
+
+		assert.Equal(t, []Binary{{Version: "2", Flavor: Flavor}, {Version: "1", Flavor: Flavor}}, binaries)
+	})
+}
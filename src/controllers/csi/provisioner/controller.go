@@ -139,7 +139,13 @@ func (provisioner *OneAgentProvisioner) Reconcile(ctx context.Context, request r
 	latestProcessModuleConfig = latestProcessModuleConfig.AddHostGroup(dk.HostGroup())
 	latestProcessModuleConfigCache := newProcessModuleConfigCache(latestProcessModuleConfig)
 
-	agentUpdater := newAgentUpdater(dtc, provisioner.path, provisioner.fs, provisioner.recorder, dk)
+	artifactSource, err := provisioner.buildArtifactSource(ctx, dk, dtc)
+	if err != nil {
+		log.Info("error when configuring the code modules source", "error", err.Error())
+		return reconcile.Result{RequeueAfter: defaultRequeueDuration}, nil
+	}
+
+	agentUpdater := newAgentUpdater(dtc, artifactSource, provisioner.path, provisioner.fs, provisioner.recorder, dk)
 	if updatedVersion, err := agentUpdater.updateAgent(dynakube.LatestVersion, dynakube.TenantUUID, storedHash, latestProcessModuleConfigCache); err != nil {
 		log.Info("error when updating agent", "error", err.Error())
 		// reporting error but not returning it to avoid immediate requeue and subsequently calling the API every few seconds
@@ -148,6 +154,11 @@ func (provisioner *OneAgentProvisioner) Reconcile(ctx context.Context, request r
 		dynakube.LatestVersion = updatedVersion
 	}
 
+	if err := agentUpdater.prePullAgents(dynakube.TenantUUID, storedHash, latestProcessModuleConfigCache); err != nil {
+		// the version in use is ready, so pods can still be served while pre-pulling is retried with the next reconcile
+		log.Info("error when pre-pulling agents", "error", err.Error())
+	}
+
 	// Set/Update the `LatestVersion` field in the database entry
 	err = provisioner.createOrUpdateDynakube(oldDynakube, dynakube)
 	if err != nil {
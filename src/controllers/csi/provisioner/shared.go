@@ -0,0 +1,192 @@
+package csiprovisioner
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/csi/provisioner/arch"
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+)
+
+const (
+	// tmpSharedBinaryPrefix marks shared binaries which are still being installed, they are ignored by the garbage collector
+	tmpSharedBinaryPrefix = "."
+
+	sharedBinaryHashLength = 16
+)
+
+var processModuleConfigPath = filepath.Join("agent", "conf", "ruxitagentproc.conf")
+
+// installBinary makes the binary available to the tenant. The code modules are stored once per node in the shared directory,
+// keyed by version, flavor, architecture and the hash of their content, and linked into the binary directory of the tenant.
+// Every tenant downloads the code modules itself, as they contain its ruxitagentproc.conf, which is left out of the hash.
+func (updater *agentUpdater) installBinary(tenantUUID string, binary arch.Binary) error {
+	if _, ok := updater.fs.(afero.Linker); !ok {
+		log.Info("filesystem does not support symlinks, installing agent for the tenant only", "fs", updater.fs.Name())
+		return updater.installAgent(binary, updater.path.AgentBinaryDirForVersion(tenantUUID, binary.Name()))
+	}
+
+	tmpDir := updater.path.SharedAgentBinaryDirForKey(tmpSharedBinaryPrefix + tenantUUID + "-" + binary.Name())
+	// an interrupted install of the tenant may have left the directory behind
+	_ = updater.fs.RemoveAll(tmpDir)
+	if err := updater.installAgent(binary, tmpDir); err != nil {
+		_ = updater.fs.RemoveAll(tmpDir)
+		return err
+	}
+
+	hash, err := hashDir(updater.fs, tmpDir)
+	if err != nil {
+		_ = updater.fs.RemoveAll(tmpDir)
+		return err
+	}
+	sharedDir := updater.path.SharedAgentBinaryDirForKey(sharedBinaryKeyPrefix(binary) + hash)
+
+	// the link is created before the shared binary, so the garbage collector never sees it without a reference
+	if err := updater.linkSharedBinary(tenantUUID, binary.Name(), sharedDir, tmpDir); err != nil {
+		_ = updater.fs.RemoveAll(tmpDir)
+		return err
+	}
+	if _, err := updater.fs.Stat(sharedDir); err == nil {
+		log.Info("using shared agent", "version", binary.Version, "flavor", binary.Flavor, "shared directory", sharedDir)
+		return errors.WithStack(updater.fs.RemoveAll(tmpDir))
+	}
+	if err := updater.fs.Rename(tmpDir, sharedDir); err != nil {
+		_ = updater.fs.RemoveAll(tmpDir)
+		// another install of the same content finished after the check
+		if _, statErr := updater.fs.Stat(sharedDir); statErr == nil {
+			log.Info("agent was installed concurrently", "shared directory", sharedDir)
+			return nil
+		}
+		return errors.WithStack(err)
+	}
+	log.Info("installed shared agent", "version", binary.Version, "flavor", binary.Flavor, "shared directory", sharedDir)
+	return nil
+}
+
+func (updater *agentUpdater) installAgent(binary arch.Binary, targetDir string) error {
+	updater.installer.SetVersion(binary.Version)
+	updater.installer.SetFlavor(binary.Flavor)
+	updater.installer.SetSha256(updater.pinnedSha256(binary))
+	return updater.installer.InstallAgent(targetDir)
+}
+
+// pinnedSha256 returns the checksum pinned in the DynaKube for the version in use in the default flavor of the node,
+// pre-pulled binaries have none.
+func (updater *agentUpdater) pinnedSha256(binary arch.Binary) string {
+	if binary.Name() != updater.dk.Version() {
+		return ""
+	}
+	return updater.dk.CodeModulesSha256()
+}
+
+// updateProcessModuleConfig writes the ruxitagentproc.conf of the tenant. Shared binaries get it in the config directory of the tenant,
+// which is mounted on top of them, binaries installed before they were shared have it in their own directory.
+func (updater *agentUpdater) updateProcessModuleConfig(tenantUUID string, binaryName string, latestProcessModuleConfigCache *processModuleConfigCache) error {
+	configDir := updater.path.AgentConfigDirForVersion(tenantUUID, binaryName)
+	if _, err := updater.fs.Stat(configDir); os.IsNotExist(err) {
+		configDir = updater.path.AgentBinaryDirForVersion(tenantUUID, binaryName)
+	}
+	return updater.installer.UpdateProcessModuleConfig(configDir, latestProcessModuleConfigCache.ProcessModuleConfig)
+}
+
+// linkSharedBinary links the binary directory of the tenant to the shared binary and prepares the config directory of the tenant
+// with the original ruxitagentproc.conf from the content directory, which holds the code modules downloaded for the tenant.
+func (updater *agentUpdater) linkSharedBinary(tenantUUID string, binaryName string, sharedDir string, contentDir string) error {
+	linker := updater.fs.(afero.Linker)
+	binaryDir := updater.path.AgentBinaryDirForVersion(tenantUUID, binaryName)
+	if err := updater.fs.MkdirAll(filepath.Dir(binaryDir), 0755); err != nil {
+		return errors.WithStack(err)
+	}
+	target, err := filepath.Rel(filepath.Dir(binaryDir), sharedDir)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	// a dangling link is left behind if the shared binary was removed
+	_ = updater.fs.Remove(binaryDir)
+	if err := linker.SymlinkIfPossible(target, binaryDir); err != nil {
+		return errors.WithStack(err)
+	}
+
+	return updater.prepareConfigDir(tenantUUID, binaryName, contentDir)
+}
+
+func (updater *agentUpdater) prepareConfigDir(tenantUUID string, binaryName string, contentDir string) error {
+	configDir := updater.path.AgentConfigDirForVersion(tenantUUID, binaryName)
+	sourcePath := filepath.Join(contentDir, processModuleConfigPath)
+	targetPath := filepath.Join(configDir, "agent", "conf", "_ruxitagentproc.conf")
+
+	if err := updater.fs.MkdirAll(filepath.Dir(targetPath), 0755); err != nil {
+		return errors.WithStack(err)
+	}
+
+	source, err := updater.fs.Open(sourcePath)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return errors.WithStack(err)
+	}
+	defer func() { _ = source.Close() }()
+
+	target, err := updater.fs.OpenFile(targetPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if _, err := io.Copy(target, source); err != nil {
+		_ = target.Close()
+		return errors.WithStack(err)
+	}
+	return errors.WithStack(target.Close())
+}
+
+func sharedBinaryKeyPrefix(binary arch.Binary) string {
+	return fmt.Sprintf("%s-%s-%s-", binary.Version, binary.Flavor, arch.Arch)
+}
+
+// hashDir returns the shortened sha256 of the paths, modes, file contents and symlinks in the directory.
+// The ruxitagentproc.conf differs between tenants, so it is left out.
+func hashDir(fs afero.Fs, dir string) (string, error) {
+	hash := sha256.New()
+	err := afero.Walk(fs, dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		relativePath, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		if relativePath == processModuleConfigPath {
+			return nil
+		}
+		_, _ = fmt.Fprintf(hash, "%s\x00%s\x00", relativePath, info.Mode())
+
+		if info.Mode()&os.ModeSymlink != 0 {
+			if reader, ok := fs.(afero.LinkReader); ok {
+				target, err := reader.ReadlinkIfPossible(path)
+				if err != nil {
+					return err
+				}
+				_, _ = io.WriteString(hash, target)
+			}
+		} else if info.Mode().IsRegular() {
+			file, err := fs.Open(path)
+			if err != nil {
+				return err
+			}
+			_, err = io.Copy(hash, file)
+			_ = file.Close()
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	return hex.EncodeToString(hash.Sum(nil))[:sharedBinaryHashLength], nil
+}
@@ -0,0 +1,194 @@
+package csiprovisioner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/csi/metadata"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/csi/provisioner/arch"
+	"github.com/Dynatrace/dynatrace-operator/src/installer"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/tools/record"
+)
+
+const otherTenantUUID = "abc456"
+
+func TestInstallBinary(t *testing.T) {
+	binary := arch.Binary{Version: testVersion, Flavor: arch.Flavor}
+
+	t.Run(`code modules are shared between tenants`, func(t *testing.T) {
+		rootDir := t.TempDir()
+		updater := createTestSharedAgentUpdater(rootDir)
+		otherUpdater := createTestSharedAgentUpdater(rootDir)
+		mockInstallAgent(t, updater, testTenantUUID, binary, "agent")
+		mockInstallAgent(t, otherUpdater, otherTenantUUID, binary, "agent")
+
+		require.NoError(t, updater.installBinary(testTenantUUID, binary))
+		require.NoError(t, otherUpdater.installBinary(otherTenantUUID, binary))
+
+		sharedDir := readSharedBinaryLink(t, updater, testTenantUUID, binary)
+		assert.Equal(t, sharedDir, readSharedBinaryLink(t, updater, otherTenantUUID, binary))
+		assert.Contains(t, filepath.Base(sharedDir), sharedBinaryKeyPrefix(binary))
+
+		// the original config of every tenant comes from its own download
+		for _, tenantUUID := range []string{testTenantUUID, otherTenantUUID} {
+			content, err := afero.ReadFile(updater.fs, filepath.Join(updater.path.AgentConfigDirForVersion(tenantUUID, testVersion), "agent", "conf", "_ruxitagentproc.conf"))
+			require.NoError(t, err)
+			assert.Equal(t, "config of "+tenantUUID, string(content))
+		}
+
+		tmpDirs, err := afero.Glob(updater.fs, updater.path.SharedAgentBinaryDirForKey(tmpSharedBinaryPrefix+"*"))
+		require.NoError(t, err)
+		assert.Empty(t, tmpDirs)
+	})
+	t.Run(`different content of the same version is stored separately`, func(t *testing.T) {
+		rootDir := t.TempDir()
+		updater := createTestSharedAgentUpdater(rootDir)
+		otherUpdater := createTestSharedAgentUpdater(rootDir)
+		mockInstallAgent(t, updater, testTenantUUID, binary, "agent")
+		mockInstallAgent(t, otherUpdater, otherTenantUUID, binary, "other agent")
+
+		require.NoError(t, updater.installBinary(testTenantUUID, binary))
+		require.NoError(t, otherUpdater.installBinary(otherTenantUUID, binary))
+
+		otherSharedDir := readSharedBinaryLink(t, updater, otherTenantUUID, binary)
+		assert.NotEqual(t, readSharedBinaryLink(t, updater, testTenantUUID, binary), otherSharedDir)
+
+		content, err := afero.ReadFile(updater.fs, filepath.Join(otherSharedDir, "agent", "lib64", "liboneagentproc.so"))
+		require.NoError(t, err)
+		assert.Equal(t, "other agent", string(content))
+	})
+	t.Run(`leftovers of an interrupted install are removed`, func(t *testing.T) {
+		rootDir := t.TempDir()
+		updater := createTestSharedAgentUpdater(rootDir)
+		otherUpdater := createTestSharedAgentUpdater(rootDir)
+		mockInstallAgent(t, updater, testTenantUUID, binary, "agent")
+		mockInstallAgent(t, otherUpdater, otherTenantUUID, binary, "agent")
+		tmpDir := updater.path.SharedAgentBinaryDirForKey(tmpSharedBinaryPrefix + testTenantUUID + "-" + binary.Name())
+		require.NoError(t, updater.fs.MkdirAll(tmpDir, 0755))
+		require.NoError(t, afero.WriteFile(updater.fs, filepath.Join(tmpDir, "partial"), []byte("partial"), 0644))
+
+		require.NoError(t, updater.installBinary(testTenantUUID, binary))
+		require.NoError(t, otherUpdater.installBinary(otherTenantUUID, binary))
+
+		assert.Equal(t, readSharedBinaryLink(t, updater, testTenantUUID, binary), readSharedBinaryLink(t, updater, otherTenantUUID, binary))
+	})
+	t.Run(`failed install leaves nothing behind`, func(t *testing.T) {
+		updater := createTestSharedAgentUpdater(t.TempDir())
+		installerMock := updater.installer.(*installer.InstallerMock)
+		installerMock.On("SetVersion", testVersion).Return()
+		installerMock.On("SetFlavor", arch.Flavor).Return()
+		installerMock.On("SetSha256", mock.Anything).Return()
+		installerMock.On("InstallAgent", mock.Anything).Return(os.ErrPermission)
+
+		require.Error(t, updater.installBinary(testTenantUUID, binary))
+
+		exists, err := afero.Exists(updater.fs, updater.path.AgentBinaryDirForVersion(testTenantUUID, testVersion))
+		require.NoError(t, err)
+		assert.False(t, exists)
+	})
+}
+
+func TestUpdateProcessModuleConfig(t *testing.T) {
+	processModuleCache := createTestProcessModuleConfigCache("1")
+
+	t.Run(`config directory of shared binary`, func(t *testing.T) {
+		updater := createTestSharedAgentUpdater(t.TempDir())
+		configDir := updater.path.AgentConfigDirForVersion(testTenantUUID, testVersion)
+		require.NoError(t, updater.fs.MkdirAll(configDir, 0755))
+		updater.installer.(*installer.InstallerMock).On("UpdateProcessModuleConfig", configDir, &testProcessModuleConfig).Return(nil)
+
+		require.NoError(t, updater.updateProcessModuleConfig(testTenantUUID, testVersion, &processModuleCache))
+	})
+	t.Run(`binary installed before sharing`, func(t *testing.T) {
+		updater := createTestSharedAgentUpdater(t.TempDir())
+		binaryDir := updater.path.AgentBinaryDirForVersion(testTenantUUID, testVersion)
+		require.NoError(t, updater.fs.MkdirAll(binaryDir, 0755))
+		updater.installer.(*installer.InstallerMock).On("UpdateProcessModuleConfig", binaryDir, &testProcessModuleConfig).Return(nil)
+
+		require.NoError(t, updater.updateProcessModuleConfig(testTenantUUID, testVersion, &processModuleCache))
+	})
+}
+
+func TestPinnedSha256(t *testing.T) {
+	updater := createTestSharedAgentUpdater(t.TempDir())
+	updater.dk.Spec.OneAgent.ApplicationMonitoring = &dynatracev1beta1.ApplicationMonitoringSpec{
+		AppInjectionSpec: dynatracev1beta1.AppInjectionSpec{
+			CodeModulesSource: &dynatracev1beta1.CodeModulesSourceSpec{Sha256: "checksum"},
+		},
+		Version: testVersion,
+	}
+
+	t.Run(`version in use`, func(t *testing.T) {
+		assert.Equal(t, "checksum", updater.pinnedSha256(arch.Binary{Version: testVersion, Flavor: arch.Flavor}))
+	})
+	t.Run(`pre-pulled flavor`, func(t *testing.T) {
+		assert.Empty(t, updater.pinnedSha256(arch.Binary{Version: testVersion, Flavor: "other"}))
+	})
+	t.Run(`pre-pulled version`, func(t *testing.T) {
+		assert.Empty(t, updater.pinnedSha256(arch.Binary{Version: "1.0.0", Flavor: arch.Flavor}))
+	})
+}
+
+func TestHashDir(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/a/agent/file", []byte("content"), 0644))
+	require.NoError(t, afero.WriteFile(fs, "/b/agent/file", []byte("content"), 0644))
+	require.NoError(t, afero.WriteFile(fs, "/c/agent/file", []byte("other content"), 0644))
+	// the config of the tenant is ignored
+	require.NoError(t, afero.WriteFile(fs, filepath.Join("/a", processModuleConfigPath), []byte("tenant config"), 0644))
+	require.NoError(t, afero.WriteFile(fs, filepath.Join("/b", processModuleConfigPath), []byte("other tenant config"), 0644))
+
+	hashA, err := hashDir(fs, "/a")
+	require.NoError(t, err)
+	hashB, err := hashDir(fs, "/b")
+	require.NoError(t, err)
+	hashC, err := hashDir(fs, "/c")
+	require.NoError(t, err)
+
+	assert.Len(t, hashA, sharedBinaryHashLength)
+	assert.Equal(t, hashA, hashB)
+	assert.NotEqual(t, hashA, hashC)
+}
+
+func createTestSharedAgentUpdater(rootDir string) *agentUpdater {
+	return &agentUpdater{
+		fs:        afero.NewOsFs(),
+		dk:        &dynatracev1beta1.DynaKube{},
+		path:      metadata.PathResolver{RootDir: rootDir},
+		installer: &installer.InstallerMock{},
+		recorder:  record.NewFakeRecorder(10),
+	}
+}
+
+func mockInstallAgent(t *testing.T, updater *agentUpdater, tenantUUID string, binary arch.Binary, content string) {
+	installerMock := updater.installer.(*installer.InstallerMock)
+	installerMock.On("SetVersion", binary.Version).Return()
+	installerMock.On("SetFlavor", binary.Flavor).Return()
+	installerMock.On("SetSha256", mock.Anything).Return()
+	installerMock.
+		On("InstallAgent", updater.path.SharedAgentBinaryDirForKey(tmpSharedBinaryPrefix+tenantUUID+"-"+binary.Name())).
+		Run(func(args mock.Arguments) {
+			for path, fileContent := range map[string]string{
+				filepath.Join("agent", "lib64", "liboneagentproc.so"): content,
+				processModuleConfigPath:                               "config of " + tenantUUID,
+			} {
+				filePath := filepath.Join(args.String(0), path)
+				require.NoError(t, updater.fs.MkdirAll(filepath.Dir(filePath), 0755))
+				require.NoError(t, afero.WriteFile(updater.fs, filePath, []byte(fileContent), 0644))
+			}
+		}).
+		Return(nil)
+}
+
+func readSharedBinaryLink(t *testing.T, updater *agentUpdater, tenantUUID string, binary arch.Binary) string {
+	binaryDir := updater.path.AgentBinaryDirForVersion(tenantUUID, binary.Name())
+	target, err := os.Readlink(binaryDir)
+	require.NoError(t, err)
+	return filepath.Join(filepath.Dir(binaryDir), target)
+}
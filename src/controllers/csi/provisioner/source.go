@@ -0,0 +1,62 @@
+package csiprovisioner
+
+import (
+	"context"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/dtversion"
+	"github.com/Dynatrace/dynatrace-operator/src/dtclient"
+	"github.com/Dynatrace/dynatrace-operator/src/installer"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// buildArtifactSource returns the source of the code modules configured in the DynaKube, nil means the Dynatrace API is used.
+func (provisioner *OneAgentProvisioner) buildArtifactSource(ctx context.Context, dk *dynatracev1beta1.DynaKube, dtc dtclient.Client) (installer.ArtifactSource, error) {
+	codeModulesSource := dk.CodeModulesSource()
+	if codeModulesSource == nil {
+		return nil, nil
+	}
+
+	config := installer.SourceConfig{
+		Image:           codeModulesSource.Image,
+		ImagePath:       codeModulesSource.ImagePath,
+		MirrorIndexUrl:  codeModulesSource.MirrorIndexURL,
+		MirrorPublicKey: codeModulesSource.MirrorPublicKey,
+		SkipCertCheck:   dk.Spec.SkipCertCheck,
+	}
+
+	if config.Image != "" {
+		dockerConfig, err := provisioner.getDockerConfig(ctx, dk)
+		if err != nil {
+			return nil, err
+		}
+		config.DockerConfig = dockerConfig
+	}
+
+	return installer.NewArtifactSource(provisioner.fs, dtc, config)
+}
+
+// getDockerConfig reads the credentials for the code modules image from the pull secret of the DynaKube.
+// The image is pulled anonymously if there is no pull secret.
+func (provisioner *OneAgentProvisioner) getDockerConfig(ctx context.Context, dk *dynatracev1beta1.DynaKube) (*dtversion.DockerConfig, error) {
+	dockerConfig := &dtversion.DockerConfig{SkipCertCheck: dk.Spec.SkipCertCheck}
+
+	var pullSecret corev1.Secret
+	err := provisioner.apiReader.Get(ctx, client.ObjectKey{Name: dk.PullSecret(), Namespace: dk.Namespace}, &pullSecret)
+	if k8serrors.IsNotFound(err) {
+		log.Info("pull secret not found, pulling code modules image anonymously", "secret", dk.PullSecret())
+		return dockerConfig, nil
+	} else if err != nil {
+		return nil, errors.WithMessage(err, "failed to get image pull secret")
+	}
+
+	auths, err := dtversion.ParseDockerAuthsFromSecret(&pullSecret)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to get Dockerconfig for pull secret")
+	}
+	dockerConfig.Auths = auths
+	return dockerConfig, nil
+}
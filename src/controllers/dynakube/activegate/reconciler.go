@@ -24,24 +24,36 @@ const (
 
 type TenantSecretReconciler struct {
 	client.Client
-	apiReader client.Reader
-	instance  *dynatracev1beta1.DynaKube
-	scheme    *runtime.Scheme
-	apiToken  string
-	dtc       dtclient.Client
+	apiReader  client.Reader
+	instance   *dynatracev1beta1.DynaKube
+	scheme     *runtime.Scheme
+	apiToken   string
+	dtc        dtclient.Client
+	secretName string
+	labels     map[string]string
 }
 
 func NewTenantSecretReconciler(clt client.Client, apiReader client.Reader, scheme *runtime.Scheme, instance *dynatracev1beta1.DynaKube, apiToken string, dtc dtclient.Client) *TenantSecretReconciler {
 	return &TenantSecretReconciler{
-		Client:    clt,
-		apiReader: apiReader,
-		scheme:    scheme,
-		instance:  instance,
-		apiToken:  apiToken,
-		dtc:       dtc,
+		Client:     clt,
+		apiReader:  apiReader,
+		scheme:     scheme,
+		instance:   instance,
+		apiToken:   apiToken,
+		dtc:        dtc,
+		secretName: instance.AGTenantSecret(),
 	}
 }
 
+// NewTenantTargetSecretReconciler manages the ActiveGate tenant secret of an additional tenant, dtc has to be the client of the tenant target.
+// The secret is labeled with the tenant target, so it's cleaned up once the tenant target no longer has an ActiveGate.
+func NewTenantTargetSecretReconciler(clt client.Client, apiReader client.Reader, scheme *runtime.Scheme, instance *dynatracev1beta1.DynaKube, target string, apiToken string, dtc dtclient.Client) *TenantSecretReconciler {
+	r := NewTenantSecretReconciler(clt, apiReader, scheme, instance, apiToken, dtc)
+	r.secretName = instance.AGTenantSecretForTarget(target)
+	r.labels = map[string]string{dynatracev1beta1.LabelTenantTarget: target}
+	return r
+}
+
 func (r *TenantSecretReconciler) Reconcile() error {
 	err := r.reconcileSecret()
 	if err != nil {
@@ -83,10 +95,10 @@ func (r *TenantSecretReconciler) getActiveGateTenantInfo() (map[string][]byte, e
 func (r *TenantSecretReconciler) createSecretIfNotExists(agSecretData map[string][]byte) (*corev1.Secret, error) {
 	var config corev1.Secret
 	err := r.apiReader.Get(context.TODO(),
-		client.ObjectKey{Name: extendWithAGSecretSuffix(r.instance.Name), Namespace: r.instance.Namespace},
+		client.ObjectKey{Name: r.secretName, Namespace: r.instance.Namespace},
 		&config)
 	if k8serrors.IsNotFound(err) {
-		log.Info("creating ag secret")
+		log.Info("creating ag secret", "name", r.secretName)
 		return r.createSecret(agSecretData)
 	}
 	return &config, err
@@ -100,7 +112,8 @@ func (r *TenantSecretReconciler) updateSecretIfOutdated(secret *corev1.Secret, d
 }
 
 func (r *TenantSecretReconciler) createSecret(secretData map[string][]byte) (*corev1.Secret, error) {
-	secret := BuildAGSecret(r.instance, secretData)
+	secret := BuildAGSecret(r.instance, r.secretName, secretData)
+	secret.Labels = r.labels
 
 	if err := controllerutil.SetControllerReference(r.instance, secret, r.scheme); err != nil {
 		return nil, errors.WithStack(err)
@@ -108,7 +121,7 @@ func (r *TenantSecretReconciler) createSecret(secretData map[string][]byte) (*co
 
 	err := r.Create(context.TODO(), secret)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create secret '%s': %w", extendWithAGSecretSuffix(r.instance.Name), err)
+		return nil, fmt.Errorf("failed to create secret '%s': %w", r.secretName, err)
 	}
 	return secret, nil
 }
@@ -122,17 +135,13 @@ func (r *TenantSecretReconciler) updateSecret(agSecret *corev1.Secret, desiredAG
 	return nil
 }
 
-func BuildAGSecret(instance *dynatracev1beta1.DynaKube, agSecretData map[string][]byte) *corev1.Secret {
+func BuildAGSecret(instance *dynatracev1beta1.DynaKube, name string, agSecretData map[string][]byte) *corev1.Secret {
 	return &corev1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      extendWithAGSecretSuffix(instance.Name),
+			Name:      name,
 			Namespace: instance.Namespace,
 		},
 		Type: corev1.SecretTypeOpaque,
 		Data: agSecretData,
 	}
 }
-
-func extendWithAGSecretSuffix(name string) string {
-	return name + dynatracev1beta1.TenantSecretSuffix
-}
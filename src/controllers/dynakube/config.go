@@ -2,8 +2,21 @@ package dynakube
 
 import (
 	"github.com/Dynatrace/dynatrace-operator/src/logger"
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
 )
 
 var (
 	log = logger.NewDTLogger().WithName("dynakube-controller")
+
+	tokenProbesMetric = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "dynatrace",
+		Subsystem: "operator",
+		Name:      "token_probes_total",
+		Help:      "Number of token probes against the Dynatrace API by token and result",
+	}, []string{"token", "result"})
 )
+
+func init() {
+	metrics.Registry.MustRegister(tokenProbesMetric)
+}
@@ -3,10 +3,12 @@ package dynakube
 import (
 	"context"
 	"fmt"
+	"time"
 
 	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
 	"github.com/Dynatrace/dynatrace-operator/src/dtclient"
 	"github.com/Dynatrace/dynatrace-operator/src/kubeobjects"
+	"github.com/Dynatrace/dynatrace-operator/src/secretprovider"
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -17,9 +19,12 @@ type options struct {
 }
 
 type DynatraceClientProperties struct {
+	// Context aborts retries of the client once it's done, usually the context of the reconcile
+	Context             context.Context
 	ApiReader           client.Reader
 	Secret              *corev1.Secret
 	Proxy               *DynatraceClientProxy
+	SecretProvider      *dynatracev1beta1.SecretProviderSpec
 	ApiUrl              string
 	Namespace           string
 	NetworkZone         string
@@ -34,17 +39,19 @@ type DynatraceClientProxy struct {
 }
 
 func NewDynatraceClientProperties(ctx context.Context, apiReader client.Reader, dk dynatracev1beta1.DynaKube) (*DynatraceClientProperties, error) {
-	var tokens corev1.Secret
-	var err error
-	if err = apiReader.Get(ctx, client.ObjectKey{Name: dk.Tokens(), Namespace: dk.Namespace}, &tokens); err != nil {
+	tokens, err := secretprovider.NewReaderForDynakube(apiReader, &dk).Get(ctx, dk.Tokens())
+	if err != nil {
+		tokens = &corev1.Secret{}
 		err = fmt.Errorf("failed to query tokens: %w", err)
 	}
 	return &DynatraceClientProperties{
+		Context:             ctx,
 		ApiReader:           apiReader,
-		Secret:              &tokens,
+		Secret:              tokens,
 		ApiUrl:              dk.Spec.APIURL,
 		Namespace:           dk.Namespace,
 		Proxy:               (*DynatraceClientProxy)(dk.Spec.Proxy),
+		SecretProvider:      dk.Spec.SecretProvider,
 		NetworkZone:         dk.Spec.NetworkZone,
 		TrustedCerts:        dk.Spec.TrustedCAs,
 		SkipCertCheck:       dk.Spec.SkipCertCheck,
@@ -54,6 +61,24 @@ func NewDynatraceClientProperties(ctx context.Context, apiReader client.Reader,
 
 // BuildDynatraceClient creates a new Dynatrace client using the settings configured on the given instance.
 func BuildDynatraceClient(properties DynatraceClientProperties) (dtclient.Client, error) {
+	dtc, err := buildDynatraceClient(properties)
+	if err != nil {
+		return nil, err
+	}
+	return dtclient.NewRetryClient(dtclient.NewMetricsClient(dtc), dtclient.RetryContext(properties.Context)), nil
+}
+
+// BuildDynatraceProbeClient creates a Dynatrace client for callers which can't wait long for an answer, like the webhooks.
+// Every request is limited to the timeout and failed requests are not retried.
+func BuildDynatraceProbeClient(properties DynatraceClientProperties, timeout time.Duration) (dtclient.Client, error) {
+	dtc, err := buildDynatraceClient(properties, dtclient.Timeout(timeout))
+	if err != nil {
+		return nil, err
+	}
+	return dtclient.NewMetricsClient(dtc), nil
+}
+
+func buildDynatraceClient(properties DynatraceClientProperties, extraOpts ...dtclient.Option) (dtclient.Client, error) {
 	namespace := properties.Namespace
 	secret := properties.Secret
 	apiReader := properties.ApiReader
@@ -68,7 +93,7 @@ func BuildDynatraceClient(properties DynatraceClientProperties) (dtclient.Client
 	opts.appendNetworkZone(properties.NetworkZone)
 	opts.appendDisableHostsRequests(properties.DisableHostRequests)
 
-	err = opts.appendProxySettings(apiReader, properties.Proxy, namespace)
+	err = opts.appendProxySettings(secretprovider.NewReader(apiReader, namespace, properties.SecretProvider), properties.Proxy)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
@@ -78,7 +103,7 @@ func BuildDynatraceClient(properties DynatraceClientProperties) (dtclient.Client
 		return nil, errors.WithStack(err)
 	}
 
-	return dtclient.NewClient(properties.ApiUrl, tokens.ApiToken, tokens.PaasToken, opts.Opts...)
+	return dtclient.NewClient(properties.ApiUrl, tokens.ApiToken, tokens.PaasToken, append(opts.Opts, extraOpts...)...)
 }
 
 func newOptions() *options {
@@ -108,11 +133,10 @@ func (opts *options) appendDisableHostsRequests(disableHostsRequests bool) {
 	opts.Opts = append(opts.Opts, dtclient.DisableHostsRequests(disableHostsRequests))
 }
 
-func (opts *options) appendProxySettings(apiReader client.Reader, proxyEntry *DynatraceClientProxy, namespace string) error {
+func (opts *options) appendProxySettings(secretReader *secretprovider.Reader, proxyEntry *DynatraceClientProxy) error {
 	if p := proxyEntry; p != nil {
 		if p.ValueFrom != "" {
-			proxySecret := &corev1.Secret{}
-			err := apiReader.Get(context.TODO(), client.ObjectKey{Name: p.ValueFrom, Namespace: namespace}, proxySecret)
+			proxySecret, err := secretReader.Get(context.TODO(), p.ValueFrom)
 			if err != nil {
 				return fmt.Errorf("failed to get proxy secret: %w", err)
 			}
@@ -9,7 +9,9 @@ import (
 	"time"
 
 	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/tokenrotation"
 	"github.com/Dynatrace/dynatrace-operator/src/dtclient"
+	"github.com/Dynatrace/dynatrace-operator/src/secretprovider"
 	corev1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
@@ -24,17 +26,51 @@ type DynatraceClientReconciler struct {
 	ApiToken, PaasToken, DataIngestToken string
 	ValidTokens                          bool
 	dkName, ns, secretKey                string
-	status                               *dynatracev1beta1.DynaKubeStatus
+	status                               tokenStatus
+}
+
+// tokenStatus points to the fields of the DynaKube status which track the tokens of a tenant,
+// either the one of the apiUrl or one of the additional tenants.
+type tokenStatus struct {
+	conditions                                                                                 *[]metav1.Condition
+	lastAPITokenProbeTimestamp, lastPaaSTokenProbeTimestamp, lastDataIngestTokenProbeTimestamp **metav1.Time
+	// rotation is only tracked for the tenant of the apiUrl, whose tokens are propagated to the derived secrets
+	rotation *dynatracev1beta1.TokenRotationStatus
 }
 
 type tokenConfig struct {
-	Type       string
-	Key, Value string
-	Scopes     []string
-	Timestamp  **metav1.Time
+	Type         string
+	Key, Value   string
+	Requirements []dynatracev1beta1.TokenScopeRequirement
+	Timestamp    **metav1.Time
 }
 
+// requiredScopesFunc returns the scopes the features of the DynaKube need on the tokens of a tenant.
+type requiredScopesFunc func(hasPaasToken, hasDataIngestToken bool) []dynatracev1beta1.TokenScopeRequirement
+
 func (r *DynatraceClientReconciler) Reconcile(ctx context.Context, instance *dynatracev1beta1.DynaKube) (dtclient.Client, bool, error) {
+	return r.reconcileTenant(ctx, instance, instance.Spec.APIURL, instance.Tokens(), instance.RequiredTokenScopes, tokenStatus{
+		conditions:                        &instance.Status.Conditions,
+		lastAPITokenProbeTimestamp:        &instance.Status.LastAPITokenProbeTimestamp,
+		lastPaaSTokenProbeTimestamp:       &instance.Status.LastPaaSTokenProbeTimestamp,
+		lastDataIngestTokenProbeTimestamp: &instance.Status.LastDataIngestTokenProbeTimestamp,
+		rotation:                          &instance.Status.TokenRotation,
+	})
+}
+
+// ReconcileTenantTarget checks the tokens of an additional tenant and builds a client for its environment.
+// The conditions are tracked in the status of the tenant target.
+func (r *DynatraceClientReconciler) ReconcileTenantTarget(ctx context.Context, instance *dynatracev1beta1.DynaKube, target dynatracev1beta1.TenantTargetSpec) (dtclient.Client, bool, error) {
+	targetStatus := instance.TenantTargetStatus(target.Name)
+	return r.reconcileTenant(ctx, instance, target.APIURL, instance.TenantTargetTokens(target), instance.TenantTargetRequiredTokenScopes, tokenStatus{
+		conditions:                        &targetStatus.Conditions,
+		lastAPITokenProbeTimestamp:        &targetStatus.LastAPITokenProbeTimestamp,
+		lastPaaSTokenProbeTimestamp:       &targetStatus.LastPaaSTokenProbeTimestamp,
+		lastDataIngestTokenProbeTimestamp: &targetStatus.LastDataIngestTokenProbeTimestamp,
+	})
+}
+
+func (r *DynatraceClientReconciler) reconcileTenant(ctx context.Context, instance *dynatracev1beta1.DynaKube, apiUrl string, secretName string, requiredScopes requiredScopesFunc, status tokenStatus) (dtclient.Client, bool, error) {
 	r.ValidTokens = true
 	if r.Now.IsZero() {
 		r.Now = metav1.Now()
@@ -45,19 +81,18 @@ func (r *DynatraceClientReconciler) Reconcile(ctx context.Context, instance *dyn
 		dtf = BuildDynatraceClient
 	}
 
-	r.status = &instance.Status
+	r.status = status
 	r.ns = instance.GetNamespace()
 	r.dkName = instance.GetName()
-	secretName := instance.Tokens()
 	updateCR := false
 
 	r.secretKey = r.ns + ":" + secretName
-	secret, err := r.getSecret(ctx, instance)
+	secret, err := secretprovider.NewReaderForDynakube(r.Client, instance).Get(ctx, secretName)
 	r.setTokens(secret)
 	if k8serrors.IsNotFound(err) {
 		message := fmt.Sprintf("Secret '%s' not found", r.secretKey)
 
-		updateCR = r.setAndLogCondition(&r.status.Conditions, metav1.Condition{
+		updateCR = r.setAndLogCondition(r.status.conditions, metav1.Condition{
 			Type:    dynatracev1beta1.APITokenConditionType,
 			Status:  metav1.ConditionFalse,
 			Reason:  dynatracev1beta1.ReasonTokenSecretNotFound,
@@ -72,7 +107,7 @@ func (r *DynatraceClientReconciler) Reconcile(ctx context.Context, instance *dyn
 
 	if r.ApiToken == "" {
 		msg := fmt.Sprintf("Token %s on secret %s missing", dtclient.DynatraceApiToken, r.secretKey)
-		updateCR = r.setAndLogCondition(&r.status.Conditions, metav1.Condition{
+		updateCR = r.setAndLogCondition(r.status.conditions, metav1.Condition{
 			Type:    dynatracev1beta1.APITokenConditionType,
 			Status:  metav1.ConditionFalse,
 			Reason:  dynatracev1beta1.ReasonTokenMissing,
@@ -84,10 +119,12 @@ func (r *DynatraceClientReconciler) Reconcile(ctx context.Context, instance *dyn
 	}
 
 	dtc, err := dtf(DynatraceClientProperties{
+		Context:             ctx,
 		ApiReader:           r.Client,
 		Secret:              secret,
 		Proxy:               convertProxy(instance.Spec.Proxy),
-		ApiUrl:              instance.Spec.APIURL,
+		SecretProvider:      instance.Spec.SecretProvider,
+		ApiUrl:              apiUrl,
 		Namespace:           r.ns,
 		NetworkZone:         instance.Spec.NetworkZone,
 		TrustedCerts:        instance.Spec.TrustedCAs,
@@ -98,7 +135,7 @@ func (r *DynatraceClientReconciler) Reconcile(ctx context.Context, instance *dyn
 	if err != nil {
 		message := fmt.Sprintf("Failed to create Dynatrace API Client: %s", err)
 
-		updateCR = r.setAndLogCondition(&r.status.Conditions, metav1.Condition{
+		updateCR = r.setAndLogCondition(r.status.conditions, metav1.Condition{
 			Type:    dynatracev1beta1.APITokenConditionType,
 			Status:  metav1.ConditionFalse,
 			Reason:  dynatracev1beta1.ReasonTokenMissing,
@@ -109,42 +146,30 @@ func (r *DynatraceClientReconciler) Reconcile(ctx context.Context, instance *dyn
 		return nil, updateCR, err
 	}
 
-	var tokens []tokenConfig
+	if r.status.rotation != nil {
+		rotated, err := r.detectTokenRotation()
+		if err != nil {
+			return nil, updateCR, err
+		}
+		updateCR = rotated || updateCR
+	}
+
+	tokens := []tokenConfig{{
+		Type:      dynatracev1beta1.APITokenConditionType,
+		Key:       dtclient.DynatraceApiToken,
+		Value:     r.ApiToken,
+		Timestamp: r.status.lastAPITokenProbeTimestamp,
+	}}
+
 	if r.PaasToken == "" {
-		tokens = []tokenConfig{{
-			Type:  dynatracev1beta1.APITokenConditionType,
-			Key:   dtclient.DynatraceApiToken,
-			Value: r.ApiToken,
-			Scopes: []string{dtclient.TokenScopeDataExport,
-				dtclient.TokenScopeInstallerDownload,
-			},
-			Timestamp: &r.status.LastAPITokenProbeTimestamp,
-		}}
 		updateCR = r.removePaaSTokenCondition() || updateCR
 	} else {
-		tokens = []tokenConfig{
-			{
-				Type:      dynatracev1beta1.APITokenConditionType,
-				Key:       dtclient.DynatraceApiToken,
-				Value:     r.ApiToken,
-				Scopes:    []string{dtclient.TokenScopeDataExport},
-				Timestamp: &r.status.LastAPITokenProbeTimestamp,
-			},
-			{
-				Type:      dynatracev1beta1.PaaSTokenConditionType,
-				Key:       dtclient.DynatracePaasToken,
-				Value:     r.PaasToken,
-				Scopes:    []string{dtclient.TokenScopeInstallerDownload},
-				Timestamp: &r.status.LastPaaSTokenProbeTimestamp,
-			}}
-	}
-
-	if instance.KubernetesMonitoringMode() &&
-		instance.FeatureAutomaticKubernetesApiMonitoring() {
-		tokens[0].Scopes = append(tokens[0].Scopes,
-			dtclient.TokenScopeEntitiesRead,
-			dtclient.TokenScopeSettingsRead,
-			dtclient.TokenScopeSettingsWrite)
+		tokens = append(tokens, tokenConfig{
+			Type:      dynatracev1beta1.PaaSTokenConditionType,
+			Key:       dtclient.DynatracePaasToken,
+			Value:     r.PaasToken,
+			Timestamp: r.status.lastPaaSTokenProbeTimestamp,
+		})
 	}
 
 	if r.DataIngestToken != "" {
@@ -152,21 +177,61 @@ func (r *DynatraceClientReconciler) Reconcile(ctx context.Context, instance *dyn
 			Type:      dynatracev1beta1.DataIngestTokenConditionType,
 			Key:       dtclient.DynatraceDataIngestToken,
 			Value:     r.DataIngestToken,
-			Scopes:    []string{dtclient.TokenScopeMetricsIngest},
-			Timestamp: &r.status.LastDataIngestTokenProbeTimestamp,
+			Timestamp: r.status.lastDataIngestTokenProbeTimestamp,
 		})
 	}
 
+	requirements := requiredScopes(r.PaasToken != "", r.DataIngestToken != "")
 	for _, token := range tokens {
+		token.Requirements = requirementsForToken(requirements, token.Key)
 		updateCR = r.CheckToken(dtc, token) || updateCR
 	}
 
 	return dtc, updateCR, nil
 }
 
+// detectTokenRotation compares the tokens with the ones last found in the secret. Changed tokens are probed right away,
+// instead of waiting for the next probe, and have to be propagated to the derived secrets again once they are valid.
+func (r *DynatraceClientReconciler) detectTokenRotation() (bool, error) {
+	tokensHash, err := tokenrotation.TokensHash(r.ApiToken, r.PaasToken, r.DataIngestToken)
+	if err != nil {
+		return false, err
+	}
+	rotation := r.status.rotation
+	if rotation.TokensHash == "" {
+		// the tokens are seen for the first time, the derived secrets are checked anyway as long as
+		// the status doesn't report them as propagated
+		rotation.TokensHash = tokensHash
+		return false, nil
+	}
+	if rotation.TokensHash == tokensHash {
+		return false, nil
+	}
+
+	log.Info("tokens changed", "dynakube", r.dkName, "secret", r.secretKey)
+	nowCopy := r.Now
+	rotation.TokensHash = tokensHash
+	rotation.LastRotationTimestamp = &nowCopy
+	rotation.OutdatedSecrets = append(rotation.UpdatedSecrets, rotation.OutdatedSecrets...)
+	rotation.UpdatedSecrets = nil
+
+	*r.status.lastAPITokenProbeTimestamp = nil
+	*r.status.lastPaaSTokenProbeTimestamp = nil
+	*r.status.lastDataIngestTokenProbeTimestamp = nil
+
+	meta.SetStatusCondition(r.status.conditions, metav1.Condition{
+		Type:               dynatracev1beta1.TokensPropagatedConditionType,
+		Status:             metav1.ConditionFalse,
+		Reason:             dynatracev1beta1.ReasonRolloutInProgress,
+		Message:            fmt.Sprintf("Tokens on secret %s changed, the derived secrets are updated once the tokens are verified", r.secretKey),
+		LastTransitionTime: r.Now,
+	})
+	return true, nil
+}
+
 func (r *DynatraceClientReconciler) CheckToken(dtc dtclient.Client, token tokenConfig) bool {
 	if strings.TrimSpace(token.Value) != token.Value {
-		return r.setAndLogCondition(&r.status.Conditions, metav1.Condition{
+		return r.setAndLogCondition(r.status.conditions, metav1.Condition{
 			Type:    token.Type,
 			Status:  metav1.ConditionFalse,
 			Reason:  dynatracev1beta1.ReasonTokenUnauthorized,
@@ -177,7 +242,7 @@ func (r *DynatraceClientReconciler) CheckToken(dtc dtclient.Client, token tokenC
 	// At this point, we can query the Dynatrace API to verify whether our tokens are correct. To avoid excessive requests,
 	// we wait at least 5 mins between proves.
 	if *token.Timestamp != nil && r.Now.Time.Before((*token.Timestamp).Add(5*time.Minute)) {
-		oldCondition := meta.FindStatusCondition(r.status.Conditions, token.Type)
+		oldCondition := meta.FindStatusCondition(*r.status.conditions, token.Type)
 		if oldCondition.Reason != dynatracev1beta1.ReasonTokenReady {
 			r.ValidTokens = false
 		}
@@ -190,7 +255,8 @@ func (r *DynatraceClientReconciler) CheckToken(dtc dtclient.Client, token tokenC
 
 	var serr dtclient.ServerError
 	if ok := errors.As(err, &serr); ok && serr.Code == http.StatusUnauthorized {
-		r.setAndLogCondition(&r.status.Conditions, metav1.Condition{
+		tokenProbesMetric.WithLabelValues(token.Type, dynatracev1beta1.ReasonTokenUnauthorized).Inc()
+		r.setAndLogCondition(r.status.conditions, metav1.Condition{
 			Type:    token.Type,
 			Status:  metav1.ConditionFalse,
 			Reason:  dynatracev1beta1.ReasonTokenUnauthorized,
@@ -200,7 +266,8 @@ func (r *DynatraceClientReconciler) CheckToken(dtc dtclient.Client, token tokenC
 	}
 
 	if err != nil {
-		r.setAndLogCondition(&r.status.Conditions, metav1.Condition{
+		tokenProbesMetric.WithLabelValues(token.Type, dynatracev1beta1.ReasonTokenError).Inc()
+		r.setAndLogCondition(r.status.conditions, metav1.Condition{
 			Type:    token.Type,
 			Status:  metav1.ConditionFalse,
 			Reason:  dynatracev1beta1.ReasonTokenError,
@@ -209,23 +276,20 @@ func (r *DynatraceClientReconciler) CheckToken(dtc dtclient.Client, token tokenC
 		return true
 	}
 
-	missingScopes := make([]string, 0)
-	for _, s := range token.Scopes {
-		if !ss.Contains(s) {
-			missingScopes = append(missingScopes, s)
-		}
-	}
+	missingScopes := MissingScopesPerFeature(token.Requirements, ss)
 	if len(missingScopes) > 0 {
-		r.setAndLogCondition(&r.status.Conditions, metav1.Condition{
+		tokenProbesMetric.WithLabelValues(token.Type, dynatracev1beta1.ReasonTokenScopeMissing).Inc()
+		r.setAndLogCondition(r.status.conditions, metav1.Condition{
 			Type:    token.Type,
 			Status:  metav1.ConditionFalse,
 			Reason:  dynatracev1beta1.ReasonTokenScopeMissing,
-			Message: fmt.Sprintf("Token on secret %s missing scopes [%s]", r.secretKey, strings.Join(missingScopes, ", ")),
+			Message: fmt.Sprintf("Token on secret %s missing scopes %s", r.secretKey, strings.Join(missingScopes, ", ")),
 		})
 		return true
 	}
 
-	r.setAndLogCondition(&r.status.Conditions, metav1.Condition{
+	tokenProbesMetric.WithLabelValues(token.Type, dynatracev1beta1.ReasonTokenReady).Inc()
+	r.setAndLogCondition(r.status.conditions, metav1.Condition{
 		Type:    token.Type,
 		Status:  metav1.ConditionTrue,
 		Reason:  dynatracev1beta1.ReasonTokenReady,
@@ -234,22 +298,33 @@ func (r *DynatraceClientReconciler) CheckToken(dtc dtclient.Client, token tokenC
 	return true
 }
 
-func (r *DynatraceClientReconciler) removePaaSTokenCondition() bool {
-	if meta.FindStatusCondition(r.status.Conditions, dynatracev1beta1.PaaSTokenConditionType) != nil {
-		meta.RemoveStatusCondition(&r.status.Conditions, dynatracev1beta1.PaaSTokenConditionType)
-		return true
+func requirementsForToken(requirements []dynatracev1beta1.TokenScopeRequirement, tokenKey string) []dynatracev1beta1.TokenScopeRequirement {
+	var tokenRequirements []dynatracev1beta1.TokenScopeRequirement
+	for _, requirement := range requirements {
+		if requirement.Token == tokenKey {
+			tokenRequirements = append(tokenRequirements, requirement)
+		}
 	}
-	return false
+	return tokenRequirements
 }
 
-func (r *DynatraceClientReconciler) getSecret(ctx context.Context, instance *dynatracev1beta1.DynaKube) (*corev1.Secret, error) {
-	secretName := instance.Tokens()
-	ns := instance.GetNamespace()
-	secret := corev1.Secret{}
-	if err := r.Client.Get(ctx, client.ObjectKey{Name: secretName, Namespace: ns}, &secret); err != nil {
-		return nil, err
+// MissingScopesPerFeature lists the scopes missing for each of the requirements as "[scope, ...] for feature".
+func MissingScopesPerFeature(requirements []dynatracev1beta1.TokenScopeRequirement, scopes dtclient.TokenScopes) []string {
+	var missing []string
+	for _, requirement := range requirements {
+		if missingScopes := requirement.MissingScopes(scopes); len(missingScopes) > 0 {
+			missing = append(missing, fmt.Sprintf("[%s] for %s", strings.Join(missingScopes, ", "), requirement.Feature))
+		}
 	}
-	return &secret, nil
+	return missing
+}
+
+func (r *DynatraceClientReconciler) removePaaSTokenCondition() bool {
+	if meta.FindStatusCondition(*r.status.conditions, dynatracev1beta1.PaaSTokenConditionType) != nil {
+		meta.RemoveStatusCondition(r.status.conditions, dynatracev1beta1.PaaSTokenConditionType)
+		return true
+	}
+	return false
 }
 
 func (r *DynatraceClientReconciler) setTokens(secret *corev1.Secret) {
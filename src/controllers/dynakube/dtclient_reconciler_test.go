@@ -7,8 +7,10 @@ import (
 	"time"
 
 	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/tokenrotation"
 	"github.com/Dynatrace/dynatrace-operator/src/dtclient"
 	"github.com/Dynatrace/dynatrace-operator/src/scheme/fake"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -120,6 +122,9 @@ func TestReconcileDynatraceClient_TokenValidation(t *testing.T) {
 			Now:                 metav1.Now(),
 		}
 
+		unauthorizedProbes := testutil.ToFloat64(tokenProbesMetric.WithLabelValues(dynatracev1beta1.PaaSTokenConditionType, dynatracev1beta1.ReasonTokenUnauthorized))
+		failedProbes := testutil.ToFloat64(tokenProbesMetric.WithLabelValues(dynatracev1beta1.APITokenConditionType, dynatracev1beta1.ReasonTokenError))
+
 		dtc, ucr, err := rec.Reconcile(context.TODO(), dk)
 		assert.Equal(t, dtcMock, dtc)
 		assert.True(t, ucr)
@@ -131,6 +136,9 @@ func TestReconcileDynatraceClient_TokenValidation(t *testing.T) {
 		AssertCondition(t, dk, dynatracev1beta1.APITokenConditionType, false, dynatracev1beta1.ReasonTokenError,
 			"error when querying token on secret dynatrace:dynakube: random error")
 
+		assert.Equal(t, unauthorizedProbes+1, testutil.ToFloat64(tokenProbesMetric.WithLabelValues(dynatracev1beta1.PaaSTokenConditionType, dynatracev1beta1.ReasonTokenUnauthorized)))
+		assert.Equal(t, failedProbes+1, testutil.ToFloat64(tokenProbesMetric.WithLabelValues(dynatracev1beta1.APITokenConditionType, dynatracev1beta1.ReasonTokenError)))
+
 		mock.AssertExpectationsForObjects(t, dtcMock)
 	})
 
@@ -153,7 +161,7 @@ func TestReconcileDynatraceClient_TokenValidation(t *testing.T) {
 		assert.NoError(t, err)
 
 		AssertCondition(t, dk, dynatracev1beta1.PaaSTokenConditionType, false, dynatracev1beta1.ReasonTokenScopeMissing,
-			"Token on secret dynatrace:dynakube missing scopes [InstallerDownload]")
+			"Token on secret dynatrace:dynakube missing scopes [InstallerDownload] for OneAgent download")
 		AssertCondition(t, dk, dynatracev1beta1.APITokenConditionType, false, dynatracev1beta1.ReasonTokenUnauthorized,
 			"Token on secret dynatrace:dynakube has leading and/or trailing spaces")
 
@@ -216,7 +224,7 @@ func TestReconcileDynatraceClient_TokenValidation(t *testing.T) {
 
 		AssertCondition(t, dk, dynatracev1beta1.PaaSTokenConditionType, true, dynatracev1beta1.ReasonTokenReady, "Ready")
 		AssertCondition(t, dk, dynatracev1beta1.APITokenConditionType, false, dynatracev1beta1.ReasonTokenScopeMissing,
-			"Token on secret dynatrace:dynakube missing scopes [entities.read]")
+			"Token on secret dynatrace:dynakube missing scopes [entities.read] for automatic Kubernetes API monitoring")
 		mock.AssertExpectationsForObjects(t, dtcMock)
 	})
 	t.Run("API token has missing scope for metrics ingest", func(t *testing.T) {
@@ -247,7 +255,7 @@ func TestReconcileDynatraceClient_TokenValidation(t *testing.T) {
 		assert.False(t, rec.ValidTokens)
 
 		AssertCondition(t, dk, dynatracev1beta1.APITokenConditionType, true, dynatracev1beta1.ReasonTokenReady, "Ready")
-		AssertCondition(t, dk, dynatracev1beta1.DataIngestTokenConditionType, false, dynatracev1beta1.ReasonTokenScopeMissing, "Token on secret dynatrace:dynakube missing scopes [metrics.ingest]")
+		AssertCondition(t, dk, dynatracev1beta1.DataIngestTokenConditionType, false, dynatracev1beta1.ReasonTokenScopeMissing, "Token on secret dynatrace:dynakube missing scopes [metrics.ingest] for data-ingest")
 		mock.AssertExpectationsForObjects(t, dtcMock)
 	})
 }
@@ -343,6 +351,109 @@ func TestReconcileDynatraceClient_ProbeRequests(t *testing.T) {
 	})
 }
 
+func TestReconcileDynatraceClient_TokenRotation(t *testing.T) {
+	now := metav1.Now()
+	lastProbe := metav1.NewTime(now.Add(-3 * time.Minute))
+
+	namespace := "dynatrace"
+	dkName := "dynakube"
+	base := dynatracev1beta1.DynaKube{
+		ObjectMeta: metav1.ObjectMeta{Name: dkName, Namespace: namespace},
+		Spec: dynatracev1beta1.DynaKubeSpec{
+			APIURL: "https://ENVIRONMENTID.live.dynatrace.com/api",
+			Tokens: dkName,
+		},
+		Status: dynatracev1beta1.DynaKubeStatus{
+			LastAPITokenProbeTimestamp:  &lastProbe,
+			LastPaaSTokenProbeTimestamp: &lastProbe,
+		},
+	}
+	for _, conditionType := range []string{dynatracev1beta1.APITokenConditionType, dynatracev1beta1.PaaSTokenConditionType} {
+		meta.SetStatusCondition(&base.Status.Conditions, metav1.Condition{
+			Type:    conditionType,
+			Status:  metav1.ConditionTrue,
+			Reason:  dynatracev1beta1.ReasonTokenReady,
+			Message: "Ready",
+		})
+	}
+	meta.SetStatusCondition(&base.Status.Conditions, metav1.Condition{
+		Type:   dynatracev1beta1.TokensPropagatedConditionType,
+		Status: metav1.ConditionTrue,
+		Reason: dynatracev1beta1.ReasonReady,
+	})
+
+	previousHash, err := tokenrotation.TokensHash("84", "42", "")
+	require.NoError(t, err)
+	base.Status.TokenRotation = dynatracev1beta1.TokenRotationStatus{
+		TokensHash:     previousHash,
+		UpdatedSecrets: []string{"dynatrace/dynakube-pull-secret"},
+	}
+
+	t.Run("Unchanged tokens are not probed again", func(t *testing.T) {
+		c := fake.NewClient(NewSecret(dkName, namespace, map[string]string{dtclient.DynatracePaasToken: "42", dtclient.DynatraceApiToken: "84"}))
+		dk := base.DeepCopy()
+		dtcMock := &dtclient.MockDynatraceClient{}
+
+		rec := &DynatraceClientReconciler{
+			Client:              c,
+			DynatraceClientFunc: StaticDynatraceClient(dtcMock),
+			Now:                 now,
+		}
+
+		_, ucr, err := rec.Reconcile(context.TODO(), dk)
+		assert.NoError(t, err)
+		assert.False(t, ucr)
+		assert.Equal(t, previousHash, dk.Status.TokenRotation.TokensHash)
+		mock.AssertExpectationsForObjects(t, dtcMock)
+	})
+	t.Run("Rotated tokens are probed right away", func(t *testing.T) {
+		c := fake.NewClient(NewSecret(dkName, namespace, map[string]string{dtclient.DynatracePaasToken: "43", dtclient.DynatraceApiToken: "85"}))
+		dk := base.DeepCopy()
+		dtcMock := &dtclient.MockDynatraceClient{}
+		dtcMock.On("GetTokenScopes", "43").Return(dtclient.TokenScopes{dtclient.TokenScopeInstallerDownload}, nil)
+		dtcMock.On("GetTokenScopes", "85").Return(dtclient.TokenScopes{dtclient.TokenScopeDataExport}, nil)
+
+		rec := &DynatraceClientReconciler{
+			Client:              c,
+			DynatraceClientFunc: StaticDynatraceClient(dtcMock),
+			Now:                 now,
+		}
+
+		_, ucr, err := rec.Reconcile(context.TODO(), dk)
+		assert.NoError(t, err)
+		assert.True(t, ucr)
+		assert.True(t, rec.ValidTokens)
+		assert.NotEqual(t, previousHash, dk.Status.TokenRotation.TokensHash)
+		assert.Equal(t, now, *dk.Status.TokenRotation.LastRotationTimestamp)
+		assert.Equal(t, []string{"dynatrace/dynakube-pull-secret"}, dk.Status.TokenRotation.OutdatedSecrets)
+		assert.Empty(t, dk.Status.TokenRotation.UpdatedSecrets)
+		assert.Equal(t, now, *dk.Status.LastAPITokenProbeTimestamp)
+		assert.Equal(t, now, *dk.Status.LastPaaSTokenProbeTimestamp)
+		AssertCondition(t, dk, dynatracev1beta1.TokensPropagatedConditionType, false, dynatracev1beta1.ReasonRolloutInProgress,
+			"Tokens on secret dynatrace:dynakube changed, the derived secrets are updated once the tokens are verified")
+		mock.AssertExpectationsForObjects(t, dtcMock)
+	})
+	t.Run("Tokens of additional tenants are not tracked", func(t *testing.T) {
+		c := fake.NewClient(NewSecret("target-tokens", namespace, map[string]string{dtclient.DynatracePaasToken: "43", dtclient.DynatraceApiToken: "85"}))
+		dk := base.DeepCopy()
+		target := dynatracev1beta1.TenantTargetSpec{Name: "target", APIURL: "https://TARGET.live.dynatrace.com/api", Tokens: "target-tokens"}
+		dk.Spec.AdditionalTenants = []dynatracev1beta1.TenantTargetSpec{target}
+		dtcMock := &dtclient.MockDynatraceClient{}
+		dtcMock.On("GetTokenScopes", "43").Return(dtclient.TokenScopes{dtclient.TokenScopeInstallerDownload}, nil)
+		dtcMock.On("GetTokenScopes", "85").Return(dtclient.TokenScopes{dtclient.TokenScopeDataExport}, nil)
+
+		rec := &DynatraceClientReconciler{
+			Client:              c,
+			DynatraceClientFunc: StaticDynatraceClient(dtcMock),
+			Now:                 now,
+		}
+
+		_, _, err := rec.ReconcileTenantTarget(context.TODO(), dk, target)
+		assert.NoError(t, err)
+		assert.Equal(t, previousHash, dk.Status.TokenRotation.TokensHash)
+	})
+}
+
 func AssertCondition(t *testing.T, dk *dynatracev1beta1.DynaKube, ct string, status bool, reason string, message string) {
 	t.Helper()
 	s := metav1.ConditionFalse
@@ -6,6 +6,7 @@ import (
 	"reflect"
 
 	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	"github.com/Dynatrace/dynatrace-operator/src/secretprovider"
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
@@ -42,14 +43,17 @@ func NewReconciler(clt client.Client, apiReader client.Reader, scheme *runtime.S
 }
 
 func (r *Reconciler) Reconcile() error {
+	var err error
 	if r.instance.Spec.CustomPullSecret == "" {
-		err := r.reconcilePullSecret()
-		if err != nil {
-			log.Error(err, "could not reconcile pull secret")
-			return errors.WithStack(err)
-		}
+		err = r.reconcilePullSecret()
+	} else if r.instance.NeedsPullSecretCopy() {
+		err = r.reconcileCustomPullSecret()
 	}
 
+	if err != nil {
+		log.Error(err, "could not reconcile pull secret")
+		return errors.WithStack(err)
+	}
 	return nil
 }
 
@@ -58,7 +62,19 @@ func (r *Reconciler) reconcilePullSecret() error {
 	if err != nil {
 		return fmt.Errorf("could not generate pull secret data: %w", err)
 	}
+	return r.reconcilePullSecretData(pullSecretData)
+}
+
+// reconcileCustomPullSecret copies the custom pull secret from the secret provider, as pods can only use Kubernetes secrets to pull images
+func (r *Reconciler) reconcileCustomPullSecret() error {
+	customPullSecret, err := secretprovider.NewReaderForDynakube(r.apiReader, r.instance).Get(context.TODO(), r.instance.Spec.CustomPullSecret)
+	if err != nil {
+		return fmt.Errorf("could not read custom pull secret: %w", err)
+	}
+	return r.reconcilePullSecretData(customPullSecret.Data)
+}
 
+func (r *Reconciler) reconcilePullSecretData(pullSecretData map[string][]byte) error {
 	pullSecret, err := r.createPullSecretIfNotExists(pullSecretData)
 	if err != nil {
 		return fmt.Errorf("failed to create or update secret: %w", err)
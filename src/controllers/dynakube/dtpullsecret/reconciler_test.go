@@ -2,6 +2,8 @@ package dtpullsecret
 
 import (
 	"context"
+	"os"
+	"path/filepath"
 	"testing"
 
 	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
@@ -9,6 +11,7 @@ import (
 	"github.com/Dynatrace/dynatrace-operator/src/scheme"
 	"github.com/Dynatrace/dynatrace-operator/src/scheme/fake"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -148,4 +151,32 @@ func TestReconciler_Reconcile(t *testing.T) {
 		assert.NotEmpty(t, pullSecret.Data[".dockerconfigjson"])
 		assert.Equal(t, expectedJSON, string(pullSecret.Data[".dockerconfigjson"]))
 	})
+	t.Run(`Reconcile copies custom pull secret from secret provider`, func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, testValue), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, testValue, ".dockerconfigjson"), []byte(`{"auths":{}}`), 0644))
+		instance := &dynatracev1beta1.DynaKube{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: testNamespace,
+				Name:      testName,
+			},
+			Spec: dynatracev1beta1.DynaKubeSpec{
+				CustomPullSecret: testValue,
+				SecretProvider: &dynatracev1beta1.SecretProviderSpec{
+					File: &dynatracev1beta1.FileSecretProviderSpec{Path: dir},
+				},
+			}}
+		fakeClient := fake.NewClient()
+
+		err := NewReconciler(fakeClient, fakeClient, scheme.Scheme, instance, "", "").Reconcile()
+
+		require.NoError(t, err)
+
+		var pullSecret corev1.Secret
+		err = fakeClient.Get(context.TODO(), client.ObjectKey{Name: instance.PullSecret(), Namespace: testNamespace}, &pullSecret)
+
+		require.NoError(t, err)
+		assert.Equal(t, testName+PullSecretSuffix, pullSecret.Name)
+		assert.Equal(t, `{"auths":{}}`, string(pullSecret.Data[".dockerconfigjson"]))
+	})
 }
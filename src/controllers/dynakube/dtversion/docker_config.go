@@ -28,7 +28,11 @@ func ParseDockerAuthsFromSecret(secret *corev1.Secret) (map[string]DockerAuth, e
 	if !hasConfig {
 		return nil, fmt.Errorf("could not find any docker config in image pull secret")
 	}
+	return ParseDockerAuths(config)
+}
 
+// ParseDockerAuths reads the credentials per registry from the content of a .dockerconfigjson
+func ParseDockerAuths(config []byte) (map[string]DockerAuth, error) {
 	var dockerConf struct {
 		Auths map[string]DockerAuth `json:"auths"`
 	}
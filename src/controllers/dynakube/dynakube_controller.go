@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"reflect"
+	"strings"
 	"time"
 
 	"github.com/Dynatrace/dynatrace-operator/src/agproxysecret"
@@ -12,33 +14,51 @@ import (
 	"github.com/Dynatrace/dynatrace-operator/src/controllers/activegate/capability"
 	"github.com/Dynatrace/dynatrace-operator/src/controllers/activegate/reconciler/automaticapimonitoring"
 	rcap "github.com/Dynatrace/dynatrace-operator/src/controllers/activegate/reconciler/capability"
+	dtcsi "github.com/Dynatrace/dynatrace-operator/src/controllers/csi"
 	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/activegate"
 	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/dtpullsecret"
 	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/dtversion"
 	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/istio"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/networkpolicy"
 	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/oneagent"
 	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/oneagent/daemonset"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/oneagent/rollout"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/pendingpods"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/proxysecret"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/settings"
 	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/status"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/tokenrotation"
 	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/updates"
 	"github.com/Dynatrace/dynatrace-operator/src/dtclient"
 	dtingestendpoint "github.com/Dynatrace/dynatrace-operator/src/ingestendpoint"
 	"github.com/Dynatrace/dynatrace-operator/src/initgeneration"
 	"github.com/Dynatrace/dynatrace-operator/src/mapper"
+	"github.com/Dynatrace/dynatrace-operator/src/secretprovider"
 	"github.com/pkg/errors"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 )
 
 const (
 	defaultUpdateInterval = 5 * time.Minute
+	// rolloutRequeueInterval is used while a staged OneAgent rollout, or the rollout of a component's pods, is in progress
+	rolloutRequeueInterval = 1 * time.Minute
 )
 
 func Add(mgr manager.Manager, _ string) error {
@@ -53,6 +73,7 @@ func NewController(mgr manager.Manager) *DynakubeController {
 		scheme:            mgr.GetScheme(),
 		dtcBuildFunc:      BuildDynatraceClient,
 		config:            mgr.GetConfig(),
+		recorder:          mgr.GetEventRecorderFor("dynakube-controller"),
 		operatorPodName:   os.Getenv("POD_NAME"),
 		operatorNamespace: os.Getenv("POD_NAMESPACE"),
 	}
@@ -63,16 +84,49 @@ func (controller *DynakubeController) SetupWithManager(mgr ctrl.Manager) error {
 		For(&dynatracev1beta1.DynaKube{}).
 		Owns(&appsv1.StatefulSet{}).
 		Owns(&appsv1.DaemonSet{}).
+		// rotated tokens are propagated right away instead of on the next periodic reconcile
+		Watches(&source.Kind{Type: &corev1.Secret{}}, handler.EnqueueRequestsFromMapFunc(controller.dynakubesForTokenSecret)).
 		Complete(controller)
 }
 
-func NewDynaKubeController(c client.Client, apiReader client.Reader, scheme *runtime.Scheme, dtcBuildFunc DynatraceClientFunc, config *rest.Config) *DynakubeController {
+// dynakubesForTokenSecret returns the DynaKubes in the namespace of the secret which use it as tokens secret,
+// either for the tenant of the apiUrl or for one of the additional tenants.
+func (controller *DynakubeController) dynakubesForTokenSecret(secret client.Object) []reconcile.Request {
+	var dynakubes dynatracev1beta1.DynaKubeList
+	if err := controller.client.List(context.TODO(), &dynakubes, client.InNamespace(secret.GetNamespace())); err != nil {
+		log.Info("failed to list DynaKubes for changed secret", "secret", secret.GetName(), "error", err)
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, dynakube := range dynakubes.Items {
+		if usesTokenSecret(dynakube, secret.GetName()) {
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: dynakube.Name, Namespace: dynakube.Namespace}})
+		}
+	}
+	return requests
+}
+
+func usesTokenSecret(dynakube dynatracev1beta1.DynaKube, secretName string) bool {
+	if dynakube.Tokens() == secretName {
+		return true
+	}
+	for _, target := range dynakube.Spec.AdditionalTenants {
+		if dynakube.TenantTargetTokens(target) == secretName {
+			return true
+		}
+	}
+	return false
+}
+
+func NewDynaKubeController(c client.Client, apiReader client.Reader, scheme *runtime.Scheme, dtcBuildFunc DynatraceClientFunc, config *rest.Config, recorder record.EventRecorder) *DynakubeController {
 	return &DynakubeController{
 		client:            c,
 		apiReader:         apiReader,
 		scheme:            scheme,
 		dtcBuildFunc:      dtcBuildFunc,
 		config:            config,
+		recorder:          recorder,
 		operatorPodName:   os.Getenv("POD_NAME"),
 		operatorNamespace: os.Getenv("POD_NAMESPACE"),
 	}
@@ -87,6 +141,7 @@ type DynakubeController struct {
 	scheme            *runtime.Scheme
 	dtcBuildFunc      DynatraceClientFunc
 	config            *rest.Config
+	recorder          record.EventRecorder
 	operatorPodName   string
 	operatorNamespace string
 }
@@ -111,6 +166,11 @@ func (controller *DynakubeController) Reconcile(ctx context.Context, request rec
 			if err := dkMapper.UnmapFromDynaKube(); err != nil {
 				return reconcile.Result{}, err
 			}
+			// policies outside the namespace of the DynaKube are not owned by it
+			if _, err := networkpolicy.NewReconciler(controller.client, controller.apiReader, controller.scheme, controller.config).
+				Cleanup(ctx, request.Name, request.Namespace); err != nil {
+				return reconcile.Result{}, err
+			}
 			// Request object not found, could have been deleted after reconcile request.
 			// Owned objects are automatically garbage collected. For additional cleanup logic use finalizers.
 			// Return and don't requeue
@@ -119,11 +179,29 @@ func (controller *DynakubeController) Reconcile(ctx context.Context, request rec
 		// Error reading the object - requeue the request.
 		return reconcile.Result{}, err
 	}
+	if !instance.DeletionTimestamp.IsZero() {
+		return reconcile.Result{}, controller.finalizeSettings(ctx, instance)
+	}
+	if err := controller.ensureSettingsFinalizer(ctx, instance); err != nil {
+		return reconcile.Result{}, err
+	}
+
 	dkState := status.NewDynakubeState(instance)
 	controller.reconcileDynaKube(ctx, dkState, &dkMapper)
 
+	if dkState.Err != nil {
+		dkState.SetNotReconciledConditions(dkState.Err.Error())
+	} else if !dkState.ValidTokens {
+		dkState.SetNotReconciledConditions("paas or api token not valid")
+	}
+
 	if dkState.Err != nil {
 		if !dkState.ValidTokens {
+			if dkState.Updated {
+				if errClient := controller.updateCR(ctx, instance); errClient != nil {
+					return reconcile.Result{}, fmt.Errorf("failed to update CR after failure, original, %s, then: %w", dkState.Err, errClient)
+				}
+			}
 			return reconcile.Result{}, fmt.Errorf("paas or api token not valid")
 		}
 		if dkState.Updated || instance.Status.SetPhaseOnError(dkState.Err) {
@@ -147,9 +225,97 @@ func (controller *DynakubeController) Reconcile(ctx context.Context, request rec
 		}
 	}
 
+	// the workloads were usually just updated, so the conditions are refreshed soon after the rollout instead of with the next periodic reconcile
+	if status.IsRolloutInProgress(instance) && dkState.RequeueAfter > rolloutRequeueInterval {
+		dkState.RequeueAfter = rolloutRequeueInterval
+	}
+
+	// changes in the secret provider aren't watched, so they are only noticed when the DynaKube is reconciled again
+	if refreshInterval := secretprovider.RefreshInterval(instance.Spec.SecretProvider); instance.Spec.SecretProvider != nil &&
+		dkState.RequeueAfter > refreshInterval {
+		dkState.RequeueAfter = refreshInterval
+	}
+
 	return reconcile.Result{RequeueAfter: dkState.RequeueAfter}, nil
 }
 
+// ensureSettingsFinalizer sets the settings finalizer before settings objects are written to the tenant,
+// and removes it once no objects are left.
+func (controller *DynakubeController) ensureSettingsFinalizer(ctx context.Context, instance *dynatracev1beta1.DynaKube) error {
+	needsFinalizer := len(instance.Spec.Settings) > 0 || len(instance.Status.Settings.Objects) > 0
+	if needsFinalizer == controllerutil.ContainsFinalizer(instance, settings.Finalizer) {
+		return nil
+	}
+
+	if needsFinalizer {
+		controllerutil.AddFinalizer(instance, settings.Finalizer)
+	} else {
+		controllerutil.RemoveFinalizer(instance, settings.Finalizer)
+	}
+	return errors.WithStack(controller.client.Update(ctx, instance))
+}
+
+// finalizeSettings deletes the settings objects written to the tenant before the DynaKube is removed.
+// Without valid tokens, or if the deletion keeps failing until the settings.CleanupTimeout after the deletion of the
+// DynaKube, the objects are left on the tenant, so the deletion of the DynaKube and its namespace isn't blocked.
+func (controller *DynakubeController) finalizeSettings(ctx context.Context, instance *dynatracev1beta1.DynaKube) error {
+	if !controllerutil.ContainsFinalizer(instance, settings.Finalizer) {
+		return nil
+	}
+
+	if len(instance.Status.Settings.Objects) > 0 {
+		if err := controller.deleteSettings(ctx, instance); err != nil {
+			if time.Now().Before(instance.DeletionTimestamp.Add(settings.CleanupTimeout)) {
+				return err
+			}
+			controller.reportOrphanedSettings(instance, fmt.Sprintf("deletion failed for %s: %s", settings.CleanupTimeout, err))
+		}
+	}
+
+	controllerutil.RemoveFinalizer(instance, settings.Finalizer)
+	return errors.WithStack(controller.client.Update(ctx, instance))
+}
+
+// deleteSettings deletes the settings objects of the DynaKube from the tenant, the deleted objects are removed from its status.
+func (controller *DynakubeController) deleteSettings(ctx context.Context, instance *dynatracev1beta1.DynaKube) error {
+	dtcReconciler := DynatraceClientReconciler{
+		Client:              controller.client,
+		DynatraceClientFunc: controller.dtcBuildFunc,
+	}
+	dtc, _, err := dtcReconciler.Reconcile(ctx, instance)
+	if err != nil {
+		return err
+	}
+
+	if !dtcReconciler.ValidTokens {
+		controller.reportOrphanedSettings(instance, "paas or api token not valid")
+		return nil
+	}
+	if err := settings.NewReconciler(dtc).Cleanup(instance); err != nil {
+		// only the remaining objects are deleted with the next attempt
+		if errClient := controller.updateCR(ctx, instance); errClient != nil {
+			return fmt.Errorf("failed to update CR after failure, original, %s, then: %w", err, errClient)
+		}
+		return err
+	}
+	return nil
+}
+
+// reportOrphanedSettings logs and records an event with the ids of the settings objects left on the tenant,
+// so they can be deleted manually.
+func (controller *DynakubeController) reportOrphanedSettings(instance *dynatracev1beta1.DynaKube, reason string) {
+	objectIds := make([]string, 0, len(instance.Status.Settings.Objects))
+	for _, object := range instance.Status.Settings.Objects {
+		objectIds = append(objectIds, object.ObjectId)
+	}
+
+	log.Info("settings objects are left on the tenant", "name", instance.Name, "reason", reason, "objectIds", objectIds)
+	controller.recorder.Eventf(instance,
+		corev1.EventTypeWarning,
+		settings.OrphanedSettingsEvent,
+		"Settings objects %s are left on the tenant, %s", strings.Join(objectIds, ", "), reason)
+}
+
 func (controller *DynakubeController) reconcileDynaKube(ctx context.Context, dkState *status.DynakubeState, dkMapper *mapper.DynakubeMapper) {
 	dtcReconciler := DynatraceClientReconciler{
 		Client:              controller.client,
@@ -166,35 +332,88 @@ func (controller *DynakubeController) reconcileDynaKube(ctx context.Context, dkS
 	if !dtcReconciler.ValidTokens {
 		dkState.ValidTokens = false
 		log.Info("paas or api token not valid", "name", dkState.Instance.GetName())
+		setTokensNotValidCondition(dkState)
 		return
 	}
 
 	err = status.SetDynakubeStatus(dkState.Instance, status.Options{
 		Dtc:       dtc,
 		ApiClient: controller.apiReader,
+		Now:       dkState.Now.Time,
 	})
 	if dkState.Error(err) {
 		log.Error(err, "could not set Dynakube status")
 		return
 	}
 
+	targetClients := controller.reconcileAdditionalTenants(ctx, dkState)
+
 	if dkState.Instance.Spec.EnableIstio {
-		if upd, err = istio.NewIstioReconciler(controller.config, controller.scheme).ReconcileIstio(dkState.Instance); err != nil {
+		upd, err = istio.NewIstioReconciler(controller.config, controller.scheme).ReconcileIstio(dkState.Instance)
+		dkState.SetReconcileCondition(dynatracev1beta1.IstioConditionType, err)
+		if err != nil {
 			// If there are errors log them, but move on.
 			log.Info("Istio: failed to reconcile objects", "error", err)
 		} else if upd {
 			dkState.Update(true, 30*time.Second, "Istio: objects updated")
 		}
+	} else {
+		dkState.RemoveCondition(dynatracev1beta1.IstioConditionType)
+	}
+
+	if dkState.Instance.Spec.EnableNetworkPolicies {
+		upd, err = networkpolicy.NewReconciler(controller.client, controller.apiReader, controller.scheme, controller.config).Reconcile(ctx, dkState.Instance)
+		dkState.SetReconcileCondition(dynatracev1beta1.NetworkPolicyConditionType, err)
+		if err != nil {
+			// If there are errors log them, but move on.
+			log.Info("NetworkPolicies: failed to reconcile objects", "error", err)
+		} else if upd {
+			dkState.Update(true, 30*time.Second, "NetworkPolicies: objects updated")
+		}
+	} else if meta.FindStatusCondition(dkState.Instance.Status.Conditions, dynatracev1beta1.NetworkPolicyConditionType) != nil {
+		// the condition is only set while the policies are enabled, so the policies are only looked for after they were disabled
+		upd, err = networkpolicy.NewReconciler(controller.client, controller.apiReader, controller.scheme, controller.config).
+			Cleanup(ctx, dkState.Instance.Name, dkState.Instance.Namespace)
+		if err != nil {
+			log.Info("NetworkPolicies: failed to remove objects", "error", err)
+		} else {
+			dkState.RemoveCondition(dynatracev1beta1.NetworkPolicyConditionType)
+			dkState.Update(upd, defaultUpdateInterval, "NetworkPolicies: objects removed")
+		}
+	}
+
+	err = tokenrotation.
+		NewReconciler(controller.client, controller.apiReader, controller.scheme, dtc, dtcReconciler.ApiToken, dtcReconciler.PaasToken, dtcReconciler.DataIngestToken).
+		Reconcile(ctx, dkState)
+	if err != nil {
+		// Secrets which couldn't be updated are reported in the status and updated again with the next reconcile.
+		log.Info("failed to propagate tokens", "error", err)
 	}
 
 	err = dtpullsecret.
 		NewReconciler(controller.client, controller.apiReader, controller.scheme, dkState.Instance, dtcReconciler.ApiToken, dtcReconciler.PaasToken).
 		Reconcile()
+	dkState.SetReconcileCondition(dynatracev1beta1.PullSecretConditionType, err)
 	if dkState.Error(err) {
 		log.Error(err, "could not reconcile Dynatrace pull secret")
 		return
 	}
 
+	upd, err = proxysecret.NewReconciler(controller.client, controller.apiReader, controller.scheme, dkState.Instance).Reconcile(ctx)
+	if dkState.Error(err) {
+		log.Error(err, "could not reconcile proxy secret")
+		return
+	}
+	dkState.Update(upd, defaultUpdateInterval, "proxy secret updated")
+
+	err = settings.NewReconciler(dtc).Reconcile(dkState)
+	if err != nil {
+		// Objects which couldn't be written are reported in the status and written again with the next reconcile.
+		log.Info("failed to reconcile settings objects", "error", err)
+	}
+
+	controller.setCSIDriverCondition(ctx, dkState)
+
 	if dkState.Instance.FeatureEnableActivegateRawImage() && dkState.Instance.NeedsActiveGate() {
 		err = activegate.
 			NewTenantSecretReconciler(controller.client, controller.apiReader, controller.scheme, dkState.Instance, dtcReconciler.ApiToken, dtc).
@@ -205,6 +424,8 @@ func (controller *DynakubeController) reconcileDynaKube(ctx context.Context, dkS
 		}
 	}
 
+	controller.reconcileRollbacks(ctx, dkState)
+
 	upd, err = updates.ReconcileVersions(ctx, dkState, controller.client, dtversion.GetImageVersion)
 	dkState.Update(upd, defaultUpdateInterval, "Found updates")
 	dkState.Error(err)
@@ -217,6 +438,8 @@ func (controller *DynakubeController) reconcileDynaKube(ctx context.Context, dkS
 		upd, err = oneagent.NewOneAgentReconciler(
 			controller.client, controller.apiReader, controller.scheme, dkState.Instance, daemonset.HostMonitoringFeature,
 		).Reconcile(ctx, dkState)
+		controller.setOneAgentCondition(ctx, dkState, err)
+		controller.reconcileOneAgentRollout(ctx, dkState, dtc)
 		if dkState.Error(err) || dkState.Update(upd, defaultUpdateInterval, "infra monitoring reconciled") {
 			return
 		}
@@ -224,6 +447,8 @@ func (controller *DynakubeController) reconcileDynaKube(ctx context.Context, dkS
 		upd, err = oneagent.NewOneAgentReconciler(
 			controller.client, controller.apiReader, controller.scheme, dkState.Instance, daemonset.CloudNativeFeature,
 		).Reconcile(ctx, dkState)
+		controller.setOneAgentCondition(ctx, dkState, err)
+		controller.reconcileOneAgentRollout(ctx, dkState, dtc)
 		if dkState.Error(err) || dkState.Update(upd, defaultUpdateInterval, "cloud native infra monitoring reconciled") {
 			return
 		}
@@ -231,13 +456,19 @@ func (controller *DynakubeController) reconcileDynaKube(ctx context.Context, dkS
 		upd, err = oneagent.NewOneAgentReconciler(
 			controller.client, controller.apiReader, controller.scheme, dkState.Instance, daemonset.ClassicFeature,
 		).Reconcile(ctx, dkState)
+		controller.setOneAgentCondition(ctx, dkState, err)
+		controller.reconcileOneAgentRollout(ctx, dkState, dtc)
 		if dkState.Error(err) || dkState.Update(upd, defaultUpdateInterval, "classic fullstack reconciled") {
 			return
 		}
 	} else {
+		dkState.RemoveCondition(dynatracev1beta1.OneAgentConditionType)
 		controller.removeOneAgentDaemonSet(dkState)
+		controller.reconcileOneAgentRollout(ctx, dkState, dtc)
 	}
 
+	controller.reconcileTenantTargetComponents(ctx, dkState, targetClients)
+
 	endpointSecretGenerator := dtingestendpoint.NewEndpointSecretGenerator(controller.client, controller.apiReader, dkState.Instance.Namespace)
 	if dkState.Instance.NeedAppInjection() {
 		if err := dkMapper.MapFromDynakube(); err != nil {
@@ -259,7 +490,17 @@ func (controller *DynakubeController) reconcileDynaKube(ctx context.Context, dkS
 				return
 			}
 		}
+
+		upd, err = pendingpods.NewReconciler(controller.client, controller.apiReader, dkState.Now).Reconcile(ctx, dkState.Instance)
+		if err != nil {
+			// If there are errors log them, but move on.
+			log.Info("failed to reconcile uninjected pods", "error", err)
+		}
+		dkState.Update(upd, defaultUpdateInterval, "uninjected workloads updated")
 	} else {
+		dkState.Update(len(dkState.Instance.Status.UninjectedWorkloads) > 0, defaultUpdateInterval, "uninjected workloads removed")
+		dkState.Instance.Status.UninjectedWorkloads = nil
+		dkState.Instance.Status.LastUninjectedWorkloadsScanTimestamp = nil
 		if err := dkMapper.UnmapFromDynaKube(); err != nil {
 			log.Error(err, "could not unmap dynakube from namespace")
 			return
@@ -271,6 +512,213 @@ func (controller *DynakubeController) reconcileDynaKube(ctx context.Context, dkS
 	}
 }
 
+// setTokensNotValidCondition explains why changed tokens are not propagated, the derived secrets keep the previous tokens
+// meanwhile, so components keep working as long as the previous tokens are valid.
+func setTokensNotValidCondition(dkState *status.DynakubeState) {
+	condition := meta.FindStatusCondition(dkState.Instance.Status.Conditions, dynatracev1beta1.TokensPropagatedConditionType)
+	if condition == nil || condition.Status == metav1.ConditionTrue {
+		return
+	}
+	dkState.SetCondition(metav1.Condition{
+		Type:    dynatracev1beta1.TokensPropagatedConditionType,
+		Status:  metav1.ConditionFalse,
+		Reason:  dynatracev1beta1.ReasonTokensNotValid,
+		Message: "The tokens didn't pass the verifications, the derived secrets keep using the previous tokens",
+	})
+}
+
+// tenantTargetClient is the client of an additional tenant whose tokens are valid
+type tenantTargetClient struct {
+	dtc      dtclient.Client
+	apiToken string
+}
+
+// reconcileAdditionalTenants checks the tokens and connection info of every additional tenant and returns the clients of the ones with valid tokens.
+// Problems of a tenant target are tracked in its status, but don't stop the reconciliation of the primary tenant.
+func (controller *DynakubeController) reconcileAdditionalTenants(ctx context.Context, dkState *status.DynakubeState) map[string]tenantTargetClient {
+	instance := dkState.Instance
+	removeStaleTenantTargetStatuses(dkState)
+	targetClients := map[string]tenantTargetClient{}
+
+	for _, target := range instance.Spec.AdditionalTenants {
+		dtcReconciler := DynatraceClientReconciler{
+			Client:              controller.client,
+			DynatraceClientFunc: controller.dtcBuildFunc,
+		}
+		dtc, upd, err := dtcReconciler.ReconcileTenantTarget(ctx, instance, target)
+		dkState.Update(upd, defaultUpdateInterval, "Token conditions of tenant target updated")
+		if err != nil {
+			log.Error(err, "failed to check tokens of tenant target", "tenantTarget", target.Name)
+			continue
+		} else if !dtcReconciler.ValidTokens {
+			log.Info("paas or api token of tenant target not valid", "name", instance.GetName(), "tenantTarget", target.Name)
+			continue
+		}
+
+		targetStatus := instance.TenantTargetStatus(target.Name)
+		oldConnectionInfo := targetStatus.ConnectionInfo
+		if err := status.SetTenantTargetStatus(targetStatus, dtc); err != nil {
+			log.Error(err, "could not set status of tenant target", "tenantTarget", target.Name)
+			continue
+		}
+		dkState.Update(!reflect.DeepEqual(oldConnectionInfo, targetStatus.ConnectionInfo), defaultUpdateInterval, "Connection info of tenant target updated")
+		targetClients[target.Name] = tenantTargetClient{dtc: dtc, apiToken: dtcReconciler.ApiToken}
+	}
+	return targetClients
+}
+
+// reconcileTenantTargetComponents deploys the ActiveGate and the OneAgents of every additional tenant with valid tokens,
+// next to the ones of the primary tenant, and removes the ones no longer needed.
+// Problems are logged, but don't change the conditions of the DynaKube.
+func (controller *DynakubeController) reconcileTenantTargetComponents(ctx context.Context, dkState *status.DynakubeState, targetClients map[string]tenantTargetClient) {
+	instance := dkState.Instance
+	oneAgentFeature := oneAgentFeature(instance)
+
+	for _, target := range instance.Spec.AdditionalTenants {
+		targetClient, ok := targetClients[target.Name]
+		if !ok {
+			continue
+		}
+
+		if instance.ActiveGateMode() {
+			upd, err := controller.reconcileTenantTargetActiveGate(dkState, target.Name, targetClient)
+			if err != nil {
+				log.Error(err, "could not reconcile ActiveGate of tenant target", "tenantTarget", target.Name)
+			}
+			dkState.Update(upd, defaultUpdateInterval, "ActiveGate of tenant target reconciled")
+		}
+
+		if oneAgentFeature != "" {
+			upd, err := controller.reconcileTenantTargetOneAgent(ctx, dkState, oneAgentFeature, target.Name, targetClient)
+			if err != nil {
+				log.Error(err, "could not reconcile OneAgent of tenant target", "tenantTarget", target.Name)
+			}
+			dkState.Update(upd, defaultUpdateInterval, "OneAgent of tenant target reconciled")
+		}
+	}
+
+	if err := controller.removeStaleTenantTargetObjects(ctx, instance); err != nil {
+		log.Error(err, "could not remove objects of tenant targets")
+	}
+}
+
+func (controller *DynakubeController) reconcileTenantTargetActiveGate(dkState *status.DynakubeState, target string, targetClient tenantTargetClient) (bool, error) {
+	err := activegate.
+		NewTenantTargetSecretReconciler(controller.client, controller.apiReader, controller.scheme, dkState.Instance, target, targetClient.apiToken, targetClient.dtc).
+		Reconcile()
+	if err != nil {
+		return false, err
+	}
+
+	return rcap.NewTenantTargetReconciler(
+		capability.NewMultiCapability(dkState.Instance), target, controller.client, controller.apiReader, controller.scheme, dkState.Instance).Reconcile()
+}
+
+func (controller *DynakubeController) reconcileTenantTargetOneAgent(ctx context.Context, dkState *status.DynakubeState, feature string, target string, targetClient tenantTargetClient) (bool, error) {
+	tenantInfo, err := targetClient.dtc.GetAgentTenantInfo()
+	if err != nil {
+		return false, err
+	}
+
+	return oneagent.NewOneAgentReconciler(controller.client, controller.apiReader, controller.scheme, dkState.Instance, feature).
+		ReconcileTenantTarget(ctx, dkState, target, tenantInfo)
+}
+
+// removeStaleTenantTargetObjects deletes the objects of tenant targets, which were removed from the DynaKube or no longer need the component.
+// The objects of tenant targets with invalid tokens are kept, so their components keep working.
+func (controller *DynakubeController) removeStaleTenantTargetObjects(ctx context.Context, instance *dynatracev1beta1.DynaKube) error {
+	isTenantTarget, err := labels.NewRequirement(dynatracev1beta1.LabelTenantTarget, selection.Exists, nil)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	listOpts := []client.ListOption{
+		client.InNamespace(instance.Namespace),
+		client.MatchingLabelsSelector{Selector: labels.NewSelector().Add(*isTenantTarget)},
+	}
+	isStale := func(obj metav1.Object, needed bool) bool {
+		return metav1.IsControlledBy(obj, instance) && (!needed || instance.TenantTarget(obj.GetLabels()[dynatracev1beta1.LabelTenantTarget]) == nil)
+	}
+
+	var statefulSets appsv1.StatefulSetList
+	if err := controller.apiReader.List(ctx, &statefulSets, listOpts...); err != nil {
+		return errors.WithStack(err)
+	}
+	for i := range statefulSets.Items {
+		sts := &statefulSets.Items[i]
+		if !isStale(sts, instance.ActiveGateMode()) {
+			continue
+		}
+		log.Info("removing ActiveGate of tenant target", "name", sts.Name)
+		// the service is named like the statefulset of the capability
+		svc := corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: sts.Name, Namespace: sts.Namespace}}
+		if err := controller.ensureDeleted(sts); err != nil {
+			return errors.WithStack(err)
+		}
+		if err := controller.ensureDeleted(&svc); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+
+	var daemonSets appsv1.DaemonSetList
+	if err := controller.apiReader.List(ctx, &daemonSets, listOpts...); err != nil {
+		return errors.WithStack(err)
+	}
+	for i := range daemonSets.Items {
+		ds := &daemonSets.Items[i]
+		if isStale(ds, instance.NeedsOneAgent()) {
+			log.Info("removing OneAgent of tenant target", "name", ds.Name)
+			if err := controller.ensureDeleted(ds); err != nil {
+				return errors.WithStack(err)
+			}
+		}
+	}
+
+	var secrets corev1.SecretList
+	if err := controller.apiReader.List(ctx, &secrets, listOpts...); err != nil {
+		return errors.WithStack(err)
+	}
+	for i := range secrets.Items {
+		secret := &secrets.Items[i]
+		target := secret.Labels[dynatracev1beta1.LabelTenantTarget]
+		needed := (secret.Name == instance.AGTenantSecretForTarget(target) && instance.ActiveGateMode()) ||
+			(secret.Name == instance.OneAgentTenantSecretForTarget(target) && instance.NeedsOneAgent())
+		if isStale(secret, needed) {
+			log.Info("removing tenant secret of tenant target", "name", secret.Name)
+			if err := controller.ensureDeleted(secret); err != nil {
+				return errors.WithStack(err)
+			}
+		}
+	}
+	return nil
+}
+
+func oneAgentFeature(instance *dynatracev1beta1.DynaKube) string {
+	switch {
+	case instance.HostMonitoringMode():
+		return daemonset.HostMonitoringFeature
+	case instance.CloudNativeFullstackMode():
+		return daemonset.CloudNativeFeature
+	case instance.ClassicFullStackMode():
+		return daemonset.ClassicFeature
+	}
+	return ""
+}
+
+func removeStaleTenantTargetStatuses(dkState *status.DynakubeState) {
+	instance := dkState.Instance
+	targetStatuses := make([]dynatracev1beta1.TenantTargetStatus, 0, len(instance.Status.AdditionalTenants))
+	for _, targetStatus := range instance.Status.AdditionalTenants {
+		if instance.TenantTarget(targetStatus.Name) != nil {
+			targetStatuses = append(targetStatuses, targetStatus)
+		}
+	}
+
+	if len(targetStatuses) != len(instance.Status.AdditionalTenants) {
+		instance.Status.AdditionalTenants = targetStatuses
+		dkState.Update(true, defaultUpdateInterval, "Removed status of tenant targets")
+	}
+}
+
 func (controller *DynakubeController) removeOneAgentDaemonSet(dkState *status.DynakubeState) {
 	ds := appsv1.DaemonSet{ObjectMeta: metav1.ObjectMeta{Name: dkState.Instance.OneAgentDaemonsetName(), Namespace: dkState.Instance.Namespace}}
 	if err := controller.ensureDeleted(&ds); dkState.Error(err) {
@@ -318,10 +766,12 @@ func (controller *DynakubeController) reconcileActiveGateCapabilities(dynakubeSt
 		if c.Enabled() {
 			upd, err := rcap.NewReconciler(
 				c, controller.client, controller.apiReader, controller.scheme, dynakubeState.Instance).Reconcile()
+			controller.setActiveGateCondition(dynakubeState, c, err)
 			if dynakubeState.Error(err) || dynakubeState.Update(upd, defaultUpdateInterval, c.ShortName()+" reconciled") {
 				return false
 			}
 		} else {
+			dynakubeState.RemoveCondition(activeGateConditionType(c))
 			sts := appsv1.StatefulSet{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      capability.CalculateStatefulSetName(c, dynakubeState.Instance.Name),
@@ -352,14 +802,129 @@ func (controller *DynakubeController) reconcileActiveGateCapabilities(dynakubeSt
 		dynakubeState.Instance.KubernetesMonitoringMode() {
 		err := automaticapimonitoring.NewReconciler(dtc, dynakubeState.Instance.Name, dynakubeState.Instance.Status.KubeSystemUUID).
 			Reconcile()
+		dynakubeState.SetReconcileCondition(dynatracev1beta1.AutomaticApiMonitoringConditionType, err)
 		if err != nil {
 			log.Error(err, "could not create setting")
 		}
+	} else {
+		dynakubeState.RemoveCondition(dynatracev1beta1.AutomaticApiMonitoringConditionType)
 	}
 
 	return true
 }
 
+// setOneAgentCondition sets the OneAgent condition according to the rollout of the daemonset, unless reconciling it failed.
+func (controller *DynakubeController) setOneAgentCondition(ctx context.Context, dkState *status.DynakubeState, err error) {
+	if err != nil {
+		dkState.SetReconcileCondition(dynatracev1beta1.OneAgentConditionType, err)
+		return
+	}
+	controller.setDaemonSetCondition(ctx, dkState, dynatracev1beta1.OneAgentConditionType, dkState.Instance.OneAgentDaemonsetName())
+}
+
+// reconcileOneAgentRollout progresses the staged rollout of the OneAgent pods, if configured, and sets its condition.
+// While the rollout is in progress the DynaKube is requeued more often, to move on as soon as a stage is done.
+func (controller *DynakubeController) reconcileOneAgentRollout(ctx context.Context, dkState *status.DynakubeState, dtc dtclient.Client) {
+	if dkState.Instance.OneAgentRollout() == nil {
+		dkState.RemoveCondition(dynatracev1beta1.OneAgentRolloutConditionType)
+		dkState.Update(dkState.Instance.Status.OneAgent.Rollout != nil, defaultUpdateInterval, "OneAgent rollout status removed")
+		dkState.Instance.Status.OneAgent.Rollout = nil
+		return
+	}
+
+	upd, err := rollout.NewReconciler(controller.client, controller.apiReader, dtc, dkState.Now).Reconcile(ctx, dkState.Instance)
+	if err != nil {
+		// If there are errors log them, but move on.
+		dkState.SetReconcileCondition(dynatracev1beta1.OneAgentRolloutConditionType, err)
+		log.Info("OneAgent rollout: failed to reconcile", "error", err)
+		return
+	}
+
+	dkState.SetCondition(rollout.Condition(dkState.Instance.Status.OneAgent.Rollout))
+	dkState.Update(upd, rolloutRequeueInterval, "OneAgent rollout progressed")
+
+	if rolloutStatus := dkState.Instance.Status.OneAgent.Rollout; rolloutStatus != nil &&
+		(rolloutStatus.Phase == dynatracev1beta1.RolloutProgressing || rolloutStatus.Phase == dynatracev1beta1.RolloutSoaking) &&
+		dkState.RequeueAfter > rolloutRequeueInterval {
+		dkState.RequeueAfter = rolloutRequeueInterval
+	}
+}
+
+// reconcileRollbacks restores the previous OneAgent and ActiveGate versions, if the pods of an update don't become ready,
+// and sets the RolledBack condition. While an update is watched the DynaKube is requeued more often, to roll back in time.
+func (controller *DynakubeController) reconcileRollbacks(ctx context.Context, dkState *status.DynakubeState) {
+	upd, err := updates.ReconcileRollbacks(ctx, dkState, controller.apiReader)
+	dkState.Update(upd, defaultUpdateInterval, "failed update rolled back")
+	dkState.Error(err)
+
+	if condition := updates.RollbackCondition(dkState.Instance); condition != nil {
+		dkState.SetCondition(*condition)
+	} else {
+		dkState.RemoveCondition(dynatracev1beta1.RolledBackConditionType)
+	}
+
+	dkStatus := dkState.Instance.Status
+	if (dkStatus.OneAgent.PreviousVersion != "" || dkStatus.ActiveGate.PreviousVersion != "") &&
+		dkState.RequeueAfter > defaultUpdateInterval {
+		dkState.RequeueAfter = defaultUpdateInterval
+	}
+}
+
+// setCSIDriverCondition sets the CSI driver condition according to the rollout of the CSI driver daemonset, which is not managed by the DynaKube,
+// but has to be running on the nodes for the code modules to be provisioned.
+func (controller *DynakubeController) setCSIDriverCondition(ctx context.Context, dkState *status.DynakubeState) {
+	if !dkState.Instance.NeedsCSIDriver() {
+		dkState.RemoveCondition(dynatracev1beta1.CSIDriverConditionType)
+		return
+	}
+	controller.setDaemonSetCondition(ctx, dkState, dynatracev1beta1.CSIDriverConditionType, dtcsi.DaemonSetName)
+}
+
+// setDaemonSetCondition sets the condition according to the rollout of the daemonset. It's read from the api server,
+// as the daemonset was usually just updated and the cache would still report the previous rollout.
+func (controller *DynakubeController) setDaemonSetCondition(ctx context.Context, dkState *status.DynakubeState, conditionType string, name string) {
+	var ds appsv1.DaemonSet
+	err := controller.apiReader.Get(ctx, client.ObjectKey{Name: name, Namespace: dkState.Instance.Namespace}, &ds)
+	if k8serrors.IsNotFound(err) {
+		dkState.SetCondition(status.NotFoundCondition(conditionType, "daemonset", name))
+	} else if err != nil {
+		dkState.SetReconcileCondition(conditionType, err)
+	} else {
+		dkState.SetCondition(status.DaemonSetCondition(conditionType, &ds))
+	}
+}
+
+// setActiveGateCondition sets the condition of the capability according to the rollout of its statefulset, unless reconciling it failed.
+func (controller *DynakubeController) setActiveGateCondition(dkState *status.DynakubeState, c capability.Capability, err error) {
+	conditionType := activeGateConditionType(c)
+	if err != nil {
+		dkState.SetReconcileCondition(conditionType, err)
+		return
+	}
+
+	name := capability.CalculateStatefulSetName(c, dkState.Instance.Name)
+	var sts appsv1.StatefulSet
+	err = controller.apiReader.Get(context.TODO(), client.ObjectKey{Name: name, Namespace: dkState.Instance.Namespace}, &sts)
+	if k8serrors.IsNotFound(err) {
+		dkState.SetCondition(status.NotFoundCondition(conditionType, "statefulset", name))
+	} else if err != nil {
+		dkState.SetReconcileCondition(conditionType, err)
+	} else {
+		dkState.SetCondition(status.StatefulSetCondition(conditionType, &sts))
+	}
+}
+
+func activeGateConditionType(c capability.Capability) string {
+	switch c.ShortName() {
+	case dynatracev1beta1.KubeMonCapability.ShortName:
+		return dynatracev1beta1.KubeMonActiveGateConditionType
+	case dynatracev1beta1.RoutingCapability.ShortName:
+		return dynatracev1beta1.RoutingActiveGateConditionType
+	default:
+		return dynatracev1beta1.ActiveGateConditionType
+	}
+}
+
 func (controller *DynakubeController) updateCR(ctx context.Context, instance *dynatracev1beta1.DynaKube) error {
 	instance.Status.UpdatedTimestamp = metav1.Now()
 	err := controller.client.Status().Update(ctx, instance)
@@ -2,12 +2,18 @@ package dynakube
 
 import (
 	"context"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/Dynatrace/dynatrace-operator/src/agproxysecret"
 	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
 	"github.com/Dynatrace/dynatrace-operator/src/controllers/activegate/capability"
 	rcap "github.com/Dynatrace/dynatrace-operator/src/controllers/activegate/reconciler/capability"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/activegate"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/oneagent/daemonset"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/settings"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/status"
 	"github.com/Dynatrace/dynatrace-operator/src/dtclient"
 	"github.com/Dynatrace/dynatrace-operator/src/kubesystem"
 	"github.com/Dynatrace/dynatrace-operator/src/scheme"
@@ -18,9 +24,11 @@ import (
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
@@ -392,6 +400,230 @@ func TestReconcile_ActiveGateMultiCapability(t *testing.T) {
 	assert.True(t, k8serrors.IsNotFound(err))
 }
 
+func TestReconcile_ComponentConditions(t *testing.T) {
+	t.Run(`conditions of reconciled components are set`, func(t *testing.T) {
+		mockClient := createDTMockClient(dtclient.TokenScopes{dtclient.TokenScopeInstallerDownload},
+			dtclient.TokenScopes{dtclient.TokenScopeDataExport})
+		instance := &dynatracev1beta1.DynaKube{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      testName,
+				Namespace: testNamespace,
+			},
+			Spec: dynatracev1beta1.DynaKubeSpec{
+				ActiveGate: dynatracev1beta1.ActiveGateSpec{
+					Capabilities: []dynatracev1beta1.CapabilityDisplayName{
+						dynatracev1beta1.RoutingCapability.DisplayName,
+					},
+				},
+			}}
+		controller := createFakeClientAndReconcile(mockClient, instance, testPaasToken, testAPIToken)
+
+		result, err := controller.Reconcile(context.TODO(), reconcile.Request{
+			NamespacedName: types.NamespacedName{Namespace: testNamespace, Name: testName},
+		})
+		require.NoError(t, err)
+
+		var dynakube dynatracev1beta1.DynaKube
+		require.NoError(t, controller.client.Get(context.TODO(), client.ObjectKey{Name: testName, Namespace: testNamespace}, &dynakube))
+
+		pullSecretCondition := meta.FindStatusCondition(dynakube.Status.Conditions, dynatracev1beta1.PullSecretConditionType)
+		require.NotNil(t, pullSecretCondition)
+		assert.Equal(t, metav1.ConditionTrue, pullSecretCondition.Status)
+
+		activeGateCondition := meta.FindStatusCondition(dynakube.Status.Conditions, dynatracev1beta1.ActiveGateConditionType)
+		require.NotNil(t, activeGateCondition)
+		assert.Equal(t, metav1.ConditionFalse, activeGateCondition.Status)
+		assert.Equal(t, dynatracev1beta1.ReasonRolloutInProgress, activeGateCondition.Reason)
+		assert.LessOrEqual(t, result.RequeueAfter, rolloutRequeueInterval)
+
+		assert.Nil(t, meta.FindStatusCondition(dynakube.Status.Conditions, dynatracev1beta1.OneAgentConditionType))
+	})
+	t.Run(`conditions of disabled components are removed`, func(t *testing.T) {
+		mockClient := createDTMockClient(dtclient.TokenScopes{dtclient.TokenScopeInstallerDownload},
+			dtclient.TokenScopes{dtclient.TokenScopeDataExport})
+		instance := &dynatracev1beta1.DynaKube{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      testName,
+				Namespace: testNamespace,
+			},
+			Status: dynatracev1beta1.DynaKubeStatus{
+				Conditions: []metav1.Condition{
+					{Type: dynatracev1beta1.IstioConditionType, Status: metav1.ConditionTrue, Reason: dynatracev1beta1.ReasonReady},
+					{Type: dynatracev1beta1.OneAgentConditionType, Status: metav1.ConditionTrue, Reason: dynatracev1beta1.ReasonReady},
+				},
+			}}
+		controller := createFakeClientAndReconcile(mockClient, instance, testPaasToken, testAPIToken)
+
+		_, err := controller.Reconcile(context.TODO(), reconcile.Request{
+			NamespacedName: types.NamespacedName{Namespace: testNamespace, Name: testName},
+		})
+		require.NoError(t, err)
+
+		var dynakube dynatracev1beta1.DynaKube
+		require.NoError(t, controller.client.Get(context.TODO(), client.ObjectKey{Name: testName, Namespace: testNamespace}, &dynakube))
+
+		assert.Nil(t, meta.FindStatusCondition(dynakube.Status.Conditions, dynatracev1beta1.IstioConditionType))
+		assert.Nil(t, meta.FindStatusCondition(dynakube.Status.Conditions, dynatracev1beta1.OneAgentConditionType))
+	})
+	t.Run(`conditions are unknown if the components are not reconciled`, func(t *testing.T) {
+		mockClient := createDTMockClient(dtclient.TokenScopes{}, dtclient.TokenScopes{})
+		instance := &dynatracev1beta1.DynaKube{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      testName,
+				Namespace: testNamespace,
+			},
+			Spec: dynatracev1beta1.DynaKubeSpec{
+				OneAgent: dynatracev1beta1.OneAgentSpec{ClassicFullStack: &dynatracev1beta1.ClassicFullStackSpec{}},
+			},
+			Status: dynatracev1beta1.DynaKubeStatus{
+				Conditions: []metav1.Condition{
+					{Type: dynatracev1beta1.OneAgentConditionType, Status: metav1.ConditionTrue, Reason: dynatracev1beta1.ReasonReady},
+				},
+			}}
+		controller := createFakeClientAndReconcile(mockClient, instance, testPaasToken, testAPIToken)
+
+		_, err := controller.Reconcile(context.TODO(), reconcile.Request{
+			NamespacedName: types.NamespacedName{Namespace: testNamespace, Name: testName},
+		})
+		require.NoError(t, err)
+
+		var dynakube dynatracev1beta1.DynaKube
+		require.NoError(t, controller.client.Get(context.TODO(), client.ObjectKey{Name: testName, Namespace: testNamespace}, &dynakube))
+
+		oneAgentCondition := meta.FindStatusCondition(dynakube.Status.Conditions, dynatracev1beta1.OneAgentConditionType)
+		require.NotNil(t, oneAgentCondition)
+		assert.Equal(t, metav1.ConditionUnknown, oneAgentCondition.Status)
+		assert.Equal(t, dynatracev1beta1.ReasonNotReconciled, oneAgentCondition.Reason)
+	})
+}
+
+func TestReconcileAdditionalTenants(t *testing.T) {
+	const (
+		targetName       = "migration"
+		targetApiUrl     = "https://migration.live.dynatrace.com/api"
+		targetAPIToken   = "target-api-token"
+		targetTenantUUID = "target-uuid"
+	)
+	newController := func(instance *dynatracev1beta1.DynaKube, targetClient dtclient.Client) *DynakubeController {
+		controller := createFakeClientAndReconcile(createDTMockClient(dtclient.TokenScopes{}, dtclient.TokenScopes{}), instance, "", testAPIToken)
+		require.NoError(t, controller.client.Create(context.TODO(), &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: testName + "-" + targetName, Namespace: testNamespace},
+			Data:       map[string][]byte{dtclient.DynatraceApiToken: []byte(targetAPIToken)},
+		}))
+		controller.dtcBuildFunc = func(properties DynatraceClientProperties) (dtclient.Client, error) {
+			assert.Equal(t, targetApiUrl, properties.ApiUrl)
+			return targetClient, nil
+		}
+		return controller
+	}
+	newInstance := func() *dynatracev1beta1.DynaKube {
+		return &dynatracev1beta1.DynaKube{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      testName,
+				Namespace: testNamespace,
+			},
+			Spec: dynatracev1beta1.DynaKubeSpec{
+				APIURL:            "https://ENVIRONMENTID.live.dynatrace.com/api",
+				AdditionalTenants: []dynatracev1beta1.TenantTargetSpec{{Name: targetName, APIURL: targetApiUrl}},
+			},
+			Status: dynatracev1beta1.DynaKubeStatus{
+				AdditionalTenants: []dynatracev1beta1.TenantTargetStatus{{Name: "removed"}},
+			},
+		}
+	}
+
+	t.Run(`status is reconciled per tenant target`, func(t *testing.T) {
+		targetClient := &dtclient.MockDynatraceClient{}
+		targetClient.On("GetTokenScopes", targetAPIToken).
+			Return(dtclient.TokenScopes{dtclient.TokenScopeDataExport, dtclient.TokenScopeInstallerDownload}, nil)
+		targetClient.On("GetConnectionInfo").Return(dtclient.ConnectionInfo{TenantUUID: targetTenantUUID}, nil)
+		instance := newInstance()
+		controller := newController(instance, targetClient)
+		dkState := status.NewDynakubeState(instance)
+
+		controller.reconcileAdditionalTenants(context.TODO(), dkState)
+
+		assert.True(t, dkState.Updated)
+		require.Len(t, instance.Status.AdditionalTenants, 1)
+		targetStatus := instance.Status.AdditionalTenants[0]
+		assert.Equal(t, targetName, targetStatus.Name)
+		assert.Equal(t, targetTenantUUID, targetStatus.ConnectionInfo.TenantUUID)
+		AssertCondition(t, &dynatracev1beta1.DynaKube{Status: dynatracev1beta1.DynaKubeStatus{Conditions: targetStatus.Conditions}},
+			dynatracev1beta1.APITokenConditionType, true, dynatracev1beta1.ReasonTokenReady, "Ready")
+		mock.AssertExpectationsForObjects(t, targetClient)
+	})
+	t.Run(`invalid tokens of tenant target are tracked in its status`, func(t *testing.T) {
+		targetClient := &dtclient.MockDynatraceClient{}
+		targetClient.On("GetTokenScopes", targetAPIToken).Return(dtclient.TokenScopes{}, nil)
+		instance := newInstance()
+		controller := newController(instance, targetClient)
+		dkState := status.NewDynakubeState(instance)
+
+		controller.reconcileAdditionalTenants(context.TODO(), dkState)
+
+		require.Len(t, instance.Status.AdditionalTenants, 1)
+		targetStatus := instance.Status.AdditionalTenants[0]
+		assert.Empty(t, targetStatus.ConnectionInfo.TenantUUID)
+		AssertCondition(t, &dynatracev1beta1.DynaKube{Status: dynatracev1beta1.DynaKubeStatus{Conditions: targetStatus.Conditions}},
+			dynatracev1beta1.APITokenConditionType, false, dynatracev1beta1.ReasonTokenScopeMissing,
+			"Token on secret test-namespace:test-name-migration missing scopes [DataExport] for tenant connection, [InstallerDownload] for OneAgent download")
+	})
+	t.Run(`OneAgent and ActiveGate are deployed per tenant target`, func(t *testing.T) {
+		targetClient := &dtclient.MockDynatraceClient{}
+		targetClient.On("GetTokenScopes", targetAPIToken).
+			Return(dtclient.TokenScopes{dtclient.TokenScopeDataExport, dtclient.TokenScopeInstallerDownload}, nil)
+		targetClient.On("GetConnectionInfo").Return(dtclient.ConnectionInfo{TenantUUID: targetTenantUUID}, nil)
+		targetClient.On("GetActiveGateTenantInfo").Return(&dtclient.ActiveGateTenantInfo{
+			TenantInfo: dtclient.TenantInfo{UUID: targetTenantUUID, Token: "ag-token"},
+			Endpoints:  "https://migration.live.dynatrace.com/communication",
+		}, nil)
+		targetClient.On("GetAgentTenantInfo").Return(&dtclient.AgentTenantInfo{
+			TenantInfo: dtclient.TenantInfo{UUID: targetTenantUUID, Token: "agent-token"},
+			Endpoints:  []string{"https://a.migration.live.dynatrace.com", "https://b.migration.live.dynatrace.com"},
+		}, nil)
+		instance := newInstance()
+		instance.UID = testUID
+		instance.Annotations = map[string]string{dynatracev1beta1.AnnotationFeatureEnableMultipleOsAgentsOnNode: "true"}
+		instance.Spec.OneAgent.CloudNativeFullStack = &dynatracev1beta1.CloudNativeFullStackSpec{}
+		instance.Spec.ActiveGate.Capabilities = []dynatracev1beta1.CapabilityDisplayName{dynatracev1beta1.KubeMonCapability.DisplayName}
+		controller := newController(instance, targetClient)
+		dkState := status.NewDynakubeState(instance)
+
+		targetClients := controller.reconcileAdditionalTenants(context.TODO(), dkState)
+		require.Contains(t, targetClients, targetName)
+		for i := 0; i < 3; i++ {
+			controller.reconcileTenantTargetComponents(context.TODO(), dkState, targetClients)
+		}
+
+		var agSecret, oneAgentSecret corev1.Secret
+		require.NoError(t, controller.client.Get(context.TODO(), client.ObjectKey{Name: instance.AGTenantSecretForTarget(targetName), Namespace: testNamespace}, &agSecret))
+		assert.Equal(t, "ag-token", string(agSecret.Data[activegate.TenantTokenName]))
+		require.NoError(t, controller.client.Get(context.TODO(), client.ObjectKey{Name: instance.OneAgentTenantSecretForTarget(targetName), Namespace: testNamespace}, &oneAgentSecret))
+		assert.Equal(t, targetTenantUUID, string(oneAgentSecret.Data[daemonset.TenantUUIDKey]))
+		assert.Equal(t, "{https://a.migration.live.dynatrace.com;https://b.migration.live.dynatrace.com}", string(oneAgentSecret.Data[daemonset.CommunicationEndpointsKey]))
+
+		var ds appsv1.DaemonSet
+		require.NoError(t, controller.client.Get(context.TODO(), client.ObjectKey{Name: instance.OneAgentDaemonsetNameForTarget(targetName), Namespace: testNamespace}, &ds))
+		assert.Equal(t, targetName, ds.Spec.Template.Labels[dynatracev1beta1.LabelTenantTarget])
+		var sts appsv1.StatefulSet
+		require.NoError(t, controller.client.Get(context.TODO(), client.ObjectKey{Name: testName + "-" + targetName + "-activegate", Namespace: testNamespace}, &sts))
+		assert.Equal(t, targetName, sts.Spec.Template.Labels[dynatracev1beta1.LabelTenantTarget])
+
+		// the objects of a removed tenant target are deleted, the ones of the primary tenant are kept
+		instance.Spec.AdditionalTenants = nil
+		controller.reconcileTenantTargetComponents(context.TODO(), dkState, nil)
+
+		for _, obj := range []client.Object{&agSecret, &oneAgentSecret, &ds, &sts} {
+			err := controller.client.Get(context.TODO(), client.ObjectKeyFromObject(obj), obj)
+			assert.True(t, k8serrors.IsNotFound(err), obj.GetName())
+		}
+		var svc corev1.Service
+		err := controller.client.Get(context.TODO(), client.ObjectKey{Name: sts.Name, Namespace: testNamespace}, &svc)
+		assert.True(t, k8serrors.IsNotFound(err))
+		require.NoError(t, controller.client.Get(context.TODO(), client.ObjectKey{Name: testName + "-activegate", Namespace: testNamespace}, &sts))
+	})
+}
+
 func createDTMockClient(paasTokenScopes, apiTokenScopes dtclient.TokenScopes) *dtclient.MockDynatraceClient {
 	mockClient := &dtclient.MockDynatraceClient{}
 
@@ -463,6 +695,7 @@ func createFakeClientAndReconcile(mockClient dtclient.Client, instance *dynatrac
 		dtcBuildFunc: func(DynatraceClientProperties) (dtclient.Client, error) {
 			return mockClient, nil
 		},
+		recorder: record.NewFakeRecorder(10),
 	}
 
 	return controller
@@ -618,3 +851,189 @@ func generateStatefulSetForTesting(name, namespace, feature, kubeSystemUUID stri
 		},
 	}
 }
+
+func createDynakubeWithSettingsObject() *dynatracev1beta1.DynaKube {
+	return &dynatracev1beta1.DynaKube{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       testName,
+			Namespace:  testNamespace,
+			Finalizers: []string{settings.Finalizer},
+		},
+		Spec: dynatracev1beta1.DynaKubeSpec{
+			Settings: []dynatracev1beta1.SettingsObjectSpec{{Name: "log-storage", SchemaId: "builtin:logmonitoring.log-storage-settings", Scope: "HOST-0123456789ABCDEF"}},
+		},
+		Status: dynatracev1beta1.DynaKubeStatus{
+			Settings: dynatracev1beta1.SettingsStatus{
+				Objects: []dynatracev1beta1.SettingsObjectStatus{{Name: "log-storage", ObjectId: testObjectID}},
+			},
+		},
+	}
+}
+
+func TestEnsureSettingsFinalizer(t *testing.T) {
+	ctx := context.TODO()
+
+	t.Run(`finalizer is added for settings objects`, func(t *testing.T) {
+		instance := createDynakubeWithSettingsObject()
+		instance.Finalizers = nil
+		instance.Status.Settings.Objects = nil
+		controller := createFakeClientAndReconcile(&dtclient.MockDynatraceClient{}, instance, testPaasToken, testAPIToken)
+
+		require.NoError(t, controller.ensureSettingsFinalizer(ctx, instance))
+
+		var actual dynatracev1beta1.DynaKube
+		require.NoError(t, controller.client.Get(ctx, client.ObjectKeyFromObject(instance), &actual))
+		assert.Equal(t, []string{settings.Finalizer}, actual.Finalizers)
+	})
+	t.Run(`finalizer is kept until the objects are deleted`, func(t *testing.T) {
+		instance := createDynakubeWithSettingsObject()
+		instance.Spec.Settings = nil
+		controller := createFakeClientAndReconcile(&dtclient.MockDynatraceClient{}, instance, testPaasToken, testAPIToken)
+
+		require.NoError(t, controller.ensureSettingsFinalizer(ctx, instance))
+		assert.Equal(t, []string{settings.Finalizer}, instance.Finalizers)
+
+		instance.Status.Settings.Objects = nil
+		require.NoError(t, controller.ensureSettingsFinalizer(ctx, instance))
+
+		var actual dynatracev1beta1.DynaKube
+		require.NoError(t, controller.client.Get(ctx, client.ObjectKeyFromObject(instance), &actual))
+		assert.Empty(t, actual.Finalizers)
+	})
+}
+
+func TestFinalizeSettings(t *testing.T) {
+	ctx := context.TODO()
+	now := metav1.Now()
+
+	t.Run(`settings objects are deleted`, func(t *testing.T) {
+		mockClient := createDTMockClient(dtclient.TokenScopes{dtclient.TokenScopeInstallerDownload},
+			dtclient.TokenScopes{dtclient.TokenScopeDataExport, dtclient.TokenScopeSettingsRead, dtclient.TokenScopeSettingsWrite})
+		mockClient.On("DeleteSettingsObject", testObjectID).Return(nil)
+		instance := createDynakubeWithSettingsObject()
+		instance.DeletionTimestamp = &now
+		controller := createFakeClientAndReconcile(mockClient, instance, testPaasToken, testAPIToken)
+
+		_, err := controller.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: testNamespace, Name: testName}})
+		require.NoError(t, err)
+
+		// the DynaKube is removed once its last finalizer is removed
+		err = controller.client.Get(ctx, client.ObjectKeyFromObject(instance), &dynatracev1beta1.DynaKube{})
+		assert.True(t, k8serrors.IsNotFound(err))
+		mockClient.AssertCalled(t, "DeleteSettingsObject", testObjectID)
+	})
+	t.Run(`finalizer is kept if objects failed to be deleted`, func(t *testing.T) {
+		mockClient := createDTMockClient(dtclient.TokenScopes{dtclient.TokenScopeInstallerDownload},
+			dtclient.TokenScopes{dtclient.TokenScopeDataExport, dtclient.TokenScopeSettingsRead, dtclient.TokenScopeSettingsWrite})
+		mockClient.On("DeleteSettingsObject", testObjectID).Return(fmt.Errorf("unavailable"))
+		instance := createDynakubeWithSettingsObject()
+		instance.DeletionTimestamp = &now
+		controller := createFakeClientAndReconcile(mockClient, instance, testPaasToken, testAPIToken)
+
+		_, err := controller.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: testNamespace, Name: testName}})
+		require.Error(t, err)
+
+		var actual dynatracev1beta1.DynaKube
+		require.NoError(t, controller.client.Get(ctx, client.ObjectKeyFromObject(instance), &actual))
+		assert.Equal(t, []string{settings.Finalizer}, actual.Finalizers)
+		assert.Len(t, actual.Status.Settings.Objects, 1)
+	})
+	t.Run(`objects are left on the tenant if they can't be deleted until the timeout`, func(t *testing.T) {
+		mockClient := createDTMockClient(dtclient.TokenScopes{dtclient.TokenScopeInstallerDownload},
+			dtclient.TokenScopes{dtclient.TokenScopeDataExport, dtclient.TokenScopeSettingsRead, dtclient.TokenScopeSettingsWrite})
+		mockClient.On("DeleteSettingsObject", testObjectID).Return(fmt.Errorf("unavailable"))
+		instance := createDynakubeWithSettingsObject()
+		deleted := metav1.NewTime(now.Add(-settings.CleanupTimeout - time.Minute))
+		instance.DeletionTimestamp = &deleted
+		controller := createFakeClientAndReconcile(mockClient, instance, testPaasToken, testAPIToken)
+
+		_, err := controller.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: testNamespace, Name: testName}})
+		require.NoError(t, err)
+
+		err = controller.client.Get(ctx, client.ObjectKeyFromObject(instance), &dynatracev1beta1.DynaKube{})
+		assert.True(t, k8serrors.IsNotFound(err))
+		event := <-controller.recorder.(*record.FakeRecorder).Events
+		assert.Contains(t, event, settings.OrphanedSettingsEvent)
+		assert.Contains(t, event, testObjectID)
+	})
+	t.Run(`objects are left on the tenant without valid tokens`, func(t *testing.T) {
+		mockClient := createDTMockClient(dtclient.TokenScopes{}, dtclient.TokenScopes{})
+		instance := createDynakubeWithSettingsObject()
+		instance.DeletionTimestamp = &now
+		controller := createFakeClientAndReconcile(mockClient, instance, testPaasToken, testAPIToken)
+
+		_, err := controller.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: testNamespace, Name: testName}})
+		require.NoError(t, err)
+
+		// the DynaKube is removed once its last finalizer is removed
+		err = controller.client.Get(ctx, client.ObjectKeyFromObject(instance), &dynatracev1beta1.DynaKube{})
+		assert.True(t, k8serrors.IsNotFound(err))
+		mockClient.AssertNotCalled(t, "DeleteSettingsObject", mock.Anything)
+		assert.Contains(t, <-controller.recorder.(*record.FakeRecorder).Events, testObjectID)
+	})
+}
+
+func TestDynakubesForTokenSecret(t *testing.T) {
+	const targetTokens = "target-tokens"
+	controller := &DynakubeController{
+		client: fake.NewClient(
+			&dynatracev1beta1.DynaKube{ObjectMeta: metav1.ObjectMeta{Name: "default-tokens", Namespace: testNamespace}},
+			&dynatracev1beta1.DynaKube{
+				ObjectMeta: metav1.ObjectMeta{Name: "custom-tokens", Namespace: testNamespace},
+				Spec:       dynatracev1beta1.DynaKubeSpec{Tokens: "default-tokens"},
+			},
+			&dynatracev1beta1.DynaKube{
+				ObjectMeta: metav1.ObjectMeta{Name: "with-target", Namespace: testNamespace},
+				Spec: dynatracev1beta1.DynaKubeSpec{
+					AdditionalTenants: []dynatracev1beta1.TenantTargetSpec{{Name: "target", Tokens: targetTokens}},
+				},
+			},
+			&dynatracev1beta1.DynaKube{ObjectMeta: metav1.ObjectMeta{Name: "default-tokens", Namespace: "other-namespace"}},
+		),
+	}
+
+	t.Run(`tokens secret of the apiUrl tenant`, func(t *testing.T) {
+		requests := controller.dynakubesForTokenSecret(&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "default-tokens", Namespace: testNamespace}})
+		assert.ElementsMatch(t, []reconcile.Request{
+			{NamespacedName: types.NamespacedName{Name: "default-tokens", Namespace: testNamespace}},
+			{NamespacedName: types.NamespacedName{Name: "custom-tokens", Namespace: testNamespace}},
+		}, requests)
+	})
+	t.Run(`tokens secret of an additional tenant`, func(t *testing.T) {
+		requests := controller.dynakubesForTokenSecret(&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: targetTokens, Namespace: testNamespace}})
+		assert.Equal(t, []reconcile.Request{{NamespacedName: types.NamespacedName{Name: "with-target", Namespace: testNamespace}}}, requests)
+	})
+	t.Run(`other secret`, func(t *testing.T) {
+		requests := controller.dynakubesForTokenSecret(&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "dynakube-pull-secret", Namespace: testNamespace}})
+		assert.Empty(t, requests)
+	})
+}
+
+func TestSetTokensNotValidCondition(t *testing.T) {
+	t.Run(`changed tokens are not valid`, func(t *testing.T) {
+		dkState := status.NewDynakubeState(&dynatracev1beta1.DynaKube{})
+		meta.SetStatusCondition(&dkState.Instance.Status.Conditions, metav1.Condition{
+			Type:   dynatracev1beta1.TokensPropagatedConditionType,
+			Status: metav1.ConditionFalse,
+			Reason: dynatracev1beta1.ReasonRolloutInProgress,
+		})
+
+		setTokensNotValidCondition(dkState)
+
+		assert.True(t, dkState.Updated)
+		condition := meta.FindStatusCondition(dkState.Instance.Status.Conditions, dynatracev1beta1.TokensPropagatedConditionType)
+		assert.Equal(t, dynatracev1beta1.ReasonTokensNotValid, condition.Reason)
+	})
+	t.Run(`propagated tokens are kept`, func(t *testing.T) {
+		dkState := status.NewDynakubeState(&dynatracev1beta1.DynaKube{})
+		meta.SetStatusCondition(&dkState.Instance.Status.Conditions, metav1.Condition{
+			Type:   dynatracev1beta1.TokensPropagatedConditionType,
+			Status: metav1.ConditionTrue,
+			Reason: dynatracev1beta1.ReasonReady,
+		})
+
+		setTokensNotValidCondition(dkState)
+
+		assert.False(t, dkState.Updated)
+	})
+}
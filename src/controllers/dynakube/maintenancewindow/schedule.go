@@ -0,0 +1,126 @@
+package maintenancewindow
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+type scheduleField struct {
+	name     string
+	min, max int
+}
+
+var scheduleFields = [...]scheduleField{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12},
+	{name: "day of week", min: 0, max: 7},
+}
+
+// Schedule is a parsed cron expression with the fields minute, hour, day of month, month and day of week.
+// Each field is a comma separated list of values, ranges (1-5) and steps (*/15, 8-18/2); 0 and 7 are both Sunday.
+type Schedule struct {
+	minutes     uint64
+	hours       uint64
+	daysOfMonth uint64
+	months      uint64
+	daysOfWeek  uint64
+
+	// If both day fields are restricted, a day matches if either of them does, as with cron
+	daysOfMonthRestricted bool
+	daysOfWeekRestricted  bool
+}
+
+// ParseSchedule parses a cron expression.
+func ParseSchedule(expression string) (*Schedule, error) {
+	parts := strings.Fields(expression)
+	if len(parts) != len(scheduleFields) {
+		return nil, errors.Errorf("schedule '%s' has %d fields instead of %d", expression, len(parts), len(scheduleFields))
+	}
+
+	var values [len(scheduleFields)]uint64
+	for i, part := range parts {
+		fieldValues, err := parseScheduleField(part, scheduleFields[i])
+		if err != nil {
+			return nil, errors.WithMessagef(err, "invalid %s in schedule '%s'", scheduleFields[i].name, expression)
+		}
+		values[i] = fieldValues
+	}
+
+	daysOfWeek := values[4]
+	if daysOfWeek&(1<<7) != 0 {
+		daysOfWeek = daysOfWeek&^(1<<7) | 1
+	}
+
+	return &Schedule{
+		minutes:               values[0],
+		hours:                 values[1],
+		daysOfMonth:           values[2],
+		months:                values[3],
+		daysOfWeek:            daysOfWeek,
+		daysOfMonthRestricted: !strings.HasPrefix(parts[2], "*"),
+		daysOfWeekRestricted:  !strings.HasPrefix(parts[4], "*"),
+	}, nil
+}
+
+// Matches returns true if the schedule fires at the minute of the given time, in the location of the time.
+func (schedule *Schedule) Matches(t time.Time) bool {
+	if !has(schedule.minutes, t.Minute()) || !has(schedule.hours, t.Hour()) || !has(schedule.months, int(t.Month())) {
+		return false
+	}
+
+	dayOfMonth := has(schedule.daysOfMonth, t.Day())
+	dayOfWeek := has(schedule.daysOfWeek, int(t.Weekday()))
+	if schedule.daysOfMonthRestricted && schedule.daysOfWeekRestricted {
+		return dayOfMonth || dayOfWeek
+	}
+	return dayOfMonth && dayOfWeek
+}
+
+func parseScheduleField(value string, field scheduleField) (uint64, error) {
+	var values uint64
+	for _, item := range strings.Split(value, ",") {
+		valueRange, step := item, 1
+		if i := strings.Index(item, "/"); i >= 0 {
+			parsedStep, err := strconv.Atoi(item[i+1:])
+			if err != nil || parsedStep < 1 {
+				return 0, errors.Errorf("invalid step in '%s'", item)
+			}
+			valueRange, step = item[:i], parsedStep
+		}
+
+		from, to := field.min, field.max
+		if valueRange != "*" {
+			bounds := strings.SplitN(valueRange, "-", 2)
+			var err error
+			if from, err = strconv.Atoi(bounds[0]); err != nil {
+				return 0, errors.Errorf("invalid value in '%s'", item)
+			}
+
+			if len(bounds) == 2 {
+				if to, err = strconv.Atoi(bounds[1]); err != nil {
+					return 0, errors.Errorf("invalid value in '%s'", item)
+				}
+			} else if step == 1 {
+				to = from
+			}
+		}
+
+		if from < field.min || to > field.max || from > to {
+			return 0, errors.Errorf("'%s' is not within %d-%d", item, field.min, field.max)
+		}
+
+		for v := from; v <= to; v += step {
+			values |= 1 << uint(v)
+		}
+	}
+	return values, nil
+}
+
+func has(values uint64, value int) bool {
+	return values&(1<<uint(value)) != 0
+}
@@ -0,0 +1,70 @@
+package maintenancewindow
+
+import (
+	"time"
+	// The operator image has no time zone database
+	_ "time/tzdata"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	"github.com/pkg/errors"
+)
+
+// MaxDuration is the longest supported maintenance window.
+const MaxDuration = 7 * 24 * time.Hour
+
+// IsOpen returns true if automatic updates may be rolled out at the given time,
+// which is the case if no maintenance windows are configured or one of them is open.
+func IsOpen(windows []dynatracev1beta1.MaintenanceWindowSpec, now time.Time) (bool, error) {
+	if len(windows) == 0 {
+		return true, nil
+	}
+
+	for _, window := range windows {
+		open, err := isWindowOpen(window, now)
+		if err != nil {
+			return false, err
+		}
+		if open {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Validate returns an error if the schedule, duration or time zone of the maintenance window is invalid.
+func Validate(window dynatracev1beta1.MaintenanceWindowSpec) error {
+	_, _, err := parse(window)
+	return err
+}
+
+// isWindowOpen looks for a start of the window within its duration before the given time.
+func isWindowOpen(window dynatracev1beta1.MaintenanceWindowSpec, now time.Time) (bool, error) {
+	schedule, location, err := parse(window)
+	if err != nil {
+		return false, err
+	}
+
+	for start := now.In(location).Truncate(time.Minute); now.Sub(start) < window.Duration.Duration; start = start.Add(-time.Minute) {
+		if schedule.Matches(start) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func parse(window dynatracev1beta1.MaintenanceWindowSpec) (*Schedule, *time.Location, error) {
+	if window.Duration.Duration <= 0 || window.Duration.Duration > MaxDuration {
+		return nil, nil, errors.Errorf("duration '%s' of maintenance window is not within 0s-%s", window.Duration.Duration, MaxDuration)
+	}
+
+	schedule, err := ParseSchedule(window.Schedule)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	location, err := time.LoadLocation(window.TimeZone)
+	if err != nil {
+		return nil, nil, errors.Errorf("unknown time zone '%s' of maintenance window", window.TimeZone)
+	}
+	return schedule, location, nil
+}
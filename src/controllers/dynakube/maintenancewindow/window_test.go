@@ -0,0 +1,125 @@
+package maintenancewindow
+
+import (
+	"testing"
+	"time"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestParseSchedule(t *testing.T) {
+	t.Run(`valid schedules`, func(t *testing.T) {
+		for _, expression := range []string{
+			"* * * * *",
+			"0 22 * * 1-5",
+			"*/15 8-18/2 1,15 1-12 0,7",
+			"30 2 * * 7",
+			"5/10 * * * *",
+		} {
+			_, err := ParseSchedule(expression)
+			assert.NoError(t, err, expression)
+		}
+	})
+	t.Run(`invalid schedules`, func(t *testing.T) {
+		for _, expression := range []string{
+			"",
+			"* * * *",
+			"* * * * * *",
+			"60 * * * *",
+			"* 24 * * *",
+			"* * 0 * *",
+			"* * * 13 *",
+			"* * * * 8",
+			"5-1 * * * *",
+			"*/0 * * * *",
+			"a * * * *",
+		} {
+			_, err := ParseSchedule(expression)
+			assert.Error(t, err, expression)
+		}
+	})
+	t.Run(`matches`, func(t *testing.T) {
+		schedule, err := ParseSchedule("0 22 * * 1-5")
+		require.NoError(t, err)
+
+		assert.True(t, schedule.Matches(time.Date(2022, time.March, 7, 22, 0, 0, 0, time.UTC)), "monday")
+		assert.False(t, schedule.Matches(time.Date(2022, time.March, 7, 22, 1, 0, 0, time.UTC)))
+		assert.False(t, schedule.Matches(time.Date(2022, time.March, 6, 22, 0, 0, 0, time.UTC)), "sunday")
+	})
+	t.Run(`sunday as 7`, func(t *testing.T) {
+		schedule, err := ParseSchedule("0 0 * * 7")
+		require.NoError(t, err)
+
+		assert.True(t, schedule.Matches(time.Date(2022, time.March, 6, 0, 0, 0, 0, time.UTC)))
+	})
+	t.Run(`restricted day of month or day of week`, func(t *testing.T) {
+		schedule, err := ParseSchedule("0 0 1 * 1")
+		require.NoError(t, err)
+
+		assert.True(t, schedule.Matches(time.Date(2022, time.March, 1, 0, 0, 0, 0, time.UTC)), "first of month")
+		assert.True(t, schedule.Matches(time.Date(2022, time.March, 7, 0, 0, 0, 0, time.UTC)), "monday")
+		assert.False(t, schedule.Matches(time.Date(2022, time.March, 8, 0, 0, 0, 0, time.UTC)))
+	})
+}
+
+func TestIsOpen(t *testing.T) {
+	nightly := dynatracev1beta1.MaintenanceWindowSpec{
+		Schedule: "0 22 * * *",
+		Duration: metav1.Duration{Duration: 4 * time.Hour},
+		TimeZone: "Europe/Vienna",
+	}
+	vienna, err := time.LoadLocation("Europe/Vienna")
+	require.NoError(t, err)
+
+	t.Run(`open without windows`, func(t *testing.T) {
+		open, err := IsOpen(nil, time.Now())
+		require.NoError(t, err)
+		assert.True(t, open)
+	})
+	t.Run(`open within the window`, func(t *testing.T) {
+		for _, now := range []time.Time{
+			time.Date(2022, time.March, 7, 22, 0, 0, 0, vienna),
+			time.Date(2022, time.March, 8, 1, 59, 0, 0, vienna),
+			time.Date(2022, time.March, 7, 21, 30, 0, 0, time.UTC),
+		} {
+			open, err := IsOpen([]dynatracev1beta1.MaintenanceWindowSpec{nightly}, now)
+			require.NoError(t, err)
+			assert.True(t, open, now.String())
+		}
+	})
+	t.Run(`closed outside the window`, func(t *testing.T) {
+		for _, now := range []time.Time{
+			time.Date(2022, time.March, 7, 21, 59, 0, 0, vienna),
+			time.Date(2022, time.March, 8, 2, 0, 0, 0, vienna),
+			time.Date(2022, time.March, 8, 1, 0, 0, 0, time.UTC),
+		} {
+			open, err := IsOpen([]dynatracev1beta1.MaintenanceWindowSpec{nightly}, now)
+			require.NoError(t, err)
+			assert.False(t, open, now.String())
+		}
+	})
+	t.Run(`open if any window is open`, func(t *testing.T) {
+		weekend := dynatracev1beta1.MaintenanceWindowSpec{
+			Schedule: "0 0 * * 6",
+			Duration: metav1.Duration{Duration: 48 * time.Hour},
+		}
+		open, err := IsOpen([]dynatracev1beta1.MaintenanceWindowSpec{nightly, weekend}, time.Date(2022, time.March, 6, 12, 0, 0, 0, time.UTC))
+		require.NoError(t, err)
+		assert.True(t, open)
+	})
+	t.Run(`invalid windows`, func(t *testing.T) {
+		for _, window := range []dynatracev1beta1.MaintenanceWindowSpec{
+			{Schedule: "0 22 * *", Duration: metav1.Duration{Duration: time.Hour}},
+			{Schedule: "0 22 * * *"},
+			{Schedule: "0 22 * * *", Duration: metav1.Duration{Duration: MaxDuration + time.Hour}},
+			{Schedule: "0 22 * * *", Duration: metav1.Duration{Duration: time.Hour}, TimeZone: "Europe/Nowhere"},
+		} {
+			assert.Error(t, Validate(window), window.Schedule)
+			_, err := IsOpen([]dynatracev1beta1.MaintenanceWindowSpec{window}, time.Now())
+			assert.Error(t, err)
+		}
+	})
+}
@@ -0,0 +1,72 @@
+package networkpolicy
+
+import (
+	"context"
+	"net"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	kubernetesServiceName      = "kubernetes"
+	kubernetesServiceNamespace = "default"
+)
+
+// apiServerEgress allows egress to the kube-apiserver, to the ClusterIP of the kubernetes service and to its endpoints,
+// as depending on the network plugin the policy is evaluated before or after the service address is translated.
+func (r *Reconciler) apiServerEgress(ctx context.Context) ([]networkingv1.NetworkPolicyEgressRule, error) {
+	key := client.ObjectKey{Name: kubernetesServiceName, Namespace: kubernetesServiceNamespace}
+	var egress []networkingv1.NetworkPolicyEgressRule
+
+	service := &corev1.Service{}
+	if err := r.apiReader.Get(ctx, key, service); err != nil && !k8serrors.IsNotFound(err) {
+		return nil, err
+	} else if err == nil && net.ParseIP(service.Spec.ClusterIP) != nil {
+		ports := make([]networkingv1.NetworkPolicyPort, 0, len(service.Spec.Ports))
+		for _, port := range service.Spec.Ports {
+			ports = append(ports, buildPolicyPort(port.Protocol, port.Port))
+		}
+		egress = append(egress, buildIpEgressRule([]string{service.Spec.ClusterIP}, ports))
+	}
+
+	endpoints := &corev1.Endpoints{}
+	if err := r.apiReader.Get(ctx, key, endpoints); err != nil && !k8serrors.IsNotFound(err) {
+		return nil, err
+	}
+	for _, subset := range endpoints.Subsets {
+		ips := make([]string, 0, len(subset.Addresses))
+		for _, address := range subset.Addresses {
+			ips = append(ips, address.IP)
+		}
+		ports := make([]networkingv1.NetworkPolicyPort, 0, len(subset.Ports))
+		for _, port := range subset.Ports {
+			ports = append(ports, buildPolicyPort(port.Protocol, port.Port))
+		}
+		egress = append(egress, buildIpEgressRule(ips, ports))
+	}
+	return egress, nil
+}
+
+func buildPolicyPort(protocol corev1.Protocol, port int32) networkingv1.NetworkPolicyPort {
+	if protocol == "" {
+		protocol = corev1.ProtocolTCP
+	}
+	policyPort := intstr.FromInt(int(port))
+	return networkingv1.NetworkPolicyPort{Protocol: &protocol, Port: &policyPort}
+}
+
+func buildIpEgressRule(ips []string, ports []networkingv1.NetworkPolicyPort) networkingv1.NetworkPolicyEgressRule {
+	peers := make([]networkingv1.NetworkPolicyPeer, 0, len(ips))
+	for _, host := range ips {
+		if ip := net.ParseIP(host); ip != nil {
+			peers = append(peers, networkingv1.NetworkPolicyPeer{
+				IPBlock: &networkingv1.IPBlock{CIDR: buildCIDR(ip)},
+			})
+		}
+	}
+	return networkingv1.NetworkPolicyEgressRule{To: peers, Ports: ports}
+}
@@ -0,0 +1,148 @@
+package networkpolicy
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Dynatrace/dynatrace-operator/src/dtclient"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var (
+	// CiliumNetworkPolicyGVK => definition of the Cilium policy, which allows egress to hostnames via toFQDNs
+	CiliumNetworkPolicyGVK = schema.GroupVersionKind{
+		Group:   "cilium.io",
+		Version: "v2",
+		Kind:    "CiliumNetworkPolicy",
+	}
+
+	// CalicoNetworkPolicyGVK => definition of the Calico policy, which allows egress to hostnames via destination domains
+	CalicoNetworkPolicyGVK = schema.GroupVersionKind{
+		Group:   "projectcalico.org",
+		Version: "v3",
+		Kind:    "NetworkPolicy",
+	}
+
+	fqdnPolicyGVKs = []schema.GroupVersionKind{CiliumNetworkPolicyGVK, CalicoNetworkPolicyGVK}
+)
+
+func buildFqdnPolicy(gvk schema.GroupVersionKind, name string, instanceName string, target policyTarget, commHost dtclient.CommunicationHost) *unstructured.Unstructured {
+	policy := &unstructured.Unstructured{}
+	policy.SetGroupVersionKind(gvk)
+	objectMeta := buildObjectMeta(name, target.namespace, instanceName)
+	policy.SetName(objectMeta.Name)
+	policy.SetNamespace(objectMeta.Namespace)
+	policy.SetLabels(objectMeta.Labels)
+
+	if gvk == CiliumNetworkPolicyGVK {
+		policy.Object["spec"] = buildCiliumSpec(target, commHost)
+	} else {
+		policy.Object["spec"] = buildCalicoSpec(target, commHost)
+	}
+	return policy
+}
+
+// buildCiliumSpec allows egress to the hostname. Cilium learns the addresses of a hostname from the DNS responses
+// it proxies, so DNS requests to kube-dns have to pass the DNS proxy.
+func buildCiliumSpec(target policyTarget, commHost dtclient.CommunicationHost) map[string]interface{} {
+	return map[string]interface{}{
+		"endpointSelector": map[string]interface{}{
+			"matchLabels": toInterfaceMap(target.podSelector),
+		},
+		"egress": []interface{}{
+			map[string]interface{}{
+				"toFQDNs": []interface{}{
+					map[string]interface{}{"matchName": commHost.Host},
+				},
+				"toPorts": []interface{}{
+					map[string]interface{}{
+						"ports": []interface{}{
+							map[string]interface{}{"port": strconv.Itoa(int(commHost.Port)), "protocol": "TCP"},
+						},
+					},
+				},
+			},
+			map[string]interface{}{
+				"toEndpoints": []interface{}{
+					map[string]interface{}{
+						"matchLabels": map[string]interface{}{
+							"k8s:io.kubernetes.pod.namespace": "kube-system",
+							"k8s:k8s-app":                     "kube-dns",
+						},
+					},
+				},
+				"toPorts": []interface{}{
+					map[string]interface{}{
+						"ports": []interface{}{
+							map[string]interface{}{"port": strconv.Itoa(dnsPort), "protocol": "ANY"},
+						},
+						"rules": map[string]interface{}{
+							"dns": []interface{}{
+								map[string]interface{}{"matchPattern": "*"},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// buildCalicoSpec allows egress to the hostname and DNS to resolve it. Destination domains require Calico Enterprise or Calico Cloud.
+func buildCalicoSpec(target policyTarget, commHost dtclient.CommunicationHost) map[string]interface{} {
+	dnsRule := func(protocol string) map[string]interface{} {
+		return map[string]interface{}{
+			"action":   "Allow",
+			"protocol": protocol,
+			"destination": map[string]interface{}{
+				"ports": []interface{}{int64(dnsPort)},
+			},
+		}
+	}
+
+	return map[string]interface{}{
+		"selector": buildCalicoSelector(target.podSelector),
+		"types":    []interface{}{"Egress"},
+		"egress": []interface{}{
+			map[string]interface{}{
+				"action":   "Allow",
+				"protocol": "TCP",
+				"destination": map[string]interface{}{
+					"domains": []interface{}{commHost.Host},
+					"ports":   []interface{}{int64(commHost.Port)},
+				},
+			},
+			dnsRule("UDP"),
+			dnsRule("TCP"),
+		},
+	}
+}
+
+func buildCalicoSelector(podSelector map[string]string) string {
+	if len(podSelector) == 0 {
+		return "all()"
+	}
+
+	keys := make([]string, 0, len(podSelector))
+	for key := range podSelector {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	expressions := make([]string, 0, len(keys))
+	for _, key := range keys {
+		expressions = append(expressions, fmt.Sprintf("%s == '%s'", key, podSelector[key]))
+	}
+	return strings.Join(expressions, " && ")
+}
+
+func toInterfaceMap(labels map[string]string) map[string]interface{} {
+	result := make(map[string]interface{}, len(labels))
+	for key, value := range labels {
+		result[key] = value
+	}
+	return result
+}
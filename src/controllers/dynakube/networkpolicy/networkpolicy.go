@@ -0,0 +1,114 @@
+package networkpolicy
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net"
+
+	"github.com/Dynatrace/dynatrace-operator/src/dtclient"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+const (
+	labelComponent         = "dynatrace.com/component"
+	labelInstance          = "operator.dynatrace.com/instance"
+	componentNetworkPolicy = "network-policy"
+
+	dnsPort = 53
+
+	clusterEgressSuffix = "-cluster-egress"
+)
+
+// policyTarget are the pods a policy is created for.
+type policyTarget struct {
+	namespace   string
+	podSelector map[string]string
+}
+
+// buildNameForEndpoint returns the name of the policies of an endpoint, which is the same in every namespace.
+func buildNameForEndpoint(name string, commHost dtclient.CommunicationHost) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s-%s-%s-%d", name, commHost.Protocol, commHost.Host, commHost.Port)))
+	return hex.EncodeToString(sum[:])
+}
+
+func buildLabels(name string) map[string]string {
+	return map[string]string{
+		labelComponent: componentNetworkPolicy,
+		labelInstance:  name,
+	}
+}
+
+func buildObjectMeta(name string, namespace string, instanceName string) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Name:      name,
+		Namespace: namespace,
+		Labels:    buildLabels(instanceName),
+	}
+}
+
+// buildNetworkPolicy allows egress to the endpoint. Plain NetworkPolicies can't match hostnames,
+// so for a hostname the port is allowed to any destination, together with DNS to resolve the hostname.
+func buildNetworkPolicy(name string, instanceName string, target policyTarget, commHost dtclient.CommunicationHost) *networkingv1.NetworkPolicy {
+	tcp := corev1.ProtocolTCP
+	udp := corev1.ProtocolUDP
+	endpointPort := intstr.FromInt(int(commHost.Port))
+	dns := intstr.FromInt(dnsPort)
+
+	egress := []networkingv1.NetworkPolicyEgressRule{{
+		Ports: []networkingv1.NetworkPolicyPort{{Protocol: &tcp, Port: &endpointPort}},
+	}}
+	if ip := net.ParseIP(commHost.Host); ip != nil {
+		egress[0].To = []networkingv1.NetworkPolicyPeer{{
+			IPBlock: &networkingv1.IPBlock{CIDR: buildCIDR(ip)},
+		}}
+	} else {
+		egress = append(egress, networkingv1.NetworkPolicyEgressRule{
+			Ports: []networkingv1.NetworkPolicyPort{
+				{Protocol: &udp, Port: &dns},
+				{Protocol: &tcp, Port: &dns},
+			},
+		})
+	}
+
+	return &networkingv1.NetworkPolicy{
+		ObjectMeta: buildObjectMeta(name, target.namespace, instanceName),
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{MatchLabels: target.podSelector},
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeEgress},
+			Egress:      egress,
+		},
+	}
+}
+
+// buildClusterEgressPolicy allows egress to the pods of the cluster and to the kube-apiserver. The ActiveGate needs both
+// for Kubernetes monitoring and the routing of OneAgent traffic, which the endpoint policies would deny once the pods
+// of the DynaKube are isolated for egress.
+func buildClusterEgressPolicy(instanceName string, target policyTarget, apiServerEgress []networkingv1.NetworkPolicyEgressRule) *networkingv1.NetworkPolicy {
+	egress := append([]networkingv1.NetworkPolicyEgressRule{{
+		To: []networkingv1.NetworkPolicyPeer{{NamespaceSelector: &metav1.LabelSelector{}}},
+	}}, apiServerEgress...)
+
+	return &networkingv1.NetworkPolicy{
+		ObjectMeta: buildObjectMeta(instanceName+clusterEgressSuffix, target.namespace, instanceName),
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{MatchLabels: target.podSelector},
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeEgress},
+			Egress:      egress,
+		},
+	}
+}
+
+func buildCIDR(ip net.IP) string {
+	if ip.To4() != nil {
+		return ip.String() + "/32"
+	}
+	return ip.String() + "/128"
+}
+
+func isIp(host string) bool {
+	return net.ParseIP(host) != nil
+}
@@ -0,0 +1,110 @@
+package networkpolicy
+
+import (
+	"testing"
+
+	"github.com/Dynatrace/dynatrace-operator/src/dtclient"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	testName         = "test-name"
+	testInstanceName = "dynakube"
+	testNamespace    = "dynatrace"
+)
+
+var (
+	testHostnameEndpoint = dtclient.CommunicationHost{Protocol: "https", Host: "abc.live.dynatrace.com", Port: 443}
+	testIpEndpoint       = dtclient.CommunicationHost{Protocol: "https", Host: "42.42.42.42", Port: 9999}
+	testAllPods          = policyTarget{namespace: testNamespace, podSelector: map[string]string{}}
+)
+
+func TestBuildNameForEndpoint(t *testing.T) {
+	name := buildNameForEndpoint(testInstanceName, testHostnameEndpoint)
+	assert.Equal(t, name, buildNameForEndpoint(testInstanceName, testHostnameEndpoint))
+	assert.NotEqual(t, name, buildNameForEndpoint(testInstanceName, testIpEndpoint))
+	assert.NotEqual(t, name, buildNameForEndpoint("other", testHostnameEndpoint))
+}
+
+func TestBuildNetworkPolicy(t *testing.T) {
+	t.Run(`generate with Ip`, func(t *testing.T) {
+		policy := buildNetworkPolicy(testName, testInstanceName, testAllPods, testIpEndpoint)
+
+		assert.Equal(t, testName, policy.Name)
+		assert.Equal(t, testNamespace, policy.Namespace)
+		assert.Equal(t, buildLabels(testInstanceName), policy.Labels)
+		assert.Empty(t, policy.Spec.PodSelector.MatchLabels)
+
+		require.Len(t, policy.Spec.Egress, 1)
+		require.Len(t, policy.Spec.Egress[0].To, 1)
+		assert.Equal(t, "42.42.42.42/32", policy.Spec.Egress[0].To[0].IPBlock.CIDR)
+		require.Len(t, policy.Spec.Egress[0].Ports, 1)
+		assert.Equal(t, 9999, policy.Spec.Egress[0].Ports[0].Port.IntValue())
+		assert.Equal(t, corev1.ProtocolTCP, *policy.Spec.Egress[0].Ports[0].Protocol)
+	})
+	t.Run(`generate with hostname`, func(t *testing.T) {
+		target := policyTarget{namespace: testNamespace, podSelector: map[string]string{labelInstance: testInstanceName}}
+		policy := buildNetworkPolicy(testName, testInstanceName, target, testHostnameEndpoint)
+
+		assert.Equal(t, target.podSelector, policy.Spec.PodSelector.MatchLabels)
+		require.Len(t, policy.Spec.Egress, 2)
+		assert.Empty(t, policy.Spec.Egress[0].To)
+		assert.Equal(t, 443, policy.Spec.Egress[0].Ports[0].Port.IntValue())
+
+		require.Len(t, policy.Spec.Egress[1].Ports, 2)
+		assert.Equal(t, dnsPort, policy.Spec.Egress[1].Ports[0].Port.IntValue())
+		assert.Equal(t, corev1.ProtocolUDP, *policy.Spec.Egress[1].Ports[0].Protocol)
+	})
+	t.Run(`generate with Ipv6`, func(t *testing.T) {
+		endpoint := dtclient.CommunicationHost{Protocol: "https", Host: "2001:db8::1", Port: 443}
+		policy := buildNetworkPolicy(testName, testInstanceName, testAllPods, endpoint)
+
+		assert.Equal(t, "2001:db8::1/128", policy.Spec.Egress[0].To[0].IPBlock.CIDR)
+	})
+}
+
+func TestBuildFqdnPolicy(t *testing.T) {
+	t.Run(`generate cilium policy`, func(t *testing.T) {
+		policy := buildFqdnPolicy(CiliumNetworkPolicyGVK, testName, testInstanceName, testAllPods, testHostnameEndpoint)
+
+		assert.Equal(t, CiliumNetworkPolicyGVK, policy.GroupVersionKind())
+		assert.Equal(t, testName, policy.GetName())
+		assert.Equal(t, testNamespace, policy.GetNamespace())
+		assert.Equal(t, buildLabels(testInstanceName), policy.GetLabels())
+
+		egress, found, err := unstructured.NestedSlice(policy.Object, "spec", "egress")
+		require.NoError(t, err)
+		require.True(t, found)
+		require.Len(t, egress, 2)
+
+		fqdns, _, err := unstructured.NestedSlice(egress[0].(map[string]interface{}), "toFQDNs")
+		require.NoError(t, err)
+		assert.Equal(t, []interface{}{map[string]interface{}{"matchName": testHostnameEndpoint.Host}}, fqdns)
+	})
+	t.Run(`generate calico policy`, func(t *testing.T) {
+		target := policyTarget{namespace: testNamespace, podSelector: map[string]string{labelInstance: testInstanceName}}
+		policy := buildFqdnPolicy(CalicoNetworkPolicyGVK, testName, testInstanceName, target, testHostnameEndpoint)
+
+		assert.Equal(t, CalicoNetworkPolicyGVK, policy.GroupVersionKind())
+
+		selector, _, err := unstructured.NestedString(policy.Object, "spec", "selector")
+		require.NoError(t, err)
+		assert.Equal(t, "operator.dynatrace.com/instance == 'dynakube'", selector)
+
+		egress, _, err := unstructured.NestedSlice(policy.Object, "spec", "egress")
+		require.NoError(t, err)
+		require.Len(t, egress, 3)
+
+		domains, _, err := unstructured.NestedStringSlice(egress[0].(map[string]interface{}), "destination", "domains")
+		require.NoError(t, err)
+		assert.Equal(t, []string{testHostnameEndpoint.Host}, domains)
+	})
+}
+
+func TestBuildCalicoSelector(t *testing.T) {
+	assert.Equal(t, "all()", buildCalicoSelector(map[string]string{}))
+	assert.Equal(t, "a == '1' && b == '2'", buildCalicoSelector(map[string]string{"b": "2", "a": "1"}))
+}
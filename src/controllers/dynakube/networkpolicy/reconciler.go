@@ -0,0 +1,341 @@
+package networkpolicy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	"github.com/Dynatrace/dynatrace-operator/src/dtclient"
+	"github.com/Dynatrace/dynatrace-operator/src/kubeobjects"
+	"github.com/Dynatrace/dynatrace-operator/src/mapper"
+	"github.com/Dynatrace/dynatrace-operator/src/webhook"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+var networkPolicyGVK = networkingv1.SchemeGroupVersion.WithKind("NetworkPolicy")
+
+// Reconciler creates egress policies for the communication endpoints of the Dynatrace environment
+// and removes the policies of endpoints which are no longer used.
+// Policies isolate the selected pods for egress, so in injected namespaces they are only created if the namespace
+// is labeled to have default-deny egress policies, and then only for the pods with OneAgent injected.
+// The same label on the namespace of the DynaKube opts in to policies for its OneAgent and ActiveGate pods,
+// which then also get egress to the cluster and the kube-apiserver.
+type Reconciler struct {
+	client    client.Client
+	apiReader client.Reader
+	scheme    *runtime.Scheme
+
+	isKindAvailable func(gvk schema.GroupVersionKind, namespace string) bool
+}
+
+func NewReconciler(clt client.Client, apiReader client.Reader, scheme *runtime.Scheme, config *rest.Config) *Reconciler {
+	return &Reconciler{
+		client:    clt,
+		apiReader: apiReader,
+		scheme:    scheme,
+		isKindAvailable: func(gvk schema.GroupVersionKind, namespace string) bool {
+			probe, _ := kubeobjects.KubernetesObjectProbe(gvk, namespace, "", config)
+			return probe != kubeobjects.ProbeTypeNotFound && probe != kubeobjects.ProbeUnknown
+		},
+	}
+}
+
+func (r *Reconciler) Reconcile(ctx context.Context, instance *dynatracev1beta1.DynaKube) (bool, error) {
+	targets, err := r.buildTargets(ctx, instance)
+	if err != nil {
+		return false, fmt.Errorf("networkpolicy: failed to list injected namespaces: %w", err)
+	}
+
+	componentTarget, err := r.buildComponentTarget(ctx, instance)
+	if err != nil {
+		return false, fmt.Errorf("networkpolicy: failed to get namespace %s: %w", instance.GetNamespace(), err)
+	}
+	if componentTarget != nil {
+		targets = append(targets, *componentTarget)
+	}
+
+	fqdnKinds := r.availableFqdnKinds(instance.GetNamespace())
+	desired := map[string]bool{}
+	updated := false
+
+	for _, commHost := range communicationHosts(instance) {
+		name := buildNameForEndpoint(instance.GetName(), commHost)
+		for _, target := range targets {
+			policy := r.buildPolicy(name, instance.GetName(), target, commHost, fqdnKinds)
+			desired[objectKey(policy.GetObjectKind().GroupVersionKind(), policy.GetNamespace(), policy.GetName())] = true
+
+			changed, err := r.createOrUpdate(ctx, instance, policy)
+			if err != nil {
+				return false, fmt.Errorf("networkpolicy: failed to create policy for %s in namespace %s: %w", commHost.Host, target.namespace, err)
+			}
+			updated = updated || changed
+		}
+	}
+
+	if componentTarget != nil {
+		changed, err := r.reconcileClusterEgress(ctx, instance, *componentTarget, desired)
+		if err != nil {
+			return false, fmt.Errorf("networkpolicy: failed to create cluster egress policy in namespace %s: %w", componentTarget.namespace, err)
+		}
+		updated = updated || changed
+	}
+
+	removed, err := r.removeStale(ctx, instance.GetName(), desired, fqdnKinds)
+	if err != nil {
+		return false, fmt.Errorf("networkpolicy: failed to remove outdated policies: %w", err)
+	}
+
+	return updated || removed, nil
+}
+
+// Cleanup removes all policies of the DynaKube, after the policies were disabled or the DynaKube was deleted.
+// Policies in other namespaces than the one of the DynaKube can't be owned by it, so they are not garbage collected.
+func (r *Reconciler) Cleanup(ctx context.Context, instanceName string, namespace string) (bool, error) {
+	removed, err := r.removeStale(ctx, instanceName, map[string]bool{}, r.availableFqdnKinds(namespace))
+	if err != nil {
+		return false, fmt.Errorf("networkpolicy: failed to remove policies: %w", err)
+	}
+	return removed, nil
+}
+
+// buildTargets returns the pods with OneAgent injected of the injected namespaces which have default-deny egress policies.
+func (r *Reconciler) buildTargets(ctx context.Context, instance *dynatracev1beta1.DynaKube) ([]policyTarget, error) {
+	namespaces, err := mapper.GetNamespacesForDynakube(ctx, r.apiReader, instance.GetName())
+	if err != nil {
+		return nil, err
+	}
+
+	targets := make([]policyTarget, 0, len(namespaces))
+	for _, namespace := range namespaces {
+		if namespace.Name == instance.GetNamespace() || namespace.Labels[webhook.LabelNetworkPolicyDefaultDeny] != "true" {
+			continue
+		}
+		targets = append(targets, policyTarget{
+			namespace:   namespace.Name,
+			podSelector: map[string]string{webhook.LabelOneAgentInjected: "true"},
+		})
+	}
+
+	return targets, nil
+}
+
+// buildComponentTarget returns the OneAgent and ActiveGate pods of the DynaKube, if its namespace has default-deny egress policies.
+// Otherwise the pods are not isolated for egress, as they need more than the communication endpoints.
+func (r *Reconciler) buildComponentTarget(ctx context.Context, instance *dynatracev1beta1.DynaKube) (*policyTarget, error) {
+	namespace := &corev1.Namespace{}
+	err := r.apiReader.Get(ctx, client.ObjectKey{Name: instance.GetNamespace()}, namespace)
+	if k8serrors.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	if namespace.Labels[webhook.LabelNetworkPolicyDefaultDeny] != "true" {
+		return nil, nil
+	}
+	return &policyTarget{
+		namespace:   instance.GetNamespace(),
+		podSelector: map[string]string{labelInstance: instance.GetName()},
+	}, nil
+}
+
+// reconcileClusterEgress creates the policy which allows the pods of the DynaKube egress to the cluster and the kube-apiserver.
+func (r *Reconciler) reconcileClusterEgress(ctx context.Context, instance *dynatracev1beta1.DynaKube, target policyTarget, desired map[string]bool) (bool, error) {
+	apiServerEgress, err := r.apiServerEgress(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	policy := buildClusterEgressPolicy(instance.GetName(), target, apiServerEgress)
+	policy.SetGroupVersionKind(networkPolicyGVK)
+	desired[objectKey(networkPolicyGVK, policy.GetNamespace(), policy.GetName())] = true
+	return r.createOrUpdate(ctx, instance, policy)
+}
+
+// availableFqdnKinds returns the FQDN policy kinds which are installed on the cluster, the first one is used to create policies.
+func (r *Reconciler) availableFqdnKinds(namespace string) []schema.GroupVersionKind {
+	var available []schema.GroupVersionKind
+	for _, gvk := range fqdnPolicyGVKs {
+		if r.isKindAvailable(gvk, namespace) {
+			available = append(available, gvk)
+		}
+	}
+	return available
+}
+
+// buildPolicy uses a plain NetworkPolicy for IPs, and for hostnames if no FQDN policy kind is available.
+func (r *Reconciler) buildPolicy(name string, instanceName string, target policyTarget, commHost dtclient.CommunicationHost, fqdnKinds []schema.GroupVersionKind) client.Object {
+	if len(fqdnKinds) > 0 && !isIp(commHost.Host) {
+		return buildFqdnPolicy(fqdnKinds[0], name, instanceName, target, commHost)
+	}
+
+	policy := buildNetworkPolicy(name, instanceName, target, commHost)
+	policy.SetGroupVersionKind(networkPolicyGVK)
+	return policy
+}
+
+// createOrUpdate creates the policy, or updates it if it was changed since it was created.
+func (r *Reconciler) createOrUpdate(ctx context.Context, instance *dynatracev1beta1.DynaKube, policy client.Object) (bool, error) {
+	// policies in other namespaces can't be owned by the DynaKube
+	if policy.GetNamespace() == instance.GetNamespace() {
+		if err := controllerutil.SetControllerReference(instance, policy, r.scheme); err != nil {
+			return false, err
+		}
+	}
+
+	kind := policy.GetObjectKind().GroupVersionKind().Kind
+	existing := policy.DeepCopyObject().(client.Object)
+	err := r.apiReader.Get(ctx, client.ObjectKey{Namespace: policy.GetNamespace(), Name: policy.GetName()}, existing)
+	if k8serrors.IsNotFound(err) {
+		log.Info("creating policy", "kind", kind, "namespace", policy.GetNamespace(), "name", policy.GetName())
+		return true, r.client.Create(ctx, policy)
+	} else if err != nil {
+		return false, err
+	}
+
+	if !isDrifted(existing, policy) {
+		return false, nil
+	}
+
+	log.Info("updating policy", "kind", kind, "namespace", policy.GetNamespace(), "name", policy.GetName())
+	policy.SetResourceVersion(existing.GetResourceVersion())
+	return true, r.client.Update(ctx, policy)
+}
+
+// removeStale deletes the policies of the DynaKube, which are not part of the desired policies anymore.
+// This covers endpoints which disappeared, namespaces which are not injected anymore and policies of a previously used kind.
+func (r *Reconciler) removeStale(ctx context.Context, instanceName string, desired map[string]bool, fqdnKinds []schema.GroupVersionKind) (bool, error) {
+	matchingLabels := client.MatchingLabels(buildLabels(instanceName))
+	var existing []client.Object
+
+	networkPolicies := &networkingv1.NetworkPolicyList{}
+	if err := r.apiReader.List(ctx, networkPolicies, matchingLabels); err != nil {
+		return false, err
+	}
+	for i := range networkPolicies.Items {
+		policy := &networkPolicies.Items[i]
+		policy.SetGroupVersionKind(networkPolicyGVK)
+		existing = append(existing, policy)
+	}
+
+	for _, gvk := range fqdnKinds {
+		fqdnPolicies := &unstructured.UnstructuredList{}
+		fqdnPolicies.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+		if err := r.apiReader.List(ctx, fqdnPolicies, matchingLabels); err != nil {
+			return false, err
+		}
+		for i := range fqdnPolicies.Items {
+			policy := &fqdnPolicies.Items[i]
+			policy.SetGroupVersionKind(gvk)
+			existing = append(existing, policy)
+		}
+	}
+
+	removed := false
+	for _, policy := range existing {
+		gvk := policy.GetObjectKind().GroupVersionKind()
+		if desired[objectKey(gvk, policy.GetNamespace(), policy.GetName())] {
+			continue
+		}
+
+		log.Info("removing policy", "kind", gvk.Kind, "namespace", policy.GetNamespace(), "name", policy.GetName())
+		if err := r.client.Delete(ctx, policy); err != nil && !k8serrors.IsNotFound(err) {
+			return false, err
+		}
+		removed = true
+	}
+	return removed, nil
+}
+
+// communicationHosts returns the API host and the communication endpoints without duplicates.
+func communicationHosts(instance *dynatracev1beta1.DynaKube) []dtclient.CommunicationHost {
+	commHosts := append([]dtclient.CommunicationHost{instance.CommunicationHostForClient()}, instance.ConnectionInfo().CommunicationHosts...)
+
+	seen := map[string]bool{}
+	result := make([]dtclient.CommunicationHost, 0, len(commHosts))
+	for _, commHost := range commHosts {
+		name := buildNameForEndpoint(instance.GetName(), commHost)
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		result = append(result, commHost)
+	}
+	return result
+}
+
+// isDrifted returns true if the labels or the spec of the existing policy differ from the desired policy.
+// Fields of FQDN policies which are not set by the operator, e.g. defaults of the policy engine, are not compared.
+func isDrifted(existing client.Object, desired client.Object) bool {
+	for key, value := range desired.GetLabels() {
+		if existing.GetLabels()[key] != value {
+			return true
+		}
+	}
+
+	switch desiredPolicy := desired.(type) {
+	case *networkingv1.NetworkPolicy:
+		return !equality.Semantic.DeepEqual(existing.(*networkingv1.NetworkPolicy).Spec, desiredPolicy.Spec)
+	case *unstructured.Unstructured:
+		return !containsSpec(existing.(*unstructured.Unstructured).Object["spec"], desiredPolicy.Object["spec"])
+	}
+	return false
+}
+
+// containsSpec compares the specs in their JSON form, as numbers are read as int64 or float64 from the API server.
+func containsSpec(existing interface{}, desired interface{}) bool {
+	var existingSpec, desiredSpec interface{}
+	if !normalize(existing, &existingSpec) || !normalize(desired, &desiredSpec) {
+		return false
+	}
+	return contains(existingSpec, desiredSpec)
+}
+
+func normalize(value interface{}, normalized *interface{}) bool {
+	raw, err := json.Marshal(value)
+	return err == nil && json.Unmarshal(raw, normalized) == nil
+}
+
+func contains(existing interface{}, desired interface{}) bool {
+	switch desiredValue := desired.(type) {
+	case map[string]interface{}:
+		existingValue, ok := existing.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		for key, value := range desiredValue {
+			if !contains(existingValue[key], value) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		existingValue, ok := existing.([]interface{})
+		if !ok || len(existingValue) != len(desiredValue) {
+			return false
+		}
+		for i := range desiredValue {
+			if !contains(existingValue[i], desiredValue[i]) {
+				return false
+			}
+		}
+		return true
+	default:
+		return reflect.DeepEqual(existing, desired)
+	}
+}
+
+func objectKey(gvk schema.GroupVersionKind, namespace string, name string) string {
+	return fmt.Sprintf("%s/%s/%s", gvk.GroupKind(), namespace, name)
+}
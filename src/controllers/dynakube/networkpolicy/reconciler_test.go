@@ -0,0 +1,284 @@
+package networkpolicy
+
+import (
+	"context"
+	"testing"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/activegate/reconciler/statefulset"
+	"github.com/Dynatrace/dynatrace-operator/src/mapper"
+	"github.com/Dynatrace/dynatrace-operator/src/scheme"
+	"github.com/Dynatrace/dynatrace-operator/src/scheme/fake"
+	"github.com/Dynatrace/dynatrace-operator/src/webhook"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const testInjectedNamespace = "injected"
+
+func createTestDynakube(commHosts ...dynatracev1beta1.CommunicationHostStatus) *dynatracev1beta1.DynaKube {
+	return &dynatracev1beta1.DynaKube{
+		ObjectMeta: metav1.ObjectMeta{Name: testInstanceName, Namespace: testNamespace},
+		Status: dynatracev1beta1.DynaKubeStatus{
+			CommunicationHostForClient: dynatracev1beta1.CommunicationHostStatus(testHostnameEndpoint),
+			ConnectionInfo: dynatracev1beta1.ConnectionInfoStatus{
+				CommunicationHosts: commHosts,
+			},
+		},
+	}
+}
+
+func createTestReconciler(clt client.Client, availableKinds ...schema.GroupVersionKind) *Reconciler {
+	reconciler := NewReconciler(clt, clt, scheme.Scheme, nil)
+	reconciler.isKindAvailable = func(gvk schema.GroupVersionKind, _ string) bool {
+		for _, available := range availableKinds {
+			if available == gvk {
+				return true
+			}
+		}
+		return false
+	}
+	return reconciler
+}
+
+func createInjectedNamespace(name string) *corev1.Namespace {
+	return &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+			Labels: map[string]string{
+				mapper.InstanceLabel:                  testInstanceName,
+				webhook.LabelNetworkPolicyDefaultDeny: "true",
+			},
+		},
+	}
+}
+
+// createDynakubeNamespace returns the namespace of the DynaKube, which opts in to policies for the DynaKube pods by default-deny.
+func createDynakubeNamespace() *corev1.Namespace {
+	return &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   testNamespace,
+			Labels: map[string]string{webhook.LabelNetworkPolicyDefaultDeny: "true"},
+		},
+	}
+}
+
+func listNetworkPolicies(t *testing.T, clt client.Client) []networkingv1.NetworkPolicy {
+	policies := &networkingv1.NetworkPolicyList{}
+	require.NoError(t, clt.List(context.TODO(), policies))
+	return policies.Items
+}
+
+func TestReconcile(t *testing.T) {
+	ctx := context.TODO()
+
+	t.Run(`create policies for injected namespaces and dynakube pods`, func(t *testing.T) {
+		clt := fake.NewClient(createInjectedNamespace(testInjectedNamespace), createDynakubeNamespace())
+		instance := createTestDynakube(dynatracev1beta1.CommunicationHostStatus(testIpEndpoint), dynatracev1beta1.CommunicationHostStatus(testHostnameEndpoint))
+
+		updated, err := createTestReconciler(clt).Reconcile(ctx, instance)
+		require.NoError(t, err)
+		assert.True(t, updated)
+
+		// the duplicated hostname endpoint results in a single policy per namespace, the dynakube pods get the cluster egress policy
+		policies := listNetworkPolicies(t, clt)
+		require.Len(t, policies, 5)
+		for _, policy := range policies {
+			if policy.Namespace == testNamespace {
+				assert.Equal(t, map[string]string{labelInstance: testInstanceName}, policy.Spec.PodSelector.MatchLabels)
+				require.Len(t, policy.OwnerReferences, 1)
+				assert.Equal(t, testInstanceName, policy.OwnerReferences[0].Name)
+			} else {
+				assert.Equal(t, testInjectedNamespace, policy.Namespace)
+				assert.Equal(t, map[string]string{webhook.LabelOneAgentInjected: "true"}, policy.Spec.PodSelector.MatchLabels)
+				assert.Empty(t, policy.OwnerReferences)
+			}
+		}
+
+		updated, err = createTestReconciler(clt).Reconcile(ctx, instance)
+		require.NoError(t, err)
+		assert.False(t, updated)
+	})
+	t.Run(`injected dynakube namespace only gets a policy for dynakube pods`, func(t *testing.T) {
+		clt := fake.NewClient(createInjectedNamespace(testNamespace))
+
+		_, err := createTestReconciler(clt).Reconcile(ctx, createTestDynakube())
+		require.NoError(t, err)
+
+		policies := listNetworkPolicies(t, clt)
+		require.Len(t, policies, 2)
+		for _, policy := range policies {
+			assert.Equal(t, map[string]string{labelInstance: testInstanceName}, policy.Spec.PodSelector.MatchLabels)
+		}
+	})
+	t.Run(`no policies for dynakube pods without default-deny`, func(t *testing.T) {
+		namespace := createDynakubeNamespace()
+		namespace.Labels = nil
+		clt := fake.NewClient(namespace)
+
+		_, err := createTestReconciler(clt).Reconcile(ctx, createTestDynakube())
+		require.NoError(t, err)
+
+		assert.Empty(t, listNetworkPolicies(t, clt))
+	})
+	t.Run(`activegate can reach the api server`, func(t *testing.T) {
+		tcp := corev1.ProtocolTCP
+		service := &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: kubernetesServiceName, Namespace: kubernetesServiceNamespace},
+			Spec: corev1.ServiceSpec{
+				ClusterIP: "10.96.0.1",
+				Ports:     []corev1.ServicePort{{Name: "https", Protocol: tcp, Port: 443}},
+			},
+		}
+		endpoints := &corev1.Endpoints{
+			ObjectMeta: metav1.ObjectMeta{Name: kubernetesServiceName, Namespace: kubernetesServiceNamespace},
+			Subsets: []corev1.EndpointSubset{{
+				Addresses: []corev1.EndpointAddress{{IP: "192.168.0.10"}},
+				Ports:     []corev1.EndpointPort{{Name: "https", Protocol: tcp, Port: 6443}},
+			}},
+		}
+		clt := fake.NewClient(createDynakubeNamespace(), service, endpoints)
+		instance := createTestDynakube()
+
+		_, err := createTestReconciler(clt).Reconcile(ctx, instance)
+		require.NoError(t, err)
+
+		activeGateLabels := labels.Set(statefulset.BuildLabelsFromInstance(instance, "kubemon"))
+		allowed := map[string]int{}
+		for _, policy := range listNetworkPolicies(t, clt) {
+			selector, err := metav1.LabelSelectorAsSelector(&policy.Spec.PodSelector)
+			require.NoError(t, err)
+			if policy.Namespace != testNamespace || !selector.Matches(activeGateLabels) {
+				continue
+			}
+			for _, rule := range policy.Spec.Egress {
+				for _, peer := range rule.To {
+					if peer.IPBlock == nil {
+						continue
+					}
+					for _, port := range rule.Ports {
+						allowed[peer.IPBlock.CIDR] = port.Port.IntValue()
+					}
+				}
+			}
+		}
+		assert.Equal(t, 443, allowed["10.96.0.1/32"])
+		assert.Equal(t, 6443, allowed["192.168.0.10/32"])
+
+		policy := &networkingv1.NetworkPolicy{}
+		require.NoError(t, clt.Get(ctx, client.ObjectKey{Namespace: testNamespace, Name: testInstanceName + clusterEgressSuffix}, policy))
+		assert.Contains(t, policy.Spec.Egress, networkingv1.NetworkPolicyEgressRule{
+			To: []networkingv1.NetworkPolicyPeer{{NamespaceSelector: &metav1.LabelSelector{}}},
+		})
+	})
+	t.Run(`no policies in injected namespaces without default-deny`, func(t *testing.T) {
+		namespace := createInjectedNamespace(testInjectedNamespace)
+		delete(namespace.Labels, webhook.LabelNetworkPolicyDefaultDeny)
+		clt := fake.NewClient(namespace, createDynakubeNamespace())
+
+		_, err := createTestReconciler(clt).Reconcile(ctx, createTestDynakube())
+		require.NoError(t, err)
+
+		for _, policy := range listNetworkPolicies(t, clt) {
+			assert.Equal(t, testNamespace, policy.Namespace)
+		}
+	})
+	t.Run(`update drifted policies`, func(t *testing.T) {
+		clt := fake.NewClient(createInjectedNamespace(testInjectedNamespace))
+		instance := createTestDynakube()
+
+		_, err := createTestReconciler(clt).Reconcile(ctx, instance)
+		require.NoError(t, err)
+
+		policy := &networkingv1.NetworkPolicy{}
+		key := client.ObjectKey{Namespace: testInjectedNamespace, Name: buildNameForEndpoint(testInstanceName, testHostnameEndpoint)}
+		require.NoError(t, clt.Get(ctx, key, policy))
+		policy.Spec.PodSelector.MatchLabels = nil
+		require.NoError(t, clt.Update(ctx, policy))
+
+		updated, err := createTestReconciler(clt).Reconcile(ctx, instance)
+		require.NoError(t, err)
+		assert.True(t, updated)
+
+		require.NoError(t, clt.Get(ctx, key, policy))
+		assert.Equal(t, map[string]string{webhook.LabelOneAgentInjected: "true"}, policy.Spec.PodSelector.MatchLabels)
+	})
+	t.Run(`remove policies of outdated endpoints and namespaces`, func(t *testing.T) {
+		clt := fake.NewClient(createInjectedNamespace(testInjectedNamespace), createDynakubeNamespace())
+		instance := createTestDynakube(dynatracev1beta1.CommunicationHostStatus(testIpEndpoint))
+
+		_, err := createTestReconciler(clt).Reconcile(ctx, instance)
+		require.NoError(t, err)
+		require.Len(t, listNetworkPolicies(t, clt), 5)
+
+		namespace := &corev1.Namespace{}
+		require.NoError(t, clt.Get(ctx, client.ObjectKey{Name: testInjectedNamespace}, namespace))
+		namespace.Labels = nil
+		require.NoError(t, clt.Update(ctx, namespace))
+
+		instance.Status.ConnectionInfo.CommunicationHosts = nil
+		updated, err := createTestReconciler(clt).Reconcile(ctx, instance)
+		require.NoError(t, err)
+		assert.True(t, updated)
+
+		policies := listNetworkPolicies(t, clt)
+		require.Len(t, policies, 2)
+		for _, policy := range policies {
+			assert.Equal(t, testNamespace, policy.Namespace)
+			assert.Contains(t, []string{buildNameForEndpoint(testInstanceName, testHostnameEndpoint), testInstanceName + clusterEgressSuffix}, policy.Name)
+		}
+	})
+	t.Run(`keep policies of other dynakubes`, func(t *testing.T) {
+		other := buildNetworkPolicy(testName, "other", testAllPods, testIpEndpoint)
+		clt := fake.NewClient(other, createDynakubeNamespace())
+
+		_, err := createTestReconciler(clt).Reconcile(ctx, createTestDynakube())
+		require.NoError(t, err)
+
+		assert.Len(t, listNetworkPolicies(t, clt), 3)
+	})
+	t.Run(`cleanup removes policies of all namespaces`, func(t *testing.T) {
+		other := buildNetworkPolicy(testName, "other", testAllPods, testIpEndpoint)
+		clt := fake.NewClient(createInjectedNamespace(testInjectedNamespace), createDynakubeNamespace(), other)
+
+		_, err := createTestReconciler(clt).Reconcile(ctx, createTestDynakube())
+		require.NoError(t, err)
+		require.Len(t, listNetworkPolicies(t, clt), 4)
+
+		removed, err := createTestReconciler(clt).Cleanup(ctx, testInstanceName, testNamespace)
+		require.NoError(t, err)
+		assert.True(t, removed)
+
+		policies := listNetworkPolicies(t, clt)
+		require.Len(t, policies, 1)
+		assert.Equal(t, testName, policies[0].Name)
+	})
+	t.Run(`use fqdn policy for hostnames if available`, func(t *testing.T) {
+		clt := fake.NewClient(createDynakubeNamespace())
+		instance := createTestDynakube(dynatracev1beta1.CommunicationHostStatus(testIpEndpoint))
+
+		_, err := createTestReconciler(clt, CiliumNetworkPolicyGVK, CalicoNetworkPolicyGVK).Reconcile(ctx, instance)
+		require.NoError(t, err)
+
+		policy := &networkingv1.NetworkPolicy{}
+		require.NoError(t, clt.Get(ctx, client.ObjectKey{Namespace: testNamespace, Name: buildNameForEndpoint(testInstanceName, testIpEndpoint)}, policy))
+		require.Len(t, listNetworkPolicies(t, clt), 2)
+
+		ciliumPolicy := &unstructured.Unstructured{}
+		ciliumPolicy.SetGroupVersionKind(CiliumNetworkPolicyGVK)
+		err = clt.Get(ctx, client.ObjectKey{Namespace: testNamespace, Name: buildNameForEndpoint(testInstanceName, testHostnameEndpoint)}, ciliumPolicy)
+		require.NoError(t, err)
+		assert.Equal(t, buildLabels(testInstanceName), ciliumPolicy.GetLabels())
+
+		updated, err := createTestReconciler(clt, CiliumNetworkPolicyGVK, CalicoNetworkPolicyGVK).Reconcile(ctx, instance)
+		require.NoError(t, err)
+		assert.False(t, updated)
+	})
+}
@@ -23,6 +23,9 @@ const (
 	annotationUnprivilegedValue = "unconfined"
 	annotationVersion           = dynatracev1beta1.InternalFlagPrefix + "version"
 
+	// AnnotationTemplateHash is set on the pod template if the pods are rolled out in stages, to find the outdated pods
+	AnnotationTemplateHash = dynatracev1beta1.InternalFlagPrefix + "template-hash"
+
 	defaultUnprivilegedServiceAccountName = "dynatrace-dynakube-oneagent-unprivileged"
 	// normal oneagent shutdown scenario with some extra time
 	defaultTerminationGracePeriod = 80
@@ -187,6 +190,13 @@ func (dsInfo *builderInfo) BuildDaemonSet() (*appsv1.DaemonSet, error) {
 		},
 	}
 
+	if dsInfo.hostInjectSpec.Rollout != nil {
+		// outdated pods are deleted stage by stage by the operator
+		result.Spec.UpdateStrategy = appsv1.DaemonSetUpdateStrategy{
+			Type: appsv1.OnDeleteDaemonSetStrategyType,
+		}
+	}
+
 	return result, nil
 }
 
@@ -6,6 +6,7 @@ import (
 	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 )
@@ -53,6 +54,45 @@ func TestUseImmutableImage(t *testing.T) {
 	})
 }
 
+func TestUpdateStrategy(t *testing.T) {
+	t.Run(`pods are updated by rolling update`, func(t *testing.T) {
+		instance := dynatracev1beta1.DynaKube{
+			Spec: dynatracev1beta1.DynaKubeSpec{
+				APIURL: testURL,
+				OneAgent: dynatracev1beta1.OneAgentSpec{
+					ClassicFullStack: &dynatracev1beta1.ClassicFullStackSpec{},
+				},
+			},
+		}
+		ds, err := NewClassicFullStack(&instance, testClusterID).BuildDaemonSet()
+		require.NoError(t, err)
+
+		assert.Empty(t, ds.Spec.UpdateStrategy.Type)
+		assert.Equal(t, 1, ds.Spec.UpdateStrategy.RollingUpdate.MaxUnavailable.IntValue())
+	})
+	t.Run(`pods are deleted by the operator for staged rollouts`, func(t *testing.T) {
+		instance := dynatracev1beta1.DynaKube{
+			Spec: dynatracev1beta1.DynaKubeSpec{
+				APIURL: testURL,
+				OneAgent: dynatracev1beta1.OneAgentSpec{
+					ClassicFullStack: &dynatracev1beta1.ClassicFullStackSpec{
+						HostInjectSpec: dynatracev1beta1.HostInjectSpec{
+							Rollout: &dynatracev1beta1.RolloutSpec{
+								Stages: []dynatracev1beta1.RolloutStage{{Name: "canary", NodeSelector: map[string]string{"pool": "canary"}}},
+							},
+						},
+					},
+				},
+			},
+		}
+		ds, err := NewClassicFullStack(&instance, testClusterID).BuildDaemonSet()
+		require.NoError(t, err)
+
+		assert.Equal(t, appsv1.OnDeleteDaemonSetStrategyType, ds.Spec.UpdateStrategy.Type)
+		assert.Nil(t, ds.Spec.UpdateStrategy.RollingUpdate)
+	})
+}
+
 func TestCustomPullSecret(t *testing.T) {
 	instance := dynatracev1beta1.DynaKube{
 		Spec: dynatracev1beta1.DynaKubeSpec{
@@ -64,7 +64,7 @@ func (dsInfo *builderInfo) setDefaultProxy(envVarMap map[string]corev1.EnvVar) m
 	if dsInfo.instance.Spec.Proxy.ValueFrom != "" {
 		setDefaultValueSource(envVarMap, proxy, &corev1.EnvVarSource{
 			SecretKeyRef: &corev1.SecretKeySelector{
-				LocalObjectReference: corev1.LocalObjectReference{Name: dsInfo.instance.Spec.Proxy.ValueFrom},
+				LocalObjectReference: corev1.LocalObjectReference{Name: dsInfo.instance.ProxySecret()},
 				Key:                  "proxy",
 			},
 		})
@@ -0,0 +1,86 @@
+package daemonset
+
+import (
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	csivolumes "github.com/Dynatrace/dynatrace-operator/src/controllers/csi/driver/volumes"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+const (
+	// TenantUUIDKey, TenantTokenKey and CommunicationEndpointsKey are the keys of the OneAgent tenant secret of a tenant target
+	TenantUUIDKey             = "tenant-uuid"
+	TenantTokenKey            = "tenant-token"
+	CommunicationEndpointsKey = "communication-endpoints"
+
+	dtTenant      = "DT_TENANT"
+	dtTenantToken = "DT_TENANT_TOKEN"
+	dtServer      = "DT_SERVER"
+)
+
+// ForTenantTarget turns the DaemonSet built for the primary tenant into the one of the OneAgents reporting to the additional tenant.
+// The tenant config is passed to the installer of the OneAgent from the OneAgent tenant secret of the tenant target.
+// Its pods are rolled out with a rolling update, the staged rollout only applies to the OneAgents of the primary tenant.
+func ForTenantTarget(ds *appsv1.DaemonSet, instance *dynatracev1beta1.DynaKube, target string) {
+	ds.Name = instance.OneAgentDaemonsetNameForTarget(target)
+	ds.Labels[dynatracev1beta1.LabelTenantTarget] = target
+	ds.Spec.Selector.MatchLabels[dynatracev1beta1.LabelTenantTarget] = target
+	ds.Spec.Template.Labels[dynatracev1beta1.LabelTenantTarget] = target
+	delete(ds.Spec.Template.Annotations, AnnotationTemplateHash)
+
+	maxUnavailable := intstr.FromInt(instance.FeatureOneAgentMaxUnavailable())
+	ds.Spec.UpdateStrategy = appsv1.DaemonSetUpdateStrategy{
+		RollingUpdate: &appsv1.RollingUpdateDaemonSet{
+			MaxUnavailable: &maxUnavailable,
+		},
+	}
+
+	podSpec := &ds.Spec.Template.Spec
+	if len(podSpec.Containers) > 0 {
+		secretName := instance.OneAgentTenantSecretForTarget(target)
+		container := &podSpec.Containers[0]
+		container.Args = append(container.Args,
+			"--set-tenant=$("+dtTenant+")",
+			"--set-tenant-token=$("+dtTenantToken+")",
+			"--set-server=$("+dtServer+")")
+
+		envVarMap := envVarsToMap(container.Env)
+		envVarMap[dtTenant] = secretEnvVar(dtTenant, secretName, TenantUUIDKey)
+		envVarMap[dtTenantToken] = secretEnvVar(dtTenantToken, secretName, TenantTokenKey)
+		envVarMap[dtServer] = secretEnvVar(dtServer, secretName, CommunicationEndpointsKey)
+		container.Env = mapToArray(envVarMap)
+	}
+
+	for _, volume := range podSpec.Volumes {
+		if volume.Name == csiStorageVolumeName && volume.CSI != nil {
+			volume.CSI.VolumeAttributes[csivolumes.CSIVolumeAttributeTenantTargetField] = target
+		}
+	}
+}
+
+func secretEnvVar(name string, secretName string, key string) corev1.EnvVar {
+	return corev1.EnvVar{
+		Name: name,
+		ValueFrom: &corev1.EnvVarSource{
+			SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: secretName},
+				Key:                  key,
+			},
+		},
+	}
+}
+
+// PodSelector selects the pods of the DaemonSet with the given selector labels. The selector of the DaemonSet of the primary
+// tenant matches the pods of the tenant targets as well, so these are excluded explicitly.
+func PodSelector(matchLabels map[string]string) labels.Selector {
+	selector := labels.SelectorFromSet(matchLabels)
+	if _, isTenantTarget := matchLabels[dynatracev1beta1.LabelTenantTarget]; isTenantTarget {
+		return selector
+	}
+	// the key is a valid label key, so there's no error
+	notTenantTarget, _ := labels.NewRequirement(dynatracev1beta1.LabelTenantTarget, selection.DoesNotExist, nil)
+	return selector.Add(*notTenantTarget)
+}
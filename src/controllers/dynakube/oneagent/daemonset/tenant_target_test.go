@@ -0,0 +1,74 @@
+package daemonset
+
+import (
+	"testing"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	csivolumes "github.com/Dynatrace/dynatrace-operator/src/controllers/csi/driver/volumes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+const testTenantTarget = "migration"
+
+func TestForTenantTarget(t *testing.T) {
+	instance := &dynatracev1beta1.DynaKube{
+		ObjectMeta: metav1.ObjectMeta{Name: "dynakube"},
+		Spec: dynatracev1beta1.DynaKubeSpec{
+			APIURL: testURL,
+			OneAgent: dynatracev1beta1.OneAgentSpec{
+				CloudNativeFullStack: &dynatracev1beta1.CloudNativeFullStackSpec{
+					HostInjectSpec: dynatracev1beta1.HostInjectSpec{
+						Rollout: &dynatracev1beta1.RolloutSpec{},
+					},
+				},
+			},
+		},
+	}
+	ds, err := NewCloudNativeFullStack(instance, testClusterID).BuildDaemonSet()
+	require.NoError(t, err)
+	ds.Spec.Template.Annotations[AnnotationTemplateHash] = "hash"
+	primarySelector := ds.Spec.Selector.DeepCopy()
+
+	ForTenantTarget(ds, instance, testTenantTarget)
+
+	assert.Equal(t, "dynakube-migration-oneagent", ds.Name)
+	assert.Equal(t, testTenantTarget, ds.Spec.Selector.MatchLabels[dynatracev1beta1.LabelTenantTarget])
+	assert.Equal(t, testTenantTarget, ds.Spec.Template.Labels[dynatracev1beta1.LabelTenantTarget])
+	assert.NotContains(t, ds.Spec.Template.Annotations, AnnotationTemplateHash)
+	assert.NotEqual(t, appsv1.OnDeleteDaemonSetStrategyType, ds.Spec.UpdateStrategy.Type)
+	assert.NotNil(t, ds.Spec.UpdateStrategy.RollingUpdate)
+
+	container := ds.Spec.Template.Spec.Containers[0]
+	assert.Subset(t, container.Args, []string{"--set-tenant=$(DT_TENANT)", "--set-tenant-token=$(DT_TENANT_TOKEN)", "--set-server=$(DT_SERVER)"})
+	envVarMap := envVarsToMap(container.Env)
+	for name, key := range map[string]string{dtTenant: TenantUUIDKey, dtTenantToken: TenantTokenKey, dtServer: CommunicationEndpointsKey} {
+		require.Contains(t, envVarMap, name)
+		assert.Equal(t, instance.OneAgentTenantSecretForTarget(testTenantTarget), envVarMap[name].ValueFrom.SecretKeyRef.Name)
+		assert.Equal(t, key, envVarMap[name].ValueFrom.SecretKeyRef.Key)
+	}
+
+	var csiVolumeFound bool
+	for _, volume := range ds.Spec.Template.Spec.Volumes {
+		if volume.Name == csiStorageVolumeName {
+			csiVolumeFound = true
+			assert.Equal(t, testTenantTarget, volume.CSI.VolumeAttributes[csivolumes.CSIVolumeAttributeTenantTargetField])
+		}
+	}
+	assert.True(t, csiVolumeFound)
+
+	t.Run(`pods of tenant target are excluded from primary pods`, func(t *testing.T) {
+		primaryPods := PodSelector(primarySelector.MatchLabels)
+		targetPods := PodSelector(ds.Spec.Selector.MatchLabels)
+
+		primaryPodLabels := labels.Set(primarySelector.MatchLabels)
+		targetPodLabels := labels.Set(ds.Spec.Template.Labels)
+		assert.True(t, primaryPods.Matches(primaryPodLabels))
+		assert.False(t, primaryPods.Matches(targetPodLabels))
+		assert.True(t, targetPods.Matches(targetPodLabels))
+		assert.False(t, targetPods.Matches(primaryPodLabels))
+	})
+}
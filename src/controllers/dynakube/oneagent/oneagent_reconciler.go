@@ -157,13 +157,13 @@ func (r *OneAgentReconciler) getPods(ctx context.Context, instance *dynatracev1b
 	podList := &corev1.PodList{}
 	listOps := []client.ListOption{
 		client.InNamespace((*instance).GetNamespace()),
-		client.MatchingLabels(buildLabels(instance.Name, feature)),
+		client.MatchingLabelsSelector{Selector: daemonset.PodSelector(buildLabels(instance.Name, feature))},
 	}
 	err := r.client.List(ctx, podList, listOps...)
 	return podList.Items, listOps, err
 }
 
-func (r *OneAgentReconciler) newDaemonSetForCR(dkState *status.DynakubeState, clusterID string) (*appsv1.DaemonSet, error) {
+func (r *OneAgentReconciler) buildDaemonSet(dkState *status.DynakubeState, clusterID string) (*appsv1.DaemonSet, error) {
 	var ds *appsv1.DaemonSet
 	var err error
 
@@ -174,10 +174,23 @@ func (r *OneAgentReconciler) newDaemonSetForCR(dkState *status.DynakubeState, cl
 	} else if r.feature == daemonset.CloudNativeFeature {
 		ds, err = daemonset.NewCloudNativeFullStack(dkState.Instance, clusterID).BuildDaemonSet()
 	}
+	return ds, err
+}
+
+func (r *OneAgentReconciler) newDaemonSetForCR(dkState *status.DynakubeState, clusterID string) (*appsv1.DaemonSet, error) {
+	ds, err := r.buildDaemonSet(dkState, clusterID)
 	if err != nil {
 		return nil, err
 	}
 
+	if dkState.Instance.OneAgentRollout() != nil {
+		templateHash, err := kubeobjects.GenerateHash(ds.Spec.Template)
+		if err != nil {
+			return nil, err
+		}
+		ds.Spec.Template.Annotations[daemonset.AnnotationTemplateHash] = templateHash
+	}
+
 	dsHash, err := kubeobjects.GenerateHash(ds)
 	if err != nil {
 		return nil, err
@@ -0,0 +1,348 @@
+package rollout
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/oneagent/daemonset"
+	"github.com/Dynatrace/dynatrace-operator/src/dtclient"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	defaultSoakTime         = 10 * time.Minute
+	defaultProgressDeadline = 15 * time.Minute
+
+	// RemainingStageName is the name of the last stage, which contains the nodes not selected by any configured stage
+	RemainingStageName = "remaining"
+
+	// maxReportedPods limits the number of unhealthy pods named in the status message
+	maxReportedPods = 5
+)
+
+// Reconciler rolls out changes of the OneAgent pods stage by stage. The daemonset uses the OnDelete update strategy,
+// so its pods are only replaced when the reconciler deletes them.
+type Reconciler struct {
+	client    client.Client
+	apiReader client.Reader
+	dtc       dtclient.Client
+	now       metav1.Time
+}
+
+func NewReconciler(clt client.Client, apiReader client.Reader, dtc dtclient.Client, now metav1.Time) *Reconciler {
+	return &Reconciler{
+		client:    clt,
+		apiReader: apiReader,
+		dtc:       dtc,
+		now:       now,
+	}
+}
+
+// Reconcile deletes the outdated OneAgent pods of the current stage and checks the health of the updated ones.
+// After the soak time it continues with the next stage, unless too many updated pods are unhealthy, which halts the rollout
+// until the OneAgent pod template changes again.
+// Returns true if the rollout status of the DynaKube changed.
+func (r *Reconciler) Reconcile(ctx context.Context, instance *dynatracev1beta1.DynaKube) (bool, error) {
+	spec := instance.OneAgentRollout()
+	if spec == nil {
+		upd := instance.Status.OneAgent.Rollout != nil
+		instance.Status.OneAgent.Rollout = nil
+		return upd, nil
+	}
+
+	var ds appsv1.DaemonSet
+	err := r.client.Get(ctx, client.ObjectKey{Name: instance.OneAgentDaemonsetName(), Namespace: instance.Namespace}, &ds)
+	if k8serrors.IsNotFound(err) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	revision := ds.Spec.Template.Annotations[daemonset.AnnotationTemplateHash]
+	if revision == "" {
+		return false, nil
+	}
+
+	oldStatus := instance.Status.OneAgent.Rollout.DeepCopy()
+	rolloutStatus := instance.Status.OneAgent.Rollout
+	if rolloutStatus == nil || rolloutStatus.Revision != revision {
+		log.Info("starting staged rollout", "revision", revision)
+		rolloutStatus = &dynatracev1beta1.OneAgentRolloutStatus{Revision: revision}
+		r.startStage(rolloutStatus, spec, 0)
+		instance.Status.OneAgent.Rollout = rolloutStatus
+	} else if rolloutStatus.Stage > len(spec.Stages) {
+		r.startStage(rolloutStatus, spec, len(spec.Stages))
+	}
+
+	if rolloutStatus.Phase != dynatracev1beta1.RolloutHalted && rolloutStatus.Phase != dynatracev1beta1.RolloutCompleted {
+		err = r.reconcileStages(ctx, instance, &ds, spec, rolloutStatus)
+	}
+	return !reflect.DeepEqual(oldStatus, rolloutStatus), err
+}
+
+func (r *Reconciler) reconcileStages(ctx context.Context, instance *dynatracev1beta1.DynaKube, ds *appsv1.DaemonSet,
+	spec *dynatracev1beta1.RolloutSpec, rolloutStatus *dynatracev1beta1.OneAgentRolloutStatus) error {
+
+	pods, err := r.getPods(ctx, ds)
+	if err != nil {
+		return err
+	}
+
+	nodeLabels, err := r.getNodeLabels(ctx)
+	if err != nil {
+		return err
+	}
+
+	for {
+		stagePods := podsOfStage(pods, nodeLabels, spec, rolloutStatus.Stage)
+		outdated, updated := splitOutdated(stagePods, rolloutStatus.Revision)
+		soaking := rolloutStatus.StageReadyTimestamp != nil
+
+		if unhealthy := r.unhealthyPods(updated, spec, soaking); len(unhealthy) > spec.MaxUnhealthyPods {
+			r.halt(rolloutStatus, fmt.Sprintf("%d of %d updated pods unhealthy: %s", len(unhealthy), len(updated), describePods(unhealthy)))
+			return nil
+		}
+
+		if len(outdated) > 0 {
+			rolloutStatus.Phase = dynatracev1beta1.RolloutProgressing
+			rolloutStatus.StageReadyTimestamp = nil
+			rolloutStatus.Message = fmt.Sprintf("stage %s: %d of %d pods updated", rolloutStatus.StageName, len(updated), len(stagePods))
+			return r.deleteOutdatedPods(ctx, instance, pods, outdated)
+		}
+
+		if !allReady(updated) {
+			rolloutStatus.Message = fmt.Sprintf("stage %s: waiting for updated pods to be ready", rolloutStatus.StageName)
+			return nil
+		}
+
+		if rolloutStatus.Stage >= len(spec.Stages) {
+			log.Info("staged rollout completed", "revision", rolloutStatus.Revision)
+			rolloutStatus.Phase = dynatracev1beta1.RolloutCompleted
+			rolloutStatus.StageReadyTimestamp = r.now.DeepCopy()
+			rolloutStatus.Message = "all pods updated"
+			return nil
+		}
+
+		if len(updated) > 0 {
+			if !soaking {
+				rolloutStatus.Phase = dynatracev1beta1.RolloutSoaking
+				rolloutStatus.StageReadyTimestamp = r.now.DeepCopy()
+			}
+			soakEnd := rolloutStatus.StageReadyTimestamp.Add(durationOrDefault(spec.SoakTime, defaultSoakTime))
+			if r.now.Time.Before(soakEnd) {
+				rolloutStatus.Message = fmt.Sprintf("stage %s: soaking until %s", rolloutStatus.StageName, soakEnd.UTC().Format(time.RFC3339))
+				return nil
+			}
+
+			if unavailable := r.unavailableHosts(updated); len(unavailable) > spec.MaxUnhealthyPods {
+				r.halt(rolloutStatus, fmt.Sprintf("hosts of %d of %d updated pods not available in Dynatrace: %s",
+					len(unavailable), len(updated), describePods(unavailable)))
+				return nil
+			}
+		}
+
+		log.Info("staged rollout stage completed", "stage", rolloutStatus.StageName, "revision", rolloutStatus.Revision)
+		r.startStage(rolloutStatus, spec, rolloutStatus.Stage+1)
+	}
+}
+
+func (r *Reconciler) startStage(rolloutStatus *dynatracev1beta1.OneAgentRolloutStatus, spec *dynatracev1beta1.RolloutSpec, stage int) {
+	rolloutStatus.Stage = stage
+	rolloutStatus.StageName = RemainingStageName
+	if stage < len(spec.Stages) {
+		rolloutStatus.StageName = spec.Stages[stage].Name
+	}
+	rolloutStatus.Phase = dynatracev1beta1.RolloutProgressing
+	rolloutStatus.StageStartedTimestamp = r.now.DeepCopy()
+	rolloutStatus.StageReadyTimestamp = nil
+	rolloutStatus.Message = fmt.Sprintf("stage %s started", rolloutStatus.StageName)
+}
+
+func (r *Reconciler) halt(rolloutStatus *dynatracev1beta1.OneAgentRolloutStatus, reason string) {
+	log.Info("staged rollout halted", "stage", rolloutStatus.StageName, "revision", rolloutStatus.Revision, "reason", reason)
+	rolloutStatus.Phase = dynatracev1beta1.RolloutHalted
+	rolloutStatus.Message = fmt.Sprintf("stage %s: %s", rolloutStatus.StageName, reason)
+}
+
+// deleteOutdatedPods deletes as many outdated pods as the max unavailable setting of the OneAgent allows,
+// considering the pods which are not ready already.
+func (r *Reconciler) deleteOutdatedPods(ctx context.Context, instance *dynatracev1beta1.DynaKube, pods []corev1.Pod, outdated []corev1.Pod) error {
+	budget := instance.FeatureOneAgentMaxUnavailable()
+	for _, pod := range pods {
+		if !isReady(&pod) {
+			budget--
+		}
+	}
+
+	for i := 0; i < budget && i < len(outdated); i++ {
+		log.Info("deleting outdated OneAgent pod", "pod", outdated[i].Name, "node", outdated[i].Spec.NodeName)
+		if err := r.client.Delete(ctx, &outdated[i]); err != nil && !k8serrors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// unhealthyPods returns the updated pods which restarted, or are not ready during the soak time or after the progress deadline.
+func (r *Reconciler) unhealthyPods(updated []corev1.Pod, spec *dynatracev1beta1.RolloutSpec, soaking bool) []corev1.Pod {
+	progressDeadline := durationOrDefault(spec.ProgressDeadline, defaultProgressDeadline)
+
+	var unhealthy []corev1.Pod
+	for _, pod := range updated {
+		if restarts(&pod) > 0 {
+			unhealthy = append(unhealthy, pod)
+		} else if !isReady(&pod) && (soaking || pod.CreationTimestamp.Add(progressDeadline).Before(r.now.Time)) {
+			unhealthy = append(unhealthy, pod)
+		}
+	}
+	return unhealthy
+}
+
+// unavailableHosts returns the updated pods whose host is not known to Dynatrace.
+func (r *Reconciler) unavailableHosts(updated []corev1.Pod) []corev1.Pod {
+	if r.dtc == nil {
+		return nil
+	}
+
+	var unavailable []corev1.Pod
+	for _, pod := range updated {
+		if _, err := r.dtc.GetEntityIDForIP(pod.Status.HostIP); err != nil {
+			log.Info("host of updated OneAgent pod not available", "pod", pod.Name, "ip", pod.Status.HostIP, "cause", err)
+			unavailable = append(unavailable, pod)
+		}
+	}
+	return unavailable
+}
+
+// getPods lists the pods of the daemonset from the api server, the unavailable budget is based on their readiness,
+// so pods deleted by the previous reconcile must not be seen as ready from a stale cache.
+func (r *Reconciler) getPods(ctx context.Context, ds *appsv1.DaemonSet) ([]corev1.Pod, error) {
+	var podList corev1.PodList
+	if err := r.apiReader.List(ctx, &podList, client.InNamespace(ds.Namespace), client.MatchingLabelsSelector{Selector: daemonset.PodSelector(ds.Spec.Selector.MatchLabels)}); err != nil {
+		return nil, err
+	}
+
+	pods := podList.Items
+	sort.Slice(pods, func(i, j int) bool {
+		return pods[i].Name < pods[j].Name
+	})
+	return pods, nil
+}
+
+func (r *Reconciler) getNodeLabels(ctx context.Context) (map[string]labels.Set, error) {
+	var nodeList corev1.NodeList
+	if err := r.client.List(ctx, &nodeList); err != nil {
+		return nil, err
+	}
+
+	nodeLabels := make(map[string]labels.Set, len(nodeList.Items))
+	for _, node := range nodeList.Items {
+		nodeLabels[node.Name] = node.Labels
+	}
+	return nodeLabels, nil
+}
+
+// podsOfStage returns the pods on the nodes selected by the stage, or all pods for the remaining stage.
+func podsOfStage(pods []corev1.Pod, nodeLabels map[string]labels.Set, spec *dynatracev1beta1.RolloutSpec, stage int) []corev1.Pod {
+	if stage >= len(spec.Stages) {
+		return pods
+	}
+
+	selector := labels.SelectorFromSet(spec.Stages[stage].NodeSelector)
+	var stagePods []corev1.Pod
+	for _, pod := range pods {
+		if nodeLabel, ok := nodeLabels[pod.Spec.NodeName]; ok && selector.Matches(nodeLabel) {
+			stagePods = append(stagePods, pod)
+		}
+	}
+	return stagePods
+}
+
+func splitOutdated(pods []corev1.Pod, revision string) (outdated []corev1.Pod, updated []corev1.Pod) {
+	for _, pod := range pods {
+		if pod.Annotations[daemonset.AnnotationTemplateHash] == revision {
+			updated = append(updated, pod)
+		} else {
+			outdated = append(outdated, pod)
+		}
+	}
+	return outdated, updated
+}
+
+func allReady(pods []corev1.Pod) bool {
+	for _, pod := range pods {
+		if !isReady(&pod) {
+			return false
+		}
+	}
+	return true
+}
+
+func isReady(pod *corev1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+func restarts(pod *corev1.Pod) int32 {
+	var count int32
+	for _, containerStatus := range pod.Status.ContainerStatuses {
+		count += containerStatus.RestartCount
+	}
+	return count
+}
+
+func describePods(pods []corev1.Pod) string {
+	names := make([]string, 0, maxReportedPods)
+	for i := 0; i < len(pods) && i < maxReportedPods; i++ {
+		names = append(names, fmt.Sprintf("%s on %s", pods[i].Name, pods[i].Spec.NodeName))
+	}
+	if len(pods) > maxReportedPods {
+		names = append(names, "...")
+	}
+	return strings.Join(names, ", ")
+}
+
+func durationOrDefault(duration *metav1.Duration, defaultDuration time.Duration) time.Duration {
+	if duration == nil {
+		return defaultDuration
+	}
+	return duration.Duration
+}
+
+// Condition returns the condition of the staged rollout for the given status.
+func Condition(rolloutStatus *dynatracev1beta1.OneAgentRolloutStatus) metav1.Condition {
+	condition := metav1.Condition{
+		Type:   dynatracev1beta1.OneAgentRolloutConditionType,
+		Status: metav1.ConditionFalse,
+		Reason: dynatracev1beta1.ReasonRolloutInProgress,
+	}
+
+	if rolloutStatus == nil {
+		condition.Message = "waiting for the OneAgent daemonset"
+		return condition
+	}
+
+	condition.Message = rolloutStatus.Message
+	switch rolloutStatus.Phase {
+	case dynatracev1beta1.RolloutCompleted:
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = dynatracev1beta1.ReasonReady
+	case dynatracev1beta1.RolloutHalted:
+		condition.Reason = dynatracev1beta1.ReasonRolloutHalted
+	}
+	return condition
+}
@@ -0,0 +1,308 @@
+package rollout
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/oneagent/daemonset"
+	"github.com/Dynatrace/dynatrace-operator/src/dtclient"
+	"github.com/Dynatrace/dynatrace-operator/src/scheme/fake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	testNamespace = "dynatrace"
+	testName      = "dynakube"
+	testRevision  = "new"
+	poolLabel     = "pool"
+)
+
+var testPodLabels = map[string]string{"app": "oneagent"}
+
+func TestReconcile(t *testing.T) {
+	now := metav1.NewTime(time.Now())
+
+	t.Run(`without rollout the status is removed`, func(t *testing.T) {
+		instance := createDynakube(nil)
+		instance.Status.OneAgent.Rollout = &dynatracev1beta1.OneAgentRolloutStatus{}
+
+		upd, err := NewReconciler(fake.NewClient(), fake.NewClient(), nil, now).Reconcile(context.TODO(), instance)
+
+		require.NoError(t, err)
+		assert.True(t, upd)
+		assert.Nil(t, instance.Status.OneAgent.Rollout)
+	})
+	t.Run(`outdated pods of the canary stage are deleted first`, func(t *testing.T) {
+		instance := createDynakube(createRolloutSpec())
+		clt := createClient(
+			createPod("canary", "old", true, 0),
+			createPod("pool", "old", true, 0),
+		)
+
+		upd, err := NewReconciler(clt, clt, nil, now).Reconcile(context.TODO(), instance)
+
+		require.NoError(t, err)
+		assert.True(t, upd)
+		assertRollout(t, instance, dynatracev1beta1.RolloutProgressing, 0)
+		assert.Equal(t, []string{"oneagent-pool"}, listPods(t, clt))
+	})
+	t.Run(`no pods are deleted while pods are not ready`, func(t *testing.T) {
+		instance := createDynakube(createRolloutSpec())
+		clt := createClient(
+			createPod("canary", "old", true, 0),
+			createPod("pool", "old", false, 0),
+		)
+
+		_, err := NewReconciler(clt, clt, nil, now).Reconcile(context.TODO(), instance)
+
+		require.NoError(t, err)
+		assert.Len(t, listPods(t, clt), 2)
+	})
+	t.Run(`readiness is read from the api server`, func(t *testing.T) {
+		instance := createDynakube(createRolloutSpec())
+		// the cache still sees the pool pod ready, which was deleted and is not ready yet
+		cachedClient := createClient(
+			createPod("canary", "old", true, 0),
+			createPod("pool", "old", true, 0),
+		)
+		apiReader := createClient(
+			createPod("canary", "old", true, 0),
+			createPod("pool", "old", false, 0),
+		)
+
+		_, err := NewReconciler(cachedClient, apiReader, nil, now).Reconcile(context.TODO(), instance)
+
+		require.NoError(t, err)
+		assert.Len(t, listPods(t, cachedClient), 2)
+	})
+	t.Run(`ready canary stage is soaking`, func(t *testing.T) {
+		instance := createDynakube(createRolloutSpec())
+		clt := createClient(
+			createPod("canary", testRevision, true, 0),
+			createPod("pool", "old", true, 0),
+		)
+
+		_, err := NewReconciler(clt, clt, nil, now).Reconcile(context.TODO(), instance)
+
+		require.NoError(t, err)
+		assertRollout(t, instance, dynatracev1beta1.RolloutSoaking, 0)
+		assert.NotNil(t, instance.Status.OneAgent.Rollout.StageReadyTimestamp)
+		assert.Len(t, listPods(t, clt), 2)
+	})
+	t.Run(`next stage starts after the soak time if the hosts are available`, func(t *testing.T) {
+		instance := createDynakube(createRolloutSpec())
+		instance.Status.OneAgent.Rollout = createSoakingStatus(now.Add(-time.Hour))
+		clt := createClient(
+			createPod("canary", testRevision, true, 0),
+			createPod("pool", "old", true, 0),
+		)
+		dtc := &dtclient.MockDynatraceClient{}
+		dtc.On("GetEntityIDForIP", "10.0.0.1").Return("HOST-1", nil)
+		defer mock.AssertExpectationsForObjects(t, dtc)
+
+		_, err := NewReconciler(clt, clt, dtc, now).Reconcile(context.TODO(), instance)
+
+		require.NoError(t, err)
+		assertRollout(t, instance, dynatracev1beta1.RolloutProgressing, 1)
+		assert.Equal(t, RemainingStageName, instance.Status.OneAgent.Rollout.StageName)
+		assert.Equal(t, []string{"oneagent-canary"}, listPods(t, clt))
+	})
+	t.Run(`rollout halts if hosts are not available`, func(t *testing.T) {
+		instance := createDynakube(createRolloutSpec())
+		instance.Status.OneAgent.Rollout = createSoakingStatus(now.Add(-time.Hour))
+		clt := createClient(
+			createPod("canary", testRevision, true, 0),
+			createPod("pool", "old", true, 0),
+		)
+		dtc := &dtclient.MockDynatraceClient{}
+		dtc.On("GetEntityIDForIP", "10.0.0.1").Return("", fmt.Errorf("host not found"))
+
+		_, err := NewReconciler(clt, clt, dtc, now).Reconcile(context.TODO(), instance)
+
+		require.NoError(t, err)
+		assertRollout(t, instance, dynatracev1beta1.RolloutHalted, 0)
+		assert.Len(t, listPods(t, clt), 2)
+	})
+	t.Run(`rollout halts if updated pods restart`, func(t *testing.T) {
+		instance := createDynakube(createRolloutSpec())
+		clt := createClient(
+			createPod("canary", testRevision, true, 2),
+			createPod("pool", "old", true, 0),
+		)
+
+		_, err := NewReconciler(clt, clt, nil, now).Reconcile(context.TODO(), instance)
+
+		require.NoError(t, err)
+		assertRollout(t, instance, dynatracev1beta1.RolloutHalted, 0)
+		assert.Contains(t, instance.Status.OneAgent.Rollout.Message, "oneagent-canary on canary")
+		assert.Len(t, listPods(t, clt), 2)
+
+		// stays halted until the pod template changes
+		_, err = NewReconciler(clt, clt, nil, now).Reconcile(context.TODO(), instance)
+		require.NoError(t, err)
+		assertRollout(t, instance, dynatracev1beta1.RolloutHalted, 0)
+	})
+	t.Run(`unhealthy pods are tolerated up to the limit`, func(t *testing.T) {
+		spec := createRolloutSpec()
+		spec.MaxUnhealthyPods = 1
+		instance := createDynakube(spec)
+		clt := createClient(
+			createPod("canary", testRevision, true, 2),
+			createPod("pool", "old", true, 0),
+		)
+
+		_, err := NewReconciler(clt, clt, nil, now).Reconcile(context.TODO(), instance)
+
+		require.NoError(t, err)
+		assertRollout(t, instance, dynatracev1beta1.RolloutSoaking, 0)
+	})
+	t.Run(`rollout completes when all pods are updated`, func(t *testing.T) {
+		instance := createDynakube(createRolloutSpec())
+		instance.Status.OneAgent.Rollout = &dynatracev1beta1.OneAgentRolloutStatus{
+			Revision: testRevision,
+			Phase:    dynatracev1beta1.RolloutProgressing,
+			Stage:    1,
+		}
+		clt := createClient(
+			createPod("canary", testRevision, true, 0),
+			createPod("pool", testRevision, true, 0),
+		)
+
+		_, err := NewReconciler(clt, clt, nil, now).Reconcile(context.TODO(), instance)
+
+		require.NoError(t, err)
+		assertRollout(t, instance, dynatracev1beta1.RolloutCompleted, 1)
+		assert.Equal(t, metav1.ConditionTrue, Condition(instance.Status.OneAgent.Rollout).Status)
+	})
+	t.Run(`new revision restarts the rollout`, func(t *testing.T) {
+		instance := createDynakube(createRolloutSpec())
+		instance.Status.OneAgent.Rollout = &dynatracev1beta1.OneAgentRolloutStatus{
+			Revision: "old",
+			Phase:    dynatracev1beta1.RolloutHalted,
+			Stage:    0,
+		}
+		clt := createClient(
+			createPod("canary", "old", true, 0),
+			createPod("pool", "old", true, 0),
+		)
+
+		_, err := NewReconciler(clt, clt, nil, now).Reconcile(context.TODO(), instance)
+
+		require.NoError(t, err)
+		assertRollout(t, instance, dynatracev1beta1.RolloutProgressing, 0)
+		assert.Equal(t, testRevision, instance.Status.OneAgent.Rollout.Revision)
+		assert.Equal(t, []string{"oneagent-pool"}, listPods(t, clt))
+	})
+}
+
+func TestCondition(t *testing.T) {
+	halted := Condition(&dynatracev1beta1.OneAgentRolloutStatus{Phase: dynatracev1beta1.RolloutHalted, Message: "halted"})
+	assert.Equal(t, metav1.ConditionFalse, halted.Status)
+	assert.Equal(t, dynatracev1beta1.ReasonRolloutHalted, halted.Reason)
+	assert.Equal(t, "halted", halted.Message)
+
+	soaking := Condition(&dynatracev1beta1.OneAgentRolloutStatus{Phase: dynatracev1beta1.RolloutSoaking})
+	assert.Equal(t, metav1.ConditionFalse, soaking.Status)
+	assert.Equal(t, dynatracev1beta1.ReasonRolloutInProgress, soaking.Reason)
+}
+
+func createRolloutSpec() *dynatracev1beta1.RolloutSpec {
+	return &dynatracev1beta1.RolloutSpec{
+		Stages: []dynatracev1beta1.RolloutStage{
+			{Name: "canary", NodeSelector: map[string]string{poolLabel: "canary"}},
+		},
+	}
+}
+
+func createSoakingStatus(readySince time.Time) *dynatracev1beta1.OneAgentRolloutStatus {
+	ready := metav1.NewTime(readySince)
+	return &dynatracev1beta1.OneAgentRolloutStatus{
+		Revision:            testRevision,
+		Phase:               dynatracev1beta1.RolloutSoaking,
+		StageName:           "canary",
+		StageReadyTimestamp: &ready,
+	}
+}
+
+func createDynakube(rollout *dynatracev1beta1.RolloutSpec) *dynatracev1beta1.DynaKube {
+	return &dynatracev1beta1.DynaKube{
+		ObjectMeta: metav1.ObjectMeta{Name: testName, Namespace: testNamespace},
+		Spec: dynatracev1beta1.DynaKubeSpec{
+			OneAgent: dynatracev1beta1.OneAgentSpec{
+				ClassicFullStack: &dynatracev1beta1.ClassicFullStackSpec{
+					HostInjectSpec: dynatracev1beta1.HostInjectSpec{Rollout: rollout},
+				},
+			},
+		},
+	}
+}
+
+func createClient(pods ...*corev1.Pod) client.Client {
+	objects := []client.Object{
+		&appsv1.DaemonSet{
+			ObjectMeta: metav1.ObjectMeta{Name: testName + "-oneagent", Namespace: testNamespace},
+			Spec: appsv1.DaemonSetSpec{
+				Selector: &metav1.LabelSelector{MatchLabels: testPodLabels},
+				Template: corev1.PodTemplateSpec{
+					ObjectMeta: metav1.ObjectMeta{
+						Annotations: map[string]string{daemonset.AnnotationTemplateHash: testRevision},
+					},
+				},
+			},
+		},
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "canary", Labels: map[string]string{poolLabel: "canary"}}},
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "pool", Labels: map[string]string{poolLabel: "default"}}},
+	}
+	for _, pod := range pods {
+		objects = append(objects, pod)
+	}
+	return fake.NewClient(objects...)
+}
+
+func createPod(node string, revision string, ready bool, restartCount int32) *corev1.Pod {
+	readyStatus := corev1.ConditionFalse
+	if ready {
+		readyStatus = corev1.ConditionTrue
+	}
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "oneagent-" + node,
+			Namespace:         testNamespace,
+			Labels:            testPodLabels,
+			Annotations:       map[string]string{daemonset.AnnotationTemplateHash: revision},
+			CreationTimestamp: metav1.NewTime(time.Now()),
+		},
+		Spec: corev1.PodSpec{NodeName: node},
+		Status: corev1.PodStatus{
+			HostIP:            "10.0.0.1",
+			Conditions:        []corev1.PodCondition{{Type: corev1.PodReady, Status: readyStatus}},
+			ContainerStatuses: []corev1.ContainerStatus{{RestartCount: restartCount}},
+		},
+	}
+}
+
+func assertRollout(t *testing.T, instance *dynatracev1beta1.DynaKube, phase dynatracev1beta1.RolloutPhase, stage int) {
+	require.NotNil(t, instance.Status.OneAgent.Rollout)
+	assert.Equal(t, phase, instance.Status.OneAgent.Rollout.Phase, instance.Status.OneAgent.Rollout.Message)
+	assert.Equal(t, stage, instance.Status.OneAgent.Rollout.Stage)
+}
+
+func listPods(t *testing.T, clt client.Client) []string {
+	var podList corev1.PodList
+	require.NoError(t, clt.List(context.TODO(), &podList))
+
+	var names []string
+	for _, pod := range podList.Items {
+		names = append(names, pod.Name)
+	}
+	return names
+}
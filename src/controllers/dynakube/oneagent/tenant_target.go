@@ -0,0 +1,88 @@
+package oneagent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/oneagent/daemonset"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/status"
+	"github.com/Dynatrace/dynatrace-operator/src/dtclient"
+	"github.com/Dynatrace/dynatrace-operator/src/kubeobjects"
+	"github.com/Dynatrace/dynatrace-operator/src/kubesystem"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// ReconcileTenantTarget deploys the OneAgents reporting to the additional tenant next to the ones of the primary tenant.
+// tenantInfo is the OneAgent connection info of the tenant target. Unlike Reconcile, the status of the DynaKube isn't changed.
+func (r *OneAgentReconciler) ReconcileTenantTarget(ctx context.Context, dkState *status.DynakubeState, target string, tenantInfo *dtclient.AgentTenantInfo) (bool, error) {
+	secretUpdated, err := r.reconcileTenantTargetSecret(ctx, target, tenantInfo)
+	if err != nil {
+		return false, err
+	}
+
+	kubeSysUID, err := kubesystem.GetUID(r.apiReader)
+	if err != nil {
+		return false, err
+	}
+
+	ds, err := r.buildDaemonSet(dkState, string(kubeSysUID))
+	if err != nil {
+		return false, err
+	}
+	daemonset.ForTenantTarget(ds, r.instance, target)
+
+	dsHash, err := kubeobjects.GenerateHash(ds)
+	if err != nil {
+		return false, err
+	}
+	ds.Annotations[kubeobjects.AnnotationHash] = dsHash
+
+	if err := controllerutil.SetControllerReference(r.instance, ds, r.scheme); err != nil {
+		return false, err
+	}
+
+	dsUpdated, err := kubeobjects.CreateOrUpdateDaemonSet(r.client, log, ds)
+	return secretUpdated || dsUpdated, err
+}
+
+func (r *OneAgentReconciler) reconcileTenantTargetSecret(ctx context.Context, target string, tenantInfo *dtclient.AgentTenantInfo) (bool, error) {
+	desired := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      r.instance.OneAgentTenantSecretForTarget(target),
+			Namespace: r.instance.Namespace,
+			Labels:    map[string]string{dynatracev1beta1.LabelTenantTarget: target},
+		},
+		Type: corev1.SecretTypeOpaque,
+		Data: map[string][]byte{
+			daemonset.TenantUUIDKey:             []byte(tenantInfo.UUID),
+			daemonset.TenantTokenKey:            []byte(tenantInfo.Token),
+			daemonset.CommunicationEndpointsKey: []byte(fmt.Sprintf("{%s}", strings.Join(tenantInfo.Endpoints, ";"))),
+		},
+	}
+	if err := controllerutil.SetControllerReference(r.instance, desired, r.scheme); err != nil {
+		return false, errors.WithStack(err)
+	}
+
+	var current corev1.Secret
+	err := r.apiReader.Get(ctx, client.ObjectKeyFromObject(desired), &current)
+	if k8serrors.IsNotFound(err) {
+		log.Info("creating OneAgent tenant secret", "name", desired.Name)
+		return true, errors.WithStack(r.client.Create(ctx, desired))
+	} else if err != nil {
+		return false, errors.WithStack(err)
+	}
+
+	if kubeobjects.IsSecretEqual(&current, desired.Data) {
+		return false, nil
+	}
+	log.Info("updating OneAgent tenant secret", "name", desired.Name)
+	current.Data = desired.Data
+	return true, errors.WithStack(r.client.Update(ctx, &current))
+}
@@ -0,0 +1,252 @@
+package pendingpods
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	"github.com/Dynatrace/dynatrace-operator/src/kubeobjects"
+	"github.com/Dynatrace/dynatrace-operator/src/mapper"
+	dtwebhook "github.com/Dynatrace/dynatrace-operator/src/webhook"
+	"github.com/Dynatrace/dynatrace-operator/src/webhook/mutation"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// AnnotationRestartedAt is set on the pod template of a workload, when it is restarted to get its pods injected.
+	// A workload is only restarted once, the annotation can be removed to allow another restart.
+	AnnotationRestartedAt = "dynakube.dynatrace.com/restarted-at"
+
+	// maxReportedWorkloads limits the size of the status, in case the webhook was unavailable for a whole cluster
+	maxReportedWorkloads = 50
+
+	// scanInterval limits how often the pods of the mapped namespaces are listed, which is not served by the cache
+	scanInterval = 5 * time.Minute
+
+	// podListLimit is the page size for listing the pods of a namespace
+	podListLimit = 500
+)
+
+// Reconciler finds running pods in the namespaces mapped to a DynaKube, which were started before the namespace was
+// mapped or while the webhook was unavailable, and optionally restarts their workloads to get them injected.
+type Reconciler struct {
+	client    client.Client
+	apiReader client.Reader
+	now       metav1.Time
+}
+
+func NewReconciler(clt client.Client, apiReader client.Reader, now metav1.Time) *Reconciler {
+	return &Reconciler{
+		client:    clt,
+		apiReader: apiReader,
+		now:       now,
+	}
+}
+
+// Reconcile updates the uninjected workloads in the status of the DynaKube and restarts one of them, if enabled.
+// The namespaces are scanned at most once per scanInterval, so at most one workload is restarted per scan.
+// Returns true if the namespaces were scanned, as the timestamp of the scan is kept in the status.
+func (r *Reconciler) Reconcile(ctx context.Context, instance *dynatracev1beta1.DynaKube) (bool, error) {
+	if last := instance.Status.LastUninjectedWorkloadsScanTimestamp; last != nil && r.now.Time.Before(last.Add(scanInterval)) {
+		return false, nil
+	}
+
+	workloads, err := r.findUninjectedWorkloads(ctx, instance)
+	if err != nil {
+		return false, err
+	}
+
+	instance.Status.UninjectedWorkloads = workloads
+	instance.Status.LastUninjectedWorkloadsScanTimestamp = r.now.DeepCopy()
+
+	if !instance.Spec.RestartUninjectedWorkloads {
+		return true, nil
+	}
+
+	for _, workload := range workloads {
+		restarted, err := r.restartWorkload(ctx, workload)
+		if err != nil {
+			return true, fmt.Errorf("failed to restart %s %s in namespace %s: %w", workload.Kind, workload.Name, workload.Namespace, err)
+		} else if restarted {
+			return true, nil
+		}
+	}
+	return true, nil
+}
+
+func (r *Reconciler) findUninjectedWorkloads(ctx context.Context, instance *dynatracev1beta1.DynaKube) ([]dynatracev1beta1.UninjectedWorkloadStatus, error) {
+	namespaces, err := mapper.GetNamespacesForDynakube(ctx, r.apiReader, instance.GetName())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list mapped namespaces: %w", err)
+	}
+
+	var workloads []dynatracev1beta1.UninjectedWorkloadStatus
+	for _, namespace := range namespaces {
+		pods, err := r.listCandidatePods(ctx, namespace.Name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list pods in namespace %s: %w", namespace.Name, err)
+		}
+
+		podsPerWorkload := map[dynatracev1beta1.UninjectedWorkloadStatus]int{}
+		workloadsPerOwner := map[types.UID]dynatracev1beta1.UninjectedWorkloadStatus{}
+		for i := range pods {
+			pod := &pods[i]
+			if !needsInjection(pod, instance) {
+				continue
+			}
+			podsPerWorkload[r.findWorkload(ctx, pod, workloadsPerOwner)]++
+		}
+
+		for workload, pods := range podsPerWorkload {
+			workload.Pods = pods
+			workloads = append(workloads, workload)
+		}
+	}
+
+	sort.Slice(workloads, func(i, j int) bool {
+		if workloads[i].Namespace != workloads[j].Namespace {
+			return workloads[i].Namespace < workloads[j].Namespace
+		}
+		if workloads[i].Name != workloads[j].Name {
+			return workloads[i].Name < workloads[j].Name
+		}
+		return workloads[i].Kind < workloads[j].Kind
+	})
+
+	if len(workloads) > maxReportedWorkloads {
+		log.Info("too many uninjected workloads, status is truncated", "dynakube", instance.GetName(), "workloads", len(workloads))
+		workloads = workloads[:maxReportedWorkloads]
+	}
+	return workloads, nil
+}
+
+// listCandidatePods lists the running pods of the namespace in pages. Pods with OneAgent are labelled by the webhook,
+// so most of the injected pods are already filtered by the API server.
+func (r *Reconciler) listCandidatePods(ctx context.Context, namespace string) ([]corev1.Pod, error) {
+	notInjected, err := labels.NewRequirement(dtwebhook.LabelOneAgentInjected, selection.DoesNotExist, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var pods []corev1.Pod
+	continueToken := ""
+	for {
+		podList := &corev1.PodList{}
+		err := r.apiReader.List(ctx, podList,
+			client.InNamespace(namespace),
+			client.MatchingLabelsSelector{Selector: labels.NewSelector().Add(*notInjected)},
+			client.MatchingFieldsSelector{Selector: fields.OneTermEqualSelector("status.phase", string(corev1.PodRunning))},
+			client.Limit(podListLimit),
+			client.Continue(continueToken))
+		if err != nil {
+			return nil, err
+		}
+		pods = append(pods, podList.Items...)
+
+		continueToken = podList.Continue
+		if continueToken == "" {
+			return pods, nil
+		}
+	}
+}
+
+// findWorkload returns the workload of the pod, the root owner is only looked up once per direct owner of the pods.
+func (r *Reconciler) findWorkload(ctx context.Context, pod *corev1.Pod, workloadsPerOwner map[types.UID]dynatracev1beta1.UninjectedWorkloadStatus) dynatracev1beta1.UninjectedWorkloadStatus {
+	owner := metav1.GetControllerOf(pod)
+	if owner != nil {
+		if workload, ok := workloadsPerOwner[owner.UID]; ok {
+			return workload
+		}
+	}
+
+	name, kind, err := kubeobjects.FindRootOwnerOfPod(ctx, r.apiReader, pod, pod.Namespace)
+	workload := dynatracev1beta1.UninjectedWorkloadStatus{Namespace: pod.Namespace, Name: name, Kind: kind}
+	if err != nil {
+		log.Info("failed to find the workload of the pod", "pod", pod.Name, "namespace", pod.Namespace, "error", err)
+	} else if owner != nil {
+		workloadsPerOwner[owner.UID] = workload
+	}
+	return workload
+}
+
+// needsInjection returns true for running pods which the webhook would have injected, but didn't.
+// The injection is expected under the same conditions as in the webhook, including the container selection of the pod.
+func needsInjection(pod *corev1.Pod, instance *dynatracev1beta1.DynaKube) bool {
+	if pod.Status.Phase != corev1.PodRunning || pod.DeletionTimestamp != nil {
+		return false
+	}
+	// pods in dry-run mode are not injected on purpose
+	if len(pod.Annotations[dtwebhook.AnnotationDynatraceInjected]) > 0 || len(pod.Annotations[dtwebhook.AnnotationDryRunPatch]) > 0 {
+		return false
+	}
+
+	injectionInfo := mutation.NewInjectionInfoForPod(pod)
+	if instance.FeatureDisableMetadataEnrichment() {
+		injectionInfo.Disable(mutation.DataIngest)
+	}
+	return injectionInfo.AnyEnabled()
+}
+
+// restartWorkload triggers a rollout by annotating the pod template, like `kubectl rollout restart` does.
+// Workloads which are rolled out at the moment are skipped. Workloads which have been restarted before are not restarted
+// again, as their pods are still not injected after the restart, so another restart would not help either.
+func (r *Reconciler) restartWorkload(ctx context.Context, workload dynatracev1beta1.UninjectedWorkloadStatus) (bool, error) {
+	var obj client.Object
+	switch workload.Kind {
+	case "Deployment":
+		obj = &appsv1.Deployment{}
+	case "StatefulSet":
+		obj = &appsv1.StatefulSet{}
+	case "DaemonSet":
+		obj = &appsv1.DaemonSet{}
+	default:
+		return false, nil
+	}
+
+	if err := r.apiReader.Get(ctx, client.ObjectKey{Name: workload.Name, Namespace: workload.Namespace}, obj); err != nil {
+		return false, err
+	}
+
+	template, observedGeneration := podTemplateOf(obj)
+	if observedGeneration < obj.GetGeneration() {
+		log.Info("skipping restart of workload, rollout in progress", "kind", workload.Kind, "name", workload.Name, "namespace", workload.Namespace)
+		return false, nil
+	}
+	if restartedAt, ok := template.Annotations[AnnotationRestartedAt]; ok {
+		log.Info("not restarting workload again, its pods are not injected after a restart", "kind", workload.Kind, "name", workload.Name, "namespace", workload.Namespace, "restartedAt", restartedAt)
+		return false, nil
+	}
+
+	patch := client.MergeFrom(obj.DeepCopyObject().(client.Object))
+	if template.Annotations == nil {
+		template.Annotations = map[string]string{}
+	}
+	template.Annotations[AnnotationRestartedAt] = r.now.UTC().Format(time.RFC3339)
+
+	log.Info("restarting workload to inject its pods", "kind", workload.Kind, "name", workload.Name, "namespace", workload.Namespace, "pods", workload.Pods)
+	if err := r.client.Patch(ctx, obj, patch); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func podTemplateOf(obj client.Object) (*corev1.PodTemplateSpec, int64) {
+	switch workload := obj.(type) {
+	case *appsv1.Deployment:
+		return &workload.Spec.Template, workload.Status.ObservedGeneration
+	case *appsv1.StatefulSet:
+		return &workload.Spec.Template, workload.Status.ObservedGeneration
+	case *appsv1.DaemonSet:
+		return &workload.Spec.Template, workload.Status.ObservedGeneration
+	}
+	return nil, 0
+}
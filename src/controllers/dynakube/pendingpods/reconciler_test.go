@@ -0,0 +1,209 @@
+package pendingpods
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	"github.com/Dynatrace/dynatrace-operator/src/mapper"
+	"github.com/Dynatrace/dynatrace-operator/src/scheme/fake"
+	dtwebhook "github.com/Dynatrace/dynatrace-operator/src/webhook"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	testDynakubeName = "dynakube"
+	testNamespace    = "test-namespace"
+	testDeployment   = "test-deployment"
+	testReplicaSet   = "test-deployment-12345"
+)
+
+var testNow = metav1.NewTime(time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC))
+
+func createTestDynakube(restart bool) *dynatracev1beta1.DynaKube {
+	return &dynatracev1beta1.DynaKube{
+		ObjectMeta: metav1.ObjectMeta{Name: testDynakubeName, Namespace: "dynatrace"},
+		Spec:       dynatracev1beta1.DynaKubeSpec{RestartUninjectedWorkloads: restart},
+	}
+}
+
+func createTestObjects() []client.Object {
+	return []client.Object{
+		&corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   testNamespace,
+				Labels: map[string]string{mapper.InstanceLabel: testDynakubeName},
+			},
+		},
+		&appsv1.Deployment{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+			ObjectMeta: metav1.ObjectMeta{Name: testDeployment, Namespace: testNamespace},
+		},
+		&appsv1.ReplicaSet{
+			TypeMeta: metav1.TypeMeta{APIVersion: "apps/v1", Kind: "ReplicaSet"},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      testReplicaSet,
+				Namespace: testNamespace,
+				OwnerReferences: []metav1.OwnerReference{
+					{APIVersion: "apps/v1", Kind: "Deployment", Name: testDeployment, Controller: pointer.BoolPtr(true)},
+				},
+			},
+		},
+		createTestPod("deployment-pod-1", nil, testReplicaSet),
+		createTestPod("deployment-pod-2", nil, testReplicaSet),
+		createTestPod("injected-pod", map[string]string{dtwebhook.AnnotationDynatraceInjected: "oneagent,data-ingest"}, testReplicaSet),
+		createTestPod("disabled-pod", map[string]string{dtwebhook.AnnotationOneAgentInject: "false"}, ""),
+		createTestPod("standalone-pod", nil, ""),
+	}
+}
+
+func createTestPod(name string, annotations map[string]string, replicaSet string) *corev1.Pod {
+	pod := &corev1.Pod{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   testNamespace,
+			Annotations: annotations,
+		},
+		Status: corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+	if replicaSet != "" {
+		pod.OwnerReferences = []metav1.OwnerReference{
+			{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: replicaSet, Controller: pointer.BoolPtr(true)},
+		}
+	}
+	return pod
+}
+
+func getRestartedAt(t *testing.T, clt client.Client) string {
+	deployment := &appsv1.Deployment{}
+	require.NoError(t, clt.Get(context.TODO(), client.ObjectKey{Name: testDeployment, Namespace: testNamespace}, deployment))
+	return deployment.Spec.Template.Annotations[AnnotationRestartedAt]
+}
+
+func TestReconcile(t *testing.T) {
+	ctx := context.TODO()
+
+	t.Run(`report uninjected workloads`, func(t *testing.T) {
+		clt := fake.NewClient(createTestObjects()...)
+		instance := createTestDynakube(false)
+
+		updated, err := NewReconciler(clt, clt, testNow).Reconcile(ctx, instance)
+		require.NoError(t, err)
+		assert.True(t, updated)
+		assert.Equal(t, []dynatracev1beta1.UninjectedWorkloadStatus{
+			{Namespace: testNamespace, Name: "standalone-pod", Pods: 1},
+			{Namespace: testNamespace, Name: testDeployment, Kind: "Deployment", Pods: 2},
+		}, instance.Status.UninjectedWorkloads)
+		assert.Empty(t, getRestartedAt(t, clt))
+
+		updated, err = NewReconciler(clt, clt, testNow).Reconcile(ctx, instance)
+		require.NoError(t, err)
+		assert.False(t, updated)
+	})
+	t.Run(`scan is rate limited`, func(t *testing.T) {
+		clt := fake.NewClient(createTestObjects()...)
+		instance := createTestDynakube(false)
+
+		_, err := NewReconciler(clt, clt, testNow).Reconcile(ctx, instance)
+		require.NoError(t, err)
+		assert.Equal(t, testNow.Unix(), instance.Status.LastUninjectedWorkloadsScanTimestamp.Unix())
+		require.NoError(t, clt.Create(ctx, createTestPod("new-pod", nil, "")))
+
+		soon := metav1.NewTime(testNow.Add(time.Minute))
+		updated, err := NewReconciler(clt, clt, soon).Reconcile(ctx, instance)
+		require.NoError(t, err)
+		assert.False(t, updated)
+		assert.Len(t, instance.Status.UninjectedWorkloads, 2)
+
+		later := metav1.NewTime(testNow.Add(scanInterval + time.Minute))
+		updated, err = NewReconciler(clt, clt, later).Reconcile(ctx, instance)
+		require.NoError(t, err)
+		assert.True(t, updated)
+		assert.Len(t, instance.Status.UninjectedWorkloads, 3)
+	})
+	t.Run(`pods labelled as injected are not listed`, func(t *testing.T) {
+		labelledPod := createTestPod("labelled-pod", nil, "")
+		labelledPod.Labels = map[string]string{dtwebhook.LabelOneAgentInjected: "true"}
+		clt := fake.NewClient(append(createTestObjects(), labelledPod)...)
+		instance := createTestDynakube(false)
+
+		_, err := NewReconciler(clt, clt, testNow).Reconcile(ctx, instance)
+		require.NoError(t, err)
+		assert.Len(t, instance.Status.UninjectedWorkloads, 2)
+	})
+	t.Run(`ignore pods of unmapped namespaces`, func(t *testing.T) {
+		objects := createTestObjects()
+		objects[0].SetLabels(nil)
+		clt := fake.NewClient(objects...)
+		instance := createTestDynakube(false)
+		instance.Status.UninjectedWorkloads = []dynatracev1beta1.UninjectedWorkloadStatus{{Namespace: testNamespace, Name: "standalone-pod", Pods: 1}}
+
+		updated, err := NewReconciler(clt, clt, testNow).Reconcile(ctx, instance)
+		require.NoError(t, err)
+		assert.True(t, updated)
+		assert.Empty(t, instance.Status.UninjectedWorkloads)
+	})
+	t.Run(`restart deployment once`, func(t *testing.T) {
+		clt := fake.NewClient(createTestObjects()...)
+		instance := createTestDynakube(true)
+
+		updated, err := NewReconciler(clt, clt, testNow).Reconcile(ctx, instance)
+		require.NoError(t, err)
+		assert.True(t, updated)
+		assert.Equal(t, testNow.Format(time.RFC3339), getRestartedAt(t, clt))
+
+		later := metav1.NewTime(testNow.Add(10 * time.Minute))
+		_, err = NewReconciler(clt, clt, later).Reconcile(ctx, instance)
+		require.NoError(t, err)
+		assert.Equal(t, testNow.Format(time.RFC3339), getRestartedAt(t, clt))
+
+		// the pods are still not injected after the restart, so the deployment is not restarted again
+		muchLater := metav1.NewTime(testNow.Add(2 * time.Hour))
+		_, err = NewReconciler(clt, clt, muchLater).Reconcile(ctx, instance)
+		require.NoError(t, err)
+		assert.Equal(t, testNow.Format(time.RFC3339), getRestartedAt(t, clt))
+		assert.Len(t, instance.Status.UninjectedWorkloads, 2)
+	})
+	t.Run(`skip deployment with rollout in progress`, func(t *testing.T) {
+		objects := createTestObjects()
+		objects[1].SetGeneration(2)
+		clt := fake.NewClient(objects...)
+
+		_, err := NewReconciler(clt, clt, testNow).Reconcile(ctx, createTestDynakube(true))
+		require.NoError(t, err)
+		assert.Empty(t, getRestartedAt(t, clt))
+	})
+}
+
+func TestNeedsInjection(t *testing.T) {
+	instance := createTestDynakube(false)
+
+	assert.True(t, needsInjection(createTestPod("pod", nil, ""), instance))
+	assert.True(t, needsInjection(createTestPod("pod", map[string]string{dtwebhook.AnnotationOneAgentInject: "false", dtwebhook.AnnotationDataIngestInject: "true"}, ""), instance))
+	assert.False(t, needsInjection(createTestPod("pod", map[string]string{dtwebhook.AnnotationDynatraceInjected: "oneagent"}, ""), instance))
+	assert.False(t, needsInjection(createTestPod("pod", map[string]string{dtwebhook.AnnotationOneAgentInject: "false"}, ""), instance))
+	assert.False(t, needsInjection(createTestPod("pod", map[string]string{dtwebhook.AnnotationDryRunPatch: "[]"}, ""), instance))
+
+	noContainerSelected := createTestPod("pod", map[string]string{
+		dtwebhook.AnnotationOneAgentContainersInclude:   "other",
+		dtwebhook.AnnotationDataIngestContainersExclude: "app",
+	}, "")
+	noContainerSelected.Spec.Containers = []corev1.Container{{Name: "app"}}
+	assert.False(t, needsInjection(noContainerSelected, instance))
+
+	metadataEnrichmentDisabled := createTestDynakube(false)
+	metadataEnrichmentDisabled.Annotations = map[string]string{dynatracev1beta1.PublicAnnotationPrefix + "feature-disable-metadata-enrichment": "true"}
+	assert.False(t, needsInjection(createTestPod("pod", map[string]string{dtwebhook.AnnotationOneAgentInject: "false", dtwebhook.AnnotationDataIngestInject: "true"}, ""), metadataEnrichmentDisabled))
+
+	pendingPod := createTestPod("pod", nil, "")
+	pendingPod.Status.Phase = corev1.PodPending
+	assert.False(t, needsInjection(pendingPod, instance))
+}
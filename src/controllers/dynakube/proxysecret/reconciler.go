@@ -0,0 +1,84 @@
+package proxysecret
+
+import (
+	"context"
+	"fmt"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	"github.com/Dynatrace/dynatrace-operator/src/kubeobjects"
+	"github.com/Dynatrace/dynatrace-operator/src/secretprovider"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// Reconciler copies the proxy secret from the secret provider of a DynaKube to a Kubernetes secret,
+// as the OneAgent pods can only reference the proxy in a Kubernetes secret.
+type Reconciler struct {
+	client.Client
+	apiReader client.Reader
+	scheme    *runtime.Scheme
+	instance  *dynatracev1beta1.DynaKube
+}
+
+func NewReconciler(clt client.Client, apiReader client.Reader, scheme *runtime.Scheme, instance *dynatracev1beta1.DynaKube) *Reconciler {
+	return &Reconciler{
+		Client:    clt,
+		apiReader: apiReader,
+		scheme:    scheme,
+		instance:  instance,
+	}
+}
+
+// Reconcile creates or updates the secret returned by DynaKube.ProxySecret, if the proxy has to be copied from the secret provider.
+func (r *Reconciler) Reconcile(ctx context.Context) (bool, error) {
+	if !r.instance.NeedsProxySecretCopy() {
+		return false, nil
+	}
+
+	userSecret, err := secretprovider.NewReaderForDynakube(r.apiReader, r.instance).Get(ctx, r.instance.Spec.Proxy.ValueFrom)
+	if err != nil {
+		return false, errors.WithMessagef(err, "failed to read proxy secret %s", r.instance.Spec.Proxy.ValueFrom)
+	}
+
+	var proxySecret corev1.Secret
+	err = r.apiReader.Get(ctx, client.ObjectKey{Name: r.instance.ProxySecret(), Namespace: r.instance.Namespace}, &proxySecret)
+	if k8serrors.IsNotFound(err) {
+		log.Info("creating proxy secret", "name", r.instance.ProxySecret())
+		return true, r.createSecret(ctx, userSecret.Data)
+	} else if err != nil {
+		return false, errors.WithStack(err)
+	}
+
+	if kubeobjects.IsSecretEqual(&proxySecret, userSecret.Data) {
+		return false, nil
+	}
+	log.Info("updating proxy secret", "name", proxySecret.Name)
+	proxySecret.Data = userSecret.Data
+	if err := r.Update(ctx, &proxySecret); err != nil {
+		return false, fmt.Errorf("failed to update secret %s: %w", proxySecret.Name, err)
+	}
+	return true, nil
+}
+
+func (r *Reconciler) createSecret(ctx context.Context, data map[string][]byte) error {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      r.instance.ProxySecret(),
+			Namespace: r.instance.Namespace,
+		},
+		Type: corev1.SecretTypeOpaque,
+		Data: data,
+	}
+	if err := controllerutil.SetControllerReference(r.instance, secret, r.scheme); err != nil {
+		return errors.WithStack(err)
+	}
+	if err := r.Create(ctx, secret); err != nil {
+		return fmt.Errorf("failed to create secret '%s': %w", secret.Name, err)
+	}
+	return nil
+}
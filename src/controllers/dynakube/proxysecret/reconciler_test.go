@@ -0,0 +1,76 @@
+package proxysecret
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	"github.com/Dynatrace/dynatrace-operator/src/scheme"
+	"github.com/Dynatrace/dynatrace-operator/src/scheme/fake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	testName        = "dynakube"
+	testNamespace   = "dynatrace"
+	testProxySecret = "proxy"
+	testProxy       = "http://proxy.example.com:3128"
+)
+
+func createTestDynakube(dir string) *dynatracev1beta1.DynaKube {
+	return &dynatracev1beta1.DynaKube{
+		ObjectMeta: metav1.ObjectMeta{Name: testName, Namespace: testNamespace},
+		Spec: dynatracev1beta1.DynaKubeSpec{
+			Proxy: &dynatracev1beta1.DynaKubeProxy{ValueFrom: testProxySecret},
+			SecretProvider: &dynatracev1beta1.SecretProviderSpec{
+				File: &dynatracev1beta1.FileSecretProviderSpec{Path: dir, Format: dynatracev1beta1.FileSecretFormatEnv},
+			},
+		},
+	}
+}
+
+func TestReconcile(t *testing.T) {
+	t.Run(`proxy is copied from secret provider`, func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, testProxySecret+".env"), []byte("proxy="+testProxy), 0644))
+		clt := fake.NewClient()
+		dynakube := createTestDynakube(dir)
+
+		upd, err := NewReconciler(clt, clt, scheme.Scheme, dynakube).Reconcile(context.TODO())
+
+		require.NoError(t, err)
+		assert.True(t, upd)
+
+		var proxySecret corev1.Secret
+		require.NoError(t, clt.Get(context.TODO(), client.ObjectKey{Name: dynakube.ProxySecret(), Namespace: testNamespace}, &proxySecret))
+		assert.Equal(t, testProxy, string(proxySecret.Data["proxy"]))
+
+		upd, err = NewReconciler(clt, clt, scheme.Scheme, dynakube).Reconcile(context.TODO())
+
+		require.NoError(t, err)
+		assert.False(t, upd)
+	})
+	t.Run(`missing proxy secret`, func(t *testing.T) {
+		clt := fake.NewClient()
+
+		_, err := NewReconciler(clt, clt, scheme.Scheme, createTestDynakube(t.TempDir())).Reconcile(context.TODO())
+
+		assert.Error(t, err)
+	})
+	t.Run(`nothing to copy without secret provider`, func(t *testing.T) {
+		clt := fake.NewClient()
+		dynakube := createTestDynakube("")
+		dynakube.Spec.SecretProvider = nil
+
+		upd, err := NewReconciler(clt, clt, scheme.Scheme, dynakube).Reconcile(context.TODO())
+
+		require.NoError(t, err)
+		assert.False(t, upd)
+	})
+}
@@ -0,0 +1,24 @@
+package settings
+
+import (
+	"time"
+
+	"github.com/Dynatrace/dynatrace-operator/src/logger"
+)
+
+const (
+	// Finalizer is set on DynaKubes which have written settings objects to the tenant,
+	// so the objects are deleted before the DynaKube is removed.
+	Finalizer = "dynatrace.com/settings-objects"
+
+	// CleanupTimeout is how long the deletion of the settings objects is retried after the DynaKube was deleted,
+	// before they are left on the tenant, so an unreachable tenant doesn't block the deletion of the DynaKube.
+	CleanupTimeout = time.Hour
+
+	// OrphanedSettingsEvent is recorded on a deleted DynaKube with the ids of the settings objects left on the tenant
+	OrphanedSettingsEvent = "OrphanedSettingsObjects"
+)
+
+var (
+	log = logger.NewDTLogger().WithName("dynakube-settings")
+)
@@ -0,0 +1,292 @@
+package settings
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/status"
+	"github.com/Dynatrace/dynatrace-operator/src/dtclient"
+	"github.com/Dynatrace/dynatrace-operator/src/kubeobjects"
+	"github.com/pkg/errors"
+)
+
+// driftCheckInterval limits how often the objects are compared to the objects on the tenant,
+// changes of spec.settings are written right away
+const driftCheckInterval = 5 * time.Minute
+
+// Reconciler writes the Settings 2.0 objects of spec.settings to the tenant of the apiUrl. Objects changed or deleted on the tenant
+// are written again, objects removed from spec.settings are deleted. The object ids are kept in the status of the DynaKube.
+type Reconciler struct {
+	dtc dtclient.Client
+}
+
+// desiredObject is an entry of spec.settings with the default scope applied
+type desiredObject struct {
+	name   string
+	object dtclient.SettingsObject
+	hash   string
+	// err is set if the default scope could not be determined
+	err error
+}
+
+func NewReconciler(dtc dtclient.Client) *Reconciler {
+	return &Reconciler{dtc: dtc}
+}
+
+// Reconcile creates, updates and deletes the settings objects and sets the settings condition.
+// Failing to write an object doesn't stop the others from being written, the failures are returned together.
+func (r *Reconciler) Reconcile(dkState *status.DynakubeState) error {
+	instance := dkState.Instance
+	settingsStatus := &instance.Status.Settings
+	if len(instance.Spec.Settings) == 0 && len(settingsStatus.Objects) == 0 {
+		if settingsStatus.LastCheckTimestamp != nil {
+			settingsStatus.LastCheckTimestamp = nil
+			dkState.Updated = true
+		}
+		dkState.RemoveCondition(dynatracev1beta1.SettingsConditionType)
+		return nil
+	}
+
+	desired, err := r.desiredObjects(instance)
+	if err != nil {
+		dkState.SetReconcileCondition(dynatracev1beta1.SettingsConditionType, err)
+		return err
+	}
+	if !isChanged(settingsStatus.Objects, desired) && !dkState.IsOutdated(settingsStatus.LastCheckTimestamp, driftCheckInterval) {
+		return nil
+	}
+
+	current := make(map[string]dynatracev1beta1.SettingsObjectStatus, len(settingsStatus.Objects))
+	for _, objectStatus := range settingsStatus.Objects {
+		current[objectStatus.Name] = objectStatus
+	}
+
+	var objects []dynatracev1beta1.SettingsObjectStatus
+	var failures []string
+	for _, object := range desired {
+		objectStatus, exists := current[object.name]
+		delete(current, object.name)
+
+		var reconciled dynatracev1beta1.SettingsObjectStatus
+		err := object.err
+		if err == nil {
+			reconciled, err = r.reconcileObject(object, objectStatus, exists)
+		}
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %s", object.name, err.Error()))
+			// the object id is kept, so the object is updated instead of created again with the next reconcile
+			if exists {
+				objects = append(objects, objectStatus)
+			}
+			continue
+		}
+		objects = append(objects, reconciled)
+	}
+
+	for _, objectStatus := range settingsStatus.Objects {
+		if _, removed := current[objectStatus.Name]; !removed {
+			continue
+		}
+		if err := r.dtc.DeleteSettingsObject(objectStatus.ObjectId); err != nil {
+			failures = append(failures, fmt.Sprintf("%s: failed to delete object %s: %s", objectStatus.Name, objectStatus.ObjectId, err.Error()))
+			objects = append(objects, objectStatus)
+			continue
+		}
+		log.Info("deleted settings object", "dynakube", instance.Name, "name", objectStatus.Name, "objectId", objectStatus.ObjectId)
+	}
+
+	settingsStatus.Objects = objects
+	settingsStatus.LastCheckTimestamp = dkState.Now.DeepCopy()
+	dkState.Updated = true
+
+	if len(failures) > 0 {
+		err = errors.Errorf("failed to reconcile settings objects: %s", strings.Join(failures, "; "))
+	}
+	if len(instance.Spec.Settings) == 0 && err == nil {
+		settingsStatus.LastCheckTimestamp = nil
+		dkState.RemoveCondition(dynatracev1beta1.SettingsConditionType)
+		return nil
+	}
+	dkState.SetReconcileCondition(dynatracev1beta1.SettingsConditionType, err)
+	return err
+}
+
+// Cleanup deletes every settings object written to the tenant, it's called once the DynaKube is deleted.
+// Objects which couldn't be deleted are kept in the status and returned as error, so they are deleted with the next attempt.
+func (r *Reconciler) Cleanup(instance *dynatracev1beta1.DynaKube) error {
+	var objects []dynatracev1beta1.SettingsObjectStatus
+	var failures []string
+	for _, objectStatus := range instance.Status.Settings.Objects {
+		if err := r.dtc.DeleteSettingsObject(objectStatus.ObjectId); err != nil {
+			failures = append(failures, fmt.Sprintf("%s: failed to delete object %s: %s", objectStatus.Name, objectStatus.ObjectId, err.Error()))
+			objects = append(objects, objectStatus)
+			continue
+		}
+		log.Info("deleted settings object", "dynakube", instance.Name, "name", objectStatus.Name, "objectId", objectStatus.ObjectId)
+	}
+	instance.Status.Settings.Objects = objects
+
+	if len(failures) > 0 {
+		return errors.Errorf("failed to delete settings objects: %s", strings.Join(failures, "; "))
+	}
+	return nil
+}
+
+// reconcileObject creates the object if it doesn't exist on the tenant, or updates it if it differs from the desired object.
+// Objects can't be moved to another schema or scope, so they are created again in that case.
+func (r *Reconciler) reconcileObject(desired desiredObject, objectStatus dynatracev1beta1.SettingsObjectStatus, exists bool) (dynatracev1beta1.SettingsObjectStatus, error) {
+	if exists && (objectStatus.SchemaId != desired.object.SchemaId || objectStatus.Scope != desired.object.Scope) {
+		if err := r.dtc.DeleteSettingsObject(objectStatus.ObjectId); err != nil {
+			return objectStatus, errors.WithMessagef(err, "failed to delete object %s of previous schema or scope", objectStatus.ObjectId)
+		}
+		exists = false
+	}
+
+	var actual *dtclient.SettingsObject
+	if exists {
+		var err error
+		actual, err = r.dtc.GetSettingsObject(objectStatus.ObjectId)
+		if err != nil {
+			return objectStatus, errors.WithMessagef(err, "failed to get object %s", objectStatus.ObjectId)
+		}
+	}
+
+	objectId := objectStatus.ObjectId
+	if actual == nil {
+		if exists {
+			log.Info("settings object was deleted on the tenant, creating it again", "name", desired.name, "objectId", objectId)
+		}
+		var err error
+		objectId, err = r.dtc.CreateSettingsObject(desired.object)
+		if err != nil {
+			return objectStatus, errors.WithMessage(err, "failed to create object")
+		}
+		log.Info("created settings object", "name", desired.name, "schemaId", desired.object.SchemaId, "objectId", objectId)
+	} else if objectStatus.Hash != desired.hash || !containsValue(actual.Value, desired.object.Value) {
+		if objectStatus.Hash == desired.hash {
+			log.Info("settings object was changed on the tenant, reverting it", "name", desired.name, "objectId", objectId)
+		}
+		if err := r.dtc.UpdateSettingsObject(objectId, desired.object); err != nil {
+			return objectStatus, errors.WithMessagef(err, "failed to update object %s", objectId)
+		}
+		log.Info("updated settings object", "name", desired.name, "objectId", objectId)
+	}
+
+	return dynatracev1beta1.SettingsObjectStatus{
+		Name:     desired.name,
+		ObjectId: objectId,
+		SchemaId: desired.object.SchemaId,
+		Scope:    desired.object.Scope,
+		Hash:     desired.hash,
+	}, nil
+}
+
+// desiredObjects applies the default scope, which is the newest Kubernetes cluster entity of the cluster.
+// Objects with the default scope fail until the ActiveGate has created the entity.
+func (r *Reconciler) desiredObjects(instance *dynatracev1beta1.DynaKube) ([]desiredObject, error) {
+	var clusterScope string
+	var clusterScopeErr error
+	clusterScopeResolved := false
+
+	desired := make([]desiredObject, 0, len(instance.Spec.Settings))
+	for _, setting := range instance.Spec.Settings {
+		object := desiredObject{
+			name: setting.Name,
+			object: dtclient.SettingsObject{
+				SchemaId:      setting.SchemaId,
+				SchemaVersion: setting.SchemaVersion,
+				Scope:         setting.Scope,
+				Value:         json.RawMessage(setting.Value.Raw),
+			},
+		}
+
+		if object.object.Scope == "" {
+			if !clusterScopeResolved {
+				clusterScope, clusterScopeErr = r.clusterScope(instance.Status.KubeSystemUUID)
+				clusterScopeResolved = true
+			}
+			object.object.Scope = clusterScope
+			object.err = clusterScopeErr
+		}
+
+		hash, err := kubeobjects.GenerateHash(object.object)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		object.hash = hash
+		desired = append(desired, object)
+	}
+	return desired, nil
+}
+
+func (r *Reconciler) clusterScope(kubeSystemUUID string) (string, error) {
+	entities, err := r.dtc.GetMonitoredEntitiesForKubeSystemUUID(kubeSystemUUID)
+	if err != nil {
+		return "", errors.WithMessage(err, "failed to find Kubernetes cluster entity for default scope")
+	}
+	scope := dtclient.NewestMonitoredEntity(entities)
+	if scope == "" {
+		return "", errors.New("no Kubernetes cluster entity for default scope yet, it's created once the ActiveGate monitors the Kubernetes API")
+	}
+	return scope, nil
+}
+
+// isChanged returns true if objects were added to, removed from or changed in spec.settings since they were written to the tenant.
+func isChanged(objects []dynatracev1beta1.SettingsObjectStatus, desired []desiredObject) bool {
+	if len(objects) != len(desired) {
+		return true
+	}
+	hashes := make(map[string]string, len(objects))
+	for _, objectStatus := range objects {
+		hashes[objectStatus.Name] = objectStatus.Hash
+	}
+	for _, object := range desired {
+		if hash, ok := hashes[object.name]; !ok || hash != object.hash || object.err != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// containsValue returns true if every field of the desired value has the same value in the actual value.
+// The tenant returns every field of the schema, so fields left out of the desired value are not compared.
+func containsValue(actual, desired json.RawMessage) bool {
+	var actualValue, desiredValue interface{}
+	if json.Unmarshal(actual, &actualValue) != nil || json.Unmarshal(desired, &desiredValue) != nil {
+		return false
+	}
+	return contains(actualValue, desiredValue)
+}
+
+func contains(actual, desired interface{}) bool {
+	switch desiredValue := desired.(type) {
+	case map[string]interface{}:
+		actualValue, ok := actual.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		for field, value := range desiredValue {
+			if !contains(actualValue[field], value) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		actualValue, ok := actual.([]interface{})
+		if !ok || len(actualValue) != len(desiredValue) {
+			return false
+		}
+		for i := range desiredValue {
+			if !contains(actualValue[i], desiredValue[i]) {
+				return false
+			}
+		}
+		return true
+	default:
+		return reflect.DeepEqual(actual, desired)
+	}
+}
@@ -0,0 +1,291 @@
+package settings
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/status"
+	"github.com/Dynatrace/dynatrace-operator/src/dtclient"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	testKubeSystemUUID = "test-kube-system-uuid"
+	testClusterEntity  = "KUBERNETES_CLUSTER-0123456789ABCDEF"
+	testSchemaId       = "builtin:logmonitoring.log-storage-settings"
+	testScope          = "HOST-0123456789ABCDEF"
+	testObjectId       = "test-object-id"
+)
+
+func newDynakube(settings ...dynatracev1beta1.SettingsObjectSpec) *dynatracev1beta1.DynaKube {
+	return &dynatracev1beta1.DynaKube{
+		ObjectMeta: metav1.ObjectMeta{Name: "dynakube", Namespace: "dynatrace"},
+		Spec:       dynatracev1beta1.DynaKubeSpec{Settings: settings},
+		Status:     dynatracev1beta1.DynaKubeStatus{KubeSystemUUID: testKubeSystemUUID},
+	}
+}
+
+func newSettingsObject(name, scope, value string) dynatracev1beta1.SettingsObjectSpec {
+	return dynatracev1beta1.SettingsObjectSpec{
+		Name:     name,
+		SchemaId: testSchemaId,
+		Scope:    scope,
+		Value:    runtime.RawExtension{Raw: []byte(value)},
+	}
+}
+
+// reconcileOnce writes the objects of the dynakube with a fresh mock, so the status holds the object ids of the created objects
+func reconcileOnce(t *testing.T, dynakube *dynatracev1beta1.DynaKube) {
+	dtc := &dtclient.MockDynatraceClient{}
+	dtc.On("CreateSettingsObject", mock.Anything).Return(testObjectId, nil)
+	dkState := status.NewDynakubeState(dynakube)
+	require.NoError(t, NewReconciler(dtc).Reconcile(dkState))
+}
+
+func TestReconcile(t *testing.T) {
+	t.Run(`no settings objects`, func(t *testing.T) {
+		dtc := &dtclient.MockDynatraceClient{}
+		dkState := status.NewDynakubeState(newDynakube())
+
+		require.NoError(t, NewReconciler(dtc).Reconcile(dkState))
+		assert.False(t, dkState.Updated)
+		assert.Empty(t, dkState.Instance.Status.Conditions)
+		dtc.AssertExpectations(t)
+	})
+	t.Run(`creates settings objects`, func(t *testing.T) {
+		dtc := &dtclient.MockDynatraceClient{}
+		dtc.On("CreateSettingsObject", dtclient.SettingsObject{
+			SchemaId: testSchemaId,
+			Scope:    testScope,
+			Value:    json.RawMessage(`{"enabled":true}`),
+		}).Return(testObjectId, nil)
+		dkState := status.NewDynakubeState(newDynakube(newSettingsObject("log-storage", testScope, `{"enabled":true}`)))
+
+		require.NoError(t, NewReconciler(dtc).Reconcile(dkState))
+		assert.True(t, dkState.Updated)
+		objects := dkState.Instance.Status.Settings.Objects
+		require.Len(t, objects, 1)
+		assert.Equal(t, "log-storage", objects[0].Name)
+		assert.Equal(t, testObjectId, objects[0].ObjectId)
+		assert.Equal(t, testScope, objects[0].Scope)
+		assert.NotEmpty(t, objects[0].Hash)
+		assert.NotNil(t, dkState.Instance.Status.Settings.LastCheckTimestamp)
+		condition := meta.FindStatusCondition(dkState.Instance.Status.Conditions, dynatracev1beta1.SettingsConditionType)
+		require.NotNil(t, condition)
+		assert.Equal(t, metav1.ConditionTrue, condition.Status)
+		dtc.AssertExpectations(t)
+	})
+	t.Run(`defaults scope to the cluster entity`, func(t *testing.T) {
+		dtc := &dtclient.MockDynatraceClient{}
+		dtc.On("GetMonitoredEntitiesForKubeSystemUUID", testKubeSystemUUID).Return([]dtclient.MonitoredEntity{
+			{EntityId: "KUBERNETES_CLUSTER-OLD", LastSeenTms: 1},
+			{EntityId: testClusterEntity, LastSeenTms: 2},
+		}, nil)
+		dtc.On("CreateSettingsObject", mock.MatchedBy(func(object dtclient.SettingsObject) bool {
+			return object.Scope == testClusterEntity
+		})).Return(testObjectId, nil)
+		dkState := status.NewDynakubeState(newDynakube(newSettingsObject("log-storage", "", `{"enabled":true}`)))
+
+		require.NoError(t, NewReconciler(dtc).Reconcile(dkState))
+		assert.Equal(t, testClusterEntity, dkState.Instance.Status.Settings.Objects[0].Scope)
+		dtc.AssertExpectations(t)
+	})
+	t.Run(`fails without cluster entity but writes other objects`, func(t *testing.T) {
+		dtc := &dtclient.MockDynatraceClient{}
+		dtc.On("GetMonitoredEntitiesForKubeSystemUUID", testKubeSystemUUID).Return([]dtclient.MonitoredEntity{}, nil)
+		dtc.On("CreateSettingsObject", mock.Anything).Return(testObjectId, nil)
+		dkState := status.NewDynakubeState(newDynakube(
+			newSettingsObject("cluster", "", `{"enabled":true}`),
+			newSettingsObject("host", testScope, `{"enabled":true}`),
+		))
+
+		err := NewReconciler(dtc).Reconcile(dkState)
+		require.Error(t, err)
+		objects := dkState.Instance.Status.Settings.Objects
+		require.Len(t, objects, 1)
+		assert.Equal(t, "host", objects[0].Name)
+		condition := meta.FindStatusCondition(dkState.Instance.Status.Conditions, dynatracev1beta1.SettingsConditionType)
+		require.NotNil(t, condition)
+		assert.Equal(t, metav1.ConditionFalse, condition.Status)
+		dtc.AssertNumberOfCalls(t, "CreateSettingsObject", 1)
+	})
+	t.Run(`skips drift check within interval`, func(t *testing.T) {
+		dynakube := newDynakube(newSettingsObject("log-storage", testScope, `{"enabled":true}`))
+		reconcileOnce(t, dynakube)
+
+		dtc := &dtclient.MockDynatraceClient{}
+		dkState := status.NewDynakubeState(dynakube)
+		require.NoError(t, NewReconciler(dtc).Reconcile(dkState))
+		assert.False(t, dkState.Updated)
+		dtc.AssertExpectations(t)
+	})
+	t.Run(`keeps unchanged object after interval`, func(t *testing.T) {
+		dynakube := newDynakube(newSettingsObject("log-storage", testScope, `{"enabled":true}`))
+		reconcileOnce(t, dynakube)
+
+		dtc := &dtclient.MockDynatraceClient{}
+		dtc.On("GetSettingsObject", testObjectId).Return(&dtclient.SettingsObject{
+			ObjectId: testObjectId,
+			SchemaId: testSchemaId,
+			Scope:    testScope,
+			Value:    json.RawMessage(`{"enabled":true,"retentionDays":35}`),
+		}, nil)
+		dkState := status.NewDynakubeState(dynakube)
+		dkState.Now = metav1.NewTime(dkState.Now.Add(driftCheckInterval + time.Minute))
+
+		require.NoError(t, NewReconciler(dtc).Reconcile(dkState))
+		assert.Equal(t, dkState.Now, *dynakube.Status.Settings.LastCheckTimestamp)
+		dtc.AssertExpectations(t)
+		dtc.AssertNotCalled(t, "UpdateSettingsObject", mock.Anything, mock.Anything)
+	})
+	t.Run(`reverts object changed on the tenant`, func(t *testing.T) {
+		dynakube := newDynakube(newSettingsObject("log-storage", testScope, `{"enabled":true}`))
+		reconcileOnce(t, dynakube)
+
+		dtc := &dtclient.MockDynatraceClient{}
+		dtc.On("GetSettingsObject", testObjectId).Return(&dtclient.SettingsObject{
+			ObjectId: testObjectId,
+			Value:    json.RawMessage(`{"enabled":false}`),
+		}, nil)
+		dtc.On("UpdateSettingsObject", testObjectId, mock.Anything).Return(nil)
+		dkState := status.NewDynakubeState(dynakube)
+		dkState.Now = metav1.NewTime(dkState.Now.Add(driftCheckInterval + time.Minute))
+
+		require.NoError(t, NewReconciler(dtc).Reconcile(dkState))
+		dtc.AssertExpectations(t)
+	})
+	t.Run(`updates object changed in the spec`, func(t *testing.T) {
+		dynakube := newDynakube(newSettingsObject("log-storage", testScope, `{"enabled":true}`))
+		reconcileOnce(t, dynakube)
+		previousHash := dynakube.Status.Settings.Objects[0].Hash
+		dynakube.Spec.Settings[0].Value.Raw = []byte(`{"enabled":false}`)
+
+		dtc := &dtclient.MockDynatraceClient{}
+		dtc.On("GetSettingsObject", testObjectId).Return(&dtclient.SettingsObject{
+			ObjectId: testObjectId,
+			Value:    json.RawMessage(`{"enabled":true}`),
+		}, nil)
+		dtc.On("UpdateSettingsObject", testObjectId, mock.MatchedBy(func(object dtclient.SettingsObject) bool {
+			return string(object.Value) == `{"enabled":false}`
+		})).Return(nil)
+		dkState := status.NewDynakubeState(dynakube)
+
+		require.NoError(t, NewReconciler(dtc).Reconcile(dkState))
+		assert.NotEqual(t, previousHash, dynakube.Status.Settings.Objects[0].Hash)
+		dtc.AssertExpectations(t)
+	})
+	t.Run(`keeps status of object which failed to update`, func(t *testing.T) {
+		dynakube := newDynakube(newSettingsObject("log-storage", testScope, `{"enabled":true}`))
+		reconcileOnce(t, dynakube)
+		previous := dynakube.Status.Settings.Objects[0]
+		dynakube.Spec.Settings[0].Value.Raw = []byte(`{"enabled":false}`)
+
+		dtc := &dtclient.MockDynatraceClient{}
+		dtc.On("GetSettingsObject", testObjectId).Return(&dtclient.SettingsObject{ObjectId: testObjectId, Value: json.RawMessage(`{}`)}, nil)
+		dtc.On("UpdateSettingsObject", testObjectId, mock.Anything).Return(fmt.Errorf("constraint violation"))
+		dkState := status.NewDynakubeState(dynakube)
+
+		require.Error(t, NewReconciler(dtc).Reconcile(dkState))
+		assert.Equal(t, []dynatracev1beta1.SettingsObjectStatus{previous}, dynakube.Status.Settings.Objects)
+	})
+	t.Run(`creates object deleted on the tenant`, func(t *testing.T) {
+		dynakube := newDynakube(newSettingsObject("log-storage", testScope, `{"enabled":true}`))
+		reconcileOnce(t, dynakube)
+
+		dtc := &dtclient.MockDynatraceClient{}
+		dtc.On("GetSettingsObject", testObjectId).Return((*dtclient.SettingsObject)(nil), nil)
+		dtc.On("CreateSettingsObject", mock.Anything).Return("new-object-id", nil)
+		dkState := status.NewDynakubeState(dynakube)
+		dkState.Now = metav1.NewTime(dkState.Now.Add(driftCheckInterval + time.Minute))
+
+		require.NoError(t, NewReconciler(dtc).Reconcile(dkState))
+		assert.Equal(t, "new-object-id", dynakube.Status.Settings.Objects[0].ObjectId)
+		dtc.AssertExpectations(t)
+	})
+	t.Run(`creates object again when scope changes`, func(t *testing.T) {
+		dynakube := newDynakube(newSettingsObject("log-storage", testScope, `{"enabled":true}`))
+		reconcileOnce(t, dynakube)
+		dynakube.Spec.Settings[0].Scope = dynatracev1beta1.SettingsScopeEnvironment
+
+		dtc := &dtclient.MockDynatraceClient{}
+		dtc.On("DeleteSettingsObject", testObjectId).Return(nil)
+		dtc.On("CreateSettingsObject", mock.MatchedBy(func(object dtclient.SettingsObject) bool {
+			return object.Scope == dynatracev1beta1.SettingsScopeEnvironment
+		})).Return("new-object-id", nil)
+		dkState := status.NewDynakubeState(dynakube)
+
+		require.NoError(t, NewReconciler(dtc).Reconcile(dkState))
+		assert.Equal(t, "new-object-id", dynakube.Status.Settings.Objects[0].ObjectId)
+		dtc.AssertExpectations(t)
+	})
+	t.Run(`deletes objects removed from the spec`, func(t *testing.T) {
+		dynakube := newDynakube(newSettingsObject("log-storage", testScope, `{"enabled":true}`))
+		reconcileOnce(t, dynakube)
+		dynakube.Spec.Settings = nil
+
+		dtc := &dtclient.MockDynatraceClient{}
+		dtc.On("DeleteSettingsObject", testObjectId).Return(nil)
+		dkState := status.NewDynakubeState(dynakube)
+
+		require.NoError(t, NewReconciler(dtc).Reconcile(dkState))
+		assert.Empty(t, dynakube.Status.Settings.Objects)
+		assert.Nil(t, dynakube.Status.Settings.LastCheckTimestamp)
+		assert.Nil(t, meta.FindStatusCondition(dkState.Instance.Status.Conditions, dynatracev1beta1.SettingsConditionType))
+		dtc.AssertExpectations(t)
+	})
+	t.Run(`keeps objects which failed to be deleted`, func(t *testing.T) {
+		dynakube := newDynakube(newSettingsObject("log-storage", testScope, `{"enabled":true}`))
+		reconcileOnce(t, dynakube)
+		dynakube.Spec.Settings = nil
+
+		dtc := &dtclient.MockDynatraceClient{}
+		dtc.On("DeleteSettingsObject", testObjectId).Return(fmt.Errorf("unavailable"))
+		dkState := status.NewDynakubeState(dynakube)
+
+		require.Error(t, NewReconciler(dtc).Reconcile(dkState))
+		assert.Len(t, dynakube.Status.Settings.Objects, 1)
+	})
+}
+
+func TestCleanup(t *testing.T) {
+	t.Run(`deletes every object`, func(t *testing.T) {
+		dynakube := newDynakube(newSettingsObject("log-storage", testScope, `{"enabled":true}`))
+		reconcileOnce(t, dynakube)
+
+		dtc := &dtclient.MockDynatraceClient{}
+		dtc.On("DeleteSettingsObject", testObjectId).Return(nil)
+
+		require.NoError(t, NewReconciler(dtc).Cleanup(dynakube))
+		assert.Empty(t, dynakube.Status.Settings.Objects)
+		dtc.AssertExpectations(t)
+	})
+	t.Run(`keeps objects which failed to be deleted`, func(t *testing.T) {
+		dynakube := newDynakube(newSettingsObject("log-storage", testScope, `{"enabled":true}`))
+		reconcileOnce(t, dynakube)
+
+		dtc := &dtclient.MockDynatraceClient{}
+		dtc.On("DeleteSettingsObject", testObjectId).Return(fmt.Errorf("unavailable"))
+
+		require.Error(t, NewReconciler(dtc).Cleanup(dynakube))
+		assert.Len(t, dynakube.Status.Settings.Objects, 1)
+	})
+}
+
+func TestContainsValue(t *testing.T) {
+	actual := json.RawMessage(`{"enabled":true,"retentionDays":35,"rules":[{"name":"a","enabled":true}]}`)
+
+	assert.True(t, containsValue(actual, json.RawMessage(`{"enabled":true}`)))
+	assert.True(t, containsValue(actual, json.RawMessage(`{"rules":[{"name":"a"}]}`)))
+	assert.False(t, containsValue(actual, json.RawMessage(`{"enabled":false}`)))
+	assert.False(t, containsValue(actual, json.RawMessage(`{"retentionDays":10}`)))
+	assert.False(t, containsValue(actual, json.RawMessage(`{"rules":[]}`)))
+	assert.False(t, containsValue(actual, json.RawMessage(`{"missing":true}`)))
+}
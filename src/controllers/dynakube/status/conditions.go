@@ -0,0 +1,159 @@
+package status
+
+import (
+	"fmt"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// componentConditionTypes are the conditions of the components reconciled by the DynaKube controller
+var componentConditionTypes = []string{
+	dynatracev1beta1.IstioConditionType,
+	dynatracev1beta1.NetworkPolicyConditionType,
+	dynatracev1beta1.PullSecretConditionType,
+	dynatracev1beta1.SettingsConditionType,
+	dynatracev1beta1.CSIDriverConditionType,
+	dynatracev1beta1.OneAgentConditionType,
+	dynatracev1beta1.OneAgentRolloutConditionType,
+	dynatracev1beta1.ActiveGateConditionType,
+	dynatracev1beta1.KubeMonActiveGateConditionType,
+	dynatracev1beta1.RoutingActiveGateConditionType,
+	dynatracev1beta1.AutomaticApiMonitoringConditionType,
+}
+
+// SetCondition sets the condition on the DynaKube and marks it as updated, if the condition changed.
+// The observed generation of the condition is set to the generation of the DynaKube.
+func (dkState *DynakubeState) SetCondition(condition metav1.Condition) {
+	condition.ObservedGeneration = dkState.Instance.Generation
+	dkState.markReconciled(condition.Type)
+
+	oldCondition := meta.FindStatusCondition(dkState.Instance.Status.Conditions, condition.Type)
+	if oldCondition != nil &&
+		oldCondition.Status == condition.Status &&
+		oldCondition.Reason == condition.Reason &&
+		oldCondition.Message == condition.Message &&
+		oldCondition.ObservedGeneration == condition.ObservedGeneration {
+		return
+	}
+
+	meta.SetStatusCondition(&dkState.Instance.Status.Conditions, condition)
+	dkState.Updated = true
+}
+
+// SetReconcileCondition sets the condition of a component without pods to ready, or to failed if reconciling its objects returned an error.
+func (dkState *DynakubeState) SetReconcileCondition(conditionType string, err error) {
+	if err != nil {
+		dkState.SetCondition(metav1.Condition{
+			Type:    conditionType,
+			Status:  metav1.ConditionFalse,
+			Reason:  dynatracev1beta1.ReasonReconcileFailed,
+			Message: err.Error(),
+		})
+		return
+	}
+	dkState.SetCondition(metav1.Condition{
+		Type:    conditionType,
+		Status:  metav1.ConditionTrue,
+		Reason:  dynatracev1beta1.ReasonReady,
+		Message: "Ready",
+	})
+}
+
+// RemoveCondition removes the condition of a component which is not enabled for the DynaKube.
+func (dkState *DynakubeState) RemoveCondition(conditionType string) {
+	dkState.markReconciled(conditionType)
+	if meta.FindStatusCondition(dkState.Instance.Status.Conditions, conditionType) != nil {
+		meta.RemoveStatusCondition(&dkState.Instance.Status.Conditions, conditionType)
+		dkState.Updated = true
+	}
+}
+
+// SetNotReconciledConditions sets the conditions of the components, which the reconcile stopped before, to unknown.
+// Otherwise their last reported state, e.g. ready, would be taken as current although the components weren't checked.
+func (dkState *DynakubeState) SetNotReconciledConditions(cause string) {
+	for _, conditionType := range componentConditionTypes {
+		if dkState.reconciledConditions[conditionType] || meta.FindStatusCondition(dkState.Instance.Status.Conditions, conditionType) == nil {
+			continue
+		}
+		dkState.SetCondition(metav1.Condition{
+			Type:    conditionType,
+			Status:  metav1.ConditionUnknown,
+			Reason:  dynatracev1beta1.ReasonNotReconciled,
+			Message: fmt.Sprintf("Not reconciled: %s", cause),
+		})
+	}
+}
+
+// IsRolloutInProgress returns true if the pods of a component are not updated or not ready yet.
+func IsRolloutInProgress(instance *dynatracev1beta1.DynaKube) bool {
+	for _, conditionType := range componentConditionTypes {
+		if condition := meta.FindStatusCondition(instance.Status.Conditions, conditionType); condition != nil &&
+			condition.Reason == dynatracev1beta1.ReasonRolloutInProgress {
+			return true
+		}
+	}
+	return false
+}
+
+func (dkState *DynakubeState) markReconciled(conditionType string) {
+	if dkState.reconciledConditions == nil {
+		dkState.reconciledConditions = map[string]bool{}
+	}
+	dkState.reconciledConditions[conditionType] = true
+}
+
+// DaemonSetCondition returns a ready condition if the controller has observed the latest spec of the daemonset
+// and its pods on all scheduled nodes are updated and available.
+func DaemonSetCondition(conditionType string, ds *appsv1.DaemonSet) metav1.Condition {
+	desired := ds.Status.DesiredNumberScheduled
+	ready := ds.Status.ObservedGeneration >= ds.Generation &&
+		ds.Status.UpdatedNumberScheduled == desired &&
+		ds.Status.NumberAvailable == desired
+
+	return rolloutCondition(conditionType, ready, ds.Status.NumberAvailable, desired)
+}
+
+// StatefulSetCondition returns a ready condition if the controller has observed the latest spec of the statefulset
+// and all of its replicas are updated and ready.
+func StatefulSetCondition(conditionType string, sts *appsv1.StatefulSet) metav1.Condition {
+	desired := int32(1)
+	if sts.Spec.Replicas != nil {
+		desired = *sts.Spec.Replicas
+	}
+	ready := sts.Status.ObservedGeneration >= sts.Generation &&
+		sts.Status.UpdatedReplicas == desired &&
+		sts.Status.ReadyReplicas == desired
+
+	return rolloutCondition(conditionType, ready, sts.Status.ReadyReplicas, desired)
+}
+
+// NotFoundCondition returns the condition of a component whose workload does not exist.
+func NotFoundCondition(conditionType string, kind string, name string) metav1.Condition {
+	return metav1.Condition{
+		Type:    conditionType,
+		Status:  metav1.ConditionFalse,
+		Reason:  dynatracev1beta1.ReasonNotFound,
+		Message: fmt.Sprintf("%s %s not found", kind, name),
+	}
+}
+
+func rolloutCondition(conditionType string, ready bool, readyPods int32, desiredPods int32) metav1.Condition {
+	message := fmt.Sprintf("%d of %d pods ready", readyPods, desiredPods)
+	if ready {
+		return metav1.Condition{
+			Type:    conditionType,
+			Status:  metav1.ConditionTrue,
+			Reason:  dynatracev1beta1.ReasonReady,
+			Message: message,
+		}
+	}
+	return metav1.Condition{
+		Type:    conditionType,
+		Status:  metav1.ConditionFalse,
+		Reason:  dynatracev1beta1.ReasonRolloutInProgress,
+		Message: message,
+	}
+}
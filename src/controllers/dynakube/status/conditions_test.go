@@ -0,0 +1,130 @@
+package status
+
+import (
+	"fmt"
+	"testing"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSetCondition(t *testing.T) {
+	t.Run(`marks state as updated if condition changed`, func(t *testing.T) {
+		dkState := NewDynakubeState(&dynatracev1beta1.DynaKube{ObjectMeta: metav1.ObjectMeta{Generation: 2}})
+
+		dkState.SetReconcileCondition(dynatracev1beta1.PullSecretConditionType, nil)
+		assert.True(t, dkState.Updated)
+
+		condition := meta.FindStatusCondition(dkState.Instance.Status.Conditions, dynatracev1beta1.PullSecretConditionType)
+		require.NotNil(t, condition)
+		assert.Equal(t, metav1.ConditionTrue, condition.Status)
+		assert.Equal(t, dynatracev1beta1.ReasonReady, condition.Reason)
+		assert.Equal(t, int64(2), condition.ObservedGeneration)
+
+		dkState.Updated = false
+		dkState.SetReconcileCondition(dynatracev1beta1.PullSecretConditionType, nil)
+		assert.False(t, dkState.Updated)
+
+		dkState.SetReconcileCondition(dynatracev1beta1.PullSecretConditionType, fmt.Errorf(testError))
+		assert.True(t, dkState.Updated)
+
+		condition = meta.FindStatusCondition(dkState.Instance.Status.Conditions, dynatracev1beta1.PullSecretConditionType)
+		require.NotNil(t, condition)
+		assert.Equal(t, metav1.ConditionFalse, condition.Status)
+		assert.Equal(t, dynatracev1beta1.ReasonReconcileFailed, condition.Reason)
+		assert.Equal(t, testError, condition.Message)
+	})
+	t.Run(`removes condition`, func(t *testing.T) {
+		dkState := NewDynakubeState(&dynatracev1beta1.DynaKube{})
+
+		dkState.RemoveCondition(dynatracev1beta1.IstioConditionType)
+		assert.False(t, dkState.Updated)
+
+		dkState.SetReconcileCondition(dynatracev1beta1.IstioConditionType, nil)
+		dkState.Updated = false
+		dkState.RemoveCondition(dynatracev1beta1.IstioConditionType)
+		assert.True(t, dkState.Updated)
+		assert.Empty(t, dkState.Instance.Status.Conditions)
+	})
+}
+
+func TestSetNotReconciledConditions(t *testing.T) {
+	dkState := NewDynakubeState(&dynatracev1beta1.DynaKube{
+		Status: dynatracev1beta1.DynaKubeStatus{
+			Conditions: []metav1.Condition{
+				{Type: dynatracev1beta1.PullSecretConditionType, Status: metav1.ConditionTrue, Reason: dynatracev1beta1.ReasonReady},
+				{Type: dynatracev1beta1.OneAgentConditionType, Status: metav1.ConditionTrue, Reason: dynatracev1beta1.ReasonReady},
+				{Type: dynatracev1beta1.APITokenConditionType, Status: metav1.ConditionTrue, Reason: dynatracev1beta1.ReasonTokenReady},
+			},
+		},
+	})
+	dkState.SetReconcileCondition(dynatracev1beta1.PullSecretConditionType, nil)
+
+	dkState.SetNotReconciledConditions(testError)
+
+	assert.True(t, dkState.Updated)
+	pullSecretCondition := meta.FindStatusCondition(dkState.Instance.Status.Conditions, dynatracev1beta1.PullSecretConditionType)
+	assert.Equal(t, metav1.ConditionTrue, pullSecretCondition.Status)
+
+	oneAgentCondition := meta.FindStatusCondition(dkState.Instance.Status.Conditions, dynatracev1beta1.OneAgentConditionType)
+	assert.Equal(t, metav1.ConditionUnknown, oneAgentCondition.Status)
+	assert.Equal(t, dynatracev1beta1.ReasonNotReconciled, oneAgentCondition.Reason)
+	assert.Equal(t, "Not reconciled: "+testError, oneAgentCondition.Message)
+
+	apiTokenCondition := meta.FindStatusCondition(dkState.Instance.Status.Conditions, dynatracev1beta1.APITokenConditionType)
+	assert.Equal(t, dynatracev1beta1.ReasonTokenReady, apiTokenCondition.Reason)
+
+	assert.Nil(t, meta.FindStatusCondition(dkState.Instance.Status.Conditions, dynatracev1beta1.IstioConditionType))
+}
+
+func TestDaemonSetCondition(t *testing.T) {
+	ds := &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{Generation: 3},
+		Status: appsv1.DaemonSetStatus{
+			ObservedGeneration:     3,
+			DesiredNumberScheduled: 3,
+			UpdatedNumberScheduled: 3,
+			NumberAvailable:        3,
+		},
+	}
+
+	condition := DaemonSetCondition(dynatracev1beta1.OneAgentConditionType, ds)
+	assert.Equal(t, metav1.ConditionTrue, condition.Status)
+	assert.Equal(t, dynatracev1beta1.ReasonReady, condition.Reason)
+	assert.Equal(t, "3 of 3 pods ready", condition.Message)
+
+	ds.Status.UpdatedNumberScheduled = 2
+	condition = DaemonSetCondition(dynatracev1beta1.OneAgentConditionType, ds)
+	assert.Equal(t, metav1.ConditionFalse, condition.Status)
+	assert.Equal(t, dynatracev1beta1.ReasonRolloutInProgress, condition.Reason)
+
+	ds.Status.UpdatedNumberScheduled = 3
+	ds.Generation = 4
+	condition = DaemonSetCondition(dynatracev1beta1.OneAgentConditionType, ds)
+	assert.Equal(t, metav1.ConditionFalse, condition.Status)
+}
+
+func TestStatefulSetCondition(t *testing.T) {
+	replicas := int32(2)
+	sts := &appsv1.StatefulSet{
+		Spec: appsv1.StatefulSetSpec{Replicas: &replicas},
+		Status: appsv1.StatefulSetStatus{
+			UpdatedReplicas: 2,
+			ReadyReplicas:   1,
+		},
+	}
+
+	condition := StatefulSetCondition(dynatracev1beta1.RoutingActiveGateConditionType, sts)
+	assert.Equal(t, metav1.ConditionFalse, condition.Status)
+	assert.Equal(t, dynatracev1beta1.ReasonRolloutInProgress, condition.Reason)
+	assert.Equal(t, "1 of 2 pods ready", condition.Message)
+
+	sts.Status.ReadyReplicas = 2
+	condition = StatefulSetCondition(dynatracev1beta1.RoutingActiveGateConditionType, sts)
+	assert.Equal(t, metav1.ConditionTrue, condition.Status)
+	assert.Equal(t, dynatracev1beta1.ReasonReady, condition.Reason)
+}
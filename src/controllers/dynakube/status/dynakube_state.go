@@ -25,6 +25,9 @@ type DynakubeState struct {
 	Updated      bool
 	ValidTokens  bool
 	RequeueAfter time.Duration
+
+	// reconciledConditions are the conditions set or removed during the reconcile
+	reconciledConditions map[string]bool
 }
 
 func NewDynakubeState(dk *dynatracev1beta1.DynaKube) *DynakubeState {
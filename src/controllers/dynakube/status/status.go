@@ -1,7 +1,10 @@
 package status
 
 import (
+	"time"
+
 	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/maintenancewindow"
 	"github.com/Dynatrace/dynatrace-operator/src/dtclient"
 	"github.com/Dynatrace/dynatrace-operator/src/kubesystem"
 	"github.com/pkg/errors"
@@ -11,6 +14,7 @@ import (
 type Options struct {
 	Dtc       dtclient.Client
 	ApiClient client.Reader
+	Now       time.Time
 }
 
 func SetDynakubeStatus(instance *dynatracev1beta1.DynaKube, opts Options) error {
@@ -44,20 +48,49 @@ func SetDynakubeStatus(instance *dynatracev1beta1.DynaKube, opts Options) error
 
 	communicationHostStatus := dynatracev1beta1.CommunicationHostStatus(communicationHost)
 
-	connectionInfoStatus := dynatracev1beta1.ConnectionInfoStatus{
-		CommunicationHosts: communicationHostsToStatus(connectionInfo.CommunicationHosts),
-		TenantUUID:         connectionInfo.TenantUUID,
-	}
-
 	instance.Status.KubeSystemUUID = string(uid)
 	instance.Status.CommunicationHostForClient = communicationHostStatus
-	instance.Status.ConnectionInfo = connectionInfoStatus
+	instance.Status.ConnectionInfo = connectionInfoToStatus(connectionInfo)
 	instance.Status.LatestAgentVersionUnixDefault = latestAgentVersionUnixDefault
-	instance.Status.LatestAgentVersionUnixPaas = latestAgentVersionUnixPaas
 
+	return errors.WithStack(setLatestAgentVersionUnixPaas(instance, latestAgentVersionUnixPaas, opts.Now))
+}
+
+// setLatestAgentVersionUnixPaas sets the agent version the CSI driver provisions the code modules with.
+// Outside of the maintenance windows a new version is kept pending, unless no version has been set yet.
+func setLatestAgentVersionUnixPaas(instance *dynatracev1beta1.DynaKube, latestAgentVersionUnixPaas string, now time.Time) error {
+	windowOpen, err := maintenancewindow.IsOpen(instance.Spec.MaintenanceWindows, now)
+	if err != nil {
+		return err
+	}
+
+	if windowOpen || instance.Status.LatestAgentVersionUnixPaas == "" || instance.Status.LatestAgentVersionUnixPaas == latestAgentVersionUnixPaas {
+		instance.Status.LatestAgentVersionUnixPaas = latestAgentVersionUnixPaas
+		instance.Status.PendingAgentVersionUnixPaas = ""
+	} else {
+		instance.Status.PendingAgentVersionUnixPaas = latestAgentVersionUnixPaas
+	}
+	return nil
+}
+
+// SetTenantTargetStatus caches the connection info of an additional tenant, the client has to be the one of the tenant target.
+func SetTenantTargetStatus(targetStatus *dynatracev1beta1.TenantTargetStatus, dtc dtclient.Client) error {
+	connectionInfo, err := dtc.GetConnectionInfo()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	targetStatus.ConnectionInfo = connectionInfoToStatus(connectionInfo)
 	return nil
 }
 
+func connectionInfoToStatus(connectionInfo dtclient.ConnectionInfo) dynatracev1beta1.ConnectionInfoStatus {
+	return dynatracev1beta1.ConnectionInfoStatus{
+		CommunicationHosts: communicationHostsToStatus(connectionInfo.CommunicationHosts),
+		TenantUUID:         connectionInfo.TenantUUID,
+	}
+}
+
 func communicationHostsToStatus(communicationHosts []dtclient.CommunicationHost) []dynatracev1beta1.CommunicationHostStatus {
 	var communicationHostStatuses []dynatracev1beta1.CommunicationHostStatus
 
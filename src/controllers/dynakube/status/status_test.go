@@ -3,12 +3,14 @@ package status
 import (
 	"fmt"
 	"testing"
+	"time"
 
 	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
 	"github.com/Dynatrace/dynatrace-operator/src/dtclient"
 	"github.com/Dynatrace/dynatrace-operator/src/kubesystem"
 	"github.com/Dynatrace/dynatrace-operator/src/scheme/fake"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -245,3 +247,78 @@ func TestSetDynakubeStatus(t *testing.T) {
 		assert.EqualError(t, err, testError)
 	})
 }
+
+func TestSetTenantTargetStatus(t *testing.T) {
+	t.Run(`set connection info`, func(t *testing.T) {
+		targetStatus := &dynatracev1beta1.TenantTargetStatus{Name: "migration"}
+		dtc := &dtclient.MockDynatraceClient{}
+		dtc.On("GetConnectionInfo").Return(dtclient.ConnectionInfo{
+			CommunicationHosts: []dtclient.CommunicationHost{
+				{
+					Protocol: testProtocol,
+					Host:     testHost,
+					Port:     testPort,
+				},
+			},
+			TenantUUID: testUUID,
+		}, nil)
+
+		err := SetTenantTargetStatus(targetStatus, dtc)
+
+		assert.NoError(t, err)
+		assert.Equal(t, testUUID, targetStatus.ConnectionInfo.TenantUUID)
+		assert.Equal(t, []dynatracev1beta1.CommunicationHostStatus{
+			{
+				Protocol: testProtocol,
+				Host:     testHost,
+				Port:     testPort,
+			},
+		}, targetStatus.ConnectionInfo.CommunicationHosts)
+	})
+	t.Run(`error querying connection info`, func(t *testing.T) {
+		targetStatus := &dynatracev1beta1.TenantTargetStatus{Name: "migration"}
+		dtc := &dtclient.MockDynatraceClient{}
+		dtc.On("GetConnectionInfo").Return(dtclient.ConnectionInfo{}, fmt.Errorf(testError))
+
+		err := SetTenantTargetStatus(targetStatus, dtc)
+		assert.EqualError(t, err, testError)
+		assert.Empty(t, targetStatus.ConnectionInfo.TenantUUID)
+	})
+}
+
+func TestSetLatestAgentVersionUnixPaas(t *testing.T) {
+	const newVersionPaas = "2.218.12345-678910"
+	windows := []dynatracev1beta1.MaintenanceWindowSpec{{
+		Schedule: "0 22 * * *",
+		Duration: metav1.Duration{Duration: 2 * time.Hour},
+	}}
+	inWindow := time.Date(2022, time.March, 7, 23, 0, 0, 0, time.UTC)
+	outsideWindow := time.Date(2022, time.March, 7, 12, 0, 0, 0, time.UTC)
+
+	t.Run(`first version is set outside of the maintenance windows`, func(t *testing.T) {
+		instance := &dynatracev1beta1.DynaKube{Spec: dynatracev1beta1.DynaKubeSpec{MaintenanceWindows: windows}}
+
+		require.NoError(t, setLatestAgentVersionUnixPaas(instance, testVersionPaas, outsideWindow))
+		assert.Equal(t, testVersionPaas, instance.Status.LatestAgentVersionUnixPaas)
+		assert.Empty(t, instance.Status.PendingAgentVersionUnixPaas)
+	})
+	t.Run(`new version is pending until the maintenance window`, func(t *testing.T) {
+		instance := &dynatracev1beta1.DynaKube{Spec: dynatracev1beta1.DynaKubeSpec{MaintenanceWindows: windows}}
+		instance.Status.LatestAgentVersionUnixPaas = testVersionPaas
+
+		require.NoError(t, setLatestAgentVersionUnixPaas(instance, newVersionPaas, outsideWindow))
+		assert.Equal(t, testVersionPaas, instance.Status.LatestAgentVersionUnixPaas)
+		assert.Equal(t, newVersionPaas, instance.Status.PendingAgentVersionUnixPaas)
+
+		require.NoError(t, setLatestAgentVersionUnixPaas(instance, newVersionPaas, inWindow))
+		assert.Equal(t, newVersionPaas, instance.Status.LatestAgentVersionUnixPaas)
+		assert.Empty(t, instance.Status.PendingAgentVersionUnixPaas)
+	})
+	t.Run(`invalid maintenance window`, func(t *testing.T) {
+		instance := &dynatracev1beta1.DynaKube{Spec: dynatracev1beta1.DynaKubeSpec{
+			MaintenanceWindows: []dynatracev1beta1.MaintenanceWindowSpec{{Schedule: "0 22 * *"}},
+		}}
+
+		assert.Error(t, setLatestAgentVersionUnixPaas(instance, testVersionPaas, inWindow))
+	})
+}
@@ -0,0 +1,9 @@
+package tokenrotation
+
+import (
+	"github.com/Dynatrace/dynatrace-operator/src/logger"
+)
+
+var (
+	log = logger.NewDTLogger().WithName("dynakube-tokenrotation")
+)
@@ -0,0 +1,220 @@
+package tokenrotation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/activegate"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/dtpullsecret"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/status"
+	"github.com/Dynatrace/dynatrace-operator/src/dtclient"
+	"github.com/Dynatrace/dynatrace-operator/src/ingestendpoint"
+	"github.com/Dynatrace/dynatrace-operator/src/initgeneration"
+	"github.com/Dynatrace/dynatrace-operator/src/kubeobjects"
+	"github.com/Dynatrace/dynatrace-operator/src/mapper"
+	"github.com/Dynatrace/dynatrace-operator/src/standalone"
+	"github.com/Dynatrace/dynatrace-operator/src/webhook"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// maxReportedSecrets limits the size of the status, the init and data-ingest endpoint secrets exist in every monitored namespace
+const maxReportedSecrets = 50
+
+// TokensHash returns the hash of the tokens, which is kept in the status to notice when the tokens were rotated.
+func TokensHash(apiToken, paasToken, dataIngestToken string) (string, error) {
+	return kubeobjects.GenerateHash(map[string]string{
+		dtclient.DynatraceApiToken:        apiToken,
+		dtclient.DynatracePaasToken:       paasToken,
+		dtclient.DynatraceDataIngestToken: dataIngestToken,
+	})
+}
+
+// Reconciler updates all secrets derived from the tokens in one pass after the tokens were rotated, instead of leaving it
+// to the reconcilers of the single components, which only run once the components before them are reconciled.
+// Components keep using the previous tokens until their secret is updated, so the previous tokens have to stay valid
+// until the status reports all secrets as updated.
+type Reconciler struct {
+	client                               client.Client
+	apiReader                            client.Reader
+	scheme                               *runtime.Scheme
+	dtc                                  dtclient.Client
+	apiToken, paasToken, dataIngestToken string
+}
+
+type derivedSecret struct {
+	namespace, name string
+	// isCurrent reports whether the secret contains the current tokens
+	isCurrent func(secret *corev1.Secret) bool
+}
+
+func NewReconciler(clt client.Client, apiReader client.Reader, scheme *runtime.Scheme, dtc dtclient.Client, apiToken, paasToken, dataIngestToken string) *Reconciler {
+	return &Reconciler{
+		client:          clt,
+		apiReader:       apiReader,
+		scheme:          scheme,
+		dtc:             dtc,
+		apiToken:        apiToken,
+		paasToken:       paasToken,
+		dataIngestToken: dataIngestToken,
+	}
+}
+
+// Reconcile updates the derived secrets and reports which of them use the current tokens in the status of the DynaKube.
+// Nothing is done if the status already reports all of them as updated.
+// Failing to update a secret doesn't stop the others from being updated, the secret is reported as outdated instead.
+func (r *Reconciler) Reconcile(ctx context.Context, dkState *status.DynakubeState) error {
+	instance := dkState.Instance
+	if meta.IsStatusConditionTrue(instance.Status.Conditions, dynatracev1beta1.TokensPropagatedConditionType) {
+		return nil
+	}
+
+	log.Info("propagating tokens to the derived secrets", "dynakube", instance.Name)
+	secrets, failures, err := r.propagate(ctx, instance)
+	if err != nil {
+		return err
+	}
+
+	var updated, outdated []string
+	for _, derived := range secrets {
+		secret, err := kubeobjects.GetSecret(ctx, r.apiReader, derived.name, derived.namespace)
+		if err != nil {
+			return err
+		}
+
+		name := derived.namespace + "/" + derived.name
+		if secret != nil && derived.isCurrent(secret) {
+			updated = append(updated, name)
+		} else {
+			outdated = append(outdated, name)
+		}
+	}
+	setStatus(dkState, updated, outdated)
+
+	if len(failures) > 0 {
+		return errors.Errorf("failed to propagate tokens: %s", strings.Join(failures, "; "))
+	}
+	return nil
+}
+
+// propagate updates every secret derived from the tokens and returns them, with the errors of the failed updates.
+func (r *Reconciler) propagate(ctx context.Context, instance *dynatracev1beta1.DynaKube) ([]derivedSecret, []string, error) {
+	var secrets []derivedSecret
+	var failures []string
+
+	if instance.Spec.CustomPullSecret == "" {
+		pullSecretReconciler := dtpullsecret.NewReconciler(r.client, r.apiReader, r.scheme, instance, r.apiToken, r.paasToken)
+		if err := pullSecretReconciler.Reconcile(); err != nil {
+			failures = append(failures, err.Error())
+		}
+		desiredData, err := pullSecretReconciler.GenerateData()
+		secrets = append(secrets, derivedSecret{
+			namespace: instance.Namespace,
+			name:      instance.Name + dtpullsecret.PullSecretSuffix,
+			isCurrent: func(secret *corev1.Secret) bool {
+				return err == nil && kubeobjects.IsSecretEqual(secret, desiredData)
+			},
+		})
+	}
+
+	if instance.FeatureEnableActivegateRawImage() && instance.NeedsActiveGate() {
+		// the tenant secret holds no tokens, but it's up-to-date only if it could be queried with the current API token
+		agErr := activegate.NewTenantSecretReconciler(r.client, r.apiReader, r.scheme, instance, r.apiToken, r.dtc).Reconcile()
+		if agErr != nil {
+			failures = append(failures, agErr.Error())
+		}
+		secrets = append(secrets, derivedSecret{
+			namespace: instance.Namespace,
+			name:      instance.AGTenantSecret(),
+			isCurrent: func(*corev1.Secret) bool {
+				return agErr == nil
+			},
+		})
+	}
+
+	if !instance.NeedAppInjection() {
+		return secrets, failures, nil
+	}
+
+	if _, err := initgeneration.NewInitGenerator(r.client, r.apiReader, instance.Namespace).GenerateForDynakube(ctx, instance); err != nil {
+		failures = append(failures, err.Error())
+	}
+	withEndpointSecrets := !instance.FeatureDisableMetadataEnrichment()
+	if withEndpointSecrets {
+		if _, err := ingestendpoint.NewEndpointSecretGenerator(r.client, r.apiReader, instance.Namespace).GenerateForDynakube(ctx, instance); err != nil {
+			failures = append(failures, err.Error())
+		}
+	}
+
+	namespaces, err := mapper.GetNamespacesForDynakube(ctx, r.apiReader, instance.Name)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, namespace := range namespaces {
+		secrets = append(secrets, derivedSecret{namespace: namespace.Name, name: webhook.SecretConfigName, isCurrent: r.isInitSecretCurrent})
+		if withEndpointSecrets {
+			secrets = append(secrets, derivedSecret{namespace: namespace.Name, name: ingestendpoint.SecretEndpointName, isCurrent: r.isEndpointSecretCurrent})
+		}
+	}
+	return secrets, failures, nil
+}
+
+func (r *Reconciler) isInitSecretCurrent(secret *corev1.Secret) bool {
+	var config standalone.SecretConfig
+	if err := json.Unmarshal(secret.Data[standalone.SecretConfigFieldName], &config); err != nil {
+		return false
+	}
+
+	paasToken := r.paasToken
+	if paasToken == "" {
+		paasToken = r.apiToken
+	}
+	return config.ApiToken == r.apiToken && config.PaasToken == paasToken
+}
+
+func (r *Reconciler) isEndpointSecretCurrent(secret *corev1.Secret) bool {
+	return string(secret.Data[ingestendpoint.TokenSecretField]) == r.dataIngestToken
+}
+
+func setStatus(dkState *status.DynakubeState, updated, outdated []string) {
+	rotation := &dkState.Instance.Status.TokenRotation
+	reportedUpdated, reportedOutdated := limitSecrets(updated), limitSecrets(outdated)
+	if !reflect.DeepEqual(rotation.UpdatedSecrets, reportedUpdated) || !reflect.DeepEqual(rotation.OutdatedSecrets, reportedOutdated) {
+		rotation.UpdatedSecrets = reportedUpdated
+		rotation.OutdatedSecrets = reportedOutdated
+		dkState.Updated = true
+	}
+
+	if len(outdated) == 0 {
+		dkState.SetCondition(metav1.Condition{
+			Type:    dynatracev1beta1.TokensPropagatedConditionType,
+			Status:  metav1.ConditionTrue,
+			Reason:  dynatracev1beta1.ReasonReady,
+			Message: fmt.Sprintf("All %d secrets derived from the tokens use the current tokens", len(updated)),
+		})
+		return
+	}
+
+	log.Info("secrets derived from the tokens still use previous tokens", "dynakube", dkState.Instance.Name, "secrets", outdated)
+	dkState.SetCondition(metav1.Condition{
+		Type:    dynatracev1beta1.TokensPropagatedConditionType,
+		Status:  metav1.ConditionFalse,
+		Reason:  dynatracev1beta1.ReasonRolloutInProgress,
+		Message: fmt.Sprintf("%d of %d secrets derived from the tokens still use previous tokens", len(outdated), len(updated)+len(outdated)),
+	})
+}
+
+func limitSecrets(secrets []string) []string {
+	if len(secrets) > maxReportedSecrets {
+		return secrets[:maxReportedSecrets]
+	}
+	return secrets
+}
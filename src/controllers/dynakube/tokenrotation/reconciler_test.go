@@ -0,0 +1,149 @@
+package tokenrotation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/status"
+	"github.com/Dynatrace/dynatrace-operator/src/dtclient"
+	"github.com/Dynatrace/dynatrace-operator/src/ingestendpoint"
+	"github.com/Dynatrace/dynatrace-operator/src/mapper"
+	"github.com/Dynatrace/dynatrace-operator/src/scheme"
+	"github.com/Dynatrace/dynatrace-operator/src/scheme/fake"
+	"github.com/Dynatrace/dynatrace-operator/src/standalone"
+	"github.com/Dynatrace/dynatrace-operator/src/webhook"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	testDynakubeName    = "dynakube"
+	testNamespace       = "dynatrace"
+	testMonitoredNs     = "test-namespace"
+	testApiToken        = "new-api-token"
+	testPaasToken       = "new-paas-token"
+	testDataIngestToken = "new-data-ingest-token"
+)
+
+func createTestDynakube() *dynatracev1beta1.DynaKube {
+	return &dynatracev1beta1.DynaKube{
+		ObjectMeta: metav1.ObjectMeta{Name: testDynakubeName, Namespace: testNamespace},
+		Spec: dynatracev1beta1.DynaKubeSpec{
+			APIURL: "https://test-tenant.dev.dynatracelabs.com/api",
+			OneAgent: dynatracev1beta1.OneAgentSpec{
+				ApplicationMonitoring: &dynatracev1beta1.ApplicationMonitoringSpec{},
+			},
+		},
+	}
+}
+
+func createTestObjects() []client.Object {
+	previousConfig, _ := json.Marshal(standalone.SecretConfig{ApiToken: "old-api-token", PaasToken: "old-paas-token"})
+	return []client.Object{
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "kube-system", UID: "42"}},
+		&corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   testMonitoredNs,
+				Labels: map[string]string{mapper.InstanceLabel: testDynakubeName},
+			},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: testDynakubeName, Namespace: testNamespace},
+			Data: map[string][]byte{
+				dtclient.DynatraceApiToken:        []byte(testApiToken),
+				dtclient.DynatracePaasToken:       []byte(testPaasToken),
+				dtclient.DynatraceDataIngestToken: []byte(testDataIngestToken),
+			},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: webhook.SecretConfigName, Namespace: testMonitoredNs},
+			Data:       map[string][]byte{standalone.SecretConfigFieldName: previousConfig},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: ingestendpoint.SecretEndpointName, Namespace: testMonitoredNs},
+			Data:       map[string][]byte{ingestendpoint.TokenSecretField: []byte("old-data-ingest-token")},
+		},
+	}
+}
+
+func TestReconcile(t *testing.T) {
+	t.Run(`all derived secrets are updated`, func(t *testing.T) {
+		clt := fake.NewClient(createTestObjects()...)
+		dkState := status.NewDynakubeState(createTestDynakube())
+
+		err := NewReconciler(clt, clt, scheme.Scheme, &dtclient.MockDynatraceClient{}, testApiToken, testPaasToken, testDataIngestToken).
+			Reconcile(context.TODO(), dkState)
+
+		require.NoError(t, err)
+		assert.True(t, dkState.Updated)
+		assert.Equal(t, []string{
+			"dynatrace/dynakube-pull-secret",
+			"test-namespace/dynatrace-dynakube-config",
+			"test-namespace/dynatrace-data-ingest-endpoint",
+		}, dkState.Instance.Status.TokenRotation.UpdatedSecrets)
+		assert.Empty(t, dkState.Instance.Status.TokenRotation.OutdatedSecrets)
+		assert.True(t, meta.IsStatusConditionTrue(dkState.Instance.Status.Conditions, dynatracev1beta1.TokensPropagatedConditionType))
+
+		var endpointSecret corev1.Secret
+		require.NoError(t, clt.Get(context.TODO(), client.ObjectKey{Name: ingestendpoint.SecretEndpointName, Namespace: testMonitoredNs}, &endpointSecret))
+		assert.Equal(t, testDataIngestToken, string(endpointSecret.Data[ingestendpoint.TokenSecretField]))
+	})
+	t.Run(`failed secrets are reported as outdated`, func(t *testing.T) {
+		clt := fake.NewClient(createTestObjects()...)
+		dynakube := createTestDynakube()
+		dynakube.Annotations = map[string]string{dynatracev1beta1.AnnotationFeatureEnableActivegateRawImage: "true"}
+		dynakube.Spec.ActiveGate.Capabilities = []dynatracev1beta1.CapabilityDisplayName{dynatracev1beta1.RoutingCapability.DisplayName}
+		dkState := status.NewDynakubeState(dynakube)
+		dtc := &dtclient.MockDynatraceClient{}
+		dtc.On("GetActiveGateTenantInfo").Return(&dtclient.ActiveGateTenantInfo{}, fmt.Errorf("token unauthorized"))
+
+		err := NewReconciler(clt, clt, scheme.Scheme, dtc, testApiToken, testPaasToken, testDataIngestToken).
+			Reconcile(context.TODO(), dkState)
+
+		assert.Error(t, err)
+		assert.Equal(t, []string{"dynatrace/dynakube-activegate-tenant-secret"}, dkState.Instance.Status.TokenRotation.OutdatedSecrets)
+		assert.Len(t, dkState.Instance.Status.TokenRotation.UpdatedSecrets, 3)
+
+		condition := meta.FindStatusCondition(dkState.Instance.Status.Conditions, dynatracev1beta1.TokensPropagatedConditionType)
+		require.NotNil(t, condition)
+		assert.Equal(t, metav1.ConditionFalse, condition.Status)
+		assert.Equal(t, "1 of 4 secrets derived from the tokens still use previous tokens", condition.Message)
+	})
+	t.Run(`nothing to do once all secrets are updated`, func(t *testing.T) {
+		clt := fake.NewClient(createTestObjects()...)
+		dynakube := createTestDynakube()
+		meta.SetStatusCondition(&dynakube.Status.Conditions, metav1.Condition{
+			Type:   dynatracev1beta1.TokensPropagatedConditionType,
+			Status: metav1.ConditionTrue,
+			Reason: dynatracev1beta1.ReasonReady,
+		})
+		dkState := status.NewDynakubeState(dynakube)
+
+		err := NewReconciler(clt, clt, scheme.Scheme, &dtclient.MockDynatraceClient{}, testApiToken, testPaasToken, testDataIngestToken).
+			Reconcile(context.TODO(), dkState)
+
+		require.NoError(t, err)
+		assert.False(t, dkState.Updated)
+
+		var endpointSecret corev1.Secret
+		require.NoError(t, clt.Get(context.TODO(), client.ObjectKey{Name: ingestendpoint.SecretEndpointName, Namespace: testMonitoredNs}, &endpointSecret))
+		assert.Equal(t, "old-data-ingest-token", string(endpointSecret.Data[ingestendpoint.TokenSecretField]))
+	})
+}
+
+func TestTokensHash(t *testing.T) {
+	hash, err := TokensHash(testApiToken, testPaasToken, "")
+	require.NoError(t, err)
+
+	rotatedHash, err := TokensHash(testApiToken, "rotated-paas-token", "")
+	require.NoError(t, err)
+
+	assert.NotEqual(t, hash, rotatedHash)
+}
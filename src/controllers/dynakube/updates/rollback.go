@@ -0,0 +1,194 @@
+package updates
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/activegate/reconciler/statefulset"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/status"
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// RollbackDeadline is how long the pods of an update may take to become ready, before the update is rolled back.
+	RollbackDeadline = 15 * time.Minute
+
+	// annotationVersion is set on the pod templates of the OneAgent daemonset and the ActiveGate statefulsets
+	annotationVersion = dynatracev1beta1.InternalFlagPrefix + "version"
+)
+
+// ReconcileRollbacks watches the pods of OneAgent and ActiveGate updates. If most of the updated pods did not become
+// ready within the RollbackDeadline, the previous version is restored and automatic updates of the component are
+// paused, until the failed version is acknowledged with the AnnotationAcknowledgeRollback annotation.
+func ReconcileRollbacks(ctx context.Context, dkState *status.DynakubeState, apiReader client.Reader) (bool, error) {
+	dk := dkState.Instance
+
+	upd := acknowledgeRollback(dk, "OneAgent", &dk.Status.OneAgent.VersionStatus)
+	if acknowledgeRollback(dk, "ActiveGate", &dk.Status.ActiveGate.VersionStatus) {
+		upd = true
+	}
+
+	if dk.Status.OneAgent.PreviousVersion != "" {
+		pods, err := oneAgentPods(ctx, apiReader, dk)
+		if err != nil {
+			return upd, err
+		}
+		if watchRollout(dkState, "OneAgent", &dk.Status.OneAgent.VersionStatus, pods) {
+			upd = true
+		}
+	}
+
+	if dk.Status.ActiveGate.PreviousVersion != "" {
+		pods, err := activeGatePods(ctx, apiReader, dk)
+		if err != nil {
+			return upd, err
+		}
+		if watchRollout(dkState, "ActiveGate", &dk.Status.ActiveGate.VersionStatus, pods) {
+			upd = true
+		}
+	}
+
+	return upd, nil
+}
+
+// RollbackCondition returns the condition which reports the rolled back components, nil if there are none.
+func RollbackCondition(dk *dynatracev1beta1.DynaKube) *metav1.Condition {
+	var messages []string
+	for _, component := range []struct {
+		name          string
+		versionStatus dynatracev1beta1.VersionStatus
+	}{
+		{"OneAgent", dk.Status.OneAgent.VersionStatus},
+		{"ActiveGate", dk.Status.ActiveGate.VersionStatus},
+	} {
+		if rolledBack := component.versionStatus.RolledBack; rolledBack != nil {
+			messages = append(messages, fmt.Sprintf("%s update to version %s was rolled back to %s",
+				component.name, rolledBack.Version, component.versionStatus.Version))
+		}
+	}
+
+	if len(messages) == 0 {
+		return nil
+	}
+
+	return &metav1.Condition{
+		Type:   dynatracev1beta1.RolledBackConditionType,
+		Status: metav1.ConditionTrue,
+		Reason: dynatracev1beta1.ReasonUpdateFailed,
+		Message: fmt.Sprintf("%s, set the %s annotation to the failed version to resume automatic updates",
+			strings.Join(messages, ", "), dynatracev1beta1.AnnotationAcknowledgeRollback),
+	}
+}
+
+// acknowledgeRollback resumes the automatic updates of a component, once its failed version is acknowledged.
+// The next probe for updates is done right away.
+func acknowledgeRollback(dk *dynatracev1beta1.DynaKube, component string, versionStatus *dynatracev1beta1.VersionStatus) bool {
+	if versionStatus.RolledBack == nil || !dk.RollbackAcknowledged(versionStatus.RolledBack.Version) {
+		return false
+	}
+
+	log.Info("rollback acknowledged, resuming automatic updates",
+		"component", component, "failedVersion", versionStatus.RolledBack.Version)
+	versionStatus.RolledBack = nil
+	versionStatus.LastUpdateProbeTimestamp = nil
+	return true
+}
+
+// watchRollout confirms the update of a component once all of its pods run the new version and are ready,
+// or restores the previous version if most of the updated pods are not ready after the RollbackDeadline.
+func watchRollout(dkState *status.DynakubeState, component string, versionStatus *dynatracev1beta1.VersionStatus, pods []corev1.Pod) bool {
+	var updated, unhealthy int
+	for _, pod := range pods {
+		if pod.Annotations[annotationVersion] != versionStatus.Version {
+			continue
+		}
+		updated++
+		if !isReady(&pod) && pod.CreationTimestamp.Add(RollbackDeadline).Before(dkState.Now.Time) {
+			unhealthy++
+		}
+	}
+
+	if len(pods) > 0 && updated == len(pods) && allReady(pods) {
+		log.Info("update rolled out", "component", component, "version", versionStatus.Version)
+		versionStatus.PreviousVersion = ""
+		versionStatus.PreviousImageHash = ""
+		return true
+	}
+
+	if unhealthy == 0 || unhealthy*2 < updated || dkState.Instance.FeatureDisableAutomaticRollback() {
+		return false
+	}
+
+	log.Info("pods of update not ready, rolling back",
+		"component", component, "failedVersion", versionStatus.Version, "restoredVersion", versionStatus.PreviousVersion,
+		"unhealthyPods", unhealthy, "updatedPods", updated)
+	versionStatus.RolledBack = &dynatracev1beta1.RollbackStatus{
+		Version:   versionStatus.Version,
+		ImageHash: versionStatus.ImageHash,
+		Timestamp: *dkState.Now.DeepCopy(),
+	}
+	versionStatus.Version = versionStatus.PreviousVersion
+	versionStatus.ImageHash = versionStatus.PreviousImageHash
+	versionStatus.PreviousVersion = ""
+	versionStatus.PreviousImageHash = ""
+	return true
+}
+
+func oneAgentPods(ctx context.Context, apiReader client.Reader, dk *dynatracev1beta1.DynaKube) ([]corev1.Pod, error) {
+	var ds appsv1.DaemonSet
+	err := apiReader.Get(ctx, client.ObjectKey{Name: dk.OneAgentDaemonsetName(), Namespace: dk.Namespace}, &ds)
+	if k8serrors.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(ds.Spec.Selector)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return listPods(ctx, apiReader, dk.Namespace, selector)
+}
+
+func activeGatePods(ctx context.Context, apiReader client.Reader, dk *dynatracev1beta1.DynaKube) ([]corev1.Pod, error) {
+	selector := labels.SelectorFromSet(map[string]string{
+		statefulset.KeyDynatrace:  statefulset.ValueActiveGate,
+		statefulset.KeyActiveGate: dk.Name,
+	})
+	return listPods(ctx, apiReader, dk.Namespace, selector)
+}
+
+func listPods(ctx context.Context, apiReader client.Reader, namespace string, selector labels.Selector) ([]corev1.Pod, error) {
+	var podList corev1.PodList
+	if err := apiReader.List(ctx, &podList, client.InNamespace(namespace), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return podList.Items, nil
+}
+
+func allReady(pods []corev1.Pod) bool {
+	for _, pod := range pods {
+		if !isReady(&pod) {
+			return false
+		}
+	}
+	return true
+}
+
+func isReady(pod *corev1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
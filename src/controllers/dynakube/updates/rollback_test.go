@@ -0,0 +1,219 @@
+package updates
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/activegate/reconciler/statefulset"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/status"
+	"github.com/Dynatrace/dynatrace-operator/src/scheme/fake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var testPodLabels = map[string]string{"app": "oneagent"}
+
+func TestReconcileRollbacks(t *testing.T) {
+	ctx := context.Background()
+
+	dkTemplate := dynatracev1beta1.DynaKube{
+		ObjectMeta: metav1.ObjectMeta{Name: testName, Namespace: testNamespace},
+		Spec: dynatracev1beta1.DynaKubeSpec{
+			APIURL: testApiUrl,
+			OneAgent: dynatracev1beta1.OneAgentSpec{
+				ClassicFullStack: &dynatracev1beta1.ClassicFullStackSpec{},
+			},
+		},
+	}
+
+	t.Run(`failed OneAgent update is rolled back and updates are paused until acknowledged`, func(t *testing.T) {
+		const oldVersion, newVersion = "1.220.0.20210701-120000", "1.221.0.20210801-120000"
+		dk := dkTemplate.DeepCopy()
+		dkState, fakeClient, _ := testInitDynakubeState(t, dk)
+		oneAgentStatus := &dkState.Instance.Status.OneAgent
+		registry := newFakeRegistry(map[string]string{oneAgentImagePath: oldVersion})
+		require.NoError(t, fakeClient.Create(ctx, testOneAgentDaemonSet(dk)))
+
+		_, err := ReconcileVersions(ctx, dkState, fakeClient, registry.ImageVersionExt)
+		require.NoError(t, err)
+		assert.Equal(t, oldVersion, oneAgentStatus.Version)
+		assert.Empty(t, oneAgentStatus.PreviousVersion)
+		previousHash := oneAgentStatus.ImageHash
+
+		registry.SetVersion(oneAgentImagePath, newVersion)
+		testChangeTime(t, dkState, ProbeThreshold+time.Second)
+		_, err = ReconcileVersions(ctx, dkState, fakeClient, registry.ImageVersionExt)
+		require.NoError(t, err)
+		assert.Equal(t, newVersion, oneAgentStatus.Version)
+		assert.Equal(t, oldVersion, oneAgentStatus.PreviousVersion)
+		assert.Equal(t, previousHash, oneAgentStatus.PreviousImageHash)
+		failedHash := oneAgentStatus.ImageHash
+
+		require.NoError(t, fakeClient.Create(ctx, testPod("oneagent-a", dkState.Now, newVersion, false)))
+
+		upd, err := ReconcileRollbacks(ctx, dkState, fakeClient)
+		require.NoError(t, err)
+		assert.False(t, upd, "pods may become ready until the deadline")
+
+		testChangeTime(t, dkState, RollbackDeadline+time.Second)
+		upd, err = ReconcileRollbacks(ctx, dkState, fakeClient)
+		require.NoError(t, err)
+		assert.True(t, upd)
+		assert.Equal(t, oldVersion, oneAgentStatus.Version)
+		assert.Equal(t, previousHash, oneAgentStatus.ImageHash)
+		assert.Empty(t, oneAgentStatus.PreviousVersion)
+		require.NotNil(t, oneAgentStatus.RolledBack)
+		assert.Equal(t, newVersion, oneAgentStatus.RolledBack.Version)
+		assert.Equal(t, failedHash, oneAgentStatus.RolledBack.ImageHash)
+		assert.Equal(t, testDockerRegistry+"/linux/oneagent@sha256:"+previousHash, dk.ImmutableOneAgentImage())
+
+		condition := RollbackCondition(dk)
+		require.NotNil(t, condition)
+		assert.Equal(t, dynatracev1beta1.RolledBackConditionType, condition.Type)
+		assert.Equal(t, metav1.ConditionTrue, condition.Status)
+		assert.Equal(t, dynatracev1beta1.ReasonUpdateFailed, condition.Reason)
+		assert.Contains(t, condition.Message, "OneAgent update to version "+newVersion+" was rolled back to "+oldVersion)
+
+		testChangeTime(t, dkState, ProbeThreshold+time.Second)
+		upd, err = ReconcileVersions(ctx, dkState, fakeClient, registry.ImageVersionExt)
+		require.NoError(t, err)
+		assert.False(t, upd, "updates are paused after a rollback")
+		assert.Equal(t, oldVersion, oneAgentStatus.Version)
+
+		dk.Annotations = map[string]string{dynatracev1beta1.AnnotationAcknowledgeRollback: newVersion}
+		upd, err = ReconcileRollbacks(ctx, dkState, fakeClient)
+		require.NoError(t, err)
+		assert.True(t, upd)
+		assert.Nil(t, oneAgentStatus.RolledBack)
+		assert.Nil(t, oneAgentStatus.LastUpdateProbeTimestamp)
+		assert.Nil(t, RollbackCondition(dk))
+	})
+
+	t.Run(`update is confirmed once all pods are updated and ready`, func(t *testing.T) {
+		dk := dkTemplate.DeepCopy()
+		dk.Status.OneAgent.VersionStatus = dynatracev1beta1.VersionStatus{
+			Version:           "1.1.0",
+			PreviousVersion:   "1.0.0",
+			PreviousImageHash: "abcdef",
+		}
+		now := metav1.Now()
+		fakeClient := fake.NewClient(
+			testOneAgentDaemonSet(dk),
+			testPod("oneagent-a", now, "1.1.0", true),
+			testPod("oneagent-b", now, "1.0.0", true),
+		)
+		dkState := &status.DynakubeState{Instance: dk, Now: now}
+
+		upd, err := ReconcileRollbacks(ctx, dkState, fakeClient)
+		require.NoError(t, err)
+		assert.False(t, upd, "pod b is still outdated")
+		assert.Equal(t, "1.0.0", dk.Status.OneAgent.PreviousVersion)
+
+		require.NoError(t, fakeClient.Delete(ctx, testPod("oneagent-b", now, "1.0.0", true)))
+		upd, err = ReconcileRollbacks(ctx, dkState, fakeClient)
+		require.NoError(t, err)
+		assert.True(t, upd)
+		assert.Equal(t, "1.1.0", dk.Status.OneAgent.Version)
+		assert.Empty(t, dk.Status.OneAgent.PreviousVersion)
+		assert.Empty(t, dk.Status.OneAgent.PreviousImageHash)
+	})
+
+	t.Run(`no rollback if most updated pods are ready`, func(t *testing.T) {
+		dk := dkTemplate.DeepCopy()
+		dk.Status.OneAgent.VersionStatus = dynatracev1beta1.VersionStatus{
+			Version:           "1.1.0",
+			PreviousVersion:   "1.0.0",
+			PreviousImageHash: "abcdef",
+		}
+		created := metav1.NewTime(time.Now().Add(-RollbackDeadline - time.Minute))
+		fakeClient := fake.NewClient(
+			testOneAgentDaemonSet(dk),
+			testPod("oneagent-a", created, "1.1.0", false),
+			testPod("oneagent-b", created, "1.1.0", true),
+			testPod("oneagent-c", created, "1.1.0", true),
+		)
+		dkState := &status.DynakubeState{Instance: dk, Now: metav1.Now()}
+
+		upd, err := ReconcileRollbacks(ctx, dkState, fakeClient)
+		require.NoError(t, err)
+		assert.False(t, upd)
+		assert.Equal(t, "1.1.0", dk.Status.OneAgent.Version)
+		assert.Nil(t, dk.Status.OneAgent.RolledBack)
+	})
+
+	t.Run(`no rollback if disabled by feature flag`, func(t *testing.T) {
+		dk := dkTemplate.DeepCopy()
+		dk.Annotations = map[string]string{"alpha.operator.dynatrace.com/feature-disable-automatic-rollback": "true"}
+		dk.Status.OneAgent.VersionStatus = dynatracev1beta1.VersionStatus{
+			Version:           "1.1.0",
+			PreviousVersion:   "1.0.0",
+			PreviousImageHash: "abcdef",
+		}
+		created := metav1.NewTime(time.Now().Add(-RollbackDeadline - time.Minute))
+		fakeClient := fake.NewClient(testOneAgentDaemonSet(dk), testPod("oneagent-a", created, "1.1.0", false))
+		dkState := &status.DynakubeState{Instance: dk, Now: metav1.Now()}
+
+		upd, err := ReconcileRollbacks(ctx, dkState, fakeClient)
+		require.NoError(t, err)
+		assert.False(t, upd)
+		assert.Equal(t, "1.1.0", dk.Status.OneAgent.Version)
+	})
+
+	t.Run(`failed ActiveGate update is rolled back`, func(t *testing.T) {
+		dk := dkTemplate.DeepCopy()
+		dk.Spec.OneAgent = dynatracev1beta1.OneAgentSpec{}
+		dk.Spec.ActiveGate.Capabilities = []dynatracev1beta1.CapabilityDisplayName{dynatracev1beta1.RoutingCapability.DisplayName}
+		dk.Status.ActiveGate.VersionStatus = dynatracev1beta1.VersionStatus{
+			Version:           "1.1.0",
+			ImageHash:         "123456",
+			PreviousVersion:   "1.0.0",
+			PreviousImageHash: "abcdef",
+		}
+		pod := testPod("activegate-0", metav1.NewTime(time.Now().Add(-RollbackDeadline-time.Minute)), "1.1.0", false)
+		pod.Labels = statefulset.BuildLabelsFromInstance(dk, "routing")
+		fakeClient := fake.NewClient(pod)
+		dkState := &status.DynakubeState{Instance: dk, Now: metav1.Now()}
+
+		upd, err := ReconcileRollbacks(ctx, dkState, fakeClient)
+		require.NoError(t, err)
+		assert.True(t, upd)
+		assert.Equal(t, "1.0.0", dk.Status.ActiveGate.Version)
+		require.NotNil(t, dk.Status.ActiveGate.RolledBack)
+		assert.Equal(t, "1.1.0", dk.Status.ActiveGate.RolledBack.Version)
+		assert.Equal(t, testDockerRegistry+"/linux/activegate@sha256:abcdef", dk.ActiveGateImage())
+	})
+}
+
+func testOneAgentDaemonSet(dk *dynatracev1beta1.DynaKube) *appsv1.DaemonSet {
+	return &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{Name: dk.OneAgentDaemonsetName(), Namespace: dk.Namespace},
+		Spec: appsv1.DaemonSetSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: testPodLabels},
+		},
+	}
+}
+
+func testPod(name string, created metav1.Time, version string, ready bool) *corev1.Pod {
+	readyStatus := corev1.ConditionFalse
+	if ready {
+		readyStatus = corev1.ConditionTrue
+	}
+
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         testNamespace,
+			Labels:            testPodLabels,
+			Annotations:       map[string]string{annotationVersion: version},
+			CreationTimestamp: created,
+		},
+		Status: corev1.PodStatus{
+			Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: readyStatus}},
+		},
+	}
+}
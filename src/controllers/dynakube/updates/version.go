@@ -8,6 +8,7 @@ import (
 
 	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
 	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/dtversion"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/maintenancewindow"
 	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/status"
 	"github.com/Dynatrace/dynatrace-operator/src/dtclient"
 	"github.com/pkg/errors"
@@ -31,21 +32,28 @@ func ReconcileVersions(
 	upd := false
 	dk := dkState.Instance
 
+	windowOpen, err := maintenancewindow.IsOpen(dk.Spec.MaintenanceWindows, dkState.Now.Time)
+	if err != nil {
+		return upd, errors.WithMessage(err, "failed to check maintenance windows")
+	}
+
 	needsOneAgentUpdate := dk.NeedsOneAgent() &&
-		dkState.IsOutdated(dk.Status.OneAgent.LastUpdateProbeTimestamp, ProbeThreshold) &&
+		dk.Status.OneAgent.RolledBack == nil &&
+		needsProbe(dkState, &dk.Status.OneAgent.VersionStatus, windowOpen) &&
 		dk.ShouldAutoUpdateOneAgent()
 
 	needsActiveGateUpdate := dk.NeedsActiveGate() &&
 		!dk.FeatureDisableActiveGateUpdates() &&
-		dkState.IsOutdated(dk.Status.ActiveGate.LastUpdateProbeTimestamp, ProbeThreshold)
+		dk.Status.ActiveGate.RolledBack == nil &&
+		needsProbe(dkState, &dk.Status.ActiveGate.VersionStatus, windowOpen)
 
 	needsEecUpdate := dk.NeedsStatsd() &&
 		!dk.FeatureDisableActiveGateUpdates() &&
-		dkState.IsOutdated(dk.Status.ExtensionController.LastUpdateProbeTimestamp, ProbeThreshold)
+		needsProbe(dkState, &dk.Status.ExtensionController.VersionStatus, windowOpen)
 
 	needsStatsdUpdate := dk.NeedsStatsd() &&
 		!dk.FeatureDisableActiveGateUpdates() &&
-		dkState.IsOutdated(dk.Status.Statsd.LastUpdateProbeTimestamp, ProbeThreshold)
+		needsProbe(dkState, &dk.Status.Statsd.VersionStatus, windowOpen)
 
 	if !(needsActiveGateUpdate || needsOneAgentUpdate || needsEecUpdate || needsStatsdUpdate) {
 		return upd, nil
@@ -63,33 +71,38 @@ func ReconcileVersions(
 
 	dockerCfg := dtversion.DockerConfig{Auths: auths, SkipCertCheck: dk.Spec.SkipCertCheck}
 	if dk.Spec.TrustedCAs != "" {
-		dockerCfg.UseTrustedCerts = saveCustomCAs(cl, *dk)
+		dockerCfg.UseTrustedCerts = SaveCustomCAs(cl, *dk)
 		defer func() {
 			_ = os.Remove(path.Join(dtversion.TmpCAPath, dtversion.TmpCAName))
 		}()
 	}
 	upd = true // updateImageVersion() always updates the status
 
+	// the images are pinned to the digest of the deployed version, the registry is probed by tag
+	probedDk := dk.DeepCopy()
+	probedDk.Status.ActiveGate.ImageHash = ""
+	probedDk.Status.OneAgent.ImageHash = ""
+
 	if needsActiveGateUpdate {
-		if err := updateImageVersion(dkState, dk.ActiveGateImage(), &dk.Status.ActiveGate.VersionStatus, &dockerCfg, verProvider, true); err != nil {
+		if err := updateWatchedImageVersion(dkState, probedDk.ActiveGateImage(), &dk.Status.ActiveGate.VersionStatus, &dockerCfg, verProvider, true, windowOpen); err != nil {
 			log.Error(err, "failed to update ActiveGate image version")
 		}
 	}
 
 	if needsEecUpdate {
-		if err := updateImageVersion(dkState, dk.EecImage(), &dk.Status.ExtensionController.VersionStatus, &dockerCfg, verProvider, true); err != nil {
+		if err := updateImageVersion(dkState, dk.EecImage(), &dk.Status.ExtensionController.VersionStatus, &dockerCfg, verProvider, true, windowOpen); err != nil {
 			log.Error(err, "Failed to update Extension Controller image version")
 		}
 	}
 
 	if needsStatsdUpdate {
-		if err := updateImageVersion(dkState, dk.StatsdImage(), &dk.Status.Statsd.VersionStatus, &dockerCfg, verProvider, true); err != nil {
+		if err := updateImageVersion(dkState, dk.StatsdImage(), &dk.Status.Statsd.VersionStatus, &dockerCfg, verProvider, true, windowOpen); err != nil {
 			log.Error(err, "Failed to update StatsD image version")
 		}
 	}
 
 	if needsOneAgentUpdate {
-		if err := updateImageVersion(dkState, dk.ImmutableOneAgentImage(), &dk.Status.OneAgent.VersionStatus, &dockerCfg, verProvider, false); err != nil {
+		if err := updateWatchedImageVersion(dkState, probedDk.ImmutableOneAgentImage(), &dk.Status.OneAgent.VersionStatus, &dockerCfg, verProvider, false, windowOpen); err != nil {
 			log.Error(err, "failed to update OneAgent image version")
 		}
 	}
@@ -97,7 +110,16 @@ func ReconcileVersions(
 	return upd, nil
 }
 
-func saveCustomCAs(cl client.Client, dk dynatracev1beta1.DynaKube) bool {
+// needsProbe returns true if the version of a component is probed again, which is the case after the ProbeThreshold
+// or as soon as a maintenance window opens for a pending version.
+func needsProbe(dkState *status.DynakubeState, versionStatus *dynatracev1beta1.VersionStatus, windowOpen bool) bool {
+	return dkState.IsOutdated(versionStatus.LastUpdateProbeTimestamp, ProbeThreshold) ||
+		windowOpen && versionStatus.PendingVersion != ""
+}
+
+// SaveCustomCAs stores the trusted CAs of the DynaKube where the registry client of dtversion picks them up.
+// Returns false if the CAs couldn't be stored.
+func SaveCustomCAs(cl client.Client, dk dynatracev1beta1.DynaKube) bool {
 	certs := &corev1.ConfigMap{}
 	if err := cl.Get(context.TODO(), client.ObjectKey{Namespace: dk.Namespace, Name: dk.Spec.TrustedCAs}, certs); err != nil {
 		log.Error(err, "failed to load trusted CAs")
@@ -115,6 +137,30 @@ func saveCustomCAs(cl client.Client, dk dynatracev1beta1.DynaKube) bool {
 	return true
 }
 
+// updateWatchedImageVersion updates the version like updateImageVersion, but keeps the deployed version until the pods
+// of the update are ready, so ReconcileRollbacks can restore it if they are not.
+func updateWatchedImageVersion(
+	dkState *status.DynakubeState,
+	img string,
+	target *dynatracev1beta1.VersionStatus,
+	dockerCfg *dtversion.DockerConfig,
+	verProvider VersionProviderCallback,
+	allowDowngrades bool,
+	windowOpen bool,
+) error {
+	deployed := target.Status()
+	if err := updateImageVersion(dkState, img, target, dockerCfg, verProvider, allowDowngrades, windowOpen); err != nil {
+		return err
+	}
+
+	// Keep the version of the last successful rollout, if an update is replaced before it got ready
+	if target.Version != deployed.Version && target.PreviousVersion == "" {
+		target.PreviousVersion = deployed.Version
+		target.PreviousImageHash = deployed.ImageHash
+	}
+	return nil
+}
+
 func updateImageVersion(
 	dkState *status.DynakubeState,
 	img string,
@@ -122,6 +168,7 @@ func updateImageVersion(
 	dockerCfg *dtversion.DockerConfig,
 	verProvider VersionProviderCallback,
 	allowDowngrades bool,
+	windowOpen bool,
 ) error {
 	target.LastUpdateProbeTimestamp = dkState.Now.DeepCopy()
 
@@ -131,6 +178,8 @@ func updateImageVersion(
 	}
 
 	if target.Version == ver.Version {
+		target.PendingVersion = ""
+		target.PendingImageHash = ""
 		return nil
 	}
 
@@ -142,11 +191,24 @@ func updateImageVersion(
 		}
 	}
 
+	// The deployed version is kept until the next maintenance window, the first version is deployed right away
+	if !windowOpen && target.Version != "" {
+		if target.PendingVersion != ver.Version {
+			log.Info("update found outside of the maintenance windows, keeping it pending",
+				"image", img, "version", target.Version, "pendingVersion", ver.Version)
+		}
+		target.PendingVersion = ver.Version
+		target.PendingImageHash = ver.Hash
+		return nil
+	}
+
 	log.Info("update found",
 		"image", img,
 		"oldVersion", target.Version, "newVersion", ver.Version,
 		"oldHash", target.ImageHash, "newHash", ver.Hash)
 	target.Version = ver.Version
 	target.ImageHash = ver.Hash
+	target.PendingVersion = ""
+	target.PendingImageHash = ""
 	return nil
 }
@@ -21,6 +21,7 @@ import (
 	"crypto/sha256"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
@@ -157,6 +158,53 @@ func TestReconcile_UpdateImageVersion(t *testing.T) {
 			assertVersionStatusEquals(t, registry, statsdImagePath, now, &status.Statsd)
 		}
 	})
+
+	t.Run("updates are pending outside of the maintenance windows", func(t *testing.T) {
+		dk := dkTemplate.DeepCopy()
+		dkState, fakeClient, _ := testInitDynakubeState(t, dk)
+		dkState.Now = metav1.NewTime(time.Date(2022, time.March, 7, 21, 55, 0, 0, time.UTC))
+		dk.Spec.MaintenanceWindows = []dynatracev1beta1.MaintenanceWindowSpec{{
+			Schedule: "0 22 * * *",
+			Duration: metav1.Duration{Duration: 2 * time.Hour},
+		}}
+		status := &dkState.Instance.Status
+		status.ActiveGate.Version = "1.0.0"
+		status.ActiveGate.ImageHash = "sha256:deployed"
+		registry := newFakeRegistry(map[string]string{
+			agImagePath:       "1.0.1",
+			eecImagePath:      "1.0.0",
+			statsdImagePath:   "1.0.0",
+			oneAgentImagePath: "1.0.0",
+		})
+
+		deployedImage := dk.ActiveGateImage()
+		{
+			upd, err := ReconcileVersions(ctx, dkState, fakeClient, registry.ImageVersionExt)
+			assert.NoError(t, err)
+			assert.True(t, upd)
+			assert.Equal(t, "1.0.0", status.ActiveGate.Version)
+			assert.Equal(t, "1.0.1", status.ActiveGate.PendingVersion)
+			assert.NotEmpty(t, status.ActiveGate.PendingImageHash)
+
+			// the pod template is unchanged, restarted pods keep the deployed image
+			assert.Equal(t, deployedImage, dk.ActiveGateImage())
+			assert.True(t, strings.HasSuffix(dk.ActiveGateImage(), "@sha256:deployed"), dk.ActiveGateImage())
+			assert.Equal(t, "1.0.0", status.OneAgent.Version, "the first version is deployed right away")
+			assert.Empty(t, status.OneAgent.PendingVersion)
+		}
+
+		testChangeTime(t, dkState, 6*time.Minute)
+		{
+			upd, err := ReconcileVersions(ctx, dkState, fakeClient, registry.ImageVersionExt)
+			assert.NoError(t, err)
+			assert.True(t, upd, "pending versions are probed when the window opens")
+			assertVersionStatusEquals(t, registry, agImagePath, dkState.Now, &status.ActiveGate)
+			assert.Empty(t, status.ActiveGate.PendingVersion)
+			assert.Empty(t, status.ActiveGate.PendingImageHash)
+			assert.Equal(t, "1.0.0", status.ActiveGate.PreviousVersion)
+			assert.True(t, strings.HasSuffix(dk.ActiveGateImage(), status.ActiveGate.ImageHash), dk.ActiveGateImage())
+		}
+	})
 }
 
 type fakeRegistry struct {
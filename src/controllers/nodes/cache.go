@@ -17,6 +17,13 @@ type CacheEntry struct {
 	IPAddress                string    `json:"ip"`
 	LastSeen                 time.Time `json:"seen"`
 	LastMarkedForTermination time.Time `json:"marked"`
+
+	// Reported is the state of the node which was last reported by lifecycle events
+	Reported         *NodeState `json:"reported,omitempty"`
+	LastEventFailure time.Time  `json:"eventFailure,omitempty"`
+
+	// LastEntityLookupFailure is when the host entity of the node was last looked up without success
+	LastEntityLookupFailure time.Time `json:"entityLookupFailure,omitempty"`
 }
 
 // Cache manages information about Nodes.
@@ -4,15 +4,38 @@ import (
 	"time"
 
 	"github.com/Dynatrace/dynatrace-operator/src/logger"
+	corev1 "k8s.io/api/core/v1"
 )
 
 const (
 	cacheName                   = "dynatrace-node-cache"
 	cacheLifetime               = 10 * time.Minute
 	lastUpdatedCacheAnnotiation = "DTOperatorLastUpdated"
+
+	// labelInstance selects the DynaKubeNodes of a dynakube
+	labelInstance = "operator.dynatrace.com/instance"
+	// maxTerminationEvents is the number of termination events kept per node in the node statuses
+	maxTerminationEvents = 5
+	// nodeStatusRetention is how long removed nodes are kept in the node statuses
+	nodeStatusRetention = time.Hour
+	// entityLookupRetryInterval is the time to wait before looking up the host entity of a node again, after it was not found
+	entityLookupRetryInterval = 5 * time.Minute
+
+	// nodeAddedThreshold is the age up to which a node, which is seen for the first time, is reported as added
+	nodeAddedThreshold = time.Hour
+	// eventRetryInterval is the time to wait before sending lifecycle events again, after sending them failed
+	eventRetryInterval = 5 * time.Minute
+
+	eventSource = "Dynatrace Operator"
 )
 
 var (
 	log                 = logger.NewDTLogger().WithName("nodes-controller")
 	unschedulableTaints = []string{"ToBeDeletedByClusterAutoscaler"}
+	interruptionTaints  = []string{
+		"aws-node-termination-handler/spot-itn",
+		"aws-node-termination-handler/rebalance-recommendation",
+		"cloud.google.com/impending-node-termination",
+	}
+	pressureConditions = []corev1.NodeConditionType{corev1.NodeMemoryPressure, corev1.NodeDiskPressure, corev1.NodePIDPressure}
 )
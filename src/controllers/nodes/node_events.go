@@ -0,0 +1,165 @@
+package nodes
+
+import (
+	"fmt"
+	"time"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	"github.com/Dynatrace/dynatrace-operator/src/dtclient"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	instanceTypeLabel = "node.kubernetes.io/instance-type"
+
+	nodeNameProperty = "k8s.node.name"
+	dynakubeProperty = "dynakube"
+)
+
+// NodeState is the state of a node, which is compared with the last reported one to detect lifecycle changes.
+type NodeState struct {
+	Ready          bool     `json:"ready"`
+	Pressure       []string `json:"pressure,omitempty"`
+	KernelVersion  string   `json:"kernel,omitempty"`
+	KubeletVersion string   `json:"kubelet,omitempty"`
+	Interruptions  []string `json:"interruptions,omitempty"`
+}
+
+func newNodeState(node *corev1.Node, dynakube *dynatracev1beta1.DynaKube) NodeState {
+	state := NodeState{
+		KernelVersion:  node.Status.NodeInfo.KernelVersion,
+		KubeletVersion: node.Status.NodeInfo.KubeletVersion,
+	}
+
+	if condition := findNodeCondition(node, corev1.NodeReady); condition != nil {
+		state.Ready = condition.Status == corev1.ConditionTrue
+	}
+
+	for _, conditionType := range pressureConditions {
+		if condition := findNodeCondition(node, conditionType); condition != nil && condition.Status == corev1.ConditionTrue {
+			state.Pressure = append(state.Pressure, string(conditionType))
+		}
+	}
+
+	taints := append(append([]string{}, interruptionTaints...), dynakube.Spec.NodeEvents.InterruptionTaints...)
+	for _, taint := range node.Spec.Taints {
+		if contains(taints, taint.Key) && !contains(state.Interruptions, taint.Key) {
+			state.Interruptions = append(state.Interruptions, taint.Key)
+		}
+	}
+	return state
+}
+
+// nodeLifecycleEvents returns the enabled events for the changes between the previous and the current state of the node.
+// Without a previous state only the NodeAdded event is considered, and only for nodes younger than nodeAddedThreshold.
+func nodeLifecycleEvents(dynakube *dynatracev1beta1.DynaKube, node *corev1.Node, previous *NodeState, current NodeState, now time.Time) []*dtclient.EventData {
+	var events []*dtclient.EventData
+
+	if previous == nil {
+		if dynakube.NodeEventEnabled(dynatracev1beta1.NodeEventAdded) && node.CreationTimestamp.Add(nodeAddedThreshold).After(now) {
+			properties := map[string]string{
+				"kernelVersion":  current.KernelVersion,
+				"kubeletVersion": current.KubeletVersion,
+			}
+			if instanceType, ok := node.Labels[instanceTypeLabel]; ok {
+				properties["instanceType"] = instanceType
+			}
+			events = append(events, newNodeEvent(dynakube, node, dtclient.CustomInfoEvent, "Kubernetes node added",
+				"Kubernetes node joined the cluster.", properties))
+		}
+		return events
+	}
+
+	if dynakube.NodeEventEnabled(dynatracev1beta1.NodeEventNotReady) && previous.Ready != current.Ready {
+		properties := map[string]string{}
+		if condition := findNodeCondition(node, corev1.NodeReady); condition != nil {
+			properties["reason"] = condition.Reason
+			properties["message"] = condition.Message
+		}
+		if current.Ready {
+			events = append(events, newNodeEvent(dynakube, node, dtclient.CustomInfoEvent, "Kubernetes node ready",
+				"Kubernetes node is ready again.", properties))
+		} else {
+			events = append(events, newNodeEvent(dynakube, node, dtclient.CustomInfoEvent, "Kubernetes node not ready",
+				"Kubernetes node is not ready.", properties))
+		}
+	}
+
+	if dynakube.NodeEventEnabled(dynatracev1beta1.NodeEventPressure) {
+		for _, condition := range current.Pressure {
+			if !contains(previous.Pressure, condition) {
+				events = append(events, newNodeEvent(dynakube, node, dtclient.CustomInfoEvent, "Kubernetes node "+condition,
+					fmt.Sprintf("Kubernetes node reports %s.", condition), map[string]string{"condition": condition}))
+			}
+		}
+		for _, condition := range previous.Pressure {
+			if !contains(current.Pressure, condition) {
+				events = append(events, newNodeEvent(dynakube, node, dtclient.CustomInfoEvent, "Kubernetes node "+condition+" resolved",
+					fmt.Sprintf("Kubernetes node no longer reports %s.", condition), map[string]string{"condition": condition}))
+			}
+		}
+	}
+
+	if dynakube.NodeEventEnabled(dynatracev1beta1.NodeEventVersionChanged) &&
+		(previous.KernelVersion != current.KernelVersion || previous.KubeletVersion != current.KubeletVersion) {
+		events = append(events, newNodeEvent(dynakube, node, dtclient.CustomConfigurationEvent, "Kubernetes node version changed",
+			"Kernel or kubelet version of the Kubernetes node changed.", map[string]string{
+				"kernelVersion":           current.KernelVersion,
+				"kernelVersion.previous":  previous.KernelVersion,
+				"kubeletVersion":          current.KubeletVersion,
+				"kubeletVersion.previous": previous.KubeletVersion,
+			}))
+	}
+
+	if dynakube.NodeEventEnabled(dynatracev1beta1.NodeEventInterruption) {
+		for _, taint := range current.Interruptions {
+			if !contains(previous.Interruptions, taint) {
+				events = append(events, newNodeEvent(dynakube, node, dtclient.CustomInfoEvent, "Kubernetes node interruption",
+					"Kubernetes node received a spot or preemptible interruption notice. Node is likely to be terminated.",
+					map[string]string{"taint": taint}))
+			}
+		}
+	}
+
+	return events
+}
+
+func newNodeEvent(dynakube *dynatracev1beta1.DynaKube, node *corev1.Node, eventType string, title string, description string,
+	properties map[string]string) *dtclient.EventData {
+
+	customProperties := make(map[string]string, len(dynakube.Spec.NodeEvents.CustomProperties)+len(properties)+2)
+	for key, value := range dynakube.Spec.NodeEvents.CustomProperties {
+		customProperties[key] = value
+	}
+	for key, value := range properties {
+		customProperties[key] = value
+	}
+	customProperties[nodeNameProperty] = node.Name
+	customProperties[dynakubeProperty] = dynakube.Name
+
+	return &dtclient.EventData{
+		EventType:        eventType,
+		Source:           eventSource,
+		Title:            title,
+		Description:      description,
+		CustomProperties: customProperties,
+	}
+}
+
+func findNodeCondition(node *corev1.Node, conditionType corev1.NodeConditionType) *corev1.NodeCondition {
+	for i := range node.Status.Conditions {
+		if node.Status.Conditions[i].Type == conditionType {
+			return &node.Status.Conditions[i]
+		}
+	}
+	return nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
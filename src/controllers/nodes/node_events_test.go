@@ -0,0 +1,156 @@
+package nodes
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	"github.com/Dynatrace/dynatrace-operator/src/dtclient"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestNodeLifecycleEvents(t *testing.T) {
+	now := time.Now().UTC()
+	dynakube := &dynatracev1beta1.DynaKube{
+		ObjectMeta: metav1.ObjectMeta{Name: "dynakube"},
+		Spec: dynatracev1beta1.DynaKubeSpec{
+			NodeEvents: dynatracev1beta1.NodeEventsSpec{
+				Events: []dynatracev1beta1.NodeEventType{
+					dynatracev1beta1.NodeEventAdded,
+					dynatracev1beta1.NodeEventNotReady,
+					dynatracev1beta1.NodeEventPressure,
+					dynatracev1beta1.NodeEventVersionChanged,
+					dynatracev1beta1.NodeEventInterruption,
+				},
+				CustomProperties:   map[string]string{"team": "infra", dynakubeProperty: "overridden"},
+				InterruptionTaints: []string{"example.com/preempted"},
+			},
+		},
+	}
+
+	t.Run(`new nodes are reported as added`, func(t *testing.T) {
+		node := createNode(now.Add(-time.Minute), nil, nil)
+		node.Labels = map[string]string{instanceTypeLabel: "m5.large"}
+
+		events := nodeLifecycleEvents(dynakube, node, nil, newNodeState(node, dynakube), now)
+
+		require.Len(t, events, 1)
+		assert.Equal(t, dtclient.CustomInfoEvent, events[0].EventType)
+		assert.Equal(t, "Kubernetes node added", events[0].Title)
+		assert.Equal(t, "m5.large", events[0].CustomProperties["instanceType"])
+		assert.Equal(t, "node1", events[0].CustomProperties[nodeNameProperty])
+		assert.Equal(t, "dynakube", events[0].CustomProperties[dynakubeProperty])
+		assert.Equal(t, "infra", events[0].CustomProperties["team"])
+	})
+	t.Run(`old nodes seen for the first time are not reported`, func(t *testing.T) {
+		node := createNode(now.Add(-2*nodeAddedThreshold), nil, nil)
+
+		assert.Empty(t, nodeLifecycleEvents(dynakube, node, nil, newNodeState(node, dynakube), now))
+	})
+	t.Run(`unchanged nodes are not reported`, func(t *testing.T) {
+		node := createNode(now, []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue}}, nil)
+		state := newNodeState(node, dynakube)
+
+		assert.Empty(t, nodeLifecycleEvents(dynakube, node, &state, newNodeState(node, dynakube), now))
+	})
+	t.Run(`changes are reported`, func(t *testing.T) {
+		previousNode := createNode(now, []corev1.NodeCondition{
+			{Type: corev1.NodeReady, Status: corev1.ConditionTrue},
+			{Type: corev1.NodeDiskPressure, Status: corev1.ConditionTrue},
+		}, nil)
+		previous := newNodeState(previousNode, dynakube)
+
+		node := createNode(now, []corev1.NodeCondition{
+			{Type: corev1.NodeReady, Status: corev1.ConditionFalse, Reason: "KubeletNotReady"},
+			{Type: corev1.NodeMemoryPressure, Status: corev1.ConditionTrue},
+			{Type: corev1.NodeDiskPressure, Status: corev1.ConditionFalse},
+		}, []corev1.Taint{{Key: "aws-node-termination-handler/spot-itn"}, {Key: "example.com/preempted"}, {Key: "other"}})
+		node.Status.NodeInfo.KubeletVersion = "v1.23.1"
+
+		events := nodeLifecycleEvents(dynakube, node, &previous, newNodeState(node, dynakube), now)
+
+		var titles []string
+		for _, event := range events {
+			titles = append(titles, event.Title)
+		}
+		assert.Equal(t, []string{
+			"Kubernetes node not ready",
+			"Kubernetes node MemoryPressure",
+			"Kubernetes node DiskPressure resolved",
+			"Kubernetes node version changed",
+			"Kubernetes node interruption",
+			"Kubernetes node interruption",
+		}, titles)
+		assert.Equal(t, "KubeletNotReady", events[0].CustomProperties["reason"])
+		assert.Equal(t, dtclient.CustomConfigurationEvent, events[3].EventType)
+		assert.Equal(t, "v1.23.1", events[3].CustomProperties["kubeletVersion"])
+		assert.Equal(t, "v1.22.0", events[3].CustomProperties["kubeletVersion.previous"])
+		assert.Equal(t, "aws-node-termination-handler/spot-itn", events[4].CustomProperties["taint"])
+		assert.Equal(t, "example.com/preempted", events[5].CustomProperties["taint"])
+	})
+	t.Run(`disabled events are not reported`, func(t *testing.T) {
+		node := createNode(now, nil, nil)
+		previous := NodeState{Ready: true}
+
+		assert.Empty(t, nodeLifecycleEvents(&dynatracev1beta1.DynaKube{}, node, &previous, newNodeState(node, dynakube), now))
+	})
+}
+
+func TestNodesReconciler_LifecycleEvents(t *testing.T) {
+	fakeClient := createDefaultFakeClient()
+	var dk dynatracev1beta1.DynaKube
+	require.NoError(t, fakeClient.Get(context.TODO(), client.ObjectKey{Name: "oneagent1", Namespace: testNamespace}, &dk))
+	dk.Spec.NodeEvents.Events = []dynatracev1beta1.NodeEventType{dynatracev1beta1.NodeEventNotReady}
+	require.NoError(t, fakeClient.Update(context.TODO(), &dk))
+
+	dtClient := &dtclient.MockDynatraceClient{}
+	dtClient.On("GetEntityIDForIP", "1.2.3.4").Return("HOST-42", nil)
+	dtClient.On("SendEvent", mock.MatchedBy(func(e *dtclient.EventData) bool {
+		return e.Title == "Kubernetes node not ready" && e.AttachRules.EntityIDs[0] == "HOST-42"
+	})).Return(nil).Once()
+	defer mock.AssertExpectationsForObjects(t, dtClient)
+
+	ctrl := createDefaultReconciler(fakeClient, dtClient)
+	setNodeReady(t, fakeClient, corev1.ConditionTrue)
+	_, err := ctrl.Reconcile(context.TODO(), createReconcileRequest("node1"))
+	require.NoError(t, err)
+
+	setNodeReady(t, fakeClient, corev1.ConditionFalse)
+	_, err = ctrl.Reconcile(context.TODO(), createReconcileRequest("node1"))
+	require.NoError(t, err)
+
+	// already reported, so no further event is sent
+	_, err = ctrl.Reconcile(context.TODO(), createReconcileRequest("node1"))
+	require.NoError(t, err)
+
+	c, err := ctrl.getCache()
+	require.NoError(t, err)
+	entry, err := c.Get("node1")
+	require.NoError(t, err)
+	require.NotNil(t, entry.Reported)
+	assert.False(t, entry.Reported.Ready)
+}
+
+func createNode(created time.Time, conditions []corev1.NodeCondition, taints []corev1.Taint) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node1", CreationTimestamp: metav1.NewTime(created)},
+		Spec:       corev1.NodeSpec{Taints: taints},
+		Status: corev1.NodeStatus{
+			Conditions: conditions,
+			NodeInfo:   corev1.NodeSystemInfo{KernelVersion: "5.4.0", KubeletVersion: "v1.22.0"},
+		},
+	}
+}
+
+func setNodeReady(t *testing.T, fakeClient client.Client, ready corev1.ConditionStatus) {
+	var node corev1.Node
+	require.NoError(t, fakeClient.Get(context.TODO(), client.ObjectKey{Name: "node1"}, &node))
+	node.Status.Conditions = []corev1.NodeCondition{{Type: corev1.NodeReady, Status: ready}}
+	require.NoError(t, fakeClient.Update(context.TODO(), &node))
+}
@@ -0,0 +1,202 @@
+package nodes
+
+import (
+	"sort"
+	"time"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NodeStatus is the status of a node, which is kept in the DynaKubeNode of the node and the dynakube running a OneAgent on it.
+type NodeStatus = dynatracev1beta1.DynaKubeNodeStatus
+
+// DynaKubeNodeName returns the name of the DynaKubeNode of the dynakube and the node.
+// Keeping a DynaKubeNode per node out of the status of the dynakube keeps it small on large clusters.
+func DynaKubeNodeName(dynakube *dynatracev1beta1.DynaKube, nodeName string) string {
+	return dynakube.Name + "-" + nodeName
+}
+
+// NodeStatuses manages the DynaKubeNodes of a dynakube, keyed by node name.
+// The changes are tracked per node, so only the changed DynaKubeNodes are written.
+type NodeStatuses struct {
+	dynakube *dynatracev1beta1.DynaKube
+	nodes    map[string]*dynatracev1beta1.DynaKubeNode
+	created  map[string]bool
+	updated  map[string]bool
+	deleted  map[string]*dynatracev1beta1.DynaKubeNode
+}
+
+func newNodeStatuses(dynakube *dynatracev1beta1.DynaKube, dynakubeNodes []dynatracev1beta1.DynaKubeNode) *NodeStatuses {
+	statuses := &NodeStatuses{
+		dynakube: dynakube,
+		nodes:    make(map[string]*dynatracev1beta1.DynaKubeNode, len(dynakubeNodes)),
+	}
+	for i := range dynakubeNodes {
+		statuses.nodes[dynakubeNodes[i].Spec.NodeName] = &dynakubeNodes[i]
+	}
+	statuses.clearChanges()
+	return statuses
+}
+
+// Get returns a copy of the status of the node, or nil if the node is not listed.
+func (statuses *NodeStatuses) Get(nodeName string) *NodeStatus {
+	dynakubeNode, ok := statuses.nodes[nodeName]
+	if !ok {
+		return nil
+	}
+	return dynakubeNode.Status.DeepCopy()
+}
+
+// Set updates the status of the node, the DynaKubeNode is created if the node is not listed yet.
+func (statuses *NodeStatuses) Set(nodeName string, nodeStatus *NodeStatus) {
+	dynakubeNode, ok := statuses.nodes[nodeName]
+	if !ok {
+		dynakubeNode = &dynatracev1beta1.DynaKubeNode{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      DynaKubeNodeName(statuses.dynakube, nodeName),
+				Namespace: statuses.dynakube.Namespace,
+				Labels:    map[string]string{labelInstance: statuses.dynakube.Name},
+			},
+			Spec: dynatracev1beta1.DynaKubeNodeSpec{
+				DynaKube: statuses.dynakube.Name,
+				NodeName: nodeName,
+			},
+		}
+		statuses.nodes[nodeName] = dynakubeNode
+		if _, wasDeleted := statuses.deleted[nodeName]; wasDeleted {
+			delete(statuses.deleted, nodeName)
+			statuses.updated[nodeName] = true
+		} else {
+			statuses.created[nodeName] = true
+		}
+	} else if equality.Semantic.DeepEqual(dynakubeNode.Status, *nodeStatus) {
+		return
+	} else if !statuses.created[nodeName] {
+		statuses.updated[nodeName] = true
+	}
+	dynakubeNode.Status = *nodeStatus.DeepCopy()
+}
+
+// Delete removes the node from the statuses.
+func (statuses *NodeStatuses) Delete(nodeName string) {
+	dynakubeNode, ok := statuses.nodes[nodeName]
+	if !ok {
+		return
+	}
+	delete(statuses.nodes, nodeName)
+	delete(statuses.updated, nodeName)
+	if statuses.created[nodeName] {
+		delete(statuses.created, nodeName)
+	} else {
+		statuses.deleted[nodeName] = dynakubeNode
+	}
+}
+
+// NodeNames returns the names of the listed nodes.
+func (statuses *NodeStatuses) NodeNames() []string {
+	nodeNames := make([]string, 0, len(statuses.nodes))
+	for nodeName := range statuses.nodes {
+		nodeNames = append(nodeNames, nodeName)
+	}
+	sort.Strings(nodeNames)
+	return nodeNames
+}
+
+// Changed returns true if changes have been made to the statuses.
+func (statuses *NodeStatuses) Changed() bool {
+	return len(statuses.created)+len(statuses.updated)+len(statuses.deleted) > 0
+}
+
+// clearChanges forgets the changes, after they were written.
+func (statuses *NodeStatuses) clearChanges() {
+	statuses.created = map[string]bool{}
+	statuses.updated = map[string]bool{}
+	statuses.deleted = map[string]*dynatracev1beta1.DynaKubeNode{}
+}
+
+// setNodeStatus adds the node to the statuses, or updates its ip address.
+func setNodeStatus(statuses *NodeStatuses, nodeName string, ipAddress string) {
+	nodeStatus := statuses.Get(nodeName)
+	if nodeStatus == nil {
+		statuses.Set(nodeName, &NodeStatus{IPAddress: ipAddress})
+		return
+	}
+
+	if nodeStatus.IPAddress != ipAddress {
+		nodeStatus.IPAddress = ipAddress
+		nodeStatus.HostEntityID = ""
+	}
+	nodeStatus.RemovedTimestamp = nil
+	statuses.Set(nodeName, nodeStatus)
+}
+
+// setHostEntityID sets the entity id of the OneAgent host of the node, if the node is listed.
+func setHostEntityID(statuses *NodeStatuses, nodeName string, entityID string) {
+	nodeStatus := statuses.Get(nodeName)
+	if nodeStatus == nil {
+		return
+	}
+	nodeStatus.HostEntityID = entityID
+	statuses.Set(nodeName, nodeStatus)
+}
+
+// addTerminationEvent records a sent, or failed, MARKED_FOR_TERMINATION event for the node, keeping only the latest ones.
+func addTerminationEvent(statuses *NodeStatuses, nodeName string, cachedNode CacheEntry,
+	entityID string, reason dynatracev1beta1.NodeTerminationReason, cause error) {
+
+	nodeStatus := statuses.Get(nodeName)
+	if nodeStatus == nil {
+		nodeStatus = &NodeStatus{}
+	}
+
+	if nodeStatus.IPAddress == "" {
+		nodeStatus.IPAddress = cachedNode.IPAddress
+	}
+	if entityID != "" {
+		nodeStatus.HostEntityID = entityID
+	}
+
+	event := dynatracev1beta1.NodeTerminationEvent{
+		Timestamp: metav1.Now(),
+		Reason:    reason,
+	}
+	if cause != nil {
+		event.Error = cause.Error()
+	}
+
+	nodeStatus.TerminationEvents = append(nodeStatus.TerminationEvents, event)
+	if len(nodeStatus.TerminationEvents) > maxTerminationEvents {
+		nodeStatus.TerminationEvents = nodeStatus.TerminationEvents[len(nodeStatus.TerminationEvents)-maxTerminationEvents:]
+	}
+	statuses.Set(nodeName, nodeStatus)
+}
+
+// setNodeRemoved marks the node as removed from the cluster.
+func setNodeRemoved(statuses *NodeStatuses, nodeName string, now time.Time) {
+	nodeStatus := statuses.Get(nodeName)
+	if nodeStatus == nil || nodeStatus.RemovedTimestamp != nil {
+		return
+	}
+	removed := metav1.NewTime(now)
+	nodeStatus.RemovedTimestamp = &removed
+	statuses.Set(nodeName, nodeStatus)
+}
+
+// pruneNodeStatuses marks nodes which are no longer in the cluster as removed,
+// and drops them from the statuses once they have been removed for longer than nodeStatusRetention.
+func pruneNodeStatuses(statuses *NodeStatuses, clusterNodes map[string]bool, now time.Time) {
+	for _, nodeName := range statuses.NodeNames() {
+		if clusterNodes[nodeName] {
+			continue
+		}
+
+		nodeStatus := statuses.Get(nodeName)
+		if nodeStatus.RemovedTimestamp != nil && nodeStatus.RemovedTimestamp.Add(nodeStatusRetention).Before(now) {
+			statuses.Delete(nodeName)
+		} else {
+			setNodeRemoved(statuses, nodeName, now)
+		}
+	}
+}
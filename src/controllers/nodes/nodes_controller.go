@@ -102,8 +102,20 @@ func (controller *NodesController) Reconcile(ctx context.Context, request reconc
 
 		if cached, err := nodeCache.Get(nodeName); err == nil {
 			cacheEntry.LastMarkedForTermination = cached.LastMarkedForTermination
+			cacheEntry.Reported = cached.Reported
+			cacheEntry.LastEventFailure = cached.LastEventFailure
+			cacheEntry.LastEntityLookupFailure = cached.LastEntityLookupFailure
 		}
 
+		controller.sendLifecycleEvents(dynakube, &node, &cacheEntry)
+
+		nodeStatuses, err := controller.getNodeStatuses(ctx, dynakube)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+		setNodeStatus(nodeStatuses, nodeName, ipAddress)
+		controller.lookupHostEntityID(dynakube, nodeStatuses, nodeName, &cacheEntry)
+
 		if err := nodeCache.Set(nodeName, cacheEntry); err != nil {
 			return reconcile.Result{}, err
 		}
@@ -116,9 +128,15 @@ func (controller *NodesController) Reconcile(ctx context.Context, request reconc
 				nodeName:   nodeName,
 			}
 
-			if err := controller.markForTermination(dynakube, cachedNodeData); err != nil {
+			markErr := controller.markForTermination(dynakube, cachedNodeData, nodeStatuses, dynatracev1beta1.NodeUnschedulable)
+			if err := controller.updateNodeStatuses(ctx, nodeStatuses); err != nil {
 				return reconcile.Result{}, err
 			}
+			if markErr != nil {
+				return reconcile.Result{}, markErr
+			}
+		} else if err := controller.updateNodeStatuses(ctx, nodeStatuses); err != nil {
+			return reconcile.Result{}, err
 		}
 	}
 
@@ -159,9 +177,19 @@ func (controller *NodesController) reconcileNodeDeletion(nodeName string) error
 			nodeName:   nodeName,
 		}
 
-		if err := controller.markForTermination(dynakube, cachedNodeData); err != nil {
+		nodeStatuses, err := controller.getNodeStatuses(context.TODO(), dynakube)
+		if err != nil {
 			return err
 		}
+
+		markErr := controller.markForTermination(dynakube, cachedNodeData, nodeStatuses, dynatracev1beta1.NodeDeleted)
+		setNodeRemoved(nodeStatuses, nodeName, time.Now().UTC())
+		if err := controller.updateNodeStatuses(context.TODO(), nodeStatuses); err != nil {
+			return err
+		}
+		if markErr != nil {
+			return markErr
+		}
 	}
 
 	nodeCache.Delete(nodeName)
@@ -220,6 +248,41 @@ func (controller *NodesController) updateCache(nodeCache *Cache, ctx context.Con
 	return nil
 }
 
+// getNodeStatuses returns the node statuses of the dynakube from its DynaKubeNodes, which are owned by the dynakube and removed along with it.
+func (controller *NodesController) getNodeStatuses(ctx context.Context, dynakube *dynatracev1beta1.DynaKube) (*NodeStatuses, error) {
+	var dynakubeNodes dynatracev1beta1.DynaKubeNodeList
+	err := controller.client.List(ctx, &dynakubeNodes, client.InNamespace(dynakube.Namespace), client.MatchingLabels{labelInstance: dynakube.Name})
+	if err != nil {
+		return nil, err
+	}
+	return newNodeStatuses(dynakube, dynakubeNodes.Items), nil
+}
+
+// updateNodeStatuses creates, updates and deletes the DynaKubeNodes of the changed nodes.
+func (controller *NodesController) updateNodeStatuses(ctx context.Context, nodeStatuses *NodeStatuses) error {
+	for nodeName := range nodeStatuses.created {
+		dynakubeNode := nodeStatuses.nodes[nodeName]
+		if err := controllerutil.SetControllerReference(nodeStatuses.dynakube, dynakubeNode, controller.scheme); err != nil {
+			return err
+		}
+		if err := controller.client.Create(ctx, dynakubeNode); err != nil {
+			return err
+		}
+	}
+	for nodeName := range nodeStatuses.updated {
+		if err := controller.client.Update(ctx, nodeStatuses.nodes[nodeName]); err != nil {
+			return err
+		}
+	}
+	for _, dynakubeNode := range nodeStatuses.deleted {
+		if err := controller.client.Delete(ctx, dynakubeNode); err != nil && !k8serrors.IsNotFound(err) {
+			return err
+		}
+	}
+	nodeStatuses.clearChanges()
+	return nil
+}
+
 func (controller *NodesController) handleOutdatedCache(nodeCache *Cache) error {
 	var nodeLst corev1.NodeList
 	if err := controller.client.List(context.TODO(), &nodeLst); err != nil {
@@ -251,6 +314,32 @@ func (controller *NodesController) handleOutdatedCache(nodeCache *Cache) error {
 			}
 		}
 	}
+	return controller.pruneNodeStatuses(nodeLst)
+}
+
+// pruneNodeStatuses removes nodes from the node statuses of the dynakubes, which are no longer part of the cluster
+func (controller *NodesController) pruneNodeStatuses(nodeLst corev1.NodeList) error {
+	dynakubeList, err := controller.getDynakubeList()
+	if err != nil {
+		return err
+	}
+
+	clusterNodes := make(map[string]bool, len(nodeLst.Items))
+	for _, node := range nodeLst.Items {
+		clusterNodes[node.Name] = true
+	}
+
+	now := time.Now().UTC()
+	for i := range dynakubeList.Items {
+		nodeStatuses, err := controller.getNodeStatuses(context.TODO(), &dynakubeList.Items[i])
+		if err != nil {
+			return err
+		}
+		pruneNodeStatuses(nodeStatuses, clusterNodes, now)
+		if err := controller.updateNodeStatuses(context.TODO(), nodeStatuses); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -269,39 +358,116 @@ func (controller *NodesController) isNodeDeleteable(cachedNode CacheEntry) bool
 	return false
 }
 
-func (controller *NodesController) sendMarkedForTermination(dynakubeInstance *dynatracev1beta1.DynaKube, cachedNode CacheEntry) error {
+// sendMarkedForTermination sends the event to the host entity of the node and returns the id of the entity
+func (controller *NodesController) sendMarkedForTermination(dynakubeInstance *dynatracev1beta1.DynaKube, cachedNode CacheEntry) (string, error) {
+	ts := uint64(cachedNode.LastSeen.Add(-10*time.Minute).UnixNano()) / uint64(time.Millisecond)
+	return controller.sendNodeEvents(dynakubeInstance, cachedNode.IPAddress, &dtclient.EventData{
+		EventType:     dtclient.MarkedForTerminationEvent,
+		Source:        eventSource,
+		Description:   "Kubernetes node cordoned. Node might be drained or terminated.",
+		StartInMillis: ts,
+		EndInMillis:   ts,
+	})
+}
+
+// sendLifecycleEvents sends the enabled lifecycle events for the changes since the last reported state of the node.
+// Failures are logged and retried after eventRetryInterval, they don't fail the reconciliation of the node.
+func (controller *NodesController) sendLifecycleEvents(dynakubeInstance *dynatracev1beta1.DynaKube, node *corev1.Node, cacheEntry *CacheEntry) {
+	now := time.Now().UTC()
+	current := newNodeState(node, dynakubeInstance)
+
+	events := nodeLifecycleEvents(dynakubeInstance, node, cacheEntry.Reported, current, now)
+	if len(events) == 0 {
+		cacheEntry.Reported = &current
+		return
+	}
+
+	if cacheEntry.LastEventFailure.Add(eventRetryInterval).After(now) {
+		return
+	}
+
+	ts := uint64(now.UnixNano()) / uint64(time.Millisecond)
+	for _, event := range events {
+		event.StartInMillis = ts
+		event.EndInMillis = ts
+	}
+
+	log.Info("sending node lifecycle events to dynatrace server", "dynakube", dynakubeInstance.Name, "node", node.Name, "events", len(events))
+	if _, err := controller.sendNodeEvents(dynakubeInstance, cacheEntry.IPAddress, events...); err != nil {
+		cacheEntry.LastEventFailure = now
+		return
+	}
+
+	cacheEntry.Reported = &current
+	cacheEntry.LastEventFailure = time.Time{}
+}
+
+// lookupHostEntityID sets the id of the OneAgent host entity of the node, if it is not known yet.
+// A failed lookup is logged and retried after entityLookupRetryInterval, it doesn't fail the reconciliation of the node.
+func (controller *NodesController) lookupHostEntityID(dynakubeInstance *dynatracev1beta1.DynaKube, nodeStatuses *NodeStatuses, nodeName string, cacheEntry *CacheEntry) {
+	now := time.Now().UTC()
+	nodeStatus := nodeStatuses.Get(nodeName)
+	if nodeStatus == nil || nodeStatus.HostEntityID != "" || nodeStatus.IPAddress == "" ||
+		cacheEntry.LastEntityLookupFailure.Add(entityLookupRetryInterval).After(now) {
+		return
+	}
+
+	dtc, err := controller.buildDynatraceClient(dynakubeInstance)
+	if err != nil {
+		cacheEntry.LastEntityLookupFailure = now
+		return
+	}
+
+	entityID, err := dtc.GetEntityIDForIP(nodeStatus.IPAddress)
+	if err != nil {
+		log.Info("failed to determine host entity id", "dynakube", dynakubeInstance.Name, "node", nodeName, "nodeIP", nodeStatus.IPAddress, "cause", err)
+		cacheEntry.LastEntityLookupFailure = now
+		return
+	}
+
+	cacheEntry.LastEntityLookupFailure = time.Time{}
+	setHostEntityID(nodeStatuses, nodeName, entityID)
+}
+
+func (controller *NodesController) buildDynatraceClient(dynakubeInstance *dynatracev1beta1.DynaKube) (dtclient.Client, error) {
 	dtp, err := dynakube.NewDynatraceClientProperties(context.TODO(), controller.client, *dynakubeInstance)
 	if err != nil {
 		log.Error(err, err.Error())
 	}
 
-	dtc, err := controller.dtClientFunc(*dtp)
+	return controller.dtClientFunc(*dtp)
+}
+
+// sendNodeEvents attaches the events to the host entity with the given ip, sends them and returns the id of the entity
+func (controller *NodesController) sendNodeEvents(dynakubeInstance *dynatracev1beta1.DynaKube, ipAddress string, events ...*dtclient.EventData) (string, error) {
+	dtc, err := controller.buildDynatraceClient(dynakubeInstance)
 	if err != nil {
-		return err
+		return "", err
 	}
 
-	entityID, err := dtc.GetEntityIDForIP(cachedNode.IPAddress)
+	entityID, err := dtc.GetEntityIDForIP(ipAddress)
 	if err != nil {
-		log.Info("failed to send mark for termination event",
-			"reason", "failed to determine entity id", "dynakube", dynakubeInstance.Name, "nodeIP", cachedNode.IPAddress, "cause", err)
+		log.Info("failed to send node event",
+			"reason", "failed to determine entity id", "dynakube", dynakubeInstance.Name, "nodeIP", ipAddress, "cause", err)
 
-		return err
+		return "", err
 	}
 
-	ts := uint64(cachedNode.LastSeen.Add(-10*time.Minute).UnixNano()) / uint64(time.Millisecond)
-	return dtc.SendEvent(&dtclient.EventData{
-		EventType:     dtclient.MarkedForTerminationEvent,
-		Source:        "Dynatrace Operator",
-		Description:   "Kubernetes node cordoned. Node might be drained or terminated.",
-		StartInMillis: ts,
-		EndInMillis:   ts,
-		AttachRules: dtclient.EventDataAttachRules{
+	for _, event := range events {
+		event.AttachRules = dtclient.EventDataAttachRules{
 			EntityIDs: []string{entityID},
-		},
-	})
+		}
+		if err := dtc.SendEvent(event); err != nil {
+			log.Info("failed to send node event", "eventType", event.EventType, "dynakube", dynakubeInstance.Name, "nodeIP", ipAddress, "cause", err)
+			return entityID, err
+		}
+	}
+	return entityID, nil
 }
 
-func (controller *NodesController) markForTermination(dynakube *dynatracev1beta1.DynaKube, cachedNodeData CachedNodeInfo) error {
+// markForTermination sends the event and records it in the node statuses, which have to be updated by the caller, also if it fails
+func (controller *NodesController) markForTermination(dynakube *dynatracev1beta1.DynaKube, cachedNodeData CachedNodeInfo,
+	nodeStatuses *NodeStatuses, reason dynatracev1beta1.NodeTerminationReason) error {
 	if !controller.isMarkableForTermination(&cachedNodeData.cachedNode) {
 		return nil
 	}
@@ -311,9 +477,11 @@ func (controller *NodesController) markForTermination(dynakube *dynatracev1beta1
 	}
 
 	log.Info("sending mark for termination event to dynatrace server", "dynakube", dynakube.Name, "ip", cachedNodeData.cachedNode.IPAddress,
-		"node", cachedNodeData.nodeName)
+		"node", cachedNodeData.nodeName, "reason", reason)
 
-	return controller.sendMarkedForTermination(dynakube, cachedNodeData.cachedNode)
+	entityID, sendErr := controller.sendMarkedForTermination(dynakube, cachedNodeData.cachedNode)
+	addTerminationEvent(nodeStatuses, cachedNodeData.nodeName, cachedNodeData.cachedNode, entityID, reason, sendErr)
+	return sendErr
 }
 
 func (controller *NodesController) isUnschedulable(node *corev1.Node) bool {
@@ -28,6 +28,7 @@ func TestNodesReconciler_CreateCache(t *testing.T) {
 	fakeClient := createDefaultFakeClient()
 
 	dtClient := &dtclient.MockDynatraceClient{}
+	dtClient.On("GetEntityIDForIP", "1.2.3.4").Return("HOST-42", nil)
 	defer mock.AssertExpectationsForObjects(t, dtClient)
 
 	ctrl := createDefaultReconciler(fakeClient, dtClient)
@@ -149,6 +150,159 @@ func Test_RemoveNode_ServerError(t *testing.T) {
 
 }
 
+func TestNodesReconciler_NodeStatus(t *testing.T) {
+	t.Run(`nodes are listed in the node statuses of their dynakube`, func(t *testing.T) {
+		fakeClient := createDefaultFakeClient()
+		dtClient := &dtclient.MockDynatraceClient{}
+		dtClient.On("GetEntityIDForIP", "1.2.3.4").Return("HOST-42", nil).Once()
+		dtClient.On("GetEntityIDForIP", "5.6.7.8").Return("HOST-43", nil).Once()
+		defer mock.AssertExpectationsForObjects(t, dtClient)
+
+		ctrl := createDefaultReconciler(fakeClient, dtClient)
+		reconcileAllNodes(t, ctrl, fakeClient)
+		reconcileAllNodes(t, ctrl, fakeClient)
+
+		nodeStatuses := getNodeStatuses(t, fakeClient, "oneagent1")
+		assert.Equal(t, []string{"node1"}, nodeStatuses.NodeNames())
+		nodeStatus := nodeStatuses.Get("node1")
+		require.NotNil(t, nodeStatus)
+		assert.Equal(t, "1.2.3.4", nodeStatus.IPAddress)
+		assert.Equal(t, "HOST-42", nodeStatus.HostEntityID)
+		assert.Empty(t, nodeStatus.TerminationEvents)
+
+		nodeStatuses = getNodeStatuses(t, fakeClient, "oneagent2")
+		assert.Equal(t, []string{"node2"}, nodeStatuses.NodeNames())
+
+		var dynakubeNode dynatracev1beta1.DynaKubeNode
+		require.NoError(t, fakeClient.Get(context.TODO(), client.ObjectKey{Name: "oneagent2-node2", Namespace: testNamespace}, &dynakubeNode))
+		assert.Equal(t, dynatracev1beta1.DynaKubeNodeSpec{DynaKube: "oneagent2", NodeName: "node2"}, dynakubeNode.Spec)
+		assert.Equal(t, "5.6.7.8", dynakubeNode.Status.IPAddress)
+		require.Len(t, dynakubeNode.OwnerReferences, 1)
+		assert.Equal(t, "oneagent2", dynakubeNode.OwnerReferences[0].Name)
+
+		assert.Empty(t, getDynakube(t, fakeClient, "oneagent1").Status.UpdatedTimestamp)
+	})
+	t.Run(`failed host entity lookups are retried later`, func(t *testing.T) {
+		fakeClient := createDefaultFakeClient()
+		dtClient := &dtclient.MockDynatraceClient{}
+		dtClient.On("GetEntityIDForIP", mock.Anything).Return("", ErrNotFound)
+		ctrl := createDefaultReconciler(fakeClient, dtClient)
+
+		reconcileAllNodes(t, ctrl, fakeClient)
+		reconcileAllNodes(t, ctrl, fakeClient)
+
+		dtClient.AssertNumberOfCalls(t, "GetEntityIDForIP", 2)
+		assert.Empty(t, getNodeStatuses(t, fakeClient, "oneagent1").Get("node1").HostEntityID)
+	})
+	t.Run(`termination events are recorded with the host entity id`, func(t *testing.T) {
+		fakeClient := createDefaultFakeClient()
+		dtClient := createDTMockClient("1.2.3.4", "HOST-42")
+		ctrl := createDefaultReconciler(fakeClient, dtClient)
+		reconcileAllNodes(t, ctrl, fakeClient)
+
+		var node1 corev1.Node
+		require.NoError(t, fakeClient.Get(context.TODO(), client.ObjectKey{Name: "node1"}, &node1))
+		node1.Spec.Unschedulable = true
+		require.NoError(t, fakeClient.Update(context.TODO(), &node1))
+
+		_, err := ctrl.Reconcile(context.TODO(), createReconcileRequest("node1"))
+		require.NoError(t, err)
+
+		require.NoError(t, ctrl.reconcileNodeDeletion("node1"))
+
+		nodeStatus := getNodeStatuses(t, fakeClient, "oneagent1").Get("node1")
+		require.NotNil(t, nodeStatus)
+		assert.Equal(t, "HOST-42", nodeStatus.HostEntityID)
+		assert.NotNil(t, nodeStatus.RemovedTimestamp)
+		// the deletion happened within an hour of the cordon, so no second event is sent
+		require.Len(t, nodeStatus.TerminationEvents, 1)
+		assert.Equal(t, dynatracev1beta1.NodeUnschedulable, nodeStatus.TerminationEvents[0].Reason)
+		assert.Empty(t, nodeStatus.TerminationEvents[0].Error)
+	})
+	t.Run(`failed termination events are recorded with their cause`, func(t *testing.T) {
+		fakeClient := createDefaultFakeClient()
+		dtClient := &dtclient.MockDynatraceClient{}
+		dtClient.On("GetEntityIDForIP", mock.Anything).Return("", ErrNotFound)
+		ctrl := createDefaultReconciler(fakeClient, dtClient)
+		reconcileAllNodes(t, ctrl, fakeClient)
+
+		assert.Error(t, ctrl.reconcileNodeDeletion("node1"))
+
+		nodeStatus := getNodeStatuses(t, fakeClient, "oneagent1").Get("node1")
+		require.NotNil(t, nodeStatus)
+		require.Len(t, nodeStatus.TerminationEvents, 1)
+		assert.Equal(t, dynatracev1beta1.NodeDeleted, nodeStatus.TerminationEvents[0].Reason)
+		assert.Equal(t, ErrNotFound.Error(), nodeStatus.TerminationEvents[0].Error)
+		assert.Empty(t, nodeStatus.HostEntityID)
+	})
+	t.Run(`dynakube nodes of removed nodes are deleted after the retention`, func(t *testing.T) {
+		fakeClient := createDefaultFakeClient()
+		dtClient := createDTMockClient("1.2.3.4", "HOST-42")
+		ctrl := createDefaultReconciler(fakeClient, dtClient)
+		reconcileAllNodes(t, ctrl, fakeClient)
+
+		nodeStatuses := getNodeStatuses(t, fakeClient, "oneagent1")
+		longAgo := metav1.NewTime(time.Now().Add(-2 * nodeStatusRetention))
+		nodeStatuses.Set("node1", &NodeStatus{IPAddress: "1.2.3.4", RemovedTimestamp: &longAgo})
+		require.NoError(t, ctrl.updateNodeStatuses(context.TODO(), nodeStatuses))
+
+		require.NoError(t, ctrl.pruneNodeStatuses(corev1.NodeList{}))
+
+		var dynakubeNodes dynatracev1beta1.DynaKubeNodeList
+		require.NoError(t, fakeClient.List(context.TODO(), &dynakubeNodes))
+		require.Len(t, dynakubeNodes.Items, 1)
+		assert.Equal(t, "node2", dynakubeNodes.Items[0].Spec.NodeName)
+		assert.NotNil(t, dynakubeNodes.Items[0].Status.RemovedTimestamp)
+	})
+}
+
+func TestPruneNodeStatuses(t *testing.T) {
+	now := time.Now().UTC()
+	longAgo := metav1.NewTime(now.Add(-2 * nodeStatusRetention))
+	nodeStatuses := newNodeStatuses(&dynatracev1beta1.DynaKube{ObjectMeta: metav1.ObjectMeta{Name: "oneagent1", Namespace: testNamespace}}, nil)
+	nodeStatuses.Set("node1", &NodeStatus{})
+	nodeStatuses.Set("node2", &NodeStatus{})
+	nodeStatuses.Set("node3", &NodeStatus{RemovedTimestamp: &longAgo})
+	nodeStatuses.clearChanges()
+
+	pruneNodeStatuses(nodeStatuses, map[string]bool{"node1": true}, now)
+	assert.True(t, nodeStatuses.Changed())
+	assert.Equal(t, []string{"node1", "node2"}, nodeStatuses.NodeNames())
+	assert.Nil(t, nodeStatuses.Get("node1").RemovedTimestamp)
+	assert.NotNil(t, nodeStatuses.Get("node2").RemovedTimestamp)
+	assert.Nil(t, nodeStatuses.Get("node3"))
+	assert.Contains(t, nodeStatuses.deleted, "node3")
+
+	nodeStatuses.clearChanges()
+	pruneNodeStatuses(nodeStatuses, map[string]bool{"node1": true}, now)
+	assert.False(t, nodeStatuses.Changed())
+}
+
+func TestAddTerminationEvent(t *testing.T) {
+	nodeStatuses := newNodeStatuses(&dynatracev1beta1.DynaKube{ObjectMeta: metav1.ObjectMeta{Name: "oneagent1", Namespace: testNamespace}}, nil)
+	for i := 0; i < maxTerminationEvents+2; i++ {
+		addTerminationEvent(nodeStatuses, "node1", CacheEntry{IPAddress: "1.2.3.4"}, "HOST-42", dynatracev1beta1.NodeUnschedulable, nil)
+	}
+
+	nodeStatus := nodeStatuses.Get("node1")
+	require.NotNil(t, nodeStatus)
+	assert.Equal(t, "1.2.3.4", nodeStatus.IPAddress)
+	assert.Equal(t, "HOST-42", nodeStatus.HostEntityID)
+	assert.Len(t, nodeStatus.TerminationEvents, maxTerminationEvents)
+}
+
+func getNodeStatuses(t *testing.T, fakeClient client.Client, dynakubeName string) *NodeStatuses {
+	var dynakubeNodes dynatracev1beta1.DynaKubeNodeList
+	require.NoError(t, fakeClient.List(context.TODO(), &dynakubeNodes, client.MatchingLabels{labelInstance: dynakubeName}))
+	return newNodeStatuses(getDynakube(t, fakeClient, dynakubeName), dynakubeNodes.Items)
+}
+
+func getDynakube(t *testing.T, fakeClient client.Client, name string) *dynatracev1beta1.DynaKube {
+	var dk dynatracev1beta1.DynaKube
+	require.NoError(t, fakeClient.Get(context.TODO(), client.ObjectKey{Name: name, Namespace: testNamespace}, &dk))
+	return &dk
+}
+
 func createReconcileRequest(nodeName string) reconcile.Request {
 	return reconcile.Request{
 		NamespacedName: types.NamespacedName{Namespace: testNamespace, Name: nodeName},
@@ -168,6 +322,8 @@ func createDefaultReconciler(fakeClient client.Client, dtClient *dtclient.MockDy
 func createDTMockClient(ip, host string) *dtclient.MockDynatraceClient {
 	dtClient := &dtclient.MockDynatraceClient{}
 	dtClient.On("GetEntityIDForIP", ip).Return(host, nil)
+	// the host entities of the other nodes are looked up too
+	dtClient.On("GetEntityIDForIP", mock.Anything).Return("", ErrNotFound).Maybe()
 	dtClient.On("SendEvent", mock.MatchedBy(func(e *dtclient.EventData) bool {
 		return e.EventType == "MARKED_FOR_TERMINATION"
 	})).Return(nil)
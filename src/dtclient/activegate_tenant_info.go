@@ -30,7 +30,7 @@ func (dtc *dynatraceClient) GetActiveGateTenantInfo() (*ActiveGateTenantInfo, er
 
 	data, err := dtc.getServerResponseData(response)
 	if err != nil {
-		return nil, dtc.handleErrorResponseFromAPI(data, response.StatusCode)
+		return nil, err
 	}
 
 	tenantInfo, err := dtc.readResponseForActiveGateTenantInfo(data)
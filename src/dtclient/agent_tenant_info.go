@@ -31,7 +31,7 @@ func (dtc *dynatraceClient) GetAgentTenantInfo() (*AgentTenantInfo, error) {
 
 	data, err := dtc.getServerResponseData(response)
 	if err != nil {
-		return nil, errors.WithStack(dtc.handleErrorResponseFromAPI(data, response.StatusCode))
+		return nil, errors.WithStack(err)
 	}
 
 	tenantInfo, err := dtc.readResponseForTenantInfo(data)
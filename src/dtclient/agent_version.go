@@ -75,9 +75,9 @@ func (dtc *dynatraceClient) GetLatestAgent(os, installerType, flavor, arch strin
 	}
 
 	url := dtc.getLatestAgentUrl(os, installerType, flavor, arch, technologies)
-	md5, err := dtc.makeRequestForBinary(url, dynatracePaaSToken, writer)
+	checksum, err := dtc.makeRequestForBinary(url, dynatracePaaSToken, writer)
 	if err == nil {
-		log.Info("downloaded agent file", "os", os, "type", installerType, "flavor", flavor, "arch", arch, "technologies", technologies, "md5", md5)
+		log.Info("downloaded agent file", "os", os, "type", installerType, "flavor", flavor, "arch", arch, "technologies", technologies, "sha256", checksum)
 	}
 	return err
 }
@@ -102,17 +102,17 @@ func (dtc *dynatraceClient) GetAgent(os, installerType, flavor, arch, version st
 	}
 
 	url := dtc.getAgentUrl(os, installerType, flavor, arch, version, technologies)
-	md5, err := dtc.makeRequestForBinary(url, dynatracePaaSToken, writer)
+	checksum, err := dtc.makeRequestForBinary(url, dynatracePaaSToken, writer)
 	if err == nil {
-		log.Info("downloaded agent file", "os", os, "type", installerType, "flavor", flavor, "arch", arch, "technologies", technologies, "md5", md5)
+		log.Info("downloaded agent file", "os", os, "type", installerType, "flavor", flavor, "arch", arch, "technologies", technologies, "sha256", checksum)
 	}
 	return err
 }
 
 func (dtc *dynatraceClient) GetAgentViaInstallerUrl(url string, writer io.Writer) error {
-	md5, err := dtc.makeRequestForBinary(url, installerUrlToken, writer)
+	checksum, err := dtc.makeRequestForBinary(url, installerUrlToken, writer)
 	if err == nil {
-		log.Info("downloaded agent file using given url", "url", url, "md5", md5)
+		log.Info("downloaded agent file using given url", "url", url, "sha256", checksum)
 	}
 	return err
 }
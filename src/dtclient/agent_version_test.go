@@ -1,9 +1,15 @@
 package dtclient
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"net/http"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
@@ -235,6 +241,111 @@ func TestDynatraceClient_GetAgent(t *testing.T) {
 	})
 }
 
+func TestDynatraceClient_GetAgentResumable(t *testing.T) {
+	t.Run(`resume interrupted download`, func(t *testing.T) {
+		handler := &interruptingAgentHandler{interruptAfter: 5, etag: `"v1"`}
+		dynatraceServer, _ := createTestDynatraceClientWithFunc(t, handler.ServeHTTP)
+		defer dynatraceServer.Close()
+
+		dtc := dynatraceClient{
+			httpClient: dynatraceServer.Client(),
+			url:        dynatraceServer.URL,
+			paasToken:  paasToken,
+		}
+		var buffer bytes.Buffer
+		err := dtc.GetAgent(OsUnix, InstallerTypePaaS, "", "", "1.2.3", nil, &buffer)
+
+		require.NoError(t, err)
+		assert.Equal(t, versionedAgentResponse, buffer.String())
+		assert.Equal(t, []string{"", "bytes=5-"}, handler.ranges)
+		assert.Equal(t, []string{"", `"v1"`}, handler.ifRanges)
+	})
+	t.Run(`start over if the artifact changed`, func(t *testing.T) {
+		handler := &interruptingAgentHandler{interruptAfter: 5, etag: `"v1"`, changedEtag: `"v2"`}
+		dynatraceServer, _ := createTestDynatraceClientWithFunc(t, handler.ServeHTTP)
+		defer dynatraceServer.Close()
+
+		dtc := dynatraceClient{
+			httpClient: dynatraceServer.Client(),
+			url:        dynatraceServer.URL,
+			paasToken:  paasToken,
+		}
+		file, err := afero.TempFile(afero.NewMemMapFs(), "client", "installer")
+		require.NoError(t, err)
+		err = dtc.GetLatestAgent(OsUnix, InstallerTypePaaS, "", "", nil, file)
+		require.NoError(t, err)
+
+		_, err = file.Seek(0, io.SeekStart)
+		require.NoError(t, err)
+		content, err := ioutil.ReadAll(file)
+		require.NoError(t, err)
+		assert.Equal(t, versionedAgentResponse, string(content))
+	})
+	t.Run(`fail if the artifact changed and the partial download can't be discarded`, func(t *testing.T) {
+		handler := &interruptingAgentHandler{interruptAfter: 5, etag: `"v1"`, changedEtag: `"v2"`}
+		dynatraceServer, _ := createTestDynatraceClientWithFunc(t, handler.ServeHTTP)
+		defer dynatraceServer.Close()
+
+		dtc := dynatraceClient{
+			httpClient: dynatraceServer.Client(),
+			url:        dynatraceServer.URL,
+			paasToken:  paasToken,
+		}
+		var buffer bytes.Buffer
+		err := dtc.GetLatestAgent(OsUnix, InstallerTypePaaS, "", "", nil, &buffer)
+
+		require.Error(t, err)
+	})
+	t.Run(`do not resume without validator`, func(t *testing.T) {
+		handler := &interruptingAgentHandler{interruptAfter: 5}
+		dynatraceServer, _ := createTestDynatraceClientWithFunc(t, handler.ServeHTTP)
+		defer dynatraceServer.Close()
+
+		dtc := dynatraceClient{
+			httpClient: dynatraceServer.Client(),
+			url:        dynatraceServer.URL,
+			paasToken:  paasToken,
+		}
+		var buffer bytes.Buffer
+		err := dtc.GetAgent(OsUnix, InstallerTypePaaS, "", "", "1.2.3", nil, &buffer)
+
+		require.Error(t, err)
+		assert.Equal(t, []string{""}, handler.ranges)
+	})
+	t.Run(`verify digest of server`, func(t *testing.T) {
+		handler := &interruptingAgentHandler{digest: sha256Digest(versionedAgentResponse)}
+		dynatraceServer, _ := createTestDynatraceClientWithFunc(t, handler.ServeHTTP)
+		defer dynatraceServer.Close()
+
+		dtc := dynatraceClient{
+			httpClient: dynatraceServer.Client(),
+			url:        dynatraceServer.URL,
+			paasToken:  paasToken,
+		}
+		var buffer bytes.Buffer
+		err := dtc.GetAgent(OsUnix, InstallerTypePaaS, "", "", "1.2.3", nil, &buffer)
+
+		require.NoError(t, err)
+		assert.Equal(t, versionedAgentResponse, buffer.String())
+	})
+	t.Run(`reject download not matching digest of server`, func(t *testing.T) {
+		handler := &interruptingAgentHandler{digest: sha256Digest("something else")}
+		dynatraceServer, _ := createTestDynatraceClientWithFunc(t, handler.ServeHTTP)
+		defer dynatraceServer.Close()
+
+		dtc := dynatraceClient{
+			httpClient: dynatraceServer.Client(),
+			url:        dynatraceServer.URL,
+			paasToken:  paasToken,
+		}
+		var buffer bytes.Buffer
+		err := dtc.GetAgent(OsUnix, InstallerTypePaaS, "", "", "1.2.3", nil, &buffer)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "checksum mismatch")
+	})
+}
+
 func TestDynatraceClient_GetAgentVersions(t *testing.T) {
 	t.Run(`handle response correctly`, func(t *testing.T) {
 		dynatraceServer, _ := createTestDynatraceClientWithFunc(t, versionsRequestHandler)
@@ -295,6 +406,63 @@ func errorHandler(response http.ResponseWriter, _ *http.Request) {
 	_, _ = response.Write([]byte(testErrorMessage))
 }
 
+// interruptingAgentHandler serves versionedAgentResponse and drops the first connection after interruptAfter bytes.
+// The artifact is identified by etag, which changes to changedEtag after the first request if set.
+type interruptingAgentHandler struct {
+	interruptAfter int
+	digest         string
+	etag           string
+	changedEtag    string
+	ranges         []string
+	ifRanges       []string
+}
+
+func (handler *interruptingAgentHandler) ServeHTTP(response http.ResponseWriter, request *http.Request) {
+	rangeHeader := request.Header.Get("Range")
+	handler.ranges = append(handler.ranges, rangeHeader)
+	handler.ifRanges = append(handler.ifRanges, request.Header.Get("If-Range"))
+	response.Header().Set("Accept-Ranges", "bytes")
+
+	etag := handler.etag
+	if handler.changedEtag != "" && len(handler.ranges) > 1 {
+		etag = handler.changedEtag
+	}
+	if etag != "" {
+		response.Header().Set("ETag", etag)
+	}
+
+	if rangeHeader != "" && request.Header.Get("If-Range") == etag {
+		var offset int
+		_, _ = fmt.Sscanf(rangeHeader, "bytes=%d-", &offset)
+		response.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", offset, len(versionedAgentResponse)-1, len(versionedAgentResponse)))
+		response.WriteHeader(http.StatusPartialContent)
+		_, _ = response.Write([]byte(versionedAgentResponse[offset:]))
+		return
+	}
+
+	if handler.digest != "" {
+		response.Header().Set("Digest", handler.digest)
+	}
+	response.Header().Set("Content-Length", strconv.Itoa(len(versionedAgentResponse)))
+
+	if handler.interruptAfter > 0 && len(handler.ranges) == 1 {
+		response.WriteHeader(http.StatusOK)
+		_, _ = response.Write([]byte(versionedAgentResponse[:handler.interruptAfter]))
+		response.(http.Flusher).Flush()
+		conn, _, _ := response.(http.Hijacker).Hijack()
+		_ = conn.Close()
+		return
+	}
+
+	response.WriteHeader(http.StatusOK)
+	_, _ = response.Write([]byte(versionedAgentResponse))
+}
+
+func sha256Digest(content string) string {
+	checksum := sha256.Sum256([]byte(content))
+	return "SHA-256=" + base64.StdEncoding.EncodeToString(checksum[:])
+}
+
 type memoryReadWriter struct {
 	data []byte
 }
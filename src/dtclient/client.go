@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 )
@@ -78,6 +79,18 @@ type Client interface {
 	// GetSettingsForMonitoredEntities returns the settings response with the number of settings objects,
 	// or an api error otherwise
 	GetSettingsForMonitoredEntities(monitoredEntities []MonitoredEntity) (GetSettingsResponse, error)
+
+	// CreateSettingsObject returns the object id of the created settings object if successful, or an api error otherwise
+	CreateSettingsObject(object SettingsObject) (string, error)
+
+	// GetSettingsObject returns the settings object with the given object id, or nil if it doesn't exist
+	GetSettingsObject(objectId string) (*SettingsObject, error)
+
+	// UpdateSettingsObject replaces the schema version and value of the settings object with the given object id
+	UpdateSettingsObject(objectId string, object SettingsObject) error
+
+	// DeleteSettingsObject deletes the settings object with the given object id, deleting a missing object is no error
+	DeleteSettingsObject(objectId string) error
 }
 
 // Known OS values.
@@ -212,3 +225,10 @@ func DisableHostsRequests(disabledHostsRequests bool) Option {
 		c.disableHostsRequests = disabledHostsRequests
 	}
 }
+
+// Timeout creates an Option that limits the time of every request, including reading the response body.
+func Timeout(timeout time.Duration) Option {
+	return func(c *dynatraceClient) {
+		c.httpClient.Timeout = timeout
+	}
+}
@@ -3,6 +3,7 @@ package dtclient
 import (
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -83,3 +84,10 @@ func TestCerts(t *testing.T) {
 	certs(&dtc)
 	assert.Equal(t, [][]uint8{}, transport.TLSClientConfig.RootCAs.Subjects())
 }
+
+func TestTimeout(t *testing.T) {
+	dtc := dynatraceClient{httpClient: &http.Client{}}
+
+	Timeout(5 * time.Second)(&dtc)
+	assert.Equal(t, 5*time.Second, dtc.httpClient.Timeout)
+}
@@ -2,8 +2,38 @@ package dtclient
 
 import (
 	"github.com/Dynatrace/dynatrace-operator/src/logger"
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
 )
 
 var (
 	log = logger.NewDTLogger().WithName("dtclient")
+
+	requestsMetric = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "dynatrace",
+		Subsystem: "dtclient",
+		Name:      "requests_total",
+		Help:      "Number of requests to the Dynatrace API by endpoint and status code",
+	}, []string{"endpoint", "status_code"})
+
+	requestDurationMetric = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "dynatrace",
+		Subsystem: "dtclient",
+		Name:      "request_duration_seconds",
+		Help:      "Duration of requests to the Dynatrace API by endpoint",
+		Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 300},
+	}, []string{"endpoint"})
+
+	downloadedBytesMetric = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "dynatrace",
+		Subsystem: "dtclient",
+		Name:      "downloaded_bytes_total",
+		Help:      "Number of bytes of OneAgent packages downloaded from the Dynatrace API by endpoint",
+	}, []string{"endpoint"})
 )
+
+func init() {
+	metrics.Registry.MustRegister(requestsMetric)
+	metrics.Registry.MustRegister(requestDurationMetric)
+	metrics.Registry.MustRegister(downloadedBytesMetric)
+}
@@ -1,13 +1,16 @@
 package dtclient
 
 import (
-	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"hash"
 	"io"
 	"io/ioutil"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/pkg/errors"
@@ -36,6 +39,8 @@ type dynatraceClient struct {
 	now time.Time
 }
 
+const maxDownloadResumeAttempts = 5
+
 type tokenType int
 
 const (
@@ -47,6 +52,14 @@ const (
 // makeRequest does an HTTP request by formatting the URL from the given arguments and returns the response.
 // The response body must be closed by the caller when no longer used.
 func (dtc *dynatraceClient) makeRequest(url string, tokenType tokenType) (*http.Response, error) {
+	req, err := dtc.newRequest(url, tokenType)
+	if err != nil {
+		return nil, err
+	}
+	return dtc.httpClient.Do(req)
+}
+
+func (dtc *dynatraceClient) newRequest(url string, tokenType tokenType) (*http.Request, error) {
 	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("error initializing http request: %s", err.Error())
@@ -66,14 +79,14 @@ func (dtc *dynatraceClient) makeRequest(url string, tokenType tokenType) (*http.
 		}
 		authHeader = fmt.Sprintf("Api-Token %s", dtc.paasToken)
 	case installerUrlToken:
-		return dtc.httpClient.Do(req)
+		return req, nil
 	default:
 		return nil, errors.New("unable to determine token to set in headers")
 	}
 
 	req.Header.Add("Authorization", authHeader)
 
-	return dtc.httpClient.Do(req)
+	return req, nil
 }
 
 func (dtc *dynatraceClient) getServerResponseData(response *http.Response) ([]byte, error) {
@@ -84,7 +97,7 @@ func (dtc *dynatraceClient) getServerResponseData(response *http.Response) ([]by
 
 	if response.StatusCode != http.StatusOK &&
 		response.StatusCode != http.StatusCreated {
-		return responseData, dtc.handleErrorResponseFromAPI(responseData, response.StatusCode)
+		return responseData, dtc.handleErrorResponseFromAPI(responseData, response.StatusCode, response.Header)
 	}
 
 	return responseData, nil
@@ -105,6 +118,12 @@ func (dtc *dynatraceClient) makeRequestAndUnmarshal(url string, token tokenType,
 	return json.Unmarshal(responseData, &response)
 }
 
+// makeRequestForBinary downloads the given url to the writer and returns the hex encoded SHA-256 checksum of the download.
+// If the connection drops while the body is transferred, the download is resumed with a range request, which is only
+// answered with the remaining bytes if the artifact didn't change in between. Otherwise the download starts over,
+// as long as the writer can be rewound.
+// If the server announces a SHA-256 digest, the download is verified against it.
+// The installer endpoints of the Dynatrace API don't announce one, the installer verifies such downloads against a pinned checksum.
 func (dtc *dynatraceClient) makeRequestForBinary(url string, token tokenType, writer io.Writer) (string, error) {
 	resp, err := dtc.makeRequest(url, token)
 	if err != nil {
@@ -113,28 +132,180 @@ func (dtc *dynatraceClient) makeRequestForBinary(url string, token tokenType, wr
 	defer func() { _ = resp.Body.Close() }()
 
 	if resp.StatusCode != http.StatusOK {
-		var errorResponse serverErrorResponse
-		err = json.NewDecoder(resp.Body).Decode(&errorResponse)
-		if err != nil {
-			return "", err
+		return "", dtc.getBinaryErrorResponse(resp)
+	}
+
+	expectedChecksum := getSha256Digest(resp.Header)
+	download := &binaryDownload{
+		target:    writer,
+		hash:      sha256.New(),
+		validator: getRangeValidator(resp.Header),
+	}
+
+	_, err = io.Copy(download, resp.Body)
+	for resumeAttempt := 1; err != nil && resumeAttempt <= maxDownloadResumeAttempts; resumeAttempt++ {
+		if !isResumable(resp, download) {
+			break
 		}
-		return "", fmt.Errorf("dynatrace server error %d: %s", errorResponse.ErrorMessage.Code, errorResponse.ErrorMessage.Message)
+		log.Info("download interrupted, resuming", "url", url, "offset", download.written, "attempt", resumeAttempt, "error", err.Error())
+		err = dtc.resumeDownload(url, token, download)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	checksum := hex.EncodeToString(download.hash.Sum(nil))
+	if expectedChecksum == "" {
+		log.Info("server did not announce a checksum, download is not verified", "url", url, "sha256", checksum)
+	} else if expectedChecksum != checksum {
+		return "", fmt.Errorf("checksum mismatch for download, expected sha256 %s but got %s", expectedChecksum, checksum)
+	}
+	return checksum, nil
+}
+
+// resumeDownload requests the remaining bytes of a download and appends them to the given writer.
+// The range is conditional on the validator of the first response, if the artifact changed in between,
+// e.g. a new latest version was released, the server sends the whole new artifact and the download starts over.
+func (dtc *dynatraceClient) resumeDownload(url string, token tokenType, download *binaryDownload) error {
+	req, err := dtc.newRequest(url, token)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-", download.written))
+	req.Header.Set("If-Range", download.validator)
+
+	resp, err := dtc.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode == http.StatusOK {
+		log.Info("artifact changed since the download started, starting over", "url", url)
+		if err := download.restart(); err != nil {
+			return err
+		}
+		download.validator = getRangeValidator(resp.Header)
+		_, err = io.Copy(download, resp.Body)
+		return err
+	} else if resp.StatusCode != http.StatusPartialContent {
+		return dtc.getBinaryErrorResponse(resp)
+	}
+
+	expectedRange := fmt.Sprintf("bytes %d-", download.written)
+	if contentRange := resp.Header.Get("Content-Range"); !strings.HasPrefix(contentRange, expectedRange) {
+		return fmt.Errorf("server responded with unexpected content range %q, expected %q", contentRange, expectedRange)
+	}
+
+	_, err = io.Copy(download, resp.Body)
+	return err
+}
+
+func (dtc *dynatraceClient) getBinaryErrorResponse(resp *http.Response) error {
+	responseData, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("error reading response: %w", err)
 	}
+	return dtc.handleErrorResponseFromAPI(responseData, resp.StatusCode, resp.Header)
+}
+
+// isResumable reports whether the server accepts range requests, the artifact can be identified by a validator
+// and the download failed on the connection, not on the writer.
+func isResumable(resp *http.Response, download *binaryDownload) bool {
+	return download.writeErr == nil && download.validator != "" && resp.Header.Get("Accept-Ranges") == "bytes"
+}
+
+// getRangeValidator returns the value for the If-Range header, which is the strong ETag or else the Last-Modified date of the artifact.
+// Weak ETags can't be used for range requests, an empty string is returned if there is no usable validator.
+func getRangeValidator(header http.Header) string {
+	if etag := header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+		return etag
+	}
+	return header.Get("Last-Modified")
+}
 
-	hash := md5.New()
-	_, err = io.Copy(writer, io.TeeReader(resp.Body, hash))
-	return hex.EncodeToString(hash.Sum(nil)), err
+// rewindableWriter is implemented by files, which can discard a partial download to start over.
+type rewindableWriter interface {
+	io.Writer
+	io.Seeker
+	Truncate(size int64) error
 }
 
-func (dtc *dynatraceClient) handleErrorResponseFromAPI(response []byte, statusCode int) error {
+// binaryDownload keeps track of how many bytes of a download have been written, their checksum and whether writing failed.
+type binaryDownload struct {
+	target    io.Writer
+	hash      hash.Hash
+	validator string
+	written   int64
+	writeErr  error
+}
+
+func (download *binaryDownload) Write(p []byte) (int, error) {
+	n, err := io.MultiWriter(download.target, download.hash).Write(p)
+	download.written += int64(n)
+	if err != nil {
+		download.writeErr = err
+	}
+	return n, err
+}
+
+// restart discards what has been written so far, or returns an error if the target can't be rewound.
+func (download *binaryDownload) restart() error {
+	target, ok := download.target.(rewindableWriter)
+	if !ok {
+		return errors.New("artifact changed since the download started and the partial download can't be discarded")
+	}
+	if err := target.Truncate(0); err != nil {
+		return fmt.Errorf("failed to discard partial download: %w", err)
+	}
+	if _, err := target.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to discard partial download: %w", err)
+	}
+	download.hash.Reset()
+	download.written = 0
+	return nil
+}
+
+// getSha256Digest returns the hex encoded SHA-256 checksum announced via the Digest header (RFC 3230), or an empty string.
+func getSha256Digest(header http.Header) string {
+	for _, digest := range strings.Split(header.Get("Digest"), ",") {
+		parts := strings.SplitN(strings.TrimSpace(digest), "=", 2)
+		if len(parts) != 2 || !strings.EqualFold(parts[0], "sha-256") {
+			continue
+		}
+		checksum, err := base64.StdEncoding.DecodeString(parts[1])
+		if err != nil {
+			log.Info("ignoring malformed digest header", "digest", digest)
+			return ""
+		}
+		return hex.EncodeToString(checksum)
+	}
+	return ""
+}
+
+func (dtc *dynatraceClient) handleErrorResponseFromAPI(response []byte, statusCode int, header http.Header) error {
+	retryAfter := parseRetryAfter(header, dtc.currentTime())
+
 	se := serverErrorResponse{}
 	if err := json.Unmarshal(response, &se); err != nil {
+		if isRetryableStatusCode(statusCode) {
+			// Proxies and load balancers answer with plain text bodies, keep the status code so the request can be retried
+			return ServerError{Code: statusCode, Message: http.StatusText(statusCode), RetryAfter: retryAfter}
+		}
 		return fmt.Errorf("response error: %d, can't unmarshal json response: %w", statusCode, err)
 	}
 
+	se.ErrorMessage.RetryAfter = retryAfter
 	return se.ErrorMessage
 }
 
+func (dtc *dynatraceClient) currentTime() time.Time {
+	if dtc.now.IsZero() {
+		return time.Now().UTC()
+	}
+	return dtc.now
+}
+
 func (dtc *dynatraceClient) getHostInfoForIP(ip string) (*hostInfo, error) {
 	if len(dtc.hostCache) == 0 {
 		err := dtc.buildHostCache()
@@ -201,10 +372,7 @@ func (dtc *dynatraceClient) setHostCacheFromResponse(response []byte) error {
 		return errors.WithStack(err)
 	}
 
-	now := dtc.now
-	if now.IsZero() {
-		now = time.Now().UTC()
-	}
+	now := dtc.currentTime()
 
 	var inactive []string
 
@@ -249,6 +417,9 @@ type serverErrorResponse struct {
 type ServerError struct {
 	Code    int
 	Message string
+
+	// RetryAfter is the wait time requested by the server via the Retry-After or X-RateLimit-Reset headers, zero if none was given.
+	RetryAfter time.Duration `json:"-"`
 }
 
 // Error formats the server error code and message.
@@ -1,6 +1,9 @@
 package dtclient
 
-import "fmt"
+import (
+	"fmt"
+	"net/url"
+)
 
 func (dtc *dynatraceClient) getAgentUrl(os, installerType, flavor, arch, version string, technologies []string) string {
 	url := fmt.Sprintf("%s/v1/deployment/installer/agent/%s/%s/version/%s?flavor=%s&arch=%s&bitness=64",
@@ -47,6 +50,10 @@ func (dtc *dynatraceClient) getSettingsUrl(validate bool) string {
 	return fmt.Sprintf("%s/v2/settings/objects%s", dtc.url, validationQuery)
 }
 
+func (dtc *dynatraceClient) getSettingsObjectUrl(objectId string) string {
+	return fmt.Sprintf("%s/v2/settings/objects/%s", dtc.url, url.PathEscape(objectId))
+}
+
 func (dtc *dynatraceClient) getProcessModuleConfigUrl() string {
 	return fmt.Sprintf("%s/v1/deployment/installer/agent/processmoduleconfig", dtc.url)
 }
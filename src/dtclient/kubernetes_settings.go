@@ -1,11 +1,9 @@
 package dtclient
 
 import (
-	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
-	"io/ioutil"
 	"net/http"
 	"strings"
 
@@ -73,64 +71,27 @@ func (dtc *dynatraceClient) CreateOrUpdateKubernetesSetting(name, kubeSystemUUID
 		return "", errors.New("no kube-system namespace UUID given")
 	}
 
-	body := []postKubernetesSettingsBody{
-		{
-			SchemaId:      "builtin:cloud.kubernetes",
-			SchemaVersion: "1.0.27",
-			Value: postKubernetesSettings{
-				Enabled:                         true,
-				Label:                           name,
-				ClusterIdEnabled:                true,
-				ClusterId:                       kubeSystemUUID,
-				CloudApplicationPipelineEnabled: true,
-				OpenMetricsPipelineEnabled:      false,
-				EventProcessingActive:           false,
-				FilterEvents:                    false,
-				EventProcessingV2Active:         false,
-			},
+	body := postKubernetesSettingsBody{
+		SchemaId:      "builtin:cloud.kubernetes",
+		SchemaVersion: "1.0.27",
+		Value: postKubernetesSettings{
+			Enabled:                         true,
+			Label:                           name,
+			ClusterIdEnabled:                true,
+			ClusterId:                       kubeSystemUUID,
+			CloudApplicationPipelineEnabled: true,
+			OpenMetricsPipelineEnabled:      false,
+			EventProcessingActive:           false,
+			FilterEvents:                    false,
+			EventProcessingV2Active:         false,
 		},
 	}
 
 	if scope != "" {
-		body[0].Scope = scope
+		body.Scope = scope
 	}
 
-	bodyData, err := json.Marshal(body)
-	if err != nil {
-		return "", err
-	}
-
-	req, err := createBaseRequest(dtc.getSettingsUrl(false), http.MethodPost, dtc.apiToken, bytes.NewReader(bodyData))
-	if err != nil {
-		return "", err
-	}
-
-	res, err := dtc.httpClient.Do(req)
-	if err != nil {
-		return "", fmt.Errorf("error making post request to dynatrace api: %s", err.Error())
-	}
-
-	resData, err := ioutil.ReadAll(res.Body)
-	if err != nil {
-		return "", fmt.Errorf("error reading response: %w", err)
-	}
-
-	if res.StatusCode != http.StatusOK &&
-		res.StatusCode != http.StatusCreated {
-		return "", handleErrorArrayResponseFromAPI(resData, res.StatusCode)
-	}
-
-	var resDataJson []postSettingsResponse
-	err = json.Unmarshal(resData, &resDataJson)
-	if err != nil {
-		return "", err
-	}
-
-	if len(resDataJson) != 1 {
-		return "", fmt.Errorf("response is not containing exactly one entry %s", resData)
-	}
-
-	return resDataJson[0].ObjectId, nil
+	return dtc.postSettingsObject(body)
 }
 
 func (dtc *dynatraceClient) GetMonitoredEntitiesForKubeSystemUUID(kubeSystemUUID string) ([]MonitoredEntity, error) {
@@ -200,6 +161,22 @@ func (dtc *dynatraceClient) GetSettingsForMonitoredEntities(monitoredEntities []
 	return resDataJson, nil
 }
 
+// NewestMonitoredEntity returns the ID of the most recently seen entity, or an empty string if the slice of entities is empty
+func NewestMonitoredEntity(entities []MonitoredEntity) string {
+	if len(entities) == 0 {
+		return ""
+	}
+
+	var newestMe MonitoredEntity
+	for _, entity := range entities {
+		if entity.LastSeenTms > newestMe.LastSeenTms {
+			newestMe = entity
+		}
+	}
+
+	return newestMe.EntityId
+}
+
 func (dtc *dynatraceClient) unmarshalToJson(res *http.Response, resDataJson interface{}) error {
 	resData, err := dtc.getServerResponseData(res)
 
@@ -223,7 +200,7 @@ func createBaseRequest(url, method, apiToken string, body io.Reader) (*http.Requ
 	req.Header.Add("Accept", "application/json")
 	req.Header.Add("Authorization", fmt.Sprintf("Api-Token %s", apiToken))
 
-	if method == http.MethodPost {
+	if method == http.MethodPost || method == http.MethodPut {
 		req.Header.Add("Content-Type", "application/json")
 	}
 
@@ -244,7 +221,7 @@ func handleErrorArrayResponseFromAPI(response []byte, statusCode int) error {
 		}
 
 		var sb strings.Builder
-		sb.WriteString("[Settings Creation]: could not create the Kubernetes setting for the following reason:\n")
+		sb.WriteString("[Settings Creation]: could not create the settings object for the following reason:\n")
 
 		for _, errorResponse := range se {
 			sb.WriteString(fmt.Sprintf("[%s; Code: %d\n", errorResponse.ErrorMessage.Message, errorResponse.ErrorMessage.Code))
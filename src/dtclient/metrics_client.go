@@ -0,0 +1,236 @@
+package dtclient
+
+import (
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	statusCodeSuccess = "2xx"
+	statusCodeError   = "error"
+)
+
+// metricsClient decorates a Client and records the number, status codes and duration of the requests per endpoint,
+// as well as the bytes downloaded by the agent downloads.
+type metricsClient struct {
+	client Client
+
+	// Set for testing purposes, leave the default zero value to use time.Now.
+	now func() time.Time
+}
+
+// NewMetricsClient wraps the given client so that its requests are recorded on the controller-runtime metrics registry.
+// To record every attempt of a retried request, the metrics client has to be wrapped by the retry client, not the other way round.
+func NewMetricsClient(client Client) Client {
+	return &metricsClient{
+		client: client,
+		now:    time.Now,
+	}
+}
+
+func (mc *metricsClient) GetLatestAgentVersion(os, installerType string) (string, error) {
+	var version string
+	err := mc.observe("GetLatestAgentVersion", func() error {
+		var err error
+		version, err = mc.client.GetLatestAgentVersion(os, installerType)
+		return err
+	})
+	return version, err
+}
+
+func (mc *metricsClient) GetLatestAgent(os, installerType, flavor, arch string, technologies []string, writer io.Writer) error {
+	return mc.observeDownload("GetLatestAgent", writer, func(writer io.Writer) error {
+		return mc.client.GetLatestAgent(os, installerType, flavor, arch, technologies, writer)
+	})
+}
+
+func (mc *metricsClient) GetAgent(os, installerType, flavor, arch, version string, technologies []string, writer io.Writer) error {
+	return mc.observeDownload("GetAgent", writer, func(writer io.Writer) error {
+		return mc.client.GetAgent(os, installerType, flavor, arch, version, technologies, writer)
+	})
+}
+
+func (mc *metricsClient) GetAgentViaInstallerUrl(url string, writer io.Writer) error {
+	return mc.observeDownload("GetAgentViaInstallerUrl", writer, func(writer io.Writer) error {
+		return mc.client.GetAgentViaInstallerUrl(url, writer)
+	})
+}
+
+func (mc *metricsClient) GetAgentVersions(os, installerType, flavor, arch string) ([]string, error) {
+	var versions []string
+	err := mc.observe("GetAgentVersions", func() error {
+		var err error
+		versions, err = mc.client.GetAgentVersions(os, installerType, flavor, arch)
+		return err
+	})
+	return versions, err
+}
+
+func (mc *metricsClient) GetConnectionInfo() (ConnectionInfo, error) {
+	var connectionInfo ConnectionInfo
+	err := mc.observe("GetConnectionInfo", func() error {
+		var err error
+		connectionInfo, err = mc.client.GetConnectionInfo()
+		return err
+	})
+	return connectionInfo, err
+}
+
+func (mc *metricsClient) GetProcessModuleConfig(prevRevision uint) (*ProcessModuleConfig, error) {
+	var processModuleConfig *ProcessModuleConfig
+	err := mc.observe("GetProcessModuleConfig", func() error {
+		var err error
+		processModuleConfig, err = mc.client.GetProcessModuleConfig(prevRevision)
+		return err
+	})
+	return processModuleConfig, err
+}
+
+// GetCommunicationHostForClient only parses the API URL, there is no request to record.
+func (mc *metricsClient) GetCommunicationHostForClient() (CommunicationHost, error) {
+	return mc.client.GetCommunicationHostForClient()
+}
+
+func (mc *metricsClient) SendEvent(eventData *EventData) error {
+	return mc.observe("SendEvent", func() error {
+		return mc.client.SendEvent(eventData)
+	})
+}
+
+func (mc *metricsClient) GetEntityIDForIP(ip string) (string, error) {
+	var entityID string
+	err := mc.observe("GetEntityIDForIP", func() error {
+		var err error
+		entityID, err = mc.client.GetEntityIDForIP(ip)
+		return err
+	})
+	return entityID, err
+}
+
+func (mc *metricsClient) GetTokenScopes(token string) (TokenScopes, error) {
+	var scopes TokenScopes
+	err := mc.observe("GetTokenScopes", func() error {
+		var err error
+		scopes, err = mc.client.GetTokenScopes(token)
+		return err
+	})
+	return scopes, err
+}
+
+func (mc *metricsClient) GetAgentTenantInfo() (*AgentTenantInfo, error) {
+	var tenantInfo *AgentTenantInfo
+	err := mc.observe("GetAgentTenantInfo", func() error {
+		var err error
+		tenantInfo, err = mc.client.GetAgentTenantInfo()
+		return err
+	})
+	return tenantInfo, err
+}
+
+func (mc *metricsClient) GetActiveGateTenantInfo() (*ActiveGateTenantInfo, error) {
+	var tenantInfo *ActiveGateTenantInfo
+	err := mc.observe("GetActiveGateTenantInfo", func() error {
+		var err error
+		tenantInfo, err = mc.client.GetActiveGateTenantInfo()
+		return err
+	})
+	return tenantInfo, err
+}
+
+func (mc *metricsClient) CreateOrUpdateKubernetesSetting(name, kubeSystemUUID, scope string) (string, error) {
+	var objectID string
+	err := mc.observe("CreateOrUpdateKubernetesSetting", func() error {
+		var err error
+		objectID, err = mc.client.CreateOrUpdateKubernetesSetting(name, kubeSystemUUID, scope)
+		return err
+	})
+	return objectID, err
+}
+
+func (mc *metricsClient) GetMonitoredEntitiesForKubeSystemUUID(kubeSystemUUID string) ([]MonitoredEntity, error) {
+	var monitoredEntities []MonitoredEntity
+	err := mc.observe("GetMonitoredEntitiesForKubeSystemUUID", func() error {
+		var err error
+		monitoredEntities, err = mc.client.GetMonitoredEntitiesForKubeSystemUUID(kubeSystemUUID)
+		return err
+	})
+	return monitoredEntities, err
+}
+
+func (mc *metricsClient) GetSettingsForMonitoredEntities(monitoredEntities []MonitoredEntity) (GetSettingsResponse, error) {
+	var settings GetSettingsResponse
+	err := mc.observe("GetSettingsForMonitoredEntities", func() error {
+		var err error
+		settings, err = mc.client.GetSettingsForMonitoredEntities(monitoredEntities)
+		return err
+	})
+	return settings, err
+}
+
+func (mc *metricsClient) CreateSettingsObject(object SettingsObject) (string, error) {
+	var objectID string
+	err := mc.observe("CreateSettingsObject", func() error {
+		var err error
+		objectID, err = mc.client.CreateSettingsObject(object)
+		return err
+	})
+	return objectID, err
+}
+
+func (mc *metricsClient) GetSettingsObject(objectId string) (*SettingsObject, error) {
+	var object *SettingsObject
+	err := mc.observe("GetSettingsObject", func() error {
+		var err error
+		object, err = mc.client.GetSettingsObject(objectId)
+		return err
+	})
+	return object, err
+}
+
+func (mc *metricsClient) UpdateSettingsObject(objectId string, object SettingsObject) error {
+	return mc.observe("UpdateSettingsObject", func() error {
+		return mc.client.UpdateSettingsObject(objectId, object)
+	})
+}
+
+func (mc *metricsClient) DeleteSettingsObject(objectId string) error {
+	return mc.observe("DeleteSettingsObject", func() error {
+		return mc.client.DeleteSettingsObject(objectId)
+	})
+}
+
+// observeDownload records the bytes written to the writer, even if the download fails halfway.
+func (mc *metricsClient) observeDownload(endpoint string, writer io.Writer, download func(io.Writer) error) error {
+	counter := &countingWriter{writer: writer}
+	err := mc.observe(endpoint, func() error {
+		return download(counter)
+	})
+	downloadedBytesMetric.WithLabelValues(endpoint).Add(float64(counter.written))
+	return err
+}
+
+func (mc *metricsClient) observe(endpoint string, request func() error) error {
+	start := mc.now()
+	err := request()
+	requestDurationMetric.WithLabelValues(endpoint).Observe(mc.now().Sub(start).Seconds())
+	requestsMetric.WithLabelValues(endpoint, statusCodeLabel(err)).Inc()
+	return err
+}
+
+// statusCodeLabel returns the status code of a failed response, e.g. 429, or a placeholder if the request failed
+// before the server could answer. The client does not keep the exact code of successful responses,
+// so these are recorded as 2xx.
+func statusCodeLabel(err error) string {
+	if err == nil {
+		return statusCodeSuccess
+	}
+
+	var serverError ServerError
+	if errors.As(err, &serverError) && serverError.Code != 0 {
+		return strconv.Itoa(serverError.Code)
+	}
+	return statusCodeError
+}
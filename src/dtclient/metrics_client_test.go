@@ -0,0 +1,100 @@
+package dtclient
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestMetricsClient(client Client) Client {
+	mc := NewMetricsClient(client).(*metricsClient)
+	now := time.Unix(0, 0)
+	mc.now = func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	}
+	return mc
+}
+
+func getHistogram(t *testing.T, endpoint string) *dto.Histogram {
+	var metric dto.Metric
+	require.NoError(t, requestDurationMetric.WithLabelValues(endpoint).(prometheus.Histogram).Write(&metric))
+	return metric.GetHistogram()
+}
+
+func TestMetricsClient(t *testing.T) {
+	t.Run(`counts requests by endpoint and status code`, func(t *testing.T) {
+		mockClient := &MockDynatraceClient{}
+		mockClient.On("GetConnectionInfo").Return(ConnectionInfo{TenantUUID: "abc"}, nil).Once()
+		mockClient.On("GetConnectionInfo").Return(ConnectionInfo{}, ServerError{Code: http.StatusServiceUnavailable}).Once()
+		mockClient.On("GetConnectionInfo").Return(ConnectionInfo{}, ServerError{Code: http.StatusTooManyRequests}).Once()
+		mockClient.On("GetConnectionInfo").Return(ConnectionInfo{}, ServerError{Code: http.StatusUnauthorized}).Once()
+		mockClient.On("GetConnectionInfo").Return(ConnectionInfo{}, fmt.Errorf("connection refused")).Once()
+
+		successes := testutil.ToFloat64(requestsMetric.WithLabelValues("GetConnectionInfo", statusCodeSuccess))
+		unavailable := testutil.ToFloat64(requestsMetric.WithLabelValues("GetConnectionInfo", "503"))
+		tooManyRequests := testutil.ToFloat64(requestsMetric.WithLabelValues("GetConnectionInfo", "429"))
+		unauthorized := testutil.ToFloat64(requestsMetric.WithLabelValues("GetConnectionInfo", "401"))
+		failures := testutil.ToFloat64(requestsMetric.WithLabelValues("GetConnectionInfo", statusCodeError))
+
+		mc := newTestMetricsClient(mockClient)
+		connectionInfo, err := mc.GetConnectionInfo()
+		require.NoError(t, err)
+		assert.Equal(t, "abc", connectionInfo.TenantUUID)
+		for i := 0; i < 4; i++ {
+			_, err = mc.GetConnectionInfo()
+			assert.Error(t, err)
+		}
+
+		assert.Equal(t, successes+1, testutil.ToFloat64(requestsMetric.WithLabelValues("GetConnectionInfo", statusCodeSuccess)))
+		assert.Equal(t, unavailable+1, testutil.ToFloat64(requestsMetric.WithLabelValues("GetConnectionInfo", "503")))
+		assert.Equal(t, tooManyRequests+1, testutil.ToFloat64(requestsMetric.WithLabelValues("GetConnectionInfo", "429")))
+		assert.Equal(t, unauthorized+1, testutil.ToFloat64(requestsMetric.WithLabelValues("GetConnectionInfo", "401")))
+		assert.Equal(t, failures+1, testutil.ToFloat64(requestsMetric.WithLabelValues("GetConnectionInfo", statusCodeError)))
+		mockClient.AssertExpectations(t)
+	})
+	t.Run(`records latency of requests`, func(t *testing.T) {
+		mockClient := &MockDynatraceClient{}
+		mockClient.On("GetTokenScopes", "token").Return(TokenScopes{TokenScopeDataExport}, nil)
+
+		before := getHistogram(t, "GetTokenScopes")
+		_, err := newTestMetricsClient(mockClient).GetTokenScopes("token")
+		require.NoError(t, err)
+		after := getHistogram(t, "GetTokenScopes")
+
+		assert.Equal(t, before.GetSampleCount()+1, after.GetSampleCount())
+		assert.Equal(t, before.GetSampleSum()+1, after.GetSampleSum())
+	})
+	t.Run(`counts downloaded bytes, also of failed downloads`, func(t *testing.T) {
+		mockClient := &MockDynatraceClient{}
+		mockClient.On("GetAgent", OsUnix, InstallerTypePaaS, FlavorDefault, ArchX86, "1.2.3", []string(nil), mock.Anything).
+			Run(func(args mock.Arguments) {
+				_, _ = args.Get(6).(io.Writer).Write([]byte("agent"))
+			}).Return(nil).Once()
+		mockClient.On("GetAgent", OsUnix, InstallerTypePaaS, FlavorDefault, ArchX86, "1.2.3", []string(nil), mock.Anything).
+			Run(func(args mock.Arguments) {
+				_, _ = args.Get(6).(io.Writer).Write([]byte("age"))
+			}).Return(fmt.Errorf("connection reset")).Once()
+
+		downloaded := testutil.ToFloat64(downloadedBytesMetric.WithLabelValues("GetAgent"))
+
+		var buffer bytes.Buffer
+		mc := newTestMetricsClient(mockClient)
+		require.NoError(t, mc.GetAgent(OsUnix, InstallerTypePaaS, FlavorDefault, ArchX86, "1.2.3", nil, &buffer))
+		assert.Error(t, mc.GetAgent(OsUnix, InstallerTypePaaS, FlavorDefault, ArchX86, "1.2.3", nil, &buffer))
+
+		assert.Equal(t, "agentage", buffer.String())
+		assert.Equal(t, downloaded+8, testutil.ToFloat64(downloadedBytesMetric.WithLabelValues("GetAgent")))
+		mockClient.AssertExpectations(t)
+	})
+}
@@ -90,3 +90,23 @@ func (o *MockDynatraceClient) GetSettingsForMonitoredEntities(monitoredEntities
 	args := o.Called(monitoredEntities)
 	return args.Get(0).(GetSettingsResponse), args.Error(1)
 }
+
+func (o *MockDynatraceClient) CreateSettingsObject(object SettingsObject) (string, error) {
+	args := o.Called(object)
+	return args.String(0), args.Error(1)
+}
+
+func (o *MockDynatraceClient) GetSettingsObject(objectId string) (*SettingsObject, error) {
+	args := o.Called(objectId)
+	return args.Get(0).(*SettingsObject), args.Error(1)
+}
+
+func (o *MockDynatraceClient) UpdateSettingsObject(objectId string, object SettingsObject) error {
+	args := o.Called(objectId, object)
+	return args.Error(0)
+}
+
+func (o *MockDynatraceClient) DeleteSettingsObject(objectId string) error {
+	args := o.Called(objectId)
+	return args.Error(0)
+}
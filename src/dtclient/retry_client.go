@@ -0,0 +1,466 @@
+package dtclient
+
+import (
+	"context"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultMaxRetries = 3
+	defaultBaseDelay  = 500 * time.Millisecond
+	defaultMaxDelay   = 30 * time.Second
+	// defaultMaxWait limits the total time a single call waits between its attempts
+	defaultMaxWait = time.Minute
+
+	headerRetryAfter     = "Retry-After"
+	headerRateLimitReset = "X-RateLimit-Reset"
+)
+
+// retryClient decorates a Client and retries requests that failed because of rate limiting,
+// an unavailable server or a transient network error.
+type retryClient struct {
+	client Client
+
+	maxRetries         int
+	baseDelay          time.Duration
+	maxDelay           time.Duration
+	maxWait            time.Duration
+	retryNonIdempotent bool
+
+	// ctx aborts the wait for the next attempt once it's done
+	ctx context.Context
+
+	// Set for testing purposes, leave the default zero values to use a timer and math/rand.
+	wait   func(context.Context, time.Duration) error
+	jitter func(time.Duration) time.Duration
+}
+
+// RetryOption can be passed to NewRetryClient and customizes the retry behavior.
+type RetryOption func(*retryClient)
+
+// NewRetryClient wraps the given client so that failed requests are retried using a bounded exponential backoff with jitter.
+// Wait times requested by the server via the Retry-After or X-RateLimit-Reset headers are honored, as long as they do not exceed the maximum delay.
+// A call stops retrying once its waits would exceed the total wait time, or the context of the client is done.
+//
+// Only idempotent requests are retried by default, use RetryNonIdempotent to also retry requests that change state on the server.
+func NewRetryClient(client Client, opts ...RetryOption) Client {
+	rc := &retryClient{
+		client:     client,
+		maxRetries: defaultMaxRetries,
+		baseDelay:  defaultBaseDelay,
+		maxDelay:   defaultMaxDelay,
+		maxWait:    defaultMaxWait,
+		ctx:        context.Background(),
+	}
+
+	for _, opt := range opts {
+		opt(rc)
+	}
+
+	if rc.wait == nil {
+		rc.wait = waitOrDone
+	}
+	if rc.jitter == nil {
+		rc.jitter = fullJitter
+	}
+
+	return rc
+}
+
+// MaxRetries sets how often a failed request is retried. The default is 3, 0 disables retries.
+func MaxRetries(maxRetries int) RetryOption {
+	return func(rc *retryClient) {
+		if maxRetries >= 0 {
+			rc.maxRetries = maxRetries
+		}
+	}
+}
+
+// Backoff sets the delay before the first retry and the upper bound of the delay between two attempts.
+// The delay doubles with every attempt. The defaults are 500ms and 30s.
+func Backoff(baseDelay, maxDelay time.Duration) RetryOption {
+	return func(rc *retryClient) {
+		if baseDelay > 0 {
+			rc.baseDelay = baseDelay
+		}
+		if maxDelay >= baseDelay {
+			rc.maxDelay = maxDelay
+		}
+	}
+}
+
+// MaxWait sets the total time a single call may wait between its attempts, so a call returns in time
+// even if the server keeps asking to come back later. The default is 1m.
+func MaxWait(maxWait time.Duration) RetryOption {
+	return func(rc *retryClient) {
+		if maxWait >= 0 {
+			rc.maxWait = maxWait
+		}
+	}
+}
+
+// RetryContext sets the context, which aborts waiting for the next attempt once it's done,
+// e.g. when the operator shuts down during a reconcile. The default is a context which is never done.
+func RetryContext(ctx context.Context) RetryOption {
+	return func(rc *retryClient) {
+		if ctx != nil {
+			rc.ctx = ctx
+		}
+	}
+}
+
+// RetryNonIdempotent creates a RetryOption that specifies whether requests which change state on the server,
+// like sending events or creating settings, are retried as well. The default is false.
+func RetryNonIdempotent(retry bool) RetryOption {
+	return func(rc *retryClient) {
+		rc.retryNonIdempotent = retry
+	}
+}
+
+func (rc *retryClient) GetLatestAgentVersion(os, installerType string) (string, error) {
+	var version string
+	err := rc.retry(true, func() error {
+		var err error
+		version, err = rc.client.GetLatestAgentVersion(os, installerType)
+		return err
+	})
+	return version, err
+}
+
+func (rc *retryClient) GetLatestAgent(os, installerType, flavor, arch string, technologies []string, writer io.Writer) error {
+	return rc.retryDownload(writer, func(writer io.Writer) error {
+		return rc.client.GetLatestAgent(os, installerType, flavor, arch, technologies, writer)
+	})
+}
+
+func (rc *retryClient) GetAgent(os, installerType, flavor, arch, version string, technologies []string, writer io.Writer) error {
+	return rc.retryDownload(writer, func(writer io.Writer) error {
+		return rc.client.GetAgent(os, installerType, flavor, arch, version, technologies, writer)
+	})
+}
+
+func (rc *retryClient) GetAgentViaInstallerUrl(url string, writer io.Writer) error {
+	return rc.retryDownload(writer, func(writer io.Writer) error {
+		return rc.client.GetAgentViaInstallerUrl(url, writer)
+	})
+}
+
+func (rc *retryClient) GetAgentVersions(os, installerType, flavor, arch string) ([]string, error) {
+	var versions []string
+	err := rc.retry(true, func() error {
+		var err error
+		versions, err = rc.client.GetAgentVersions(os, installerType, flavor, arch)
+		return err
+	})
+	return versions, err
+}
+
+func (rc *retryClient) GetConnectionInfo() (ConnectionInfo, error) {
+	var connectionInfo ConnectionInfo
+	err := rc.retry(true, func() error {
+		var err error
+		connectionInfo, err = rc.client.GetConnectionInfo()
+		return err
+	})
+	return connectionInfo, err
+}
+
+func (rc *retryClient) GetProcessModuleConfig(prevRevision uint) (*ProcessModuleConfig, error) {
+	var processModuleConfig *ProcessModuleConfig
+	err := rc.retry(true, func() error {
+		var err error
+		processModuleConfig, err = rc.client.GetProcessModuleConfig(prevRevision)
+		return err
+	})
+	return processModuleConfig, err
+}
+
+// GetCommunicationHostForClient only parses the API URL, there is nothing to retry.
+func (rc *retryClient) GetCommunicationHostForClient() (CommunicationHost, error) {
+	return rc.client.GetCommunicationHostForClient()
+}
+
+func (rc *retryClient) SendEvent(eventData *EventData) error {
+	return rc.retry(false, func() error {
+		return rc.client.SendEvent(eventData)
+	})
+}
+
+func (rc *retryClient) GetEntityIDForIP(ip string) (string, error) {
+	var entityID string
+	err := rc.retry(true, func() error {
+		var err error
+		entityID, err = rc.client.GetEntityIDForIP(ip)
+		return err
+	})
+	return entityID, err
+}
+
+// GetTokenScopes is a POST request, but it only looks the token up and is therefore safe to retry.
+func (rc *retryClient) GetTokenScopes(token string) (TokenScopes, error) {
+	var scopes TokenScopes
+	err := rc.retry(true, func() error {
+		var err error
+		scopes, err = rc.client.GetTokenScopes(token)
+		return err
+	})
+	return scopes, err
+}
+
+func (rc *retryClient) GetAgentTenantInfo() (*AgentTenantInfo, error) {
+	var tenantInfo *AgentTenantInfo
+	err := rc.retry(true, func() error {
+		var err error
+		tenantInfo, err = rc.client.GetAgentTenantInfo()
+		return err
+	})
+	return tenantInfo, err
+}
+
+func (rc *retryClient) GetActiveGateTenantInfo() (*ActiveGateTenantInfo, error) {
+	var tenantInfo *ActiveGateTenantInfo
+	err := rc.retry(true, func() error {
+		var err error
+		tenantInfo, err = rc.client.GetActiveGateTenantInfo()
+		return err
+	})
+	return tenantInfo, err
+}
+
+func (rc *retryClient) CreateOrUpdateKubernetesSetting(name, kubeSystemUUID, scope string) (string, error) {
+	var objectID string
+	err := rc.retry(false, func() error {
+		var err error
+		objectID, err = rc.client.CreateOrUpdateKubernetesSetting(name, kubeSystemUUID, scope)
+		return err
+	})
+	return objectID, err
+}
+
+func (rc *retryClient) GetMonitoredEntitiesForKubeSystemUUID(kubeSystemUUID string) ([]MonitoredEntity, error) {
+	var monitoredEntities []MonitoredEntity
+	err := rc.retry(true, func() error {
+		var err error
+		monitoredEntities, err = rc.client.GetMonitoredEntitiesForKubeSystemUUID(kubeSystemUUID)
+		return err
+	})
+	return monitoredEntities, err
+}
+
+func (rc *retryClient) GetSettingsForMonitoredEntities(monitoredEntities []MonitoredEntity) (GetSettingsResponse, error) {
+	var settings GetSettingsResponse
+	err := rc.retry(true, func() error {
+		var err error
+		settings, err = rc.client.GetSettingsForMonitoredEntities(monitoredEntities)
+		return err
+	})
+	return settings, err
+}
+
+// retryDownload retries a download as long as nothing has been written to the writer yet,
+// since a partially written download can not be rewound.
+func (rc *retryClient) retryDownload(writer io.Writer, download func(io.Writer) error) error {
+	counter := &countingWriter{writer: writer}
+	return rc.retry(true, func() error {
+		err := download(counter)
+		if err != nil && counter.written > 0 {
+			return permanentError{err}
+		}
+		return err
+	})
+}
+
+func (rc *retryClient) CreateSettingsObject(object SettingsObject) (string, error) {
+	var objectID string
+	err := rc.retry(false, func() error {
+		var err error
+		objectID, err = rc.client.CreateSettingsObject(object)
+		return err
+	})
+	return objectID, err
+}
+
+func (rc *retryClient) GetSettingsObject(objectId string) (*SettingsObject, error) {
+	var object *SettingsObject
+	err := rc.retry(true, func() error {
+		var err error
+		object, err = rc.client.GetSettingsObject(objectId)
+		return err
+	})
+	return object, err
+}
+
+func (rc *retryClient) UpdateSettingsObject(objectId string, object SettingsObject) error {
+	return rc.retry(true, func() error {
+		return rc.client.UpdateSettingsObject(objectId, object)
+	})
+}
+
+func (rc *retryClient) DeleteSettingsObject(objectId string) error {
+	return rc.retry(true, func() error {
+		return rc.client.DeleteSettingsObject(objectId)
+	})
+}
+
+func (rc *retryClient) retry(idempotent bool, request func() error) error {
+	if !idempotent && !rc.retryNonIdempotent {
+		return request()
+	}
+
+	var err error
+	var waited time.Duration
+	for attempt := 0; ; attempt++ {
+		err = request()
+		if err == nil {
+			return nil
+		}
+
+		var permanent permanentError
+		if errors.As(err, &permanent) {
+			return permanent.err
+		}
+
+		retryable, retryAfter := isRetryableError(err)
+		if !retryable || attempt >= rc.maxRetries {
+			return err
+		}
+
+		delay := rc.backoff(attempt)
+		if retryAfter > rc.maxDelay {
+			log.Info("server requested a wait time exceeding the maximum delay, giving up", "retryAfter", retryAfter, "maxDelay", rc.maxDelay)
+			return err
+		} else if retryAfter > 0 {
+			delay = retryAfter
+		}
+
+		if waited+delay > rc.maxWait {
+			log.Info("retries would exceed the maximum wait time, giving up", "waited", waited, "delay", delay, "maxWait", rc.maxWait)
+			return err
+		}
+
+		log.Info("request to Dynatrace API failed, retrying", "attempt", attempt+1, "delay", delay, "error", err.Error())
+		if ctxErr := rc.wait(rc.ctx, delay); ctxErr != nil {
+			return errors.WithMessagef(err, "retry aborted: %s", ctxErr.Error())
+		}
+		waited += delay
+	}
+}
+
+// waitOrDone waits for the given delay, or returns the error of the context if it's done before.
+func waitOrDone(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// backoff returns the exponential delay for the given attempt, capped at maxDelay and randomized by jitter.
+func (rc *retryClient) backoff(attempt int) time.Duration {
+	delay := rc.maxDelay
+	if attempt < 32 {
+		if exponential := rc.baseDelay << uint(attempt); exponential > 0 && exponential < rc.maxDelay {
+			delay = exponential
+		}
+	}
+	return rc.jitter(delay)
+}
+
+// fullJitter returns a random duration between half and the whole of the given delay,
+// so that concurrent reconciles do not hit the API in lockstep.
+func fullJitter(delay time.Duration) time.Duration {
+	half := delay / 2
+	if half <= 0 {
+		return delay
+	}
+	return half + time.Duration(rand.Int63n(int64(half)+1)) //nolint:gosec // no cryptographic randomness required for jitter
+}
+
+func isRetryableError(err error) (bool, time.Duration) {
+	var serverError ServerError
+	if errors.As(err, &serverError) {
+		return isRetryableStatusCode(serverError.Code), serverError.RetryAfter
+	}
+
+	var urlError *url.Error
+	if errors.As(err, &urlError) {
+		return isTransientNetworkError(urlError), 0
+	}
+
+	return false, 0
+}
+
+// isTransientNetworkError reports timeouts, refused or reset connections and connections closed mid-response.
+// Errors like a malformed URL or an unsupported protocol scheme will not go away by trying again.
+func isTransientNetworkError(urlError *url.Error) bool {
+	var opError *net.OpError
+	return urlError.Timeout() ||
+		errors.As(urlError.Err, &opError) ||
+		errors.Is(urlError.Err, io.EOF) ||
+		errors.Is(urlError.Err, io.ErrUnexpectedEOF)
+}
+
+func isRetryableStatusCode(statusCode int) bool {
+	switch statusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// parseRetryAfter reads the wait time requested by the server.
+// Retry-After is either given in seconds or as an HTTP date,
+// X-RateLimit-Reset is sent by Dynatrace as a unix timestamp in microseconds.
+func parseRetryAfter(header http.Header, now time.Time) time.Duration {
+	if value := header.Get(headerRetryAfter); value != "" {
+		if seconds, err := strconv.ParseInt(value, 10, 64); err == nil && seconds > 0 {
+			return time.Duration(seconds) * time.Second
+		}
+		if date, err := http.ParseTime(value); err == nil && date.After(now) {
+			return date.Sub(now)
+		}
+	}
+
+	if value := header.Get(headerRateLimitReset); value != "" {
+		if microseconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+			if reset := time.UnixMicro(microseconds); reset.After(now) {
+				return reset.Sub(now)
+			}
+		}
+	}
+
+	return 0
+}
+
+// permanentError marks an error that must not be retried.
+type permanentError struct {
+	err error
+}
+
+func (e permanentError) Error() string {
+	return e.err.Error()
+}
+
+type countingWriter struct {
+	writer  io.Writer
+	written int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	n, err := w.writer.Write(p)
+	w.written += int64(n)
+	return n, err
+}
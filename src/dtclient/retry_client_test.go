@@ -0,0 +1,268 @@
+package dtclient
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRetryClient(client Client, sleeps *[]time.Duration, opts ...RetryOption) Client {
+	rc := NewRetryClient(client, opts...).(*retryClient)
+	rc.wait = func(ctx context.Context, delay time.Duration) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		*sleeps = append(*sleeps, delay)
+		return nil
+	}
+	rc.jitter = func(delay time.Duration) time.Duration {
+		return delay
+	}
+	return rc
+}
+
+func TestRetryClient(t *testing.T) {
+	rateLimited := ServerError{Code: http.StatusTooManyRequests, Message: "Too many requests"}
+
+	t.Run(`retries idempotent requests with exponential backoff`, func(t *testing.T) {
+		var sleeps []time.Duration
+		mockClient := &MockDynatraceClient{}
+		mockClient.On("GetLatestAgentVersion", OsUnix, InstallerTypeDefault).Return("", rateLimited).Twice()
+		mockClient.On("GetLatestAgentVersion", OsUnix, InstallerTypeDefault).Return("1.2.3", nil).Once()
+
+		rc := newTestRetryClient(mockClient, &sleeps, Backoff(time.Second, time.Minute))
+		version, err := rc.GetLatestAgentVersion(OsUnix, InstallerTypeDefault)
+
+		require.NoError(t, err)
+		assert.Equal(t, "1.2.3", version)
+		assert.Equal(t, []time.Duration{time.Second, 2 * time.Second}, sleeps)
+		mockClient.AssertExpectations(t)
+	})
+	t.Run(`gives up after max retries`, func(t *testing.T) {
+		var sleeps []time.Duration
+		mockClient := &MockDynatraceClient{}
+		mockClient.On("GetConnectionInfo").Return(ConnectionInfo{}, rateLimited)
+
+		rc := newTestRetryClient(mockClient, &sleeps, MaxRetries(2), Backoff(time.Second, 3*time.Second))
+		_, err := rc.GetConnectionInfo()
+
+		assert.Equal(t, rateLimited, errors.Cause(err))
+		assert.Equal(t, []time.Duration{time.Second, 2 * time.Second}, sleeps)
+		mockClient.AssertNumberOfCalls(t, "GetConnectionInfo", 3)
+	})
+	t.Run(`caps delay at max delay`, func(t *testing.T) {
+		var sleeps []time.Duration
+		mockClient := &MockDynatraceClient{}
+		mockClient.On("GetAgentVersions", OsUnix, InstallerTypeDefault, FlavorDefault, ArchX86).Return([]string{}, rateLimited)
+
+		rc := newTestRetryClient(mockClient, &sleeps, MaxRetries(3), Backoff(time.Second, 3*time.Second))
+		_, err := rc.GetAgentVersions(OsUnix, InstallerTypeDefault, FlavorDefault, ArchX86)
+
+		assert.Error(t, err)
+		assert.Equal(t, []time.Duration{time.Second, 2 * time.Second, 3 * time.Second}, sleeps)
+	})
+	t.Run(`honors retry after of server`, func(t *testing.T) {
+		var sleeps []time.Duration
+		mockClient := &MockDynatraceClient{}
+		mockClient.On("GetAgentTenantInfo").Return((*AgentTenantInfo)(nil), ServerError{Code: http.StatusServiceUnavailable, RetryAfter: 5 * time.Second}).Once()
+		mockClient.On("GetAgentTenantInfo").Return(&AgentTenantInfo{}, nil).Once()
+
+		rc := newTestRetryClient(mockClient, &sleeps)
+		_, err := rc.GetAgentTenantInfo()
+
+		require.NoError(t, err)
+		assert.Equal(t, []time.Duration{5 * time.Second}, sleeps)
+	})
+	t.Run(`gives up if retry after exceeds max delay`, func(t *testing.T) {
+		var sleeps []time.Duration
+		mockClient := &MockDynatraceClient{}
+		mockClient.On("GetAgentTenantInfo").Return((*AgentTenantInfo)(nil), ServerError{Code: http.StatusTooManyRequests, RetryAfter: time.Hour})
+
+		rc := newTestRetryClient(mockClient, &sleeps)
+		_, err := rc.GetAgentTenantInfo()
+
+		assert.Error(t, err)
+		assert.Empty(t, sleeps)
+		mockClient.AssertNumberOfCalls(t, "GetAgentTenantInfo", 1)
+	})
+	t.Run(`does not retry client errors`, func(t *testing.T) {
+		var sleeps []time.Duration
+		mockClient := &MockDynatraceClient{}
+		mockClient.On("GetTokenScopes", "token").Return(TokenScopes(nil), errors.WithStack(ServerError{Code: http.StatusUnauthorized}))
+
+		rc := newTestRetryClient(mockClient, &sleeps)
+		_, err := rc.GetTokenScopes("token")
+
+		assert.Error(t, err)
+		assert.Empty(t, sleeps)
+		mockClient.AssertNumberOfCalls(t, "GetTokenScopes", 1)
+	})
+	t.Run(`retries transient network errors`, func(t *testing.T) {
+		var sleeps []time.Duration
+		mockClient := &MockDynatraceClient{}
+		connectionRefused := &url.Error{Op: "Get", URL: "https://test", Err: &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}}
+		mockClient.On("GetProcessModuleConfig", uint(0)).Return((*ProcessModuleConfig)(nil), errors.WithStack(connectionRefused)).Once()
+		mockClient.On("GetProcessModuleConfig", uint(0)).Return(&ProcessModuleConfig{}, nil).Once()
+
+		rc := newTestRetryClient(mockClient, &sleeps)
+		_, err := rc.GetProcessModuleConfig(0)
+
+		require.NoError(t, err)
+		assert.Len(t, sleeps, 1)
+	})
+	t.Run(`does not retry non idempotent requests by default`, func(t *testing.T) {
+		var sleeps []time.Duration
+		mockClient := &MockDynatraceClient{}
+		mockClient.On("SendEvent", mock.Anything).Return(rateLimited)
+
+		rc := newTestRetryClient(mockClient, &sleeps)
+		err := rc.SendEvent(&EventData{})
+
+		assert.Error(t, err)
+		mockClient.AssertNumberOfCalls(t, "SendEvent", 1)
+	})
+	t.Run(`retries non idempotent requests if enabled`, func(t *testing.T) {
+		var sleeps []time.Duration
+		mockClient := &MockDynatraceClient{}
+		mockClient.On("SendEvent", mock.Anything).Return(rateLimited).Once()
+		mockClient.On("SendEvent", mock.Anything).Return(nil).Once()
+
+		rc := newTestRetryClient(mockClient, &sleeps, RetryNonIdempotent(true))
+		err := rc.SendEvent(&EventData{})
+
+		require.NoError(t, err)
+		mockClient.AssertNumberOfCalls(t, "SendEvent", 2)
+	})
+	t.Run(`gives up once the maximum wait time is reached`, func(t *testing.T) {
+		var sleeps []time.Duration
+		mockClient := &MockDynatraceClient{}
+		mockClient.On("GetLatestAgentVersion", OsUnix, InstallerTypeDefault).Return("", ServerError{Code: http.StatusServiceUnavailable})
+
+		rc := newTestRetryClient(mockClient, &sleeps, Backoff(time.Second, time.Minute), MaxWait(4*time.Second))
+		_, err := rc.GetLatestAgentVersion(OsUnix, InstallerTypeDefault)
+
+		require.Error(t, err)
+		assert.Equal(t, []time.Duration{time.Second, 2 * time.Second}, sleeps)
+		mockClient.AssertNumberOfCalls(t, "GetLatestAgentVersion", 3)
+	})
+	t.Run(`stops waiting once the context is done`, func(t *testing.T) {
+		var sleeps []time.Duration
+		mockClient := &MockDynatraceClient{}
+		mockClient.On("GetLatestAgentVersion", OsUnix, InstallerTypeDefault).Return("", ServerError{Code: http.StatusServiceUnavailable})
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		rc := newTestRetryClient(mockClient, &sleeps, RetryContext(ctx))
+		_, err := rc.GetLatestAgentVersion(OsUnix, InstallerTypeDefault)
+
+		var serverError ServerError
+		assert.True(t, errors.As(err, &serverError))
+		assert.Contains(t, err.Error(), context.Canceled.Error())
+		assert.Empty(t, sleeps)
+		mockClient.AssertNumberOfCalls(t, "GetLatestAgentVersion", 1)
+	})
+	t.Run(`retries download if nothing was written`, func(t *testing.T) {
+		var sleeps []time.Duration
+		mockClient := &MockDynatraceClient{}
+		mockClient.On("GetAgent", OsUnix, InstallerTypePaaS, FlavorDefault, ArchX86, "1.2.3", []string(nil), mock.Anything).Return(rateLimited).Once()
+		mockClient.On("GetAgent", OsUnix, InstallerTypePaaS, FlavorDefault, ArchX86, "1.2.3", []string(nil), mock.Anything).
+			Run(func(args mock.Arguments) {
+				_, _ = args.Get(6).(io.Writer).Write([]byte("agent"))
+			}).
+			Return(nil).Once()
+
+		var buffer bytes.Buffer
+		rc := newTestRetryClient(mockClient, &sleeps)
+		err := rc.GetAgent(OsUnix, InstallerTypePaaS, FlavorDefault, ArchX86, "1.2.3", nil, &buffer)
+
+		require.NoError(t, err)
+		assert.Equal(t, "agent", buffer.String())
+	})
+	t.Run(`does not retry partially written download`, func(t *testing.T) {
+		var sleeps []time.Duration
+		mockClient := &MockDynatraceClient{}
+		mockClient.On("GetAgentViaInstallerUrl", "https://test", mock.Anything).
+			Run(func(args mock.Arguments) {
+				_, _ = args.Get(1).(io.Writer).Write([]byte("age"))
+			}).
+			Return(&url.Error{Op: "Get", URL: "https://test", Err: io.ErrUnexpectedEOF})
+
+		var buffer bytes.Buffer
+		rc := newTestRetryClient(mockClient, &sleeps)
+		err := rc.GetAgentViaInstallerUrl("https://test", &buffer)
+
+		var urlError *url.Error
+		assert.True(t, errors.As(err, &urlError))
+		assert.Empty(t, sleeps)
+		mockClient.AssertNumberOfCalls(t, "GetAgentViaInstallerUrl", 1)
+	})
+}
+
+func TestWaitOrDone(t *testing.T) {
+	assert.NoError(t, waitOrDone(context.Background(), time.Millisecond))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, waitOrDone(ctx, time.Hour), context.Canceled)
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Unix(1600000000, 0).UTC()
+
+	t.Run(`no headers`, func(t *testing.T) {
+		assert.Zero(t, parseRetryAfter(http.Header{}, now))
+	})
+	t.Run(`retry after in seconds`, func(t *testing.T) {
+		header := http.Header{}
+		header.Set(headerRetryAfter, "7")
+		assert.Equal(t, 7*time.Second, parseRetryAfter(header, now))
+	})
+	t.Run(`retry after as http date`, func(t *testing.T) {
+		header := http.Header{}
+		header.Set(headerRetryAfter, now.Add(10*time.Second).Format(http.TimeFormat))
+		assert.Equal(t, 10*time.Second, parseRetryAfter(header, now))
+	})
+	t.Run(`rate limit reset in microseconds`, func(t *testing.T) {
+		header := http.Header{}
+		header.Set(headerRateLimitReset, "1600000003000000")
+		assert.Equal(t, 3*time.Second, parseRetryAfter(header, now))
+	})
+	t.Run(`rate limit reset in the past`, func(t *testing.T) {
+		header := http.Header{}
+		header.Set(headerRateLimitReset, "1500000000000000")
+		assert.Zero(t, parseRetryAfter(header, now))
+	})
+	t.Run(`invalid values`, func(t *testing.T) {
+		header := http.Header{}
+		header.Set(headerRetryAfter, "soon")
+		header.Set(headerRateLimitReset, "later")
+		assert.Zero(t, parseRetryAfter(header, now))
+	})
+}
+
+func TestRateLimitedResponse(t *testing.T) {
+	dynatraceServer, dtc := createTestDynatraceClientWithFunc(t, func(writer http.ResponseWriter, request *http.Request) {
+		writer.Header().Set(headerRetryAfter, "2")
+		writer.WriteHeader(http.StatusTooManyRequests)
+		_, _ = writer.Write([]byte("rate limit exceeded"))
+	})
+	defer dynatraceServer.Close()
+
+	_, err := dtc.GetLatestAgentVersion(OsUnix, InstallerTypeDefault)
+
+	var serverError ServerError
+	require.True(t, errors.As(err, &serverError))
+	assert.Equal(t, http.StatusTooManyRequests, serverError.Code)
+	assert.Equal(t, 2*time.Second, serverError.RetryAfter)
+}
@@ -11,6 +11,8 @@ import (
 
 const (
 	MarkedForTerminationEvent = "MARKED_FOR_TERMINATION"
+	CustomInfoEvent           = "CUSTOM_INFO"
+	CustomConfigurationEvent  = "CUSTOM_CONFIGURATION"
 )
 
 // EventData struct which defines what event payload should contain
@@ -21,6 +23,9 @@ type EventData struct {
 	Description   string               `json:"description"`
 	AttachRules   EventDataAttachRules `json:"attachRules"`
 	Source        string               `json:"source"`
+	Title         string               `json:"title,omitempty"`
+
+	CustomProperties map[string]string `json:"customProperties,omitempty"`
 }
 
 type EventDataAttachRules struct {
@@ -33,6 +33,33 @@ func TestEventDataMarshal(t *testing.T) {
 	assert.JSONEq(t, string(jsonBuffer), string(testJSONInput))
 }
 
+func TestEventDataMarshalCustomProperties(t *testing.T) {
+	testJSONInput := []byte(`{
+		"eventType": "CUSTOM_INFO",
+		"start": 20,
+		"end": 20,
+		"description": "Kubernetes node is not ready",
+		"title": "Node not ready",
+		"attachRules": {
+			"entityIds": [ "HOST-CA78D78BBC6687D3" ]
+		},
+		"source": "Dynatrace Operator",
+		"customProperties": {
+			"k8s.node.name": "node1"
+		}
+	}`)
+
+	var testEventData EventData
+	err := json.Unmarshal(testJSONInput, &testEventData)
+	assert.NoError(t, err)
+	assert.Equal(t, "Node not ready", testEventData.Title)
+	assert.Equal(t, map[string]string{"k8s.node.name": "node1"}, testEventData.CustomProperties)
+
+	jsonBuffer, err := json.Marshal(testEventData)
+	assert.NoError(t, err)
+	assert.JSONEq(t, string(testJSONInput), string(jsonBuffer))
+}
+
 func TestSendEvent(t *testing.T) {
 	empty := EventData{}
 	eventTypeOnly := EventData{
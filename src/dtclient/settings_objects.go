@@ -0,0 +1,140 @@
+package dtclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+)
+
+// SettingsObject is a Settings 2.0 object, its value is the JSON object defined by the schema.
+type SettingsObject struct {
+	ObjectId      string          `json:"objectId,omitempty"`
+	SchemaId      string          `json:"schemaId"`
+	SchemaVersion string          `json:"schemaVersion,omitempty"`
+	Scope         string          `json:"scope,omitempty"`
+	Value         json.RawMessage `json:"value"`
+}
+
+type putSettingsObjectBody struct {
+	SchemaVersion string          `json:"schemaVersion,omitempty"`
+	Value         json.RawMessage `json:"value"`
+}
+
+func (dtc *dynatraceClient) CreateSettingsObject(object SettingsObject) (string, error) {
+	object.ObjectId = ""
+	return dtc.postSettingsObject(object)
+}
+
+func (dtc *dynatraceClient) GetSettingsObject(objectId string) (*SettingsObject, error) {
+	req, err := createBaseRequest(dtc.getSettingsObjectUrl(objectId), http.MethodGet, dtc.apiToken, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := dtc.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error making get request to dynatrace api: %w", err)
+	}
+	defer func() { _ = res.Body.Close() }()
+
+	if res.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+
+	resData, err := dtc.getServerResponseData(res)
+	if err != nil {
+		return nil, err
+	}
+
+	var object SettingsObject
+	if err := json.Unmarshal(resData, &object); err != nil {
+		return nil, fmt.Errorf("error parsing response body: %w", err)
+	}
+	return &object, nil
+}
+
+func (dtc *dynatraceClient) UpdateSettingsObject(objectId string, object SettingsObject) error {
+	bodyData, err := json.Marshal(putSettingsObjectBody{
+		SchemaVersion: object.SchemaVersion,
+		Value:         object.Value,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := createBaseRequest(dtc.getSettingsObjectUrl(objectId), http.MethodPut, dtc.apiToken, bytes.NewReader(bodyData))
+	if err != nil {
+		return err
+	}
+
+	res, err := dtc.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("error making put request to dynatrace api: %w", err)
+	}
+	defer func() { _ = res.Body.Close() }()
+
+	_, err = dtc.getServerResponseData(res)
+	return err
+}
+
+func (dtc *dynatraceClient) DeleteSettingsObject(objectId string) error {
+	req, err := createBaseRequest(dtc.getSettingsObjectUrl(objectId), http.MethodDelete, dtc.apiToken, nil)
+	if err != nil {
+		return err
+	}
+
+	res, err := dtc.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("error making delete request to dynatrace api: %w", err)
+	}
+	defer func() { _ = res.Body.Close() }()
+
+	if res.StatusCode == http.StatusNoContent || res.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	_, err = dtc.getServerResponseData(res)
+	return err
+}
+
+// postSettingsObject creates the object and returns its object id, the errors of the settings API are returned for every object of the request.
+func (dtc *dynatraceClient) postSettingsObject(object interface{}) (string, error) {
+	bodyData, err := json.Marshal([]interface{}{object})
+	if err != nil {
+		return "", err
+	}
+
+	req, err := createBaseRequest(dtc.getSettingsUrl(false), http.MethodPost, dtc.apiToken, bytes.NewReader(bodyData))
+	if err != nil {
+		return "", err
+	}
+
+	res, err := dtc.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("error making post request to dynatrace api: %s", err.Error())
+	}
+	defer func() { _ = res.Body.Close() }()
+
+	resData, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return "", fmt.Errorf("error reading response: %w", err)
+	}
+
+	if res.StatusCode != http.StatusOK &&
+		res.StatusCode != http.StatusCreated {
+		return "", handleErrorArrayResponseFromAPI(resData, res.StatusCode)
+	}
+
+	var resDataJson []postSettingsResponse
+	err = json.Unmarshal(resData, &resDataJson)
+	if err != nil {
+		return "", err
+	}
+
+	if len(resDataJson) != 1 {
+		return "", fmt.Errorf("response is not containing exactly one entry %s", resData)
+	}
+
+	return resDataJson[0].ObjectId, nil
+}
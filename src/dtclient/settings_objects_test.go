@@ -0,0 +1,93 @@
+package dtclient
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSchemaId = "builtin:anomaly-detection.kubernetes.cluster"
+
+func mockDynatraceServerSettingsObjectsHandler(t *testing.T, objects map[string]SettingsObject) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Api-Token "+apiToken {
+			writeError(w, http.StatusUnauthorized)
+			return
+		}
+
+		const objectsPath = "/v2/settings/objects"
+		if r.URL.Path == objectsPath && r.Method == http.MethodPost {
+			var created []SettingsObject
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&created))
+			require.Len(t, created, 1)
+			created[0].ObjectId = testObjectID
+			objects[testObjectID] = created[0]
+			_, _ = w.Write([]byte(`[{"code":200,"objectId":"` + testObjectID + `"}]`))
+			return
+		}
+
+		objectId := r.URL.Path[len(objectsPath)+1:]
+		object, ok := objects[objectId]
+		switch {
+		case !ok:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":{"code":404,"message":"Settings not found"}}`))
+		case r.Method == http.MethodGet:
+			response, _ := json.Marshal(object)
+			_, _ = w.Write(response)
+		case r.Method == http.MethodPut:
+			body, _ := ioutil.ReadAll(r.Body)
+			var update putSettingsObjectBody
+			require.NoError(t, json.Unmarshal(body, &update))
+			object.Value = update.Value
+			objects[objectId] = object
+			_, _ = w.Write([]byte(`{"code":200,"objectId":"` + objectId + `"}`))
+		case r.Method == http.MethodDelete:
+			delete(objects, objectId)
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}
+}
+
+func TestDynatraceClient_SettingsObjects(t *testing.T) {
+	objects := map[string]SettingsObject{}
+	dynatraceServer := httptest.NewServer(mockDynatraceServerSettingsObjectsHandler(t, objects))
+	defer dynatraceServer.Close()
+
+	dtc, err := NewClient(dynatraceServer.URL, apiToken, paasToken)
+	require.NoError(t, err)
+
+	objectId, err := dtc.CreateSettingsObject(SettingsObject{
+		SchemaId: testSchemaId,
+		Scope:    testScope,
+		Value:    json.RawMessage(`{"enabled":true}`),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, testObjectID, objectId)
+
+	object, err := dtc.GetSettingsObject(objectId)
+	require.NoError(t, err)
+	require.NotNil(t, object)
+	assert.Equal(t, testSchemaId, object.SchemaId)
+	assert.Equal(t, testScope, object.Scope)
+	assert.JSONEq(t, `{"enabled":true}`, string(object.Value))
+
+	require.NoError(t, dtc.UpdateSettingsObject(objectId, SettingsObject{Value: json.RawMessage(`{"enabled":false}`)}))
+	object, err = dtc.GetSettingsObject(objectId)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"enabled":false}`, string(object.Value))
+
+	require.NoError(t, dtc.DeleteSettingsObject(objectId))
+	object, err = dtc.GetSettingsObject(objectId)
+	require.NoError(t, err)
+	assert.Nil(t, object)
+
+	// deleting a missing object is no error, updating it is
+	assert.NoError(t, dtc.DeleteSettingsObject(objectId))
+	assert.Error(t, dtc.UpdateSettingsObject(objectId, SettingsObject{Value: json.RawMessage(`{}`)}))
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/Dynatrace/dynatrace-operator/src/dtclient"
 	"github.com/Dynatrace/dynatrace-operator/src/kubeobjects"
 	"github.com/Dynatrace/dynatrace-operator/src/mapper"
+	"github.com/Dynatrace/dynatrace-operator/src/secretprovider"
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
@@ -133,8 +134,8 @@ func (g *EndpointSecretGenerator) prepare(ctx context.Context, dk *dynatracev1be
 func (g *EndpointSecretGenerator) PrepareFields(ctx context.Context, dk *dynatracev1beta1.DynaKube) (map[string]string, error) {
 	fields := make(map[string]string)
 
-	var tokens corev1.Secret
-	if err := g.client.Get(ctx, client.ObjectKey{Name: dk.Tokens(), Namespace: g.namespace}, &tokens); err != nil {
+	tokens, err := secretprovider.NewReader(g.client, g.namespace, dk.Spec.SecretProvider).Get(ctx, dk.Tokens())
+	if err != nil {
 		return nil, errors.WithMessage(err, "failed to query tokens")
 	}
 
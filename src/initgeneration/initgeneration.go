@@ -10,10 +10,12 @@ import (
 	"github.com/Dynatrace/dynatrace-operator/src/kubeobjects"
 	"github.com/Dynatrace/dynatrace-operator/src/kubesystem"
 	"github.com/Dynatrace/dynatrace-operator/src/mapper"
+	"github.com/Dynatrace/dynatrace-operator/src/secretprovider"
 	"github.com/Dynatrace/dynatrace-operator/src/standalone"
 	"github.com/Dynatrace/dynatrace-operator/src/webhook"
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -45,7 +47,19 @@ func NewInitGenerator(client client.Client, apiReader client.Reader, ns string)
 func (g *InitGenerator) GenerateForNamespace(ctx context.Context, dk dynatracev1beta1.DynaKube, targetNs string) (bool, error) {
 	log.Info("reconciling namespace init secret for", "namespace", targetNs)
 	g.canWatchNodes = false
-	data, err := g.generate(ctx, &dk)
+	secretConfig, err := g.generate(ctx, &dk)
+	if err != nil {
+		return false, err
+	}
+
+	namespace := corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: targetNs}}
+	if len(dk.EnrichmentRules(dynatracev1beta1.EnrichmentRuleTypeNamespaceLabel)) > 0 {
+		if err := g.apiReader.Get(ctx, client.ObjectKey{Name: targetNs}, &namespace); err != nil {
+			return false, errors.WithMessage(err, "failed to query namespace")
+		}
+	}
+
+	data, err := g.createSecretDataForNamespace(&dk, secretConfig, namespace)
 	if err != nil {
 		return false, err
 	}
@@ -57,7 +71,7 @@ func (g *InitGenerator) GenerateForNamespace(ctx context.Context, dk dynatracev1
 func (g *InitGenerator) GenerateForDynakube(ctx context.Context, dk *dynatracev1beta1.DynaKube) (bool, error) {
 	log.Info("reconciling namespace init secret for", "dynakube", dk.Name)
 	g.canWatchNodes = true
-	data, err := g.generate(ctx, dk)
+	secretConfig, err := g.generate(ctx, dk)
 	if err != nil {
 		return false, err
 	}
@@ -68,6 +82,10 @@ func (g *InitGenerator) GenerateForDynakube(ctx context.Context, dk *dynatracev1
 		return false, err
 	}
 	for _, targetNs := range nsList {
+		data, err := g.createSecretDataForNamespace(dk, secretConfig, targetNs)
+		if err != nil {
+			return false, err
+		}
 		if upd, err := kubeobjects.CreateOrUpdateSecretIfNotExists(g.client, g.apiReader, webhook.SecretConfigName, targetNs.Name, data, corev1.SecretTypeOpaque, log); err != nil {
 			return false, err
 		} else if upd {
@@ -79,7 +97,7 @@ func (g *InitGenerator) GenerateForDynakube(ctx context.Context, dk *dynatracev1
 }
 
 // generate gets the necessary info the create the init secret data
-func (g *InitGenerator) generate(ctx context.Context, dk *dynatracev1beta1.DynaKube) (map[string][]byte, error) {
+func (g *InitGenerator) generate(ctx context.Context, dk *dynatracev1beta1.DynaKube) (*standalone.SecretConfig, error) {
 	kubeSystemUID, err := kubesystem.GetUID(g.apiReader)
 	if err != nil {
 		return nil, err
@@ -95,24 +113,126 @@ func (g *InitGenerator) generate(ctx context.Context, dk *dynatracev1beta1.DynaK
 		return nil, err
 	}
 
-	data, err := g.createSecretData(secretConfig)
+	if err := g.addNodeLabels(ctx, dk, secretConfig); err != nil {
+		return nil, err
+	}
+	return secretConfig, nil
+}
+
+// addNodeLabels resolves the node labels used by the enrichment rules, so the init container doesn't need to be allowed to get nodes.
+// Each distinct set of labels is only stored once, as nodes are mostly labeled per zone or pool.
+func (g *InitGenerator) addNodeLabels(ctx context.Context, dk *dynatracev1beta1.DynaKube, secretConfig *standalone.SecretConfig) error {
+	rules := dk.EnrichmentRules(dynatracev1beta1.EnrichmentRuleTypeNodeLabel)
+	if len(rules) == 0 {
+		return nil
+	}
+
+	var nodeList corev1.NodeList
+	if err := g.apiReader.List(ctx, &nodeList); err != nil {
+		return errors.WithMessage(err, "failed to list nodes")
+	}
+
+	secretConfig.NodeLabelSets = []map[string]string{}
+	secretConfig.NodeLabelSetOfNode = map[string]int{}
+	setIndices := map[string]int{}
+	for _, node := range nodeList.Items {
+		nodeLabels := filterLabels(node.Labels, rules)
+		// the keys of a JSON object are sorted, so equal sets have the same key
+		key, err := json.Marshal(nodeLabels)
+		if err != nil {
+			return err
+		}
+
+		index, ok := setIndices[string(key)]
+		if !ok {
+			index = len(secretConfig.NodeLabelSets)
+			setIndices[string(key)] = index
+			secretConfig.NodeLabelSets = append(secretConfig.NodeLabelSets, nodeLabels)
+		}
+		secretConfig.NodeLabelSetOfNode[node.Name] = index
+	}
+	return nil
+}
+
+// createSecretDataForNamespace creates the init secret data for the namespace, which differs by the labels of the namespace used for the enrichment.
+func (g *InitGenerator) createSecretDataForNamespace(dk *dynatracev1beta1.DynaKube, secretConfig *standalone.SecretConfig, namespace corev1.Namespace) (map[string][]byte, error) {
+	namespaceConfig := *secretConfig
+	namespaceConfig.NamespaceLabels = filterLabels(namespace.Labels, dk.EnrichmentRules(dynatracev1beta1.EnrichmentRuleTypeNamespaceLabel))
+
+	data, err := g.createSecretData(&namespaceConfig)
 	if err != nil {
 		return nil, err
 	}
+
+	if err := g.addTenantTargets(dk, &namespaceConfig, data); err != nil {
+		return nil, err
+	}
 	return data, nil
 }
 
+// filterLabels returns only the labels which are used by the rules, to keep the init secret small.
+func filterLabels(labels map[string]string, rules []dynatracev1beta1.EnrichmentRule) map[string]string {
+	if len(rules) == 0 {
+		return nil
+	}
+
+	filtered := map[string]string{}
+	for _, rule := range rules {
+		if value, ok := labels[rule.Source]; ok {
+			filtered[rule.Source] = value
+		}
+	}
+	return filtered
+}
+
+// SecretConfigFieldNameForTarget returns the field of the init secret which holds the config for the additional tenant.
+// The webhook mounts it instead of the config of the primary tenant into pods which select the tenant target.
+func SecretConfigFieldNameForTarget(tenantTarget string) string {
+	return standalone.SecretConfigFieldName + "-" + tenantTarget
+}
+
+// addTenantTargets adds the config of every connected additional tenant to the init secret data.
+// The host OneAgents report to the primary tenant, so the tenant UUIDs never match and the code modules are not grouped to the hosts.
+func (g *InitGenerator) addTenantTargets(dk *dynatracev1beta1.DynaKube, secretConfig *standalone.SecretConfig, data map[string][]byte) error {
+	for _, target := range dk.Spec.AdditionalTenants {
+		tenantUUID := dk.TenantTargetUUID(target.Name)
+		if tenantUUID == "" {
+			log.Info("skipping tenant target which is not connected yet", "dynakube", dk.Name, "tenantTarget", target.Name)
+			continue
+		}
+
+		tokens, err := secretprovider.NewReader(g.client, g.namespace, dk.Spec.SecretProvider).Get(context.TODO(), dk.TenantTargetTokens(target))
+		if err != nil {
+			return errors.WithMessagef(err, "failed to query tokens of tenant target %s", target.Name)
+		}
+
+		targetConfig := *secretConfig
+		targetConfig.ApiUrl = target.APIURL
+		targetConfig.ApiToken = getAPIToken(tokens)
+		targetConfig.PaasToken = getPaasToken(tokens)
+		targetConfig.TenantUUID = tenantUUID
+
+		jsonContent, err := json.Marshal(targetConfig)
+		if err != nil {
+			return err
+		}
+		data[SecretConfigFieldNameForTarget(target.Name)] = jsonContent
+	}
+	return nil
+}
+
 func (g *InitGenerator) prepareSecretConfigForDynaKube(dk *dynatracev1beta1.DynaKube, kubeSystemUID types.UID, hostMonitoringNodes map[string]string) (*standalone.SecretConfig, error) {
-	var tokens corev1.Secret
-	if err := g.client.Get(context.TODO(), client.ObjectKey{Name: dk.Tokens(), Namespace: g.namespace}, &tokens); err != nil {
+	secretReader := secretprovider.NewReader(g.client, g.namespace, dk.Spec.SecretProvider)
+	tokens, err := secretReader.Get(context.TODO(), dk.Tokens())
+	if err != nil {
 		return nil, errors.WithMessage(err, "failed to query tokens")
 	}
 
 	var proxy string
 	if dk.Spec.Proxy != nil {
 		if dk.Spec.Proxy.ValueFrom != "" {
-			var ps corev1.Secret
-			if err := g.client.Get(context.TODO(), client.ObjectKey{Name: dk.Spec.Proxy.ValueFrom, Namespace: g.namespace}, &ps); err != nil {
+			ps, err := secretReader.Get(context.TODO(), dk.Spec.Proxy.ValueFrom)
+			if err != nil {
 				return nil, fmt.Errorf("failed to query proxy: %w", err)
 			}
 			proxy = string(ps.Data[proxyKey])
@@ -139,7 +259,7 @@ func (g *InitGenerator) prepareSecretConfigForDynaKube(dk *dynatracev1beta1.Dyna
 		tlsCert = string(tlsSecret.Data[tlsCertKey])
 	}
 
-	return &standalone.SecretConfig{
+	secretConfig := &standalone.SecretConfig{
 		ApiUrl:          dk.Spec.APIURL,
 		ApiToken:        getAPIToken(tokens),
 		PaasToken:       getPaasToken(tokens),
@@ -153,17 +273,35 @@ func (g *InitGenerator) prepareSecretConfigForDynaKube(dk *dynatracev1beta1.Dyna
 		TlsCert:         tlsCert,
 		HostGroup:       dk.HostGroup(),
 		ClusterID:       string(kubeSystemUID),
-	}, nil
+		EnrichmentRules: dk.Spec.MetadataEnrichment.Rules,
+	}
+
+	addCodeModulesSource(dk, secretConfig)
+	return secretConfig, nil
+}
+
+// addCodeModulesSource passes the configured source of the code modules on to the init container.
+// The credentials of the pull secret stay in the operator namespace, the init container pulls the image with the imagePullSecrets of the pod.
+func addCodeModulesSource(dk *dynatracev1beta1.DynaKube, secretConfig *standalone.SecretConfig) {
+	codeModulesSource := dk.CodeModulesSource()
+	if codeModulesSource == nil {
+		return
+	}
+
+	secretConfig.CodeModulesImage = codeModulesSource.Image
+	secretConfig.CodeModulesImagePath = codeModulesSource.ImagePath
+	secretConfig.MirrorIndexUrl = codeModulesSource.MirrorIndexURL
+	secretConfig.MirrorPublicKey = codeModulesSource.MirrorPublicKey
 }
 
-func getPaasToken(tokens corev1.Secret) string {
+func getPaasToken(tokens *corev1.Secret) string {
 	if len(tokens.Data[dtclient.DynatracePaasToken]) != 0 {
 		return string(tokens.Data[dtclient.DynatracePaasToken])
 	}
 	return string(tokens.Data[dtclient.DynatraceApiToken])
 }
 
-func getAPIToken(tokens corev1.Secret) string {
+func getAPIToken(tokens *corev1.Secret) string {
 	return string(tokens.Data[dtclient.DynatraceApiToken])
 }
 
@@ -3,6 +3,7 @@ package initgeneration
 import (
 	"context"
 	_ "embed"
+	"encoding/json"
 	"testing"
 
 	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
@@ -11,6 +12,7 @@ import (
 	"github.com/Dynatrace/dynatrace-operator/src/standalone"
 	"github.com/Dynatrace/dynatrace-operator/src/webhook"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
@@ -288,6 +290,170 @@ func TestGenerateForDynakube(t *testing.T) {
 	})
 }
 
+func TestGenerateForTenantTargets(t *testing.T) {
+	const (
+		testTargetName       = "migration"
+		testTargetApiUrl     = "https://migration-url/api"
+		testTargetTenantUUID = "def67890"
+	)
+	testTargetTokens := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: testDynakubeSimpleName + "-" + testTargetName, Namespace: operatorNamespace},
+		Data:       map[string][]byte{"apiToken": []byte("target-api")},
+	}
+	newDynakubeWithTarget := func() *dynatracev1beta1.DynaKube {
+		dk := testDynakubeSimple.DeepCopy()
+		dk.Spec.AdditionalTenants = []dynatracev1beta1.TenantTargetSpec{{Name: testTargetName, APIURL: testTargetApiUrl}}
+		dk.Status.AdditionalTenants = []dynatracev1beta1.TenantTargetStatus{{
+			Name:           testTargetName,
+			ConnectionInfo: dynatracev1beta1.ConnectionInfoStatus{TenantUUID: testTargetTenantUUID},
+		}}
+		return dk
+	}
+	testNamespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   testNamespaceName,
+			Labels: map[string]string{mapper.InstanceLabel: testDynakubeSimple.Name},
+		},
+	}
+
+	t.Run("Add config of tenant target to secret", func(t *testing.T) {
+		dk := newDynakubeWithTarget()
+		clt := fake.NewClient(testNamespace.DeepCopy(), testSecretDynakubeSimple, testTargetTokens, kubeNamespace, testNode1, testNode2)
+		ig := NewInitGenerator(clt, clt, operatorNamespace)
+
+		updated, err := ig.GenerateForDynakube(context.TODO(), dk)
+		require.NoError(t, err)
+		assert.True(t, updated)
+
+		var initSecret corev1.Secret
+		err = clt.Get(context.TODO(), types.NamespacedName{Name: webhook.SecretConfigName, Namespace: testNamespaceName}, &initSecret)
+		require.NoError(t, err)
+		assert.Equal(t, 2, len(initSecret.Data))
+
+		secretConfig := unmarshalSecretConfig(t, initSecret.Data[standalone.SecretConfigFieldName])
+		assert.Equal(t, testApiUrl, secretConfig.ApiUrl)
+		assert.Equal(t, testTenantUUID, secretConfig.TenantUUID)
+
+		secretConfig = unmarshalSecretConfig(t, initSecret.Data[SecretConfigFieldNameForTarget(testTargetName)])
+		assert.Equal(t, testTargetApiUrl, secretConfig.ApiUrl)
+		assert.Equal(t, "target-api", secretConfig.ApiToken)
+		assert.Equal(t, "target-api", secretConfig.PaasToken)
+		assert.Equal(t, testTargetTenantUUID, secretConfig.TenantUUID)
+	})
+	t.Run("Skip tenant target which is not connected yet", func(t *testing.T) {
+		dk := newDynakubeWithTarget()
+		dk.Status.AdditionalTenants = nil
+		clt := fake.NewClient(testNamespace.DeepCopy(), testSecretDynakubeSimple, kubeNamespace, testNode1, testNode2)
+		ig := NewInitGenerator(clt, clt, operatorNamespace)
+
+		_, err := ig.GenerateForNamespace(context.TODO(), *dk, testNamespaceName)
+		require.NoError(t, err)
+
+		var initSecret corev1.Secret
+		err = clt.Get(context.TODO(), types.NamespacedName{Name: webhook.SecretConfigName, Namespace: testNamespaceName}, &initSecret)
+		require.NoError(t, err)
+		assert.Equal(t, 1, len(initSecret.Data))
+	})
+	t.Run("Missing tokens of tenant target", func(t *testing.T) {
+		dk := newDynakubeWithTarget()
+		clt := fake.NewClient(testNamespace.DeepCopy(), testSecretDynakubeSimple, kubeNamespace)
+		ig := NewInitGenerator(clt, clt, operatorNamespace)
+
+		_, err := ig.GenerateForNamespace(context.TODO(), *dk, testNamespaceName)
+		assert.Error(t, err)
+	})
+}
+
+func TestGenerateForEnrichmentRules(t *testing.T) {
+	rules := []dynatracev1beta1.EnrichmentRule{
+		{Type: dynatracev1beta1.EnrichmentRuleTypeNamespaceLabel, Source: "cost-center"},
+		{Type: dynatracev1beta1.EnrichmentRuleTypeNodeLabel, Source: "topology.kubernetes.io/zone"},
+		{Type: dynatracev1beta1.EnrichmentRuleTypePodLabel, Source: "team"},
+	}
+	testNamespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   testNamespaceName,
+			Labels: map[string]string{mapper.InstanceLabel: testDynakubeSimple.Name, "cost-center": "1234"},
+		},
+	}
+
+	testNodeWithZone := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   testNode1Name,
+			Labels: map[string]string{"topology.kubernetes.io/zone": "eu-west-1a", "other": "label"},
+		},
+	}
+	testOtherNodeWithZone := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   testNode2Name,
+			Labels: map[string]string{"topology.kubernetes.io/zone": "eu-west-1a"},
+		},
+	}
+
+	t.Run("Add rules, namespace and node labels to secret", func(t *testing.T) {
+		dk := testDynakubeSimple.DeepCopy()
+		dk.Spec.MetadataEnrichment.Rules = rules
+		clt := fake.NewClient(testNamespace.DeepCopy(), testSecretDynakubeSimple, kubeNamespace, testNodeWithZone, testOtherNodeWithZone)
+		ig := NewInitGenerator(clt, clt, operatorNamespace)
+
+		_, err := ig.GenerateForDynakube(context.TODO(), dk)
+		require.NoError(t, err)
+
+		var initSecret corev1.Secret
+		err = clt.Get(context.TODO(), types.NamespacedName{Name: webhook.SecretConfigName, Namespace: testNamespaceName}, &initSecret)
+		require.NoError(t, err)
+
+		secretConfig := unmarshalSecretConfig(t, initSecret.Data[standalone.SecretConfigFieldName])
+		assert.Equal(t, rules, secretConfig.EnrichmentRules)
+		assert.Equal(t, map[string]string{"cost-center": "1234"}, secretConfig.NamespaceLabels)
+		// both nodes share their labels, so the set is only stored once
+		assert.Equal(t, []map[string]string{{"topology.kubernetes.io/zone": "eu-west-1a"}}, secretConfig.NodeLabelSets)
+		assert.Equal(t, map[string]int{testNode1Name: 0, testNode2Name: 0}, secretConfig.NodeLabelSetOfNode)
+	})
+	t.Run("Namespace and node labels are added by the webhook", func(t *testing.T) {
+		dk := testDynakubeSimple.DeepCopy()
+		dk.Spec.MetadataEnrichment.Rules = rules
+		clt := fake.NewClient(testNamespace.DeepCopy(), testSecretDynakubeSimple, kubeNamespace, testNodeWithZone, testNode2)
+		ig := NewInitGenerator(clt, clt, operatorNamespace)
+
+		_, err := ig.GenerateForNamespace(context.TODO(), *dk, testNamespaceName)
+		require.NoError(t, err)
+
+		var initSecret corev1.Secret
+		err = clt.Get(context.TODO(), types.NamespacedName{Name: webhook.SecretConfigName, Namespace: testNamespaceName}, &initSecret)
+		require.NoError(t, err)
+
+		secretConfig := unmarshalSecretConfig(t, initSecret.Data[standalone.SecretConfigFieldName])
+		assert.Equal(t, map[string]string{"cost-center": "1234"}, secretConfig.NamespaceLabels)
+		assert.Equal(t, []map[string]string{{"topology.kubernetes.io/zone": "eu-west-1a"}, {}}, secretConfig.NodeLabelSets)
+		assert.Equal(t, map[string]int{testNode1Name: 0, testNode2Name: 1}, secretConfig.NodeLabelSetOfNode)
+	})
+	t.Run("No nodes are listed without node label rules", func(t *testing.T) {
+		dk := testDynakubeSimple.DeepCopy()
+		dk.Spec.MetadataEnrichment.Rules = rules[:1]
+		clt := fake.NewClient(testNamespace.DeepCopy(), testSecretDynakubeSimple, kubeNamespace, testNodeWithZone)
+		ig := NewInitGenerator(clt, clt, operatorNamespace)
+
+		_, err := ig.GenerateForNamespace(context.TODO(), *dk, testNamespaceName)
+		require.NoError(t, err)
+
+		var initSecret corev1.Secret
+		err = clt.Get(context.TODO(), types.NamespacedName{Name: webhook.SecretConfigName, Namespace: testNamespaceName}, &initSecret)
+		require.NoError(t, err)
+
+		secretConfig := unmarshalSecretConfig(t, initSecret.Data[standalone.SecretConfigFieldName])
+		assert.Empty(t, secretConfig.NodeLabelSets)
+		assert.Empty(t, secretConfig.NodeLabelSetOfNode)
+	})
+}
+
+func unmarshalSecretConfig(t *testing.T, data []byte) standalone.SecretConfig {
+	var secretConfig standalone.SecretConfig
+	require.NoError(t, json.Unmarshal(data, &secretConfig))
+	return secretConfig
+}
+
+
 func TestGetInfraMonitoringNodes(t *testing.T) {
 	t.Run("Get IMNodes using nodes", func(t *testing.T) {
 		clt := fake.NewClient(testNode1, testNode2)
@@ -327,6 +493,31 @@ func TestPrepareSecretConfigForDynaKube(t *testing.T) {
 	t.Run("Create SecretConfig with correct content, if only apiToken is provided", func(t *testing.T) {
 		testForCorrectContent(t, testSecretDynakubeComplexOnlyApi)
 	})
+	t.Run("Create SecretConfig with code modules source", func(t *testing.T) {
+		dk := testDynakubeComplex.DeepCopy()
+		dk.Spec.CustomPullSecret = "pull-secret"
+		dk.Spec.OneAgent.CloudNativeFullStack.CodeModulesSource = &dynatracev1beta1.CodeModulesSourceSpec{
+			Image:     "registry.local/codemodules",
+			ImagePath: "opt/oneagent",
+		}
+		pullSecret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "pull-secret", Namespace: operatorNamespace},
+			Data: map[string][]byte{
+				".dockerconfigjson": []byte(`{"auths":{"registry.local":{"username":"user","password":"pass"}}}`),
+			},
+		}
+		clt := fake.NewClient(testSecretDynakubeComplex, caConfigMap, testTlsSecretDynakubeComplex, pullSecret)
+		ig := NewInitGenerator(clt, clt, operatorNamespace)
+
+		secretConfig, err := ig.prepareSecretConfigForDynaKube(dk, kubesystemUID, nil)
+		require.NoError(t, err)
+		assert.Equal(t, "registry.local/codemodules", secretConfig.CodeModulesImage)
+		assert.Equal(t, "opt/oneagent", secretConfig.CodeModulesImagePath)
+
+		rawConfig, err := json.Marshal(secretConfig)
+		require.NoError(t, err)
+		assert.NotContains(t, string(rawConfig), "pass")
+	})
 }
 
 func testForCorrectContent(t *testing.T, secret *corev1.Secret) {
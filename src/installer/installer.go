@@ -5,11 +5,9 @@ import (
 	"io"
 	"os"
 	"path/filepath"
-	"strings"
 
 	"github.com/Dynatrace/dynatrace-operator/src/dtclient"
 	"github.com/Dynatrace/dynatrace-operator/src/processmoduleconfig"
-	"github.com/klauspost/compress/zip"
 	"github.com/spf13/afero"
 )
 
@@ -31,6 +29,9 @@ type InstallerProperties struct {
 	Version      string
 	Technologies []string
 	Url          string // if this is set all others will be ignored
+	Sha256       string // if this is set the downloaded package, or the manifest of the image, must match this hex encoded checksum
+	// AllowUnverified allows installing packages from the Dynatrace API without Sha256, they are only checked for archive integrity
+	AllowUnverified bool
 }
 
 func (props *InstallerProperties) fillEmptyWithDefaults() {
@@ -43,25 +44,31 @@ type Installer interface {
 	InstallAgent(targetDir string) error
 	UpdateProcessModuleConfig(targetDir string, processModuleConfig *dtclient.ProcessModuleConfig) error
 	SetVersion(version string)
+	SetFlavor(flavor string)
+	SetSha256(sha256 string)
 }
 
 var _ Installer = &OneAgentInstaller{}
 
 type OneAgentInstaller struct {
-	fs    afero.Fs
-	dtc   dtclient.Client
-	props InstallerProperties
+	fs     afero.Fs
+	dtc    dtclient.Client
+	props  InstallerProperties
+	source ArtifactSource
 }
 
+// NewOneAgentInstaller creates an installer which gets the OneAgent from the given source, the Dynatrace API is used if source is nil.
 func NewOneAgentInstaller(
 	fs afero.Fs,
 	dtc dtclient.Client,
 	props InstallerProperties,
+	source ArtifactSource,
 ) *OneAgentInstaller {
 	return &OneAgentInstaller{
-		fs:    fs,
-		dtc:   dtc,
-		props: props,
+		fs:     fs,
+		dtc:    dtc,
+		props:  props,
+		source: source,
 	}
 }
 
@@ -81,160 +88,27 @@ func (installer *OneAgentInstaller) SetVersion(version string) {
 	installer.props.Version = version
 }
 
-func (installer *OneAgentInstaller) installAgent(targetDir string) error {
-	fs := installer.fs
-	tmpFile, err := afero.TempFile(fs, "", "download")
-	if err != nil {
-		return fmt.Errorf("failed to create temporary file for download: %w", err)
-	}
-	defer func() {
-		_ = tmpFile.Close()
-		if err := fs.Remove(tmpFile.Name()); err != nil {
-			log.Error(err, "failed to delete downloaded file", "path", tmpFile.Name())
-		}
-	}()
-
-	if installer.props.Url != "" {
-		if err := installer.downloadOneAgentViaInstallerUrl(tmpFile); err != nil {
-			return err
-		}
-	} else if installer.props.Version == VersionLatest {
-		if err := installer.downloadLatestOneAgent(tmpFile); err != nil {
-			return err
-		}
-	} else {
-		if err := installer.downloadOneAgentWithVersion(tmpFile); err != nil {
-			return err
-		}
-	}
-
-	var fileSize int64
-	if stat, err := tmpFile.Stat(); err == nil {
-		fileSize = stat.Size()
-	}
-
-	log.Info("saved OneAgent package", "dest", tmpFile.Name(), "size", fileSize)
-	log.Info("unzipping OneAgent package")
-	if err := installer.unzip(tmpFile, targetDir); err != nil {
-		return fmt.Errorf("failed to unzip file: %w", err)
-	}
-	log.Info("unzipped OneAgent package")
-
-	if err = installer.createSymlinkIfNotExists(targetDir); err != nil {
-		return err
-	}
-
-	return nil
+func (installer *OneAgentInstaller) SetFlavor(flavor string) {
+	installer.props.Flavor = flavor
 }
 
-func (installer *OneAgentInstaller) downloadLatestOneAgent(tmpFile afero.File) error {
-	log.Info("downloading latest OneAgent package", "props", installer.props)
-	return installer.dtc.GetLatestAgent(
-		installer.props.Os,
-		installer.props.Type,
-		installer.props.Flavor,
-		installer.props.Arch,
-		installer.props.Technologies,
-		tmpFile,
-	)
+func (installer *OneAgentInstaller) SetSha256(sha256 string) {
+	installer.props.Sha256 = sha256
 }
 
-func (installer *OneAgentInstaller) downloadOneAgentWithVersion(tmpFile afero.File) error {
-	log.Info("downloading specific OneAgent package", "version", installer.props.Version)
-	err := installer.dtc.GetAgent(
-		installer.props.Os,
-		installer.props.Type,
-		installer.props.Flavor,
-		installer.props.Arch,
-		installer.props.Version,
-		installer.props.Technologies,
-		tmpFile,
-	)
-
-	if err != nil {
-		availableVersions, getVersionsError := installer.dtc.GetAgentVersions(
-			installer.props.Os,
-			installer.props.Type,
-			installer.props.Flavor,
-			installer.props.Arch,
-		)
-		if getVersionsError != nil {
-			return fmt.Errorf("failed to fetch OneAgent version: %w", err)
-		}
-		return fmt.Errorf("failed to fetch OneAgent version: %w, available versions are: %s", err, "[ "+strings.Join(availableVersions, " , ")+" ]")
+func (installer *OneAgentInstaller) installAgent(targetDir string) error {
+	if err := installer.artifactSource().Extract(installer.props, targetDir); err != nil {
+		return err
 	}
-	return nil
-}
 
-func (installer *OneAgentInstaller) downloadOneAgentViaInstallerUrl(tmpFile afero.File) error {
-	log.Info("downloading OneAgent package using provided url, all other properties are ignored", "url", installer.props.Url)
-	return installer.dtc.GetAgentViaInstallerUrl(installer.props.Url, tmpFile)
+	return installer.createSymlinkIfNotExists(targetDir)
 }
 
-func (installer *OneAgentInstaller) unzip(file afero.File, targetDir string) error {
-	fs := installer.fs
-
-	if file == nil {
-		return fmt.Errorf("file is nil")
+func (installer *OneAgentInstaller) artifactSource() ArtifactSource {
+	if installer.source == nil {
+		return NewDynatraceSource(installer.fs, installer.dtc)
 	}
-
-	fileInfo, err := file.Stat()
-	if err != nil {
-		return fmt.Errorf("unable to determine file info: %w", err)
-	}
-
-	reader, err := zip.NewReader(file, fileInfo.Size())
-	if err != nil {
-		return fmt.Errorf("failed to open ZIP file: %w", err)
-	}
-
-	_ = fs.MkdirAll(targetDir, 0755)
-
-	for _, file := range reader.File {
-		err := func() error {
-			path := filepath.Join(targetDir, file.Name)
-
-			// Check for ZipSlip: https://snyk.io/research/zip-slip-vulnerability
-			if !strings.HasPrefix(path, filepath.Clean(targetDir)+string(os.PathSeparator)) {
-				return fmt.Errorf("illegal file path: %s", path)
-			}
-
-			mode := file.Mode()
-
-			// Mark all files inside ./agent/conf as group-writable
-			if file.Name != agentConfPath && strings.HasPrefix(file.Name, agentConfPath) {
-				mode |= 020
-			}
-
-			if file.FileInfo().IsDir() {
-				return fs.MkdirAll(path, mode)
-			}
-
-			if err := fs.MkdirAll(filepath.Dir(path), mode); err != nil {
-				return err
-			}
-
-			dstFile, err := fs.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode)
-			if err != nil {
-				return err
-			}
-			defer func() { _ = dstFile.Close() }()
-
-			srcFile, err := file.Open()
-			if err != nil {
-				return err
-			}
-			defer func() { _ = srcFile.Close() }()
-
-			_, err = io.Copy(dstFile, srcFile)
-			return err
-		}()
-		if err != nil {
-			return err
-		}
-	}
-
-	return nil
+	return installer.source
 }
 
 func (installer *OneAgentInstaller) createSymlinkIfNotExists(targetDir string) error {
@@ -55,11 +55,36 @@ func TestInstallAgent(t *testing.T) {
 		}
 		installer := &OneAgentInstaller{
 			fs: fs,
+			props: InstallerProperties{
+				AllowUnverified: true,
+			},
 		}
 
 		err := installer.installAgent("")
 		assert.EqualError(t, err, "failed to create temporary file for download: "+testErrorMessage)
 	})
+	t.Run(`error without pinned checksum`, func(t *testing.T) {
+		fs := afero.NewMemMapFs()
+		dtc := &dtclient.MockDynatraceClient{}
+		installer := &OneAgentInstaller{
+			fs:  fs,
+			dtc: dtc,
+			props: InstallerProperties{
+				Os:     dtclient.OsUnix,
+				Type:   dtclient.InstallerTypePaaS,
+				Flavor: dtclient.FlavorMultidistro,
+			},
+		}
+
+		err := installer.installAgent(testDir)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "no checksum pinned")
+		dtc.AssertNotCalled(t, "GetAgent", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+		exists, err := afero.DirExists(fs, testDir)
+		require.NoError(t, err)
+		assert.False(t, exists)
+	})
 	t.Run(`error when downloading latest agent`, func(t *testing.T) {
 		fs := afero.NewMemMapFs()
 		dtc := &dtclient.MockDynatraceClient{}
@@ -74,9 +99,10 @@ func TestInstallAgent(t *testing.T) {
 			fs:  fs,
 			dtc: dtc,
 			props: InstallerProperties{
-				Os:     dtclient.OsUnix,
-				Type:   dtclient.InstallerTypePaaS,
-				Flavor: dtclient.FlavorMultidistro,
+				AllowUnverified: true,
+				Os:              dtclient.OsUnix,
+				Type:            dtclient.InstallerTypePaaS,
+				Flavor:          dtclient.FlavorMultidistro,
 			},
 		}
 
@@ -104,9 +130,10 @@ func TestInstallAgent(t *testing.T) {
 			fs:  fs,
 			dtc: dtc,
 			props: InstallerProperties{
-				Os:     dtclient.OsUnix,
-				Type:   dtclient.InstallerTypePaaS,
-				Flavor: dtclient.FlavorMultidistro,
+				AllowUnverified: true,
+				Os:              dtclient.OsUnix,
+				Type:            dtclient.InstallerTypePaaS,
+				Flavor:          dtclient.FlavorMultidistro,
 			},
 		}
 
@@ -133,10 +160,11 @@ func TestInstallAgent(t *testing.T) {
 			fs:  fs,
 			dtc: dtc,
 			props: InstallerProperties{
-				Os:      dtclient.OsUnix,
-				Type:    dtclient.InstallerTypePaaS,
-				Flavor:  dtclient.FlavorMultidistro,
-				Version: testVersion,
+				AllowUnverified: true,
+				Os:              dtclient.OsUnix,
+				Type:            dtclient.InstallerTypePaaS,
+				Flavor:          dtclient.FlavorMultidistro,
+				Version:         testVersion,
 			},
 		}
 
@@ -169,10 +197,11 @@ func TestInstallAgent(t *testing.T) {
 			fs:  fs,
 			dtc: dtc,
 			props: InstallerProperties{
-				Os:      dtclient.OsUnix,
-				Type:    dtclient.InstallerTypePaaS,
-				Flavor:  dtclient.FlavorMultidistro,
-				Version: VersionLatest,
+				AllowUnverified: true,
+				Os:              dtclient.OsUnix,
+				Type:            dtclient.InstallerTypePaaS,
+				Flavor:          dtclient.FlavorMultidistro,
+				Version:         VersionLatest,
 			},
 		}
 
@@ -204,7 +233,8 @@ func TestInstallAgent(t *testing.T) {
 			fs:  fs,
 			dtc: dtc,
 			props: InstallerProperties{
-				Url: testUrl,
+				AllowUnverified: true,
+				Url:             testUrl,
 			},
 		}
 
@@ -222,21 +252,15 @@ func TestInstallAgent(t *testing.T) {
 func TestUnzip(t *testing.T) {
 	t.Run(`file nil`, func(t *testing.T) {
 		fs := afero.NewMemMapFs()
-		installer := &OneAgentInstaller{
-			fs: fs,
-		}
-		err := installer.unzip(nil, "")
+		err := unzip(fs, nil, "")
 		require.EqualError(t, err, "file is nil")
 	})
 	t.Run(`unzip test zip file`, func(t *testing.T) {
 		fs := afero.NewMemMapFs()
-		installer := &OneAgentInstaller{
-			fs: fs,
-		}
 		zipFile := setupTestZip(t, fs)
 		defer func() { _ = zipFile.Close() }()
 
-		err := installer.unzip(zipFile, testDir)
+		err := unzip(fs, zipFile, testDir)
 		require.NoError(t, err)
 
 		exists, err := afero.Exists(fs, filepath.Join(testDir, testFilename))
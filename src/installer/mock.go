@@ -24,3 +24,11 @@ func (mock *InstallerMock) UpdateProcessModuleConfig(targetDir string, processMo
 func (mock *InstallerMock) SetVersion(version string) {
 	mock.Called(version)
 }
+
+func (mock *InstallerMock) SetFlavor(flavor string) {
+	mock.Called(flavor)
+}
+
+func (mock *InstallerMock) SetSha256(sha256 string) {
+	mock.Called(sha256)
+}
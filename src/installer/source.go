@@ -0,0 +1,34 @@
+package installer
+
+import (
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/dtversion"
+	"github.com/Dynatrace/dynatrace-operator/src/dtclient"
+	"github.com/spf13/afero"
+)
+
+// ArtifactSource provides the OneAgent code modules matching the installer properties and extracts them into the target directory.
+type ArtifactSource interface {
+	Extract(props InstallerProperties, targetDir string) error
+}
+
+// SourceConfig selects where the OneAgent code modules are taken from.
+// The Dynatrace API is used if neither an image nor a mirror is configured.
+type SourceConfig struct {
+	Image        string
+	ImagePath    string
+	DockerConfig *dtversion.DockerConfig
+
+	MirrorIndexUrl  string
+	MirrorPublicKey string
+	SkipCertCheck   bool
+}
+
+// NewArtifactSource creates the ArtifactSource for the given config, an image takes precedence over a mirror.
+func NewArtifactSource(fs afero.Fs, dtc dtclient.Client, config SourceConfig) (ArtifactSource, error) {
+	if config.Image != "" {
+		return NewOciSource(fs, config.Image, config.ImagePath, config.DockerConfig), nil
+	} else if config.MirrorIndexUrl != "" {
+		return NewMirrorSource(fs, config.MirrorIndexUrl, config.MirrorPublicKey, config.SkipCertCheck)
+	}
+	return NewDynatraceSource(fs, dtc), nil
+}
@@ -0,0 +1,85 @@
+package installer
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Dynatrace/dynatrace-operator/src/dtclient"
+	"github.com/spf13/afero"
+)
+
+// dynatraceSource downloads the OneAgent package from the deployment API of the tenant.
+type dynatraceSource struct {
+	fs  afero.Fs
+	dtc dtclient.Client
+}
+
+var _ ArtifactSource = &dynatraceSource{}
+
+func NewDynatraceSource(fs afero.Fs, dtc dtclient.Client) ArtifactSource {
+	return &dynatraceSource{
+		fs:  fs,
+		dtc: dtc,
+	}
+}
+
+// Extract downloads the package and extracts it to the target directory. The Dynatrace API doesn't provide a checksum
+// for the package, so a pinned one is required, unless unverified packages are explicitly allowed.
+func (source *dynatraceSource) Extract(props InstallerProperties, targetDir string) error {
+	if props.Sha256 == "" && !props.AllowUnverified {
+		return errors.New("no checksum pinned for the OneAgent package, pin it with the sha256 of the code modules source or the oneagent.dynatrace.com/installer-sha256 annotation, or allow unverified downloads")
+	}
+	return extractZip(source.fs, targetDir, props.Sha256, func(tmpFile afero.File) error {
+		if props.Url != "" {
+			return source.downloadOneAgentViaInstallerUrl(props, tmpFile)
+		} else if props.Version == VersionLatest {
+			return source.downloadLatestOneAgent(props, tmpFile)
+		}
+		return source.downloadOneAgentWithVersion(props, tmpFile)
+	})
+}
+
+func (source *dynatraceSource) downloadLatestOneAgent(props InstallerProperties, tmpFile afero.File) error {
+	log.Info("downloading latest OneAgent package", "props", props)
+	return source.dtc.GetLatestAgent(
+		props.Os,
+		props.Type,
+		props.Flavor,
+		props.Arch,
+		props.Technologies,
+		tmpFile,
+	)
+}
+
+func (source *dynatraceSource) downloadOneAgentWithVersion(props InstallerProperties, tmpFile afero.File) error {
+	log.Info("downloading specific OneAgent package", "version", props.Version)
+	err := source.dtc.GetAgent(
+		props.Os,
+		props.Type,
+		props.Flavor,
+		props.Arch,
+		props.Version,
+		props.Technologies,
+		tmpFile,
+	)
+
+	if err != nil {
+		availableVersions, getVersionsError := source.dtc.GetAgentVersions(
+			props.Os,
+			props.Type,
+			props.Flavor,
+			props.Arch,
+		)
+		if getVersionsError != nil {
+			return fmt.Errorf("failed to fetch OneAgent version: %w", err)
+		}
+		return fmt.Errorf("failed to fetch OneAgent version: %w, available versions are: %s", err, "[ "+strings.Join(availableVersions, " , ")+" ]")
+	}
+	return nil
+}
+
+func (source *dynatraceSource) downloadOneAgentViaInstallerUrl(props InstallerProperties, tmpFile afero.File) error {
+	log.Info("downloading OneAgent package using provided url, all other properties are ignored", "url", props.Url)
+	return source.dtc.GetAgentViaInstallerUrl(props.Url, tmpFile)
+}
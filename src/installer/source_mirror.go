@@ -0,0 +1,204 @@
+package installer
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/dtversion"
+	"github.com/spf13/afero"
+)
+
+const (
+	signatureSuffix = ".sig"
+	mirrorTimeout   = 15 * time.Minute
+)
+
+// MirrorIndex lists the OneAgent packages provided by a mirror.
+// The index is signed with ed25519, the base64 encoded signature of the index file is expected at <index url>.sig.
+type MirrorIndex struct {
+	Artifacts []MirrorArtifact `json:"artifacts"`
+}
+
+// MirrorArtifact is a OneAgent package in zip format, the url may be relative to the index.
+type MirrorArtifact struct {
+	Version string `json:"version"`
+	Os      string `json:"os"`
+	Type    string `json:"type"`
+	Flavor  string `json:"flavor"`
+	Arch    string `json:"arch"`
+	Url     string `json:"url"`
+	Sha256  string `json:"sha256"`
+}
+
+// mirrorSource downloads the OneAgent package from a http(s) or file mirror which provides a signed index.
+type mirrorSource struct {
+	fs         afero.Fs
+	httpClient *http.Client
+	indexUrl   *url.URL
+	publicKey  ed25519.PublicKey
+}
+
+var _ ArtifactSource = &mirrorSource{}
+
+func NewMirrorSource(fs afero.Fs, indexUrl string, publicKey string, skipCertCheck bool) (ArtifactSource, error) {
+	parsedUrl, err := url.Parse(indexUrl)
+	if err != nil {
+		return nil, fmt.Errorf("invalid mirror index url %s: %w", indexUrl, err)
+	}
+	if parsedUrl.Scheme != "http" && parsedUrl.Scheme != "https" && parsedUrl.Scheme != "file" {
+		return nil, fmt.Errorf("unsupported scheme of mirror index url %s", indexUrl)
+	}
+
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(publicKey))
+	if err != nil {
+		return nil, fmt.Errorf("invalid mirror public key: %w", err)
+	}
+	if len(key) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid mirror public key, expected %d bytes but got %d", ed25519.PublicKeySize, len(key))
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: skipCertCheck} //nolint:gosec // skipping the check is an explicit choice of the user
+
+	return &mirrorSource{
+		fs:         fs,
+		httpClient: &http.Client{Transport: transport, Timeout: mirrorTimeout},
+		indexUrl:   parsedUrl,
+		publicKey:  ed25519.PublicKey(key),
+	}, nil
+}
+
+func (source *mirrorSource) Extract(props InstallerProperties, targetDir string) error {
+	index, err := source.getIndex()
+	if err != nil {
+		return err
+	}
+
+	artifact, err := index.find(props)
+	if err != nil {
+		return err
+	}
+
+	if artifact.Sha256 == "" {
+		return fmt.Errorf("mirror index does not provide a checksum for version %s", artifact.Version)
+	} else if props.Sha256 != "" && !strings.EqualFold(props.Sha256, artifact.Sha256) {
+		return fmt.Errorf("checksum mismatch, expected sha256 %s but the mirror provides %s", props.Sha256, artifact.Sha256)
+	}
+
+	artifactUrl, err := source.indexUrl.Parse(artifact.Url)
+	if err != nil {
+		return fmt.Errorf("invalid url of artifact %s: %w", artifact.Url, err)
+	}
+
+	return extractZip(source.fs, targetDir, artifact.Sha256, func(tmpFile afero.File) error {
+		log.Info("downloading OneAgent package from mirror", "url", artifactUrl.String(), "version", artifact.Version)
+		return source.download(artifactUrl, tmpFile)
+	})
+}
+
+// getIndex downloads the index and verifies its signature before it is parsed.
+func (source *mirrorSource) getIndex() (*MirrorIndex, error) {
+	var indexData, signatureData bytes.Buffer
+	if err := source.download(source.indexUrl, &indexData); err != nil {
+		return nil, fmt.Errorf("failed to download mirror index: %w", err)
+	}
+
+	signatureUrl := *source.indexUrl
+	signatureUrl.Path += signatureSuffix
+	if err := source.download(&signatureUrl, &signatureData); err != nil {
+		return nil, fmt.Errorf("failed to download signature of mirror index: %w", err)
+	}
+
+	signature, err := base64.StdEncoding.DecodeString(strings.TrimSpace(signatureData.String()))
+	if err != nil {
+		return nil, fmt.Errorf("invalid signature of mirror index: %w", err)
+	}
+	if !ed25519.Verify(source.publicKey, indexData.Bytes(), signature) {
+		return nil, fmt.Errorf("signature of mirror index %s does not match the public key", source.indexUrl)
+	}
+
+	var index MirrorIndex
+	if err := json.Unmarshal(indexData.Bytes(), &index); err != nil {
+		return nil, fmt.Errorf("failed to parse mirror index: %w", err)
+	}
+	return &index, nil
+}
+
+func (source *mirrorSource) download(location *url.URL, writer io.Writer) error {
+	if location.Scheme == "file" {
+		file, err := source.fs.Open(location.Path)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = file.Close() }()
+
+		_, err = io.Copy(writer, file)
+		return err
+	}
+
+	response, err := source.httpClient.Get(location.String())
+	if err != nil {
+		return err
+	}
+	defer func() { _ = response.Body.Close() }()
+
+	if response.StatusCode != http.StatusOK {
+		_, _ = io.Copy(ioutil.Discard, response.Body)
+		return fmt.Errorf("request to %s failed with status code %d", location, response.StatusCode)
+	}
+
+	_, err = io.Copy(writer, response.Body)
+	return err
+}
+
+// find returns the artifact for the requested version, or the highest version if the latest is requested.
+// Empty fields of the installer properties or the artifact match anything.
+func (index *MirrorIndex) find(props InstallerProperties) (*MirrorArtifact, error) {
+	var found *MirrorArtifact
+	var foundVersion dtversion.VersionInfo
+
+	for i := range index.Artifacts {
+		artifact := &index.Artifacts[i]
+		if !matches(props.Os, artifact.Os) || !matches(props.Type, artifact.Type) ||
+			!matches(props.Flavor, artifact.Flavor) || !matches(props.Arch, artifact.Arch) {
+			continue
+		}
+
+		if props.Version != VersionLatest && props.Version != "" {
+			if artifact.Version == props.Version {
+				return artifact, nil
+			}
+			continue
+		}
+
+		version, err := dtversion.ExtractVersion(artifact.Version)
+		if err != nil {
+			log.Info("ignoring artifact with invalid version in mirror index", "version", artifact.Version)
+			continue
+		}
+		if found == nil || dtversion.CompareVersionInfo(version, foundVersion) > 0 {
+			found = artifact
+			foundVersion = version
+		}
+	}
+
+	if found == nil {
+		return nil, fmt.Errorf("mirror index does not contain a OneAgent package for version %s, os %s, type %s, flavor %s and arch %s",
+			props.Version, props.Os, props.Type, props.Flavor, props.Arch)
+	}
+	return found, nil
+}
+
+func matches(requested string, provided string) bool {
+	return requested == "" || provided == "" || requested == provided
+}
@@ -0,0 +1,131 @@
+package installer
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/Dynatrace/dynatrace-operator/src/dtclient"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testMirrorDir = "/mirror"
+
+func createTestMirrorIndex(t *testing.T, privateKey ed25519.PrivateKey, zipSha256 string) ([]byte, []byte) {
+	index, err := json.Marshal(MirrorIndex{
+		Artifacts: []MirrorArtifact{
+			{Version: "1.203.0.20200908-220956", Os: dtclient.OsUnix, Type: dtclient.InstallerTypePaaS, Arch: dtclient.ArchX86, Url: "old.zip", Sha256: "0000"},
+			{Version: "1.205.0.20201001-100000", Os: dtclient.OsUnix, Type: dtclient.InstallerTypePaaS, Arch: dtclient.ArchX86, Url: "agents/latest.zip", Sha256: zipSha256},
+			{Version: "1.207.0.20201101-100000", Os: dtclient.OsUnix, Type: dtclient.InstallerTypePaaS, Arch: dtclient.ArchARM, Url: "arm.zip", Sha256: "0000"},
+		},
+	})
+	require.NoError(t, err)
+	signature := base64.StdEncoding.EncodeToString(ed25519.Sign(privateKey, index))
+	return index, []byte(signature)
+}
+
+func setupTestMirror(t *testing.T) (afero.Fs, string) {
+	zipContent, err := base64.StdEncoding.DecodeString(testZip)
+	require.NoError(t, err)
+	checksum := sha256.Sum256(zipContent)
+
+	publicKey, privateKey, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	index, signature := createTestMirrorIndex(t, privateKey, hex.EncodeToString(checksum[:]))
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, filepath.Join(testMirrorDir, "index.json"), index, 0644))
+	require.NoError(t, afero.WriteFile(fs, filepath.Join(testMirrorDir, "index.json.sig"), signature, 0644))
+	require.NoError(t, afero.WriteFile(fs, filepath.Join(testMirrorDir, "agents", "latest.zip"), zipContent, 0644))
+
+	return fs, base64.StdEncoding.EncodeToString(publicKey)
+}
+
+func TestMirrorSource(t *testing.T) {
+	props := InstallerProperties{
+		Os:      dtclient.OsUnix,
+		Type:    dtclient.InstallerTypePaaS,
+		Arch:    dtclient.ArchX86,
+		Flavor:  dtclient.FlavorMultidistro,
+		Version: VersionLatest,
+	}
+
+	t.Run(`extracts latest version from file mirror`, func(t *testing.T) {
+		fs, publicKey := setupTestMirror(t)
+		source, err := NewMirrorSource(fs, "file://"+testMirrorDir+"/index.json", publicKey, false)
+		require.NoError(t, err)
+
+		err = source.Extract(props, testDir)
+		require.NoError(t, err)
+
+		info, err := fs.Stat(filepath.Join(testDir, testFilename))
+		require.NoError(t, err)
+		assert.Equal(t, int64(25), info.Size())
+	})
+	t.Run(`extracts from http mirror`, func(t *testing.T) {
+		fs, publicKey := setupTestMirror(t)
+		server := httptest.NewServer(http.FileServer(afero.NewHttpFs(fs).Dir(testMirrorDir)))
+		defer server.Close()
+
+		source, err := NewMirrorSource(fs, server.URL+"/index.json", publicKey, false)
+		require.NoError(t, err)
+
+		versionProps := props
+		versionProps.Version = "1.205.0.20201001-100000"
+		err = source.Extract(versionProps, testDir)
+		require.NoError(t, err)
+
+		exists, err := afero.Exists(fs, filepath.Join(testDir, testFilename))
+		require.NoError(t, err)
+		assert.True(t, exists)
+	})
+	t.Run(`rejects index signed with other key`, func(t *testing.T) {
+		fs, _ := setupTestMirror(t)
+		otherKey, _, err := ed25519.GenerateKey(nil)
+		require.NoError(t, err)
+
+		source, err := NewMirrorSource(fs, "file://"+testMirrorDir+"/index.json", base64.StdEncoding.EncodeToString(otherKey), false)
+		require.NoError(t, err)
+
+		err = source.Extract(props, testDir)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "does not match the public key")
+	})
+	t.Run(`rejects artifact not matching the pinned checksum`, func(t *testing.T) {
+		fs, publicKey := setupTestMirror(t)
+		source, err := NewMirrorSource(fs, "file://"+testMirrorDir+"/index.json", publicKey, false)
+		require.NoError(t, err)
+
+		pinnedProps := props
+		pinnedProps.Sha256 = "1234"
+		err = source.Extract(pinnedProps, testDir)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "checksum mismatch")
+	})
+	t.Run(`unknown version`, func(t *testing.T) {
+		fs, publicKey := setupTestMirror(t)
+		source, err := NewMirrorSource(fs, "file://"+testMirrorDir+"/index.json", publicKey, false)
+		require.NoError(t, err)
+
+		versionProps := props
+		versionProps.Version = "1.0.0.20200101-000000"
+		err = source.Extract(versionProps, testDir)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "does not contain a OneAgent package")
+	})
+	t.Run(`invalid config`, func(t *testing.T) {
+		_, err := NewMirrorSource(afero.NewMemMapFs(), "ftp://mirror/index.json", "", false)
+		assert.Error(t, err)
+
+		_, err = NewMirrorSource(afero.NewMemMapFs(), "https://mirror/index.json", "dGVzdA==", false)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,370 @@
+package installer
+
+import (
+	"archive/tar"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/dtversion"
+	"github.com/Dynatrace/dynatrace-operator/src/dtclient"
+	"github.com/containers/image/v5/docker/reference"
+	"github.com/containers/image/v5/image"
+	"github.com/containers/image/v5/pkg/blobinfocache/none"
+	"github.com/containers/image/v5/pkg/compression"
+	"github.com/containers/image/v5/transports/alltransports"
+	"github.com/containers/image/v5/types"
+	"github.com/spf13/afero"
+)
+
+const (
+	DefaultImagePath = "opt/dynatrace/oneagent"
+
+	whiteoutPrefix = ".wh."
+	whiteoutOpaque = ".wh..wh..opq"
+)
+
+// ociSource pulls the layers of a OneAgent code modules image from a registry and extracts the files below the image path.
+type ociSource struct {
+	fs           afero.Fs
+	image        string
+	imagePath    string
+	dockerConfig *dtversion.DockerConfig
+}
+
+var _ ArtifactSource = &ociSource{}
+
+func NewOciSource(fs afero.Fs, image string, imagePath string, dockerConfig *dtversion.DockerConfig) ArtifactSource {
+	if imagePath == "" {
+		imagePath = DefaultImagePath
+	}
+	return &ociSource{
+		fs:           fs,
+		image:        image,
+		imagePath:    imagePath,
+		dockerConfig: dockerConfig,
+	}
+}
+
+func (source *ociSource) Extract(props InstallerProperties, targetDir string) error {
+	imageName, err := imageForVersion(source.image, props.Version)
+	if err != nil {
+		return err
+	}
+	if props.Sha256 != "" {
+		// the manifest of an image pulled by digest is verified against it, and the layers against the manifest
+		imageName, err = imageWithDigest(imageName, props.Sha256)
+		if err != nil {
+			return err
+		}
+	}
+	log.Info("pulling OneAgent code modules image", "image", imageName, "path", source.imagePath)
+
+	transportImageName := fmt.Sprintf("docker://%s", imageName)
+	imageReference, err := alltransports.ParseImageName(transportImageName)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.TODO()
+	systemContext := dtversion.MakeSystemContext(imageReference.DockerReference(), source.dockerConfig)
+	systemContext.OSChoice = "linux"
+	systemContext.ArchitectureChoice = imageArchitecture(props.Arch)
+
+	imageSource, err := imageReference.NewImageSource(ctx, systemContext)
+	if err != nil {
+		return fmt.Errorf("failed to access image %s: %w", imageName, err)
+	}
+	defer func() { _ = imageSource.Close() }()
+
+	img, err := image.FromUnparsedImage(ctx, systemContext, image.UnparsedInstance(imageSource, nil))
+	if err != nil {
+		return fmt.Errorf("failed to read manifest of image %s: %w", imageName, err)
+	}
+
+	if err := source.fs.MkdirAll(targetDir, 0755); err != nil {
+		return err
+	}
+
+	for _, layer := range img.LayerInfos() {
+		if err := source.extractLayer(ctx, imageSource, layer, targetDir); err != nil {
+			return fmt.Errorf("failed to extract layer %s of image %s: %w", layer.Digest, imageName, err)
+		}
+	}
+	log.Info("extracted OneAgent code modules image", "image", imageName, "layers", len(img.LayerInfos()))
+	return nil
+}
+
+// extractLayer untars a single layer. The digest of the layer is verified after it has been read completely,
+// a mismatch fails the installation which removes the target directory again.
+func (source *ociSource) extractLayer(ctx context.Context, imageSource types.ImageSource, layer types.BlobInfo, targetDir string) error {
+	if err := layer.Digest.Validate(); err != nil {
+		return err
+	}
+
+	blob, _, err := imageSource.GetBlob(ctx, layer, none.NoCache)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = blob.Close() }()
+
+	verifier := layer.Digest.Verifier()
+	reader := io.TeeReader(blob, verifier)
+
+	decompressed, _, err := compression.AutoDecompress(reader)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = decompressed.Close() }()
+
+	if err := untar(source.fs, decompressed, source.imagePath, targetDir); err != nil {
+		return err
+	}
+
+	// the tar end marker may be followed by padding, which is part of the digest as well
+	if _, err := io.Copy(ioutil.Discard, reader); err != nil {
+		return err
+	}
+	if !verifier.Verified() {
+		return fmt.Errorf("digest mismatch")
+	}
+	return nil
+}
+
+// untar extracts the entries of the layer which are located below imagePath into the target directory.
+// Whiteout files remove what previous layers have extracted.
+func untar(fs afero.Fs, reader io.Reader, imagePath string, targetDir string) error {
+	prefix := strings.Trim(path.Clean("/"+imagePath), "/") + "/"
+	tarReader := tar.NewReader(reader)
+
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		// cleaning the absolute path gets rid of any ../ elements
+		name := strings.TrimPrefix(path.Clean("/"+header.Name), "/")
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		relativePath := strings.TrimPrefix(name, prefix)
+		targetPath := filepath.Join(targetDir, filepath.FromSlash(relativePath))
+
+		if symlinked, err := hasSymlinkedParent(fs, targetDir, relativePath); err != nil {
+			return err
+		} else if symlinked {
+			log.Info("skipping tar entry below a symlink", "path", relativePath)
+			continue
+		}
+
+		if base := path.Base(relativePath); strings.HasPrefix(base, whiteoutPrefix) {
+			if err := applyWhiteout(fs, filepath.Dir(targetPath), base); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := extractTarEntry(fs, tarReader, header, prefix, relativePath, targetPath, targetDir); err != nil {
+			return fmt.Errorf("failed to extract %s: %w", header.Name, err)
+		}
+	}
+}
+
+func extractTarEntry(fs afero.Fs, tarReader *tar.Reader, header *tar.Header, prefix, relativePath, targetPath, targetDir string) error {
+	mode := header.FileInfo().Mode().Perm()
+
+	// Mark all files inside ./agent/conf as group-writable, like it is done for the zip
+	if strings.HasPrefix(relativePath+"/", agentConfPath) && relativePath+"/" != agentConfPath {
+		mode |= 020
+	}
+
+	switch header.Typeflag {
+	case tar.TypeDir:
+		return fs.MkdirAll(targetPath, mode|0700)
+	case tar.TypeReg, tar.TypeRegA:
+		return writeFile(fs, targetPath, mode, tarReader)
+	case tar.TypeLink:
+		linkPath := strings.TrimPrefix(path.Clean("/"+header.Linkname), "/")
+		if !strings.HasPrefix(linkPath, prefix) {
+			log.Info("skipping hard link pointing outside of the code modules", "path", relativePath, "target", header.Linkname)
+			return nil
+		}
+		linkRelativePath := strings.TrimPrefix(linkPath, prefix)
+		source, err := fs.Open(filepath.Join(targetDir, filepath.FromSlash(linkRelativePath)))
+		if err != nil {
+			return err
+		}
+		defer func() { _ = source.Close() }()
+		return writeFile(fs, targetPath, mode, source)
+	case tar.TypeSymlink:
+		linker, ok := fs.(afero.Linker)
+		if !ok {
+			log.Info("symlinking not possible", "path", relativePath)
+			return nil
+		}
+		if !isContainedSymlink(relativePath, header.Linkname) {
+			log.Info("skipping symlink pointing outside of the code modules", "path", relativePath, "target", header.Linkname)
+			return nil
+		}
+		if err := fs.MkdirAll(filepath.Dir(targetPath), 0755); err != nil {
+			return err
+		}
+		_ = fs.Remove(targetPath)
+		return linker.SymlinkIfPossible(header.Linkname, targetPath)
+	default:
+		log.Info("skipping unsupported tar entry", "path", relativePath, "type", header.Typeflag)
+		return nil
+	}
+}
+
+func writeFile(fs afero.Fs, targetPath string, mode os.FileMode, reader io.Reader) error {
+	if err := fs.MkdirAll(filepath.Dir(targetPath), 0755); err != nil {
+		return err
+	}
+	// replace symlinks instead of writing to the file they point to
+	if lstater, ok := fs.(afero.Lstater); ok {
+		if info, _, err := lstater.LstatIfPossible(targetPath); err == nil && info.Mode()&os.ModeSymlink != 0 {
+			if err := fs.Remove(targetPath); err != nil {
+				return err
+			}
+		}
+	}
+
+	file, err := fs.OpenFile(targetPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = file.Close() }()
+
+	_, err = io.Copy(file, reader)
+	return err
+}
+
+func applyWhiteout(fs afero.Fs, dir string, whiteout string) error {
+	if whiteout == whiteoutOpaque {
+		entries, err := afero.ReadDir(fs, dir)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		for _, entry := range entries {
+			if err := fs.RemoveAll(filepath.Join(dir, entry.Name())); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return fs.RemoveAll(filepath.Join(dir, strings.TrimPrefix(whiteout, whiteoutPrefix)))
+}
+
+// hasSymlinkedParent returns true if a directory between the target directory and the entry is a symlink.
+// Nothing is written through symlinks, otherwise chained symlinks could place files outside of the target directory.
+func hasSymlinkedParent(fs afero.Fs, targetDir string, relativePath string) (bool, error) {
+	lstater, ok := fs.(afero.Lstater)
+	if !ok {
+		return false, nil
+	}
+
+	dir := targetDir
+	for _, element := range strings.Split(path.Dir(relativePath), "/") {
+		if element == "." {
+			continue
+		}
+		dir = filepath.Join(dir, element)
+		info, _, err := lstater.LstatIfPossible(dir)
+		if os.IsNotExist(err) {
+			return false, nil
+		} else if err != nil {
+			return false, err
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// isContainedSymlink only accepts relative symlinks which step up at the start, and not above the target directory.
+// As the directories of the symlink are never symlinks themselves, the symlink resolves to a location within the target
+// directory, even if it is chained with other symlinks. Stepping up after another element isn't resolved lexically,
+// for example in a/b/.. where a/b is a symlink, so it's rejected.
+func isContainedSymlink(relativePath string, linkname string) bool {
+	if linkname == "" || path.IsAbs(linkname) {
+		return false
+	}
+
+	depth := 0
+	if dir := path.Dir(relativePath); dir != "." {
+		depth = len(strings.Split(dir, "/"))
+	}
+
+	descended := false
+	for _, element := range strings.Split(linkname, "/") {
+		switch element {
+		case "", ".":
+		case "..":
+			if descended || depth == 0 {
+				return false
+			}
+			depth--
+		default:
+			descended = true
+		}
+	}
+	return true
+}
+
+// imageForVersion adds the version as tag, if the image is neither tagged nor pinned by digest.
+func imageForVersion(imageName string, version string) (string, error) {
+	named, err := reference.ParseNormalizedNamed(imageName)
+	if err != nil {
+		return "", fmt.Errorf("invalid code modules image %s: %w", imageName, err)
+	}
+	if !reference.IsNameOnly(named) {
+		return named.String(), nil
+	}
+
+	tag := version
+	if tag == "" {
+		tag = VersionLatest
+	}
+	tagged, err := reference.WithTag(named, tag)
+	if err != nil {
+		return "", fmt.Errorf("invalid code modules image tag %s: %w", tag, err)
+	}
+	return tagged.String(), nil
+}
+
+// imageWithDigest pins the image to the hex encoded SHA-256 digest, its tag is dropped.
+// An image which is already pinned to another digest can't match the checksum.
+func imageWithDigest(imageName string, sha256 string) (string, error) {
+	named, err := reference.ParseNormalizedNamed(imageName)
+	if err != nil {
+		return "", fmt.Errorf("invalid code modules image %s: %w", imageName, err)
+	}
+	digest := "sha256:" + strings.ToLower(strings.TrimSpace(sha256))
+	if canonical, ok := named.(reference.Canonical); ok && canonical.Digest().String() != digest {
+		return "", fmt.Errorf("checksum mismatch, expected %s but the image is pinned to %s", digest, canonical.Digest())
+	}
+	pinned, err := reference.ParseNormalizedNamed(reference.TrimNamed(named).String() + "@" + digest)
+	if err != nil {
+		return "", fmt.Errorf("invalid code modules checksum %s: %w", sha256, err)
+	}
+	return pinned.String(), nil
+}
+
+func imageArchitecture(arch string) string {
+	switch arch {
+	case dtclient.ArchARM:
+		return "arm64"
+	default:
+		return "amd64"
+	}
+}
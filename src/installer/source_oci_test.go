@@ -0,0 +1,205 @@
+package installer
+
+import (
+	"archive/tar"
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Dynatrace/dynatrace-operator/src/dtclient"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type tarEntry struct {
+	name     string
+	content  string
+	typeflag byte
+	linkname string
+}
+
+func createTestLayer(t *testing.T, entries ...tarEntry) *bytes.Buffer {
+	var buffer bytes.Buffer
+	writer := tar.NewWriter(&buffer)
+	for _, entry := range entries {
+		header := &tar.Header{
+			Name:     entry.name,
+			Typeflag: entry.typeflag,
+			Linkname: entry.linkname,
+			Mode:     0644,
+			Size:     int64(len(entry.content)),
+		}
+		if entry.typeflag == tar.TypeDir {
+			header.Mode = 0755
+		}
+		require.NoError(t, writer.WriteHeader(header))
+		_, err := writer.Write([]byte(entry.content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, writer.Close())
+	return &buffer
+}
+
+func TestUntar(t *testing.T) {
+	t.Run(`extracts files below image path`, func(t *testing.T) {
+		fs := afero.NewMemMapFs()
+		layer := createTestLayer(t,
+			tarEntry{name: "etc/passwd", content: "root", typeflag: tar.TypeReg},
+			tarEntry{name: "opt/dynatrace/oneagent/", typeflag: tar.TypeDir},
+			tarEntry{name: "opt/dynatrace/oneagent/agent/conf/", typeflag: tar.TypeDir},
+			tarEntry{name: "opt/dynatrace/oneagent/agent/conf/test.txt", content: "you found the easter egg", typeflag: tar.TypeReg},
+			tarEntry{name: "opt/dynatrace/oneagent/test.txt", content: "test", typeflag: tar.TypeReg},
+			tarEntry{name: "opt/dynatrace/oneagent/hardlink.txt", typeflag: tar.TypeLink, linkname: "opt/dynatrace/oneagent/test.txt"},
+		)
+
+		err := untar(fs, layer, DefaultImagePath, testDir)
+		require.NoError(t, err)
+
+		exists, err := afero.Exists(fs, filepath.Join(testDir, "etc"))
+		require.NoError(t, err)
+		assert.False(t, exists)
+
+		content, err := afero.ReadFile(fs, filepath.Join(testDir, testFilename))
+		require.NoError(t, err)
+		assert.Equal(t, "test", string(content))
+
+		content, err = afero.ReadFile(fs, filepath.Join(testDir, "hardlink.txt"))
+		require.NoError(t, err)
+		assert.Equal(t, "test", string(content))
+
+		info, err := fs.Stat(filepath.Join(testDir, agentConfPath, testFilename))
+		require.NoError(t, err)
+		assert.NotEqual(t, 0, int(info.Mode().Perm()&020))
+	})
+	t.Run(`path traversal is contained`, func(t *testing.T) {
+		fs := afero.NewMemMapFs()
+		layer := createTestLayer(t,
+			tarEntry{name: "opt/dynatrace/oneagent/../../../../escape.txt", content: "escape", typeflag: tar.TypeReg},
+		)
+
+		err := untar(fs, layer, DefaultImagePath, testDir)
+		require.NoError(t, err)
+
+		exists, err := afero.Exists(fs, "escape.txt")
+		require.NoError(t, err)
+		assert.False(t, exists)
+	})
+	t.Run(`symlinks are contained`, func(t *testing.T) {
+		rootDir := t.TempDir()
+		targetDir := filepath.Join(rootDir, "target")
+		fs := afero.NewOsFs()
+		layer := createTestLayer(t,
+			tarEntry{name: "opt/dynatrace/oneagent/agent/lib64/liboneagentproc.so", content: "test", typeflag: tar.TypeReg},
+			tarEntry{name: "opt/dynatrace/oneagent/agent/lib64/liboneagentproc.so.1", typeflag: tar.TypeSymlink, linkname: "liboneagentproc.so"},
+			tarEntry{name: "opt/dynatrace/oneagent/lib64", typeflag: tar.TypeSymlink, linkname: "agent/lib64"},
+			// chained symlinks which resolve to the parent of the target directory
+			tarEntry{name: "opt/dynatrace/oneagent/a/b", typeflag: tar.TypeSymlink, linkname: "."},
+			tarEntry{name: "opt/dynatrace/oneagent/a/b/c", typeflag: tar.TypeSymlink, linkname: "../.."},
+			tarEntry{name: "opt/dynatrace/oneagent/a/b/c/escape.txt", content: "escape", typeflag: tar.TypeReg},
+			tarEntry{name: "opt/dynatrace/oneagent/d", typeflag: tar.TypeSymlink, linkname: "a/b/../.."},
+			tarEntry{name: "opt/dynatrace/oneagent/e", typeflag: tar.TypeSymlink, linkname: "../escape"},
+			tarEntry{name: "opt/dynatrace/oneagent/agent/up", typeflag: tar.TypeSymlink, linkname: ".."},
+			tarEntry{name: "opt/dynatrace/oneagent/agent/up/up.txt", content: "escape", typeflag: tar.TypeReg},
+		)
+
+		err := untar(fs, layer, DefaultImagePath, targetDir)
+		require.NoError(t, err)
+
+		content, err := afero.ReadFile(fs, filepath.Join(targetDir, "lib64", "liboneagentproc.so.1"))
+		require.NoError(t, err)
+		assert.Equal(t, "test", string(content))
+
+		for _, name := range []string{"a/b/c", "d", "e", "up.txt"} {
+			_, err := os.Lstat(filepath.Join(targetDir, filepath.FromSlash(name)))
+			assert.True(t, os.IsNotExist(err), name)
+		}
+		exists, err := afero.Exists(fs, filepath.Join(rootDir, "escape.txt"))
+		require.NoError(t, err)
+		assert.False(t, exists)
+	})
+	t.Run(`whiteouts remove files of previous layers`, func(t *testing.T) {
+		fs := afero.NewMemMapFs()
+		require.NoError(t, untar(fs, createTestLayer(t,
+			tarEntry{name: "opt/dynatrace/oneagent/test.txt", content: "test", typeflag: tar.TypeReg},
+			tarEntry{name: "opt/dynatrace/oneagent/test/test.txt", content: "test", typeflag: tar.TypeReg},
+		), DefaultImagePath, testDir))
+
+		require.NoError(t, untar(fs, createTestLayer(t,
+			tarEntry{name: "opt/dynatrace/oneagent/.wh.test.txt", typeflag: tar.TypeReg},
+			tarEntry{name: "opt/dynatrace/oneagent/test/.wh..wh..opq", typeflag: tar.TypeReg},
+		), DefaultImagePath, testDir))
+
+		exists, err := afero.Exists(fs, filepath.Join(testDir, testFilename))
+		require.NoError(t, err)
+		assert.False(t, exists)
+
+		exists, err = afero.Exists(fs, filepath.Join(testDir, testDir, testFilename))
+		require.NoError(t, err)
+		assert.False(t, exists)
+	})
+}
+
+func TestIsContainedSymlink(t *testing.T) {
+	assert.True(t, isContainedSymlink("agent/lib64/liboneagentproc.so.1", "liboneagentproc.so"))
+	assert.True(t, isContainedSymlink("agent/bin/oneagentutil", "../lib64/oneagentutil"))
+	assert.True(t, isContainedSymlink("agent/lib64", "."))
+	assert.False(t, isContainedSymlink("agent/lib64", "../.."))
+	assert.False(t, isContainedSymlink("agent/lib64", "/etc/passwd"))
+	assert.False(t, isContainedSymlink("agent/lib64", "a/b/../.."))
+	assert.False(t, isContainedSymlink("lib64", ".."))
+	assert.False(t, isContainedSymlink("lib64", ""))
+}
+
+func TestImageForVersion(t *testing.T) {
+	t.Run(`adds version as tag`, func(t *testing.T) {
+		image, err := imageForVersion("registry.local/dynatrace/codemodules", "1.2.3")
+		require.NoError(t, err)
+		assert.Equal(t, "registry.local/dynatrace/codemodules:1.2.3", image)
+	})
+	t.Run(`uses latest tag`, func(t *testing.T) {
+		image, err := imageForVersion("registry.local/dynatrace/codemodules", VersionLatest)
+		require.NoError(t, err)
+		assert.Equal(t, "registry.local/dynatrace/codemodules:latest", image)
+	})
+	t.Run(`keeps tag of image`, func(t *testing.T) {
+		image, err := imageForVersion("registry.local:5000/codemodules:pinned", "1.2.3")
+		require.NoError(t, err)
+		assert.Equal(t, "registry.local:5000/codemodules:pinned", image)
+	})
+	t.Run(`invalid image`, func(t *testing.T) {
+		_, err := imageForVersion("Invalid Image", "1.2.3")
+		assert.Error(t, err)
+	})
+}
+
+func TestImageWithDigest(t *testing.T) {
+	const testDigest = "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+
+	t.Run(`replaces tag with digest`, func(t *testing.T) {
+		image, err := imageWithDigest("registry.local/dynatrace/codemodules:1.2.3", testDigest)
+		require.NoError(t, err)
+		assert.Equal(t, "registry.local/dynatrace/codemodules@sha256:"+testDigest, image)
+	})
+	t.Run(`keeps matching digest`, func(t *testing.T) {
+		image, err := imageWithDigest("registry.local/dynatrace/codemodules@sha256:"+testDigest, strings.ToUpper(testDigest))
+		require.NoError(t, err)
+		assert.Equal(t, "registry.local/dynatrace/codemodules@sha256:"+testDigest, image)
+	})
+	t.Run(`image pinned to other digest`, func(t *testing.T) {
+		_, err := imageWithDigest("registry.local/dynatrace/codemodules@sha256:"+strings.Repeat("0", 64), testDigest)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "checksum mismatch")
+	})
+	t.Run(`invalid checksum`, func(t *testing.T) {
+		_, err := imageWithDigest("registry.local/dynatrace/codemodules:1.2.3", "abc")
+		assert.Error(t, err)
+	})
+}
+
+func TestImageArchitecture(t *testing.T) {
+	assert.Equal(t, "amd64", imageArchitecture(dtclient.ArchX86))
+	assert.Equal(t, "arm64", imageArchitecture(dtclient.ArchARM))
+}
@@ -0,0 +1,79 @@
+package installer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"github.com/klauspost/compress/zip"
+	"github.com/spf13/afero"
+)
+
+// verifyChecksum compares the SHA-256 checksum of the downloaded file with the expected one, if set.
+// Without a pinned checksum the package can only be checked for archive integrity, which is logged.
+// Sources without another way to verify the package, like the Dynatrace API, refuse to install it without a checksum, unless allowed.
+func verifyChecksum(file afero.File, expectedSha256 string) error {
+	expectedChecksum := strings.ToLower(strings.TrimSpace(expectedSha256))
+	if expectedChecksum == "" {
+		log.Info("no checksum pinned, OneAgent package is only checked for archive integrity")
+		return nil
+	}
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to rewind downloaded file: %w", err)
+	}
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return fmt.Errorf("failed to calculate checksum of downloaded file: %w", err)
+	}
+
+	if checksum := hex.EncodeToString(hash.Sum(nil)); checksum != expectedChecksum {
+		return fmt.Errorf("checksum mismatch, expected sha256 %s but got %s", expectedChecksum, checksum)
+	}
+	log.Info("verified checksum of OneAgent package", "sha256", expectedChecksum)
+	return nil
+}
+
+// verifyArchive reads every file of the ZIP archive, so a corrupt download is detected before anything is extracted to the target directory.
+func verifyArchive(file afero.File) error {
+	if file == nil {
+		return fmt.Errorf("file is nil")
+	}
+
+	fileInfo, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("unable to determine file info: %w", err)
+	}
+
+	reader, err := zip.NewReader(file, fileInfo.Size())
+	if err != nil {
+		return fmt.Errorf("failed to open ZIP file: %w", err)
+	}
+
+	for _, zipFile := range reader.File {
+		if err := verifyArchiveEntry(zipFile); err != nil {
+			return fmt.Errorf("corrupt ZIP file entry %s: %w", zipFile.Name, err)
+		}
+	}
+	return nil
+}
+
+// verifyArchiveEntry reads the entry to the end, which makes the zip reader check its CRC-32 checksum.
+func verifyArchiveEntry(zipFile *zip.File) error {
+	if zipFile.FileInfo().IsDir() {
+		return nil
+	}
+
+	reader, err := zipFile.Open()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = reader.Close() }()
+
+	_, err = io.Copy(ioutil.Discard, reader)
+	return err
+}
@@ -0,0 +1,119 @@
+package installer
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"io"
+	"testing"
+
+	"github.com/Dynatrace/dynatrace-operator/src/dtclient"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyChecksum(t *testing.T) {
+	zipContent, err := base64.StdEncoding.DecodeString(testZip)
+	require.NoError(t, err)
+	checksum := sha256.Sum256(zipContent)
+
+	t.Run(`no pinned checksum`, func(t *testing.T) {
+		fs := afero.NewMemMapFs()
+		zipFile := setupTestZip(t, fs)
+		defer func() { _ = zipFile.Close() }()
+
+		assert.NoError(t, verifyChecksum(zipFile, ""))
+	})
+	t.Run(`matching checksum`, func(t *testing.T) {
+		fs := afero.NewMemMapFs()
+		zipFile := setupTestZip(t, fs)
+		defer func() { _ = zipFile.Close() }()
+
+		assert.NoError(t, verifyChecksum(zipFile, hex.EncodeToString(checksum[:])))
+	})
+	t.Run(`checksum mismatch`, func(t *testing.T) {
+		fs := afero.NewMemMapFs()
+		zipFile := setupTestZip(t, fs)
+		defer func() { _ = zipFile.Close() }()
+
+		err := verifyChecksum(zipFile, "0000")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "checksum mismatch")
+	})
+}
+
+func TestVerifyArchive(t *testing.T) {
+	t.Run(`file nil`, func(t *testing.T) {
+		require.EqualError(t, verifyArchive(nil), "file is nil")
+	})
+	t.Run(`valid archive`, func(t *testing.T) {
+		fs := afero.NewMemMapFs()
+		zipFile := setupTestZip(t, fs)
+		defer func() { _ = zipFile.Close() }()
+
+		assert.NoError(t, verifyArchive(zipFile))
+	})
+	t.Run(`corrupt archive entry`, func(t *testing.T) {
+		fs := afero.NewMemMapFs()
+		zipFile := setupCorruptTestZip(t, fs)
+		defer func() { _ = zipFile.Close() }()
+
+		err := verifyArchive(zipFile)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "corrupt ZIP file entry")
+	})
+	t.Run(`corrupt archive is not extracted`, func(t *testing.T) {
+		fs := afero.NewMemMapFs()
+		dtc := &dtclient.MockDynatraceClient{}
+		dtc.
+			On("GetAgentViaInstallerUrl", testUrl, mock.AnythingOfType("*mem.File")).
+			Run(func(args mock.Arguments) {
+				writer := args.Get(1).(io.Writer)
+
+				zipFile := setupCorruptTestZip(t, fs)
+				defer func() { _ = zipFile.Close() }()
+
+				_, err := io.Copy(writer, zipFile)
+				require.NoError(t, err)
+			}).
+			Return(nil)
+		installer := &OneAgentInstaller{
+			fs:  fs,
+			dtc: dtc,
+			props: InstallerProperties{
+				Url:             testUrl,
+				AllowUnverified: true,
+			},
+		}
+
+		err := installer.installAgent(testDir)
+		require.Error(t, err)
+
+		exists, err := afero.DirExists(fs, testDir)
+		require.NoError(t, err)
+		assert.False(t, exists)
+	})
+}
+
+// setupCorruptTestZip returns the test zip with the content of its entries altered, so their CRC-32 checksums no longer match.
+func setupCorruptTestZip(t *testing.T, fs afero.Fs) afero.File {
+	zipContent, err := base64.StdEncoding.DecodeString(testZip)
+	require.NoError(t, err)
+
+	corruptContent := bytes.ReplaceAll(zipContent, []byte("easter egg"), []byte("rotten egg"))
+	require.NotEqual(t, zipContent, corruptContent)
+
+	zipFile, err := afero.TempFile(fs, "", "")
+	require.NoError(t, err)
+
+	_, err = zipFile.Write(corruptContent)
+	require.NoError(t, err)
+
+	_, err = zipFile.Seek(0, io.SeekStart)
+	require.NoError(t, err)
+
+	return zipFile
+}
@@ -0,0 +1,114 @@
+package installer
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/klauspost/compress/zip"
+	"github.com/spf13/afero"
+)
+
+// extractZip downloads the OneAgent package into a temporary file, verifies it and unzips it into the target directory.
+func extractZip(fs afero.Fs, targetDir string, expectedSha256 string, download func(file afero.File) error) error {
+	tmpFile, err := afero.TempFile(fs, "", "download")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file for download: %w", err)
+	}
+	defer func() {
+		_ = tmpFile.Close()
+		if err := fs.Remove(tmpFile.Name()); err != nil {
+			log.Error(err, "failed to delete downloaded file", "path", tmpFile.Name())
+		}
+	}()
+
+	if err := download(tmpFile); err != nil {
+		return err
+	}
+
+	var fileSize int64
+	if stat, err := tmpFile.Stat(); err == nil {
+		fileSize = stat.Size()
+	}
+
+	log.Info("saved OneAgent package", "dest", tmpFile.Name(), "size", fileSize)
+	if err := verifyChecksum(tmpFile, expectedSha256); err != nil {
+		return err
+	}
+	if err := verifyArchive(tmpFile); err != nil {
+		return fmt.Errorf("downloaded OneAgent package is corrupt: %w", err)
+	}
+
+	log.Info("unzipping OneAgent package")
+	if err := unzip(fs, tmpFile, targetDir); err != nil {
+		return fmt.Errorf("failed to unzip file: %w", err)
+	}
+	log.Info("unzipped OneAgent package")
+	return nil
+}
+
+func unzip(fs afero.Fs, file afero.File, targetDir string) error {
+	if file == nil {
+		return fmt.Errorf("file is nil")
+	}
+
+	fileInfo, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("unable to determine file info: %w", err)
+	}
+
+	reader, err := zip.NewReader(file, fileInfo.Size())
+	if err != nil {
+		return fmt.Errorf("failed to open ZIP file: %w", err)
+	}
+
+	_ = fs.MkdirAll(targetDir, 0755)
+
+	for _, file := range reader.File {
+		err := func() error {
+			path := filepath.Join(targetDir, file.Name)
+
+			// Check for ZipSlip: https://snyk.io/research/zip-slip-vulnerability
+			if !strings.HasPrefix(path, filepath.Clean(targetDir)+string(os.PathSeparator)) {
+				return fmt.Errorf("illegal file path: %s", path)
+			}
+
+			mode := file.Mode()
+
+			// Mark all files inside ./agent/conf as group-writable
+			if file.Name != agentConfPath && strings.HasPrefix(file.Name, agentConfPath) {
+				mode |= 020
+			}
+
+			if file.FileInfo().IsDir() {
+				return fs.MkdirAll(path, mode)
+			}
+
+			if err := fs.MkdirAll(filepath.Dir(path), mode); err != nil {
+				return err
+			}
+
+			dstFile, err := fs.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode)
+			if err != nil {
+				return err
+			}
+			defer func() { _ = dstFile.Close() }()
+
+			srcFile, err := file.Open()
+			if err != nil {
+				return err
+			}
+			defer func() { _ = srcFile.Close() }()
+
+			_, err = io.Copy(dstFile, srcFile)
+			return err
+		}()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,77 @@
+package kubeobjects
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// FindRootOwnerOfPod follows the controller references of well known workloads and returns the name and kind
+// of the top most owner. For a pod without owner, the pod name and an empty kind are returned.
+// The namespace has to be passed separately, as it is not set yet for pods in admission requests.
+func FindRootOwnerOfPod(ctx context.Context, clt client.Reader, pod *corev1.Pod, namespace string) (string, string, error) {
+	obj := &metav1.PartialObjectMetadata{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: pod.APIVersion,
+			Kind:       pod.Kind,
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            pod.ObjectMeta.Name,
+			Namespace:       namespace,
+			OwnerReferences: pod.ObjectMeta.OwnerReferences,
+		},
+	}
+	return findRootOwner(ctx, clt, obj)
+}
+
+func findRootOwner(ctx context.Context, clt client.Reader, o *metav1.PartialObjectMetadata) (string, string, error) {
+	if len(o.ObjectMeta.OwnerReferences) == 0 {
+		kind := o.Kind
+		if kind == "Pod" {
+			kind = ""
+		}
+		return o.ObjectMeta.Name, kind, nil
+	}
+
+	om := o.ObjectMeta
+	for _, owner := range om.OwnerReferences {
+		if owner.Controller != nil && *owner.Controller && isWellKnownWorkload(owner) {
+			obj := &metav1.PartialObjectMetadata{
+				TypeMeta: metav1.TypeMeta{
+					APIVersion: owner.APIVersion,
+					Kind:       owner.Kind,
+				},
+			}
+			if err := clt.Get(ctx, client.ObjectKey{Name: owner.Name, Namespace: om.Namespace}, obj); err != nil {
+				return o.ObjectMeta.Name, o.Kind, errors.WithMessagef(err, "failed to query %s %s %s in namespace %s", owner.APIVersion, owner.Kind, owner.Name, om.Namespace)
+			}
+
+			return findRootOwner(ctx, clt, obj)
+		}
+	}
+	return o.ObjectMeta.Name, o.Kind, nil
+}
+
+func isWellKnownWorkload(ownerRef metav1.OwnerReference) bool {
+	knownWorkloads := []metav1.TypeMeta{
+		{Kind: "ReplicaSet", APIVersion: "apps/v1"},
+		{Kind: "Deployment", APIVersion: "apps/v1"},
+		{Kind: "ReplicationController", APIVersion: "v1"},
+		{Kind: "StatefulSet", APIVersion: "apps/v1"},
+		{Kind: "DaemonSet", APIVersion: "apps/v1"},
+		{Kind: "Job", APIVersion: "batch/v1"},
+		{Kind: "CronJob", APIVersion: "batch/v1"},
+		{Kind: "DeploymentConfig", APIVersion: "apps.openshift.io/v1"},
+	}
+
+	for _, knownController := range knownWorkloads {
+		if ownerRef.Kind == knownController.Kind &&
+			ownerRef.APIVersion == knownController.APIVersion {
+			return true
+		}
+	}
+	return false
+}
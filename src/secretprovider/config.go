@@ -0,0 +1,32 @@
+package secretprovider
+
+import (
+	"time"
+
+	"github.com/Dynatrace/dynatrace-operator/src/logger"
+)
+
+const (
+	// DefaultRefreshInterval is used if the secret provider of a DynaKube sets no refresh interval
+	DefaultRefreshInterval = 5 * time.Minute
+
+	defaultVaultAuthPath = "kubernetes"
+	defaultVaultMount    = "secret"
+	vaultTrustedCAKey    = "certs"
+	envFileExtension     = ".env"
+
+	// vaultTokenAudience is the audience of the service account tokens used to log in to Vault,
+	// which has to be configured as audience of the role of the Kubernetes auth method
+	vaultTokenAudience   = "vault"
+	vaultTokenExpiration = 10 * time.Minute
+)
+
+var (
+	log = logger.NewDTLogger().WithName("secret-provider")
+
+	// serviceAccountTokenPath is the token of the pod's service account, which identifies the service account
+	// the tokens to log in to Vault are requested for
+	serviceAccountTokenPath = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+
+	now = time.Now
+)
@@ -0,0 +1,94 @@
+package secretprovider
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	"github.com/pkg/errors"
+)
+
+// fileProvider reads secrets from files, either mounted by the secrets store CSI driver with a file per field,
+// or as environment files with a line per field.
+type fileProvider struct {
+	spec *dynatracev1beta1.FileSecretProviderSpec
+}
+
+func newFileProvider(spec *dynatracev1beta1.FileSecretProviderSpec) *fileProvider {
+	return &fileProvider{spec: spec}
+}
+
+func (p *fileProvider) read(_ context.Context, name string) (map[string][]byte, error) {
+	if p.spec.Format == dynatracev1beta1.FileSecretFormatEnv {
+		return p.readEnvFile(filepath.Join(p.spec.Path, name+envFileExtension))
+	}
+	return p.readDirectory(filepath.Join(p.spec.Path, name))
+}
+
+// readDirectory reads every file of the directory as a field. Entries starting with .. are skipped, as the CSI driver
+// keeps the current files in such a directory the fields link to. Fields like .dockerconfigjson start with a single dot.
+func (p *fileProvider) readDirectory(dir string) (map[string][]byte, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, errSecretNotFound
+	} else if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	data := map[string][]byte{}
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), "..") {
+			continue
+		}
+		file := filepath.Join(dir, entry.Name())
+		info, err := os.Stat(file)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		if info.IsDir() {
+			continue
+		}
+		value, err := os.ReadFile(file)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		data[entry.Name()] = bytes.TrimSpace(value)
+	}
+	return data, nil
+}
+
+// readEnvFile reads the <field>=<value> lines of the file, empty lines and comments starting with # are skipped
+func (p *fileProvider) readEnvFile(file string) (map[string][]byte, error) {
+	content, err := os.ReadFile(file)
+	if os.IsNotExist(err) {
+		return nil, errSecretNotFound
+	} else if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	data := map[string][]byte{}
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		pair := strings.SplitN(line, "=", 2)
+		if len(pair) != 2 {
+			return nil, errors.Errorf("line %d of %s is no <field>=<value> pair", lineNumber, file)
+		}
+		data[strings.TrimSpace(pair[0])] = []byte(unquote(strings.TrimSpace(pair[1])))
+	}
+	return data, errors.WithStack(scanner.Err())
+}
+
+func unquote(value string) string {
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		return value[1 : len(value)-1]
+	}
+	return value
+}
@@ -0,0 +1,137 @@
+package secretprovider
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// errSecretNotFound is returned by providers if the secret doesn't exist in the external store
+var errSecretNotFound = errors.New("secret not found")
+
+// provider reads the fields of a secret from an external store.
+type provider interface {
+	read(ctx context.Context, name string) (map[string][]byte, error)
+}
+
+// Reader reads the secrets referenced by a DynaKube, which are tokens, the proxy and the custom pull secret.
+// Without a secret provider they are read from Kubernetes, otherwise from the external store configured by the provider.
+type Reader struct {
+	apiReader client.Reader
+	namespace string
+	spec      *dynatracev1beta1.SecretProviderSpec
+}
+
+func NewReader(apiReader client.Reader, namespace string, spec *dynatracev1beta1.SecretProviderSpec) *Reader {
+	return &Reader{
+		apiReader: apiReader,
+		namespace: namespace,
+		spec:      spec,
+	}
+}
+
+// NewReaderForDynakube creates a Reader for the secrets referenced by the given DynaKube.
+func NewReaderForDynakube(apiReader client.Reader, dynakube *dynatracev1beta1.DynaKube) *Reader {
+	return NewReader(apiReader, dynakube.Namespace, dynakube.Spec.SecretProvider)
+}
+
+// Get returns the secret with the given name. Like reading a Kubernetes secret, a missing secret is reported with a NotFound error.
+// Secrets from an external store are cached for the refresh interval of the secret provider.
+func (r *Reader) Get(ctx context.Context, name string) (*corev1.Secret, error) {
+	if r.spec == nil {
+		var secret corev1.Secret
+		if err := r.apiReader.Get(ctx, client.ObjectKey{Name: name, Namespace: r.namespace}, &secret); err != nil {
+			return nil, err
+		}
+		return &secret, nil
+	}
+
+	data, err := cache.get(ctx, r, name)
+	if errors.Is(err, errSecretNotFound) {
+		return nil, k8serrors.NewNotFound(corev1.Resource("secrets"), name)
+	} else if err != nil {
+		return nil, errors.WithMessagef(err, "failed to read secret %s from secret provider", name)
+	}
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: r.namespace},
+		Data:       data,
+	}, nil
+}
+
+func (r *Reader) provider() (provider, error) {
+	switch {
+	case r.spec.Vault != nil:
+		return newVaultProvider(r.apiReader, r.namespace, r.spec.Vault), nil
+	case r.spec.File != nil:
+		return newFileProvider(r.spec.File), nil
+	}
+	return nil, errors.New("secret provider configures neither vault nor file")
+}
+
+// RefreshInterval returns how long secrets read with the given secret provider are cached.
+func RefreshInterval(spec *dynatracev1beta1.SecretProviderSpec) time.Duration {
+	if spec == nil || spec.RefreshInterval == nil || spec.RefreshInterval.Duration <= 0 {
+		return DefaultRefreshInterval
+	}
+	return spec.RefreshInterval.Duration
+}
+
+// cache is shared by all readers, so the external store is queried once per refresh interval,
+// no matter how many reconcilers or webhook requests read a secret
+var cache = &secretCache{entries: map[string]cacheEntry{}}
+
+type cacheEntry struct {
+	data    map[string][]byte
+	expires time.Time
+}
+
+type secretCache struct {
+	mutex   sync.Mutex
+	entries map[string]cacheEntry
+}
+
+func (c *secretCache) get(ctx context.Context, r *Reader, name string) (map[string][]byte, error) {
+	key, err := cacheKey(r, name)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mutex.Lock()
+	entry, ok := c.entries[key]
+	c.mutex.Unlock()
+	if ok && now().Before(entry.expires) {
+		return entry.data, nil
+	}
+
+	p, err := r.provider()
+	if err != nil {
+		return nil, err
+	}
+	data, err := p.read(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	log.Info("read secret from secret provider", "namespace", r.namespace, "secret", name)
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.entries[key] = cacheEntry{data: data, expires: now().Add(RefreshInterval(r.spec))}
+	return data, nil
+}
+
+// cacheKey identifies a secret by the settings of the provider, so changing the provider of a DynaKube reads the secrets again
+func cacheKey(r *Reader, name string) (string, error) {
+	spec, err := json.Marshal(r.spec)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	return r.namespace + "/" + name + "/" + string(spec), nil
+}
@@ -0,0 +1,136 @@
+package secretprovider
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	"github.com/Dynatrace/dynatrace-operator/src/dtclient"
+	"github.com/Dynatrace/dynatrace-operator/src/scheme/fake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	testNamespace = "dynatrace"
+	testName      = "dynakube"
+	testApiToken  = "api-token"
+)
+
+func resetCaches(t *testing.T) {
+	cache = &secretCache{entries: map[string]cacheEntry{}}
+	vaultTokens = &vaultTokenCache{tokens: map[string]vaultToken{}}
+	t.Cleanup(func() {
+		now = time.Now
+	})
+}
+
+func TestReader_Get(t *testing.T) {
+	t.Run(`without secret provider the kubernetes secret is read`, func(t *testing.T) {
+		clt := fake.NewClient(&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: testName, Namespace: testNamespace},
+			Data:       map[string][]byte{dtclient.DynatraceApiToken: []byte(testApiToken)},
+		})
+
+		secret, err := NewReader(clt, testNamespace, nil).Get(context.TODO(), testName)
+
+		require.NoError(t, err)
+		assert.Equal(t, testApiToken, string(secret.Data[dtclient.DynatraceApiToken]))
+	})
+	t.Run(`file per field`, func(t *testing.T) {
+		resetCaches(t)
+		dir := t.TempDir()
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, testName, "..data"), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, testName, dtclient.DynatraceApiToken), []byte(testApiToken+"\n"), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, testName, dtclient.DynatracePaasToken), []byte("paas-token"), 0644))
+
+		secret, err := NewReader(fake.NewClient(), testNamespace, &dynatracev1beta1.SecretProviderSpec{
+			File: &dynatracev1beta1.FileSecretProviderSpec{Path: dir},
+		}).Get(context.TODO(), testName)
+
+		require.NoError(t, err)
+		assert.Equal(t, testName, secret.Name)
+		assert.Equal(t, testNamespace, secret.Namespace)
+		assert.Equal(t, map[string][]byte{
+			dtclient.DynatraceApiToken:  []byte(testApiToken),
+			dtclient.DynatracePaasToken: []byte("paas-token"),
+		}, secret.Data)
+	})
+	t.Run(`environment file`, func(t *testing.T) {
+		resetCaches(t)
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, testName+envFileExtension), []byte(`# tokens of the dynakube
+apiToken=api-token
+
+paasToken = "paas-token"
+`), 0644))
+
+		secret, err := NewReader(fake.NewClient(), testNamespace, &dynatracev1beta1.SecretProviderSpec{
+			File: &dynatracev1beta1.FileSecretProviderSpec{Path: dir, Format: dynatracev1beta1.FileSecretFormatEnv},
+		}).Get(context.TODO(), testName)
+
+		require.NoError(t, err)
+		assert.Equal(t, map[string][]byte{
+			dtclient.DynatraceApiToken:  []byte(testApiToken),
+			dtclient.DynatracePaasToken: []byte("paas-token"),
+		}, secret.Data)
+	})
+	t.Run(`missing secret is not found`, func(t *testing.T) {
+		resetCaches(t)
+
+		_, err := NewReader(fake.NewClient(), testNamespace, &dynatracev1beta1.SecretProviderSpec{
+			File: &dynatracev1beta1.FileSecretProviderSpec{Path: t.TempDir()},
+		}).Get(context.TODO(), testName)
+
+		assert.True(t, k8serrors.IsNotFound(err))
+	})
+	t.Run(`secrets are cached for the refresh interval`, func(t *testing.T) {
+		resetCaches(t)
+		dir := t.TempDir()
+		file := filepath.Join(dir, testName+envFileExtension)
+		require.NoError(t, os.WriteFile(file, []byte("apiToken=api-token"), 0644))
+		reader := NewReader(fake.NewClient(), testNamespace, &dynatracev1beta1.SecretProviderSpec{
+			File:            &dynatracev1beta1.FileSecretProviderSpec{Path: dir, Format: dynatracev1beta1.FileSecretFormatEnv},
+			RefreshInterval: &metav1.Duration{Duration: time.Minute},
+		})
+		start := time.Now()
+		now = func() time.Time { return start }
+
+		_, err := reader.Get(context.TODO(), testName)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(file, []byte("apiToken=rotated-api-token"), 0644))
+
+		secret, err := reader.Get(context.TODO(), testName)
+		require.NoError(t, err)
+		assert.Equal(t, testApiToken, string(secret.Data[dtclient.DynatraceApiToken]))
+
+		now = func() time.Time { return start.Add(time.Minute) }
+		secret, err = reader.Get(context.TODO(), testName)
+		require.NoError(t, err)
+		assert.Equal(t, "rotated-api-token", string(secret.Data[dtclient.DynatraceApiToken]))
+	})
+	t.Run(`invalid environment file`, func(t *testing.T) {
+		resetCaches(t)
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, testName+envFileExtension), []byte("api-token"), 0644))
+
+		_, err := NewReader(fake.NewClient(), testNamespace, &dynatracev1beta1.SecretProviderSpec{
+			File: &dynatracev1beta1.FileSecretProviderSpec{Path: dir, Format: dynatracev1beta1.FileSecretFormatEnv},
+		}).Get(context.TODO(), testName)
+
+		assert.Error(t, err)
+		assert.False(t, k8serrors.IsNotFound(err))
+	})
+}
+
+func TestRefreshInterval(t *testing.T) {
+	assert.Equal(t, DefaultRefreshInterval, RefreshInterval(nil))
+	assert.Equal(t, DefaultRefreshInterval, RefreshInterval(&dynatracev1beta1.SecretProviderSpec{}))
+	assert.Equal(t, time.Minute, RefreshInterval(&dynatracev1beta1.SecretProviderSpec{RefreshInterval: &metav1.Duration{Duration: time.Minute}}))
+}
@@ -0,0 +1,78 @@
+package secretprovider
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/utils/pointer"
+)
+
+// requestServiceAccountToken requests a short-lived token of the pod's service account, which is bound to the audience
+var requestServiceAccountToken = func(_ context.Context, _ string) (string, error) {
+	return "", errors.New("service account tokens for vault are not set up")
+}
+
+// SetupServiceAccountTokens lets the vault provider log in with short-lived tokens of the pod's service account,
+// which are bound to the vault audience, so the token of the pod itself is never sent to Vault.
+func SetupServiceAccountTokens(cfg *rest.Config) error {
+	clientset, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	requestServiceAccountToken = func(ctx context.Context, audience string) (string, error) {
+		namespace, name, err := podServiceAccount()
+		if err != nil {
+			return "", err
+		}
+		tokenRequest, err := clientset.CoreV1().ServiceAccounts(namespace).CreateToken(ctx, name, &authenticationv1.TokenRequest{
+			Spec: authenticationv1.TokenRequestSpec{
+				Audiences:         []string{audience},
+				ExpirationSeconds: pointer.Int64Ptr(int64(vaultTokenExpiration.Seconds())),
+			},
+		}, metav1.CreateOptions{})
+		if err != nil {
+			return "", errors.WithMessagef(err, "failed to request token of service account %s/%s", namespace, name)
+		}
+		return tokenRequest.Status.Token, nil
+	}
+	return nil
+}
+
+// podServiceAccount returns the namespace and name of the pod's service account, from the subject of its token
+func podServiceAccount() (string, string, error) {
+	jwt, err := os.ReadFile(serviceAccountTokenPath)
+	if err != nil {
+		return "", "", errors.WithMessage(err, "failed to read service account token")
+	}
+
+	parts := strings.Split(strings.TrimSpace(string(jwt)), ".")
+	if len(parts) != 3 {
+		return "", "", errors.New("service account token is no JWT")
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return "", "", errors.WithMessage(err, "failed to decode service account token")
+	}
+	var claims struct {
+		Subject string `json:"sub"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return "", "", errors.WithMessage(err, "failed to decode service account token")
+	}
+
+	// system:serviceaccount:<namespace>:<name>
+	subject := strings.Split(claims.Subject, ":")
+	if len(subject) != 4 || subject[0] != "system" || subject[1] != "serviceaccount" {
+		return "", "", errors.Errorf("service account token has an unexpected subject %q", claims.Subject)
+	}
+	return subject[2], subject[3], nil
+}
@@ -0,0 +1,230 @@
+package secretprovider
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// vaultProvider reads secrets from a KV version 2 secrets engine, after logging in with the Kubernetes auth method.
+type vaultProvider struct {
+	apiReader client.Reader
+	namespace string
+	spec      *dynatracev1beta1.VaultSecretProviderSpec
+}
+
+type vaultLoginResponse struct {
+	Auth struct {
+		ClientToken   string `json:"client_token"`
+		LeaseDuration int    `json:"lease_duration"`
+	} `json:"auth"`
+}
+
+type vaultSecretResponse struct {
+	Data struct {
+		Data map[string]string `json:"data"`
+	} `json:"data"`
+}
+
+// vaultTokens keeps the client tokens of the logins until their lease expires
+var vaultTokens = &vaultTokenCache{tokens: map[string]vaultToken{}}
+
+type vaultToken struct {
+	token   string
+	expires time.Time
+}
+
+type vaultTokenCache struct {
+	mutex  sync.Mutex
+	tokens map[string]vaultToken
+}
+
+func newVaultProvider(apiReader client.Reader, namespace string, spec *dynatracev1beta1.VaultSecretProviderSpec) *vaultProvider {
+	return &vaultProvider{
+		apiReader: apiReader,
+		namespace: namespace,
+		spec:      spec,
+	}
+}
+
+func (p *vaultProvider) read(ctx context.Context, name string) (map[string][]byte, error) {
+	httpClient, err := p.httpClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	token, err := p.login(ctx, httpClient)
+	if err != nil {
+		return nil, err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, p.url("v1", p.mount(), "data", p.spec.Path, name), nil)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	request.Header.Set("X-Vault-Token", token)
+
+	var secret vaultSecretResponse
+	if err := p.do(httpClient, request, &secret); err != nil {
+		if errors.Is(err, errSecretNotFound) {
+			return nil, err
+		}
+		// the token may have been revoked before its lease expired, it's renewed on the next read
+		vaultTokens.remove(p.tokenKey())
+		return nil, err
+	}
+
+	data := make(map[string][]byte, len(secret.Data.Data))
+	for field, value := range secret.Data.Data {
+		data[field] = []byte(value)
+	}
+	return data, nil
+}
+
+func (p *vaultProvider) login(ctx context.Context, httpClient *http.Client) (string, error) {
+	key := p.tokenKey()
+	if token, ok := vaultTokens.get(key); ok {
+		return token, nil
+	}
+
+	// the service account token is never sent in plain text, as the address is chosen by the author of the DynaKube
+	if address, err := url.Parse(p.spec.Address); err != nil || address.Scheme != "https" {
+		return "", errors.Errorf("failed to log in to vault: address %s is no https URL", p.spec.Address)
+	}
+
+	jwt, err := requestServiceAccountToken(ctx, vaultTokenAudience)
+	if err != nil {
+		return "", errors.WithMessage(err, "failed to request service account token to log in to vault")
+	}
+	body, err := json.Marshal(map[string]string{
+		"role": p.spec.Role,
+		"jwt":  jwt,
+	})
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url("v1", "auth", p.authPath(), "login"), bytes.NewReader(body))
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	var response vaultLoginResponse
+	if err := p.do(httpClient, request, &response); errors.Is(err, errSecretNotFound) {
+		// reported as failure instead, as the secret would be considered missing otherwise
+		return "", errors.Errorf("failed to log in to vault: no auth method enabled at %s", p.authPath())
+	} else if err != nil {
+		return "", errors.WithMessage(err, "failed to log in to vault")
+	}
+	if response.Auth.ClientToken == "" {
+		return "", errors.New("failed to log in to vault: response contains no client token")
+	}
+
+	vaultTokens.set(key, vaultToken{
+		token:   response.Auth.ClientToken,
+		expires: now().Add(time.Duration(response.Auth.LeaseDuration) * time.Second),
+	})
+	return response.Auth.ClientToken, nil
+}
+
+func (p *vaultProvider) do(httpClient *http.Client, request *http.Request, result interface{}) error {
+	response, err := httpClient.Do(request)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer func() {
+		_ = response.Body.Close()
+	}()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if response.StatusCode == http.StatusNotFound {
+		return errSecretNotFound
+	}
+	if response.StatusCode != http.StatusOK {
+		return errors.Errorf("vault responded with status %d: %s", response.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return errors.WithStack(json.Unmarshal(body, result))
+}
+
+func (p *vaultProvider) httpClient(ctx context.Context) (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if p.spec.TrustedCAs != "" {
+		var certs corev1.ConfigMap
+		if err := p.apiReader.Get(ctx, client.ObjectKey{Name: p.spec.TrustedCAs, Namespace: p.namespace}, &certs); err != nil {
+			return nil, errors.WithMessage(err, "failed to get vault certificate configmap")
+		}
+		rootCAs := x509.NewCertPool()
+		if ok := rootCAs.AppendCertsFromPEM([]byte(certs.Data[vaultTrustedCAKey])); !ok {
+			return nil, errors.Errorf("vault certificate configmap %s contains no certificates in field %s", p.spec.TrustedCAs, vaultTrustedCAKey)
+		}
+		transport.TLSClientConfig = &tls.Config{RootCAs: rootCAs}
+	}
+	return &http.Client{Transport: transport, Timeout: 30 * time.Second}, nil
+}
+
+func (p *vaultProvider) url(segments ...string) string {
+	var path []string
+	for _, segment := range segments {
+		if segment = strings.Trim(segment, "/"); segment != "" {
+			path = append(path, segment)
+		}
+	}
+	return fmt.Sprintf("%s/%s", strings.TrimSuffix(p.spec.Address, "/"), strings.Join(path, "/"))
+}
+
+func (p *vaultProvider) authPath() string {
+	if p.spec.AuthPath == "" {
+		return defaultVaultAuthPath
+	}
+	return p.spec.AuthPath
+}
+
+func (p *vaultProvider) mount() string {
+	if p.spec.Mount == "" {
+		return defaultVaultMount
+	}
+	return p.spec.Mount
+}
+
+func (p *vaultProvider) tokenKey() string {
+	return p.spec.Address + "/" + p.authPath() + "/" + p.spec.Role
+}
+
+func (c *vaultTokenCache) get(key string) (string, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	token, ok := c.tokens[key]
+	if !ok || !now().Before(token.expires) {
+		return "", false
+	}
+	return token.token, true
+}
+
+func (c *vaultTokenCache) set(key string, token vaultToken) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.tokens[key] = token
+}
+
+func (c *vaultTokenCache) remove(key string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	delete(c.tokens, key)
+}
@@ -0,0 +1,183 @@
+package secretprovider
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	"github.com/Dynatrace/dynatrace-operator/src/dtclient"
+	"github.com/Dynatrace/dynatrace-operator/src/scheme/fake"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	testJwt         = "service-account-token"
+	testClientToken = "vault-client-token"
+	testRole        = "dynatrace"
+	testVaultCAs    = "vault-certs"
+)
+
+func newVaultServer(t *testing.T, logins *int) *httptest.Server {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/auth/k8s/login":
+			var login map[string]string
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&login))
+			if login["role"] != testRole || login["jwt"] != testJwt {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			*logins++
+			_, _ = w.Write([]byte(`{"auth":{"client_token":"` + testClientToken + `","lease_duration":3600}}`))
+		case "/v1/kv/data/dynatrace/" + testName:
+			if r.Header.Get("X-Vault-Token") != testClientToken {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			_, _ = w.Write([]byte(`{"data":{"data":{"apiToken":"` + testApiToken + `"},"metadata":{"version":2}}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// newVaultClient returns a client with the certificate of the vault server in the testVaultCAs configmap
+func newVaultClient(server *httptest.Server) client.Client {
+	return fake.NewClient(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: testVaultCAs, Namespace: testNamespace},
+		Data: map[string]string{
+			vaultTrustedCAKey: string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})),
+		},
+	})
+}
+
+func TestVaultProvider(t *testing.T) {
+	previousRequestToken := requestServiceAccountToken
+	requestServiceAccountToken = func(_ context.Context, audience string) (string, error) {
+		if audience != vaultTokenAudience {
+			return "", errors.New("unexpected audience")
+		}
+		return testJwt, nil
+	}
+	defer func() {
+		requestServiceAccountToken = previousRequestToken
+	}()
+
+	t.Run(`secret is read after logging in`, func(t *testing.T) {
+		resetCaches(t)
+		logins := 0
+		server := newVaultServer(t, &logins)
+		spec := &dynatracev1beta1.SecretProviderSpec{
+			Vault: &dynatracev1beta1.VaultSecretProviderSpec{
+				Address:    server.URL,
+				Role:       testRole,
+				AuthPath:   "k8s",
+				Mount:      "kv",
+				Path:       "/dynatrace/",
+				TrustedCAs: testVaultCAs,
+			},
+		}
+
+		secret, err := NewReader(newVaultClient(server), testNamespace, spec).Get(context.TODO(), testName)
+		require.NoError(t, err)
+		assert.Equal(t, testApiToken, string(secret.Data[dtclient.DynatraceApiToken]))
+
+		// the client token is reused for other secrets
+		_, err = NewReader(newVaultClient(server), testNamespace, spec).Get(context.TODO(), "other")
+		assert.True(t, k8serrors.IsNotFound(err))
+		assert.Equal(t, 1, logins)
+	})
+	t.Run(`login is denied`, func(t *testing.T) {
+		resetCaches(t)
+		logins := 0
+		server := newVaultServer(t, &logins)
+
+		_, err := NewReader(newVaultClient(server), testNamespace, &dynatracev1beta1.SecretProviderSpec{
+			Vault: &dynatracev1beta1.VaultSecretProviderSpec{
+				Address:    server.URL,
+				Role:       "other-role",
+				AuthPath:   "k8s",
+				TrustedCAs: testVaultCAs,
+			},
+		}).Get(context.TODO(), testName)
+
+		assert.Error(t, err)
+		assert.False(t, k8serrors.IsNotFound(err))
+	})
+	t.Run(`auth method is not enabled`, func(t *testing.T) {
+		resetCaches(t)
+		logins := 0
+		server := newVaultServer(t, &logins)
+
+		_, err := NewReader(newVaultClient(server), testNamespace, &dynatracev1beta1.SecretProviderSpec{
+			Vault: &dynatracev1beta1.VaultSecretProviderSpec{
+				Address:    server.URL,
+				Role:       testRole,
+				TrustedCAs: testVaultCAs,
+			},
+		}).Get(context.TODO(), testName)
+
+		assert.Error(t, err)
+		assert.False(t, k8serrors.IsNotFound(err))
+	})
+	t.Run(`no login over http`, func(t *testing.T) {
+		resetCaches(t)
+		logins := 0
+		server := httptest.NewServer(newVaultServer(t, &logins).Config.Handler)
+		defer server.Close()
+
+		_, err := NewReader(fake.NewClient(), testNamespace, &dynatracev1beta1.SecretProviderSpec{
+			Vault: &dynatracev1beta1.VaultSecretProviderSpec{
+				Address:  server.URL,
+				Role:     testRole,
+				AuthPath: "k8s",
+			},
+		}).Get(context.TODO(), testName)
+
+		assert.Error(t, err)
+		assert.Equal(t, 0, logins)
+	})
+}
+
+func TestPodServiceAccount(t *testing.T) {
+	previousTokenPath := serviceAccountTokenPath
+	serviceAccountTokenPath = filepath.Join(t.TempDir(), "token")
+	defer func() {
+		serviceAccountTokenPath = previousTokenPath
+	}()
+
+	writeToken := func(t *testing.T, payload string) {
+		jwt := "header." + base64.RawURLEncoding.EncodeToString([]byte(payload)) + ".signature"
+		require.NoError(t, os.WriteFile(serviceAccountTokenPath, []byte(jwt+"\n"), 0644))
+	}
+
+	t.Run(`service account is read from the subject of the token`, func(t *testing.T) {
+		writeToken(t, `{"sub":"system:serviceaccount:dynatrace:dynatrace-operator"}`)
+
+		namespace, name, err := podServiceAccount()
+		require.NoError(t, err)
+		assert.Equal(t, "dynatrace", namespace)
+		assert.Equal(t, "dynatrace-operator", name)
+	})
+	t.Run(`token of a user is rejected`, func(t *testing.T) {
+		writeToken(t, `{"sub":"admin"}`)
+
+		_, _, err := podServiceAccount()
+		assert.Error(t, err)
+	})
+}
@@ -13,6 +13,9 @@ const (
 	ContainerConfFilenameTemplate = "container_%s.conf"
 	SecretConfigFieldName         = "config"
 
+	PodLabelsFilename      = "labels"
+	PodAnnotationsFilename = "annotations"
+
 	enrichmentFilenameTemplate = "dt_metadata.%s"
 	ldPreloadFilename          = "ld.so.preload"
 
@@ -20,9 +23,12 @@ const (
 	InstallerMode InstallMode = "installer"
 	CsiMode       InstallMode = "csi"
 
-	ModeEnv         = "MODE"
-	CanFailEnv      = "FAILURE_POLICY"
-	InstallerUrlEnv = "INSTALLER_URL"
+	ModeEnv            = "MODE"
+	CanFailEnv         = "FAILURE_POLICY"
+	InstallerUrlEnv    = "INSTALLER_URL"
+	InstallerSha256Env = "INSTALLER_SHA256"
+
+	InstallerAllowUnverifiedEnv = "INSTALLER_ALLOW_UNVERIFIED"
 
 	InstallerFlavorEnv = "FLAVOR"
 	InstallerTechEnv   = "TECHNOLOGIES"
@@ -50,9 +56,12 @@ var (
 	log = logger.NewDTLogger().WithName("standalone-init")
 
 	// Mount Path
-	BinDirMount    = filepath.Join("mnt", "bin")
-	ShareDirMount  = filepath.Join("mnt", "share")
-	ConfigDirMount = filepath.Join("mnt", "config")
+	BinDirMount     = filepath.Join("mnt", "bin")
+	ShareDirMount   = filepath.Join("mnt", "share")
+	ConfigDirMount  = filepath.Join("mnt", "config")
+	PodInfoDirMount = filepath.Join("mnt", "pod-info")
+	// PullSecretDirMount contains the .dockerconfigjson of the imagePullSecrets of the pod, one file per secret
+	PullSecretDirMount = filepath.Join("mnt", "pull-secret")
 
 	EnrichmentPath = filepath.Join("var", "lib", "dynatrace", "enrichment")
 )
@@ -27,6 +27,7 @@ func (builder *dtclientBuilder) createClient() (dtclient.Client, error) {
 	if err != nil {
 		return nil, err
 	}
+	// requests are not retried, waiting for the tenant would only hold back the start of the application
 	return client, nil
 }
 
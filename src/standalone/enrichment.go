@@ -0,0 +1,122 @@
+package standalone
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+)
+
+type enrichmentAttribute struct {
+	key   string
+	value string
+}
+
+// getEnrichmentAttributes resolves the enrichment rules of the DynaKube, rules without a value are skipped.
+// The pod labels and annotations are read from the pod info volume, the namespace and node labels are part of the init secret.
+func (runner *Runner) getEnrichmentAttributes() ([]enrichmentAttribute, error) {
+	if len(runner.config.EnrichmentRules) == 0 {
+		return nil, nil
+	}
+
+	podLabels, err := readPodInfoFile(runner.fs, PodLabelsFilename)
+	if err != nil {
+		return nil, err
+	}
+	podAnnotations, err := readPodInfoFile(runner.fs, PodAnnotationsFilename)
+	if err != nil {
+		return nil, err
+	}
+
+	sources := map[dynatracev1beta1.EnrichmentRuleType]map[string]string{
+		dynatracev1beta1.EnrichmentRuleTypePodLabel:       podLabels,
+		dynatracev1beta1.EnrichmentRuleTypePodAnnotation:  podAnnotations,
+		dynatracev1beta1.EnrichmentRuleTypeNamespaceLabel: runner.config.NamespaceLabels,
+		dynatracev1beta1.EnrichmentRuleTypeNodeLabel:      runner.getNodeLabels(),
+	}
+
+	var attributes []enrichmentAttribute
+	for _, rule := range runner.config.EnrichmentRules {
+		value, ok := sources[rule.Type][rule.Source]
+		if !ok {
+			continue
+		}
+		attributes = append(attributes, enrichmentAttribute{key: rule.ToAttribute(), value: value})
+	}
+	return attributes, nil
+}
+
+// getNodeLabels returns the labels of the node the pod is scheduled on, which the operator resolved into the init secret.
+// Nodes which joined the cluster after the init secret was updated are unknown, their node labels are skipped
+// instead of failing the injection.
+func (runner *Runner) getNodeLabels() map[string]string {
+	if !hasRuleOfType(runner.config.EnrichmentRules, dynatracev1beta1.EnrichmentRuleTypeNodeLabel) {
+		return nil
+	}
+
+	index, ok := runner.config.NodeLabelSetOfNode[runner.env.k8NodeName]
+	if !ok || index < 0 || index >= len(runner.config.NodeLabelSets) {
+		log.Info("labels of the node are unknown, skipping node label enrichment", "node", runner.env.k8NodeName)
+		return nil
+	}
+	return runner.config.NodeLabelSets[index]
+}
+
+func hasRuleOfType(rules []dynatracev1beta1.EnrichmentRule, ruleType dynatracev1beta1.EnrichmentRuleType) bool {
+	for _, rule := range rules {
+		if rule.Type == ruleType {
+			return true
+		}
+	}
+	return false
+}
+
+// readPodInfoFile parses a file of the downward API, which contains a key="quoted value" pair per line.
+// A missing file is treated as empty, as the volume is only mounted if the rules need it.
+func readPodInfoFile(fs afero.Fs, filename string) (map[string]string, error) {
+	content, err := afero.ReadFile(fs, filepath.Join(PodInfoDirMount, filename))
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	values := map[string]string{}
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			continue
+		}
+		split := strings.SplitN(line, "=", 2)
+		if len(split) != 2 {
+			return nil, errors.Errorf("malformed line in pod info file %s", filename)
+		}
+		value, err := strconv.Unquote(split[1])
+		if err != nil {
+			return nil, errors.WithMessagef(err, "malformed value of %s in pod info file %s", split[0], filename)
+		}
+		values[split[0]] = value
+	}
+	return values, scanner.Err()
+}
+
+func jsonString(value string) string {
+	raw, _ := json.Marshal(value)
+	return string(raw)
+}
+
+var valueEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, "\r", `\r`, "=", `\=`)
+
+// escapeValue escapes backslashes, line breaks and equal signs, so values taken from labels and annotations
+// can't start a new line or be split into another key in the .properties and .conf files.
+func escapeValue(value string) string {
+	return valueEscaper.Replace(value)
+}
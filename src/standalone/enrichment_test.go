@@ -0,0 +1,111 @@
+package standalone
+
+import (
+	"path/filepath"
+	"testing"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func createEnrichmentTestRunner(t *testing.T) *Runner {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, filepath.Join(PodInfoDirMount, PodLabelsFilename),
+		[]byte("app=\"shop\"\nteam=\"payments \\\"core\\\"\"\n"), 0644))
+	require.NoError(t, afero.WriteFile(fs, filepath.Join(PodInfoDirMount, PodAnnotationsFilename),
+		[]byte("owner=\"jane\"\n"), 0644))
+
+	return &Runner{
+		fs:  fs,
+		env: &environment{k8NodeName: testNodeName},
+		config: &SecretConfig{
+			EnrichmentRules: []dynatracev1beta1.EnrichmentRule{
+				{Type: dynatracev1beta1.EnrichmentRuleTypePodLabel, Source: "team", Target: "team.name"},
+				{Type: dynatracev1beta1.EnrichmentRuleTypePodAnnotation, Source: "owner"},
+				{Type: dynatracev1beta1.EnrichmentRuleTypeNamespaceLabel, Source: "cost-center"},
+				{Type: dynatracev1beta1.EnrichmentRuleTypeNodeLabel, Source: "zone"},
+				{Type: dynatracev1beta1.EnrichmentRuleTypePodLabel, Source: "missing"},
+			},
+			NamespaceLabels:    map[string]string{"cost-center": "1234"},
+			NodeLabelSets:      []map[string]string{{"zone": "eu-west-1b"}, {"zone": "eu-west-1a"}},
+			NodeLabelSetOfNode: map[string]int{"other-node": 0, testNodeName: 1},
+		},
+	}
+}
+
+func TestGetEnrichmentAttributes(t *testing.T) {
+	t.Run(`resolve all rule types`, func(t *testing.T) {
+		runner := createEnrichmentTestRunner(t)
+
+		attributes, err := runner.getEnrichmentAttributes()
+		require.NoError(t, err)
+		assert.Equal(t, []enrichmentAttribute{
+			{key: "team.name", value: `payments "core"`},
+			{key: "k8s.pod.annotation.owner", value: "jane"},
+			{key: "k8s.namespace.label.cost-center", value: "1234"},
+			{key: "k8s.node.label.zone", value: "eu-west-1a"},
+		}, attributes)
+	})
+	t.Run(`missing pod info is ignored`, func(t *testing.T) {
+		runner := createEnrichmentTestRunner(t)
+		runner.fs = afero.NewMemMapFs()
+
+		attributes, err := runner.getEnrichmentAttributes()
+		require.NoError(t, err)
+		assert.Len(t, attributes, 2)
+	})
+	t.Run(`unknown node is ignored`, func(t *testing.T) {
+		runner := createEnrichmentTestRunner(t)
+		runner.env.k8NodeName = "new-node"
+
+		attributes, err := runner.getEnrichmentAttributes()
+		require.NoError(t, err)
+		assert.Len(t, attributes, 3)
+	})
+	t.Run(`invalid node label set is ignored`, func(t *testing.T) {
+		runner := createEnrichmentTestRunner(t)
+		runner.config.NodeLabelSetOfNode[testNodeName] = 2
+
+		attributes, err := runner.getEnrichmentAttributes()
+		require.NoError(t, err)
+		assert.Len(t, attributes, 3)
+	})
+	t.Run(`malformed pod info`, func(t *testing.T) {
+		runner := createEnrichmentTestRunner(t)
+		require.NoError(t, afero.WriteFile(runner.fs, filepath.Join(PodInfoDirMount, PodLabelsFilename), []byte("app=shop\n"), 0644))
+
+		_, err := runner.getEnrichmentAttributes()
+		assert.Error(t, err)
+	})
+}
+
+func TestEnrichmentFilesWithAttributes(t *testing.T) {
+	runner := createEnrichmentTestRunner(t)
+	attributes := []enrichmentAttribute{{key: "team.name", value: "payments"}}
+
+	require.NoError(t, runner.createJsonEnrichmentFile(attributes))
+	content, err := afero.ReadFile(runner.fs, filepath.Join(EnrichmentPath, "dt_metadata.json"))
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "\"dt.kubernetes.cluster.id\": \"\",\n\"team.name\": \"payments\"\n")
+
+	require.NoError(t, runner.createPropsEnrichmentFile(attributes))
+	content, err = afero.ReadFile(runner.fs, filepath.Join(EnrichmentPath, "dt_metadata.properties"))
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "dt.kubernetes.cluster.id=\nteam.name=payments\n")
+
+	assert.Equal(t, "team.name payments\n", runner.getEnrichmentConfContent(attributes))
+}
+
+func TestEnrichmentFilesEscapeValues(t *testing.T) {
+	runner := createEnrichmentTestRunner(t)
+	attributes := []enrichmentAttribute{{key: "team.name", value: "payments\nk8s.pod.uid=forged\\"}}
+
+	require.NoError(t, runner.createPropsEnrichmentFile(attributes))
+	content, err := afero.ReadFile(runner.fs, filepath.Join(EnrichmentPath, "dt_metadata.properties"))
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "team.name=payments\\nk8s.pod.uid\\=forged\\\\\n")
+
+	assert.Equal(t, "team.name payments\\nk8s.pod.uid\\=forged\\\\\n", runner.getEnrichmentConfContent(attributes))
+}
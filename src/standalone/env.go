@@ -16,9 +16,12 @@ type containerInfo struct {
 }
 
 type environment struct {
-	mode         InstallMode
-	canFail      bool
-	installerUrl string
+	mode            InstallMode
+	canFail         bool
+	installerUrl    string
+	installerSha256 string
+
+	installerAllowUnverified bool
 
 	installerFlavor string
 	installerTech   []string
@@ -82,6 +85,8 @@ func (env *environment) setOptionalFields() {
 	env.addWorkloadKind()
 	env.addWorkloadName()
 	env.addInstallerUrl()
+	env.addInstallerSha256()
+	env.addInstallerAllowUnverified()
 	env.addInstallerArch()
 }
 
@@ -231,6 +236,16 @@ func (env *environment) addInstallerUrl() {
 	env.installerUrl = url
 }
 
+func (env *environment) addInstallerSha256() {
+	sha256, _ := checkEnvVar(InstallerSha256Env)
+	env.installerSha256 = sha256
+}
+
+func (env *environment) addInstallerAllowUnverified() {
+	allowUnverified, _ := checkEnvVar(InstallerAllowUnverifiedEnv)
+	env.installerAllowUnverified = allowUnverified == "true"
+}
+
 func (env *environment) addOneAgentInjected() error {
 	oneAgentInjected, err := checkEnvVar(OneAgentInjectedEnv)
 	if err != nil {
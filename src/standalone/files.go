@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
 var (
@@ -66,7 +67,15 @@ func (runner *Runner) getHostConfContent() string {
 	)
 }
 
-func (runner *Runner) createJsonEnrichmentFile() error {
+func (runner *Runner) getEnrichmentConfContent(attributes []enrichmentAttribute) string {
+	var content strings.Builder
+	for _, attribute := range attributes {
+		content.WriteString(fmt.Sprintf("%s %s\n", escapeValue(attribute.key), escapeValue(attribute.value)))
+	}
+	return content.String()
+}
+
+func (runner *Runner) createJsonEnrichmentFile(attributes []enrichmentAttribute) error {
 	jsonContent := fmt.Sprintf(jsonEnrichmentContentFormatString,
 		runner.env.k8PodUID,
 		runner.env.k8PodName,
@@ -75,12 +84,19 @@ func (runner *Runner) createJsonEnrichmentFile() error {
 		runner.env.workloadName,
 		runner.config.ClusterID,
 	)
+	if len(attributes) > 0 {
+		jsonContent = strings.TrimSuffix(jsonContent, "\n")
+		for _, attribute := range attributes {
+			jsonContent += fmt.Sprintf(",\n%s: %s", jsonString(attribute.key), jsonString(attribute.value))
+		}
+		jsonContent += "\n"
+	}
 	jsonPath := filepath.Join(EnrichmentPath, fmt.Sprintf(enrichmentFilenameTemplate, "json"))
 	return runner.createConfFile(jsonPath, jsonContent)
 
 }
 
-func (runner *Runner) createPropsEnrichmentFile() error {
+func (runner *Runner) createPropsEnrichmentFile(attributes []enrichmentAttribute) error {
 	propsContent := fmt.Sprintf(propsEnrichmentContentFormatString,
 		runner.env.k8PodUID,
 		runner.env.k8PodName,
@@ -89,6 +105,9 @@ func (runner *Runner) createPropsEnrichmentFile() error {
 		runner.env.workloadName,
 		runner.config.ClusterID,
 	)
+	for _, attribute := range attributes {
+		propsContent += fmt.Sprintf("%s=%s\n", escapeValue(attribute.key), escapeValue(attribute.value))
+	}
 	propsPath := filepath.Join(EnrichmentPath, fmt.Sprintf(enrichmentFilenameTemplate, "properties"))
 	return runner.createConfFile(propsPath, propsContent)
 }
@@ -2,8 +2,11 @@ package standalone
 
 import (
 	"fmt"
+	"os"
 	"path/filepath"
+	"strings"
 
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/dtversion"
 	"github.com/Dynatrace/dynatrace-operator/src/dtclient"
 	"github.com/Dynatrace/dynatrace-operator/src/installer"
 	"github.com/pkg/errors"
@@ -32,6 +35,10 @@ func NewRunner(fs afero.Fs) (*Runner, error) {
 	if err != nil {
 		return nil, err
 	}
+	source, err := newArtifactSource(fs, client, config, env)
+	if err != nil {
+		return nil, err
+	}
 	oneAgentInstaller := installer.NewOneAgentInstaller(
 		fs,
 		client,
@@ -43,7 +50,11 @@ func NewRunner(fs afero.Fs) (*Runner, error) {
 			Technologies: env.installerTech,
 			Version:      installer.VersionLatest,
 			Url:          env.installerUrl,
+			Sha256:       env.installerSha256,
+
+			AllowUnverified: env.installerAllowUnverified,
 		},
+		source,
 	)
 	return &Runner{
 		fs:        fs,
@@ -54,6 +65,65 @@ func NewRunner(fs afero.Fs) (*Runner, error) {
 	}, nil
 }
 
+// newArtifactSource returns the source of the code modules configured in the DynaKube,
+// an installer url set on the pod still downloads from there.
+func newArtifactSource(fs afero.Fs, client dtclient.Client, config *SecretConfig, env *environment) (installer.ArtifactSource, error) {
+	if env.installerUrl != "" {
+		return nil, nil
+	}
+	var auths map[string]dtversion.DockerAuth
+	if config.CodeModulesImage != "" {
+		var err error
+		auths, err = readImagePullSecrets(fs)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return installer.NewArtifactSource(fs, client, installer.SourceConfig{
+		Image:     config.CodeModulesImage,
+		ImagePath: config.CodeModulesImagePath,
+		DockerConfig: &dtversion.DockerConfig{
+			Auths:         auths,
+			SkipCertCheck: config.SkipCertCheck,
+		},
+		MirrorIndexUrl:  config.MirrorIndexUrl,
+		MirrorPublicKey: config.MirrorPublicKey,
+		SkipCertCheck:   config.SkipCertCheck,
+	})
+}
+
+// readImagePullSecrets merges the credentials of the imagePullSecrets of the pod, which the webhook mounts for pulling the code modules image.
+// Without imagePullSecrets, the image is pulled anonymously.
+func readImagePullSecrets(fs afero.Fs) (map[string]dtversion.DockerAuth, error) {
+	files, err := afero.ReadDir(fs, PullSecretDirMount)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	auths := map[string]dtversion.DockerAuth{}
+	for _, file := range files {
+		if file.IsDir() || strings.HasPrefix(file.Name(), ".") {
+			continue
+		}
+		config, err := afero.ReadFile(fs, filepath.Join(PullSecretDirMount, file.Name()))
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		secretAuths, err := dtversion.ParseDockerAuths(config)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "failed to parse image pull secret %s", file.Name())
+		}
+		for registry, auth := range secretAuths {
+			if _, ok := auths[registry]; !ok {
+				auths[registry] = auth
+			}
+		}
+	}
+	return auths, nil
+}
+
 func (runner *Runner) Run() error {
 	log.Info("standalone agent init started")
 	var err error
@@ -141,9 +211,15 @@ func (runner *Runner) setLDPreload() error {
 }
 
 func (runner *Runner) createContainerConfigurationFiles() error {
+	attributes, err := runner.getEnrichmentAttributes()
+	if err != nil {
+		return err
+	}
+
 	for _, container := range runner.env.containers {
 		confFilePath := filepath.Join(ShareDirMount, fmt.Sprintf(ContainerConfFilenameTemplate, container.name))
 		content := runner.getBaseConfContent(container)
+		content += runner.getEnrichmentConfContent(attributes)
 		if runner.hostTenant != NoHostTenant {
 			if runner.config.TenantUUID == runner.hostTenant {
 				content += runner.getK8ConfContent()
@@ -158,10 +234,15 @@ func (runner *Runner) createContainerConfigurationFiles() error {
 }
 
 func (runner *Runner) enrichMetadata() error {
-	if err := runner.createPropsEnrichmentFile(); err != nil {
+	attributes, err := runner.getEnrichmentAttributes()
+	if err != nil {
+		return err
+	}
+
+	if err := runner.createPropsEnrichmentFile(attributes); err != nil {
 		return err
 	}
-	if err := runner.createJsonEnrichmentFile(); err != nil {
+	if err := runner.createJsonEnrichmentFile(attributes); err != nil {
 		return err
 	}
 	return nil
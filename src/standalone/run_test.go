@@ -5,6 +5,7 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/dtversion"
 	"github.com/Dynatrace/dynatrace-operator/src/dtclient"
 	"github.com/Dynatrace/dynatrace-operator/src/installer"
 	"github.com/spf13/afero"
@@ -35,6 +36,38 @@ func TestNewRunner(t *testing.T) {
 	})
 }
 
+func TestReadImagePullSecrets(t *testing.T) {
+	t.Run(`no pull secrets mounted`, func(t *testing.T) {
+		auths, err := readImagePullSecrets(afero.NewMemMapFs())
+
+		require.NoError(t, err)
+		assert.Nil(t, auths)
+	})
+	t.Run(`merge pull secrets`, func(t *testing.T) {
+		fs := afero.NewMemMapFs()
+		require.NoError(t, afero.WriteFile(fs, filepath.Join(PullSecretDirMount, "registry-a"),
+			[]byte(`{"auths":{"registry.local":{"username":"a","password":"a"}}}`), 0600))
+		require.NoError(t, afero.WriteFile(fs, filepath.Join(PullSecretDirMount, "registry-b"),
+			[]byte(`{"auths":{"registry.local":{"username":"b","password":"b"},"registry.remote":{"username":"b","password":"b"}}}`), 0600))
+
+		auths, err := readImagePullSecrets(fs)
+
+		require.NoError(t, err)
+		assert.Equal(t, map[string]dtversion.DockerAuth{
+			"registry.local":  {Username: "a", Password: "a"},
+			"registry.remote": {Username: "b", Password: "b"},
+		}, auths)
+	})
+	t.Run(`invalid pull secret`, func(t *testing.T) {
+		fs := afero.NewMemMapFs()
+		require.NoError(t, afero.WriteFile(fs, filepath.Join(PullSecretDirMount, "registry-a"), []byte(`{`), 0600))
+
+		_, err := readImagePullSecrets(fs)
+
+		require.Error(t, err)
+	})
+}
+
 func TestConsumeErrorIfNecessary(t *testing.T) {
 	runner := createMockedRunner(t)
 	t.Run(`consume error`, func(t *testing.T) {
@@ -5,6 +5,7 @@ import (
 	"io/ioutil"
 	"path/filepath"
 
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
 	"github.com/spf13/afero"
 )
 
@@ -28,7 +29,19 @@ type SecretConfig struct {
 	HostGroup       string            `json:"hostGroup"`
 
 	// For the enrichment
-	ClusterID string `json:"clusterID"`
+	ClusterID       string                            `json:"clusterID"`
+	EnrichmentRules []dynatracev1beta1.EnrichmentRule `json:"enrichmentRules,omitempty"`
+	NamespaceLabels map[string]string                 `json:"namespaceLabels,omitempty"`
+	// NodeLabelSets holds every distinct set of node labels used by the rules once, as most nodes share their labels,
+	// NodeLabelSetOfNode maps the node names to the index of their set.
+	NodeLabelSets      []map[string]string `json:"nodeLabelSets,omitempty"`
+	NodeLabelSetOfNode map[string]int      `json:"nodeLabelSetOfNode,omitempty"`
+
+	// For the code modules source
+	CodeModulesImage     string `json:"codeModulesImage"`
+	CodeModulesImagePath string `json:"codeModulesImagePath"`
+	MirrorIndexUrl       string `json:"mirrorIndexUrl"`
+	MirrorPublicKey      string `json:"mirrorPublicKey"`
 }
 
 func newSecretConfigViaFs(fs afero.Fs) (*SecretConfig, error) {
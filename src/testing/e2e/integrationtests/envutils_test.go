@@ -16,6 +16,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/envtest"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
@@ -95,7 +96,7 @@ func newTestEnvironment() (*ControllerTestEnvironment, error) {
 	}
 	testEnvironment.Reconciler = dynakube.NewDynaKubeController(
 		kubernetesClient, kubernetesClient, scheme.Scheme,
-		mockDynatraceClientFunc(&testEnvironment.CommunicationHosts), cfg)
+		mockDynatraceClientFunc(&testEnvironment.CommunicationHosts), cfg, &record.FakeRecorder{})
 
 	return testEnvironment, nil
 }
@@ -0,0 +1,115 @@
+package troubleshoot
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"strings"
+
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/certificates"
+	dtcsi "github.com/Dynatrace/dynatrace-operator/src/controllers/csi"
+	"github.com/Dynatrace/dynatrace-operator/src/kubeobjects"
+	"github.com/Dynatrace/dynatrace-operator/src/mapper"
+	"github.com/Dynatrace/dynatrace-operator/src/webhook"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// checkWebhookCerts checks that the server certificate of the webhook is still valid and that the webhook
+// configuration trusts its CA.
+func (t *Troubleshooter) checkWebhookCerts() (Status, string) {
+	secretName, err := certificates.CertificateSecretName()
+	if err != nil {
+		return StatusFailed, err.Error()
+	}
+	secret, err := kubeobjects.GetSecret(t.ctx, t.client, secretName, t.namespace)
+	if err != nil {
+		return StatusFailed, fmt.Sprintf("failed to get webhook certificates secret '%s': %s", secretName, err)
+	} else if secret == nil {
+		return StatusFailed, fmt.Sprintf("webhook certificates secret '%s' is missing", secretName)
+	}
+
+	for _, key := range []string{certificates.RootCert, certificates.ServerCert, certificates.ServerKey} {
+		if len(secret.Data[key]) == 0 {
+			return StatusFailed, fmt.Sprintf("webhook certificates secret '%s' has no %s", secretName, key)
+		}
+	}
+
+	block, _ := pem.Decode(secret.Data[certificates.ServerCert])
+	if block == nil {
+		return StatusFailed, fmt.Sprintf("%s of webhook certificates secret '%s' is not PEM encoded", certificates.ServerCert, secretName)
+	}
+	serverCert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return StatusFailed, fmt.Sprintf("failed to parse %s of webhook certificates secret '%s': %s", certificates.ServerCert, secretName, err)
+	}
+	if t.now.After(serverCert.NotAfter) {
+		return StatusFailed, fmt.Sprintf("webhook server certificate expired at %s", serverCert.NotAfter)
+	}
+
+	var webhookConfig admissionregistrationv1.MutatingWebhookConfiguration
+	if err := t.client.Get(t.ctx, client.ObjectKey{Name: webhook.DeploymentName}, &webhookConfig); k8serrors.IsNotFound(err) {
+		return StatusWarning, fmt.Sprintf("server certificate is valid until %s, mutating webhook configuration '%s' not found, this is normal when deployed using OLM",
+			serverCert.NotAfter, webhook.DeploymentName)
+	} else if err != nil {
+		return StatusFailed, fmt.Sprintf("failed to get mutating webhook configuration '%s': %s", webhook.DeploymentName, err)
+	}
+	for _, mutatingWebhook := range webhookConfig.Webhooks {
+		if !bytes.Contains(mutatingWebhook.ClientConfig.CABundle, secret.Data[certificates.RootCert]) {
+			return StatusFailed, fmt.Sprintf("CA bundle of webhook '%s' doesn't match the certificates secret '%s'", mutatingWebhook.Name, secretName)
+		}
+	}
+	return StatusPassed, fmt.Sprintf("server certificate is valid until %s and trusted by the webhook configuration", serverCert.NotAfter)
+}
+
+func (t *Troubleshooter) checkCSIDriver() (Status, string) {
+	if !t.dynakube.NeedsCSIDriver() {
+		return StatusSkipped, "DynaKube doesn't use the CSI driver"
+	}
+
+	var daemonSet appsv1.DaemonSet
+	err := t.client.Get(t.ctx, client.ObjectKey{Name: dtcsi.DaemonSetName, Namespace: t.namespace}, &daemonSet)
+	if k8serrors.IsNotFound(err) {
+		return StatusFailed, fmt.Sprintf("DynaKube requires the CSI driver, but daemonset '%s' is missing", dtcsi.DaemonSetName)
+	} else if err != nil {
+		return StatusFailed, fmt.Sprintf("failed to get CSI driver daemonset '%s': %s", dtcsi.DaemonSetName, err)
+	}
+
+	ready, desired := daemonSet.Status.NumberReady, daemonSet.Status.DesiredNumberScheduled
+	if ready < desired {
+		return StatusWarning, fmt.Sprintf("only %d of %d CSI driver pods are ready", ready, desired)
+	}
+	return StatusPassed, fmt.Sprintf("%d of %d CSI driver pods are ready", ready, desired)
+}
+
+// checkNamespaceMapping computes the namespace mapping like the operator, without updating the namespaces.
+func (t *Troubleshooter) checkNamespaceMapping() (Status, string) {
+	if !t.dynakube.NeedAppInjection() {
+		return StatusSkipped, "DynaKube doesn't inject into namespaces"
+	}
+
+	dynakubeMapper := mapper.NewDynakubeMapper(t.ctx, t.client, t.client, t.namespace, t.dynakube)
+	outdated, err := dynakubeMapper.MatchingNamespaces()
+	if err != nil {
+		return StatusFailed, fmt.Sprintf("failed to map namespaces: %s", err)
+	}
+	mapped, err := mapper.GetNamespacesForDynakube(t.ctx, t.client, t.dynakube.Name)
+	if err != nil {
+		return StatusFailed, fmt.Sprintf("failed to list namespaces of the DynaKube: %s", err)
+	}
+
+	if len(outdated) > 0 {
+		var names []string
+		for _, namespace := range outdated {
+			names = append(names, namespace.Name)
+		}
+		return StatusWarning, fmt.Sprintf("%d namespaces mapped, mapping not yet updated for: %s", len(mapped), strings.Join(names, ", "))
+	}
+	if len(mapped) == 0 {
+		return StatusWarning, "no namespaces are mapped to the DynaKube, no pods will be injected"
+	}
+	return StatusPassed, fmt.Sprintf("%d namespaces mapped", len(mapped))
+}
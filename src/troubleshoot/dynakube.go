@@ -0,0 +1,183 @@
+package troubleshoot
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/dtversion"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/updates"
+	"github.com/Dynatrace/dynatrace-operator/src/dtclient"
+	"github.com/Dynatrace/dynatrace-operator/src/kubeobjects"
+	"github.com/Dynatrace/dynatrace-operator/src/secretprovider"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func (t *Troubleshooter) checkNamespace() (Status, string) {
+	var namespace corev1.Namespace
+	if err := t.client.Get(t.ctx, client.ObjectKey{Name: t.namespace}, &namespace); err != nil {
+		return StatusFailed, fmt.Sprintf("missing namespace '%s': %s", t.namespace, err)
+	}
+	return StatusPassed, fmt.Sprintf("using namespace '%s'", t.namespace)
+}
+
+func (t *Troubleshooter) checkDynakube() (Status, string) {
+	var dk dynatracev1beta1.DynaKube
+	err := t.client.Get(t.ctx, client.ObjectKey{Name: t.dynakubeName, Namespace: t.namespace}, &dk)
+	if meta.IsNoMatchError(err) {
+		return StatusFailed, "CRD for DynaKube missing"
+	} else if k8serrors.IsNotFound(err) {
+		return StatusFailed, fmt.Sprintf("selected DynaKube '%s' does not exist", t.dynakubeName)
+	} else if err != nil {
+		return StatusFailed, fmt.Sprintf("failed to get DynaKube '%s': %s", t.dynakubeName, err)
+	}
+	t.dynakube = &dk
+	return StatusPassed, fmt.Sprintf("using DynaKube '%s'", t.dynakubeName)
+}
+
+func (t *Troubleshooter) checkApiUrl() (Status, string) {
+	apiUrl := t.dynakube.Spec.APIURL
+	if !strings.HasSuffix(apiUrl, "/api") {
+		return StatusFailed, fmt.Sprintf("api url '%s' has to end on '/api'", apiUrl)
+	}
+	return StatusPassed, fmt.Sprintf("api url '%s' is valid", apiUrl)
+}
+
+func (t *Troubleshooter) checkTokenSecret() (Status, string) {
+	secretName := t.dynakube.Tokens()
+	secret, err := secretprovider.NewReaderForDynakube(t.client, t.dynakube).Get(t.ctx, secretName)
+	if k8serrors.IsNotFound(err) {
+		return StatusFailed, fmt.Sprintf("token secret '%s' is missing", secretName)
+	} else if err != nil {
+		return StatusFailed, fmt.Sprintf("failed to get token secret '%s': %s", secretName, err)
+	}
+
+	if len(secret.Data[dtclient.DynatraceApiToken]) == 0 {
+		return StatusFailed, fmt.Sprintf("token %s does not exist in secret '%s'", dtclient.DynatraceApiToken, secretName)
+	}
+	if len(secret.Data[dtclient.DynatracePaasToken]) == 0 {
+		return StatusPassed, fmt.Sprintf("secret '%s' has no %s, using %s instead", secretName, dtclient.DynatracePaasToken, dtclient.DynatraceApiToken)
+	}
+	return StatusPassed, fmt.Sprintf("secret '%s' has %s and %s", secretName, dtclient.DynatraceApiToken, dtclient.DynatracePaasToken)
+}
+
+// checkTokenScopes probes the tokens the same way the operator does, on a copy of the DynaKube with an empty status,
+// so the probes aren't throttled by the timestamps of the previous probes of the operator.
+func (t *Troubleshooter) checkTokenScopes() (Status, string) {
+	dk := t.dynakube.DeepCopy()
+	dk.Status = dynatracev1beta1.DynaKubeStatus{}
+
+	reconciler := &dynakube.DynatraceClientReconciler{
+		Client:              t.client,
+		DynatraceClientFunc: t.DynatraceClientFunc,
+		Now:                 metav1.NewTime(t.now),
+	}
+	dtc, _, err := reconciler.Reconcile(t.ctx, dk)
+	if err != nil {
+		return StatusFailed, fmt.Sprintf("failed to create Dynatrace API client: %s", err)
+	}
+	problems := tokenProblems("", dk.Status.Conditions)
+
+	for _, target := range dk.Spec.AdditionalTenants {
+		if _, _, err := reconciler.ReconcileTenantTarget(t.ctx, dk, target); err != nil {
+			problems = append(problems, fmt.Sprintf("tenant target %s: failed to create Dynatrace API client: %s", target.Name, err))
+			continue
+		}
+		problems = append(problems, tokenProblems(target.Name, dk.TenantTargetStatus(target.Name).Conditions)...)
+	}
+
+	if len(problems) > 0 {
+		return StatusFailed, strings.Join(problems, "; ")
+	}
+	t.dtc = dtc
+	return StatusPassed, "tokens are valid and have the required scopes"
+}
+
+func tokenProblems(tenantTarget string, conditions []metav1.Condition) []string {
+	var problems []string
+	for _, condition := range conditions {
+		if condition.Status == metav1.ConditionTrue {
+			continue
+		}
+		problem := fmt.Sprintf("%s: %s", condition.Type, condition.Message)
+		if tenantTarget != "" {
+			problem = fmt.Sprintf("tenant target %s: %s", tenantTarget, problem)
+		}
+		problems = append(problems, problem)
+	}
+	return problems
+}
+
+func (t *Troubleshooter) checkConnection() (Status, string) {
+	latestVersion, err := t.dtc.GetLatestAgentVersion(dtclient.OsUnix, dtclient.InstallerTypePaaS)
+	if err != nil {
+		return StatusFailed, fmt.Sprintf("unable to connect to tenant '%s': %s", t.dynakube.Spec.APIURL, err)
+	}
+	return StatusPassed, fmt.Sprintf("tenant '%s' is accessible, latest OneAgent version is %s", t.dynakube.Spec.APIURL, latestVersion)
+}
+
+func (t *Troubleshooter) checkPullSecret() (Status, string) {
+	secretName := t.dynakube.PullSecret()
+	secret, err := kubeobjects.GetSecret(t.ctx, t.client, secretName, t.namespace)
+	if err != nil {
+		return StatusFailed, fmt.Sprintf("failed to get pull secret '%s': %s", secretName, err)
+	} else if secret == nil {
+		if t.dynakube.Spec.CustomPullSecret == "" {
+			return StatusFailed, fmt.Sprintf("pull secret '%s' is missing, it is created by the operator once the tokens are valid", secretName)
+		}
+		return StatusFailed, fmt.Sprintf("custom pull secret '%s' is missing", secretName)
+	}
+
+	auths, err := dtversion.ParseDockerAuthsFromSecret(secret)
+	if err != nil {
+		return StatusFailed, fmt.Sprintf("pull secret '%s' is invalid: %s", secretName, err)
+	}
+	t.dockerConfig = &dtversion.DockerConfig{Auths: auths, SkipCertCheck: t.dynakube.Spec.SkipCertCheck}
+	return StatusPassed, fmt.Sprintf("pull secret '%s' has credentials for %d registries", secretName, len(auths))
+}
+
+func (t *Troubleshooter) checkImages() (Status, string) {
+	images := map[string]string{}
+	if t.dynakube.NeedsOneAgent() {
+		images["OneAgent"] = t.dynakube.ImmutableOneAgentImage()
+	}
+	if t.dynakube.NeedsActiveGate() {
+		images["ActiveGate"] = t.dynakube.ActiveGateImage()
+	}
+	if len(images) == 0 {
+		return StatusSkipped, "DynaKube uses neither OneAgent nor ActiveGate images"
+	}
+
+	if t.dynakube.Spec.TrustedCAs != "" {
+		t.dockerConfig.UseTrustedCerts = updates.SaveCustomCAs(t.client, *t.dynakube)
+		defer func() {
+			_ = os.Remove(path.Join(dtversion.TmpCAPath, dtversion.TmpCAName))
+		}()
+	}
+
+	var problems, found []string
+	for _, component := range []string{"OneAgent", "ActiveGate"} {
+		image, ok := images[component]
+		if !ok {
+			continue
+		}
+		imageVersion, err := t.ImageVersionProvider(image, t.dockerConfig)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s image '%s' is not pullable: %s", component, image, err))
+			continue
+		}
+		found = append(found, fmt.Sprintf("%s image '%s' (version '%s')", component, image, imageVersion.Version))
+	}
+
+	if len(problems) > 0 {
+		return StatusFailed, strings.Join(problems, "; ")
+	}
+	return StatusPassed, fmt.Sprintf("found %s", strings.Join(found, ", "))
+}
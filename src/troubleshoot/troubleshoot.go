@@ -0,0 +1,150 @@
+package troubleshoot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/dtversion"
+	"github.com/Dynatrace/dynatrace-operator/src/dtclient"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Status is the outcome of a check.
+type Status string
+
+const (
+	StatusPassed  Status = "passed"
+	StatusWarning Status = "warning"
+	StatusFailed  Status = "failed"
+	StatusSkipped Status = "skipped"
+)
+
+// Result is the outcome of a single check of the installation.
+type Result struct {
+	Check   string `json:"check"`
+	Status  Status `json:"status"`
+	Message string `json:"message"`
+}
+
+type check struct {
+	name string
+	run  func() (Status, string)
+
+	// requires are the names of the checks which have to succeed before this check can run.
+	requires []string
+}
+
+// Troubleshooter checks the installation of the operator and of a DynaKube for known issues.
+type Troubleshooter struct {
+	ctx          context.Context
+	client       client.Client
+	namespace    string
+	dynakubeName string
+	now          time.Time
+
+	// DynatraceClientFunc and ImageVersionProvider default to the ones used by the operator.
+	DynatraceClientFunc  dynakube.DynatraceClientFunc
+	ImageVersionProvider dtversion.ImageVersionProvider
+
+	dynakube     *dynatracev1beta1.DynaKube
+	dtc          dtclient.Client
+	dockerConfig *dtversion.DockerConfig
+}
+
+func NewTroubleshooter(ctx context.Context, clt client.Client, namespace, dynakubeName string) *Troubleshooter {
+	return &Troubleshooter{
+		ctx:                  ctx,
+		client:               clt,
+		namespace:            namespace,
+		dynakubeName:         dynakubeName,
+		now:                  time.Now(),
+		ImageVersionProvider: dtversion.GetImageVersion,
+	}
+}
+
+func (t *Troubleshooter) checks() []check {
+	return []check{
+		{name: "namespace", run: t.checkNamespace},
+		{name: "dynakube", run: t.checkDynakube, requires: []string{"namespace"}},
+		{name: "apiUrl", run: t.checkApiUrl, requires: []string{"dynakube"}},
+		{name: "tokenSecret", run: t.checkTokenSecret, requires: []string{"dynakube"}},
+		{name: "tokenScopes", run: t.checkTokenScopes, requires: []string{"apiUrl", "tokenSecret"}},
+		{name: "connection", run: t.checkConnection, requires: []string{"tokenScopes"}},
+		{name: "pullSecret", run: t.checkPullSecret, requires: []string{"dynakube"}},
+		{name: "images", run: t.checkImages, requires: []string{"pullSecret"}},
+		{name: "webhookCerts", run: t.checkWebhookCerts, requires: []string{"namespace"}},
+		{name: "csiDriver", run: t.checkCSIDriver, requires: []string{"dynakube"}},
+		{name: "namespaceMapping", run: t.checkNamespaceMapping, requires: []string{"dynakube"}},
+	}
+}
+
+// Run runs all checks in order. Checks which depend on a check that didn't succeed are skipped.
+func (t *Troubleshooter) Run() []Result {
+	results := []Result{}
+	succeeded := map[string]bool{}
+
+	for _, c := range t.checks() {
+		if missing := missingRequirements(c, succeeded); len(missing) > 0 {
+			results = append(results, Result{
+				Check:   c.name,
+				Status:  StatusSkipped,
+				Message: fmt.Sprintf("requires %s", strings.Join(missing, ", ")),
+			})
+			continue
+		}
+
+		status, message := c.run()
+		succeeded[c.name] = status == StatusPassed || status == StatusWarning
+		results = append(results, Result{Check: c.name, Status: status, Message: message})
+	}
+	return results
+}
+
+func missingRequirements(c check, succeeded map[string]bool) []string {
+	var missing []string
+	for _, required := range c.requires {
+		if !succeeded[required] {
+			missing = append(missing, required)
+		}
+	}
+	return missing
+}
+
+// HasFailures returns true if any of the checks failed.
+func HasFailures(results []Result) bool {
+	for _, result := range results {
+		if result.Status == StatusFailed {
+			return true
+		}
+	}
+	return false
+}
+
+// WriteText writes one line per result, readable for humans.
+func WriteText(w io.Writer, results []Result) error {
+	for _, result := range results {
+		if _, err := fmt.Fprintf(w, "[%-7s] %-16s %s\n", result.Status, result.Check, result.Message); err != nil {
+			return err
+		}
+	}
+
+	summary := "\nNo known issues found with the dynatrace-operator installation!\n"
+	if HasFailures(results) {
+		summary = "\nIssues found with the dynatrace-operator installation, see the failed checks above.\n"
+	}
+	_, err := fmt.Fprint(w, summary)
+	return err
+}
+
+// WriteJSON writes the results as a JSON array.
+func WriteJSON(w io.Writer, results []Result) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(results)
+}
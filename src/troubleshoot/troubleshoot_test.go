@@ -0,0 +1,292 @@
+package troubleshoot
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/certificates"
+	dtcsi "github.com/Dynatrace/dynatrace-operator/src/controllers/csi"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/dtversion"
+	"github.com/Dynatrace/dynatrace-operator/src/dtclient"
+	"github.com/Dynatrace/dynatrace-operator/src/scheme/fake"
+	"github.com/Dynatrace/dynatrace-operator/src/webhook"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	testNamespace    = "dynatrace"
+	testDynakubeName = "dynakube"
+	testApiUrl       = "https://tenant.live.dynatrace.com/api"
+	testApiToken     = "api-token"
+	testPaasToken    = "paas-token"
+)
+
+func newTestDynakube() *dynatracev1beta1.DynaKube {
+	return &dynatracev1beta1.DynaKube{
+		ObjectMeta: metav1.ObjectMeta{Name: testDynakubeName, Namespace: testNamespace},
+		Spec: dynatracev1beta1.DynaKubeSpec{
+			APIURL: testApiUrl,
+			OneAgent: dynatracev1beta1.OneAgentSpec{
+				CloudNativeFullStack: &dynatracev1beta1.CloudNativeFullStackSpec{},
+			},
+		},
+	}
+}
+
+func newTestTroubleshooter(objs ...client.Object) *Troubleshooter {
+	return NewTroubleshooter(context.TODO(), fake.NewClient(objs...), testNamespace, testDynakubeName)
+}
+
+func newTestTokenSecret(data map[string][]byte) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: testDynakubeName, Namespace: testNamespace},
+		Data:       data,
+	}
+}
+
+func TestRun(t *testing.T) {
+	t.Run(`checks are skipped if their requirements fail`, func(t *testing.T) {
+		results := newTestTroubleshooter().Run()
+
+		require.NotEmpty(t, results)
+		assert.Equal(t, Result{Check: "namespace", Status: StatusFailed, Message: results[0].Message}, results[0])
+		for _, result := range results[1:] {
+			assert.Equal(t, StatusSkipped, result.Status, result.Check)
+		}
+		assert.True(t, HasFailures(results))
+	})
+	t.Run(`missing dynakube`, func(t *testing.T) {
+		troubleshooter := newTestTroubleshooter(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: testNamespace}})
+
+		results := troubleshooter.Run()
+
+		require.True(t, len(results) > 1)
+		assert.Equal(t, StatusPassed, results[0].Status)
+		assert.Equal(t, StatusFailed, results[1].Status)
+		assert.Contains(t, results[1].Message, "does not exist")
+	})
+}
+
+func TestCheckApiUrl(t *testing.T) {
+	troubleshooter := newTestTroubleshooter()
+	troubleshooter.dynakube = newTestDynakube()
+
+	status, _ := troubleshooter.checkApiUrl()
+	assert.Equal(t, StatusPassed, status)
+
+	troubleshooter.dynakube.Spec.APIURL = "https://tenant.live.dynatrace.com"
+	status, message := troubleshooter.checkApiUrl()
+	assert.Equal(t, StatusFailed, status)
+	assert.Contains(t, message, "/api")
+}
+
+func TestCheckTokenSecret(t *testing.T) {
+	t.Run(`missing secret`, func(t *testing.T) {
+		troubleshooter := newTestTroubleshooter()
+		troubleshooter.dynakube = newTestDynakube()
+
+		status, message := troubleshooter.checkTokenSecret()
+
+		assert.Equal(t, StatusFailed, status)
+		assert.Contains(t, message, "missing")
+	})
+	t.Run(`missing api token`, func(t *testing.T) {
+		troubleshooter := newTestTroubleshooter(newTestTokenSecret(map[string][]byte{dtclient.DynatracePaasToken: []byte(testPaasToken)}))
+		troubleshooter.dynakube = newTestDynakube()
+
+		status, message := troubleshooter.checkTokenSecret()
+
+		assert.Equal(t, StatusFailed, status)
+		assert.Contains(t, message, dtclient.DynatraceApiToken)
+	})
+	t.Run(`api token only`, func(t *testing.T) {
+		troubleshooter := newTestTroubleshooter(newTestTokenSecret(map[string][]byte{dtclient.DynatraceApiToken: []byte(testApiToken)}))
+		troubleshooter.dynakube = newTestDynakube()
+
+		status, _ := troubleshooter.checkTokenSecret()
+
+		assert.Equal(t, StatusPassed, status)
+	})
+}
+
+func TestCheckTokenScopes(t *testing.T) {
+	tokenSecret := newTestTokenSecret(map[string][]byte{
+		dtclient.DynatraceApiToken:  []byte(testApiToken),
+		dtclient.DynatracePaasToken: []byte(testPaasToken),
+	})
+
+	t.Run(`valid tokens`, func(t *testing.T) {
+		dtc := &dtclient.MockDynatraceClient{}
+		dtc.On("GetTokenScopes", testApiToken).Return(dtclient.TokenScopes{dtclient.TokenScopeDataExport}, nil)
+		dtc.On("GetTokenScopes", testPaasToken).Return(dtclient.TokenScopes{dtclient.TokenScopeInstallerDownload}, nil)
+		troubleshooter := newTestTroubleshooter(tokenSecret)
+		troubleshooter.DynatraceClientFunc = dynakube.StaticDynatraceClient(dtc)
+		troubleshooter.dynakube = newTestDynakube()
+		// probes of the operator must not throttle the check
+		troubleshooter.dynakube.Status.LastAPITokenProbeTimestamp = &metav1.Time{Time: time.Now()}
+
+		status, _ := troubleshooter.checkTokenScopes()
+
+		assert.Equal(t, StatusPassed, status)
+		assert.Equal(t, dtc, troubleshooter.dtc)
+		dtc.AssertExpectations(t)
+	})
+	t.Run(`missing scopes`, func(t *testing.T) {
+		dtc := &dtclient.MockDynatraceClient{}
+		dtc.On("GetTokenScopes", testApiToken).Return(dtclient.TokenScopes{}, nil)
+		dtc.On("GetTokenScopes", testPaasToken).Return(dtclient.TokenScopes{dtclient.TokenScopeInstallerDownload}, nil)
+		troubleshooter := newTestTroubleshooter(tokenSecret)
+		troubleshooter.DynatraceClientFunc = dynakube.StaticDynatraceClient(dtc)
+		troubleshooter.dynakube = newTestDynakube()
+
+		status, message := troubleshooter.checkTokenScopes()
+
+		assert.Equal(t, StatusFailed, status)
+		assert.Contains(t, message, dtclient.TokenScopeDataExport)
+		assert.Nil(t, troubleshooter.dtc)
+	})
+}
+
+func TestCheckImages(t *testing.T) {
+	troubleshooter := newTestTroubleshooter()
+	troubleshooter.dynakube = newTestDynakube()
+	troubleshooter.dockerConfig = &dtversion.DockerConfig{}
+
+	t.Run(`pullable`, func(t *testing.T) {
+		troubleshooter.ImageVersionProvider = func(img string, _ *dtversion.DockerConfig) (dtversion.ImageVersion, error) {
+			return dtversion.ImageVersion{Version: "1.2.3"}, nil
+		}
+
+		status, message := troubleshooter.checkImages()
+
+		assert.Equal(t, StatusPassed, status)
+		assert.Contains(t, message, troubleshooter.dynakube.ImmutableOneAgentImage())
+	})
+	t.Run(`not pullable`, func(t *testing.T) {
+		troubleshooter.ImageVersionProvider = func(img string, _ *dtversion.DockerConfig) (dtversion.ImageVersion, error) {
+			return dtversion.ImageVersion{}, fmt.Errorf("unauthorized")
+		}
+
+		status, message := troubleshooter.checkImages()
+
+		assert.Equal(t, StatusFailed, status)
+		assert.Contains(t, message, "unauthorized")
+	})
+}
+
+func TestCheckWebhookCerts(t *testing.T) {
+	certs := certificates.Certs{Domain: "dynatrace-webhook.dynatrace.svc"}
+	require.NoError(t, certs.ValidateCerts())
+	certSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: webhook.SecretCertsName, Namespace: testNamespace},
+		Data:       certs.Data,
+	}
+	newWebhookConfig := func(caBundle []byte) *admissionregistrationv1.MutatingWebhookConfiguration {
+		return &admissionregistrationv1.MutatingWebhookConfiguration{
+			ObjectMeta: metav1.ObjectMeta{Name: webhook.DeploymentName},
+			Webhooks: []admissionregistrationv1.MutatingWebhook{{
+				Name:         "webhook.pod.dynatrace.com",
+				ClientConfig: admissionregistrationv1.WebhookClientConfig{CABundle: caBundle},
+			}},
+		}
+	}
+
+	t.Run(`valid certificates`, func(t *testing.T) {
+		troubleshooter := newTestTroubleshooter(certSecret, newWebhookConfig(certs.Data[certificates.RootCert]))
+
+		status, message := troubleshooter.checkWebhookCerts()
+
+		assert.Equal(t, StatusPassed, status, message)
+	})
+	t.Run(`expired certificates`, func(t *testing.T) {
+		troubleshooter := newTestTroubleshooter(certSecret, newWebhookConfig(certs.Data[certificates.RootCert]))
+		troubleshooter.now = time.Now().Add(10 * 365 * 24 * time.Hour)
+
+		status, message := troubleshooter.checkWebhookCerts()
+
+		assert.Equal(t, StatusFailed, status)
+		assert.Contains(t, message, "expired")
+	})
+	t.Run(`CA bundle doesn't match`, func(t *testing.T) {
+		troubleshooter := newTestTroubleshooter(certSecret, newWebhookConfig([]byte("other")))
+
+		status, message := troubleshooter.checkWebhookCerts()
+
+		assert.Equal(t, StatusFailed, status)
+		assert.Contains(t, message, "CA bundle")
+	})
+	t.Run(`missing secret`, func(t *testing.T) {
+		troubleshooter := newTestTroubleshooter()
+
+		status, _ := troubleshooter.checkWebhookCerts()
+
+		assert.Equal(t, StatusFailed, status)
+	})
+}
+
+func TestCheckCSIDriver(t *testing.T) {
+	t.Run(`missing daemonset`, func(t *testing.T) {
+		troubleshooter := newTestTroubleshooter()
+		troubleshooter.dynakube = newTestDynakube()
+
+		status, _ := troubleshooter.checkCSIDriver()
+
+		assert.Equal(t, StatusFailed, status)
+	})
+	t.Run(`pods not ready`, func(t *testing.T) {
+		troubleshooter := newTestTroubleshooter(&appsv1.DaemonSet{
+			ObjectMeta: metav1.ObjectMeta{Name: dtcsi.DaemonSetName, Namespace: testNamespace},
+			Status:     appsv1.DaemonSetStatus{DesiredNumberScheduled: 3, NumberReady: 2},
+		})
+		troubleshooter.dynakube = newTestDynakube()
+
+		status, message := troubleshooter.checkCSIDriver()
+
+		assert.Equal(t, StatusWarning, status)
+		assert.Contains(t, message, "2 of 3")
+	})
+	t.Run(`not used`, func(t *testing.T) {
+		troubleshooter := newTestTroubleshooter()
+		troubleshooter.dynakube = newTestDynakube()
+		troubleshooter.dynakube.Spec.OneAgent = dynatracev1beta1.OneAgentSpec{ClassicFullStack: &dynatracev1beta1.ClassicFullStackSpec{}}
+
+		status, _ := troubleshooter.checkCSIDriver()
+
+		assert.Equal(t, StatusSkipped, status)
+	})
+}
+
+func TestWriteResults(t *testing.T) {
+	results := []Result{
+		{Check: "namespace", Status: StatusPassed, Message: "using namespace 'dynatrace'"},
+		{Check: "dynakube", Status: StatusFailed, Message: "CRD for DynaKube missing"},
+	}
+
+	t.Run(`text`, func(t *testing.T) {
+		var buffer bytes.Buffer
+		require.NoError(t, WriteText(&buffer, results))
+
+		assert.Contains(t, buffer.String(), "[failed ] dynakube")
+		assert.Contains(t, buffer.String(), "Issues found")
+	})
+	t.Run(`json`, func(t *testing.T) {
+		var buffer bytes.Buffer
+		require.NoError(t, WriteJSON(&buffer, results))
+
+		var decoded []Result
+		require.NoError(t, json.Unmarshal(buffer.Bytes(), &decoded))
+		assert.Equal(t, results, decoded)
+	})
+}
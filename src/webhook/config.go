@@ -7,6 +7,14 @@ const (
 	// AnnotationDynatraceInjected is set to "true" by the webhook to Pods to indicate that it has been injected.
 	AnnotationDynatraceInjected = "dynakube.dynatrace.com/injected"
 
+	// LabelOneAgentInjected is set to "true" by the webhook to Pods with OneAgent injected, the NetworkPolicies
+	// for the communication endpoints select the Pods by it.
+	LabelOneAgentInjected = "oneagent.dynatrace.com/injected"
+
+	// LabelNetworkPolicyDefaultDeny can be set to "true" on a Namespace with default-deny egress policies,
+	// to have NetworkPolicies for the communication endpoints created for its Pods with OneAgent injected.
+	LabelNetworkPolicyDefaultDeny = "networkpolicy.dynatrace.com/default-deny"
+
 	// AnnotationOneAgentInject can be set at pod level to enable/disable OneAgent injection.
 	OneAgentPrefix           = "oneagent"
 	AnnotationOneAgentInject = OneAgentPrefix + ".dynatrace.com/inject"
@@ -15,6 +23,16 @@ const (
 	DataIngestPrefix           = "data-ingest"
 	AnnotationDataIngestInject = DataIngestPrefix + ".dynatrace.com/inject"
 
+	// AnnotationOneAgentContainersInclude and AnnotationOneAgentContainersExclude can be set at pod level to a comma
+	// separated list of container names, to limit the OneAgent injection to the listed containers, or to skip them.
+	AnnotationOneAgentContainersInclude = OneAgentPrefix + ".dynatrace.com/containers-include"
+	AnnotationOneAgentContainersExclude = OneAgentPrefix + ".dynatrace.com/containers-exclude"
+
+	// AnnotationDataIngestContainersInclude and AnnotationDataIngestContainersExclude can be set at pod level to a comma
+	// separated list of container names, to limit the data-ingest injection to the listed containers, or to skip them.
+	AnnotationDataIngestContainersInclude = DataIngestPrefix + ".dynatrace.com/containers-include"
+	AnnotationDataIngestContainersExclude = DataIngestPrefix + ".dynatrace.com/containers-exclude"
+
 	// AnnotationFlavor can be set on a Pod to configure which code modules flavor to download. It's set to "default"
 	// if not set.
 	AnnotationFlavor = "oneagent.dynatrace.com/flavor"
@@ -31,10 +49,28 @@ const (
 	// defaults to the PaaS installer download url of your tenant
 	AnnotationInstallerUrl = "oneagent.dynatrace.com/installer-url"
 
+	// AnnotationInstallerSha256 can be set on a Pod to pin the hex encoded SHA-256 checksum of the downloaded agent package.
+	// The installation fails if the download does not match. With an image as code modules source, it's the digest of the image,
+	// which is then pulled by it.
+	// The Dynatrace API does not announce checksums for installers, so packages downloaded from it are only installed with the
+	// annotation set, or with the allow-unverified-oneagent-downloads feature flag on the DynaKube.
+	AnnotationInstallerSha256 = "oneagent.dynatrace.com/installer-sha256"
+
 	// AnnotationFailurePolicy can be set on a Pod to control what the init container does on failures. When set to
 	// "fail", the init container will exit with error code 1. Defaults to "silent".
 	AnnotationFailurePolicy = "oneagent.dynatrace.com/failure-policy"
 
+	// AnnotationTenantTarget can be set on a Pod or its Namespace to report the code modules to one of the additional
+	// tenants of the DynaKube instead of the environment of the apiUrl. The value is the name of the tenant target.
+	AnnotationTenantTarget = "oneagent.dynatrace.com/tenant-target"
+
+	// AnnotationDryRun can be set to "true" on a Pod or its Namespace to let the webhook compute the injection without
+	// applying it. The Pod is admitted unchanged, except for the AnnotationDryRunPatch annotation.
+	AnnotationDryRun = "dynakube.dynatrace.com/dry-run"
+
+	// AnnotationDryRunPatch is set by the webhook in dry-run mode, it contains the JSON patch the webhook would have applied.
+	AnnotationDryRunPatch = "dynakube.dynatrace.com/dry-run-patch"
+
 	// DefaultInstallPath is the default directory to install the app-only OneAgent package.
 	DefaultInstallPath = "/opt/dynatrace/oneagent-paas"
 
@@ -8,6 +8,7 @@ const (
 	injectEvent          = "Inject"
 	updatePodEvent       = "UpdatePod"
 	missingDynakubeEvent = "MissingDynakube"
+	dryRunEvent          = "DryRun"
 
 	dataIngestInjectedEnvVarName = "DATA_INGEST_INJECTED"
 	oneAgentInjectedEnvVarName   = "ONEAGENT_INJECTED"
@@ -25,6 +26,10 @@ const (
 
 	injectionConfigVolumeName = "injection-config"
 
+	podInfoVolumeName = "pod-info"
+
+	pullSecretVolumeName = "pull-secret"
+
 	provisionedVolumeMode = "provisioned"
 	installerVolumeMode   = "installer"
 )
@@ -0,0 +1,116 @@
+package mutation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	"github.com/Dynatrace/dynatrace-operator/src/dtclient"
+	dtingestendpoint "github.com/Dynatrace/dynatrace-operator/src/ingestendpoint"
+	"github.com/Dynatrace/dynatrace-operator/src/mapper"
+	"github.com/Dynatrace/dynatrace-operator/src/scheme"
+	dtwebhook "github.com/Dynatrace/dynatrace-operator/src/webhook"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const (
+	dryRunClusterID   = "dry-run-cluster-id"
+	dryRunPlaceholder = "<dry-run>"
+)
+
+// DryRun runs the injection of the webhook offline, for a pod created in the given namespace, which is mapped to the DynaKube.
+// The secrets read by the webhook are replaced by placeholders, and only the direct owner of the pod is used as its workload.
+// Returns the JSON patch the webhook would apply, which is empty if the pod wouldn't be injected.
+func DryRun(ctx context.Context, dk *dynatracev1beta1.DynaKube, pod *corev1.Pod, namespace string, image string) ([]byte, error) {
+	if dk.Namespace == "" {
+		dk.Namespace = "dynatrace"
+	}
+	// the placeholders are Kubernetes secrets, so they aren't read from a secret provider
+	dk.Spec.SecretProvider = nil
+
+	rawPod, err := json.Marshal(pod)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder, err := admission.NewDecoder(scheme.Scheme)
+	if err != nil {
+		return nil, err
+	}
+
+	clt := fake.NewClientBuilder().
+		WithScheme(scheme.Scheme).
+		WithObjects(createDryRunObjects(dk, pod, namespace)...).
+		Build()
+	mutator := &podMutator{
+		client:     clt,
+		metaClient: clt,
+		apiReader:  clt,
+		decoder:    decoder,
+		image:      image,
+		namespace:  dk.Namespace,
+		clusterID:  dryRunClusterID,
+		recorder:   record.NewFakeRecorder(10),
+	}
+
+	rsp := mutator.Handle(ctx, admission.Request{
+		AdmissionRequest: admissionv1.AdmissionRequest{
+			Name:      pod.Name,
+			Namespace: namespace,
+			Object:    runtime.RawExtension{Raw: rawPod},
+		},
+	})
+	if len(rsp.Patches) == 0 {
+		if rsp.Result != nil && rsp.Result.Message != "" {
+			return nil, fmt.Errorf("%s", rsp.Result.Message)
+		}
+		return []byte("[]"), nil
+	}
+	return json.MarshalIndent(rsp.Patches, "", "  ")
+}
+
+func createDryRunObjects(dk *dynatracev1beta1.DynaKube, pod *corev1.Pod, namespace string) []client.Object {
+	objects := []client.Object{
+		dk,
+		&corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   namespace,
+				Labels: map[string]string{mapper.InstanceLabel: dk.Name},
+			},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: dk.Tokens(), Namespace: dk.Namespace},
+			Data: map[string][]byte{
+				dtclient.DynatraceApiToken:        []byte(dryRunPlaceholder),
+				dtclient.DynatracePaasToken:       []byte(dryRunPlaceholder),
+				dtclient.DynatraceDataIngestToken: []byte(dryRunPlaceholder),
+			},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: dtwebhook.SecretConfigName, Namespace: namespace},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: dtingestendpoint.SecretEndpointName, Namespace: namespace},
+		},
+	}
+
+	// the owners are created without owners themselves, so the direct owner of the pod is its root owner
+	for _, owner := range pod.OwnerReferences {
+		ownerObject := &unstructured.Unstructured{}
+		ownerObject.SetAPIVersion(owner.APIVersion)
+		ownerObject.SetKind(owner.Kind)
+		ownerObject.SetName(owner.Name)
+		ownerObject.SetNamespace(namespace)
+		objects = append(objects, ownerObject)
+	}
+	return objects
+}
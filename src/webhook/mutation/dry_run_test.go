@@ -0,0 +1,77 @@
+package mutation
+
+import (
+	"context"
+	"testing"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	dtwebhook "github.com/Dynatrace/dynatrace-operator/src/webhook"
+	jsonpatch "github.com/evanphx/json-patch"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/json"
+	"k8s.io/utils/pointer"
+)
+
+func createDryRunTestDynakube() *dynatracev1beta1.DynaKube {
+	return &dynatracev1beta1.DynaKube{
+		ObjectMeta: metav1.ObjectMeta{Name: dynakubeName, Namespace: "dynatrace"},
+		Spec: dynatracev1beta1.DynaKubeSpec{
+			APIURL: "https://tenant.test-api-url.com/api",
+			OneAgent: dynatracev1beta1.OneAgentSpec{
+				ApplicationMonitoring: &dynatracev1beta1.ApplicationMonitoringSpec{},
+			},
+		},
+	}
+}
+
+func TestDryRun(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test-pod-12345",
+			OwnerReferences: []metav1.OwnerReference{
+				{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "test-replicaset", Controller: pointer.BoolPtr(true)},
+			},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "test-container", Image: "alpine"}},
+		},
+	}
+
+	t.Run(`returns patch of injection`, func(t *testing.T) {
+		patchBytes, err := DryRun(context.TODO(), createDryRunTestDynakube(), pod, "test-namespace", "operator-image")
+		require.NoError(t, err)
+
+		podBytes, err := json.Marshal(pod)
+		require.NoError(t, err)
+		patch, err := jsonpatch.DecodePatch(patchBytes)
+		require.NoError(t, err)
+		injectedPodBytes, err := patch.Apply(podBytes)
+		require.NoError(t, err)
+
+		var injectedPod corev1.Pod
+		require.NoError(t, json.Unmarshal(injectedPodBytes, &injectedPod))
+		require.Len(t, injectedPod.Spec.InitContainers, 1)
+		assert.Equal(t, "operator-image", injectedPod.Spec.InitContainers[0].Image)
+		assert.Contains(t, injectedPod.Spec.InitContainers[0].Env, corev1.EnvVar{Name: workloadNameEnvVarName, Value: "test-replicaset"})
+		assert.Equal(t, "data-ingest,oneagent", injectedPod.Annotations[dtwebhook.AnnotationDynatraceInjected])
+	})
+	t.Run(`empty patch if pod is not injected`, func(t *testing.T) {
+		disabledPod := pod.DeepCopy()
+		disabledPod.Annotations = map[string]string{dtwebhook.AnnotationOneAgentInject: "false"}
+
+		patchBytes, err := DryRun(context.TODO(), createDryRunTestDynakube(), disabledPod, "test-namespace", "operator-image")
+		require.NoError(t, err)
+		assert.JSONEq(t, "[]", string(patchBytes))
+	})
+	t.Run(`returns reason if injection fails`, func(t *testing.T) {
+		targetPod := pod.DeepCopy()
+		targetPod.Annotations = map[string]string{dtwebhook.AnnotationTenantTarget: "unknown"}
+
+		_, err := DryRun(context.TODO(), createDryRunTestDynakube(), targetPod, "test-namespace", "operator-image")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "has no tenant target unknown")
+	})
+}
@@ -59,7 +59,39 @@ func (f FeatureType) namePrefixed() string {
 }
 
 type InjectionInfo struct {
-	features map[FeatureType]bool
+	features   map[FeatureType]bool
+	containers map[FeatureType]containerSelection
+}
+
+// containerSelection holds the container names of the include and exclude annotations of a feature.
+// An empty include list selects all containers.
+type containerSelection struct {
+	include map[string]bool
+	exclude map[string]bool
+}
+
+func newContainerSelection(pod *corev1.Pod, includeAnnotation string, excludeAnnotation string) containerSelection {
+	return containerSelection{
+		include: parseContainerNames(pod.Annotations[includeAnnotation]),
+		exclude: parseContainerNames(pod.Annotations[excludeAnnotation]),
+	}
+}
+
+func parseContainerNames(value string) map[string]bool {
+	names := make(map[string]bool)
+	for _, name := range strings.Split(value, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names[name] = true
+		}
+	}
+	return names
+}
+
+func (selection containerSelection) selected(containerName string) bool {
+	if len(selection.include) > 0 && !selection.include[containerName] {
+		return false
+	}
+	return !selection.exclude[containerName]
 }
 
 func NewInjectionInfoForPod(pod *corev1.Pod) *InjectionInfo {
@@ -69,11 +101,42 @@ func NewInjectionInfoForPod(pod *corev1.Pod) *InjectionInfo {
 	injectionInfo := NewInjectionInfo()
 	injectionInfo.add(NewFeature(OneAgent, oneAgentInject))
 	injectionInfo.add(NewFeature(DataIngest, dataIngestInject))
+	injectionInfo.selectContainers(pod, OneAgent,
+		newContainerSelection(pod, dtwebhook.AnnotationOneAgentContainersInclude, dtwebhook.AnnotationOneAgentContainersExclude))
+	injectionInfo.selectContainers(pod, DataIngest,
+		newContainerSelection(pod, dtwebhook.AnnotationDataIngestContainersInclude, dtwebhook.AnnotationDataIngestContainersExclude))
 	return injectionInfo
 }
 
 func NewInjectionInfo() *InjectionInfo {
-	return &InjectionInfo{features: make(map[FeatureType]bool)}
+	return &InjectionInfo{
+		features:   make(map[FeatureType]bool),
+		containers: make(map[FeatureType]containerSelection),
+	}
+}
+
+// selectContainers limits the feature to the selected containers of the pod,
+// the feature is disabled if none of the containers is selected.
+func (info *InjectionInfo) selectContainers(pod *corev1.Pod, feature FeatureType, selection containerSelection) {
+	if len(selection.include) == 0 && len(selection.exclude) == 0 {
+		return
+	}
+
+	info.containers[feature] = selection
+	for _, c := range pod.Spec.Containers {
+		if selection.selected(c.Name) {
+			return
+		}
+	}
+	if info.exists(feature) {
+		info.features[feature] = false
+	}
+}
+
+// containerEnabled checks if the feature is enabled and the container is selected for it.
+func (info *InjectionInfo) containerEnabled(wanted FeatureType, containerName string) bool {
+	selection, exists := info.containers[wanted]
+	return info.enabled(wanted) && (!exists || selection.selected(containerName))
 }
 
 func (info *InjectionInfo) exists(wanted FeatureType) bool {
@@ -86,7 +149,8 @@ func (info *InjectionInfo) enabled(wanted FeatureType) bool {
 	return exists && val
 }
 
-func (info *InjectionInfo) anyEnabled() bool {
+// AnyEnabled returns true if the webhook injects any feature into the pod.
+func (info *InjectionInfo) AnyEnabled() bool {
 	for _, enabled := range info.features {
 		if enabled {
 			return true
@@ -95,6 +159,13 @@ func (info *InjectionInfo) anyEnabled() bool {
 	return false
 }
 
+// Disable turns off a feature for the pod, e.g. if it is disabled for the whole DynaKube.
+func (info *InjectionInfo) Disable(feature FeatureType) {
+	if info.exists(feature) {
+		info.features[feature] = false
+	}
+}
+
 func (info *InjectionInfo) add(f Feature) {
 	info.features[f.ftype] = f.enabled
 }
@@ -2,6 +2,10 @@ package mutation
 
 import (
 	"testing"
+
+	dtwebhook "github.com/Dynatrace/dynatrace-operator/src/webhook"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
 )
 
 func TestFeature_name(t *testing.T) {
@@ -149,3 +153,45 @@ func TestInjectionInfo_injectedAnnotation(t *testing.T) {
 		})
 	}
 }
+
+func TestNewInjectionInfoForPodContainerSelection(t *testing.T) {
+	pod := &corev1.Pod{
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "app"}, {Name: "sidecar"}, {Name: "proxy"}},
+		},
+	}
+
+	t.Run(`all containers selected by default`, func(t *testing.T) {
+		info := NewInjectionInfoForPod(pod)
+
+		for _, c := range pod.Spec.Containers {
+			assert.True(t, info.containerEnabled(OneAgent, c.Name))
+			assert.True(t, info.containerEnabled(DataIngest, c.Name))
+		}
+	})
+	t.Run(`include and exclude per feature`, func(t *testing.T) {
+		pod := pod.DeepCopy()
+		pod.Annotations = map[string]string{
+			dtwebhook.AnnotationOneAgentContainersInclude:   "app, sidecar",
+			dtwebhook.AnnotationOneAgentContainersExclude:   "sidecar",
+			dtwebhook.AnnotationDataIngestContainersExclude: "proxy",
+		}
+		info := NewInjectionInfoForPod(pod)
+
+		assert.True(t, info.containerEnabled(OneAgent, "app"))
+		assert.False(t, info.containerEnabled(OneAgent, "sidecar"))
+		assert.False(t, info.containerEnabled(OneAgent, "proxy"))
+		assert.True(t, info.containerEnabled(DataIngest, "app"))
+		assert.True(t, info.containerEnabled(DataIngest, "sidecar"))
+		assert.False(t, info.containerEnabled(DataIngest, "proxy"))
+	})
+	t.Run(`feature disabled if no container is selected`, func(t *testing.T) {
+		pod := pod.DeepCopy()
+		pod.Annotations = map[string]string{dtwebhook.AnnotationOneAgentContainersInclude: "unknown"}
+		info := NewInjectionInfoForPod(pod)
+
+		assert.False(t, info.enabled(OneAgent))
+		assert.True(t, info.enabled(DataIngest))
+		assert.Equal(t, "data-ingest", info.injectedAnnotation())
+	})
+}
@@ -116,72 +116,6 @@ type podMutator struct {
 	recorder       record.EventRecorder
 }
 
-func findRootOwnerOfPod(ctx context.Context, clt client.Client, pod *corev1.Pod, namespace string) (string, string, error) {
-	obj := &metav1.PartialObjectMetadata{
-		TypeMeta: metav1.TypeMeta{
-			APIVersion: pod.APIVersion,
-			Kind:       pod.Kind,
-		},
-		ObjectMeta: metav1.ObjectMeta{
-			Name: pod.ObjectMeta.Name,
-			// pod.ObjectMeta.Namespace is empty yet
-			Namespace:       namespace,
-			OwnerReferences: pod.ObjectMeta.OwnerReferences,
-		},
-	}
-	return findRootOwner(ctx, clt, obj)
-}
-
-func findRootOwner(ctx context.Context, clt client.Client, o *metav1.PartialObjectMetadata) (string, string, error) {
-	if len(o.ObjectMeta.OwnerReferences) == 0 {
-		kind := o.Kind
-		if kind == "Pod" {
-			kind = ""
-		}
-		return o.ObjectMeta.Name, kind, nil
-	}
-
-	om := o.ObjectMeta
-	for _, owner := range om.OwnerReferences {
-		if owner.Controller != nil && *owner.Controller && isWellKnownWorkload(owner) {
-			obj := &metav1.PartialObjectMetadata{
-				TypeMeta: metav1.TypeMeta{
-					APIVersion: owner.APIVersion,
-					Kind:       owner.Kind,
-				},
-			}
-			if err := clt.Get(ctx, client.ObjectKey{Name: owner.Name, Namespace: om.Namespace}, obj); err != nil {
-				podLog.Error(err, "failed to query the object", "apiVersion", owner.APIVersion, "kind", owner.Kind, "name", owner.Name, "namespace", om.Namespace)
-				return o.ObjectMeta.Name, o.Kind, err
-			}
-
-			return findRootOwner(ctx, clt, obj)
-		}
-	}
-	return o.ObjectMeta.Name, o.Kind, nil
-}
-
-func isWellKnownWorkload(ownerRef metav1.OwnerReference) bool {
-	knownWorkloads := []metav1.TypeMeta{
-		{Kind: "ReplicaSet", APIVersion: "apps/v1"},
-		{Kind: "Deployment", APIVersion: "apps/v1"},
-		{Kind: "ReplicationController", APIVersion: "v1"},
-		{Kind: "StatefulSet", APIVersion: "apps/v1"},
-		{Kind: "DaemonSet", APIVersion: "apps/v1"},
-		{Kind: "Job", APIVersion: "batch/v1"},
-		{Kind: "CronJob", APIVersion: "batch/v1"},
-		{Kind: "DeploymentConfig", APIVersion: "apps.openshift.io/v1"},
-	}
-
-	for _, knownController := range knownWorkloads {
-		if ownerRef.Kind == knownController.Kind &&
-			ownerRef.APIVersion == knownController.APIVersion {
-			return true
-		}
-	}
-	return false
-}
-
 // podMutator adds an annotation to every incoming pods
 func (m *podMutator) Handle(ctx context.Context, req admission.Request) admission.Response {
 	emptyPatch := admission.Patched("")
@@ -200,7 +134,7 @@ func (m *podMutator) Handle(ctx context.Context, req admission.Request) admissio
 	}()
 
 	injectionInfo := NewInjectionInfoForPod(pod)
-	if !injectionInfo.anyEnabled() {
+	if !injectionInfo.AnyEnabled() {
 		return emptyPatch
 	}
 
@@ -215,22 +149,37 @@ func (m *podMutator) Handle(ctx context.Context, req admission.Request) admissio
 	}
 
 	if dk.FeatureDisableMetadataEnrichment() {
-		injectionInfo.features[DataIngest] = false
+		injectionInfo.Disable(DataIngest)
 	}
 
 	if !dk.NeedAppInjection() {
 		return emptyPatch
 	}
 
-	secretResponse := m.ensureInitSecret(ctx, ns, dk)
-	if secretResponse != nil {
-		return *secretResponse
+	tenantTargetResponse := m.setTenantTarget(pod, ns, dk)
+	if tenantTargetResponse != nil {
+		return *tenantTargetResponse
+	}
+
+	// a dry run must not change the cluster, so the secrets mounted by the injected pod aren't created
+	dryRun := isDryRun(pod, ns)
+	if !dryRun {
+		secretResponse := m.ensureInitSecret(ctx, ns, dk, pod.Annotations[dtwebhook.AnnotationTenantTarget])
+		if secretResponse != nil {
+			return *secretResponse
+		}
 	}
 
 	dataIngestFields := map[string]string{}
 	if injectionInfo.enabled(DataIngest) {
+		if !dryRun {
+			if err := m.ensureDataIngestSecret(ctx, ns, dkName); err != nil {
+				return silentErrorResponse(m.currentPodName, err)
+			}
+		}
+
 		var err error
-		dataIngestFields, err = m.ensureDataIngestSecret(ctx, ns, dkName, dk)
+		dataIngestFields, err = m.prepareDataIngestFields(ctx, dk)
 		if err != nil {
 			return silentErrorResponse(m.currentPodName, err)
 		}
@@ -244,33 +193,43 @@ func (m *podMutator) Handle(ctx context.Context, req admission.Request) admissio
 	}
 
 	injectionInfo.fillAnnotations(pod)
+	addOneAgentInjectedLabel(pod, injectionInfo)
 
 	workloadName, workloadKind, workloadResponse := m.retrieveWorkload(ctx, req, injectionInfo, pod)
 	if workloadResponse != nil {
 		return *workloadResponse
 	}
 
-	flavor, technologies, installPath, installerURL, failurePolicy, image := m.getBasicData(pod)
+	flavor, technologies, installPath, installerURL, installerSha256, failurePolicy, image := m.getBasicData(pod)
 
-	dkVol, mode := ensureDynakubeVolume(dk)
+	dkVol, mode := ensureDynakubeVolume(dk, pod)
 
 	setupInjectionConfigVolume(pod)
 	setupOneAgentVolumes(injectionInfo, pod, dkVol)
 	setupDataIngestVolumes(injectionInfo, pod)
+	setupPodInfoVolume(pod, dk)
+	setupPullSecretVolume(injectionInfo, pod, dk, mode)
 
-	sc := getSecurityContext(pod)
+	sc := getSecurityContext(pod, injectionInfo)
 	basePodName := getBasePodName(pod)
 	deploymentMetadata := m.getDeploymentMetadata(dk)
 
-	installContainer := createInstallInitContainerBase(image, pod, failurePolicy, basePodName, sc, dk)
+	installContainer := createInstallInitContainerBase(image, pod, injectionInfo, failurePolicy, basePodName, sc, dk)
 
-	decorateInstallContainerWithOneAgent(&installContainer, injectionInfo, flavor, technologies, installPath, installerURL, mode)
+	decorateInstallContainerWithOneAgent(&installContainer, injectionInfo, flavor, technologies, installPath, installerURL, installerSha256, mode)
+	decorateInstallContainerWithUnverifiedDownloads(&installContainer, injectionInfo, dk)
 	decorateInstallContainerWithDataIngest(&installContainer, injectionInfo, workloadKind, workloadName)
+	decorateInstallContainerWithPodInfo(&installContainer, dk)
+	decorateInstallContainerWithPullSecret(&installContainer, injectionInfo, pod, dk, mode)
 
 	updateContainers(pod, injectionInfo, &installContainer, dk, deploymentMetadata, dataIngestFields)
 
 	addToInitContainers(pod, installContainer)
 
+	if dryRun {
+		return m.getDryRunResponse(pod, dk, basePodName, req)
+	}
+
 	m.recorder.Eventf(&dk,
 		corev1.EventTypeNormal,
 		injectEvent,
@@ -279,6 +238,40 @@ func (m *podMutator) Handle(ctx context.Context, req admission.Request) admissio
 	return getResponseForPod(pod, &req)
 }
 
+func isDryRun(pod *corev1.Pod, ns corev1.Namespace) bool {
+	return kubeobjects.GetFieldBool(pod.Annotations, dtwebhook.AnnotationDryRun, kubeobjects.GetFieldBool(ns.Annotations, dtwebhook.AnnotationDryRun, false))
+}
+
+// getDryRunResponse admits the original pod, only annotated with the JSON patch of the injected pod.
+func (m *podMutator) getDryRunResponse(injectedPod *corev1.Pod, dk dynatracev1beta1.DynaKube, basePodName string, req admission.Request) admission.Response {
+	injectionResponse := getResponseForPod(injectedPod, &req)
+	if len(injectionResponse.Patches) == 0 {
+		return injectionResponse
+	}
+
+	patch, err := json.Marshal(injectionResponse.Patches)
+	if err != nil {
+		return silentErrorResponse(m.currentPodName, err)
+	}
+
+	pod, rsp := m.getPod(req)
+	if rsp != nil {
+		return *rsp
+	}
+	if pod.Annotations == nil {
+		pod.Annotations = map[string]string{}
+	}
+	pod.Annotations[dtwebhook.AnnotationDryRunPatch] = string(patch)
+
+	m.recorder.Eventf(&dk,
+		corev1.EventTypeNormal,
+		dryRunEvent,
+		"Dry run of injection into pod %s in namespace %s resulted in %d patch operations, see annotation %s",
+		basePodName, req.Namespace, len(injectionResponse.Patches), dtwebhook.AnnotationDryRunPatch)
+
+	return getResponseForPod(pod, &req)
+}
+
 func (m *podMutator) handleAlreadyInjectedPod(pod *corev1.Pod, dk dynatracev1beta1.DynaKube, injectionInfo *InjectionInfo, dataIngestFields map[string]string, req admission.Request) *admission.Response {
 	// are there any injections already?
 	if len(pod.Annotations[dtwebhook.AnnotationDynatraceInjected]) > 0 {
@@ -292,19 +285,33 @@ func (m *podMutator) handleAlreadyInjectedPod(pod *corev1.Pod, dk dynatracev1bet
 	return nil
 }
 
+// addOneAgentInjectedLabel marks the pods with OneAgent injected, so the NetworkPolicies for the communication endpoints can select them.
+func addOneAgentInjectedLabel(pod *corev1.Pod, injectionInfo *InjectionInfo) {
+	if !injectionInfo.enabled(OneAgent) {
+		return
+	}
+	if pod.Labels == nil {
+		pod.Labels = map[string]string{}
+	}
+	pod.Labels[dtwebhook.LabelOneAgentInjected] = "true"
+}
+
 func addToInitContainers(pod *corev1.Pod, installContainer corev1.Container) {
 	pod.Spec.InitContainers = append(pod.Spec.InitContainers, installContainer)
 }
 
 func updateContainers(pod *corev1.Pod, injectionInfo *InjectionInfo, ic *corev1.Container, dk dynatracev1beta1.DynaKube, deploymentMetadata *deploymentmetadata.DeploymentMetadata, dataIngestFields map[string]string) {
+	oneAgentContainers := 0
 	for i := range pod.Spec.Containers {
 		c := &pod.Spec.Containers[i]
 
-		if injectionInfo.enabled(OneAgent) {
-			updateInstallContainerOneAgent(ic, i+1, c.Name, c.Image)
+		if injectionInfo.containerEnabled(OneAgent, c.Name) {
+			// the install container expects the injected containers to be numbered without gaps
+			oneAgentContainers++
+			updateInstallContainerOneAgent(ic, oneAgentContainers, c.Name, c.Image)
 			updateContainerOneAgent(c, &dk, pod, deploymentMetadata)
 		}
-		if injectionInfo.enabled(DataIngest) {
+		if injectionInfo.containerEnabled(DataIngest, c.Name) {
 			updateContainerDataIngest(c, pod, deploymentMetadata, dataIngestFields)
 		}
 	}
@@ -328,7 +335,7 @@ func decorateInstallContainerWithDataIngest(ic *corev1.Container, injectionInfo
 	}
 }
 
-func decorateInstallContainerWithOneAgent(ic *corev1.Container, injectionInfo *InjectionInfo, flavor string, technologies string, installPath string, installerURL string, mode string) {
+func decorateInstallContainerWithOneAgent(ic *corev1.Container, injectionInfo *InjectionInfo, flavor string, technologies string, installPath string, installerURL string, installerSha256 string, mode string) {
 	if injectionInfo.enabled(OneAgent) {
 		ic.Env = append(ic.Env,
 			corev1.EnvVar{Name: standalone.InstallerFlavorEnv, Value: flavor},
@@ -339,6 +346,10 @@ func decorateInstallContainerWithOneAgent(ic *corev1.Container, injectionInfo *I
 			corev1.EnvVar{Name: standalone.OneAgentInjectedEnv, Value: "true"},
 		)
 
+		if installerSha256 != "" {
+			ic.Env = append(ic.Env, corev1.EnvVar{Name: standalone.InstallerSha256Env, Value: installerSha256})
+		}
+
 		ic.VolumeMounts = append(ic.VolumeMounts,
 			corev1.VolumeMount{Name: oneAgentBinVolumeName, MountPath: standalone.BinDirMount},
 			corev1.VolumeMount{Name: oneAgentShareVolumeName, MountPath: standalone.ShareDirMount},
@@ -350,14 +361,14 @@ func decorateInstallContainerWithOneAgent(ic *corev1.Container, injectionInfo *I
 	}
 }
 
-func createInstallInitContainerBase(image string, pod *corev1.Pod, failurePolicy string, basePodName string, sc *corev1.SecurityContext, dk dynatracev1beta1.DynaKube) corev1.Container {
+func createInstallInitContainerBase(image string, pod *corev1.Pod, injectionInfo *InjectionInfo, failurePolicy string, basePodName string, sc *corev1.SecurityContext, dk dynatracev1beta1.DynaKube) corev1.Container {
 	ic := corev1.Container{
 		Name:            dtwebhook.InstallContainerName,
 		Image:           image,
 		ImagePullPolicy: corev1.PullIfNotPresent,
 		Args:            []string{"init"},
 		Env: []corev1.EnvVar{
-			{Name: standalone.ContainerCountEnv, Value: strconv.Itoa(len(pod.Spec.Containers))},
+			{Name: standalone.ContainerCountEnv, Value: strconv.Itoa(getContainerCount(pod, injectionInfo))},
 			{Name: standalone.CanFailEnv, Value: failurePolicy},
 			{Name: standalone.K8PodNameEnv, ValueFrom: fieldEnvVar("metadata.name")},
 			{Name: standalone.K8PodUIDEnv, ValueFrom: fieldEnvVar("metadata.uid")},
@@ -397,14 +408,49 @@ func getBasePodName(pod *corev1.Pod) string {
 	return basePodName
 }
 
-func getSecurityContext(pod *corev1.Pod) *corev1.SecurityContext {
+// getContainerCount returns the number of containers the OneAgent is injected into,
+// or the number of all containers, if the OneAgent isn't injected.
+func getContainerCount(pod *corev1.Pod, injectionInfo *InjectionInfo) int {
+	if !injectionInfo.enabled(OneAgent) {
+		return len(pod.Spec.Containers)
+	}
+
+	count := 0
+	for _, c := range pod.Spec.Containers {
+		if injectionInfo.containerEnabled(OneAgent, c.Name) {
+			count++
+		}
+	}
+	return count
+}
+
+// getSecurityContext uses the security context of the first container selected for the OneAgent injection,
+// as the install container has to create the agent files with its user. If the OneAgent isn't injected,
+// the first container selected for the data-ingest injection is used.
+func getSecurityContext(pod *corev1.Pod, injectionInfo *InjectionInfo) *corev1.SecurityContext {
+	container := &pod.Spec.Containers[0]
+	if selected := findFirstSelectedContainer(pod, injectionInfo, OneAgent); selected != nil {
+		container = selected
+	} else if selected := findFirstSelectedContainer(pod, injectionInfo, DataIngest); selected != nil {
+		container = selected
+	}
+
 	var sc *corev1.SecurityContext
-	if pod.Spec.Containers[0].SecurityContext != nil {
-		sc = pod.Spec.Containers[0].SecurityContext.DeepCopy()
+	if container.SecurityContext != nil {
+		sc = container.SecurityContext.DeepCopy()
 	}
 	return sc
 }
 
+func findFirstSelectedContainer(pod *corev1.Pod, injectionInfo *InjectionInfo, feature FeatureType) *corev1.Container {
+	for i := range pod.Spec.Containers {
+		if injectionInfo.containerEnabled(feature, pod.Spec.Containers[i].Name) {
+			return &pod.Spec.Containers[i]
+		}
+	}
+	return nil
+}
+
 func setupDataIngestVolumes(injectionInfo *InjectionInfo, pod *corev1.Pod) {
 	if !injectionInfo.enabled(DataIngest) {
 		return
@@ -451,17 +497,108 @@ func setupInjectionConfigVolume(pod *corev1.Pod) {
 			VolumeSource: corev1.VolumeSource{
 				Secret: &corev1.SecretVolumeSource{
 					SecretName: dtwebhook.SecretConfigName,
+					Items:      getInitSecretItems(pod),
 				},
 			},
 		},
 	)
 }
 
+// setupPodInfoVolume exposes the labels and annotations of the pod to the install container, if the enrichment rules of the DynaKube use them.
+func setupPodInfoVolume(pod *corev1.Pod, dk dynatracev1beta1.DynaKube) {
+	if !dk.NeedsPodInfo() {
+		return
+	}
+
+	pod.Spec.Volumes = append(pod.Spec.Volumes,
+		corev1.Volume{
+			Name: podInfoVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				DownwardAPI: &corev1.DownwardAPIVolumeSource{
+					Items: []corev1.DownwardAPIVolumeFile{
+						{Path: standalone.PodLabelsFilename, FieldRef: &corev1.ObjectFieldSelector{FieldPath: "metadata.labels"}},
+						{Path: standalone.PodAnnotationsFilename, FieldRef: &corev1.ObjectFieldSelector{FieldPath: "metadata.annotations"}},
+					},
+				},
+			},
+		},
+	)
+}
+
+func decorateInstallContainerWithPodInfo(ic *corev1.Container, dk dynatracev1beta1.DynaKube) {
+	if !dk.NeedsPodInfo() {
+		return
+	}
+
+	ic.VolumeMounts = append(ic.VolumeMounts, corev1.VolumeMount{
+		Name:      podInfoVolumeName,
+		MountPath: standalone.PodInfoDirMount,
+	})
+}
+
+// decorateInstallContainerWithUnverifiedDownloads lets the install container download the code modules from the Dynatrace API
+// without a pinned checksum, if the DynaKube allows it.
+func decorateInstallContainerWithUnverifiedDownloads(ic *corev1.Container, injectionInfo *InjectionInfo, dk dynatracev1beta1.DynaKube) {
+	if !injectionInfo.enabled(OneAgent) || !dk.FeatureAllowUnverifiedOneAgentDownloads() {
+		return
+	}
+
+	ic.Env = append(ic.Env, corev1.EnvVar{Name: standalone.InstallerAllowUnverifiedEnv, Value: "true"})
+}
+
+// needsPullSecretVolume returns true if the install container pulls the code modules image itself, which it does with the imagePullSecrets of the pod.
+// The pull secret of the DynaKube is only used by the CSI driver, so its credentials don't leave the operator namespace.
+func needsPullSecretVolume(injectionInfo *InjectionInfo, pod *corev1.Pod, dk dynatracev1beta1.DynaKube, mode string) bool {
+	codeModulesSource := dk.CodeModulesSource()
+	return injectionInfo.enabled(OneAgent) && mode == installerVolumeMode &&
+		codeModulesSource != nil && codeModulesSource.Image != "" && len(pod.Spec.ImagePullSecrets) > 0
+}
+
+func setupPullSecretVolume(injectionInfo *InjectionInfo, pod *corev1.Pod, dk dynatracev1beta1.DynaKube, mode string) {
+	if !needsPullSecretVolume(injectionInfo, pod, dk, mode) {
+		return
+	}
+
+	optional := true
+	var sources []corev1.VolumeProjection
+	for _, pullSecret := range pod.Spec.ImagePullSecrets {
+		sources = append(sources, corev1.VolumeProjection{
+			Secret: &corev1.SecretProjection{
+				LocalObjectReference: pullSecret,
+				Items:                []corev1.KeyToPath{{Key: corev1.DockerConfigJsonKey, Path: pullSecret.Name}},
+				Optional:             &optional,
+			},
+		})
+	}
+
+	pod.Spec.Volumes = append(pod.Spec.Volumes,
+		corev1.Volume{
+			Name: pullSecretVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				Projected: &corev1.ProjectedVolumeSource{Sources: sources},
+			},
+		},
+	)
+}
+
+func decorateInstallContainerWithPullSecret(ic *corev1.Container, injectionInfo *InjectionInfo, pod *corev1.Pod, dk dynatracev1beta1.DynaKube, mode string) {
+	if !needsPullSecretVolume(injectionInfo, pod, dk, mode) {
+		return
+	}
+
+	ic.VolumeMounts = append(ic.VolumeMounts, corev1.VolumeMount{
+		Name:      pullSecretVolumeName,
+		MountPath: standalone.PullSecretDirMount,
+		ReadOnly:  true,
+	})
+}
+
 func (m *podMutator) getBasicData(pod *corev1.Pod) (
 	flavor string,
 	technologies string,
 	installPath string,
 	installerURL string,
+	installerSha256 string,
 	failurePolicy string,
 	image string,
 ) {
@@ -469,15 +606,16 @@ func (m *podMutator) getBasicData(pod *corev1.Pod) (
 	technologies = url.QueryEscape(kubeobjects.GetField(pod.Annotations, dtwebhook.AnnotationTechnologies, "all"))
 	installPath = kubeobjects.GetField(pod.Annotations, dtwebhook.AnnotationInstallPath, dtwebhook.DefaultInstallPath)
 	installerURL = kubeobjects.GetField(pod.Annotations, dtwebhook.AnnotationInstallerUrl, "")
+	installerSha256 = kubeobjects.GetField(pod.Annotations, dtwebhook.AnnotationInstallerSha256, "")
 	failurePolicy = kubeobjects.GetField(pod.Annotations, dtwebhook.AnnotationFailurePolicy, "silent")
 	image = m.image
 	return
 }
 
-func ensureDynakubeVolume(dk dynatracev1beta1.DynaKube) (corev1.VolumeSource, string) {
+func ensureDynakubeVolume(dk dynatracev1beta1.DynaKube, pod *corev1.Pod) (corev1.VolumeSource, string) {
 	dkVol := corev1.VolumeSource{}
 	mode := ""
-	if dk.NeedsCSIDriver() {
+	if dk.NeedsCSIDriver() && !needsInstallerForFlavor(dk, pod) {
 		dkVol.CSI = &corev1.CSIVolumeSource{
 			Driver: dtcsi.DriverName,
 			VolumeAttributes: map[string]string{
@@ -485,6 +623,10 @@ func ensureDynakubeVolume(dk dynatracev1beta1.DynaKube) (corev1.VolumeSource, st
 				csivolumes.CSIVolumeAttributeDynakubeField: dk.Name,
 			},
 		}
+		// only an explicitly requested flavor is passed on, the CSI driver mounts the default flavor of the node otherwise
+		if flavor, ok := pod.Annotations[dtwebhook.AnnotationFlavor]; ok {
+			dkVol.CSI.VolumeAttributes[csivolumes.CSIVolumeAttributeFlavorField] = flavor
+		}
 		mode = provisionedVolumeMode
 	} else {
 		dkVol.EmptyDir = &corev1.EmptyDirVolumeSource{}
@@ -493,12 +635,31 @@ func ensureDynakubeVolume(dk dynatracev1beta1.DynaKube) (corev1.VolumeSource, st
 	return dkVol, mode
 }
 
+// needsInstallerForFlavor checks if the pod requests musl code modules, which the CSI driver only provides if the DynaKube pre-pulls them.
+// The default flavors of the nodes are glibc based and can't replace them, so the pod downloads them with the installer instead.
+func needsInstallerForFlavor(dk dynatracev1beta1.DynaKube, pod *corev1.Pod) bool {
+	if pod.Annotations[dtwebhook.AnnotationFlavor] != dtclient.FlavorMusl {
+		return false
+	}
+
+	prePull := dk.CodeModulesPrePull()
+	if prePull == nil {
+		return true
+	}
+	for _, flavor := range prePull.Flavors {
+		if flavor == dtclient.FlavorMusl {
+			return false
+		}
+	}
+	return true
+}
+
 func (m *podMutator) retrieveWorkload(ctx context.Context, req admission.Request, injectionInfo *InjectionInfo, pod *corev1.Pod) (string, string, *admission.Response) {
 	var rsp admission.Response
 	var workloadName, workloadKind string
 	if injectionInfo.enabled(DataIngest) {
 		var err error
-		workloadName, workloadKind, err = findRootOwnerOfPod(ctx, m.metaClient, pod, req.Namespace)
+		workloadName, workloadKind, err = kubeobjects.FindRootOwnerOfPod(ctx, m.metaClient, pod, req.Namespace)
 		if err != nil {
 			rsp = silentErrorResponse(m.currentPodName, err)
 			return "", "", &rsp
@@ -514,7 +675,7 @@ func (m *podMutator) applyReinvocationPolicy(pod *corev1.Pod, dk dynatracev1beta
 		c := &pod.Spec.Containers[i]
 
 		oaInjected := false
-		if injectionInfo.enabled(OneAgent) {
+		if injectionInfo.containerEnabled(OneAgent, c.Name) {
 			for _, e := range c.Env {
 				if e.Name == "LD_PRELOAD" {
 					oaInjected = true
@@ -523,7 +684,7 @@ func (m *podMutator) applyReinvocationPolicy(pod *corev1.Pod, dk dynatracev1beta
 			}
 		}
 		diInjected := false
-		if injectionInfo.enabled(DataIngest) {
+		if injectionInfo.containerEnabled(DataIngest, c.Name) {
 			for _, vm := range c.VolumeMounts {
 				if vm.Name == dataIngestEndpointVolumeName {
 					diInjected = true
@@ -532,8 +693,8 @@ func (m *podMutator) applyReinvocationPolicy(pod *corev1.Pod, dk dynatracev1beta
 			}
 		}
 
-		oaInjectionMissing := injectionInfo.enabled(OneAgent) && !oaInjected
-		diInjectionMissing := injectionInfo.enabled(DataIngest) && !diInjected
+		oaInjectionMissing := injectionInfo.containerEnabled(OneAgent, c.Name) && !oaInjected
+		diInjectionMissing := injectionInfo.containerEnabled(DataIngest, c.Name) && !diInjected
 
 		if oaInjectionMissing {
 			// container does not have LD_PRELOAD set
@@ -553,7 +714,7 @@ func (m *podMutator) applyReinvocationPolicy(pod *corev1.Pod, dk dynatracev1beta
 					}
 				}
 			}
-			updateInstallContainerOneAgent(installContainer, i+1, c.Name, c.Image)
+			addContainerToInstallContainer(installContainer, c.Name, c.Image)
 
 			needsUpdate = true
 		}
@@ -611,20 +772,23 @@ func (m *podMutator) getNsAndDkName(ctx context.Context, req admission.Request)
 	return ns, dkName, nil
 }
 
-func (m *podMutator) ensureDataIngestSecret(ctx context.Context, ns corev1.Namespace, dkName string, dk dynatracev1beta1.DynaKube) (map[string]string, error) {
-	endpointGenerator := dtingestendpoint.NewEndpointSecretGenerator(m.client, m.apiReader, m.namespace)
-
+func (m *podMutator) ensureDataIngestSecret(ctx context.Context, ns corev1.Namespace, dkName string) error {
 	var endpointSecret corev1.Secret
 	if err := m.apiReader.Get(ctx, client.ObjectKey{Name: dtingestendpoint.SecretEndpointName, Namespace: ns.Name}, &endpointSecret); k8serrors.IsNotFound(err) {
+		endpointGenerator := dtingestendpoint.NewEndpointSecretGenerator(m.client, m.apiReader, m.namespace)
 		if _, err := endpointGenerator.GenerateForNamespace(ctx, dkName, ns.Name); err != nil {
 			podLog.Error(err, "failed to create the data-ingest endpoint secret before pod injection")
-			return nil, err
+			return err
 		}
 	} else if err != nil {
 		podLog.Error(err, "failed to query the data-ingest endpoint secret before pod injection")
-		return nil, err
+		return err
 	}
+	return nil
+}
 
+func (m *podMutator) prepareDataIngestFields(ctx context.Context, dk dynatracev1beta1.DynaKube) (map[string]string, error) {
+	endpointGenerator := dtingestendpoint.NewEndpointSecretGenerator(m.client, m.apiReader, m.namespace)
 	dataIngestFields, err := endpointGenerator.PrepareFields(ctx, &dk)
 	if err != nil {
 		podLog.Error(err, "failed to query the data-ingest endpoint secret before pod injection")
@@ -653,11 +817,51 @@ func (m *podMutator) getDynakube(ctx context.Context, req admission.Request, dkN
 	return dk, nil
 }
 
-func (m *podMutator) ensureInitSecret(ctx context.Context, ns corev1.Namespace, dk dynatracev1beta1.DynaKube) *admission.Response {
+// setTenantTarget copies the tenant target of the namespace to the pod, unless the pod selects one itself.
+// Pods are not injected if the tenant target is unknown or not connected yet, to not report them to the wrong environment.
+func (m *podMutator) setTenantTarget(pod *corev1.Pod, ns corev1.Namespace, dk dynatracev1beta1.DynaKube) *admission.Response {
+	tenantTarget := kubeobjects.GetField(pod.Annotations, dtwebhook.AnnotationTenantTarget, ns.Annotations[dtwebhook.AnnotationTenantTarget])
+	if tenantTarget == "" {
+		return nil
+	}
+
+	var err error
+	if dk.TenantTarget(tenantTarget) == nil {
+		err = fmt.Errorf("dynakube %s has no tenant target %s", dk.Name, tenantTarget)
+	} else if dk.TenantTargetUUID(tenantTarget) == "" {
+		err = fmt.Errorf("tenant target %s of dynakube %s is not connected yet", tenantTarget, dk.Name)
+	}
+	if err != nil {
+		rsp := silentErrorResponse(m.currentPodName, err)
+		return &rsp
+	}
+
+	if pod.Annotations == nil {
+		pod.Annotations = map[string]string{}
+	}
+	pod.Annotations[dtwebhook.AnnotationTenantTarget] = tenantTarget
+	return nil
+}
+
+// getInitSecretItems mounts the config of the additional tenant instead of the primary one, if the pod selects a tenant target.
+func getInitSecretItems(pod *corev1.Pod) []corev1.KeyToPath {
+	tenantTarget := pod.Annotations[dtwebhook.AnnotationTenantTarget]
+	if tenantTarget == "" {
+		return nil
+	}
+	return []corev1.KeyToPath{{
+		Key:  initgeneration.SecretConfigFieldNameForTarget(tenantTarget),
+		Path: standalone.SecretConfigFieldName,
+	}}
+}
+
+// ensureInitSecret creates the init secret if it is missing, or updates it if it lacks the config of the tenant target.
+func (m *podMutator) ensureInitSecret(ctx context.Context, ns corev1.Namespace, dk dynatracev1beta1.DynaKube, tenantTarget string) *admission.Response {
 	var initSecret corev1.Secret
 	var rsp admission.Response
 
-	if err := m.apiReader.Get(ctx, client.ObjectKey{Name: dtwebhook.SecretConfigName, Namespace: ns.Name}, &initSecret); k8serrors.IsNotFound(err) {
+	err := m.apiReader.Get(ctx, client.ObjectKey{Name: dtwebhook.SecretConfigName, Namespace: ns.Name}, &initSecret)
+	if k8serrors.IsNotFound(err) || (err == nil && tenantTarget != "" && len(initSecret.Data[initgeneration.SecretConfigFieldNameForTarget(tenantTarget)]) == 0) {
 		if _, err := initgeneration.NewInitGenerator(m.client, m.apiReader, m.namespace).GenerateForNamespace(ctx, dk, ns.Name); err != nil {
 			podLog.Error(err, "Failed to create the init secret before pod injection")
 			rsp = silentErrorResponse(m.currentPodName, err)
@@ -691,6 +895,24 @@ func updateInstallContainerOneAgent(ic *corev1.Container, number int, name strin
 		corev1.EnvVar{Name: fmt.Sprintf("CONTAINER_%d_IMAGE", number), Value: image})
 }
 
+// addContainerToInstallContainer numbers the container after the ones already known by the install container,
+// and raises the container count accordingly.
+func addContainerToInstallContainer(ic *corev1.Container, name string, image string) {
+	number := 1
+	for _, env := range ic.Env {
+		if strings.HasPrefix(env.Name, "CONTAINER_") && strings.HasSuffix(env.Name, "_NAME") {
+			number++
+		}
+	}
+	updateInstallContainerOneAgent(ic, number, name, image)
+
+	for i := range ic.Env {
+		if ic.Env[i].Name == standalone.ContainerCountEnv {
+			ic.Env[i].Value = strconv.Itoa(number)
+		}
+	}
+}
+
 // updateContainerOA sets missing preload Variables
 func updateContainerOneAgent(c *corev1.Container, dk *dynatracev1beta1.DynaKube, pod *corev1.Pod, deploymentMetadata *deploymentmetadata.DeploymentMetadata) {
 
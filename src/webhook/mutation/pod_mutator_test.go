@@ -13,6 +13,7 @@ import (
 	appvolumes "github.com/Dynatrace/dynatrace-operator/src/controllers/csi/driver/volumes/app"
 	"github.com/Dynatrace/dynatrace-operator/src/dtclient"
 	dtingestendpoint "github.com/Dynatrace/dynatrace-operator/src/ingestendpoint"
+	"github.com/Dynatrace/dynatrace-operator/src/initgeneration"
 	"github.com/Dynatrace/dynatrace-operator/src/mapper"
 	"github.com/Dynatrace/dynatrace-operator/src/scheme"
 	"github.com/Dynatrace/dynatrace-operator/src/scheme/fake"
@@ -24,11 +25,13 @@ import (
 	"github.com/stretchr/testify/require"
 	admissionv1 "k8s.io/api/admission/v1"
 	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/json"
 	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 )
@@ -410,6 +413,334 @@ func TestPodInjection(t *testing.T) {
 	)
 }
 
+func TestPodInjectionDryRun(t *testing.T) {
+	decoder, err := admission.NewDecoder(scheme.Scheme)
+	require.NoError(t, err)
+
+	runDryRun := func(t *testing.T, inj *podMutator, podAnnotations map[string]string) (corev1.Pod, []byte) {
+		basePod := corev1.Pod{
+			TypeMeta: metav1.TypeMeta{
+				Kind: "Pod",
+			},
+			ObjectMeta: metav1.ObjectMeta{Name: "test-pod-12345", Namespace: "test-namespace", Annotations: podAnnotations},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{
+					Name:  "test-container",
+					Image: "alpine",
+				}},
+			},
+		}
+		basePodBytes, err := json.Marshal(&basePod)
+		require.NoError(t, err)
+
+		req := admission.Request{
+			AdmissionRequest: admissionv1.AdmissionRequest{
+				Object: runtime.RawExtension{
+					Raw: basePodBytes,
+				},
+				Namespace: "test-namespace",
+			},
+		}
+		resp := inj.Handle(context.TODO(), req)
+		require.NoError(t, resp.Complete(req))
+		require.True(t, resp.Allowed)
+
+		patch, err := jsonpatch.DecodePatch(resp.Patch)
+		require.NoError(t, err)
+		updPodBytes, err := patch.Apply(basePodBytes)
+		require.NoError(t, err)
+
+		var updPod corev1.Pod
+		require.NoError(t, json.Unmarshal(updPodBytes, &updPod))
+		return updPod, basePodBytes
+	}
+
+	assertDryRun := func(t *testing.T, inj *podMutator, updPod corev1.Pod, basePodBytes []byte) {
+		assert.Empty(t, updPod.Spec.InitContainers)
+		assert.Empty(t, updPod.Spec.Volumes)
+		assert.Empty(t, updPod.Spec.Containers[0].Env)
+		assert.NotContains(t, updPod.Annotations, dtwebhook.AnnotationDynatraceInjected)
+
+		dryRunPatch, err := jsonpatch.DecodePatch([]byte(updPod.Annotations[dtwebhook.AnnotationDryRunPatch]))
+		require.NoError(t, err)
+		injectedPodBytes, err := dryRunPatch.Apply(basePodBytes)
+		require.NoError(t, err)
+
+		var injectedPod corev1.Pod
+		require.NoError(t, json.Unmarshal(injectedPodBytes, &injectedPod))
+		require.Len(t, injectedPod.Spec.InitContainers, 1)
+		assert.Equal(t, dtwebhook.InstallContainerName, injectedPod.Spec.InitContainers[0].Name)
+		assert.Equal(t, "data-ingest,oneagent", injectedPod.Annotations[dtwebhook.AnnotationDynatraceInjected])
+
+		t_utils.AssertEvents(t,
+			inj.recorder.(*record.FakeRecorder).Events,
+			t_utils.Events{
+				t_utils.Event{
+					EventType: corev1.EventTypeNormal,
+					Reason:    dryRunEvent,
+				},
+			},
+		)
+	}
+
+	t.Run(`dry run annotation on pod`, func(t *testing.T) {
+		inj, instance := createPodInjector(t, decoder, defaultInjection)
+		require.NoError(t, inj.client.Update(context.TODO(), instance))
+
+		updPod, basePodBytes := runDryRun(t, inj, map[string]string{dtwebhook.AnnotationDryRun: "true"})
+		assertDryRun(t, inj, updPod, basePodBytes)
+	})
+	t.Run(`dry run annotation on namespace`, func(t *testing.T) {
+		inj, instance := createPodInjector(t, decoder, defaultInjection)
+		require.NoError(t, inj.client.Update(context.TODO(), instance))
+
+		var ns corev1.Namespace
+		require.NoError(t, inj.client.Get(context.TODO(), client.ObjectKey{Name: "test-namespace"}, &ns))
+		ns.Annotations = map[string]string{dtwebhook.AnnotationDryRun: "true"}
+		require.NoError(t, inj.client.Update(context.TODO(), &ns))
+
+		updPod, basePodBytes := runDryRun(t, inj, nil)
+		assertDryRun(t, inj, updPod, basePodBytes)
+	})
+	t.Run(`dry run does not create secrets`, func(t *testing.T) {
+		inj, instance := createPodInjector(t, decoder, defaultInjection)
+		require.NoError(t, inj.client.Update(context.TODO(), instance))
+		inj.apiReader = inj.client
+
+		updPod, basePodBytes := runDryRun(t, inj, map[string]string{dtwebhook.AnnotationDryRun: "true"})
+		assertDryRun(t, inj, updPod, basePodBytes)
+
+		for _, name := range []string{dtwebhook.SecretConfigName, dtingestendpoint.SecretEndpointName} {
+			err := inj.client.Get(context.TODO(), client.ObjectKey{Name: name, Namespace: "test-namespace"}, &corev1.Secret{})
+			assert.True(t, k8serrors.IsNotFound(err), name)
+		}
+	})
+	t.Run(`pod annotation overrides namespace`, func(t *testing.T) {
+		inj, instance := createPodInjector(t, decoder, defaultInjection)
+		require.NoError(t, inj.client.Update(context.TODO(), instance))
+
+		var ns corev1.Namespace
+		require.NoError(t, inj.client.Get(context.TODO(), client.ObjectKey{Name: "test-namespace"}, &ns))
+		ns.Annotations = map[string]string{dtwebhook.AnnotationDryRun: "true"}
+		require.NoError(t, inj.client.Update(context.TODO(), &ns))
+
+		updPod, _ := runDryRun(t, inj, map[string]string{dtwebhook.AnnotationDryRun: "false"})
+		assert.Len(t, updPod.Spec.InitContainers, 1)
+		assert.NotContains(t, updPod.Annotations, dtwebhook.AnnotationDryRunPatch)
+	})
+}
+
+func TestPodInjectionContainerSelection(t *testing.T) {
+	decoder, err := admission.NewDecoder(scheme.Scheme)
+	require.NoError(t, err)
+
+	inj, instance := createPodInjector(t, decoder, defaultInjection)
+	require.NoError(t, inj.client.Update(context.TODO(), instance))
+
+	basePod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-pod-12345",
+			Namespace: "test-namespace",
+			Annotations: map[string]string{
+				dtwebhook.AnnotationOneAgentContainersExclude:   "app",
+				dtwebhook.AnnotationDataIngestContainersInclude: "proxy",
+			},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{Name: "app", Image: "app-image", SecurityContext: &corev1.SecurityContext{RunAsUser: pointer.Int64Ptr(1000)}},
+				{Name: "sidecar", Image: "sidecar-image", SecurityContext: &corev1.SecurityContext{RunAsUser: pointer.Int64Ptr(2000)}},
+				{Name: "proxy", Image: "proxy-image"},
+			},
+		},
+	}
+	basePodBytes, err := json.Marshal(&basePod)
+	require.NoError(t, err)
+
+	req := admission.Request{
+		AdmissionRequest: admissionv1.AdmissionRequest{
+			Object: runtime.RawExtension{
+				Raw: basePodBytes,
+			},
+			Namespace: "test-namespace",
+		},
+	}
+	resp := inj.Handle(context.TODO(), req)
+	require.NoError(t, resp.Complete(req))
+	require.True(t, resp.Allowed)
+
+	patch, err := jsonpatch.DecodePatch(resp.Patch)
+	require.NoError(t, err)
+	updPodBytes, err := patch.Apply(basePodBytes)
+	require.NoError(t, err)
+
+	var updPod corev1.Pod
+	require.NoError(t, json.Unmarshal(updPodBytes, &updPod))
+
+	require.Len(t, updPod.Spec.InitContainers, 1)
+	installContainer := updPod.Spec.InitContainers[0]
+	assert.Equal(t, pointer.Int64Ptr(2000), installContainer.SecurityContext.RunAsUser)
+
+	installEnv := map[string]string{}
+	for _, env := range installContainer.Env {
+		installEnv[env.Name] = env.Value
+	}
+	assert.Equal(t, "2", installEnv[standalone.ContainerCountEnv])
+	assert.Equal(t, "sidecar", installEnv["CONTAINER_1_NAME"])
+	assert.Equal(t, "proxy", installEnv["CONTAINER_2_NAME"])
+	assert.NotContains(t, installEnv, "CONTAINER_3_NAME")
+
+	hasEnv := func(c corev1.Container, name string) bool {
+		for _, env := range c.Env {
+			if env.Name == name {
+				return true
+			}
+		}
+		return false
+	}
+	hasVolumeMount := func(c corev1.Container, name string) bool {
+		for _, vm := range c.VolumeMounts {
+			if vm.Name == name {
+				return true
+			}
+		}
+		return false
+	}
+
+	app, sidecar, proxy := updPod.Spec.Containers[0], updPod.Spec.Containers[1], updPod.Spec.Containers[2]
+	assert.False(t, hasEnv(app, "LD_PRELOAD"))
+	assert.True(t, hasEnv(sidecar, "LD_PRELOAD"))
+	assert.True(t, hasEnv(proxy, "LD_PRELOAD"))
+	assert.False(t, hasVolumeMount(app, dataIngestEndpointVolumeName))
+	assert.False(t, hasVolumeMount(sidecar, dataIngestEndpointVolumeName))
+	assert.True(t, hasVolumeMount(proxy, dataIngestEndpointVolumeName))
+	assert.Equal(t, "data-ingest,oneagent", updPod.Annotations[dtwebhook.AnnotationDynatraceInjected])
+}
+
+func TestPodInjectionWithTenantTarget(t *testing.T) {
+	const tenantTarget = "migration"
+	decoder, err := admission.NewDecoder(scheme.Scheme)
+	require.NoError(t, err)
+
+	injectPod := func(t *testing.T, inj *podMutator, annotations map[string]string) (admission.Response, corev1.Pod) {
+		basePod := corev1.Pod{
+			TypeMeta:   metav1.TypeMeta{Kind: "Pod"},
+			ObjectMeta: metav1.ObjectMeta{Name: "test-pod-12345", Namespace: "test-namespace", Annotations: annotations},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: "test-container", Image: "alpine"}},
+			},
+		}
+		basePodBytes, err := json.Marshal(&basePod)
+		require.NoError(t, err)
+
+		req := admission.Request{
+			AdmissionRequest: admissionv1.AdmissionRequest{
+				Object:    runtime.RawExtension{Raw: basePodBytes},
+				Namespace: "test-namespace",
+			},
+		}
+		resp := inj.Handle(context.TODO(), req)
+		require.NoError(t, resp.Complete(req))
+		if len(resp.Patch) == 0 {
+			return resp, basePod
+		}
+
+		patch, err := jsonpatch.DecodePatch(resp.Patch)
+		require.NoError(t, err)
+		updPodBytes, err := patch.Apply(basePodBytes)
+		require.NoError(t, err)
+
+		var updPod corev1.Pod
+		require.NoError(t, json.Unmarshal(updPodBytes, &updPod))
+		return resp, updPod
+	}
+	createInjectorWithTenantTarget := func(t *testing.T) *podMutator {
+		inj, instance := createPodInjector(t, decoder, defaultInjection)
+		instance.Spec.AdditionalTenants = []dynatracev1beta1.TenantTargetSpec{{Name: tenantTarget, APIURL: "https://migration.test-api-url.com/api"}}
+		instance.Status.AdditionalTenants = []dynatracev1beta1.TenantTargetStatus{{
+			Name:           tenantTarget,
+			ConnectionInfo: dynatracev1beta1.ConnectionInfoStatus{TenantUUID: "migration"},
+		}}
+		require.NoError(t, inj.client.Update(context.TODO(), instance))
+
+		apiReader := buildTestSecrets()
+		var initSecret corev1.Secret
+		require.NoError(t, apiReader.Get(context.TODO(), client.ObjectKey{Name: dtwebhook.SecretConfigName, Namespace: "test-namespace"}, &initSecret))
+		initSecret.Data = map[string][]byte{initgeneration.SecretConfigFieldNameForTarget(tenantTarget): []byte("{}")}
+		require.NoError(t, apiReader.Update(context.TODO(), &initSecret))
+		inj.apiReader = apiReader
+		return inj
+	}
+	assertInitSecretKey := func(t *testing.T, pod corev1.Pod, key string) {
+		for _, volume := range pod.Spec.Volumes {
+			if volume.Name == injectionConfigVolumeName {
+				assert.Equal(t, dtwebhook.SecretConfigName, volume.Secret.SecretName)
+				if key == "" {
+					assert.Empty(t, volume.Secret.Items)
+					return
+				}
+				require.Len(t, volume.Secret.Items, 1)
+				assert.Equal(t, key, volume.Secret.Items[0].Key)
+				assert.Equal(t, standalone.SecretConfigFieldName, volume.Secret.Items[0].Path)
+				return
+			}
+		}
+		assert.Fail(t, "injection config volume is missing")
+	}
+
+	t.Run(`pod selects tenant target`, func(t *testing.T) {
+		inj := createInjectorWithTenantTarget(t)
+
+		resp, pod := injectPod(t, inj, map[string]string{dtwebhook.AnnotationTenantTarget: tenantTarget})
+		require.True(t, resp.Allowed)
+
+		assertInitSecretKey(t, pod, "config-"+tenantTarget)
+	})
+	t.Run(`tenant target of namespace is copied to pod`, func(t *testing.T) {
+		inj := createInjectorWithTenantTarget(t)
+		var ns corev1.Namespace
+		require.NoError(t, inj.client.Get(context.TODO(), client.ObjectKey{Name: "test-namespace"}, &ns))
+		ns.Annotations = map[string]string{dtwebhook.AnnotationTenantTarget: tenantTarget}
+		require.NoError(t, inj.client.Update(context.TODO(), &ns))
+
+		resp, pod := injectPod(t, inj, nil)
+		require.True(t, resp.Allowed)
+
+		assert.Equal(t, tenantTarget, pod.Annotations[dtwebhook.AnnotationTenantTarget])
+		assertInitSecretKey(t, pod, "config-"+tenantTarget)
+	})
+	t.Run(`pod without tenant target uses primary tenant`, func(t *testing.T) {
+		inj := createInjectorWithTenantTarget(t)
+
+		resp, pod := injectPod(t, inj, nil)
+		require.True(t, resp.Allowed)
+
+		assertInitSecretKey(t, pod, "")
+	})
+	t.Run(`unknown tenant target is not injected`, func(t *testing.T) {
+		inj := createInjectorWithTenantTarget(t)
+
+		resp, pod := injectPod(t, inj, map[string]string{dtwebhook.AnnotationTenantTarget: "unknown"})
+		require.True(t, resp.Allowed)
+
+		assert.Empty(t, pod.Spec.InitContainers)
+		assert.Contains(t, resp.Result.Message, "has no tenant target unknown")
+	})
+	t.Run(`tenant target which is not connected yet is not injected`, func(t *testing.T) {
+		inj := createInjectorWithTenantTarget(t)
+		var instance dynatracev1beta1.DynaKube
+		require.NoError(t, inj.client.Get(context.TODO(), client.ObjectKey{Name: dynakubeName, Namespace: "dynatrace"}, &instance))
+		instance.Status.AdditionalTenants = nil
+		require.NoError(t, inj.client.Update(context.TODO(), &instance))
+
+		resp, pod := injectPod(t, inj, map[string]string{dtwebhook.AnnotationTenantTarget: tenantTarget})
+		require.True(t, resp.Allowed)
+
+		assert.Empty(t, pod.Spec.InitContainers)
+		assert.Contains(t, resp.Result.Message, "is not connected yet")
+	})
+}
+
 func TestPodInjectionWithCSI(t *testing.T) {
 	decoder, err := admission.NewDecoder(scheme.Scheme)
 	require.NoError(t, err)
@@ -1226,6 +1557,10 @@ func buildResultPod(_ *testing.T, oneAgentFf FeatureFlag, dataIngestFf FeatureFl
 		pod.ObjectMeta.Annotations = make(map[string]string)
 	}
 
+	if oaEnabled {
+		pod.ObjectMeta.Labels = map[string]string{dtwebhook.LabelOneAgentInjected: "true"}
+	}
+
 	if oaEnabled && diEnabled {
 		pod.ObjectMeta.Annotations["dynakube.dynatrace.com/injected"] = "data-ingest,oneagent"
 	} else if oaEnabled {
@@ -1550,3 +1885,182 @@ func buildTestSecrets() client.Client {
 		},
 	)
 }
+
+func TestSetupPodInfoVolume(t *testing.T) {
+	t.Run(`no pod info without pod rules`, func(t *testing.T) {
+		pod := &corev1.Pod{}
+		ic := &corev1.Container{}
+		dk := dynatracev1beta1.DynaKube{}
+		dk.Spec.MetadataEnrichment.Rules = []dynatracev1beta1.EnrichmentRule{{Type: dynatracev1beta1.EnrichmentRuleTypeNamespaceLabel, Source: "team"}}
+
+		setupPodInfoVolume(pod, dk)
+		decorateInstallContainerWithPodInfo(ic, dk)
+
+		assert.Empty(t, pod.Spec.Volumes)
+		assert.Empty(t, ic.VolumeMounts)
+	})
+	t.Run(`mount pod info for pod rules`, func(t *testing.T) {
+		pod := &corev1.Pod{}
+		ic := &corev1.Container{}
+		dk := dynatracev1beta1.DynaKube{}
+		dk.Spec.MetadataEnrichment.Rules = []dynatracev1beta1.EnrichmentRule{{Type: dynatracev1beta1.EnrichmentRuleTypePodAnnotation, Source: "owner"}}
+
+		setupPodInfoVolume(pod, dk)
+		decorateInstallContainerWithPodInfo(ic, dk)
+
+		require.Len(t, pod.Spec.Volumes, 1)
+		require.NotNil(t, pod.Spec.Volumes[0].DownwardAPI)
+		assert.Len(t, pod.Spec.Volumes[0].DownwardAPI.Items, 2)
+		require.Len(t, ic.VolumeMounts, 1)
+		assert.Equal(t, standalone.PodInfoDirMount, ic.VolumeMounts[0].MountPath)
+	})
+}
+
+func TestDecorateInstallContainerWithUnverifiedDownloads(t *testing.T) {
+	t.Run(`no env without feature flag`, func(t *testing.T) {
+		ic := &corev1.Container{}
+		dk := dynatracev1beta1.DynaKube{}
+
+		decorateInstallContainerWithUnverifiedDownloads(ic, NewInjectionInfoForPod(&corev1.Pod{}), dk)
+
+		assert.Empty(t, ic.Env)
+	})
+	t.Run(`allow unverified downloads with feature flag`, func(t *testing.T) {
+		ic := &corev1.Container{}
+		dk := dynatracev1beta1.DynaKube{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{dynatracev1beta1.AnnotationFeatureAllowUnverifiedOneAgentDownloads: "true"},
+			},
+		}
+
+		decorateInstallContainerWithUnverifiedDownloads(ic, NewInjectionInfoForPod(&corev1.Pod{}), dk)
+
+		assert.Equal(t, []corev1.EnvVar{{Name: standalone.InstallerAllowUnverifiedEnv, Value: "true"}}, ic.Env)
+	})
+	t.Run(`no env without OneAgent injection`, func(t *testing.T) {
+		ic := &corev1.Container{}
+		dk := dynatracev1beta1.DynaKube{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{dynatracev1beta1.AnnotationFeatureAllowUnverifiedOneAgentDownloads: "true"},
+			},
+		}
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{dtwebhook.AnnotationOneAgentInject: "false"},
+			},
+		}
+
+		decorateInstallContainerWithUnverifiedDownloads(ic, NewInjectionInfoForPod(pod), dk)
+
+		assert.Empty(t, ic.Env)
+	})
+}
+
+func TestSetupPullSecretVolume(t *testing.T) {
+	dk := dynatracev1beta1.DynaKube{
+		Spec: dynatracev1beta1.DynaKubeSpec{
+			OneAgent: dynatracev1beta1.OneAgentSpec{
+				ApplicationMonitoring: &dynatracev1beta1.ApplicationMonitoringSpec{
+					AppInjectionSpec: dynatracev1beta1.AppInjectionSpec{
+						CodeModulesSource: &dynatracev1beta1.CodeModulesSourceSpec{Image: "registry.local/codemodules"},
+					},
+				},
+			},
+		},
+	}
+	newPod := func() *corev1.Pod {
+		return &corev1.Pod{Spec: corev1.PodSpec{
+			ImagePullSecrets: []corev1.LocalObjectReference{{Name: "registry-a"}, {Name: "registry-b"}},
+		}}
+	}
+
+	t.Run(`mount image pull secrets of the pod`, func(t *testing.T) {
+		pod := newPod()
+		ic := &corev1.Container{}
+
+		setupPullSecretVolume(NewInjectionInfoForPod(pod), pod, dk, installerVolumeMode)
+		decorateInstallContainerWithPullSecret(ic, NewInjectionInfoForPod(pod), pod, dk, installerVolumeMode)
+
+		require.Len(t, pod.Spec.Volumes, 1)
+		require.NotNil(t, pod.Spec.Volumes[0].Projected)
+		sources := pod.Spec.Volumes[0].Projected.Sources
+		require.Len(t, sources, 2)
+		assert.Equal(t, "registry-a", sources[0].Secret.Name)
+		assert.Equal(t, corev1.DockerConfigJsonKey, sources[0].Secret.Items[0].Key)
+		assert.True(t, *sources[0].Secret.Optional)
+		require.Len(t, ic.VolumeMounts, 1)
+		assert.Equal(t, standalone.PullSecretDirMount, ic.VolumeMounts[0].MountPath)
+	})
+	t.Run(`no pull secrets with the CSI driver`, func(t *testing.T) {
+		pod := newPod()
+		ic := &corev1.Container{}
+
+		setupPullSecretVolume(NewInjectionInfoForPod(pod), pod, dk, provisionedVolumeMode)
+		decorateInstallContainerWithPullSecret(ic, NewInjectionInfoForPod(pod), pod, dk, provisionedVolumeMode)
+
+		assert.Empty(t, pod.Spec.Volumes)
+		assert.Empty(t, ic.VolumeMounts)
+	})
+	t.Run(`no pull secrets without code modules image`, func(t *testing.T) {
+		pod := newPod()
+
+		setupPullSecretVolume(NewInjectionInfoForPod(pod), pod, dynatracev1beta1.DynaKube{}, installerVolumeMode)
+
+		assert.Empty(t, pod.Spec.Volumes)
+	})
+}
+
+func TestEnsureDynakubeVolume(t *testing.T) {
+	dk := dynatracev1beta1.DynaKube{
+		ObjectMeta: metav1.ObjectMeta{Name: dynakubeName},
+		Spec: dynatracev1beta1.DynaKubeSpec{
+			OneAgent: dynatracev1beta1.OneAgentSpec{
+				CloudNativeFullStack: &dynatracev1beta1.CloudNativeFullStackSpec{},
+			},
+		},
+	}
+
+	t.Run(`no flavor without annotation`, func(t *testing.T) {
+		dkVol, mode := ensureDynakubeVolume(dk, &corev1.Pod{})
+
+		assert.Equal(t, provisionedVolumeMode, mode)
+		require.NotNil(t, dkVol.CSI)
+		assert.NotContains(t, dkVol.CSI.VolumeAttributes, csivolumes.CSIVolumeAttributeFlavorField)
+	})
+	t.Run(`requested flavor is passed to the CSI driver`, func(t *testing.T) {
+		prePullDk := *dk.DeepCopy()
+		prePullDk.Spec.OneAgent.CloudNativeFullStack.CodeModulesPrePull = &dynatracev1beta1.CodeModulesPrePullSpec{
+			Flavors: []string{dtclient.FlavorMusl},
+		}
+		pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{dtwebhook.AnnotationFlavor: dtclient.FlavorMusl},
+		}}
+
+		dkVol, mode := ensureDynakubeVolume(prePullDk, pod)
+
+		assert.Equal(t, provisionedVolumeMode, mode)
+		require.NotNil(t, dkVol.CSI)
+		assert.Equal(t, dtclient.FlavorMusl, dkVol.CSI.VolumeAttributes[csivolumes.CSIVolumeAttributeFlavorField])
+	})
+	t.Run(`musl is downloaded by the installer if it is not pre-pulled`, func(t *testing.T) {
+		pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{dtwebhook.AnnotationFlavor: dtclient.FlavorMusl},
+		}}
+
+		dkVol, mode := ensureDynakubeVolume(dk, pod)
+
+		assert.Equal(t, installerVolumeMode, mode)
+		assert.Nil(t, dkVol.CSI)
+		assert.NotNil(t, dkVol.EmptyDir)
+	})
+	t.Run(`other flavors use the CSI driver without pre-pull`, func(t *testing.T) {
+		pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{dtwebhook.AnnotationFlavor: dtclient.FlavorDefault},
+		}}
+
+		dkVol, mode := ensureDynakubeVolume(dk, pod)
+
+		assert.Equal(t, provisionedVolumeMode, mode)
+		require.NotNil(t, dkVol.CSI)
+	})
+}
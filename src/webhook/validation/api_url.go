@@ -35,17 +35,22 @@ func noApiUrl(dv *dynakubeValidator, dynakube *dynatracev1beta1.DynaKube) string
 }
 
 func isInvalidApiUrl(dv *dynakubeValidator, dynakube *dynatracev1beta1.DynaKube) string {
-	apiUrl := dynakube.Spec.APIURL
+	if !isValidApiUrl(dynakube.Spec.APIURL) {
+		return errorInvalidApiUrl
+	}
+	return ""
+}
 
+func isValidApiUrl(apiUrl string) bool {
 	if !strings.HasSuffix(apiUrl, "/api") {
 		log.Info("api url does not end with /api", "apiUrl", apiUrl)
-		return errorInvalidApiUrl
+		return false
 	}
 
 	parsedUrl, err := url.Parse(apiUrl)
 	if err != nil {
 		log.Info("API URL is not a valid URL", "err", err.Error())
-		return errorInvalidApiUrl
+		return false
 	}
 
 	hostname := parsedUrl.Hostname()
@@ -55,8 +60,8 @@ func isInvalidApiUrl(dv *dynakubeValidator, dynakube *dynatracev1beta1.DynaKube)
 
 	if len(hostnameWithDomains) < 1 || len(hostnameWithDomains[0]) == 0 {
 		log.Info("invalid hostname in the api url", "hostname", hostname)
-		return errorInvalidApiUrl
+		return false
 	}
 
-	return ""
+	return true
 }
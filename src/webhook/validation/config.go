@@ -12,11 +12,18 @@ type validator func(dv *dynakubeValidator, dynakube *dynatracev1beta1.DynaKube)
 var validators = []validator{
 	noApiUrl,
 	isInvalidApiUrl,
+	invalidTenantTargets,
+	tenantTargetsWithoutMultipleOsAgents,
+	invalidMaintenanceWindows,
+	invalidSecretProvider,
+	invalidSettingsObjects,
 	missingCSIDaemonSet,
 	conflictingActiveGateConfiguration,
 	invalidActiveGateCapabilities,
 	duplicateActiveGateCapabilities,
 	conflictingOneAgentConfiguration,
+	invalidCodeModulesSource,
+	invalidCodeModulesPrePull,
 	conflictingNodeSelector,
 	conflictingNamespaceSelector,
 	conflictingReadOnlyFilesystemAndMultipleOsAgentsOnNode,
@@ -27,4 +34,5 @@ var warnings = []validator{
 	metricIngestPreviewWarning,
 	statsdIngestPreviewWarning,
 	missingActiveGateMemoryLimit,
+	missingTokenScopes,
 }
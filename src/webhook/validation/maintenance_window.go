@@ -0,0 +1,24 @@
+package validation
+
+import (
+	"fmt"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	"github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube/maintenancewindow"
+)
+
+const (
+	errorInvalidMaintenanceWindow = `The DynaKube's specification has an invalid maintenance window with the schedule '%s': %s.
+Make sure the schedule is a cron expression with 5 fields, the duration is at most 7 days and the time zone is a valid IANA time zone.
+`
+)
+
+func invalidMaintenanceWindows(_ *dynakubeValidator, dynakube *dynatracev1beta1.DynaKube) string {
+	for _, window := range dynakube.Spec.MaintenanceWindows {
+		if err := maintenancewindow.Validate(window); err != nil {
+			log.Info("requested dynakube has an invalid maintenance window", "name", dynakube.Name, "schedule", window.Schedule, "error", err.Error())
+			return fmt.Sprintf(errorInvalidMaintenanceWindow, window.Schedule, err.Error())
+		}
+	}
+	return ""
+}
@@ -0,0 +1,39 @@
+package validation
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestInvalidMaintenanceWindows(t *testing.T) {
+	newDynakube := func(windows ...dynatracev1beta1.MaintenanceWindowSpec) *dynatracev1beta1.DynaKube {
+		return &dynatracev1beta1.DynaKube{
+			ObjectMeta: defaultDynakubeObjectMeta,
+			Spec: dynatracev1beta1.DynaKubeSpec{
+				APIURL:             testApiUrl,
+				MaintenanceWindows: windows,
+			},
+		}
+	}
+
+	t.Run(`valid maintenance windows`, func(t *testing.T) {
+		assertAllowedResponseWithoutWarnings(t, newDynakube(
+			dynatracev1beta1.MaintenanceWindowSpec{Schedule: "0 22 * * 1-5", Duration: metav1.Duration{Duration: 4 * time.Hour}, TimeZone: "Europe/Vienna"},
+			dynatracev1beta1.MaintenanceWindowSpec{Schedule: "0 0 * * 6", Duration: metav1.Duration{Duration: 48 * time.Hour}},
+		))
+	})
+	t.Run(`invalid schedule`, func(t *testing.T) {
+		assertDeniedResponse(t, []string{fmt.Sprintf(errorInvalidMaintenanceWindow, "0 22 * *", "schedule '0 22 * *' has 4 fields instead of 5")}, newDynakube(
+			dynatracev1beta1.MaintenanceWindowSpec{Schedule: "0 22 * *", Duration: metav1.Duration{Duration: time.Hour}},
+		))
+	})
+	t.Run(`invalid time zone`, func(t *testing.T) {
+		assertDeniedResponse(t, []string{fmt.Sprintf(errorInvalidMaintenanceWindow, "0 22 * * *", "unknown time zone 'Europe/Nowhere' of maintenance window")}, newDynakube(
+			dynatracev1beta1.MaintenanceWindowSpec{Schedule: "0 22 * * *", Duration: metav1.Duration{Duration: time.Hour}, TimeZone: "Europe/Nowhere"},
+		))
+	})
+}
@@ -2,9 +2,12 @@ package validation
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 
 	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	"github.com/Dynatrace/dynatrace-operator/src/dtclient"
 	"k8s.io/apimachinery/pkg/labels"
 )
 
@@ -12,6 +15,26 @@ const (
 	errorConflictingOneagentMode = `The DynaKube's specification tries to use multiple oneagent modes at the same time, which is not supported.
 `
 
+	errorConflictingCodeModulesSource = `The DynaKube's specification tries to get the code modules from an image and a mirror at the same time, which is not supported.
+`
+
+	errorMissingMirrorPublicKey = `The DynaKube's specification sets a mirror index url for the code modules, but no public key to verify its signature.
+`
+
+	errorInvalidCodeModulesSha256 = `The DynaKube's specification pins the code modules checksum '%s', which is not a hex encoded SHA-256 checksum.
+`
+
+	errorCodeModulesSha256WithoutVersion = `The DynaKube's specification pins the code modules checksum without a version, which is not supported.
+Make sure the version of the code modules is set, as the checksum only matches the package of a single version.
+`
+
+	errorConflictingCodeModulesSha256 = `The DynaKube's specification pins the code modules checksum, but gets the code modules from an image or a mirror, which is not supported.
+`
+
+	errorInvalidCodeModulesPrePull = `The DynaKube's specification pre-pulls code modules of the unknown %s '%s'.
+Make sure the flavors are either default, multidistro or musl and the architectures are either x86 or arm.
+`
+
 	errorNodeSelectorConflict = `The DynaKube's specification tries to specify a nodeSelector conflicts with an another Dynakube's nodeSelector, which is not supported.
 The conflicting Dynakube: %s
 `
@@ -38,6 +61,61 @@ func conflictingOneAgentConfiguration(dv *dynakubeValidator, dynakube *dynatrace
 	return ""
 }
 
+func invalidCodeModulesSource(_ *dynakubeValidator, dynakube *dynatracev1beta1.DynaKube) string {
+	source := dynakube.CodeModulesSource()
+	if source == nil {
+		return ""
+	}
+	if source.Image != "" && source.MirrorIndexURL != "" {
+		log.Info("requested dynakube has conflicting code modules source", "name", dynakube.Name, "namespace", dynakube.Namespace)
+		return errorConflictingCodeModulesSource
+	}
+	if source.MirrorIndexURL != "" && source.MirrorPublicKey == "" {
+		log.Info("requested dynakube has no public key for the code modules mirror", "name", dynakube.Name, "namespace", dynakube.Namespace)
+		return errorMissingMirrorPublicKey
+	}
+	if source.Sha256 != "" {
+		return invalidCodeModulesSha256(dynakube, source)
+	}
+	return ""
+}
+
+func invalidCodeModulesSha256(dynakube *dynatracev1beta1.DynaKube, source *dynatracev1beta1.CodeModulesSourceSpec) string {
+	if checksum, err := hex.DecodeString(source.Sha256); err != nil || len(checksum) != sha256.Size {
+		log.Info("requested dynakube pins an invalid code modules checksum", "name", dynakube.Name, "namespace", dynakube.Namespace)
+		return fmt.Sprintf(errorInvalidCodeModulesSha256, source.Sha256)
+	}
+	if dynakube.Version() == "" {
+		log.Info("requested dynakube pins the code modules checksum without a version", "name", dynakube.Name, "namespace", dynakube.Namespace)
+		return errorCodeModulesSha256WithoutVersion
+	}
+	if source.Image != "" || source.MirrorIndexURL != "" {
+		log.Info("requested dynakube pins the code modules checksum of an image or mirror", "name", dynakube.Name, "namespace", dynakube.Namespace)
+		return errorConflictingCodeModulesSha256
+	}
+	return ""
+}
+
+func invalidCodeModulesPrePull(_ *dynakubeValidator, dynakube *dynatracev1beta1.DynaKube) string {
+	prePull := dynakube.CodeModulesPrePull()
+	if prePull == nil {
+		return ""
+	}
+	for _, flavor := range prePull.Flavors {
+		if flavor != dtclient.FlavorDefault && flavor != dtclient.FlavorMultidistro && flavor != dtclient.FlavorMusl {
+			log.Info("requested dynakube pre-pulls an unknown flavor", "name", dynakube.Name, "namespace", dynakube.Namespace, "flavor", flavor)
+			return fmt.Sprintf(errorInvalidCodeModulesPrePull, "flavor", flavor)
+		}
+	}
+	for _, arch := range prePull.Architectures {
+		if arch != dtclient.ArchX86 && arch != dtclient.ArchARM {
+			log.Info("requested dynakube pre-pulls an unknown architecture", "name", dynakube.Name, "namespace", dynakube.Namespace, "arch", arch)
+			return fmt.Sprintf(errorInvalidCodeModulesPrePull, "architecture", arch)
+		}
+	}
+	return ""
+}
+
 func conflictingReadOnlyFilesystemAndMultipleOsAgentsOnNode(_ *dynakubeValidator, dynakube *dynatracev1beta1.DynaKube) string {
 	if dynakube.FeatureDisableReadOnlyOneAgent() && dynakube.FeatureEnableMultipleOsAgentsOnNode() {
 		return "Multiple OsAgents require readonly host filesystem"
@@ -72,6 +72,109 @@ func TestConflictingOneAgentConfiguration(t *testing.T) {
 	})
 }
 
+const testCodeModulesSha256 = "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+
+func TestInvalidCodeModulesSource(t *testing.T) {
+	newDynakube := func(source *dynatracev1beta1.CodeModulesSourceSpec) *dynatracev1beta1.DynaKube {
+		return &dynatracev1beta1.DynaKube{
+			ObjectMeta: defaultDynakubeObjectMeta,
+			Spec: dynatracev1beta1.DynaKubeSpec{
+				APIURL: testApiUrl,
+				OneAgent: dynatracev1beta1.OneAgentSpec{
+					ApplicationMonitoring: &dynatracev1beta1.ApplicationMonitoringSpec{
+						AppInjectionSpec: dynatracev1beta1.AppInjectionSpec{
+							CodeModulesSource: source,
+						},
+					},
+				},
+			},
+		}
+	}
+
+	t.Run(`valid code modules sources`, func(t *testing.T) {
+		assertAllowedResponseWithoutWarnings(t, newDynakube(nil))
+		assertAllowedResponseWithoutWarnings(t, newDynakube(&dynatracev1beta1.CodeModulesSourceSpec{
+			Image: "registry.local/codemodules",
+		}))
+		assertAllowedResponseWithoutWarnings(t, newDynakube(&dynatracev1beta1.CodeModulesSourceSpec{
+			MirrorIndexURL:  "https://mirror.local/index.json",
+			MirrorPublicKey: "key",
+		}))
+	})
+	t.Run(`image and mirror`, func(t *testing.T) {
+		assertDeniedResponse(t, []string{errorConflictingCodeModulesSource}, newDynakube(&dynatracev1beta1.CodeModulesSourceSpec{
+			Image:           "registry.local/codemodules",
+			MirrorIndexURL:  "https://mirror.local/index.json",
+			MirrorPublicKey: "key",
+		}))
+	})
+	t.Run(`mirror without public key`, func(t *testing.T) {
+		assertDeniedResponse(t, []string{errorMissingMirrorPublicKey}, newDynakube(&dynatracev1beta1.CodeModulesSourceSpec{
+			MirrorIndexURL: "https://mirror.local/index.json",
+		}))
+	})
+	t.Run(`pinned checksum`, func(t *testing.T) {
+		dynakube := newDynakube(&dynatracev1beta1.CodeModulesSourceSpec{Sha256: testCodeModulesSha256})
+		dynakube.Spec.OneAgent.ApplicationMonitoring.Version = "1.2.3"
+		assertAllowedResponseWithoutWarnings(t, dynakube)
+	})
+	t.Run(`invalid pinned checksum`, func(t *testing.T) {
+		dynakube := newDynakube(&dynatracev1beta1.CodeModulesSourceSpec{Sha256: "abc"})
+		dynakube.Spec.OneAgent.ApplicationMonitoring.Version = "1.2.3"
+		assertDeniedResponse(t, []string{fmt.Sprintf(errorInvalidCodeModulesSha256, "abc")}, dynakube)
+	})
+	t.Run(`pinned checksum without version`, func(t *testing.T) {
+		assertDeniedResponse(t, []string{errorCodeModulesSha256WithoutVersion}, newDynakube(&dynatracev1beta1.CodeModulesSourceSpec{
+			Sha256: testCodeModulesSha256,
+		}))
+	})
+	t.Run(`pinned checksum with image`, func(t *testing.T) {
+		dynakube := newDynakube(&dynatracev1beta1.CodeModulesSourceSpec{
+			Image:  "registry.local/codemodules",
+			Sha256: testCodeModulesSha256,
+		})
+		dynakube.Spec.OneAgent.ApplicationMonitoring.Version = "1.2.3"
+		assertDeniedResponse(t, []string{errorConflictingCodeModulesSha256}, dynakube)
+	})
+}
+
+func TestInvalidCodeModulesPrePull(t *testing.T) {
+	newDynakube := func(prePull *dynatracev1beta1.CodeModulesPrePullSpec) *dynatracev1beta1.DynaKube {
+		return &dynatracev1beta1.DynaKube{
+			ObjectMeta: defaultDynakubeObjectMeta,
+			Spec: dynatracev1beta1.DynaKubeSpec{
+				APIURL: testApiUrl,
+				OneAgent: dynatracev1beta1.OneAgentSpec{
+					ApplicationMonitoring: &dynatracev1beta1.ApplicationMonitoringSpec{
+						AppInjectionSpec: dynatracev1beta1.AppInjectionSpec{
+							CodeModulesPrePull: prePull,
+						},
+					},
+				},
+			},
+		}
+	}
+
+	t.Run(`valid pre-pulls`, func(t *testing.T) {
+		assertAllowedResponseWithoutWarnings(t, newDynakube(nil))
+		assertAllowedResponseWithoutWarnings(t, newDynakube(&dynatracev1beta1.CodeModulesPrePullSpec{
+			Versions:      []string{"1.239.0.20220315-161254"},
+			Flavors:       []string{"default", "multidistro", "musl"},
+			Architectures: []string{"x86", "arm"},
+		}))
+	})
+	t.Run(`unknown flavor`, func(t *testing.T) {
+		assertDeniedResponse(t, []string{fmt.Sprintf(errorInvalidCodeModulesPrePull, "flavor", "alpine")}, newDynakube(&dynatracev1beta1.CodeModulesPrePullSpec{
+			Flavors: []string{"musl", "alpine"},
+		}))
+	})
+	t.Run(`unknown architecture`, func(t *testing.T) {
+		assertDeniedResponse(t, []string{fmt.Sprintf(errorInvalidCodeModulesPrePull, "architecture", "amd64")}, newDynakube(&dynatracev1beta1.CodeModulesPrePullSpec{
+			Architectures: []string{"amd64"},
+		}))
+	})
+}
+
 func TestConflictingNodeSelector(t *testing.T) {
 	newCloudNativeDynakube := func(name string, annotations map[string]string, nodeSelectorValue string) *dynatracev1beta1.DynaKube {
 		return &dynatracev1beta1.DynaKube{
@@ -0,0 +1,43 @@
+package validation
+
+import (
+	"net/url"
+	"path/filepath"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+)
+
+const (
+	errorConflictingSecretProvider = `The DynaKube's specification has to configure exactly one of vault and file for the secret provider.
+`
+	errorInvalidVaultAddress = `The DynaKube's specification has an invalid vault address for the secret provider.
+Make sure the address is a URL with the scheme https, e.g. https://vault.vault.svc:8200.
+`
+	errorRelativeSecretProviderPath = `The DynaKube's specification has a relative file path for the secret provider.
+Make sure the path is the absolute path the secrets are mounted to in the operator, webhook and CSI driver pods.
+`
+)
+
+func invalidSecretProvider(_ *dynakubeValidator, dynakube *dynatracev1beta1.DynaKube) string {
+	provider := dynakube.Spec.SecretProvider
+	if provider == nil {
+		return ""
+	}
+
+	if (provider.Vault == nil) == (provider.File == nil) {
+		log.Info("requested dynakube has conflicting secret provider", "name", dynakube.Name)
+		return errorConflictingSecretProvider
+	}
+	if provider.Vault != nil {
+		address, err := url.Parse(provider.Vault.Address)
+		if err != nil || address.Scheme != "https" || address.Host == "" {
+			log.Info("requested dynakube has invalid vault address", "name", dynakube.Name, "address", provider.Vault.Address)
+			return errorInvalidVaultAddress
+		}
+	}
+	if provider.File != nil && !filepath.IsAbs(provider.File.Path) {
+		log.Info("requested dynakube has relative secret provider path", "name", dynakube.Name, "path", provider.File.Path)
+		return errorRelativeSecretProviderPath
+	}
+	return ""
+}
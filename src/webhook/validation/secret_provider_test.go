@@ -0,0 +1,43 @@
+package validation
+
+import (
+	"testing"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+)
+
+func TestInvalidSecretProvider(t *testing.T) {
+	newDynakube := func(provider *dynatracev1beta1.SecretProviderSpec) *dynatracev1beta1.DynaKube {
+		return &dynatracev1beta1.DynaKube{
+			ObjectMeta: defaultDynakubeObjectMeta,
+			Spec: dynatracev1beta1.DynaKubeSpec{
+				APIURL:         testApiUrl,
+				SecretProvider: provider,
+			},
+		}
+	}
+	vault := &dynatracev1beta1.VaultSecretProviderSpec{Address: "https://vault.vault.svc:8200", Role: "dynatrace"}
+	file := &dynatracev1beta1.FileSecretProviderSpec{Path: "/mnt/secrets"}
+
+	t.Run(`valid secret providers`, func(t *testing.T) {
+		assertAllowedResponseWithoutWarnings(t, newDynakube(&dynatracev1beta1.SecretProviderSpec{Vault: vault}))
+		assertAllowedResponseWithoutWarnings(t, newDynakube(&dynatracev1beta1.SecretProviderSpec{File: file}))
+	})
+	t.Run(`conflicting secret providers`, func(t *testing.T) {
+		assertDeniedResponse(t, []string{errorConflictingSecretProvider}, newDynakube(&dynatracev1beta1.SecretProviderSpec{Vault: vault, File: file}))
+		assertDeniedResponse(t, []string{errorConflictingSecretProvider}, newDynakube(&dynatracev1beta1.SecretProviderSpec{}))
+	})
+	t.Run(`invalid vault address`, func(t *testing.T) {
+		assertDeniedResponse(t, []string{errorInvalidVaultAddress}, newDynakube(&dynatracev1beta1.SecretProviderSpec{
+			Vault: &dynatracev1beta1.VaultSecretProviderSpec{Address: "vault.vault.svc:8200", Role: "dynatrace"},
+		}))
+		assertDeniedResponse(t, []string{errorInvalidVaultAddress}, newDynakube(&dynatracev1beta1.SecretProviderSpec{
+			Vault: &dynatracev1beta1.VaultSecretProviderSpec{Address: "http://vault.vault.svc:8200", Role: "dynatrace"},
+		}))
+	})
+	t.Run(`relative file path`, func(t *testing.T) {
+		assertDeniedResponse(t, []string{errorRelativeSecretProviderPath}, newDynakube(&dynatracev1beta1.SecretProviderSpec{
+			File: &dynatracev1beta1.FileSecretProviderSpec{Path: "mnt/secrets"},
+		}))
+	})
+}
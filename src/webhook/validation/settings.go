@@ -0,0 +1,41 @@
+package validation
+
+import (
+	"encoding/json"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+)
+
+const (
+	errorInvalidSettingsObjectName = `The DynaKube's specification has a settings object without a name or with a name used by another settings object.
+Make sure every settings object has a unique name, the name identifies the object on the tenant across reconciles.
+`
+	errorMissingSettingsSchemaId = `The DynaKube's specification has a settings object without a schemaId.
+`
+	errorInvalidSettingsValue = `The DynaKube's specification has a settings object with a value which isn't a JSON object.
+Make sure the value contains the fields of the schema, e.g. value: {"enabled": true}.
+`
+)
+
+func invalidSettingsObjects(_ *dynakubeValidator, dynakube *dynatracev1beta1.DynaKube) string {
+	names := make(map[string]bool, len(dynakube.Spec.Settings))
+	for _, setting := range dynakube.Spec.Settings {
+		if setting.Name == "" || names[setting.Name] {
+			log.Info("requested dynakube has invalid settings object name", "name", dynakube.Name, "settingsObject", setting.Name)
+			return errorInvalidSettingsObjectName
+		}
+		names[setting.Name] = true
+
+		if setting.SchemaId == "" {
+			log.Info("requested dynakube has settings object without schemaId", "name", dynakube.Name, "settingsObject", setting.Name)
+			return errorMissingSettingsSchemaId
+		}
+
+		var value map[string]interface{}
+		if err := json.Unmarshal(setting.Value.Raw, &value); err != nil || value == nil {
+			log.Info("requested dynakube has invalid settings object value", "name", dynakube.Name, "settingsObject", setting.Name)
+			return errorInvalidSettingsValue
+		}
+	}
+	return ""
+}
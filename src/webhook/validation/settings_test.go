@@ -0,0 +1,56 @@
+package validation
+
+import (
+	"testing"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestInvalidSettingsObjects(t *testing.T) {
+	newDynakube := func(settings ...dynatracev1beta1.SettingsObjectSpec) *dynatracev1beta1.DynaKube {
+		return &dynatracev1beta1.DynaKube{
+			ObjectMeta: defaultDynakubeObjectMeta,
+			Spec: dynatracev1beta1.DynaKubeSpec{
+				APIURL:   testApiUrl,
+				Settings: settings,
+			},
+		}
+	}
+	newSettingsObject := func(name, schemaId, value string) dynatracev1beta1.SettingsObjectSpec {
+		return dynatracev1beta1.SettingsObjectSpec{
+			Name:     name,
+			SchemaId: schemaId,
+			Value:    runtime.RawExtension{Raw: []byte(value)},
+		}
+	}
+
+	t.Run(`valid settings objects`, func(t *testing.T) {
+		assertAllowedResponseWithoutWarnings(t, newDynakube(
+			newSettingsObject("anomaly-detection", "builtin:anomaly-detection.kubernetes.cluster", `{"readinessIssues":{"enabled":true}}`),
+			newSettingsObject("log-monitoring", "builtin:logmonitoring.log-storage-settings", `{"enabled":true}`),
+		))
+	})
+	t.Run(`missing or duplicate name`, func(t *testing.T) {
+		assertDeniedResponse(t, []string{errorInvalidSettingsObjectName}, newDynakube(
+			newSettingsObject("", "builtin:logmonitoring.log-storage-settings", `{"enabled":true}`),
+		))
+		assertDeniedResponse(t, []string{errorInvalidSettingsObjectName}, newDynakube(
+			newSettingsObject("log-monitoring", "builtin:logmonitoring.log-storage-settings", `{"enabled":true}`),
+			newSettingsObject("log-monitoring", "builtin:logmonitoring.log-storage-settings", `{"enabled":false}`),
+		))
+	})
+	t.Run(`missing schemaId`, func(t *testing.T) {
+		assertDeniedResponse(t, []string{errorMissingSettingsSchemaId}, newDynakube(
+			newSettingsObject("log-monitoring", "", `{"enabled":true}`),
+		))
+	})
+	t.Run(`value is not an object`, func(t *testing.T) {
+		assertDeniedResponse(t, []string{errorInvalidSettingsValue}, newDynakube(
+			newSettingsObject("log-monitoring", "builtin:logmonitoring.log-storage-settings", `[true]`),
+		))
+		assertDeniedResponse(t, []string{errorInvalidSettingsValue}, newDynakube(
+			newSettingsObject("log-monitoring", "builtin:logmonitoring.log-storage-settings", `null`),
+		))
+	})
+}
@@ -0,0 +1,62 @@
+package validation
+
+import (
+	"fmt"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	k8svalidation "k8s.io/apimachinery/pkg/util/validation"
+)
+
+const (
+	maxTenantTargetNameLength = 20
+
+	errorInvalidTenantTargetName = `The DynaKube's specification has an additional tenant with the invalid name '%s'.
+Make sure the name is a lowercase RFC 1123 label with at most 20 characters.
+`
+
+	errorDuplicateTenantTarget = `The DynaKube's specification has multiple additional tenants with the name '%s', which is not supported.
+`
+
+	errorInvalidTenantTargetApiUrl = `The DynaKube's specification has an invalid API URL value set for the additional tenant '%s'.
+Make sure you correctly specify the URL in your custom resource (including the /api postfix).
+`
+
+	errorTenantTargetIsPrimaryTenant = `The DynaKube's specification has the additional tenant '%s' with the same API URL as the DynaKube, which is not supported.
+`
+
+	errorTenantTargetWithoutMultipleOsAgents = `The DynaKube's specification has additional tenants and a OneAgent, which deploys a OneAgent per tenant on every node.
+Enable the feature flag ` + dynatracev1beta1.AnnotationFeatureEnableMultipleOsAgentsOnNode + ` to run multiple OneAgents on the same node.
+`
+)
+
+func invalidTenantTargets(_ *dynakubeValidator, dynakube *dynatracev1beta1.DynaKube) string {
+	names := map[string]bool{}
+	for _, target := range dynakube.Spec.AdditionalTenants {
+		if len(target.Name) > maxTenantTargetNameLength || len(k8svalidation.IsDNS1123Label(target.Name)) > 0 {
+			log.Info("requested dynakube has an invalid tenant target name", "name", dynakube.Name, "tenantTarget", target.Name)
+			return fmt.Sprintf(errorInvalidTenantTargetName, target.Name)
+		}
+		if names[target.Name] {
+			log.Info("requested dynakube has duplicate tenant targets", "name", dynakube.Name, "tenantTarget", target.Name)
+			return fmt.Sprintf(errorDuplicateTenantTarget, target.Name)
+		}
+		names[target.Name] = true
+
+		if target.APIURL == exampleApiUrl || !isValidApiUrl(target.APIURL) {
+			return fmt.Sprintf(errorInvalidTenantTargetApiUrl, target.Name)
+		}
+		if target.APIURL == dynakube.Spec.APIURL {
+			log.Info("requested dynakube has a tenant target for its own environment", "name", dynakube.Name, "tenantTarget", target.Name)
+			return fmt.Sprintf(errorTenantTargetIsPrimaryTenant, target.Name)
+		}
+	}
+	return ""
+}
+
+func tenantTargetsWithoutMultipleOsAgents(_ *dynakubeValidator, dynakube *dynatracev1beta1.DynaKube) string {
+	if len(dynakube.Spec.AdditionalTenants) > 0 && dynakube.NeedsOneAgent() && !dynakube.FeatureEnableMultipleOsAgentsOnNode() {
+		log.Info("requested dynakube has tenant targets without multiple OneAgents per node", "name", dynakube.Name)
+		return errorTenantTargetWithoutMultipleOsAgents
+	}
+	return ""
+}
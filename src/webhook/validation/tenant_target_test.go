@@ -0,0 +1,92 @@
+package validation
+
+import (
+	"fmt"
+	"testing"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestInvalidTenantTargets(t *testing.T) {
+	newDynakube := func(targets ...dynatracev1beta1.TenantTargetSpec) *dynatracev1beta1.DynaKube {
+		return &dynatracev1beta1.DynaKube{
+			ObjectMeta: defaultDynakubeObjectMeta,
+			Spec: dynatracev1beta1.DynaKubeSpec{
+				APIURL:            testApiUrl,
+				AdditionalTenants: targets,
+			},
+		}
+	}
+
+	t.Run(`valid tenant targets`, func(t *testing.T) {
+		assertAllowedResponseWithoutWarnings(t, newDynakube())
+		assertAllowedResponseWithoutWarnings(t, newDynakube(
+			dynatracev1beta1.TenantTargetSpec{Name: "migration", APIURL: "https://migration.f.q.d.n/api"},
+			dynatracev1beta1.TenantTargetSpec{Name: "second-tenant", APIURL: "https://other.f.q.d.n/api", Tokens: "other-tokens"},
+		))
+	})
+	t.Run(`invalid name`, func(t *testing.T) {
+		assertDeniedResponse(t, []string{fmt.Sprintf(errorInvalidTenantTargetName, "Migration")}, newDynakube(
+			dynatracev1beta1.TenantTargetSpec{Name: "Migration", APIURL: "https://migration.f.q.d.n/api"},
+		))
+		assertDeniedResponse(t, []string{fmt.Sprintf(errorInvalidTenantTargetName, "")}, newDynakube(
+			dynatracev1beta1.TenantTargetSpec{APIURL: "https://migration.f.q.d.n/api"},
+		))
+		assertDeniedResponse(t, []string{fmt.Sprintf(errorInvalidTenantTargetName, "a-very-long-tenant-target")}, newDynakube(
+			dynatracev1beta1.TenantTargetSpec{Name: "a-very-long-tenant-target", APIURL: "https://migration.f.q.d.n/api"},
+		))
+	})
+	t.Run(`duplicate name`, func(t *testing.T) {
+		assertDeniedResponse(t, []string{fmt.Sprintf(errorDuplicateTenantTarget, "migration")}, newDynakube(
+			dynatracev1beta1.TenantTargetSpec{Name: "migration", APIURL: "https://migration.f.q.d.n/api"},
+			dynatracev1beta1.TenantTargetSpec{Name: "migration", APIURL: "https://other.f.q.d.n/api"},
+		))
+	})
+	t.Run(`invalid api url`, func(t *testing.T) {
+		assertDeniedResponse(t, []string{fmt.Sprintf(errorInvalidTenantTargetApiUrl, "migration")}, newDynakube(
+			dynatracev1beta1.TenantTargetSpec{Name: "migration"},
+		))
+		assertDeniedResponse(t, []string{fmt.Sprintf(errorInvalidTenantTargetApiUrl, "migration")}, newDynakube(
+			dynatracev1beta1.TenantTargetSpec{Name: "migration", APIURL: "https://migration.f.q.d.n"},
+		))
+		assertDeniedResponse(t, []string{fmt.Sprintf(errorInvalidTenantTargetApiUrl, "migration")}, newDynakube(
+			dynatracev1beta1.TenantTargetSpec{Name: "migration", APIURL: exampleApiUrl},
+		))
+	})
+	t.Run(`api url of dynakube`, func(t *testing.T) {
+		assertDeniedResponse(t, []string{fmt.Sprintf(errorTenantTargetIsPrimaryTenant, "migration")}, newDynakube(
+			dynatracev1beta1.TenantTargetSpec{Name: "migration", APIURL: testApiUrl},
+		))
+	})
+}
+
+func TestTenantTargetsWithoutMultipleOsAgents(t *testing.T) {
+	newDynakube := func(annotations map[string]string) *dynatracev1beta1.DynaKube {
+		return &dynatracev1beta1.DynaKube{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        testName,
+				Namespace:   testNamespace,
+				Annotations: annotations,
+			},
+			Spec: dynatracev1beta1.DynaKubeSpec{
+				APIURL: testApiUrl,
+				OneAgent: dynatracev1beta1.OneAgentSpec{
+					CloudNativeFullStack: &dynatracev1beta1.CloudNativeFullStackSpec{},
+				},
+				AdditionalTenants: []dynatracev1beta1.TenantTargetSpec{
+					{Name: "migration", APIURL: "https://migration.f.q.d.n/api"},
+				},
+			},
+		}
+	}
+
+	t.Run(`multiple OneAgents per node enabled`, func(t *testing.T) {
+		assertAllowedResponseWithWarnings(t, 0, newDynakube(map[string]string{
+			dynatracev1beta1.AnnotationFeatureEnableMultipleOsAgentsOnNode: "true",
+		}), &defaultCSIDaemonSet)
+	})
+	t.Run(`multiple OneAgents per node disabled`, func(t *testing.T) {
+		assertDeniedResponse(t, []string{errorTenantTargetWithoutMultipleOsAgents}, newDynakube(nil), &defaultCSIDaemonSet)
+	})
+}
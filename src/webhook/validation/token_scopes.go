@@ -0,0 +1,89 @@
+package validation
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	dtcontroller "github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube"
+	"github.com/Dynatrace/dynatrace-operator/src/dtclient"
+)
+
+const (
+	// tokenScopesTimeout limits how long the webhook waits for the Dynatrace API, so the request doesn't run into the
+	// timeout of the webhook configuration. The requests of the probe are limited to it as well, so they don't outlive the
+	// admission request.
+	tokenScopesTimeout = 5 * time.Second
+
+	warningMissingTokenScopes = `The tokens on secret '%s' are missing scopes needed by the features enabled in the DynaKube's specification: %s.
+Add the scopes to the tokens, otherwise the features won't work.
+`
+)
+
+// missingTokenScopes checks the tokens of the apiUrl tenant against the scopes the features of the DynaKube need.
+// A missing secret or an unreachable tenant are not reported here, the operator reports them in the status of the DynaKube.
+func missingTokenScopes(dv *dynakubeValidator, dynakube *dynatracev1beta1.DynaKube) string {
+	properties, err := dtcontroller.NewDynatraceClientProperties(context.TODO(), dv.apiReader, *dynakube)
+	if err != nil || len(properties.Secret.Data[dtclient.DynatraceApiToken]) == 0 {
+		return ""
+	}
+
+	result := make(chan []string, 1)
+	go func() {
+		result <- dv.probeTokenScopes(*properties, dynakube)
+	}()
+
+	select {
+	case missing := <-result:
+		if len(missing) == 0 {
+			return ""
+		}
+		log.Info("requested dynakube has tokens with missing scopes", "name", dynakube.Name, "namespace", dynakube.Namespace, "missing", missing)
+		return fmt.Sprintf(warningMissingTokenScopes, dynakube.Tokens(), strings.Join(missing, "; "))
+	case <-time.After(tokenScopesTimeout):
+		log.Info("timed out checking the token scopes", "name", dynakube.Name, "namespace", dynakube.Namespace)
+		return ""
+	}
+}
+
+func (dv *dynakubeValidator) probeTokenScopes(properties dtcontroller.DynatraceClientProperties, dynakube *dynatracev1beta1.DynaKube) []string {
+	dynatraceClientFunc := dv.dynatraceClientFunc
+	if dynatraceClientFunc == nil {
+		dynatraceClientFunc = func(properties dtcontroller.DynatraceClientProperties) (dtclient.Client, error) {
+			return dtcontroller.BuildDynatraceProbeClient(properties, tokenScopesTimeout)
+		}
+	}
+	dtc, err := dynatraceClientFunc(properties)
+	if err != nil {
+		log.Info("failed to create Dynatrace API client to check the token scopes", "err", err.Error())
+		return nil
+	}
+
+	tokens := properties.Secret.Data
+	requirements := dynakube.RequiredTokenScopes(len(tokens[dtclient.DynatracePaasToken]) > 0, len(tokens[dtclient.DynatraceDataIngestToken]) > 0)
+
+	var missing []string
+	for _, tokenKey := range []string{dtclient.DynatraceApiToken, dtclient.DynatracePaasToken, dtclient.DynatraceDataIngestToken} {
+		var tokenRequirements []dynatracev1beta1.TokenScopeRequirement
+		for _, requirement := range requirements {
+			if requirement.Token == tokenKey {
+				tokenRequirements = append(tokenRequirements, requirement)
+			}
+		}
+		if len(tokenRequirements) == 0 {
+			continue
+		}
+
+		scopes, err := dtc.GetTokenScopes(strings.TrimSpace(string(tokens[tokenKey])))
+		if err != nil {
+			log.Info("failed to query the token scopes", "token", tokenKey, "err", err.Error())
+			continue
+		}
+		if missingScopes := dtcontroller.MissingScopesPerFeature(tokenRequirements, scopes); len(missingScopes) > 0 {
+			missing = append(missing, fmt.Sprintf("%s is missing %s", tokenKey, strings.Join(missingScopes, ", ")))
+		}
+	}
+	return missing
+}
@@ -0,0 +1,100 @@
+package validation
+
+import (
+	"fmt"
+	"testing"
+
+	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	dtcontroller "github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube"
+	"github.com/Dynatrace/dynatrace-operator/src/dtclient"
+	"github.com/Dynatrace/dynatrace-operator/src/scheme/fake"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestMissingTokenScopes(t *testing.T) {
+	const (
+		apiToken  = "api-token"
+		paasToken = "paas-token"
+	)
+	tokenSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: testName, Namespace: testNamespace},
+		Data: map[string][]byte{
+			dtclient.DynatraceApiToken:  []byte(apiToken),
+			dtclient.DynatracePaasToken: []byte(paasToken),
+		},
+	}
+	newValidator := func(dtc dtclient.Client, objs ...client.Object) *dynakubeValidator {
+		clt := fake.NewClient(objs...)
+		return &dynakubeValidator{
+			clt:                 clt,
+			apiReader:           clt,
+			dynatraceClientFunc: dtcontroller.StaticDynatraceClient(dtc),
+		}
+	}
+	newDynakube := func() *dynatracev1beta1.DynaKube {
+		return &dynatracev1beta1.DynaKube{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      testName,
+				Namespace: testNamespace,
+				Annotations: map[string]string{
+					"alpha.operator.dynatrace.com/feature-automatic-kubernetes-api-monitoring": "true",
+				},
+			},
+			Spec: dynatracev1beta1.DynaKubeSpec{
+				APIURL: testApiUrl,
+				ActiveGate: dynatracev1beta1.ActiveGateSpec{
+					Capabilities: []dynatracev1beta1.CapabilityDisplayName{dynatracev1beta1.KubeMonCapability.DisplayName},
+				},
+			},
+		}
+	}
+
+	t.Run(`tokens have all scopes`, func(t *testing.T) {
+		dtc := &dtclient.MockDynatraceClient{}
+		dtc.On("GetTokenScopes", apiToken).Return(dtclient.TokenScopes{
+			dtclient.TokenScopeDataExport,
+			dtclient.TokenScopeEntitiesRead,
+			dtclient.TokenScopeSettingsRead,
+			dtclient.TokenScopeSettingsWrite,
+		}, nil)
+		dtc.On("GetTokenScopes", paasToken).Return(dtclient.TokenScopes{dtclient.TokenScopeInstallerDownload}, nil)
+
+		assert.Empty(t, missingTokenScopes(newValidator(dtc, tokenSecret), newDynakube()))
+		dtc.AssertExpectations(t)
+	})
+	t.Run(`scopes of enabled feature are missing`, func(t *testing.T) {
+		dtc := &dtclient.MockDynatraceClient{}
+		dtc.On("GetTokenScopes", apiToken).Return(dtclient.TokenScopes{dtclient.TokenScopeDataExport, dtclient.TokenScopeSettingsRead}, nil)
+		dtc.On("GetTokenScopes", paasToken).Return(dtclient.TokenScopes{dtclient.TokenScopeInstallerDownload}, nil)
+
+		warning := missingTokenScopes(newValidator(dtc, tokenSecret), newDynakube())
+
+		assert.Equal(t, fmt.Sprintf(warningMissingTokenScopes, testName,
+			"apiToken is missing [entities.read, settings.write] for automatic Kubernetes API monitoring"), warning)
+	})
+	t.Run(`scopes of disabled feature are not needed`, func(t *testing.T) {
+		dtc := &dtclient.MockDynatraceClient{}
+		dtc.On("GetTokenScopes", apiToken).Return(dtclient.TokenScopes{dtclient.TokenScopeDataExport}, nil)
+		dtc.On("GetTokenScopes", paasToken).Return(dtclient.TokenScopes{dtclient.TokenScopeInstallerDownload}, nil)
+		dynakube := newDynakube()
+		dynakube.Annotations = nil
+
+		assert.Empty(t, missingTokenScopes(newValidator(dtc, tokenSecret), dynakube))
+	})
+	t.Run(`tenant not reachable`, func(t *testing.T) {
+		dtc := &dtclient.MockDynatraceClient{}
+		dtc.On("GetTokenScopes", apiToken).Return(dtclient.TokenScopes{}, fmt.Errorf("connection refused"))
+		dtc.On("GetTokenScopes", paasToken).Return(dtclient.TokenScopes{}, fmt.Errorf("connection refused"))
+
+		assert.Empty(t, missingTokenScopes(newValidator(dtc, tokenSecret), newDynakube()))
+	})
+	t.Run(`missing secret`, func(t *testing.T) {
+		dtc := &dtclient.MockDynatraceClient{}
+
+		assert.Empty(t, missingTokenScopes(newValidator(dtc), newDynakube()))
+		dtc.AssertNotCalled(t, "GetTokenScopes", apiToken)
+	})
+}
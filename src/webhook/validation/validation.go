@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	dynatracev1beta1 "github.com/Dynatrace/dynatrace-operator/src/api/v1beta1"
+	dtcontroller "github.com/Dynatrace/dynatrace-operator/src/controllers/dynakube"
 	"github.com/Dynatrace/dynatrace-operator/src/scheme"
 	"github.com/pkg/errors"
 	"k8s.io/client-go/rest"
@@ -20,6 +21,9 @@ type dynakubeValidator struct {
 	clt       client.Client
 	apiReader client.Reader
 	cfg       *rest.Config
+
+	// dynatraceClientFunc defaults to the client of the operator, it's replaced in tests
+	dynatraceClientFunc dtcontroller.DynatraceClientFunc
 }
 
 func newDynakubeValidator(apiReader client.Reader, cfg *rest.Config) admission.Handler {